@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schema provides helpers to embed the canonical validation of
+// Knative field types into CustomResourceDefinition schemas.
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	apix "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"knative.dev/pkg/apis"
+)
+
+// ItemsSegment is the path segment that descends into the items of an array
+// when addressing a field, e.g. "spec.sinks.[].uri".
+const ItemsSegment = "[]"
+
+// URL returns the schema of an apis.URL field.
+func URL() apix.JSONSchemaProps {
+	return apix.JSONSchemaProps{
+		Type:    "string",
+		Format:  apis.URLSchemaFormat,
+		Pattern: apis.URLSchemaPattern,
+	}
+}
+
+// Duration returns the schema of a duration field, such as metav1.Duration.
+func Duration() apix.JSONSchemaProps {
+	return apix.JSONSchemaProps{
+		Type:    "string",
+		Pattern: apis.DurationSchemaPattern,
+	}
+}
+
+// SetField sets the type, format and pattern of the field at the given
+// dot-separated path of root to those of field. Other settings of the
+// existing field, such as its description, are preserved. Missing
+// intermediate properties result in an error rather than being created, so
+// that typos in the path do not go unnoticed.
+func SetField(root *apix.JSONSchemaProps, path string, field apix.JSONSchemaProps) error {
+	if err := setField(root, strings.Split(path, "."), field); err != nil {
+		return fmt.Errorf("%q: %w", path, err)
+	}
+	return nil
+}
+
+func setField(target *apix.JSONSchemaProps, segments []string, field apix.JSONSchemaProps) error {
+	if len(segments) == 0 {
+		target.Type = field.Type
+		target.Format = field.Format
+		target.Pattern = field.Pattern
+		return nil
+	}
+
+	seg, rest := segments[0], segments[1:]
+	if seg == ItemsSegment {
+		if target.Items == nil || target.Items.Schema == nil {
+			return fmt.Errorf("%s is applied to a non-array", seg)
+		}
+		return setField(target.Items.Schema, rest, field)
+	}
+
+	// Properties holds values, so the modified copy has to be written back.
+	prop, ok := target.Properties[seg]
+	if !ok {
+		return fmt.Errorf("property %q not found", seg)
+	}
+	if err := setField(&prop, rest, field); err != nil {
+		return err
+	}
+	target.Properties[seg] = prop
+	return nil
+}
+
+// SetCRDFields applies SetField with each of the given path to schema
+// mappings to the schemas of all versions of the CRD.
+func SetCRDFields(crd *apix.CustomResourceDefinition, fields map[string]apix.JSONSchemaProps) error {
+	for i := range crd.Spec.Versions {
+		v := &crd.Spec.Versions[i]
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			return fmt.Errorf("version %s has no schema", v.Name)
+		}
+		for path, field := range fields {
+			if err := SetField(v.Schema.OpenAPIV3Schema, path, field); err != nil {
+				return fmt.Errorf("version %s: %w", v.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apix "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func testSchema() *apix.JSONSchemaProps {
+	return &apix.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apix.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apix.JSONSchemaProps{
+					"uri": {
+						Type:        "string",
+						Description: "where to send things",
+					},
+					"sinks": {
+						Type: "array",
+						Items: &apix.JSONSchemaPropsOrArray{
+							Schema: &apix.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apix.JSONSchemaProps{
+									"timeout": {Type: "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSetField(t *testing.T) {
+	s := testSchema()
+	if err := SetField(s, "spec.uri", URL()); err != nil {
+		t.Fatal("SetField() =", err)
+	}
+	if err := SetField(s, "spec.sinks.[].timeout", Duration()); err != nil {
+		t.Fatal("SetField() =", err)
+	}
+
+	want := testSchema()
+	want.Properties["spec"].Properties["uri"] = apix.JSONSchemaProps{
+		Type:        "string",
+		Format:      "uri",
+		Pattern:     URL().Pattern,
+		Description: "where to send things",
+	}
+	want.Properties["spec"].Properties["sinks"].Items.Schema.Properties["timeout"] = Duration()
+	if !cmp.Equal(s, want) {
+		t.Error("SetField() (-want, +got) =", cmp.Diff(want, s))
+	}
+}
+
+func TestSetFieldErrors(t *testing.T) {
+	for _, path := range []string{
+		"spec.url",
+		"status.uri",
+		"spec.uri.[]",
+		"spec.[].uri",
+	} {
+		t.Run(path, func(t *testing.T) {
+			if err := SetField(testSchema(), path, URL()); err == nil {
+				t.Error("SetField() = nil, wanted error")
+			}
+		})
+	}
+}
+
+func TestSetCRDFields(t *testing.T) {
+	crd := &apix.CustomResourceDefinition{
+		Spec: apix.CustomResourceDefinitionSpec{
+			Versions: []apix.CustomResourceDefinitionVersion{{
+				Name:   "v1alpha1",
+				Schema: &apix.CustomResourceValidation{OpenAPIV3Schema: testSchema()},
+			}, {
+				Name:   "v1",
+				Schema: &apix.CustomResourceValidation{OpenAPIV3Schema: testSchema()},
+			}},
+		},
+	}
+	if err := SetCRDFields(crd, map[string]apix.JSONSchemaProps{"spec.uri": URL()}); err != nil {
+		t.Fatal("SetCRDFields() =", err)
+	}
+	for _, v := range crd.Spec.Versions {
+		if got := v.Schema.OpenAPIV3Schema.Properties["spec"].Properties["uri"].Format; got != "uri" {
+			t.Errorf("%s: format = %q, want: uri", v.Name, got)
+		}
+	}
+
+	crd.Spec.Versions = append(crd.Spec.Versions, apix.CustomResourceDefinitionVersion{Name: "v2"})
+	if err := SetCRDFields(crd, map[string]apix.JSONSchemaProps{"spec.uri": URL()}); err == nil {
+		t.Error("SetCRDFields() = nil, wanted error for a version without schema")
+	}
+}
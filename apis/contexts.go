@@ -158,6 +158,70 @@ func ParentMeta(ctx context.Context) metav1.ObjectMeta {
 	return metav1.ObjectMeta{}
 }
 
+// This is attached to contexts as they are passed down through a resource
+// being validated or defaulted to signal the enclosing resource.
+type parentObjectKey struct{}
+
+// WithinParentObject attaches the resource enclosing the nested resources
+// we are validating, so that their validation can reference its other
+// fields.  The ObjectMeta of the resource is attached as with WithinParent
+// if it embeds one.  This is intended for use with interfaces like
+// apis.Defaultable and apis.Validatable.
+func WithinParentObject(ctx context.Context, parent interface{}) context.Context {
+	if a, ok := parent.(metav1.ObjectMetaAccessor); ok {
+		if om, ok := a.GetObjectMeta().(*metav1.ObjectMeta); ok && om != nil {
+			ctx = WithinParent(ctx, *om)
+		}
+	}
+	return context.WithValue(ctx, parentObjectKey{}, parent)
+}
+
+// ParentObject accesses the enclosing parent resource from the context, or
+// returns nil if there is none.  See WithinParentObject for how to attach
+// the parent to the context.
+func ParentObject(ctx context.Context) interface{} {
+	return ctx.Value(parentObjectKey{})
+}
+
+// This is attached to contexts as they are passed down through a resource
+// being validated or defaulted to signal the path of the field within the
+// enclosing resource.
+type fieldPathKey struct{}
+
+// WithinField notes on the context that further validation or defaulting
+// is within the named field, as ViaField does for FieldErrors.  This is
+// intended for use with interfaces like apis.Defaultable and
+// apis.Validatable.
+func WithinField(ctx context.Context, name string) context.Context {
+	path := FieldPath(ctx)
+	return context.WithValue(ctx, fieldPathKey{}, append(path, name))
+}
+
+// WithinFieldIndex notes on the context that further validation or
+// defaulting is within the given element of the named field, as
+// ViaFieldIndex does for FieldErrors.
+func WithinFieldIndex(ctx context.Context, name string, index int) context.Context {
+	return WithinField(ctx, name+asIndex(index))
+}
+
+// WithinFieldKey notes on the context that further validation or
+// defaulting is within the given key of the named field, as ViaFieldKey
+// does for FieldErrors.
+func WithinFieldKey(ctx context.Context, name, key string) context.Context {
+	return WithinField(ctx, name+asKey(key))
+}
+
+// FieldPath returns the path of the field being validated or defaulted
+// within the enclosing resource, from the outermost to the innermost field,
+// e.g. [spec template[0]].  See WithinField for how to attach the path to
+// the context.
+func FieldPath(ctx context.Context) []string {
+	path, _ := ctx.Value(fieldPathKey{}).([]string)
+	// Copy the path so that appending to it doesn't alter the one of the
+	// parent context.
+	return append([]string(nil), path...)
+}
+
 // This is attached to contexts as they are passed down through a resource
 // being validated or defaulted to signal that we are within a Spec.
 type inSpec struct{}
@@ -237,7 +301,10 @@ func WithDryRun(ctx context.Context) context.Context {
 	return context.WithValue(ctx, isDryRun{}, struct{}{})
 }
 
-// IsDryRun indicates that this request is in DryRun mode.
+// IsDryRun indicates that this request is in DryRun mode. The admission
+// webhooks attach it to the contexts passed to Validate, SetDefaults and
+// their callbacks when the AdmissionRequest has DryRun set, in which case
+// they must not have side effects, e.g. reserving quota.
 func IsDryRun(ctx context.Context) bool {
 	return ctx.Value(isDryRun{}) != nil
 }
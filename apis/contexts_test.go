@@ -224,6 +224,79 @@ func TestParentMeta(t *testing.T) {
 	}
 }
 
+func TestParentObject(t *testing.T) {
+	ctx := context.Background()
+
+	if got := ParentObject(ctx); got != nil {
+		t.Errorf("ParentObject() = %v, wanted nil", got)
+	}
+
+	parent := &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "bar",
+		},
+	}
+	ctx = WithinParentObject(ctx, parent)
+
+	if got := ParentObject(ctx); got != parent {
+		t.Errorf("ParentObject() = %v, wanted %v", got, parent)
+	}
+	if got, want := ParentMeta(ctx), parent.ObjectMeta; !cmp.Equal(want, got) {
+		t.Errorf("ParentMeta() = %v, wanted %v", got, want)
+	}
+
+	// Parents without ObjectMeta leave the parent's ObjectMeta alone.
+	ctx = WithinParentObject(ctx, "not an object")
+	if got, want := ParentMeta(ctx), parent.ObjectMeta; !cmp.Equal(want, got) {
+		t.Errorf("ParentMeta() = %v, wanted %v", got, want)
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	ctx := context.Background()
+
+	if got := FieldPath(ctx); len(got) != 0 {
+		t.Errorf("FieldPath() = %v, wanted empty", got)
+	}
+
+	spec := WithinField(ctx, "spec")
+	containers := WithinFieldIndex(spec, "containers", 1)
+	env := WithinFieldKey(containers, "env", "FOO")
+	// A sibling of the containers shares their parent path.
+	volumes := WithinFieldIndex(spec, "volumes", 0)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{{
+		name: "field",
+		ctx:  spec,
+		want: []string{"spec"},
+	}, {
+		name: "index",
+		ctx:  containers,
+		want: []string{"spec", "containers[1]"},
+	}, {
+		name: "key",
+		ctx:  env,
+		want: []string{"spec", "containers[1]", "env[FOO]"},
+	}, {
+		name: "sibling",
+		ctx:  volumes,
+		want: []string{"spec", "volumes[0]"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FieldPath(tc.ctx); !cmp.Equal(tc.want, got) {
+				t.Errorf("FieldPath() = %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestGetHTTPRequest(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package duck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// applyMetadataFields are the metadata fields retained in an apply
+// configuration. Everything else under metadata is either server-populated
+// (uid, resourceVersion, managedFields, ...) or owned by other managers.
+var applyMetadataFields = []string{"name", "namespace"}
+
+// CreateApplyConfiguration builds a server-side apply configuration from a
+// duck-typed object. The result only holds apiVersion, kind, the object's
+// name and namespace, and the given top-level fields (e.g. "spec" or
+// "status"), so that the field manager applying it only claims ownership
+// of the fields the duck type knows about. If no fields are given, all
+// top-level fields are retained.
+//
+// The object must carry its apiVersion and kind, which is the case for
+// objects read through the duck informers.
+func CreateApplyConfiguration(obj interface{}, fields ...string) (*unstructured.Unstructured, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	full := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &full); err != nil {
+		return nil, err
+	}
+	pruneNulls(full)
+
+	u := &unstructured.Unstructured{Object: full}
+	if u.GetAPIVersion() == "" || u.GetKind() == "" {
+		return nil, errors.New("apply configuration requires apiVersion and kind to be set")
+	}
+	if u.GetName() == "" {
+		return nil, errors.New("apply configuration requires metadata.name to be set")
+	}
+
+	ac := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": u.GetAPIVersion(),
+		"kind":       u.GetKind(),
+	}}
+	metadata := map[string]interface{}{}
+	for _, f := range applyMetadataFields {
+		if v, ok, _ := unstructured.NestedFieldNoCopy(full, "metadata", f); ok {
+			metadata[f] = v
+		}
+	}
+	ac.Object["metadata"] = metadata
+
+	if len(fields) == 0 {
+		for k, v := range full {
+			if _, ok := ac.Object[k]; !ok {
+				ac.Object[k] = v
+			}
+		}
+		return ac, nil
+	}
+	for _, f := range fields {
+		if v, ok := full[f]; ok {
+			ac.Object[f] = v
+		}
+	}
+	return ac, nil
+}
+
+// pruneNulls drops explicit nulls, e.g. from zero-valued times, which server-side
+// apply would otherwise interpret as a request to remove the field.
+func pruneNulls(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if e == nil {
+				delete(v, k)
+				continue
+			}
+			pruneNulls(e)
+		}
+	case []interface{}:
+		for _, e := range v {
+			pruneNulls(e)
+		}
+	}
+}
+
+// ApplyPatcher persists duck-typed objects with server-side apply.
+type ApplyPatcher struct {
+	// Client is used to issue the apply requests.
+	Client dynamic.Interface
+
+	// FieldManager is the name of the manager owning the applied fields.
+	FieldManager string
+
+	// Force makes the apply take ownership of fields owned by other
+	// managers instead of failing with a conflict.
+	Force bool
+}
+
+func (p *ApplyPatcher) options() (metav1.ApplyOptions, error) {
+	if p.FieldManager == "" {
+		return metav1.ApplyOptions{}, errors.New("server-side apply requires a field manager")
+	}
+	return metav1.ApplyOptions{
+		FieldManager: p.FieldManager,
+		Force:        p.Force,
+	}, nil
+}
+
+// Apply applies the given top-level fields of obj, see
+// CreateApplyConfiguration.
+func (p *ApplyPatcher) Apply(ctx context.Context, gvr schema.GroupVersionResource, obj interface{}, fields ...string) (*unstructured.Unstructured, error) {
+	opts, err := p.options()
+	if err != nil {
+		return nil, err
+	}
+	ac, err := CreateApplyConfiguration(obj, fields...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := p.Client.Resource(gvr).Namespace(ac.GetNamespace()).Apply(ctx, ac.GetName(), ac, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s %s/%s: %w", gvr.Resource, ac.GetNamespace(), ac.GetName(), err)
+	}
+	return result, nil
+}
+
+// ApplyStatus applies the status of obj through the status subresource.
+func (p *ApplyPatcher) ApplyStatus(ctx context.Context, gvr schema.GroupVersionResource, obj interface{}) (*unstructured.Unstructured, error) {
+	opts, err := p.options()
+	if err != nil {
+		return nil, err
+	}
+	ac, err := CreateApplyConfiguration(obj, "status")
+	if err != nil {
+		return nil, err
+	}
+	result, err := p.Client.Resource(gvr).Namespace(ac.GetNamespace()).ApplyStatus(ctx, ac.GetName(), ac, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply status of %s %s/%s: %w", gvr.Resource, ac.GetNamespace(), ac.GetName(), err)
+	}
+	return result, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package duck_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/apis/duck"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	. "knative.dev/pkg/testing"
+)
+
+func applyTestResource() *duckv1.KResource {
+	return &duckv1.KResource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "pkg.knative.dev/v2",
+			Kind:       "Resource",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "foo",
+			Name:            "bar",
+			ResourceVersion: "42",
+			UID:             "abcd",
+			Labels:          map[string]string{"a": "b"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: "someone-else",
+			}},
+		},
+		Status: duckv1.Status{
+			ObservedGeneration: 3,
+			Conditions: duckv1.Conditions{{
+				Type:   apis.ConditionReady,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+}
+
+func TestCreateApplyConfiguration(t *testing.T) {
+	got, err := duck.CreateApplyConfiguration(applyTestResource(), "status")
+	if err != nil {
+		t.Fatal("CreateApplyConfiguration() =", err)
+	}
+	want := map[string]interface{}{
+		"apiVersion": "pkg.knative.dev/v2",
+		"kind":       "Resource",
+		"metadata": map[string]interface{}{
+			"namespace": "foo",
+			"name":      "bar",
+		},
+		"status": map[string]interface{}{
+			"observedGeneration": float64(3),
+			"conditions": []interface{}{map[string]interface{}{
+				"type":   "Ready",
+				"status": "True",
+			}},
+		},
+	}
+	if !cmp.Equal(got.Object, want) {
+		t.Error("CreateApplyConfiguration (-want, +got) =", cmp.Diff(want, got.Object))
+	}
+}
+
+func TestCreateApplyConfigurationAllFields(t *testing.T) {
+	got, err := duck.CreateApplyConfiguration(applyTestResource())
+	if err != nil {
+		t.Fatal("CreateApplyConfiguration() =", err)
+	}
+	if _, ok := got.Object["status"]; !ok {
+		t.Error("status was not retained")
+	}
+	if got.GetResourceVersion() != "" || len(got.GetManagedFields()) != 0 || got.GetUID() != "" {
+		t.Errorf("server-populated metadata was retained: %#v", got.Object["metadata"])
+	}
+}
+
+func TestCreateApplyConfigurationErrors(t *testing.T) {
+	noType := applyTestResource()
+	noType.TypeMeta = metav1.TypeMeta{}
+	noName := applyTestResource()
+	noName.Name = ""
+
+	for name, obj := range map[string]interface{}{
+		"no type":         noType,
+		"no name":         noName,
+		"doesn't marshal": make(chan struct{}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := duck.CreateApplyConfiguration(obj); err == nil {
+				t.Error("CreateApplyConfiguration() = nil, wanted error")
+			}
+		})
+	}
+}
+
+func TestApplyPatcher(t *testing.T) {
+	tests := []struct {
+		name        string
+		apply       func(*duck.ApplyPatcher) (*unstructured.Unstructured, error)
+		subresource string
+	}{{
+		name: "apply",
+		apply: func(p *duck.ApplyPatcher) (*unstructured.Unstructured, error) {
+			return p.Apply(context.Background(), SchemeGroupVersion.WithResource("resources"), applyTestResource(), "status")
+		},
+	}, {
+		name: "apply status",
+		apply: func(p *duck.ApplyPatcher) (*unstructured.Unstructured, error) {
+			return p.ApplyStatus(context.Background(), SchemeGroupVersion.WithResource("resources"), applyTestResource())
+		},
+		subresource: "status",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+			var got clientgotesting.PatchAction
+			client.PrependReactor("patch", "resources", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				got = action.(clientgotesting.PatchAction)
+				u := &unstructured.Unstructured{}
+				return true, u, json.Unmarshal(got.GetPatch(), &u.Object)
+			})
+
+			if _, err := tc.apply(&duck.ApplyPatcher{Client: client}); err == nil {
+				t.Error("Apply() = nil, wanted error without a field manager")
+			}
+
+			result, err := tc.apply(&duck.ApplyPatcher{Client: client, FieldManager: "test", Force: true})
+			if err != nil {
+				t.Fatal("Apply() =", err)
+			}
+			if got.GetPatchType() != types.ApplyPatchType {
+				t.Errorf("PatchType = %v, want: %v", got.GetPatchType(), types.ApplyPatchType)
+			}
+			if got.GetSubresource() != tc.subresource {
+				t.Errorf("Subresource = %q, want: %q", got.GetSubresource(), tc.subresource)
+			}
+			if got.GetNamespace() != "foo" || got.GetName() != "bar" {
+				t.Errorf("Patched %s/%s, want: foo/bar", got.GetNamespace(), got.GetName())
+			}
+			if _, ok := result.Object["status"]; !ok {
+				t.Error("Applied configuration has no status")
+			}
+		})
+	}
+}
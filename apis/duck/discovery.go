@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package duck
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/apis/duck/ducktypes"
+)
+
+// crdResource is the resource of CustomResourceDefinitions, which are
+// accessed through the dynamic client to avoid depending on the
+// apiextensions clientset.
+var crdResource = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// duckLabelIndex indexes CRDs by the duck types they are labeled with.
+const duckLabelIndex = "duck.knative.dev/label"
+
+// Discoverer finds the resources in the cluster implementing a registered
+// duck type. CRDs are matched by the duck.knative.dev/<name>=true label
+// convention, and are served from an informer-backed cache.
+type Discoverer struct {
+	informer cache.SharedIndexInformer
+}
+
+// NewDiscoverer creates a Discoverer watching CRDs through the given client.
+// Run must be called before the Discoverer is used.
+func NewDiscoverer(client dynamic.Interface, resync time.Duration) *Discoverer {
+	crds := client.Resource(crdResource)
+	informer := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				return crds.List(context.Background(), opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				return crds.Watch(context.Background(), opts)
+			},
+		},
+		&unstructured.Unstructured{},
+		resync,
+		cache.Indexers{duckLabelIndex: indexByDuckLabel},
+	)
+	return &Discoverer{informer: informer}
+}
+
+func indexByDuckLabel(obj interface{}) ([]string, error) {
+	crd, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", obj)
+	}
+	var labels []string
+	for k, v := range crd.GetLabels() {
+		if strings.HasPrefix(k, ducktypes.GroupName+"/") && v == "true" {
+			labels = append(labels, k)
+		}
+	}
+	return labels, nil
+}
+
+// Run starts the underlying informer and blocks until stopCh is closed.
+func (d *Discoverer) Run(stopCh <-chan struct{}) {
+	d.informer.Run(stopCh)
+}
+
+// HasSynced returns true once the cache of CRDs has been populated.
+func (d *Discoverer) HasSynced() bool {
+	return d.informer.HasSynced()
+}
+
+// Implementing returns the resources implementing the given registered duck
+// type: its built-ins, followed by every served version of the CRDs labeled
+// as implementing it, in a stable order.
+func (d *Discoverer) Implementing(impl ducktypes.Implementable) ([]schema.GroupVersionResource, error) {
+	r, ok := ducktypes.LookupType(impl)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a registered duck type", impl)
+	}
+	return d.implementing(r)
+}
+
+// ImplementingName is like Implementing, but looks the duck type up by
+// its registered name.
+func (d *Discoverer) ImplementingName(name string) ([]schema.GroupVersionResource, error) {
+	r, ok := ducktypes.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a registered duck type", name)
+	}
+	return d.implementing(r)
+}
+
+func (d *Discoverer) implementing(r ducktypes.Registration) ([]schema.GroupVersionResource, error) {
+	objs, err := d.informer.GetIndexer().ByIndex(duckLabelIndex, r.Label())
+	if err != nil {
+		return nil, err
+	}
+
+	gvrs := make([]schema.GroupVersionResource, 0, len(objs))
+	for _, obj := range objs {
+		crd := obj.(*unstructured.Unstructured)
+		found, err := servedResources(crd)
+		if err != nil {
+			return nil, fmt.Errorf("CRD %s: %w", crd.GetName(), err)
+		}
+		gvrs = append(gvrs, found...)
+	}
+	sort.Slice(gvrs, func(i, j int) bool {
+		return gvrs[i].String() < gvrs[j].String()
+	})
+	return append(append([]schema.GroupVersionResource{}, r.BuiltIns...), gvrs...), nil
+}
+
+func servedResources(crd *unstructured.Unstructured) ([]schema.GroupVersionResource, error) {
+	group, _, err := unstructured.NestedString(crd.Object, "spec", "group")
+	if err != nil {
+		return nil, err
+	}
+	plural, _, err := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	if err != nil {
+		return nil, err
+	}
+	versions, _, err := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if err != nil {
+		return nil, err
+	}
+
+	gvrs := make([]schema.GroupVersionResource, 0, len(versions))
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if served, _, _ := unstructured.NestedBool(version, "served"); !served {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		gvrs = append(gvrs, schema.GroupVersionResource{
+			Group:    group,
+			Version:  name,
+			Resource: plural,
+		})
+	}
+	return gvrs, nil
+}
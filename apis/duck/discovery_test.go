@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package duck_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/apis/duck"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	duckv1alpha1 "knative.dev/pkg/apis/duck/v1alpha1"
+)
+
+func crd(name, group, plural string, labels map[string]interface{}, versions ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": labels,
+		},
+		"spec": map[string]interface{}{
+			"group": group,
+			"names": map[string]interface{}{
+				"plural": plural,
+			},
+			"versions": versions,
+		},
+	}}
+}
+
+func version(name string, served bool) map[string]interface{} {
+	return map[string]interface{}{"name": name, "served": served}
+}
+
+func TestDiscoverer(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}: "CustomResourceDefinitionList",
+		},
+		crd("services.serving.knative.dev", "serving.knative.dev", "services",
+			map[string]interface{}{"duck.knative.dev/addressable": "true", "duck.knative.dev/podspecable": "true"},
+			version("v1", true), version("v1beta1", false)),
+		crd("brokers.eventing.knative.dev", "eventing.knative.dev", "brokers",
+			map[string]interface{}{"duck.knative.dev/addressable": "true"},
+			version("v1", true), version("v1beta1", true)),
+		crd("pingsources.sources.knative.dev", "sources.knative.dev", "pingsources",
+			map[string]interface{}{"duck.knative.dev/source": "true", "duck.knative.dev/addressable": "false"},
+			version("v1", true)),
+		crd("unrelated.example.com", "example.com", "unrelated", nil, version("v1", true)),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := duck.NewDiscoverer(client, 0)
+	go d.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), d.HasSynced) {
+		t.Fatal("Failed to sync the Discoverer")
+	}
+
+	got, err := d.Implementing(&duckv1.Addressable{})
+	if err != nil {
+		t.Fatal("Implementing() =", err)
+	}
+	want := []schema.GroupVersionResource{
+		{Group: "eventing.knative.dev", Version: "v1", Resource: "brokers"},
+		{Group: "eventing.knative.dev", Version: "v1beta1", Resource: "brokers"},
+		{Group: "serving.knative.dev", Version: "v1", Resource: "services"},
+	}
+	if !cmp.Equal(got, want) {
+		t.Error("Implementing (-want, +got) =", cmp.Diff(want, got))
+	}
+
+	got, err = d.ImplementingName("scalable")
+	if err != nil {
+		t.Fatal("ImplementingName() =", err)
+	}
+	want = []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "replicasets"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	}
+	if !cmp.Equal(got, want) {
+		t.Error("ImplementingName (-want, +got) =", cmp.Diff(want, got))
+	}
+
+	if _, err := d.Implementing(&duckv1alpha1.Addressable{}); err == nil {
+		t.Error("Implementing() = nil, wanted error for an unregistered duck type")
+	}
+	if _, err := d.ImplementingName("quackable"); err == nil {
+		t.Error("ImplementingName() = nil, wanted error for an unregistered duck type")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ducktypes
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Registration describes a duck type and how resources advertise that they
+// implement it. By convention, CRDs implementing the duck type named "foo"
+// carry the label duck.knative.dev/foo=true.
+type Registration struct {
+	// Name is the short name of the duck type, e.g. "addressable".
+	Name string
+
+	// Type is the duck type.
+	Type Implementable
+
+	// BuiltIns are the well-known resources implementing the duck type,
+	// which are not CRDs and thus cannot be labeled.
+	BuiltIns []schema.GroupVersionResource
+}
+
+// Label returns the CRD label marking implementations of the duck type.
+func (r Registration) Label() string {
+	return GroupName + "/" + r.Name
+}
+
+var (
+	registryMu sync.RWMutex
+	byName     = map[string]Registration{}
+	byType     = map[reflect.Type]Registration{}
+)
+
+// Register adds the duck type to the registry. It panics if a duck type
+// with the same name or Go type has already been registered, as that is a
+// programming error.
+func Register(r Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	t := reflect.TypeOf(r.Type)
+	if _, ok := byName[r.Name]; ok {
+		panic(fmt.Sprintf("duck type %q is already registered", r.Name))
+	}
+	if other, ok := byType[t]; ok {
+		panic(fmt.Sprintf("%v is already registered as duck type %q", t, other.Name))
+	}
+	byName[r.Name] = r
+	byType[t] = r
+}
+
+// Lookup returns the registration of the duck type with the given name.
+func Lookup(name string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := byName[name]
+	return r, ok
+}
+
+// LookupType returns the registration of the given duck type.
+func LookupType(impl Implementable) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := byType[reflect.TypeOf(impl)]
+	return r, ok
+}
+
+// Registered returns all registered duck types, ordered by name.
+func Registered() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	regs := make([]Registration, 0, len(byName))
+	for _, r := range byName {
+		regs = append(regs, r)
+	}
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].Name < regs[j].Name
+	})
+	return regs
+}
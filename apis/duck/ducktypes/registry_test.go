@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ducktypes
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fooable struct{}
+
+func (*fooable) GetFullType() Populatable { return nil }
+
+type barable struct{}
+
+func (*barable) GetFullType() Populatable { return nil }
+
+// register registers the duck type for the duration of the test.
+func register(t *testing.T, r Registration) {
+	t.Helper()
+	Register(r)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(byName, r.Name)
+		delete(byType, reflect.TypeOf(r.Type))
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	register(t, Registration{Name: "test-fooable", Type: &fooable{}})
+
+	r, ok := Lookup("test-fooable")
+	if !ok {
+		t.Fatal("Lookup() = false, want: true")
+	}
+	if got, want := r.Label(), "duck.knative.dev/test-fooable"; got != want {
+		t.Errorf("Label() = %q, want: %q", got, want)
+	}
+	if r, ok := LookupType(&fooable{}); !ok || r.Name != "test-fooable" {
+		t.Errorf("LookupType() = %v, %v", r, ok)
+	}
+	if _, ok := LookupType(&barable{}); ok {
+		t.Error("LookupType() = true for an unregistered type")
+	}
+	if _, ok := Lookup("test-barable"); ok {
+		t.Error("Lookup() = true for an unregistered name")
+	}
+
+	register(t, Registration{Name: "test-barable", Type: &barable{}})
+	regs := Registered()
+	for i := 1; i < len(regs); i++ {
+		if regs[i-1].Name >= regs[i].Name {
+			t.Errorf("Registered() is not sorted: %q before %q", regs[i-1].Name, regs[i].Name)
+		}
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	type dupable struct{ fooable }
+	register(t, Registration{Name: "test-dupable", Type: &dupable{}})
+
+	for name, r := range map[string]Registration{
+		"same name": {Name: "test-dupable", Type: &struct{ barable }{}},
+		"same type": {Name: "test-dupable-2", Type: &dupable{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register() did not panic")
+				}
+			}()
+			Register(r)
+		})
+	}
+}
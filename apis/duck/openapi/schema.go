@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi derives from the duck types the OpenAPI v3 schema
+// fragments which the CRDs implementing them must include.
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/apis/duck/ducktypes"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeMeta      = reflect.TypeOf(metav1.TypeMeta{})
+	objectMeta    = reflect.TypeOf(metav1.ObjectMeta{})
+
+	dateTime = apiextensionsv1.JSONSchemaProps{Type: "string", Format: "date-time"}
+
+	// knownTypes are the schemas of the types with a custom JSON encoding.
+	knownTypes = map[reflect.Type]apiextensionsv1.JSONSchemaProps{
+		reflect.TypeOf(apis.URL{}):               {Type: "string"},
+		reflect.TypeOf(apis.VolatileTime{}):      dateTime,
+		reflect.TypeOf(metav1.Time{}):            dateTime,
+		reflect.TypeOf(metav1.MicroTime{}):       dateTime,
+		reflect.TypeOf(metav1.Duration{}):        {Type: "string"},
+		reflect.TypeOf(intstr.IntOrString{}):     {XIntOrString: true},
+		reflect.TypeOf(corev1.PodSpec{}):         preserveUnknownFields(),
+		reflect.TypeOf(corev1.PodTemplateSpec{}): preserveUnknownFields(),
+		reflect.TypeOf(duckv1.PodSpecable{}):     preserveUnknownFields(),
+	}
+)
+
+// preserveUnknownFields returns the schema of the objects whose fields are
+// not pruned, for the types whose schema is too large to be minimal, e.g.
+// the PodSpec, or which have a custom JSON encoding.
+func preserveUnknownFields() apiextensionsv1.JSONSchemaProps {
+	preserve := true
+	return apiextensionsv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserve}
+}
+
+// For returns the minimal OpenAPI v3 schema of the resources implementing
+// the duck type: that of the fields of the spec and status of its full
+// type, which the schema of the CRDs implementing it must include.
+func For(impl ducktypes.Implementable) *apiextensionsv1.JSONSchemaProps {
+	t := reflect.TypeOf(impl.GetFullType())
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: make(map[string]apiextensionsv1.JSONSchemaProps),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type == typeMeta || f.Type == objectMeta {
+			continue
+		}
+		addField(schema, f, map[reflect.Type]bool{t: true})
+	}
+	return schema
+}
+
+// schemaOf returns the schema of the values of type t. The types being
+// visited are those of the enclosing fields, to stop at recursive types.
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) apiextensionsv1.JSONSchemaProps {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if known, ok := knownTypes[t]; ok {
+		return known
+	}
+	if visiting[t] || (t.Kind() == reflect.Struct && t.Implements(jsonMarshaler)) ||
+		(t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(jsonMarshaler)) {
+		return preserveUnknownFields()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return apiextensionsv1.JSONSchemaProps{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return apiextensionsv1.JSONSchemaProps{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return apiextensionsv1.JSONSchemaProps{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return apiextensionsv1.JSONSchemaProps{Type: "number"}
+	case reflect.String:
+		return apiextensionsv1.JSONSchemaProps{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return apiextensionsv1.JSONSchemaProps{Type: "string", Format: "byte"}
+		}
+		items := schemaOf(t.Elem(), visiting)
+		return apiextensionsv1.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &items},
+		}
+	case reflect.Map:
+		values := schemaOf(t.Elem(), visiting)
+		return apiextensionsv1.JSONSchemaProps{
+			Type:                 "object",
+			AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Allows: true, Schema: &values},
+		}
+	case reflect.Struct:
+		visiting[t] = true
+		defer delete(visiting, t)
+		schema := apiextensionsv1.JSONSchemaProps{
+			Type:       "object",
+			Properties: make(map[string]apiextensionsv1.JSONSchemaProps),
+		}
+		for i := 0; i < t.NumField(); i++ {
+			addField(&schema, t.Field(i), visiting)
+		}
+		return schema
+	default:
+		return preserveUnknownFields()
+	}
+}
+
+// addField adds the properties of the field to those of the object schema,
+// inlining the embedded structs.
+func addField(schema *apiextensionsv1.JSONSchemaProps, f reflect.StructField, visiting map[reflect.Type]bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		// Unexported fields are not encoded.
+		return
+	}
+	tag := f.Tag.Get("json")
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" && opts == "" {
+		return
+	}
+	inline := opts == "inline" || strings.HasPrefix(opts, "inline,") || (f.Anonymous && name == "")
+	if inline {
+		embedded := schemaOf(f.Type, visiting)
+		for prop, s := range embedded.Properties {
+			schema.Properties[prop] = s
+		}
+		return
+	}
+	if name == "" {
+		name = f.Name
+	}
+	schema.Properties[name] = schemaOf(f.Type, visiting)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"knative.dev/pkg/apis/duck/ducktypes"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestForCoversPopulatedFields(t *testing.T) {
+	for _, reg := range ducktypes.Registered() {
+		t.Run(reg.Name, func(t *testing.T) {
+			full := reg.Type.GetFullType()
+			full.Populate()
+			b, err := json.Marshal(full)
+			if err != nil {
+				t.Fatal("Marshal() =", err)
+			}
+			var obj map[string]interface{}
+			if err := json.Unmarshal(b, &obj); err != nil {
+				t.Fatal("Unmarshal() =", err)
+			}
+			delete(obj, "apiVersion")
+			delete(obj, "kind")
+			delete(obj, "metadata")
+
+			schema := For(reg.Type)
+			if schema.Type != "object" {
+				t.Errorf("Type = %q, wanted object", schema.Type)
+			}
+			checkCovered(t, "", *schema, obj)
+		})
+	}
+}
+
+// checkCovered checks that the schema covers all the fields of the value,
+// i.e. that they would not be pruned by the API server.
+func checkCovered(t *testing.T, path string, schema apiextensionsv1.JSONSchemaProps, value interface{}) {
+	t.Helper()
+	if schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields {
+		return
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, fv := range v {
+			if s, ok := schema.Properties[k]; ok {
+				checkCovered(t, path+"."+k, s, fv)
+			} else if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+				checkCovered(t, path+"."+k, *schema.AdditionalProperties.Schema, fv)
+			} else {
+				t.Errorf("The field %s%s is not in the schema", path, "."+k)
+			}
+		}
+	case []interface{}:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			t.Errorf("The field %s has no items schema", path)
+			return
+		}
+		for _, item := range v {
+			checkCovered(t, path+"[]", *schema.Items.Schema, item)
+		}
+	}
+}
+
+func TestForSchemas(t *testing.T) {
+	schema := For(&duckv1.PodSpecable{})
+	spec, ok := schema.Properties["spec"]
+	if !ok {
+		t.Fatal("The spec is not in the PodSpecable schema")
+	}
+	template := spec.Properties["template"]
+	if template.XPreserveUnknownFields == nil || !*template.XPreserveUnknownFields {
+		t.Errorf("spec.template = %+v, wanted the unknown fields preserved", template)
+	}
+
+	schema = For(&duckv1.Source{})
+	if got, want := schema.Properties["status"].Properties["sinkUri"].Type, "string"; got != want {
+		t.Errorf("status.sinkUri type = %q, wanted %q", got, want)
+	}
+	lastTransitionTime := schema.Properties["status"].Properties["conditions"].Items.Schema.Properties["lastTransitionTime"]
+	if lastTransitionTime.Type != "string" || lastTransitionTime.Format != "date-time" {
+		t.Errorf("lastTransitionTime = %+v, wanted a date-time string", lastTransitionTime)
+	}
+}
@@ -18,6 +18,7 @@ package duck
 
 import (
 	"encoding/json"
+	"fmt"
 
 	jsonmergepatch "github.com/evanphx/json-patch/v5"
 	jsonpatch "gomodules.xyz/jsonpatch/v2"
@@ -71,3 +72,43 @@ type JSONPatch []jsonpatch.JsonPatchOperation
 func (p JSONPatch) MarshalJSON() ([]byte, error) {
 	return json.Marshal([]jsonpatch.JsonPatchOperation(p))
 }
+
+// MinimizePatch returns the smallest of the given patch of the doc and of
+// the patch recomputed from the doc it results in. The recomputed patch
+// drops the operations without effect and collapses the ones on the same
+// paths, e.g. when a value set by an operation is replaced by a later one,
+// which lowers the cost of applying the patch and the size of its records.
+// The order of the operations of the recomputed patch is unspecified, and
+// may differ between calls for the same doc and patch.
+func MinimizePatch(doc []byte, patch JSONPatch) (JSONPatch, error) {
+	if len(patch) == 0 {
+		return patch, nil
+	}
+	rawPatch, err := patch.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := jsonmergepatch.DecodePatch(rawPatch)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode patch: %w", err)
+	}
+	patched, err := decoded.Apply(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot apply patch: %w", err)
+	}
+	minimized, err := jsonpatch.CreatePatch(doc, patched)
+	if err != nil {
+		return nil, err
+	}
+
+	// The recomputed patch may be larger, e.g. as it does not move array
+	// items but replaces them.
+	rawMinimized, err := json.Marshal(minimized)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawMinimized) >= len(rawPatch) {
+		return patch, nil
+	}
+	return minimized, nil
+}
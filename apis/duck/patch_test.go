@@ -22,6 +22,8 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -377,6 +379,93 @@ func TestPatchToJSON(t *testing.T) {
 	}
 }
 
+func TestMinimizePatch(t *testing.T) {
+	doc := []byte(`{"spec":{"replicas":1,"args":["a","b","c","d","e","f"]}}`)
+
+	tests := []struct {
+		name    string
+		patch   JSONPatch
+		want    JSONPatch
+		wantErr bool
+	}{{
+		name: "empty",
+	}, {
+		name: "no-op",
+		patch: JSONPatch{{
+			Operation: "replace",
+			Path:      "/spec/replicas",
+			Value:     1,
+		}},
+		want: JSONPatch{},
+	}, {
+		name: "collapsed",
+		patch: JSONPatch{{
+			Operation: "add",
+			Path:      "/metadata",
+			Value:     map[string]interface{}{},
+		}, {
+			Operation: "add",
+			Path:      "/metadata/labels",
+			Value:     map[string]interface{}{},
+		}, {
+			Operation: "add",
+			Path:      "/metadata/labels/foo",
+			Value:     "bar",
+		}, {
+			Operation: "replace",
+			Path:      "/spec/replicas",
+			Value:     2,
+		}, {
+			Operation: "replace",
+			Path:      "/spec/replicas",
+			Value:     3,
+		}},
+		want: JSONPatch{{
+			Operation: "add",
+			Path:      "/metadata",
+			Value: map[string]interface{}{
+				"labels": map[string]interface{}{"foo": "bar"},
+			},
+		}, {
+			Operation: "replace",
+			Path:      "/spec/replicas",
+			Value:     3.0,
+		}},
+	}, {
+		name: "smaller as it is",
+		patch: JSONPatch{{
+			Operation: "remove",
+			Path:      "/spec/args/0",
+		}},
+		want: JSONPatch{{
+			Operation: "remove",
+			Path:      "/spec/args/0",
+		}},
+	}, {
+		name: "not applicable",
+		patch: JSONPatch{{
+			Operation: "replace",
+			Path:      "/status/foo",
+			Value:     1,
+		}},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := MinimizePatch(doc, test.patch)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("MinimizePatch() = %v, wanted error: %v", err, test.wantErr)
+			}
+			// The operations on different paths are recomputed in no particular order.
+			byPath := cmpopts.SortSlices(func(a, b jsonpatch.JsonPatchOperation) bool { return a.Path < b.Path })
+			if !cmp.Equal(got, test.want, byPath) {
+				t.Error("MinimizePatch() (-want, +got):", cmp.Diff(test.want, got, byPath))
+			}
+		})
+	}
+}
+
 type DoesntMarshal struct{}
 
 var _ json.Marshaler = (*DoesntMarshal)(nil)
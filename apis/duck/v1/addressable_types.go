@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -48,6 +49,12 @@ type Addressable struct {
 	// according to https://www.rfc-editor.org/rfc/rfc7468.
 	// +optional
 	CACerts *string `json:"CACerts,omitempty"`
+
+	// Ready is a hint of whether the address is serviceable, e.g. whether
+	// the HTTPS address has its certificate provisioned. Addresses without
+	// this hint are assumed to be ready.
+	// +optional
+	Ready *corev1.ConditionStatus `json:"ready,omitempty"`
 }
 
 var (
@@ -82,6 +89,30 @@ type AddressStatus struct {
 	Addresses []Addressable `json:"addresses,omitempty"`
 }
 
+// IsReady returns whether the address is serviceable, that is whether it
+// has no readiness hint or its hint is True.
+func (a *Addressable) IsReady() bool {
+	return a.Ready == nil || *a.Ready == corev1.ConditionTrue
+}
+
+// ReadyAddresses returns the addresses which are serviceable. When Addresses
+// is present, it is filtered, otherwise Address is considered.
+func (as *AddressStatus) ReadyAddresses() []Addressable {
+	if len(as.Addresses) == 0 {
+		if as.Address != nil && as.Address.IsReady() {
+			return []Addressable{*as.Address}
+		}
+		return nil
+	}
+	var ready []Addressable
+	for _, a := range as.Addresses {
+		if a.IsReady() {
+			ready = append(ready, a)
+		}
+	}
+	return ready
+}
+
 // Verify AddressableType resources meet duck contracts.
 var (
 	_ apis.Listable         = (*AddressableType)(nil)
@@ -107,15 +138,21 @@ func (a *Addressable) ConvertFrom(ctx context.Context, from apis.Convertible) er
 // Populate implements duck.Populatable
 func (t *AddressableType) Populate() {
 	name := "http"
-	t.Status = AddressStatus{
-		Address: &Addressable{
-			// Populate ALL fields
-			Name: &name,
-			URL: &apis.URL{
-				Scheme: "http",
-				Host:   "foo.com",
-			},
+	ready := corev1.ConditionTrue
+	caCerts := "-----BEGIN CERTIFICATE-----"
+	address := Addressable{
+		// Populate ALL fields
+		Name: &name,
+		URL: &apis.URL{
+			Scheme: "http",
+			Host:   "foo.com",
 		},
+		CACerts: &caCerts,
+		Ready:   &ready,
+	}
+	t.Status = AddressStatus{
+		Address:   &address,
+		Addresses: []Addressable{address},
 	}
 }
 
@@ -21,7 +21,10 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
 	"knative.dev/pkg/apis"
+	"knative.dev/pkg/ptr"
 )
 
 func TestConversion(t *testing.T) {
@@ -72,3 +75,59 @@ func TestConversion(t *testing.T) {
 		})
 	}
 }
+
+func TestReadyAddresses(t *testing.T) {
+	ready, notReady, unknown := corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown
+	http := Addressable{Name: ptr.String("http"), URL: apis.HTTP("foo.com")}
+	https := Addressable{Name: ptr.String("https"), URL: apis.HTTPS("foo.com")}
+	withReady := func(a Addressable, status *corev1.ConditionStatus) Addressable {
+		a.Ready = status
+		return a
+	}
+
+	tests := []struct {
+		name   string
+		status AddressStatus
+		want   []Addressable
+	}{{
+		name: "empty",
+	}, {
+		name:   "address without hint",
+		status: AddressStatus{Address: &http},
+		want:   []Addressable{http},
+	}, {
+		name:   "address not ready",
+		status: AddressStatus{Address: &Addressable{URL: apis.HTTP("foo.com"), Ready: &notReady}},
+	}, {
+		name: "addresses take precedence",
+		status: AddressStatus{
+			Address:   &http,
+			Addresses: []Addressable{withReady(https, &ready)},
+		},
+		want: []Addressable{withReady(https, &ready)},
+	}, {
+		name: "filters addresses",
+		status: AddressStatus{
+			Addresses: []Addressable{
+				withReady(http, &ready),
+				withReady(https, &notReady),
+				withReady(https, &unknown),
+				https,
+			},
+		},
+		want: []Addressable{withReady(http, &ready), https},
+	}, {
+		name: "none ready",
+		status: AddressStatus{
+			Addresses: []Addressable{withReady(https, &notReady)},
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.status.ReadyAddresses(); !cmp.Equal(got, tc.want) {
+				t.Error("ReadyAddresses (-want, +got) =", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
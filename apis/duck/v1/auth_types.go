@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"knative.dev/pkg/apis"
+)
+
+// AuthStatus is meant to be the generic Auth status of a resource, which
+// publishes the identities its data plane authenticates as.
+type AuthStatus struct {
+	// ServiceAccountName is the name of the service account used for this
+	// component's authentication. It is kept for consumers that only
+	// understand a single identity, and mirrors the default identity when
+	// Identities is set.
+	// +optional
+	ServiceAccountName *string `json:"serviceAccountName,omitempty"`
+
+	// Identities is the list of identities used by this component's data
+	// plane, each possibly scoped to the audience of one of its addresses.
+	// If Identities is present, ServiceAccountName must be ignored by clients.
+	// +optional
+	Identities []AuthIdentity `json:"identities,omitempty"`
+}
+
+// AuthIdentity is a single identity a data plane authenticates as.
+type AuthIdentity struct {
+	// ServiceAccountName is the name of the service account backing the
+	// identity.
+	ServiceAccountName string `json:"serviceAccountName"`
+
+	// Audience is the audience of the address this identity is used for.
+	// Identities without an audience are used for any address that has no
+	// more specific identity.
+	// +optional
+	Audience *string `json:"audience,omitempty"`
+
+	// Roles are hints about what the identity is used for, e.g. "sender"
+	// or "receiver". They are not interpreted by this package.
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+}
+
+// GetIdentities returns the identities of the AuthStatus, treating a lone
+// ServiceAccountName as a single identity used for any audience.
+func (a *AuthStatus) GetIdentities() []AuthIdentity {
+	if a == nil {
+		return nil
+	}
+	if len(a.Identities) > 0 {
+		return a.Identities
+	}
+	if a.ServiceAccountName != nil {
+		return []AuthIdentity{{ServiceAccountName: *a.ServiceAccountName}}
+	}
+	return nil
+}
+
+// IdentityFor returns the identity to use for the given audience: the one
+// scoped to the audience if any, else the default identity, else nil.
+func (a *AuthStatus) IdentityFor(audience string) *AuthIdentity {
+	var fallback *AuthIdentity
+	ids := a.GetIdentities()
+	for i := range ids {
+		switch {
+		case ids[i].Audience == nil:
+			if fallback == nil {
+				fallback = &ids[i]
+			}
+		case *ids[i].Audience == audience:
+			return &ids[i]
+		}
+	}
+	return fallback
+}
+
+// SetIdentities sets the identities of the AuthStatus, and keeps
+// ServiceAccountName pointing at the default identity (or the first one,
+// when all identities are scoped) for single identity consumers.
+func (a *AuthStatus) SetIdentities(ids ...AuthIdentity) {
+	a.Identities = ids
+	a.ServiceAccountName = nil
+	if len(ids) == 0 {
+		return
+	}
+	def := &ids[0]
+	for i := range ids {
+		if ids[i].Audience == nil {
+			def = &ids[i]
+			break
+		}
+	}
+	name := def.ServiceAccountName
+	a.ServiceAccountName = &name
+}
+
+// HasRole returns whether the identity carries the given role hint.
+func (i *AuthIdentity) HasRole(role string) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate the AuthStatus: every identity must name a service account, and
+// at most one identity may be used per audience.
+func (a *AuthStatus) Validate(ctx context.Context) *apis.FieldError {
+	if a == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	seen := make(map[string]struct{}, len(a.Identities))
+	defaults := 0
+	for i, id := range a.Identities {
+		if id.ServiceAccountName == "" {
+			errs = errs.Also(apis.ErrMissingField("serviceAccountName").ViaFieldIndex("identities", i))
+		}
+		if id.Audience == nil {
+			defaults++
+			if defaults > 1 {
+				errs = errs.Also(apis.ErrGeneric("only one identity may omit the audience", "audience").ViaFieldIndex("identities", i))
+			}
+			continue
+		}
+		if _, ok := seen[*id.Audience]; ok {
+			errs = errs.Also(apis.ErrInvalidValue(*id.Audience, "audience", "duplicate audience").ViaFieldIndex("identities", i))
+		}
+		seen[*id.Audience] = struct{}{}
+	}
+	return errs
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+
+	"knative.dev/pkg/apis"
+)
+
+func TestAuthStatusGetIdentities(t *testing.T) {
+	tests := []struct {
+		name string
+		auth *AuthStatus
+		want []AuthIdentity
+	}{{
+		name: "nil",
+	}, {
+		name: "empty",
+		auth: &AuthStatus{},
+	}, {
+		name: "single service account",
+		auth: &AuthStatus{ServiceAccountName: pointer.String("sa")},
+		want: []AuthIdentity{{ServiceAccountName: "sa"}},
+	}, {
+		name: "identities take precedence",
+		auth: &AuthStatus{
+			ServiceAccountName: pointer.String("old"),
+			Identities:         []AuthIdentity{{ServiceAccountName: "new"}},
+		},
+		want: []AuthIdentity{{ServiceAccountName: "new"}},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.auth.GetIdentities(); !cmp.Equal(got, tc.want) {
+				t.Error("GetIdentities (-want, +got) =", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestAuthStatusIdentityFor(t *testing.T) {
+	auth := &AuthStatus{}
+	auth.SetIdentities(AuthIdentity{
+		ServiceAccountName: "https-sa",
+		Audience:           pointer.String("https-audience"),
+		Roles:              []string{"receiver"},
+	}, AuthIdentity{
+		ServiceAccountName: "default-sa",
+	})
+
+	if got, want := *auth.ServiceAccountName, "default-sa"; got != want {
+		t.Errorf("ServiceAccountName = %q, want: %q", got, want)
+	}
+
+	tests := []struct {
+		audience string
+		want     string
+	}{{
+		audience: "https-audience",
+		want:     "https-sa",
+	}, {
+		audience: "http-audience",
+		want:     "default-sa",
+	}, {
+		audience: "",
+		want:     "default-sa",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.audience, func(t *testing.T) {
+			id := auth.IdentityFor(tc.audience)
+			if id == nil {
+				t.Fatal("IdentityFor() = nil")
+			}
+			if id.ServiceAccountName != tc.want {
+				t.Errorf("IdentityFor() = %q, want: %q", id.ServiceAccountName, tc.want)
+			}
+		})
+	}
+
+	if id := auth.IdentityFor("https-audience"); !id.HasRole("receiver") || id.HasRole("sender") {
+		t.Errorf("Roles = %v, want: [receiver]", id.Roles)
+	}
+
+	scoped := &AuthStatus{}
+	scoped.SetIdentities(AuthIdentity{
+		ServiceAccountName: "scoped-sa",
+		Audience:           pointer.String("audience"),
+	})
+	if id := scoped.IdentityFor("other"); id != nil {
+		t.Errorf("IdentityFor() = %v, want: nil", id)
+	}
+	if got, want := *scoped.ServiceAccountName, "scoped-sa"; got != want {
+		t.Errorf("ServiceAccountName = %q, want: %q", got, want)
+	}
+
+	scoped.SetIdentities()
+	if scoped.ServiceAccountName != nil || scoped.Identities != nil {
+		t.Errorf("SetIdentities() = %#v, want empty", scoped)
+	}
+}
+
+func TestAuthStatusSingleIdentityCompat(t *testing.T) {
+	// Status written by a single identity publisher is read as a default
+	// identity, and status written with identities is still readable by
+	// single identity consumers.
+	var auth AuthStatus
+	if err := json.Unmarshal([]byte(`{"serviceAccountName":"sa"}`), &auth); err != nil {
+		t.Fatal("Unmarshal() =", err)
+	}
+	if id := auth.IdentityFor("anything"); id == nil || id.ServiceAccountName != "sa" {
+		t.Errorf("IdentityFor() = %v, want: sa", id)
+	}
+
+	auth.SetIdentities(AuthIdentity{ServiceAccountName: "sa", Roles: []string{"sender"}})
+	b, err := json.Marshal(auth)
+	if err != nil {
+		t.Fatal("Marshal() =", err)
+	}
+	var old struct {
+		ServiceAccountName string `json:"serviceAccountName"`
+	}
+	if err := json.Unmarshal(b, &old); err != nil {
+		t.Fatal("Unmarshal() =", err)
+	}
+	if old.ServiceAccountName != "sa" {
+		t.Errorf("serviceAccountName = %q, want: sa", old.ServiceAccountName)
+	}
+}
+
+func TestAuthStatusValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		auth *AuthStatus
+		want *apis.FieldError
+	}{{
+		name: "nil",
+	}, {
+		name: "single service account",
+		auth: &AuthStatus{ServiceAccountName: pointer.String("sa")},
+	}, {
+		name: "valid identities",
+		auth: &AuthStatus{Identities: []AuthIdentity{{
+			ServiceAccountName: "a",
+			Audience:           pointer.String("a"),
+		}, {
+			ServiceAccountName: "b",
+			Audience:           pointer.String("b"),
+		}, {
+			ServiceAccountName: "default",
+		}}},
+	}, {
+		name: "missing service account",
+		auth: &AuthStatus{Identities: []AuthIdentity{{}}},
+		want: apis.ErrMissingField("identities[0].serviceAccountName"),
+	}, {
+		name: "duplicate audience",
+		auth: &AuthStatus{Identities: []AuthIdentity{{
+			ServiceAccountName: "a",
+			Audience:           pointer.String("a"),
+		}, {
+			ServiceAccountName: "b",
+			Audience:           pointer.String("a"),
+		}}},
+		want: apis.ErrInvalidValue("a", "identities[1].audience", "duplicate audience"),
+	}, {
+		name: "multiple defaults",
+		auth: &AuthStatus{Identities: []AuthIdentity{{
+			ServiceAccountName: "a",
+		}, {
+			ServiceAccountName: "b",
+		}}},
+		want: apis.ErrGeneric("only one identity may omit the audience", "identities[1].audience"),
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.auth.Validate(context.Background())
+			if got.Error() != tc.want.Error() {
+				t.Errorf("Validate() = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
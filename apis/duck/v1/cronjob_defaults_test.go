@@ -106,3 +106,35 @@ func TestCronJobDefaulting(t *testing.T) {
 		})
 	}
 }
+
+func TestCronJobDeepCopyWithDefaults(t *testing.T) {
+	c := &CronJob{
+		Spec: batchv1.CronJobSpec{
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Name:  "blah",
+								Image: "busybox",
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	want := c.DeepCopy()
+
+	ctx := WithCronJobDefaulter(context.Background(), func(ctx context.Context, c *CronJob) {
+		c.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image = "busybox@sha256:deadbeef"
+	})
+	got := c.DeepCopyWithDefaults(ctx)
+
+	if got, want := got.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image, "busybox@sha256:deadbeef"; got != want {
+		t.Errorf("DeepCopyWithDefaults() image = %s, want: %s", got, want)
+	}
+	if !cmp.Equal(want, c) {
+		t.Error("DeepCopyWithDefaults() modified the receiver (-want, +got) =", cmp.Diff(want, c))
+	}
+}
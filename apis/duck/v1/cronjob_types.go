@@ -28,6 +28,7 @@ import (
 
 // +genduck
 
+// +gendefaults
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // CronJob is a wrapper around CronJob resource, which supports our interfaces
@@ -24,6 +24,8 @@ import (
 	"knative.dev/pkg/apis"
 )
 
+// +gendefaults
+
 // Destination represents a target of an invocation over HTTP.
 type Destination struct {
 	// Ref points to an Addressable.
@@ -71,7 +73,12 @@ func ValidateDestination(ctx context.Context, dest Destination) *apis.FieldError
 		return ref.Validate(ctx).ViaField("ref")
 	}
 	if caCerts != nil {
-		return validateCACerts(caCerts)
+		if err := validateCACerts(caCerts); err != nil {
+			return err
+		}
+	}
+	if URITemplatesEnabled(ctx) {
+		return validateURITemplate(uri)
 	}
 	return nil
 }
@@ -85,14 +92,22 @@ func (d *Destination) GetRef() *KReference {
 	return d.Ref
 }
 
+// SetDefaults defaults the namespace of the Ref to the namespace of the
+// parent object (using apis.ParentMeta), and upgrades the http URI of a
+// cluster-local service to https, as configured by the DestinationDefaults
+// of the context.
 func (d *Destination) SetDefaults(ctx context.Context) {
 	if d == nil {
 		return
 	}
+	defaults := GetDestinationDefaults(ctx)
 
-	if d.Ref != nil && d.Ref.Namespace == "" {
+	if d.Ref != nil && d.Ref.Namespace == "" && !defaults.SkipRefNamespace {
 		d.Ref.Namespace = apis.ParentMeta(ctx).Namespace
 	}
+	if defaults.ClusterLocalHTTPS && d.URI != nil && d.URI.Scheme == "http" && IsClusterLocal(d.URI) {
+		d.URI.Scheme = "https"
+	}
 }
 
 func validateCACerts(CACert *string) *apis.FieldError {
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/network"
+)
+
+// DestinationDefaults configures the defaulting of the Destinations by
+// SetDefaults. The webhooks apply it uniformly by associating it with the
+// context of the defaulting of their resources.
+type DestinationDefaults struct {
+	// SkipRefNamespace leaves the namespace of the refs empty, rather than
+	// defaulting it to the namespace of the parent.
+	SkipRefNamespace bool
+
+	// ClusterLocalHTTPS upgrades the http URIs of cluster-local services
+	// to https, for clusters whose policy requires TLS between services.
+	ClusterLocalHTTPS bool
+}
+
+// destinationDefaultsKey is used for associating the DestinationDefaults
+// with a context.Context.
+type destinationDefaultsKey struct{}
+
+// WithDestinationDefaults associates the defaulting configuration of the
+// Destinations with the context.
+func WithDestinationDefaults(ctx context.Context, defaults DestinationDefaults) context.Context {
+	return context.WithValue(ctx, destinationDefaultsKey{}, defaults)
+}
+
+// GetDestinationDefaults returns the defaulting configuration of the
+// Destinations associated with the context, the zero value otherwise.
+func GetDestinationDefaults(ctx context.Context) DestinationDefaults {
+	defaults, _ := ctx.Value(destinationDefaultsKey{}).(DestinationDefaults)
+	return defaults
+}
+
+// IsClusterLocal returns whether the URL is absolute and points to a
+// service of the cluster, e.g. http://foo.bar.svc.cluster.local.
+func IsClusterLocal(u *apis.URL) bool {
+	if u == nil || !u.URL().IsAbs() {
+		return false
+	}
+	host := strings.TrimSuffix(u.URL().Hostname(), ".")
+	return strings.HasSuffix(host, ".svc") ||
+		strings.HasSuffix(host, ".svc."+network.GetClusterDomainName())
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// uriTemplatesKey is used for associating the opt-in to URI templates
+// with a context.Context.
+type uriTemplatesKey struct{}
+
+// WithURITemplates opts the Destinations validated with the returned context
+// into URI templates: their URI may then contain RFC 6570 expressions, e.g.
+// `/events/{type}`, which are checked for syntax and left to be expanded by
+// the resolver with ExpandURITemplate.
+func WithURITemplates(ctx context.Context) context.Context {
+	return context.WithValue(ctx, uriTemplatesKey{}, struct{}{})
+}
+
+// URITemplatesEnabled returns whether the context opted into URI templates.
+func URITemplatesEnabled(ctx context.Context) bool {
+	return ctx.Value(uriTemplatesKey{}) != nil
+}
+
+// uriTemplateBraces restores the braces of the template expressions which
+// apis.URL escapes in paths and fragments. Hence templated URIs can't contain
+// literal braces.
+var uriTemplateBraces = strings.NewReplacer("%7B", "{", "%7b", "{", "%7D", "}", "%7d", "}")
+
+// IsURITemplate returns whether the URL contains URI template expressions.
+func IsURITemplate(u *apis.URL) bool {
+	return u != nil && strings.ContainsAny(uriTemplateBraces.Replace(u.String()), "{}")
+}
+
+// URITemplateVariables returns the names of the variables referenced by the
+// URI template, in order of first appearance, or an error if the template is
+// malformed.
+//
+// The expressions of RFC 6570 levels 1 and 2 are supported: simple string
+// expansion `{var}`, reserved expansion `{+var}` and fragment expansion
+// `{#var}`, each possibly referencing a comma separated list of variables.
+func URITemplateVariables(u *apis.URL) ([]string, error) {
+	if u == nil {
+		return nil, nil
+	}
+	var names []string
+	seen := make(map[string]struct{})
+	_, err := expandURITemplate(uriTemplateBraces.Replace(u.String()), func(name string) (string, bool) {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+		return "", true
+	})
+	return names, err
+}
+
+// ExpandURITemplate returns the URL resulting from expanding the URI template
+// with the given variables. Unlike RFC 6570, referencing an undefined
+// variable is an error, so that events aren't silently sent to the wrong
+// place. URLs without expressions are returned as is.
+func ExpandURITemplate(u *apis.URL, vars map[string]string) (*apis.URL, error) {
+	if !IsURITemplate(u) {
+		return u, nil
+	}
+	var missing []string
+	expanded, err := expandURITemplate(uriTemplateBraces.Replace(u.String()), func(name string) (string, bool) {
+		v, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v, ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("undefined URI template variables: %s", strings.Join(missing, ", "))
+	}
+	return apis.ParseURL(expanded)
+}
+
+// validateURITemplate validates the syntax of the URI template expressions.
+func validateURITemplate(u *apis.URL) *apis.FieldError {
+	if _, err := URITemplateVariables(u); err != nil {
+		return apis.ErrInvalidValue(err.Error(), "uri")
+	}
+	return nil
+}
+
+// expandURITemplate expands the expressions of the template, looking the
+// variables up with lookup.
+func expandURITemplate(template string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.IndexAny(template, "{}")
+		if start < 0 {
+			b.WriteString(template)
+			return b.String(), nil
+		}
+		if template[start] == '}' {
+			return "", fmt.Errorf("unexpected '}' at %q", template[start:])
+		}
+		end := strings.IndexAny(template[start+1:], "{}")
+		if end < 0 || template[start+1+end] == '{' {
+			return "", fmt.Errorf("unterminated expression at %q", template[start:])
+		}
+		end += start + 1
+
+		b.WriteString(template[:start])
+		if err := expandExpression(&b, template[start+1:end], lookup); err != nil {
+			return "", err
+		}
+		template = template[end+1:]
+	}
+}
+
+func expandExpression(b *strings.Builder, expr string, lookup func(string) (string, bool)) error {
+	prefix, reserved := "", false
+	switch {
+	case strings.HasPrefix(expr, "+"):
+		expr, reserved = expr[1:], true
+	case strings.HasPrefix(expr, "#"):
+		expr, prefix, reserved = expr[1:], "#", true
+	}
+
+	values := make([]string, 0, strings.Count(expr, ",")+1)
+	for _, name := range strings.Split(expr, ",") {
+		if !isURITemplateVarName(name) {
+			return fmt.Errorf("invalid variable name %q in expression {%s}", name, expr)
+		}
+		v, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if reserved {
+			values = append(values, escapeURITemplateValue(v, uriUnreserved+uriReserved))
+		} else {
+			values = append(values, escapeURITemplateValue(v, uriUnreserved))
+		}
+	}
+	if len(values) > 0 {
+		b.WriteString(prefix)
+		b.WriteString(strings.Join(values, ","))
+	}
+	return nil
+}
+
+// isURITemplateVarName returns whether name is a valid RFC 6570 varname,
+// ignoring percent encoded characters.
+func isURITemplateVarName(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	// uriUnreserved are the characters left as is by simple expansion.
+	uriUnreserved = "-._~"
+	// uriReserved are the characters additionally left as is by reserved
+	// and fragment expansion.
+	uriReserved = ":/?#[]@!$&'()*+,;="
+)
+
+// escapeURITemplateValue percent encodes the value, leaving alphanumerics
+// and the given characters untouched.
+func escapeURITemplateValue(v, allowed string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte(allowed, c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
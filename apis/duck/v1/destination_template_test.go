@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/pkg/apis"
+)
+
+func mustParseURL(t *testing.T, s string) *apis.URL {
+	t.Helper()
+	u, err := apis.ParseURL(s)
+	if err != nil {
+		t.Fatalf("ParseURL(%q) = %v", s, err)
+	}
+	return u
+}
+
+func TestURITemplateVariables(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    []string
+		wantErr bool
+	}{{
+		uri: "http://example.com/foo",
+	}, {
+		uri:  "http://example.com/events/{type}",
+		want: []string{"type"},
+	}, {
+		uri:  "http://example.com/{+base}/events/{type}?source={source,type}",
+		want: []string{"base", "type", "source"},
+	}, {
+		uri:  "/relative/{ns}/{name.first}",
+		want: []string{"ns", "name.first"},
+	}, {
+		uri:     "http://example.com/events/{type",
+		wantErr: true,
+	}, {
+		uri:     "http://example.com/events/type}",
+		wantErr: true,
+	}, {
+		uri:     "http://example.com/events/{{type}}",
+		wantErr: true,
+	}, {
+		uri:     "http://example.com/events/{}",
+		wantErr: true,
+	}, {
+		uri:     "http://example.com/events/{ty-pe}",
+		wantErr: true,
+	}, {
+		uri:     "http://example.com/events/{;type}",
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.uri, func(t *testing.T) {
+			got, err := URITemplateVariables(mustParseURL(t, tc.uri))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("URITemplateVariables() = %v, wantErr: %v", err, tc.wantErr)
+			}
+			if !cmp.Equal(got, tc.want) && !tc.wantErr {
+				t.Error("URITemplateVariables (-want, +got) =", cmp.Diff(tc.want, got))
+			}
+			if got, want := IsURITemplate(mustParseURL(t, tc.uri)), len(tc.want) > 0 || tc.wantErr; got != want {
+				t.Errorf("IsURITemplate() = %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestExpandURITemplate(t *testing.T) {
+	vars := map[string]string{
+		"type":   "dev.knative.foo",
+		"source": "/apis/v1?x=y",
+		"base":   "a/b",
+		"space":  "hello world",
+	}
+	tests := []struct {
+		uri     string
+		want    string
+		wantErr string
+	}{{
+		uri:  "http://example.com/foo",
+		want: "http://example.com/foo",
+	}, {
+		uri:  "http://example.com/events/{type}",
+		want: "http://example.com/events/dev.knative.foo",
+	}, {
+		uri:  "http://example.com/{space}",
+		want: "http://example.com/hello%20world",
+	}, {
+		uri:  "http://example.com/{+base}/{base}",
+		want: "http://example.com/a/b/a%2Fb",
+	}, {
+		uri:  "http://example.com/?s={source}",
+		want: "http://example.com/?s=%2Fapis%2Fv1%3Fx%3Dy",
+	}, {
+		uri:  "http://example.com/x{#type,base}",
+		want: "http://example.com/x#dev.knative.foo,a/b",
+	}, {
+		uri:     "http://example.com/{type}/{name}/{ns}",
+		wantErr: "undefined URI template variables: name, ns",
+	}, {
+		uri:     "http://example.com/{type",
+		wantErr: `unterminated expression at "{type"`,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.uri, func(t *testing.T) {
+			got, err := ExpandURITemplate(mustParseURL(t, tc.uri), vars)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("ExpandURITemplate() = %v, want error: %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("ExpandURITemplate() =", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("ExpandURITemplate() = %s, want: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateDestinationURITemplate(t *testing.T) {
+	tests := map[string]struct {
+		ctx  context.Context
+		uri  string
+		want string
+	}{"template without opt-in": {
+		ctx: context.Background(),
+		uri: "http://example.com/events/{type",
+	}, "valid template": {
+		ctx: WithURITemplates(context.Background()),
+		uri: "http://example.com/events/{type}",
+	}, "invalid template": {
+		ctx:  WithURITemplates(context.Background()),
+		uri:  "http://example.com/events/{type",
+		want: `invalid value: unterminated expression at "{type": uri`,
+	}}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dest := &Destination{URI: mustParseURL(t, tc.uri)}
+			gotErr := dest.Validate(tc.ctx)
+			if tc.want != "" {
+				if got := gotErr.Error(); got != tc.want {
+					t.Errorf("Error() = %v, wanted %v", got, tc.want)
+				}
+			} else if gotErr != nil {
+				t.Errorf("Validate() = %v, wanted nil", gotErr)
+			}
+		})
+	}
+}
@@ -276,6 +276,11 @@ func TestDestinationSetDefaults(t *testing.T) {
 		d:    &Destination{Ref: &KReference{}, URI: apis.HTTP("example.com")},
 		ctx:  apis.WithinParent(ctx, metav1.ObjectMeta{Namespace: parentNamespace}),
 		want: parentNamespace,
+	}, "namespace not set, context set, skipped": {
+		d: &Destination{Ref: &KReference{}},
+		ctx: WithDestinationDefaults(apis.WithinParent(ctx, metav1.ObjectMeta{Namespace: parentNamespace}),
+			DestinationDefaults{SkipRefNamespace: true}),
+		want: "",
 	}}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -291,3 +296,53 @@ func TestDestinationSetDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestDestinationSetDefaultsClusterLocalHTTPS(t *testing.T) {
+	ctx := WithDestinationDefaults(context.Background(), DestinationDefaults{ClusterLocalHTTPS: true})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		uri  *apis.URL
+		want string
+	}{{
+		name: "cluster-local service, upgraded",
+		ctx:  ctx,
+		uri:  apis.HTTP("foo.bar.svc.cluster.local"),
+		want: "https://foo.bar.svc.cluster.local",
+	}, {
+		name: "short cluster-local service, upgraded",
+		ctx:  ctx,
+		uri:  &apis.URL{Scheme: "http", Host: "foo.bar.svc:8080", Path: "/path"},
+		want: "https://foo.bar.svc:8080/path",
+	}, {
+		name: "external host, not modified",
+		ctx:  ctx,
+		uri:  apis.HTTP("example.com"),
+		want: "http://example.com",
+	}, {
+		name: "https, not modified",
+		ctx:  ctx,
+		uri:  apis.HTTPS("foo.bar.svc.cluster.local"),
+		want: "https://foo.bar.svc.cluster.local",
+	}, {
+		name: "relative uri, not modified",
+		ctx:  ctx,
+		uri:  &apis.URL{Path: "/foo"},
+		want: "/foo",
+	}, {
+		name: "no policy, not modified",
+		ctx:  context.Background(),
+		uri:  apis.HTTP("foo.bar.svc.cluster.local"),
+		want: "http://foo.bar.svc.cluster.local",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &Destination{URI: tc.uri}
+			d.SetDefaults(tc.ctx)
+			if got := d.URI.String(); got != tc.want {
+				t.Errorf("URI = %s, wanted %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -21,3 +21,6 @@ limitations under the License.
 // +k8s:deepcopy-gen=package
 // +groupName=duck.knative.dev
 package v1
+
+// The schemas of the duck types, for the CRDs implementing them.
+//go:generate go run knative.dev/pkg/codegen/cmd/duck-schema-gen -output-dir schemas
@@ -58,3 +58,17 @@ func TestImplementsPodSpecable(t *testing.T) {
 		}
 	}
 }
+
+func TestImplementsScalable(t *testing.T) {
+	instances := []interface{}{
+		&Scalable{},
+		&appsv1.ReplicaSet{},
+		&appsv1.Deployment{},
+		&appsv1.StatefulSet{},
+	}
+	for _, instance := range instances {
+		if err := duck.VerifyType(instance, &Scalable{}); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"knative.dev/pkg/apis"
 )
 
+// +gendefaults
+
 // KReference contains enough information to refer to another object.
 // It's a trimmed down version of corev1.ObjectReference.
 type KReference struct {
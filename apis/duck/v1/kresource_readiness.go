@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// TopLevelConditionAnnotationKey is the annotation a resource can carry to
+// name its top-level condition type, when it is neither Ready nor Succeeded.
+const TopLevelConditionAnnotationKey = "duck.knative.dev/top-level-condition"
+
+// Readiness is a stable, coarse-grained summary of a resource's state that
+// is suitable for generic tooling such as dashboards.
+type Readiness string
+
+const (
+	// ReadinessReady means the top-level condition is True.
+	ReadinessReady Readiness = "Ready"
+	// ReadinessNotReady means the top-level condition is False, but the
+	// resource may still recover.
+	ReadinessNotReady Readiness = "NotReady"
+	// ReadinessUnknown means the top-level condition is Unknown or missing,
+	// or the status does not reflect the latest generation.
+	ReadinessUnknown Readiness = "Unknown"
+	// ReadinessFailed means the resource ran to completion unsuccessfully
+	// and will not recover on its own.
+	ReadinessFailed Readiness = "Failed"
+)
+
+// TopLevelCondition returns a copy of the top-level condition of the
+// resource, or nil when it is not set. The condition type is taken from the
+// TopLevelConditionAnnotationKey annotation if present, and otherwise from
+// the resource's condition set.
+func (t *KResource) TopLevelCondition() *apis.Condition {
+	return t.Status.GetCondition(t.topLevelConditionType())
+}
+
+func (t *KResource) topLevelConditionType() apis.ConditionType {
+	if ct := t.GetAnnotations()[TopLevelConditionAnnotationKey]; ct != "" {
+		return apis.ConditionType(ct)
+	}
+	// Mirrors GetConditionSet, which picks the batch condition set when the
+	// Succeeded condition is present.
+	if t.Status.GetCondition(apis.ConditionSucceeded) != nil {
+		return apis.ConditionSucceeded
+	}
+	return apis.ConditionReady
+}
+
+// Readiness returns the readiness of the resource. A status that has not
+// observed the latest generation is reported as ReadinessUnknown.
+func (t *KResource) Readiness() Readiness {
+	if t.Status.ObservedGeneration != t.Generation {
+		return ReadinessUnknown
+	}
+	return t.Status.Readiness(t.topLevelConditionType())
+}
+
+// Summary returns a one-line, human readable explanation of the resource's
+// readiness. See Status.Summary.
+func (t *KResource) Summary() string {
+	if t.Status.ObservedGeneration != t.Generation {
+		return fmt.Sprintf("%s: generation %d has not been observed yet", ReadinessUnknown, t.Generation)
+	}
+	return t.Status.Summary(t.topLevelConditionType())
+}
+
+// Readiness returns the readiness as expressed by the given top-level
+// condition. Only a False Succeeded condition is considered terminal.
+func (s *Status) Readiness(topLevel apis.ConditionType) Readiness {
+	tlc := s.GetCondition(topLevel)
+	switch {
+	case tlc.IsTrue():
+		return ReadinessReady
+	case tlc.IsFalse() && topLevel == apis.ConditionSucceeded:
+		return ReadinessFailed
+	case tlc.IsFalse():
+		return ReadinessNotReady
+	default:
+		return ReadinessUnknown
+	}
+}
+
+// Summary returns a one-line, human readable explanation of the state of
+// the given top-level condition. When the top-level condition carries no
+// message, the failing dependent conditions are listed instead. Only
+// dependents with Error severity are taken into account, since Warning and
+// Info failures do not affect readiness.
+func (s *Status) Summary(topLevel apis.ConditionType) string {
+	tlc := s.GetCondition(topLevel)
+	if tlc == nil {
+		return fmt.Sprintf("%s: condition %s is not set", ReadinessUnknown, topLevel)
+	}
+	status := string(s.Readiness(topLevel))
+	if tlc.IsTrue() {
+		return status
+	}
+	if tlc.Message != "" {
+		return joinSummary(status, tlc.Reason, tlc.Message)
+	}
+
+	var failing []string
+	for _, c := range s.Conditions {
+		if c.Type == topLevel || c.IsTrue() || c.Severity != apis.ConditionSeverityError {
+			continue
+		}
+		failing = append(failing, joinSummary(string(c.Type), c.Reason, c.Message))
+	}
+	if len(failing) == 0 {
+		return joinSummary(status, tlc.Reason, "")
+	}
+	sort.Strings(failing)
+	return joinSummary(status, tlc.Reason, strings.Join(failing, "; "))
+}
+
+func joinSummary(prefix, reason, message string) string {
+	parts := []string{prefix}
+	if reason != "" {
+		parts = append(parts, reason)
+	}
+	if message != "" {
+		parts = append(parts, message)
+	}
+	return strings.Join(parts, ": ")
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+)
+
+func TestKResourceReadiness(t *testing.T) {
+	tests := []struct {
+		name          string
+		resource      KResource
+		wantReadiness Readiness
+		wantSummary   string
+		wantTopLevel  apis.ConditionType
+	}{{
+		name:          "no conditions",
+		wantReadiness: ReadinessUnknown,
+		wantSummary:   "Unknown: condition Ready is not set",
+	}, {
+		name: "ready",
+		resource: KResource{
+			Status: Status{
+				Conditions: Conditions{{
+					Type:   apis.ConditionReady,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+		},
+		wantReadiness: ReadinessReady,
+		wantSummary:   "Ready",
+		wantTopLevel:  apis.ConditionReady,
+	}, {
+		name: "stale generation",
+		resource: KResource{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Status: Status{
+				ObservedGeneration: 1,
+				Conditions: Conditions{{
+					Type:   apis.ConditionReady,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+		},
+		wantReadiness: ReadinessUnknown,
+		wantSummary:   "Unknown: generation 2 has not been observed yet",
+		wantTopLevel:  apis.ConditionReady,
+	}, {
+		name: "not ready with message",
+		resource: KResource{
+			Status: Status{
+				Conditions: Conditions{{
+					Type:    apis.ConditionReady,
+					Status:  corev1.ConditionFalse,
+					Reason:  "Broken",
+					Message: "it broke",
+				}},
+			},
+		},
+		wantReadiness: ReadinessNotReady,
+		wantSummary:   "NotReady: Broken: it broke",
+		wantTopLevel:  apis.ConditionReady,
+	}, {
+		name: "not ready from dependents, ignoring warnings",
+		resource: KResource{
+			Status: Status{
+				Conditions: Conditions{{
+					Type:   apis.ConditionReady,
+					Status: corev1.ConditionFalse,
+					Reason: "DependentsFailed",
+				}, {
+					Type:    "Foo",
+					Status:  corev1.ConditionFalse,
+					Reason:  "FooFailed",
+					Message: "foo is down",
+				}, {
+					Type:     "Bar",
+					Status:   corev1.ConditionFalse,
+					Severity: apis.ConditionSeverityWarning,
+					Reason:   "BarDegraded",
+				}, {
+					Type:   "Baz",
+					Status: corev1.ConditionUnknown,
+				}, {
+					Type:   "Qux",
+					Status: corev1.ConditionTrue,
+				}},
+			},
+		},
+		wantReadiness: ReadinessNotReady,
+		wantSummary:   "NotReady: DependentsFailed: Baz; Foo: FooFailed: foo is down",
+		wantTopLevel:  apis.ConditionReady,
+	}, {
+		name: "unknown",
+		resource: KResource{
+			Status: Status{
+				Conditions: Conditions{{
+					Type:   apis.ConditionReady,
+					Status: corev1.ConditionUnknown,
+					Reason: "Reconciling",
+				}},
+			},
+		},
+		wantReadiness: ReadinessUnknown,
+		wantSummary:   "Unknown: Reconciling",
+		wantTopLevel:  apis.ConditionReady,
+	}, {
+		name: "terminal failure",
+		resource: KResource{
+			Status: Status{
+				Conditions: Conditions{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  "Failed",
+					Message: "exit code 1",
+				}},
+			},
+		},
+		wantReadiness: ReadinessFailed,
+		wantSummary:   "Failed: Failed: exit code 1",
+		wantTopLevel:  apis.ConditionSucceeded,
+	}, {
+		name: "top-level condition from annotation",
+		resource: KResource{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					TopLevelConditionAnnotationKey: "Deployed",
+				},
+			},
+			Status: Status{
+				Conditions: Conditions{{
+					Type:   apis.ConditionReady,
+					Status: corev1.ConditionFalse,
+				}, {
+					Type:   "Deployed",
+					Status: corev1.ConditionTrue,
+				}},
+			},
+		},
+		wantReadiness: ReadinessReady,
+		wantSummary:   "Ready",
+		wantTopLevel:  "Deployed",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.resource.Readiness(); got != tc.wantReadiness {
+				t.Errorf("Readiness() = %s, want: %s", got, tc.wantReadiness)
+			}
+			if got := tc.resource.Summary(); got != tc.wantSummary {
+				t.Errorf("Summary() = %q, want: %q", got, tc.wantSummary)
+			}
+			tlc := tc.resource.TopLevelCondition()
+			if tc.wantTopLevel == "" {
+				if tlc != nil {
+					t.Errorf("TopLevelCondition() = %v, want: nil", tlc)
+				}
+			} else if tlc == nil || tlc.Type != tc.wantTopLevel {
+				t.Errorf("TopLevelCondition() = %v, want type: %s", tlc, tc.wantTopLevel)
+			}
+		})
+	}
+}
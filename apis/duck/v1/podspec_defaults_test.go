@@ -162,3 +162,36 @@ func TestPodDefaulting(t *testing.T) {
 		})
 	}
 }
+
+func TestDeepCopyWithDefaults(t *testing.T) {
+	p := &WithPod{
+		Spec: WithPodSpec{
+			Template: PodSpecable{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "blah",
+						Image: "busybox",
+					}},
+				},
+			},
+		},
+	}
+	want := p.DeepCopy()
+
+	ctx := WithPodSpecDefaulter(context.Background(), func(ctx context.Context, wp *WithPod) {
+		wp.Spec.Template.Spec.Containers[0].Image = "busybox@sha256:deadbeef"
+	})
+	got := p.DeepCopyWithDefaults(ctx)
+
+	if got, want := got.Spec.Template.Spec.Containers[0].Image, "busybox@sha256:deadbeef"; got != want {
+		t.Errorf("DeepCopyWithDefaults() image = %s, want: %s", got, want)
+	}
+	if !cmp.Equal(want, p) {
+		t.Error("DeepCopyWithDefaults() modified the receiver (-want, +got) =", cmp.Diff(want, p))
+	}
+
+	var nilPod *WithPod
+	if got := nilPod.DeepCopyWithDefaults(ctx); got != nil {
+		t.Errorf("DeepCopyWithDefaults() = %v, want: nil", got)
+	}
+}
@@ -34,6 +34,7 @@ type PodSpecable corev1.PodTemplateSpec
 // PodSpecable is an Implementable duck type.
 var _ ducktypes.Implementable = (*PodSpecable)(nil)
 
+// +gendefaults
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // WithPod is the shell that demonstrates how PodSpecable types wrap
@@ -95,6 +96,7 @@ type WithPodList struct {
 	Items []WithPod `json:"items"`
 }
 
+// +gendefaults
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // Pod is a wrapper around Pod-like resource, which supports our interfaces
@@ -58,7 +58,46 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		(&WithPod{}).GetListType(),
 		&Binding{},
 		(&Binding{}).GetListType(),
+		&Scalable{},
+		(&Scalable{}).GetListType(),
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
 }
+
+func init() {
+	// Register the duck types that resources advertise via the
+	// duck.knative.dev/<name>=true label convention.
+	ducktypes.Register(ducktypes.Registration{
+		Name: "addressable",
+		Type: &Addressable{},
+	})
+	ducktypes.Register(ducktypes.Registration{
+		Name: "binding",
+		Type: &Binding{},
+	})
+	ducktypes.Register(ducktypes.Registration{
+		Name: "source",
+		Type: &Source{},
+	})
+	ducktypes.Register(ducktypes.Registration{
+		Name: "podspecable",
+		Type: &PodSpecable{},
+		BuiltIns: []schema.GroupVersionResource{
+			{Group: "apps", Version: "v1", Resource: "deployments"},
+			{Group: "apps", Version: "v1", Resource: "replicasets"},
+			{Group: "apps", Version: "v1", Resource: "statefulsets"},
+			{Group: "apps", Version: "v1", Resource: "daemonsets"},
+			{Group: "batch", Version: "v1", Resource: "jobs"},
+		},
+	})
+	ducktypes.Register(ducktypes.Registration{
+		Name: "scalable",
+		Type: &Scalable{},
+		BuiltIns: []schema.GroupVersionResource{
+			{Group: "apps", Version: "v1", Resource: "deployments"},
+			{Group: "apps", Version: "v1", Resource: "replicasets"},
+			{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		},
+	})
+}
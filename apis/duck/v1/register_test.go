@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/pkg/apis/duck/ducktypes"
 )
 
 func TestRegisterHelpers(t *testing.T) {
@@ -40,3 +42,24 @@ func TestRegisterHelpers(t *testing.T) {
 		t.Error("addKnownTypes() =", err)
 	}
 }
+
+func TestRegisteredDuckTypes(t *testing.T) {
+	for name, impl := range map[string]ducktypes.Implementable{
+		"addressable": &Addressable{},
+		"binding":     &Binding{},
+		"source":      &Source{},
+		"scalable":    &Scalable{},
+	} {
+		r, ok := ducktypes.LookupType(impl)
+		if !ok {
+			t.Errorf("%T is not registered", impl)
+			continue
+		}
+		if r.Name != name {
+			t.Errorf("Name = %q, want: %q", r.Name, name)
+		}
+		if got, want := r.Label(), "duck.knative.dev/"+name; got != want {
+			t.Errorf("Label() = %q, want: %q", got, want)
+		}
+	}
+}
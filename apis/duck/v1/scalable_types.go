@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/apis/duck/ducktypes"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
+)
+
+// +genduck
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Scalable is the minimum resource shape of a workload that can be
+// horizontally scaled, e.g. Deployment, ReplicaSet and StatefulSet.
+// The shape mirrors what the /scale subresource exposes, so that
+// autoscaler integrations can read desired and ready replicas off
+// arbitrary workloads.  This is not a real resource.
+type Scalable struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScalableSpec   `json:"spec,omitempty"`
+	Status ScalableStatus `json:"status,omitempty"`
+}
+
+// ScalableSpec holds the desired replica count and the selector used to
+// find the pods that make up the workload.
+type ScalableSpec struct {
+	// Replicas is the desired number of replicas. Defaults to 1 when unset,
+	// matching the behavior of the built-in workload types.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Selector is a label query over the pods that should match the
+	// replica count. This is what the /scale subresource reports as
+	// status.selector.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ScalableStatus holds the most recently observed replica counts.
+type ScalableStatus struct {
+	// ObservedGeneration is the 'Generation' of the resource that was last
+	// processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Replicas is the total number of replicas observed.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the number of observed replicas that are ready.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+}
+
+// Verify Scalable resources meet duck contracts.
+var (
+	_ apis.Listable           = (*Scalable)(nil)
+	_ ducktypes.Implementable = (*Scalable)(nil)
+	_ ducktypes.Populatable   = (*Scalable)(nil)
+	_ kmeta.OwnerRefable      = (*Scalable)(nil)
+)
+
+// GetFullType implements duck.Implementable
+func (*Scalable) GetFullType() ducktypes.Populatable {
+	return &Scalable{}
+}
+
+// Populate implements duck.Populatable
+func (s *Scalable) Populate() {
+	s.Spec = ScalableSpec{
+		// Populate ALL fields
+		Replicas: ptr.Int32(3),
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"app": "scalable",
+			},
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "tier",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"web", "api"},
+			}},
+		},
+	}
+	s.Status = ScalableStatus{
+		ObservedGeneration: 42,
+		Replicas:           3,
+		ReadyReplicas:      2,
+	}
+}
+
+// GetGroupVersionKind implements kmeta.OwnerRefable
+func (s *Scalable) GetGroupVersionKind() schema.GroupVersionKind {
+	return s.GroupVersionKind()
+}
+
+// GetListType implements apis.Listable
+func (*Scalable) GetListType() runtime.Object {
+	return &ScalableList{}
+}
+
+// DesiredReplicas returns the number of replicas requested in the spec,
+// applying the Kubernetes default of 1 when the field is unset.
+func (s *Scalable) DesiredReplicas() int32 {
+	if s.Spec.Replicas == nil {
+		return 1
+	}
+	return *s.Spec.Replicas
+}
+
+// ScaleSelector returns the serialized label selector in the form used by
+// the status.selector field of the /scale subresource. An unset selector
+// results in an empty string.
+func (s *Scalable) ScaleSelector() (string, error) {
+	if s.Spec.Selector == nil {
+		return "", nil
+	}
+	sel, err := metav1.LabelSelectorAsSelector(s.Spec.Selector)
+	if err != nil {
+		return "", err
+	}
+	return sel.String(), nil
+}
+
+// IsScaledUp returns true when the observed status reflects the latest
+// generation and every desired replica is ready.
+func (s *Scalable) IsScaledUp() bool {
+	return s.Status.ObservedGeneration >= s.Generation &&
+		s.Status.ReadyReplicas >= s.DesiredReplicas()
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScalableList is a list of Scalable resources
+type ScalableList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Scalable `json:"items"`
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/ptr"
+)
+
+func TestScalableDesiredReplicas(t *testing.T) {
+	s := &Scalable{}
+	if got, want := s.DesiredReplicas(), int32(1); got != want {
+		t.Errorf("DesiredReplicas() = %d, want: %d", got, want)
+	}
+	s.Spec.Replicas = ptr.Int32(0)
+	if got, want := s.DesiredReplicas(), int32(0); got != want {
+		t.Errorf("DesiredReplicas() = %d, want: %d", got, want)
+	}
+}
+
+func TestScalableScaleSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		want     string
+		wantErr  bool
+	}{{
+		name: "no selector",
+	}, {
+		name: "match labels",
+		selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "foo"},
+		},
+		want: "app=foo",
+	}, {
+		name: "match expressions",
+		selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "foo"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "tier",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"web"},
+			}},
+		},
+		want: "app=foo,tier in (web)",
+	}, {
+		name: "invalid operator",
+		selector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "tier",
+				Operator: "Nope",
+			}},
+		},
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Scalable{Spec: ScalableSpec{Selector: tc.selector}}
+			got, err := s.ScaleSelector()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ScaleSelector() = %v, wantErr: %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ScaleSelector() = %q, want: %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScalableIsScaledUp(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Scalable
+		want bool
+	}{{
+		name: "default replicas, none ready",
+		want: false,
+	}, {
+		name: "default replicas, one ready",
+		s: Scalable{
+			Status: ScalableStatus{ReadyReplicas: 1},
+		},
+		want: true,
+	}, {
+		name: "stale generation",
+		s: Scalable{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec:       ScalableSpec{Replicas: ptr.Int32(2)},
+			Status:     ScalableStatus{ObservedGeneration: 1, ReadyReplicas: 2},
+		},
+		want: false,
+	}, {
+		name: "partially ready",
+		s: Scalable{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec:       ScalableSpec{Replicas: ptr.Int32(3)},
+			Status:     ScalableStatus{ObservedGeneration: 2, ReadyReplicas: 2},
+		},
+		want: false,
+	}, {
+		name: "scaled to zero",
+		s: Scalable{
+			Spec: ScalableSpec{Replicas: ptr.Int32(0)},
+		},
+		want: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.s.IsScaledUp(); got != tc.want {
+				t.Errorf("IsScaledUp() = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
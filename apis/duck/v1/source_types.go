@@ -89,6 +89,11 @@ type SourceStatus struct {
 	// according to https://www.rfc-editor.org/rfc/rfc7468.
 	// +optional
 	SinkCACerts *string `json:"sinkCACerts,omitempty"`
+
+	// Auth defines the attributes that provide the generated service account
+	// name(s) used by the Source to authenticate.
+	// +optional
+	Auth *AuthStatus `json:"auth,omitempty"`
 }
 
 // CloudEventAttributes specifies the attributes that a Source
@@ -134,12 +139,23 @@ func (*Source) GetFullType() ducktypes.Populatable {
 
 // Populate implements duck.Populatable
 func (s *Source) Populate() {
+	caCerts := "-----BEGIN CERTIFICATE-----"
+	address := "mattmoor.tableflip.svc.cluster.local"
 	s.Spec.Sink = Destination{
+		Ref: &KReference{
+			Kind:       "Service",
+			Namespace:  "tableflip",
+			Name:       "mattmoor",
+			APIVersion: "serving.knative.dev/v1",
+			Group:      "serving.knative.dev",
+			Address:    &address,
+		},
 		URI: &apis.URL{
 			Scheme:   "https",
 			Host:     "tableflip.dev",
 			RawQuery: "flip=mattmoor",
 		},
+		CACerts: &caCerts,
 	}
 	s.Spec.CloudEventOverrides = &CloudEventOverrides{
 		Extensions: map[string]string{"boosh": "kakow"},
@@ -150,16 +166,28 @@ func (s *Source) Populate() {
 		Type:               SourceConditionSinkProvided,
 		Status:             corev1.ConditionTrue,
 		LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(time.Date(1984, 02, 28, 18, 52, 00, 00, time.UTC))},
+		Severity:           apis.ConditionSeverityInfo,
+		Reason:             "SinkFound",
+		Message:            "The sink was resolved",
 	}}
+	s.Status.Annotations = map[string]string{"foo": "bar"}
 	s.Status.SinkURI = &apis.URL{
 		Scheme:   "https",
 		Host:     "tableflip.dev",
 		RawQuery: "flip=mattmoor",
 	}
+	s.Status.SinkCACerts = &caCerts
 	s.Status.CloudEventAttributes = []CloudEventAttributes{{
 		Type:   "dev.knative.foo",
 		Source: "http://knative.dev/knative/eventing",
 	}}
+	audience := "tableflip.dev"
+	s.Status.Auth = &AuthStatus{}
+	s.Status.Auth.SetIdentities(AuthIdentity{
+		ServiceAccountName: "mattmoor",
+		Audience:           &audience,
+		Roles:              []string{"sender"},
+	})
 }
 
 // GetListType implements apis.Listable
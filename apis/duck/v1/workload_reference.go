@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+)
+
+// WorkloadReference points to a scalable workload in the namespace of the
+// referrer: a resource implementing the Scalable duck type and serving the
+// /scale subresource, such as a Deployment.
+type WorkloadReference struct {
+	// API version of the referent.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the referent.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds
+	Kind string `json:"kind"`
+
+	// Name of the referent.
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names
+	Name string `json:"name"`
+}
+
+// Validate the WorkloadReference has all the necessary fields.
+func (wr *WorkloadReference) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if wr == nil {
+		return errs.Also(apis.ErrMissingField("name", "kind", "apiVersion"))
+	}
+	if wr.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("name"))
+	}
+	if wr.Kind == "" {
+		errs = errs.Also(apis.ErrMissingField("kind"))
+	}
+	if wr.APIVersion == "" {
+		errs = errs.Also(apis.ErrMissingField("apiVersion"))
+	} else if _, err := schema.ParseGroupVersion(wr.APIVersion); err != nil {
+		errs = errs.Also(apis.ErrInvalidValue(wr.APIVersion, "apiVersion", err.Error()))
+	}
+	return errs
+}
+
+// GroupVersionKind returns the GroupVersionKind of the referent.
+func (wr *WorkloadReference) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(wr.APIVersion, wr.Kind)
+}
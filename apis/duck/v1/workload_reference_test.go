@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWorkloadReferenceValidate(t *testing.T) {
+	tests := map[string]struct {
+		ref  *WorkloadReference
+		want string
+	}{"nil": {
+		want: "missing field(s): apiVersion, kind, name",
+	}, "valid": {
+		ref: &WorkloadReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       "foo",
+		},
+	}, "missing name": {
+		ref: &WorkloadReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+		},
+		want: "missing field(s): name",
+	}, "invalid apiVersion": {
+		ref: &WorkloadReference{
+			APIVersion: "apps/v1/beta",
+			Kind:       "Deployment",
+			Name:       "foo",
+		},
+		want: "invalid value: apps/v1/beta: apiVersion\nunexpected GroupVersion string: apps/v1/beta",
+	}}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotErr := tc.ref.Validate(context.Background())
+			if tc.want != "" {
+				if got := gotErr.Error(); got != tc.want {
+					t.Errorf("Error() = %q, wanted %q", got, tc.want)
+				}
+			} else if gotErr != nil {
+				t.Errorf("Validate() = %v, wanted nil", gotErr)
+			}
+		})
+	}
+}
+
+func TestWorkloadReferenceGroupVersionKind(t *testing.T) {
+	ref := &WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "foo"}
+	want := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	if got := ref.GroupVersionKind(); got != want {
+		t.Errorf("GroupVersionKind() = %v, want: %v", got, want)
+	}
+}
@@ -132,18 +132,22 @@ func (a *Addressable) ConvertFrom(ctx context.Context, from apis.Convertible) er
 // Populate implements duck.Populatable
 func (t *AddressableType) Populate() {
 	name := "http"
-	t.Status = AddressStatus{
-		Address: &Addressable{
-			// Populate ALL fields
-			Addressable: v1beta1.Addressable{
-				Name: &name,
-				URL: &apis.URL{
-					Scheme: "http",
-					Host:   "foo.bar.svc.cluster.local",
-				},
+	caCerts := "-----BEGIN CERTIFICATE-----"
+	address := Addressable{
+		// Populate ALL fields
+		Addressable: v1beta1.Addressable{
+			Name: &name,
+			URL: &apis.URL{
+				Scheme: "http",
+				Host:   "foo.bar.svc.cluster.local",
 			},
-			Hostname: "this is not empty",
+			CACerts: &caCerts,
 		},
+		Hostname: "this is not empty",
+	}
+	t.Status = AddressStatus{
+		Address:   &address,
+		Addresses: []Addressable{address},
 	}
 }
 
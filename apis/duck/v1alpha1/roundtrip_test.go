@@ -17,11 +17,15 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"regexp"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"knative.dev/pkg/apis"
+	v1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/apis/duck/v1beta1"
 	pkgfuzzer "knative.dev/pkg/apis/testing/fuzzer"
 	"knative.dev/pkg/apis/testing/roundtrip"
 )
@@ -35,3 +39,16 @@ func TestEventingRoundTripTypesToJSON(t *testing.T) {
 	)
 	roundtrip.ExternalTypesViaJSON(t, scheme, fuzzerFuncs)
 }
+
+func TestAddressableRoundTripViaHub(t *testing.T) {
+	roundtrip.ConvertibleViaHub(t, &v1.Addressable{}, []apis.Convertible{
+		&Addressable{},
+		&v1beta1.Addressable{},
+	},
+		// Only the URL is converted to v1.
+		roundtrip.IgnoreFields(v1beta1.Addressable{}, "Name", "CACerts"),
+		// The Hostname is derived from the URL.
+		roundtrip.SkipFieldsWithPattern(regexp.MustCompile("Hostname")),
+		roundtrip.IgnoreFields(Addressable{}, "Hostname"),
+	)
+}
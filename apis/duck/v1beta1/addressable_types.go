@@ -120,15 +120,19 @@ func (a *Addressable) ConvertFrom(ctx context.Context, from apis.Convertible) er
 // Populate implements duck.Populatable
 func (t *AddressableType) Populate() {
 	name := "http"
-	t.Status = AddressStatus{
-		Address: &Addressable{
-			// Populate ALL fields
-			Name: &name,
-			URL: &apis.URL{
-				Scheme: "http",
-				Host:   "foo.com",
-			},
+	caCerts := "-----BEGIN CERTIFICATE-----"
+	address := Addressable{
+		// Populate ALL fields
+		Name: &name,
+		URL: &apis.URL{
+			Scheme: "http",
+			Host:   "foo.com",
 		},
+		CACerts: &caCerts,
+	}
+	t.Status = AddressStatus{
+		Address:   &address,
+		Addresses: []Addressable{address},
 	}
 }
 
@@ -114,12 +114,24 @@ func (*Source) GetFullType() ducktypes.Populatable {
 
 // Populate implements duck.Populatable
 func (s *Source) Populate() {
+	caCerts := "-----BEGIN CERTIFICATE-----"
 	s.Spec.Sink = Destination{
+		Ref: &corev1.ObjectReference{
+			Kind:       "Service",
+			Namespace:  "tableflip",
+			Name:       "mattmoor",
+			APIVersion: "serving.knative.dev/v1",
+		},
+		DeprecatedAPIVersion: "serving.knative.dev/v1",
+		DeprecatedKind:       "Service",
+		DeprecatedName:       "mattmoor",
+		DeprecatedNamespace:  "tableflip",
 		URI: &apis.URL{
 			Scheme:   "https",
 			Host:     "tableflip.dev",
 			RawQuery: "flip=mattmoor",
 		},
+		CACerts: &caCerts,
 	}
 	s.Spec.CloudEventOverrides = &CloudEventOverrides{
 		Extensions: map[string]string{"boosh": "kakow"},
@@ -130,7 +142,11 @@ func (s *Source) Populate() {
 		Type:               SourceConditionSinkProvided,
 		Status:             corev1.ConditionTrue,
 		LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(time.Date(1984, 02, 28, 18, 52, 00, 00, time.UTC))},
+		Severity:           apis.ConditionSeverityInfo,
+		Reason:             "SinkFound",
+		Message:            "The sink was resolved",
 	}}
+	s.Status.Annotations = map[string]string{"foo": "bar"}
 	s.Status.SinkURI = &apis.URL{
 		Scheme:   "https",
 		Host:     "tableflip.dev",
@@ -130,9 +130,11 @@ func (t *KResource) Populate() {
 		Type:               "Birthday",
 		Status:             corev1.ConditionTrue,
 		LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(time.Date(1984, 02, 28, 18, 52, 00, 00, time.UTC))},
+		Severity:           apis.ConditionSeverityInfo,
 		Reason:             "Celebrate",
 		Message:            "n3wScott, find your party hat :tada:",
 	}}
+	t.Status.Annotations = map[string]string{"foo": "bar"}
 }
 
 // GetListType implements apis.Listable
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// IPFamily is the version of the Internet Protocol of an address, with the
+// same values as the IP families of Kubernetes Services.
+type IPFamily string
+
+const (
+	// IPv4 is the family of the IPv4 addresses.
+	IPv4 IPFamily = "IPv4"
+
+	// IPv6 is the family of the IPv6 addresses, including the IPv4-mapped
+	// ones like ::ffff:10.0.0.1.
+	IPv6 IPFamily = "IPv6"
+)
+
+// IPAddress is an IPv4 or IPv6 address, like 10.0.0.1 or fd00::1, in its
+// string form. Being a string, it decodes whatever it is given and is then
+// checked by Validate, so that invalid addresses are reported like the other
+// invalid fields of a resource.
+// +kubebuilder:validation:Type=string
+type IPAddress string
+
+// Addr returns the parsed address.
+func (a IPAddress) Addr() (netip.Addr, error) {
+	addr, err := netip.ParseAddr(string(a))
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	if addr.Zone() != "" {
+		return netip.Addr{}, fmt.Errorf("IP address %q must not have a zone", a)
+	}
+	return addr, nil
+}
+
+// Family returns the family of the address, or the empty string if the
+// address is invalid.
+func (a IPAddress) Family() IPFamily {
+	addr, err := a.Addr()
+	if err != nil {
+		return ""
+	}
+	return addrFamily(addr)
+}
+
+// Validate checks that the address is valid and, when families are given,
+// of one of them. The empty address is valid, required fields are checked
+// by their callers.
+func (a IPAddress) Validate(families ...IPFamily) *FieldError {
+	if a == "" {
+		return nil
+	}
+	addr, err := a.Addr()
+	if err != nil {
+		return ErrInvalidValue(a, CurrentField, "must be an IP address, like 10.0.0.1 or fd00::1")
+	}
+	return validateFamily(a, addrFamily(addr), families)
+}
+
+// CIDR is a block of IPv4 or IPv6 addresses in CIDR notation, like
+// 10.0.0.0/8 or fd00::/8. Like IPAddress, it is a string checked by
+// Validate.
+// +kubebuilder:validation:Type=string
+type CIDR string
+
+// Prefix returns the parsed block.
+func (c CIDR) Prefix() (netip.Prefix, error) {
+	return netip.ParsePrefix(string(c))
+}
+
+// Family returns the family of the block, or the empty string if the block
+// is invalid.
+func (c CIDR) Family() IPFamily {
+	p, err := c.Prefix()
+	if err != nil {
+		return ""
+	}
+	return addrFamily(p.Addr())
+}
+
+// Contains returns whether the block is valid and contains the given valid
+// address.
+func (c CIDR) Contains(a IPAddress) bool {
+	p, err := c.Prefix()
+	if err != nil {
+		return false
+	}
+	addr, err := a.Addr()
+	if err != nil {
+		return false
+	}
+	return p.Contains(addr)
+}
+
+// Validate checks that the block is valid, that its address is the first
+// of the block, e.g. 10.0.0.0/8 rather than 10.0.0.1/8, and, when families
+// are given, that it is of one of them. The empty block is valid, required
+// fields are checked by their callers.
+func (c CIDR) Validate(families ...IPFamily) *FieldError {
+	if c == "" {
+		return nil
+	}
+	p, err := c.Prefix()
+	if err != nil {
+		return ErrInvalidValue(c, CurrentField, "must be a CIDR, like 10.0.0.0/8 or fd00::/8")
+	}
+	if masked := p.Masked(); masked != p {
+		return ErrInvalidValue(c, CurrentField, "must be the first address of the block, "+masked.String())
+	}
+	return validateFamily(c, addrFamily(p.Addr()), families)
+}
+
+func addrFamily(addr netip.Addr) IPFamily {
+	if addr.Is4() {
+		return IPv4
+	}
+	return IPv6
+}
+
+func validateFamily(value interface{}, family IPFamily, families []IPFamily) *FieldError {
+	if len(families) == 0 {
+		return nil
+	}
+	want := ""
+	for i, f := range families {
+		if f == family {
+			return nil
+		}
+		if i > 0 {
+			want += " or "
+		}
+		want += string(f)
+	}
+	return ErrInvalidValue(value, CurrentField, "must be "+want)
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIPAddressValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		addr       IPAddress
+		families   []IPFamily
+		wantFamily IPFamily
+		want       string
+	}{{
+		name: "empty",
+	}, {
+		name:       "ipv4",
+		addr:       "10.0.0.1",
+		wantFamily: IPv4,
+	}, {
+		name:       "ipv6",
+		addr:       "fd00::1",
+		wantFamily: IPv6,
+	}, {
+		name:       "ipv4-mapped ipv6",
+		addr:       "::ffff:10.0.0.1",
+		families:   []IPFamily{IPv6},
+		wantFamily: IPv6,
+	}, {
+		name:       "either family",
+		addr:       "fd00::1",
+		families:   []IPFamily{IPv4, IPv6},
+		wantFamily: IPv6,
+	}, {
+		name:       "wrong family",
+		addr:       "10.0.0.1",
+		families:   []IPFamily{IPv6},
+		wantFamily: IPv4,
+		want:       "invalid value: 10.0.0.1: addr\nmust be IPv6",
+	}, {
+		name: "hostname",
+		addr: "example.com",
+		want: "invalid value: example.com: addr\nmust be an IP address, like 10.0.0.1 or fd00::1",
+	}, {
+		name: "leading zeros",
+		addr: "010.0.0.1",
+		want: "invalid value: 010.0.0.1: addr\nmust be an IP address, like 10.0.0.1 or fd00::1",
+	}, {
+		name: "zone",
+		addr: "fe80::1%eth0",
+		want: "invalid value: fe80::1%eth0: addr\nmust be an IP address, like 10.0.0.1 or fd00::1",
+	}, {
+		name: "cidr",
+		addr: "10.0.0.0/8",
+		want: "invalid value: 10.0.0.0/8: addr\nmust be an IP address, like 10.0.0.1 or fd00::1",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.addr.Family(); got != test.wantFamily {
+				t.Errorf("Family() = %q, wanted %q", got, test.wantFamily)
+			}
+			if got := test.addr.Validate(test.families...).ViaField("addr"); got.Error() != test.want {
+				t.Errorf("Validate() = %q, wanted %q", got.Error(), test.want)
+			}
+		})
+	}
+}
+
+func TestCIDRValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		cidr       CIDR
+		families   []IPFamily
+		wantFamily IPFamily
+		want       string
+	}{{
+		name: "empty",
+	}, {
+		name:       "ipv4",
+		cidr:       "10.0.0.0/8",
+		wantFamily: IPv4,
+	}, {
+		name:       "ipv6",
+		cidr:       "fd00::/8",
+		families:   []IPFamily{IPv6},
+		wantFamily: IPv6,
+	}, {
+		name:       "single address",
+		cidr:       "10.0.0.1/32",
+		wantFamily: IPv4,
+	}, {
+		name:       "wrong family",
+		cidr:       "fd00::/8",
+		families:   []IPFamily{IPv4},
+		wantFamily: IPv6,
+		want:       "invalid value: fd00::/8: cidr\nmust be IPv4",
+	}, {
+		name:       "not the first address",
+		cidr:       "10.0.0.1/8",
+		wantFamily: IPv4,
+		want:       "invalid value: 10.0.0.1/8: cidr\nmust be the first address of the block, 10.0.0.0/8",
+	}, {
+		name: "address",
+		cidr: "10.0.0.1",
+		want: "invalid value: 10.0.0.1: cidr\nmust be a CIDR, like 10.0.0.0/8 or fd00::/8",
+	}, {
+		name: "prefix too long",
+		cidr: "10.0.0.0/33",
+		want: "invalid value: 10.0.0.0/33: cidr\nmust be a CIDR, like 10.0.0.0/8 or fd00::/8",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.cidr.Family(); got != test.wantFamily {
+				t.Errorf("Family() = %q, wanted %q", got, test.wantFamily)
+			}
+			if got := test.cidr.Validate(test.families...).ViaField("cidr"); got.Error() != test.want {
+				t.Errorf("Validate() = %q, wanted %q", got.Error(), test.want)
+			}
+		})
+	}
+}
+
+func TestCIDRContains(t *testing.T) {
+	tests := []struct {
+		cidr CIDR
+		addr IPAddress
+		want bool
+	}{
+		{cidr: "10.0.0.0/8", addr: "10.1.2.3", want: true},
+		{cidr: "10.0.0.0/8", addr: "11.0.0.1", want: false},
+		{cidr: "fd00::/8", addr: "fd00::1", want: true},
+		{cidr: "10.0.0.0/8", addr: "fd00::1", want: false},
+		{cidr: "invalid", addr: "10.0.0.1", want: false},
+		{cidr: "10.0.0.0/8", addr: "invalid", want: false},
+	}
+
+	for _, test := range tests {
+		if got := test.cidr.Contains(test.addr); got != test.want {
+			t.Errorf("%q.Contains(%q) = %v, wanted %v", test.cidr, test.addr, got, test.want)
+		}
+	}
+}
+
+func TestIPJSON(t *testing.T) {
+	type spec struct {
+		Address IPAddress `json:"address,omitempty"`
+		Block   CIDR      `json:"block,omitempty"`
+	}
+
+	in := `{"address":"not an address","block":"fd00::/8"}`
+	var got spec
+	if err := json.Unmarshal([]byte(in), &got); err != nil {
+		t.Fatal("Unmarshal() =", err)
+	}
+	want := spec{Address: "not an address", Block: "fd00::/8"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unmarshal() (-want, +got):", diff)
+	}
+
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal("Marshal() =", err)
+	}
+	if string(b) != in {
+		t.Errorf("Marshal() = %s, wanted %s", b, in)
+	}
+
+	if err := json.Unmarshal([]byte(`{"address":10}`), &got); err == nil {
+		t.Error("Unmarshal() of a number succeeded, wanted an error")
+	}
+}
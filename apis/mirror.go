@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// mirrorTag is the struct tag declaring the spec fields mirrored into
+	// the status, as `mirror:"StatusField"`, and the status field holding
+	// their hash, as `mirror:",hash"`.
+	mirrorTag = "mirror"
+
+	mirrorHashOption = "hash"
+)
+
+// MirrorSpec records the spec fields a reconciler acted on into its status,
+// e.g. as a snapshot of the observed configuration for drift detection: the
+// fields of spec tagged `mirror:"Field"` are copied to the status fields of
+// that name, which must have the same JSON representation, and their hash to
+// the string field of status tagged `mirror:",hash"`, if any. It returns
+// whether the status changed.
+//
+//	type FooSpec struct {
+//	    Config ConfigSpec `json:"config" mirror:"ObservedConfig"`
+//	}
+//
+//	type FooStatus struct {
+//	    ObservedConfig *ConfigSpec `json:"observedConfig,omitempty"`
+//	    ObservedHash   string      `json:"observedHash,omitempty" mirror:",hash"`
+//	}
+func MirrorSpec(spec, status interface{}) (bool, error) {
+	fields, err := mirroredFields(spec)
+	if err != nil {
+		return false, err
+	}
+	sv := reflect.ValueOf(status)
+	if sv.Kind() != reflect.Ptr || sv.Elem().Kind() != reflect.Struct {
+		return false, fmt.Errorf("status must be a pointer to a struct, got %T", status)
+	}
+	sv = sv.Elem()
+
+	changed := false
+	for name, raw := range fields {
+		field := sv.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() {
+			return false, fmt.Errorf("status %T has no field %s", status, name)
+		}
+		mirror := reflect.New(field.Type())
+		if err := json.Unmarshal(raw, mirror.Interface()); err != nil {
+			return false, fmt.Errorf("failed to mirror %s: %w", name, err)
+		}
+		if !reflect.DeepEqual(field.Interface(), mirror.Elem().Interface()) {
+			field.Set(mirror.Elem())
+			changed = true
+		}
+	}
+
+	if hashField, ok := mirrorHashField(sv); ok {
+		hash, err := hashMirroredFields(fields)
+		if err != nil {
+			return false, err
+		}
+		if hashField.String() != hash {
+			hashField.SetString(hash)
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// MirroredSpecChanged returns whether the fields of spec mirrored by
+// MirrorSpec changed since they were recorded into status, per the hash of
+// status. It returns true when status has no hash yet.
+func MirroredSpecChanged(spec, status interface{}) (bool, error) {
+	hash, err := MirrorHash(spec)
+	if err != nil {
+		return false, err
+	}
+	sv := reflect.ValueOf(status)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	}
+	if sv.Kind() != reflect.Struct {
+		return false, fmt.Errorf("status must be a struct, got %T", status)
+	}
+	hashField, ok := mirrorHashField(sv)
+	if !ok {
+		return false, fmt.Errorf("status %T has no field tagged %s:\",%s\"", status, mirrorTag, mirrorHashOption)
+	}
+	return hashField.String() != hash, nil
+}
+
+// MirrorHash returns the hash of the fields of spec mirrored by MirrorSpec.
+func MirrorHash(spec interface{}) (string, error) {
+	fields, err := mirroredFields(spec)
+	if err != nil {
+		return "", err
+	}
+	return hashMirroredFields(fields)
+}
+
+// mirroredFields returns the JSON of the fields of spec to mirror, by the
+// name of their status field.
+func mirroredFields(spec interface{}) (map[string]json.RawMessage, error) {
+	v := reflect.ValueOf(spec)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("spec must be a struct, got %T", spec)
+	}
+	fields := make(map[string]json.RawMessage)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get(mirrorTag), ",")
+		if name == "" {
+			continue
+		}
+		raw, err := json.Marshal(v.Field(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to mirror %s: %w", t.Field(i).Name, err)
+		}
+		fields[name] = raw
+	}
+	return fields, nil
+}
+
+// hashMirroredFields returns the hash of the mirrored fields.
+func hashMirroredFields(fields map[string]json.RawMessage) (string, error) {
+	// Maps are marshaled with sorted keys, so the hash is stable.
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// mirrorHashField returns the string field of the status struct holding the
+// hash of the mirrored fields, if any.
+func mirrorHashField(sv reflect.Value) (reflect.Value, bool) {
+	t := sv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		_, opts, _ := strings.Cut(t.Field(i).Tag.Get(mirrorTag), ",")
+		if opts == mirrorHashOption && t.Field(i).Type.Kind() == reflect.String {
+			return sv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
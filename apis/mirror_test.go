@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mirrorConfig struct {
+	Replicas int               `json:"replicas"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type mirrorSpec struct {
+	Config  mirrorConfig `json:"config" mirror:"ObservedConfig"`
+	Image   string       `json:"image" mirror:"ObservedImage"`
+	Ignored string       `json:"ignored"`
+}
+
+type mirrorStatus struct {
+	ObservedConfig *mirrorConfig `json:"observedConfig,omitempty"`
+	ObservedImage  string        `json:"observedImage,omitempty"`
+	ObservedHash   string        `json:"observedHash,omitempty" mirror:",hash"`
+}
+
+func TestMirrorSpec(t *testing.T) {
+	spec := &mirrorSpec{
+		Config: mirrorConfig{Replicas: 2, Labels: map[string]string{"app": "foo"}},
+		Image:  "foo:v1",
+	}
+	status := &mirrorStatus{}
+
+	if changed, err := MirroredSpecChanged(spec, status); err != nil || !changed {
+		t.Errorf("MirroredSpecChanged() before mirroring = %v, %v, wanted true", changed, err)
+	}
+	if changed, err := MirrorSpec(spec, status); err != nil || !changed {
+		t.Fatalf("MirrorSpec() = %v, %v, wanted true", changed, err)
+	}
+	want := &mirrorStatus{
+		ObservedConfig: &mirrorConfig{Replicas: 2, Labels: map[string]string{"app": "foo"}},
+		ObservedImage:  "foo:v1",
+		ObservedHash:   status.ObservedHash,
+	}
+	if !cmp.Equal(status, want) {
+		t.Error("MirrorSpec() (-want, +got):", cmp.Diff(want, status))
+	}
+	if status.ObservedHash == "" {
+		t.Error("MirrorSpec() recorded no hash")
+	}
+
+	// The snapshot does not alias the spec.
+	spec.Config.Labels["app"] = "bar"
+	if got := status.ObservedConfig.Labels["app"]; got != "foo" {
+		t.Errorf("ObservedConfig.Labels[app] = %q after changing the spec, wanted foo", got)
+	}
+	if changed, err := MirroredSpecChanged(spec, status); err != nil || !changed {
+		t.Errorf("MirroredSpecChanged() after changing the spec = %v, %v, wanted true", changed, err)
+	}
+	spec.Config.Labels["app"] = "foo"
+
+	// Unmirrored fields are not part of the snapshot.
+	spec.Ignored = "changed"
+	if changed, err := MirroredSpecChanged(spec, status); err != nil || changed {
+		t.Errorf("MirroredSpecChanged() after changing an unmirrored field = %v, %v, wanted false", changed, err)
+	}
+	if changed, err := MirrorSpec(spec, status); err != nil || changed {
+		t.Errorf("MirrorSpec() again = %v, %v, wanted false", changed, err)
+	}
+}
+
+func TestMirrorSpecErrors(t *testing.T) {
+	spec := &mirrorSpec{Image: "foo:v1"}
+	if _, err := MirrorSpec(spec, mirrorStatus{}); err == nil {
+		t.Error("MirrorSpec(non-pointer status) = nil, wanted error")
+	}
+	if _, err := MirrorSpec("spec", &mirrorStatus{}); err == nil {
+		t.Error("MirrorSpec(non-struct spec) = nil, wanted error")
+	}
+	if _, err := MirrorSpec(spec, &struct{ ObservedConfig *mirrorConfig }{}); err == nil {
+		t.Error("MirrorSpec(status missing a field) = nil, wanted error")
+	}
+	if _, err := MirrorSpec(spec, &struct {
+		ObservedConfig string
+		ObservedImage  string
+	}{}); err == nil {
+		t.Error("MirrorSpec(status with a mismatched field) = nil, wanted error")
+	}
+	if _, err := MirroredSpecChanged(spec, &struct{ ObservedImage string }{}); err == nil {
+		t.Error("MirroredSpecChanged(status without hash) = nil, wanted error")
+	}
+}
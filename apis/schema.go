@@ -0,0 +1,38 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+// The following are the canonical OpenAPI schema settings for fields whose
+// Go representation is parsed by this package or by the standard library.
+// Using them in CRD schemas makes the API server reject the same values
+// that parsing would reject at the Go level.
+const (
+	// URLSchemaFormat is the OpenAPI format of URL fields, which the API
+	// server validates the same way ParseURL parses them.
+	URLSchemaFormat = "uri"
+
+	// URLSchemaPattern additionally rejects the ASCII control characters
+	// refused by net/url.Parse, for servers that ignore the format.
+	// The empty string is accepted, as ParseURL maps it to a nil URL.
+	URLSchemaPattern = `^[^\x00-\x1f\x7f]*$`
+
+	// DurationSchemaPattern matches exactly the strings accepted by
+	// time.ParseDuration, which is how metav1.Duration and duration
+	// strings in Knative APIs are parsed. The OpenAPI "duration" format is
+	// deliberately not used since it also accepts units such as "d" and "w".
+	DurationSchemaPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+)
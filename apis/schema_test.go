@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestDurationSchemaPattern(t *testing.T) {
+	re := regexp.MustCompile(DurationSchemaPattern)
+	for _, s := range []string{
+		"", "0", "+0", "-0", "1", "1s", "-1.5h", "1.h", ".5m", "1h30m", "2h45m30.5s",
+		"10ns", "10us", "10µs", "10μs", "10ms", "1d", "1w", "h", ".", "1.5.5s", " 1s",
+		"1s ", "1S", "1 s", "--1s", "+-1s", "1m1", "3600",
+	} {
+		_, err := time.ParseDuration(s)
+		if got, want := re.MatchString(s), err == nil; got != want {
+			t.Errorf("pattern match of %q = %v, but time.ParseDuration() = %v", s, got, err)
+		}
+	}
+}
+
+func TestURLSchemaPattern(t *testing.T) {
+	re := regexp.MustCompile(URLSchemaPattern)
+	for _, s := range []string{
+		"", "http://example.com", "https://example.com:8443/path?query=1#frag",
+		"/relative/path", "example.com", "mailto:someone@example.com",
+		"http://example.com/\x7f", "http://example.com/\n", "http://example.com/\x00",
+	} {
+		_, err := ParseURL(s)
+		if got, want := re.MatchString(s), err == nil; got != want {
+			t.Errorf("pattern match of %q = %v, but ParseURL() = %v", s, got, err)
+		}
+	}
+}
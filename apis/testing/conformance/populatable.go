@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis/duck/ducktypes"
+)
+
+// Option configures Populatable.
+type Option func(*options)
+
+type options struct {
+	// ignored are the fields left out of the coverage check, keyed by
+	// struct type.
+	ignored map[reflect.Type]map[string]struct{}
+}
+
+// IgnoreFields leaves the given fields of the struct type of typ out of the
+// check that Populate sets all the fields, e.g. because they are meant to be
+// left empty.
+func IgnoreFields(typ interface{}, names ...string) Option {
+	return func(o *options) {
+		t := reflect.TypeOf(typ)
+		if o.ignored[t] == nil {
+			o.ignored[t] = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			o.ignored[t][name] = struct{}{}
+		}
+	}
+}
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+	// The object metadata is not part of the duck types' shape.
+	defaultIgnored = []reflect.Type{
+		reflect.TypeOf(metav1.TypeMeta{}),
+		reflect.TypeOf(metav1.ObjectMeta{}),
+	}
+)
+
+// Populatable verifies that the Populate method of the given duck type sets
+// all of its fields, down to the Kubernetes types it embeds, and that the
+// populated object survives JSON round trips and deep copies without losing
+// any of them. The value of p is only used for its type.
+func Populatable(t *testing.T, p ducktypes.Populatable, opts ...Option) {
+	t.Helper()
+
+	o := &options{ignored: make(map[reflect.Type]map[string]struct{})}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	obj := newLike(p)
+	obj.Populate()
+
+	if missing := o.unpopulated(reflect.ValueOf(obj), reflect.TypeOf(obj).Elem().Name()); len(missing) > 0 {
+		t.Errorf("%T.Populate() leaves fields unset: %s", obj, strings.Join(missing, ", "))
+	}
+
+	// knative.dev/pkg/apis.URL is an alias to net.URL which embeds a
+	// url.Userinfo that has an unexported field
+	cmpOpts := []cmp.Option{cmpopts.IgnoreUnexported(url.Userinfo{})}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Failed to marshal %T: %v", obj, err)
+	}
+	got := newLike(p)
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatalf("Failed to unmarshal %T: %v", obj, err)
+	}
+	if diff := cmp.Diff(obj, got, cmpOpts...); diff != "" {
+		t.Errorf("JSON round trip of %T lost fields (-want, +got): %s", obj, diff)
+	}
+
+	deepCopy := reflect.ValueOf(obj).MethodByName("DeepCopy")
+	if !deepCopy.IsValid() {
+		t.Errorf("%T has no DeepCopy method", obj)
+		return
+	}
+	if diff := cmp.Diff(obj, deepCopy.Call(nil)[0].Interface(), cmpOpts...); diff != "" {
+		t.Errorf("DeepCopy of %T lost fields (-want, +got): %s", obj, diff)
+	}
+}
+
+// unpopulated returns the paths of the fields of v left at their zero value.
+func (o *options) unpopulated(v reflect.Value, path string) []string {
+	typ := v.Type()
+	if typ.Implements(jsonMarshaler) || reflect.PtrTo(typ).Implements(jsonMarshaler) {
+		// Types with custom encodings, like metav1.Time or apis.URL, are
+		// only checked to be set.
+		if v.IsZero() {
+			return []string{path}
+		}
+		if typ.Kind() != reflect.Ptr {
+			return nil
+		}
+	}
+
+	if strings.HasPrefix(typ.PkgPath(), "k8s.io/") {
+		// The Kubernetes types embedded in duck types, like corev1.PodSpec,
+		// are only checked to be set, as their fields are not part of the
+		// duck types' own shape.
+		if v.IsZero() {
+			return []string{path}
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return []string{path}
+		}
+		return o.unpopulated(v.Elem(), path)
+
+	case reflect.Struct:
+		var missing []string
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() || f.Tag.Get("json") == "-" || o.isIgnored(typ, f) {
+				continue
+			}
+			missing = append(missing, o.unpopulated(v.Field(i), path+"."+f.Name)...)
+		}
+		return missing
+
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return []string{path}
+		}
+		var missing []string
+		for i := 0; i < v.Len(); i++ {
+			missing = append(missing, o.unpopulated(v.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return missing
+
+	case reflect.Map:
+		if v.Len() == 0 {
+			return []string{path}
+		}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			k := fmt.Sprint(it.Key().Interface())
+			keys = append(keys, k)
+			values[k] = it.Value()
+		}
+		sort.Strings(keys)
+		var missing []string
+		for _, k := range keys {
+			missing = append(missing, o.unpopulated(values[k], fmt.Sprintf("%s[%s]", path, k))...)
+		}
+		return missing
+
+	default:
+		if v.IsZero() {
+			return []string{path}
+		}
+		return nil
+	}
+}
+
+func (o *options) isIgnored(typ reflect.Type, f reflect.StructField) bool {
+	if _, ok := o.ignored[typ][f.Name]; ok {
+		return true
+	}
+	for _, t := range defaultIgnored {
+		if f.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// newLike returns a new zero value of the type pointed to by p.
+func newLike(p ducktypes.Populatable) ducktypes.Populatable {
+	return reflect.New(reflect.TypeOf(p).Elem()).Interface().(ducktypes.Populatable)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/pkg/apis"
+)
+
+type testSpec struct {
+	Name   string            `json:"name"`
+	URL    *apis.URL         `json:"url"`
+	Labels map[string]string `json:"labels"`
+	Ref    corev1.ObjectReference
+	Items  []testItem `json:"items"`
+	Skip   string     `json:"-"`
+}
+
+type testItem struct {
+	Count int `json:"count"`
+}
+
+type testResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec testSpec `json:"spec"`
+}
+
+func (t *testResource) Populate() {
+	t.Spec = testSpec{
+		Name:   "foo",
+		URL:    apis.HTTP("foo.com"),
+		Labels: map[string]string{"foo": "bar"},
+		Ref:    corev1.ObjectReference{Name: "foo"},
+		Items:  []testItem{{Count: 1}},
+	}
+}
+
+func (t *testResource) DeepCopy() *testResource {
+	out := *t
+	out.Spec.Labels = map[string]string{}
+	for k, v := range t.Spec.Labels {
+		out.Spec.Labels[k] = v
+	}
+	out.Spec.Items = append([]testItem(nil), t.Spec.Items...)
+	return &out
+}
+
+func (t *testResource) DeepCopyObject() runtime.Object {
+	return t.DeepCopy()
+}
+
+func (*testResource) GetListType() runtime.Object {
+	return &testResource{}
+}
+
+func TestPopulatable(t *testing.T) {
+	Populatable(t, &testResource{})
+}
+
+func TestUnpopulated(t *testing.T) {
+	tests := []struct {
+		name string
+		spec testSpec
+		opts []Option
+		want []string
+	}{{
+		name: "empty",
+		want: []string{"spec.Name", "spec.URL", "spec.Labels", "spec.Ref", "spec.Items"},
+	}, {
+		name: "nested",
+		spec: testSpec{
+			Name:   "foo",
+			URL:    apis.HTTP("foo.com"),
+			Labels: map[string]string{"foo": ""},
+			Ref:    corev1.ObjectReference{Name: "foo"},
+			Items:  []testItem{{Count: 1}, {}},
+		},
+		want: []string{"spec.Labels[foo]", "spec.Items[1].Count"},
+	}, {
+		name: "ignored",
+		spec: testSpec{
+			Name: "foo",
+			URL:  apis.HTTP("foo.com"),
+		},
+		opts: []Option{IgnoreFields(testSpec{}, "Labels", "Ref", "Items")},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := &options{ignored: make(map[reflect.Type]map[string]struct{})}
+			for _, opt := range test.opts {
+				opt(o)
+			}
+			got := o.unpopulated(reflect.ValueOf(test.spec), "spec")
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("unpopulated() = %v, wanted %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtrip
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/api/apitesting/roundtrip"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"knative.dev/pkg/apis"
+	pkgfuzzer "knative.dev/pkg/apis/testing/fuzzer"
+)
+
+// ConvertibleOption configures ConvertibleViaHub.
+type ConvertibleOption func(*convertibleOptions)
+
+type convertibleOptions struct {
+	fuzzerFuncs []fuzzer.FuzzerFuncs
+	skipFields  []*regexp.Regexp
+	cmpOpts     []cmp.Option
+	iterations  int
+}
+
+// WithFuzzerFuncs adds custom fuzzer functions, on top of the ones for
+// metav1 and knative.dev/pkg/apis types.
+func WithFuzzerFuncs(funcs fuzzer.FuzzerFuncs) ConvertibleOption {
+	return func(o *convertibleOptions) {
+		o.fuzzerFuncs = append(o.fuzzerFuncs, funcs)
+	}
+}
+
+// SkipFieldsWithPattern leaves the fields whose name matches the pattern
+// unset when fuzzing, e.g. because they are known not to be representable in
+// the hub version.
+func SkipFieldsWithPattern(pattern *regexp.Regexp) ConvertibleOption {
+	return func(o *convertibleOptions) {
+		o.skipFields = append(o.skipFields, pattern)
+	}
+}
+
+// IgnoreFields ignores the given fields of the struct type of typ when
+// comparing the round tripped object with the original, since they are known
+// to be lossy. See cmpopts.IgnoreFields.
+func IgnoreFields(typ interface{}, names ...string) ConvertibleOption {
+	return WithCompareOptions(cmpopts.IgnoreFields(typ, names...))
+}
+
+// WithCompareOptions adds options used when comparing the round tripped
+// object with the original.
+func WithCompareOptions(opts ...cmp.Option) ConvertibleOption {
+	return func(o *convertibleOptions) {
+		o.cmpOpts = append(o.cmpOpts, opts...)
+	}
+}
+
+// WithIterations sets the number of fuzzed objects round tripped per
+// version, which defaults to the value of the --fuzz-iters flag.
+func WithIterations(n int) ConvertibleOption {
+	return func(o *convertibleOptions) {
+		o.iterations = n
+	}
+}
+
+// ConvertibleViaHub applies the round-trip test to each of the given versions
+// of a Convertible type, which are expected to implement conversions to and
+// from the hub version as duck types do. This is effectively testing the
+// scenario:
+//
+//	version -> hub version -> version
+//
+// The values of versions and hub are only used for their type.
+func ConvertibleViaHub(t *testing.T, hub apis.Convertible, versions []apis.Convertible, opts ...ConvertibleOption) {
+	t.Helper()
+
+	o := &convertibleOptions{
+		fuzzerFuncs: []fuzzer.FuzzerFuncs{metafuzzer.Funcs, pkgfuzzer.Funcs},
+		iterations:  *roundtrip.FuzzIters,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	// knative.dev/pkg/apis.URL is an alias to net.URL which embeds a
+	// url.Userinfo that has an unexported field
+	cmpOpts := append([]cmp.Option{cmpopts.IgnoreUnexported(url.Userinfo{})}, o.cmpOpts...)
+
+	for _, version := range versions {
+		t.Run(fmt.Sprintf("%T", version), func(t *testing.T) {
+			for i := 0; i < o.iterations; i++ {
+				seed := rand.Int63()
+				convertibleViaHub(t, hub, version, func(obj apis.Convertible) {
+					o.fuzzer(seed).Fuzz(obj)
+				}, cmpOpts)
+
+				if t.Failed() {
+					t.Log("Failed with seed", seed)
+					break
+				}
+			}
+		})
+	}
+}
+
+// fuzzer returns a fuzzer for the given seed, so that fuzzing two objects
+// with fuzzers of the same seed produces equal objects.
+func (o *convertibleOptions) fuzzer(seed int64) *fuzz.Fuzzer {
+	f := fuzzer.FuzzerFor(
+		fuzzer.MergeFuzzerFuncs(o.fuzzerFuncs...),
+		rand.NewSource(seed),
+		// This seems to be used for protobuf not json
+		serializer.NewCodecFactory(runtime.NewScheme()),
+	)
+	for _, pattern := range o.skipFields {
+		f.SkipFieldsWithPattern(pattern)
+	}
+	return f
+}
+
+func convertibleViaHub(t *testing.T, hub, version apis.Convertible, fuzzObj func(apis.Convertible), cmpOpts []cmp.Option) {
+	t.Helper()
+	ctx := context.Background()
+
+	obj, original := newLike(version), newLike(version)
+	fuzzObj(obj)
+	fuzzObj(original)
+
+	sink := newLike(hub)
+	if err := obj.ConvertTo(ctx, sink); err != nil {
+		t.Errorf("Conversion to hub (%T) failed: %v", sink, err)
+		return
+	}
+	if diff := cmp.Diff(original, obj, cmpOpts...); diff != "" {
+		t.Errorf("Conversion to hub (%T) modified the original object (ConvertTo should not have side-effects), diff: %s", sink, diff)
+		return
+	}
+
+	got := newLike(version)
+	if err := got.ConvertFrom(ctx, sink); err != nil {
+		t.Errorf("Conversion from hub (%T) failed: %v", sink, err)
+		return
+	}
+	if diff := cmp.Diff(original, got, cmpOpts...); diff != "" {
+		t.Errorf("round trip through hub (%T) produced a diff: %s", sink, diff)
+	}
+}
+
+// newLike returns a new zero value of the type pointed to by c.
+func newLike(c apis.Convertible) apis.Convertible {
+	return reflect.New(reflect.TypeOf(c).Elem()).Interface().(apis.Convertible)
+}
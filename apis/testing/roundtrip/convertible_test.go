@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtrip
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/pkg/apis"
+)
+
+type hubThing struct {
+	URL   *apis.URL
+	Count int
+}
+
+func (*hubThing) ConvertTo(context.Context, apis.Convertible) error {
+	return errors.New("hub")
+}
+
+func (*hubThing) ConvertFrom(context.Context, apis.Convertible) error {
+	return errors.New("hub")
+}
+
+type oldThing struct {
+	URL   *apis.URL
+	Count int
+	// Lossy is not representable in the hub.
+	Lossy string
+}
+
+func (o *oldThing) ConvertTo(_ context.Context, to apis.Convertible) error {
+	sink := to.(*hubThing)
+	sink.URL = o.URL.DeepCopy()
+	sink.Count = o.Count
+	return nil
+}
+
+func (o *oldThing) ConvertFrom(_ context.Context, from apis.Convertible) error {
+	source := from.(*hubThing)
+	o.URL = source.URL.DeepCopy()
+	o.Count = source.Count
+	return nil
+}
+
+func TestConvertibleViaHub(t *testing.T) {
+	ConvertibleViaHub(t, &hubThing{}, []apis.Convertible{&oldThing{}},
+		IgnoreFields(oldThing{}, "Lossy"),
+		WithIterations(50))
+}
+
+func TestConvertibleViaHubDetectsLoss(t *testing.T) {
+	o := &convertibleOptions{}
+	ft := &testing.T{}
+	convertibleViaHub(ft, &hubThing{}, &oldThing{}, func(obj apis.Convertible) {
+		obj.(*oldThing).Lossy = "lost"
+	}, nil)
+	if !ft.Failed() {
+		t.Error("convertibleViaHub() did not fail for a lossy field")
+	}
+
+	// The same seed produces the same objects.
+	a, b := &oldThing{}, &oldThing{}
+	o.fuzzer(42).Fuzz(a)
+	o.fuzzer(42).Fuzz(b)
+	if !cmp.Equal(a, b) {
+		t.Error("fuzzer() is not deterministic (-a, +b):", cmp.Diff(a, b))
+	}
+}
@@ -22,6 +22,7 @@ import (
 	context "context"
 
 	v1beta1 "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1beta1"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	factory "knative.dev/pkg/client/injection/apiextensions/informers/factory"
 	controller "knative.dev/pkg/controller"
 	injection "knative.dev/pkg/injection"
@@ -30,6 +31,7 @@ import (
 
 func init() {
 	injection.Default.RegisterInformer(withInformer)
+	injection.RegisterKey("knative.dev/pkg/client/injection/apiextensions/informers/apiextensions/v1beta1/customresourcedefinition", Key{})
 }
 
 // Key is used for associating the Informer inside the context.Context.
@@ -38,7 +40,11 @@ type Key struct{}
 func withInformer(ctx context.Context) (context.Context, controller.Informer) {
 	f := factory.Get(ctx)
 	inf := f.Apiextensions().V1beta1().CustomResourceDefinitions()
-	return context.WithValue(ctx, Key{}, inf), inf.Informer()
+	return context.WithValue(ctx, Key{}, inf), controller.WithResource(inf.Informer(), schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1beta1",
+		Resource: "customresourcedefinitions",
+	})
 }
 
 // Get extracts the typed informer from the context.
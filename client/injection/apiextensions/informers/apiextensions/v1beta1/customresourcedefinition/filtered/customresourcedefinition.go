@@ -22,6 +22,7 @@ import (
 	context "context"
 
 	v1beta1 "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1beta1"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	filtered "knative.dev/pkg/client/injection/apiextensions/informers/factory/filtered"
 	controller "knative.dev/pkg/controller"
 	injection "knative.dev/pkg/injection"
@@ -49,7 +50,11 @@ func withInformer(ctx context.Context) (context.Context, []controller.Informer)
 		f := filtered.Get(ctx, selector)
 		inf := f.Apiextensions().V1beta1().CustomResourceDefinitions()
 		ctx = context.WithValue(ctx, Key{Selector: selector}, inf)
-		infs = append(infs, inf.Informer())
+		infs = append(infs, controller.WithResource(inf.Informer(), schema.GroupVersionResource{
+			Group:    "apiextensions.k8s.io",
+			Version:  "v1beta1",
+			Resource: "customresourcedefinitions",
+		}))
 	}
 	return ctx, infs
 }
@@ -65,6 +65,22 @@ type Finalizer interface {
 	FinalizeKind(ctx context.Context, o *v1.CustomResourceDefinition) reconciler.Event
 }
 
+// NamedFinalizers defines the strongly typed interfaces to be implemented by a
+// controller finalizing v1.CustomResourceDefinition with several finalizers, e.g. one per
+// external system, independently of each other and of Finalizer.
+type NamedFinalizers interface {
+	// FinalizerNames returns the names of the finalizers to set on v1.CustomResourceDefinition.
+	FinalizerNames() []string
+
+	// FinalizeNamed implements custom logic to finalize v1.CustomResourceDefinition for the
+	// finalizer of the given name. Any changes to the objects .Status or
+	// .Finalizers will be ignored. Returning a nil or Normal type
+	// reconciler.Event will allow that finalizer to be deleted on the
+	// resource. The resource passed to FinalizeNamed will always have a set
+	// deletion timestamp.
+	FinalizeNamed(ctx context.Context, name string, o *v1.CustomResourceDefinition) reconciler.Event
+}
+
 // ReadOnlyInterface defines the strongly typed interfaces to be implemented by a
 // controller reconciling v1.CustomResourceDefinition if they want to process resources for which
 // they are not the leader.
@@ -76,6 +92,18 @@ type ReadOnlyInterface interface {
 
 type doReconcile func(ctx context.Context, o *v1.CustomResourceDefinition) reconciler.Event
 
+// withHooks returns do run between the hooks, as the named method.
+func (do doReconcile) withHooks(hooks []reconciler.ReconcileHook, method string) doReconcile {
+	if do == nil || len(hooks) == 0 {
+		return do
+	}
+	return func(ctx context.Context, o *v1.CustomResourceDefinition) reconciler.Event {
+		return reconciler.RunReconcileHooks(ctx, hooks, method, o, func(ctx context.Context) reconciler.Event {
+			return do(ctx, o)
+		})
+	}
+}
+
 // reconcilerImpl implements controller.Reconciler for v1.CustomResourceDefinition resources.
 type reconcilerImpl struct {
 	// LeaderAwareFuncs is inlined to help us implement reconciler.LeaderAware.
@@ -101,9 +129,29 @@ type reconcilerImpl struct {
 	// finalizerName is the name of the finalizer to reconcile.
 	finalizerName string
 
+	// readinessGates are the external dependencies which must be ready
+	// before reconciling the resources.
+	readinessGates []reconciler.ReadinessGate
+
+	// hooks are run around every call of the methods of the reconciler.
+	hooks []reconciler.ReconcileHook
+
+	// observationStore shares the observations of the resources by the
+	// replicas, if set.
+	// +optional
+	observationStore reconciler.ObservationStore
+
 	// skipStatusUpdates configures whether or not this reconciler automatically updates
 	// the status of the reconciled resource.
 	skipStatusUpdates bool
+
+	// statusFieldManager is the field manager this reconciler applies the
+	// status of the reconciled resource as, with server-side apply, if any.
+	statusFieldManager string
+
+	// statusConflictStrategy is how this reconciler resolves the conflicts
+	// of the status updates of the reconciled resource.
+	statusConflictStrategy reconciler.StatusConflictStrategy
 }
 
 // Check that our Reconciler implements controller.Reconciler.
@@ -158,9 +206,24 @@ func NewReconciler(ctx context.Context, logger *zap.SugaredLogger, client client
 		if opts.SkipStatusUpdates {
 			rec.skipStatusUpdates = true
 		}
+		if opts.StatusFieldManager != "" {
+			rec.statusFieldManager = opts.StatusFieldManager
+		}
+		if opts.StatusConflictStrategy != "" {
+			rec.statusConflictStrategy = opts.StatusConflictStrategy
+		}
 		if opts.DemoteFunc != nil {
 			rec.DemoteFunc = opts.DemoteFunc
 		}
+		if len(opts.ReadinessGates) > 0 {
+			rec.readinessGates = opts.ReadinessGates
+		}
+		if len(opts.ReconcileHooks) > 0 {
+			rec.hooks = opts.ReconcileHooks
+		}
+		if opts.ObservationStore != nil {
+			rec.observationStore = opts.ObservationStore
+		}
 	}
 
 	return rec
@@ -191,6 +254,11 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 		ctx = r.configStore.ToContext(ctx)
 	}
 
+	// If observationStore is set, attach it to the context.
+	if r.observationStore != nil {
+		ctx = reconciler.WithObservationStore(ctx, r.observationStore)
+	}
+
 	// Add the recorder to context.
 	ctx = controller.WithEventRecorder(ctx, r.Recorder)
 
@@ -221,15 +289,38 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 	var reconcileEvent reconciler.Event
 
 	name, do := s.reconcileMethodFor(resource)
+	do = do.withHooks(r.hooks, name)
 	// Append the target method to the logger.
 	logger = logger.With(zap.String("targetMethod", name))
 	switch name {
 	case reconciler.DoReconcileKind:
+		// Skip the reconciliation of paused resources, so that operators
+		// can freeze individual resources.
+		if reconciler.IsPaused(resource) {
+			logger.Debugf("Skip reconciling resource %q, its reconciliation is paused", key)
+			reconciler.ReportPaused(ctx, defaultControllerAgentName, s.namespace)
+
+			break
+		}
+
+		// Wait for the external dependencies of the reconciler to be ready.
+		if gate, err := reconciler.CheckReadinessGates(ctx, defaultControllerAgentName, r.readinessGates); err != nil {
+			logger.Infof("Skip reconciling resource %q, readiness gate %q is not ready: %v", key, gate.Name, err)
+
+			reconcileEvent = controller.NewRequeueAfter(reconciler.ReadinessGateRequeueDelay)
+			break
+		}
+
 		// Set and update the finalizer on resource if r.reconciler
 		// implements Finalizer.
 		if resource, err = r.setFinalizerIfFinalizer(ctx, resource); err != nil {
 			return fmt.Errorf("failed to set finalizers: %w", err)
 		}
+		// Set and update the finalizers on resource if r.reconciler
+		// implements NamedFinalizers.
+		if resource, err = r.setNamedFinalizers(ctx, resource); err != nil {
+			return fmt.Errorf("failed to set finalizers: %w", err)
+		}
 
 		// Reconcile this copy of the resource and then write back any status
 		// updates regardless of whether the reconciliation errored out.
@@ -244,6 +335,18 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 			return fmt.Errorf("failed to clear finalizers: %w", err)
 		}
 
+		// Finalize each of the named finalizers, removing those which
+		// finalized cleanly.
+		var namedEvent reconciler.Event
+		if resource, namedEvent, err = r.finalizeNamed(ctx, resource); err != nil {
+			return fmt.Errorf("failed to clear finalizers: %w", err)
+		}
+		// The failure of a named finalizer prevails over a clean event of
+		// FinalizeKind, so that the resource is finalized again.
+		if namedEvent != nil && reconciler.IsCleanEvent(reconcileEvent) {
+			reconcileEvent = namedEvent
+		}
+
 	case reconciler.DoObserveKind:
 		// Observe any changes to this resource, since we are not the leader.
 		reconcileEvent = do(ctx, resource)
@@ -275,6 +378,18 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 
 	// Report the reconciler event, if any.
 	if reconcileEvent != nil {
+		var structured *reconciler.StructuredEvent
+		if reconciler.EventAs(reconcileEvent, &structured) {
+			logger.Infow("Returned a structured event", zap.Any("event", reconcileEvent))
+			structured.Record(ctx, r.Recorder, defaultControllerAgentName, resource)
+
+			// the event was wrapped inside an error, consider the reconciliation as failed
+			if _, isEvent := reconcileEvent.(*reconciler.StructuredEvent); !isEvent {
+				return reconcileEvent
+			}
+			return nil
+		}
+
 		var event *reconciler.ReconcilerEvent
 		if reconciler.EventAs(reconcileEvent, &event) {
 			logger.Infow("Returned an event", zap.Any("event", reconcileEvent))
@@ -302,8 +417,15 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 }
 
 func (r *reconcilerImpl) updateStatus(ctx context.Context, logger *zap.SugaredLogger, existing *v1.CustomResourceDefinition, desired *v1.CustomResourceDefinition) error {
+	if r.statusFieldManager != "" {
+		return r.applyStatus(ctx, logger, existing, desired)
+	}
+
+	original := existing
 	existing = existing.DeepCopy()
 	return reconciler.RetryUpdateConflicts(func(attempts int) (err error) {
+		want := desired
+
 		// The first iteration tries to use the injectionInformer's state, subsequent attempts fetch the latest state via API.
 		if attempts > 0 {
 
@@ -313,20 +435,26 @@ func (r *reconcilerImpl) updateStatus(ctx context.Context, logger *zap.SugaredLo
 			if err != nil {
 				return err
 			}
+
+			// Resolve the conflict with the status written since it was read.
+			want = desired.DeepCopy()
+			if err := r.statusConflictStrategy.Resolve(&original.Status, &existing.Status, &want.Status); err != nil {
+				return err
+			}
 		}
 
 		// If there's nothing to update, just return.
-		if equality.Semantic.DeepEqual(existing.Status, desired.Status) {
+		if equality.Semantic.DeepEqual(existing.Status, want.Status) {
 			return nil
 		}
 
 		if logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
-			if diff, err := kmp.SafeDiff(existing.Status, desired.Status); err == nil && diff != "" {
+			if diff, err := kmp.SafeDiff(existing.Status, want.Status); err == nil && diff != "" {
 				logger.Debug("Updating status with: ", diff)
 			}
 		}
 
-		existing.Status = desired.Status
+		existing.Status = want.Status
 
 		updater := r.Client.ApiextensionsV1().CustomResourceDefinitions()
 
@@ -335,6 +463,31 @@ func (r *reconcilerImpl) updateStatus(ctx context.Context, logger *zap.SugaredLo
 	})
 }
 
+// applyStatus applies the status of desired with server-side apply, as
+// r.statusFieldManager, leaving alone the status fields owned by the other
+// field managers.
+func (r *reconcilerImpl) applyStatus(ctx context.Context, logger *zap.SugaredLogger, existing *v1.CustomResourceDefinition, desired *v1.CustomResourceDefinition) error {
+	if logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
+		if diff, err := kmp.SafeDiff(existing.Status, desired.Status); err == nil && diff != "" {
+			logger.Debug("Applying status with: ", diff)
+		}
+	}
+
+	patch, err := reconciler.StatusApplyPatch(v1.SchemeGroupVersion.WithKind("CustomResourceDefinition"), r.statusFieldManager, existing, existing.Status, desired.Status)
+	if err != nil {
+		return err
+	}
+
+	patcher := r.Client.ApiextensionsV1().CustomResourceDefinitions()
+
+	force := true
+	_, err = patcher.Patch(ctx, existing.Name, types.ApplyPatchType, patch, metav1.PatchOptions{
+		FieldManager: r.statusFieldManager,
+		Force:        &force,
+	}, "status")
+	return err
+}
+
 // updateFinalizersFiltered will update the Finalizers of the resource.
 // TODO: this method could be generic and sync all finalizers. For now it only
 // updates defaultFinalizerName or its override.
@@ -364,10 +517,15 @@ func (r *reconcilerImpl) updateFinalizersFiltered(ctx context.Context, resource
 		finalizers = existingFinalizers.List()
 	}
 
+	return r.patchFinalizers(ctx, resource, finalizers)
+}
+
+// patchFinalizers will set the Finalizers of the resource to finalizers.
+func (r *reconcilerImpl) patchFinalizers(ctx context.Context, resource *v1.CustomResourceDefinition, finalizers []string) (*v1.CustomResourceDefinition, error) {
 	mergePatch := map[string]interface{}{
 		"metadata": map[string]interface{}{
 			"finalizers":      finalizers,
-			"resourceVersion": existing.ResourceVersion,
+			"resourceVersion": resource.ResourceVersion,
 		},
 	}
 
@@ -381,7 +539,7 @@ func (r *reconcilerImpl) updateFinalizersFiltered(ctx context.Context, resource
 	resourceName := resource.Name
 	updated, err := patcher.Patch(ctx, resourceName, types.MergePatchType, patch, metav1.PatchOptions{})
 	if err != nil {
-		r.Recorder.Eventf(existing, corev1.EventTypeWarning, "FinalizerUpdateFailed",
+		r.Recorder.Eventf(resource, corev1.EventTypeWarning, "FinalizerUpdateFailed",
 			"Failed to update finalizers for %q: %v", resourceName, err)
 	} else {
 		r.Recorder.Eventf(updated, corev1.EventTypeNormal, "FinalizerUpdate",
@@ -430,3 +588,38 @@ func (r *reconcilerImpl) clearFinalizer(ctx context.Context, resource *v1.Custom
 	// Synchronize the finalizers filtered by r.finalizerName.
 	return r.updateFinalizersFiltered(ctx, resource, finalizers)
 }
+
+func (r *reconcilerImpl) setNamedFinalizers(ctx context.Context, resource *v1.CustomResourceDefinition) (*v1.CustomResourceDefinition, error) {
+	nf, ok := r.reconciler.(NamedFinalizers)
+	if !ok || !resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil
+	}
+
+	finalizers, changed := reconciler.NewFinalizerRegistry(nf.FinalizerNames()...).Ensure(resource.Finalizers)
+	if !changed {
+		return resource, nil
+	}
+	return r.patchFinalizers(ctx, resource, finalizers)
+}
+
+func (r *reconcilerImpl) finalizeNamed(ctx context.Context, resource *v1.CustomResourceDefinition) (*v1.CustomResourceDefinition, reconciler.Event, error) {
+	nf, ok := r.reconciler.(NamedFinalizers)
+	if !ok || resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil, nil
+	}
+
+	finalizers, changed, event := reconciler.NewFinalizerRegistry(nf.FinalizerNames()...).Finalize(resource.Finalizers, func(name string) reconciler.Event {
+		return nf.FinalizeNamed(ctx, name, resource)
+	})
+	if !changed {
+		return resource, event, nil
+	}
+	resource, err := r.patchFinalizers(ctx, resource, finalizers)
+	return resource, event, err
+}
+
+// finalizeNamedOnly is the FinalizeKind of the reconcilers which implement
+// NamedFinalizers but not Finalizer.
+func finalizeNamedOnly(context.Context, *v1.CustomResourceDefinition) reconciler.Event {
+	return nil
+}
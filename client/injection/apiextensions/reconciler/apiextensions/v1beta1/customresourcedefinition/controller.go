@@ -27,6 +27,7 @@ import (
 	zap "go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	clientsetscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
+	v1beta1 "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1beta1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -35,6 +36,7 @@ import (
 	record "k8s.io/client-go/tools/record"
 	client "knative.dev/pkg/client/injection/apiextensions/client"
 	customresourcedefinition "knative.dev/pkg/client/injection/apiextensions/informers/apiextensions/v1beta1/customresourcedefinition"
+	filtered "knative.dev/pkg/client/injection/apiextensions/informers/apiextensions/v1beta1/customresourcedefinition/filtered"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	controller "knative.dev/pkg/controller"
 	logging "knative.dev/pkg/logging"
@@ -52,6 +54,19 @@ const (
 // the provided Interface and optional Finalizer methods. OptionsFn is used to return
 // controller.ControllerOptions to be used by the internal reconciler.
 func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsFn) *controller.Impl {
+	return newImpl(ctx, r, customresourcedefinition.Get(ctx).Lister(), optionsFns...)
+}
+
+// NewFilteredImpl returns a controller.Impl like NewImpl, wired against the
+// filtered informer of the given label selector, which must be among those
+// of the context, so that it only sees the resources selected, e.g. those
+// of a tenant. The informer is also restricted to the namespace scope of the
+// context, if any.
+func NewFilteredImpl(ctx context.Context, r Interface, selector string, optionsFns ...controller.OptionsFn) *controller.Impl {
+	return newImpl(ctx, r, filtered.Get(ctx, selector).Lister(), optionsFns...)
+}
+
+func newImpl(ctx context.Context, r Interface, lister v1beta1.CustomResourceDefinitionLister, optionsFns ...controller.OptionsFn) *controller.Impl {
 	logger := logging.FromContext(ctx)
 
 	// Check the options function input. It should be 0 or 1.
@@ -59,10 +74,6 @@ func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsF
 		logger.Fatal("Up to one options function is supported, found: ", len(optionsFns))
 	}
 
-	customresourcedefinitionInformer := customresourcedefinition.Get(ctx)
-
-	lister := customresourcedefinitionInformer.Lister()
-
 	var promoteFilterFunc func(obj interface{}) bool
 	var promoteFunc = func(bkt reconciler.Bucket) {}
 
@@ -124,6 +135,12 @@ func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsF
 		if opts.SkipStatusUpdates {
 			rec.skipStatusUpdates = true
 		}
+		if opts.StatusFieldManager != "" {
+			rec.statusFieldManager = opts.StatusFieldManager
+		}
+		if opts.StatusConflictStrategy != "" {
+			rec.statusConflictStrategy = opts.StatusConflictStrategy
+		}
 		if opts.DemoteFunc != nil {
 			rec.DemoteFunc = opts.DemoteFunc
 		}
@@ -133,6 +150,15 @@ func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsF
 		if opts.PromoteFunc != nil {
 			promoteFunc = opts.PromoteFunc
 		}
+		if len(opts.ReadinessGates) > 0 {
+			rec.readinessGates = opts.ReadinessGates
+		}
+		if len(opts.ReconcileHooks) > 0 {
+			rec.hooks = opts.ReconcileHooks
+		}
+		if opts.ObservationStore != nil {
+			rec.observationStore = opts.ObservationStore
+		}
 	}
 
 	rec.Recorder = createRecorder(ctx, agentName)
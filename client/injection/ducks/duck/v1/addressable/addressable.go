@@ -31,6 +31,7 @@ import (
 
 func init() {
 	injection.Default.RegisterDuck(WithDuck)
+	injection.RegisterKey("knative.dev/pkg/client/injection/ducks/duck/v1/addressable", Key{})
 }
 
 // Key is used for associating the Informer inside the context.Context.
@@ -32,6 +32,7 @@ func init() {
 	injection.Default.RegisterClientFetcher(func(ctx context.Context) interface{} {
 		return Get(ctx)
 	})
+	injection.RegisterKey("knative.dev/pkg/client/injection/kube/client", Key{})
 }
 
 // Key is used as the key for associating information with a context.Context.
@@ -21,6 +21,7 @@ package filtered
 import (
 	context "context"
 
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	v1 "k8s.io/client-go/informers/admissionregistration/v1"
 	filtered "knative.dev/pkg/client/injection/kube/informers/factory/filtered"
 	controller "knative.dev/pkg/controller"
@@ -49,7 +50,11 @@ func withInformer(ctx context.Context) (context.Context, []controller.Informer)
 		f := filtered.Get(ctx, selector)
 		inf := f.Admissionregistration().V1().MutatingWebhookConfigurations()
 		ctx = context.WithValue(ctx, Key{Selector: selector}, inf)
-		infs = append(infs, inf.Informer())
+		infs = append(infs, controller.WithResource(inf.Informer(), schema.GroupVersionResource{
+			Group:    "admissionregistration.k8s.io",
+			Version:  "v1",
+			Resource: "mutatingwebhookconfigurations",
+		}))
 	}
 	return ctx, infs
 }
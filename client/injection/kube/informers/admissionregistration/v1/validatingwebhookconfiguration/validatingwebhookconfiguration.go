@@ -21,6 +21,7 @@ package validatingwebhookconfiguration
 import (
 	context "context"
 
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	v1 "k8s.io/client-go/informers/admissionregistration/v1"
 	factory "knative.dev/pkg/client/injection/kube/informers/factory"
 	controller "knative.dev/pkg/controller"
@@ -30,6 +31,7 @@ import (
 
 func init() {
 	injection.Default.RegisterInformer(withInformer)
+	injection.RegisterKey("knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1/validatingwebhookconfiguration", Key{})
 }
 
 // Key is used for associating the Informer inside the context.Context.
@@ -38,7 +40,11 @@ type Key struct{}
 func withInformer(ctx context.Context) (context.Context, controller.Informer) {
 	f := factory.Get(ctx)
 	inf := f.Admissionregistration().V1().ValidatingWebhookConfigurations()
-	return context.WithValue(ctx, Key{}, inf), inf.Informer()
+	return context.WithValue(ctx, Key{}, inf), controller.WithResource(inf.Informer(), schema.GroupVersionResource{
+		Group:    "admissionregistration.k8s.io",
+		Version:  "v1",
+		Resource: "validatingwebhookconfigurations",
+	})
 }
 
 // Get extracts the typed informer from the context.
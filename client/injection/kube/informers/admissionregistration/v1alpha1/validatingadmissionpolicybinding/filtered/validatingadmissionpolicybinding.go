@@ -21,6 +21,7 @@ package filtered
 import (
 	context "context"
 
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	v1alpha1 "k8s.io/client-go/informers/admissionregistration/v1alpha1"
 	filtered "knative.dev/pkg/client/injection/kube/informers/factory/filtered"
 	controller "knative.dev/pkg/controller"
@@ -49,7 +50,11 @@ func withInformer(ctx context.Context) (context.Context, []controller.Informer)
 		f := filtered.Get(ctx, selector)
 		inf := f.Admissionregistration().V1alpha1().ValidatingAdmissionPolicyBindings()
 		ctx = context.WithValue(ctx, Key{Selector: selector}, inf)
-		infs = append(infs, inf.Informer())
+		infs = append(infs, controller.WithResource(inf.Informer(), schema.GroupVersionResource{
+			Group:    "admissionregistration.k8s.io",
+			Version:  "v1alpha1",
+			Resource: "validatingadmissionpolicybindings",
+		}))
 	}
 	return ctx, infs
 }
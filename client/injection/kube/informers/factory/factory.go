@@ -30,6 +30,7 @@ import (
 
 func init() {
 	injection.Default.RegisterInformerFactory(withInformerFactory)
+	injection.RegisterKey("knative.dev/pkg/client/injection/kube/informers/factory", Key{})
 }
 
 // Key is used as the key for associating information with a context.Context.
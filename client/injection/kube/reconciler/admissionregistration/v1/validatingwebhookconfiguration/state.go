@@ -92,6 +92,8 @@ func (s *state) reconcileMethodFor(o *v1.ValidatingWebhookConfiguration) (string
 		}
 	} else if fin, ok := s.reconciler.(Finalizer); s.isLeader && ok {
 		return reconciler.DoFinalizeKind, fin.FinalizeKind
+	} else if _, ok := s.reconciler.(NamedFinalizers); s.isLeader && ok {
+		return reconciler.DoFinalizeKind, finalizeNamedOnly
 	}
 	return "unknown", nil
 }
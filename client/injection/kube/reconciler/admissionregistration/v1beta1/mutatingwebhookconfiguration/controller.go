@@ -31,9 +31,11 @@ import (
 	watch "k8s.io/apimachinery/pkg/watch"
 	scheme "k8s.io/client-go/kubernetes/scheme"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	v1beta1 "k8s.io/client-go/listers/admissionregistration/v1beta1"
 	record "k8s.io/client-go/tools/record"
 	client "knative.dev/pkg/client/injection/kube/client"
 	mutatingwebhookconfiguration "knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1beta1/mutatingwebhookconfiguration"
+	filtered "knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1beta1/mutatingwebhookconfiguration/filtered"
 	controller "knative.dev/pkg/controller"
 	logging "knative.dev/pkg/logging"
 	logkey "knative.dev/pkg/logging/logkey"
@@ -50,6 +52,19 @@ const (
 // the provided Interface and optional Finalizer methods. OptionsFn is used to return
 // controller.ControllerOptions to be used by the internal reconciler.
 func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsFn) *controller.Impl {
+	return newImpl(ctx, r, mutatingwebhookconfiguration.Get(ctx).Lister(), optionsFns...)
+}
+
+// NewFilteredImpl returns a controller.Impl like NewImpl, wired against the
+// filtered informer of the given label selector, which must be among those
+// of the context, so that it only sees the resources selected, e.g. those
+// of a tenant. The informer is also restricted to the namespace scope of the
+// context, if any.
+func NewFilteredImpl(ctx context.Context, r Interface, selector string, optionsFns ...controller.OptionsFn) *controller.Impl {
+	return newImpl(ctx, r, filtered.Get(ctx, selector).Lister(), optionsFns...)
+}
+
+func newImpl(ctx context.Context, r Interface, lister v1beta1.MutatingWebhookConfigurationLister, optionsFns ...controller.OptionsFn) *controller.Impl {
 	logger := logging.FromContext(ctx)
 
 	// Check the options function input. It should be 0 or 1.
@@ -57,10 +72,6 @@ func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsF
 		logger.Fatal("Up to one options function is supported, found: ", len(optionsFns))
 	}
 
-	mutatingwebhookconfigurationInformer := mutatingwebhookconfiguration.Get(ctx)
-
-	lister := mutatingwebhookconfigurationInformer.Lister()
-
 	var promoteFilterFunc func(obj interface{}) bool
 	var promoteFunc = func(bkt reconciler.Bucket) {}
 
@@ -128,6 +139,15 @@ func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsF
 		if opts.PromoteFunc != nil {
 			promoteFunc = opts.PromoteFunc
 		}
+		if len(opts.ReadinessGates) > 0 {
+			rec.readinessGates = opts.ReadinessGates
+		}
+		if len(opts.ReconcileHooks) > 0 {
+			rec.hooks = opts.ReconcileHooks
+		}
+		if opts.ObservationStore != nil {
+			rec.observationStore = opts.ObservationStore
+		}
 	}
 
 	rec.Recorder = createRecorder(ctx, agentName)
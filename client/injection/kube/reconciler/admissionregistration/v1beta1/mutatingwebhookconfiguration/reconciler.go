@@ -62,6 +62,22 @@ type Finalizer interface {
 	FinalizeKind(ctx context.Context, o *v1beta1.MutatingWebhookConfiguration) reconciler.Event
 }
 
+// NamedFinalizers defines the strongly typed interfaces to be implemented by a
+// controller finalizing v1beta1.MutatingWebhookConfiguration with several finalizers, e.g. one per
+// external system, independently of each other and of Finalizer.
+type NamedFinalizers interface {
+	// FinalizerNames returns the names of the finalizers to set on v1beta1.MutatingWebhookConfiguration.
+	FinalizerNames() []string
+
+	// FinalizeNamed implements custom logic to finalize v1beta1.MutatingWebhookConfiguration for the
+	// finalizer of the given name. Any changes to the objects .Status or
+	// .Finalizers will be ignored. Returning a nil or Normal type
+	// reconciler.Event will allow that finalizer to be deleted on the
+	// resource. The resource passed to FinalizeNamed will always have a set
+	// deletion timestamp.
+	FinalizeNamed(ctx context.Context, name string, o *v1beta1.MutatingWebhookConfiguration) reconciler.Event
+}
+
 // ReadOnlyInterface defines the strongly typed interfaces to be implemented by a
 // controller reconciling v1beta1.MutatingWebhookConfiguration if they want to process resources for which
 // they are not the leader.
@@ -73,6 +89,18 @@ type ReadOnlyInterface interface {
 
 type doReconcile func(ctx context.Context, o *v1beta1.MutatingWebhookConfiguration) reconciler.Event
 
+// withHooks returns do run between the hooks, as the named method.
+func (do doReconcile) withHooks(hooks []reconciler.ReconcileHook, method string) doReconcile {
+	if do == nil || len(hooks) == 0 {
+		return do
+	}
+	return func(ctx context.Context, o *v1beta1.MutatingWebhookConfiguration) reconciler.Event {
+		return reconciler.RunReconcileHooks(ctx, hooks, method, o, func(ctx context.Context) reconciler.Event {
+			return do(ctx, o)
+		})
+	}
+}
+
 // reconcilerImpl implements controller.Reconciler for v1beta1.MutatingWebhookConfiguration resources.
 type reconcilerImpl struct {
 	// LeaderAwareFuncs is inlined to help us implement reconciler.LeaderAware.
@@ -97,6 +125,18 @@ type reconcilerImpl struct {
 
 	// finalizerName is the name of the finalizer to reconcile.
 	finalizerName string
+
+	// readinessGates are the external dependencies which must be ready
+	// before reconciling the resources.
+	readinessGates []reconciler.ReadinessGate
+
+	// hooks are run around every call of the methods of the reconciler.
+	hooks []reconciler.ReconcileHook
+
+	// observationStore shares the observations of the resources by the
+	// replicas, if set.
+	// +optional
+	observationStore reconciler.ObservationStore
 }
 
 // Check that our Reconciler implements controller.Reconciler.
@@ -151,6 +191,15 @@ func NewReconciler(ctx context.Context, logger *zap.SugaredLogger, client kubern
 		if opts.DemoteFunc != nil {
 			rec.DemoteFunc = opts.DemoteFunc
 		}
+		if len(opts.ReadinessGates) > 0 {
+			rec.readinessGates = opts.ReadinessGates
+		}
+		if len(opts.ReconcileHooks) > 0 {
+			rec.hooks = opts.ReconcileHooks
+		}
+		if opts.ObservationStore != nil {
+			rec.observationStore = opts.ObservationStore
+		}
 	}
 
 	return rec
@@ -181,6 +230,11 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 		ctx = r.configStore.ToContext(ctx)
 	}
 
+	// If observationStore is set, attach it to the context.
+	if r.observationStore != nil {
+		ctx = reconciler.WithObservationStore(ctx, r.observationStore)
+	}
+
 	// Add the recorder to context.
 	ctx = controller.WithEventRecorder(ctx, r.Recorder)
 
@@ -211,15 +265,38 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 	var reconcileEvent reconciler.Event
 
 	name, do := s.reconcileMethodFor(resource)
+	do = do.withHooks(r.hooks, name)
 	// Append the target method to the logger.
 	logger = logger.With(zap.String("targetMethod", name))
 	switch name {
 	case reconciler.DoReconcileKind:
+		// Skip the reconciliation of paused resources, so that operators
+		// can freeze individual resources.
+		if reconciler.IsPaused(resource) {
+			logger.Debugf("Skip reconciling resource %q, its reconciliation is paused", key)
+			reconciler.ReportPaused(ctx, defaultControllerAgentName, s.namespace)
+
+			break
+		}
+
+		// Wait for the external dependencies of the reconciler to be ready.
+		if gate, err := reconciler.CheckReadinessGates(ctx, defaultControllerAgentName, r.readinessGates); err != nil {
+			logger.Infof("Skip reconciling resource %q, readiness gate %q is not ready: %v", key, gate.Name, err)
+
+			reconcileEvent = controller.NewRequeueAfter(reconciler.ReadinessGateRequeueDelay)
+			break
+		}
+
 		// Set and update the finalizer on resource if r.reconciler
 		// implements Finalizer.
 		if resource, err = r.setFinalizerIfFinalizer(ctx, resource); err != nil {
 			return fmt.Errorf("failed to set finalizers: %w", err)
 		}
+		// Set and update the finalizers on resource if r.reconciler
+		// implements NamedFinalizers.
+		if resource, err = r.setNamedFinalizers(ctx, resource); err != nil {
+			return fmt.Errorf("failed to set finalizers: %w", err)
+		}
 
 		// Reconcile this copy of the resource and then write back any status
 		// updates regardless of whether the reconciliation errored out.
@@ -234,6 +311,18 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 			return fmt.Errorf("failed to clear finalizers: %w", err)
 		}
 
+		// Finalize each of the named finalizers, removing those which
+		// finalized cleanly.
+		var namedEvent reconciler.Event
+		if resource, namedEvent, err = r.finalizeNamed(ctx, resource); err != nil {
+			return fmt.Errorf("failed to clear finalizers: %w", err)
+		}
+		// The failure of a named finalizer prevails over a clean event of
+		// FinalizeKind, so that the resource is finalized again.
+		if namedEvent != nil && reconciler.IsCleanEvent(reconcileEvent) {
+			reconcileEvent = namedEvent
+		}
+
 	case reconciler.DoObserveKind:
 		// Observe any changes to this resource, since we are not the leader.
 		reconcileEvent = do(ctx, resource)
@@ -242,6 +331,18 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 
 	// Report the reconciler event, if any.
 	if reconcileEvent != nil {
+		var structured *reconciler.StructuredEvent
+		if reconciler.EventAs(reconcileEvent, &structured) {
+			logger.Infow("Returned a structured event", zap.Any("event", reconcileEvent))
+			structured.Record(ctx, r.Recorder, defaultControllerAgentName, resource)
+
+			// the event was wrapped inside an error, consider the reconciliation as failed
+			if _, isEvent := reconcileEvent.(*reconciler.StructuredEvent); !isEvent {
+				return reconcileEvent
+			}
+			return nil
+		}
+
 		var event *reconciler.ReconcilerEvent
 		if reconciler.EventAs(reconcileEvent, &event) {
 			logger.Infow("Returned an event", zap.Any("event", reconcileEvent))
@@ -297,10 +398,15 @@ func (r *reconcilerImpl) updateFinalizersFiltered(ctx context.Context, resource
 		finalizers = existingFinalizers.List()
 	}
 
+	return r.patchFinalizers(ctx, resource, finalizers)
+}
+
+// patchFinalizers will set the Finalizers of the resource to finalizers.
+func (r *reconcilerImpl) patchFinalizers(ctx context.Context, resource *v1beta1.MutatingWebhookConfiguration, finalizers []string) (*v1beta1.MutatingWebhookConfiguration, error) {
 	mergePatch := map[string]interface{}{
 		"metadata": map[string]interface{}{
 			"finalizers":      finalizers,
-			"resourceVersion": existing.ResourceVersion,
+			"resourceVersion": resource.ResourceVersion,
 		},
 	}
 
@@ -314,7 +420,7 @@ func (r *reconcilerImpl) updateFinalizersFiltered(ctx context.Context, resource
 	resourceName := resource.Name
 	updated, err := patcher.Patch(ctx, resourceName, types.MergePatchType, patch, metav1.PatchOptions{})
 	if err != nil {
-		r.Recorder.Eventf(existing, v1.EventTypeWarning, "FinalizerUpdateFailed",
+		r.Recorder.Eventf(resource, v1.EventTypeWarning, "FinalizerUpdateFailed",
 			"Failed to update finalizers for %q: %v", resourceName, err)
 	} else {
 		r.Recorder.Eventf(updated, v1.EventTypeNormal, "FinalizerUpdate",
@@ -363,3 +469,38 @@ func (r *reconcilerImpl) clearFinalizer(ctx context.Context, resource *v1beta1.M
 	// Synchronize the finalizers filtered by r.finalizerName.
 	return r.updateFinalizersFiltered(ctx, resource, finalizers)
 }
+
+func (r *reconcilerImpl) setNamedFinalizers(ctx context.Context, resource *v1beta1.MutatingWebhookConfiguration) (*v1beta1.MutatingWebhookConfiguration, error) {
+	nf, ok := r.reconciler.(NamedFinalizers)
+	if !ok || !resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil
+	}
+
+	finalizers, changed := reconciler.NewFinalizerRegistry(nf.FinalizerNames()...).Ensure(resource.Finalizers)
+	if !changed {
+		return resource, nil
+	}
+	return r.patchFinalizers(ctx, resource, finalizers)
+}
+
+func (r *reconcilerImpl) finalizeNamed(ctx context.Context, resource *v1beta1.MutatingWebhookConfiguration) (*v1beta1.MutatingWebhookConfiguration, reconciler.Event, error) {
+	nf, ok := r.reconciler.(NamedFinalizers)
+	if !ok || resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil, nil
+	}
+
+	finalizers, changed, event := reconciler.NewFinalizerRegistry(nf.FinalizerNames()...).Finalize(resource.Finalizers, func(name string) reconciler.Event {
+		return nf.FinalizeNamed(ctx, name, resource)
+	})
+	if !changed {
+		return resource, event, nil
+	}
+	resource, err := r.patchFinalizers(ctx, resource, finalizers)
+	return resource, event, err
+}
+
+// finalizeNamedOnly is the FinalizeKind of the reconcilers which implement
+// NamedFinalizers but not Finalizer.
+func finalizeNamedOnly(context.Context, *v1beta1.MutatingWebhookConfiguration) reconciler.Event {
+	return nil
+}
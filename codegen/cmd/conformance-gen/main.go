@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// conformance-gen generates, in each of the given package directories, a
+// test verifying with conformance.Populatable the duck types which have a
+// Populate method, so that the test covers the new duck types and fields
+// without being updated by hand.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const outputFile = "zz_generated.conformance_test.go"
+
+func main() {
+	inputDirs := flag.String("input-dirs", "", "Comma separated directories of the packages to generate the tests for.")
+	headerFile := flag.String("go-header-file", "", "File holding the header of the generated files.")
+	flag.Parse()
+
+	var header []byte
+	if *headerFile != "" {
+		var err error
+		if header, err = os.ReadFile(*headerFile); err != nil {
+			log.Fatal("Error reading the header file: ", err)
+		}
+	}
+
+	for _, dir := range strings.Split(*inputDirs, ",") {
+		if dir == "" {
+			continue
+		}
+		if err := generate(dir, header); err != nil {
+			log.Fatalf("Error generating the test of %s: %v", dir, err)
+		}
+	}
+}
+
+// generate writes the test of the package in dir, or removes it if the
+// package has no populatable types left.
+func generate(dir string, header []byte) error {
+	pkg, types, err := populatableTypes(dir)
+	if err != nil {
+		return err
+	}
+	out := filepath.Join(dir, outputFile)
+	if len(types) == 0 {
+		if err := os.Remove(out); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	src, err := render(pkg, types, header)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644) //nolint:gosec // Generated sources are world readable.
+}
+
+// populatableTypes returns the package name and the sorted names of the
+// types with a Populate method of the non-test sources in dir.
+func populatableTypes(dir string) (string, []string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("expected a single package, found %d", len(pkgs))
+	}
+
+	var name string
+	var types []string
+	for n, pkg := range pkgs {
+		name = n
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				if typ, ok := populateReceiver(decl); ok {
+					types = append(types, typ)
+				}
+			}
+		}
+	}
+	sort.Strings(types)
+	return name, types, nil
+}
+
+// populateReceiver returns the receiver type of the declaration if it is a
+// `func (*T) Populate()` method of an exported type.
+func populateReceiver(decl ast.Decl) (string, bool) {
+	fn, ok := decl.(*ast.FuncDecl)
+	if !ok || fn.Recv == nil || fn.Name.Name != "Populate" ||
+		fn.Type.Params.NumFields() != 0 || fn.Type.Results.NumFields() != 0 {
+		return "", false
+	}
+	star, ok := fn.Recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	if !ok || !ident.IsExported() {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+func render(pkg string, types []string, header []byte) ([]byte, error) {
+	var b bytes.Buffer
+	b.Write(header)
+	fmt.Fprintf(&b, `
+// Code generated by conformance-gen. DO NOT EDIT.
+
+package %s
+
+import (
+	"testing"
+
+	"knative.dev/pkg/apis/duck/ducktypes"
+	"knative.dev/pkg/apis/testing/conformance"
+)
+
+func TestPopulatableConformance(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  ducktypes.Populatable
+	}{
+`, pkg)
+	for _, typ := range types {
+		fmt.Fprintf(&b, "\t\t{name: %q, typ: &%s{}},\n", typ, typ)
+	}
+	b.WriteString(`	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conformance.Populatable(t, test.typ)
+		})
+	}
+}
+`)
+	return format.Source(b.Bytes())
+}
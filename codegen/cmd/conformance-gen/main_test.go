@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testSource = `package foo
+
+type Foo struct{}
+
+func (*Foo) Populate() {}
+
+type Bar struct{}
+
+func (Bar) Populate() {}
+
+type baz struct{}
+
+func (*baz) Populate() {}
+
+type Qux struct{}
+
+func (*Qux) Populate(n int) {}
+
+type Quux struct{}
+
+func (*Quux) Fill() {}
+
+func Populate() {}
+`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(testSource), 0o600); err != nil {
+		t.Fatal("WriteFile() =", err)
+	}
+
+	pkg, types, err := populatableTypes(dir)
+	if err != nil {
+		t.Fatal("populatableTypes() =", err)
+	}
+	if pkg != "foo" {
+		t.Errorf("populatableTypes() package = %s, wanted foo", pkg)
+	}
+	if want := []string{"Foo"}; !cmp.Equal(types, want) {
+		t.Errorf("populatableTypes() = %v, wanted %v", types, want)
+	}
+
+	if err := generate(dir, []byte("// header\n")); err != nil {
+		t.Fatal("generate() =", err)
+	}
+	out := filepath.Join(dir, outputFile)
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal("ReadFile() =", err)
+	}
+	for _, want := range []string{"// header", "package foo", `{name: "Foo", typ: &Foo{}}`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("generated test = %s, wanted it to contain %q", b, want)
+		}
+	}
+
+	// The generated test is removed once no type is populatable.
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte("package foo\n"), 0o600); err != nil {
+		t.Fatal("WriteFile() =", err)
+	}
+	if err := generate(dir, nil); err != nil {
+		t.Fatal("generate() =", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("Stat() = %v, wanted the generated test to be removed", err)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// duck-schema-gen generates, for each registered duck type, the minimal
+// OpenAPI v3 schema which the CRDs implementing it must include, so that
+// CRD authors can embed it in their schemas. It is meant to be run with
+// go:generate, e.g.:
+//
+//	//go:generate go run knative.dev/pkg/codegen/cmd/duck-schema-gen -output-dir schemas
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"knative.dev/pkg/apis/duck/ducktypes"
+	"knative.dev/pkg/apis/duck/openapi"
+
+	// Register the duck types of knative.dev/pkg.
+	_ "knative.dev/pkg/apis/duck/v1"
+)
+
+func main() {
+	outputDir := flag.String("output-dir", ".", "Directory to write the schemas to, as <duck type>.yaml.")
+	duckTypes := flag.String("duck-types", "", "Comma separated names of the duck types to generate the schemas of, all the registered ones by default.")
+	flag.Parse()
+
+	regs, err := registrations(*duckTypes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(*outputDir, 0o755); err != nil { //nolint:gosec // Generated sources are world readable.
+		log.Fatal("Error creating the output directory: ", err)
+	}
+	for _, reg := range regs {
+		src, err := render(reg)
+		if err != nil {
+			log.Fatalf("Error generating the schema of %s: %v", reg.Name, err)
+		}
+		out := filepath.Join(*outputDir, reg.Name+".yaml")
+		if err := os.WriteFile(out, src, 0o644); err != nil { //nolint:gosec // Generated sources are world readable.
+			log.Fatalf("Error writing the schema of %s: %v", reg.Name, err)
+		}
+	}
+}
+
+// registrations returns the registrations of the duck types of the given
+// comma separated names, or all of them.
+func registrations(names string) ([]ducktypes.Registration, error) {
+	if names == "" {
+		return ducktypes.Registered(), nil
+	}
+	var regs []ducktypes.Registration
+	for _, name := range strings.Split(names, ",") {
+		reg, ok := ducktypes.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown duck type %q", name)
+		}
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
+
+func render(reg ducktypes.Registration) ([]byte, error) {
+	schema, err := yaml.Marshal(openapi.For(reg.Type))
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `# Code generated by duck-schema-gen. DO NOT EDIT.
+#
+# The minimal OpenAPI v3 schema of the resources implementing the %s
+# duck type, to include in the openAPIV3Schema of their CRDs. The CRDs
+# advertise that they implement it with the label %s: "true".
+`, reg.Name, reg.Label())
+	b.Write(schema)
+	return b.Bytes(), nil
+}
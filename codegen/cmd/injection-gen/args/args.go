@@ -29,6 +29,7 @@ type CustomArgs struct {
 	ExternalVersionsInformersPackage string
 	ListersPackage                   string
 	ForceKinds                       string
+	FilteredKinds                    string
 	ListerHasPointerElem             bool
 }
 
@@ -46,6 +47,7 @@ func (ca *CustomArgs) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&ca.ExternalVersionsInformersPackage, "external-versions-informers-package", ca.ExternalVersionsInformersPackage, "the full package name for the external versions injection informer to use")
 	fs.StringVar(&ca.ListersPackage, "listers-package", ca.ListersPackage, "the full package name for client listers to use")
 	fs.StringVar(&ca.ForceKinds, "force-genreconciler-kinds", ca.ForceKinds, `force kinds will override the genreconciler tag setting for the given set of kinds, comma separated: "Foo,Bar,Baz"`)
+	fs.StringVar(&ca.FilteredKinds, "filtered-genreconciler-kinds", ca.FilteredKinds, `filtered kinds will override the genreconciler:filtered tag setting for the given set of kinds, comma separated: "Foo,Bar,Baz"`)
 
 	fs.BoolVar(&ca.ListerHasPointerElem, "lister-has-pointer-elem", false, "")
 	fs.MarkDeprecated("lister-has-pointer-elem", "this flag has no effect")
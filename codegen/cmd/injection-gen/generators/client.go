@@ -64,6 +64,8 @@ func (g *clientGenerator) GenerateType(c *generator.Context, t *types.Type, w io
 	klog.V(5).Info("processing type ", t)
 
 	m := map[string]interface{}{
+		"outputPackage":              g.outputPackage,
+		"injectionRegisterKey":       c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "RegisterKey"}),
 		"clientSetNewForConfigOrDie": c.Universe.Function(types.Name{Package: g.clientSetPackage, Name: "NewForConfigOrDie"}),
 		"clientSetInterface":         c.Universe.Type(types.Name{Package: g.clientSetPackage, Name: "Interface"}),
 		"injectionRegisterClient":    c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "Default.RegisterClient"}),
@@ -93,6 +95,7 @@ func init() {
 	{{.injectionRegisterClientFetcher|raw}}(func(ctx context.Context) interface{} {
 		return Get(ctx)
 	})
+	{{.injectionRegisterKey|raw}}("{{.outputPackage}}", Key{})
 }
 
 // Key is used as the key for associating information with a context.Context.
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"io"
+
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+	"k8s.io/klog/v2"
+)
+
+// defaultsGenerator produces DeepCopyWithDefaults methods for the types
+// tagged with +gendefaults, alongside their generated deep copy functions.
+type defaultsGenerator struct {
+	generator.DefaultGen
+	outputPackage   string
+	typesToGenerate []*types.Type
+	imports         namer.ImportTracker
+}
+
+var _ generator.Generator = (*defaultsGenerator)(nil)
+
+func (g *defaultsGenerator) Filter(c *generator.Context, t *types.Type) bool {
+	for _, typ := range g.typesToGenerate {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *defaultsGenerator) Namers(c *generator.Context) namer.NameSystems {
+	return namer.NameSystems{
+		"raw": namer.NewRawNamer(g.outputPackage, g.imports),
+	}
+}
+
+func (g *defaultsGenerator) Imports(c *generator.Context) (imports []string) {
+	imports = append(imports, g.imports.ImportLines()...)
+	return
+}
+
+func (g *defaultsGenerator) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
+	sw := generator.NewSnippetWriter(w, c, "{{", "}}")
+
+	klog.V(5).Info("processing type ", t)
+
+	m := map[string]interface{}{
+		"type": t,
+		"contextContext": c.Universe.Type(types.Name{
+			Package: "context",
+			Name:    "Context",
+		}),
+	}
+
+	sw.Do(deepCopyWithDefaults, m)
+
+	return sw.Error()
+}
+
+var deepCopyWithDefaults = `
+// DeepCopyWithDefaults is an autogenerated function, deep copying the
+// receiver and setting the defaults of the copy, creating a new {{.type|raw}}.
+func (in *{{.type|raw}}) DeepCopyWithDefaults(ctx {{.contextContext|raw}}) *{{.type|raw}} {
+	if in == nil {
+		return nil
+	}
+	out := in.DeepCopy()
+	out.SetDefaults(ctx)
+	return out
+}
+`
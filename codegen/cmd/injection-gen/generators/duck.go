@@ -77,6 +77,8 @@ func (g *duckGenerator) GenerateType(c *generator.Context, t *types.Type, w io.W
 	klog.V(5).Info("processing type ", t)
 
 	m := map[string]interface{}{
+		"outputPackage":             g.outputPackage,
+		"injectionRegisterKey":      c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "RegisterKey"}),
 		"group":                     namer.IC(g.groupGoName),
 		"type":                      t,
 		"version":                   namer.IC(g.groupVersion.Version.String()),
@@ -104,6 +106,7 @@ func (g *duckGenerator) GenerateType(c *generator.Context, t *types.Type, w io.W
 var duckFactory = `
 func init() {
 	{{.injectionRegisterDuck|raw}}(WithDuck)
+	{{.injectionRegisterKey|raw}}("{{.outputPackage}}", Key{})
 }
 
 // Key is used for associating the Informer inside the context.Context.
@@ -64,7 +64,9 @@ func (g *factoryGenerator) GenerateType(c *generator.Context, t *types.Type, w i
 	klog.V(5).Info("processing type ", t)
 
 	m := map[string]interface{}{
-		"cachingClientGet": c.Universe.Type(types.Name{Package: g.cachingClientSetPackage, Name: "Get"}),
+		"outputPackage":        g.outputPackage,
+		"injectionRegisterKey": c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "RegisterKey"}),
+		"cachingClientGet":     c.Universe.Type(types.Name{Package: g.cachingClientSetPackage, Name: "Get"}),
 		"informersNewSharedInformerFactoryWithOptions": c.Universe.Function(types.Name{Package: g.sharedInformerFactoryPackage, Name: "NewSharedInformerFactoryWithOptions"}),
 		"informersSharedInformerOption":                c.Universe.Function(types.Name{Package: g.sharedInformerFactoryPackage, Name: "SharedInformerOption"}),
 		"informersWithNamespace":                       c.Universe.Function(types.Name{Package: g.sharedInformerFactoryPackage, Name: "WithNamespace"}),
@@ -91,6 +93,7 @@ func (g *factoryGenerator) GenerateType(c *generator.Context, t *types.Type, w i
 var injectionFactory = `
 func init() {
 	{{.injectionRegisterInformerFactory|raw}}(withInformerFactory)
+	{{.injectionRegisterKey|raw}}("{{.outputPackage}}", Key{})
 }
 
 // Key is used as the key for associating information with a context.Context.
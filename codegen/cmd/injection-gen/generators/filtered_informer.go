@@ -83,6 +83,10 @@ func (g *filteredInjectionGenerator) GenerateType(c *generator.Context, t *types
 		"type":                               t,
 		"version":                            namer.IC(g.groupVersion.Version.String()),
 		"injectionRegisterFilteredInformers": c.Universe.Type(types.Name{Package: "knative.dev/pkg/injection", Name: "Default.RegisterFilteredInformers"}),
+		"controllerWithResource":             c.Universe.Function(types.Name{Package: "knative.dev/pkg/controller", Name: "WithResource"}),
+		"schemaGroupVersionResource":         c.Universe.Type(types.Name{Package: "k8s.io/apimachinery/pkg/runtime/schema", Name: "GroupVersionResource"}),
+		"groupName":                          resourceGroup(g.groupVersion.Group),
+		"versionName":                        g.groupVersion.Version.String(),
 		"controllerInformer":                 c.Universe.Type(types.Name{Package: "knative.dev/pkg/controller", Name: "Informer"}),
 		"informersTypedInformer":             c.Universe.Type(types.Name{Package: g.typedInformerPackage, Name: t.Name.Name + "Informer"}),
 		"factoryLabelKey":                    c.Universe.Type(types.Name{Package: g.groupInformerFactoryPackage, Name: "LabelKey"}),
@@ -128,7 +132,11 @@ func withInformer(ctx {{.contextContext|raw}}) ({{.contextContext|raw}}, []{{.co
 		f := {{.factoryGet|raw}}(ctx, selector)
 		inf := f.{{.group}}().{{.version}}().{{.type|publicPlural}}()
 		ctx = {{ .contextWithValue|raw }}(ctx, Key{Selector: selector}, inf)
-		infs = append(infs, inf.Informer())
+		infs = append(infs, {{.controllerWithResource|raw}}(inf.Informer(), {{.schemaGroupVersionResource|raw}}{
+			Group:    "{{.groupName}}",
+			Version:  "{{.versionName}}",
+			Resource: "{{.type|allLowercasePlural}}",
+		}))
 	}
 	return ctx, infs
 }
@@ -79,13 +79,19 @@ func (g *injectionGenerator) GenerateType(c *generator.Context, t *types.Type, w
 	klog.V(5).Info("processing type ", t)
 
 	m := map[string]interface{}{
-		"groupGoName":               namer.IC(g.groupGoName),
-		"versionGoName":             namer.IC(g.groupVersion.Version.String()),
-		"type":                      t,
-		"injectionRegisterInformer": c.Universe.Type(types.Name{Package: "knative.dev/pkg/injection", Name: "Default.RegisterInformer"}),
-		"controllerInformer":        c.Universe.Type(types.Name{Package: "knative.dev/pkg/controller", Name: "Informer"}),
-		"informersTypedInformer":    c.Universe.Type(types.Name{Package: g.typedInformerPackage, Name: t.Name.Name + "Informer"}),
-		"factoryGet":                c.Universe.Type(types.Name{Package: g.groupInformerFactoryPackage, Name: "Get"}),
+		"outputPackage":              g.outputPackage,
+		"injectionRegisterKey":       c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "RegisterKey"}),
+		"groupGoName":                namer.IC(g.groupGoName),
+		"versionGoName":              namer.IC(g.groupVersion.Version.String()),
+		"type":                       t,
+		"injectionRegisterInformer":  c.Universe.Type(types.Name{Package: "knative.dev/pkg/injection", Name: "Default.RegisterInformer"}),
+		"controllerWithResource":     c.Universe.Function(types.Name{Package: "knative.dev/pkg/controller", Name: "WithResource"}),
+		"schemaGroupVersionResource": c.Universe.Type(types.Name{Package: "k8s.io/apimachinery/pkg/runtime/schema", Name: "GroupVersionResource"}),
+		"groupName":                  resourceGroup(g.groupVersion.Group),
+		"versionName":                g.groupVersion.Version.String(),
+		"controllerInformer":         c.Universe.Type(types.Name{Package: "knative.dev/pkg/controller", Name: "Informer"}),
+		"informersTypedInformer":     c.Universe.Type(types.Name{Package: g.typedInformerPackage, Name: t.Name.Name + "Informer"}),
+		"factoryGet":                 c.Universe.Type(types.Name{Package: g.groupInformerFactoryPackage, Name: "Get"}),
 		"loggingFromContext": c.Universe.Function(types.Name{
 			Package: "knative.dev/pkg/logging",
 			Name:    "FromContext",
@@ -108,6 +114,7 @@ func (g *injectionGenerator) GenerateType(c *generator.Context, t *types.Type, w
 var injectionInformer = `
 func init() {
 	{{.injectionRegisterInformer|raw}}(withInformer)
+	{{.injectionRegisterKey|raw}}("{{.outputPackage}}", Key{})
 }
 
 // Key is used for associating the Informer inside the context.Context.
@@ -116,7 +123,11 @@ type Key struct{}
 func withInformer(ctx {{.contextContext|raw}}) ({{.contextContext|raw}}, {{.controllerInformer|raw}}) {
 	f := {{.factoryGet|raw}}(ctx)
 	inf := f.{{.groupGoName}}().{{.versionGoName}}().{{.type|publicPlural}}()
-	return {{ .contextWithValue|raw }}(ctx, Key{}, inf), inf.Informer()
+	return {{ .contextWithValue|raw }}(ctx, Key{}, inf), {{.controllerWithResource|raw}}(inf.Informer(), {{.schemaGroupVersionResource|raw}}{
+		Group:    "{{.groupName}}",
+		Version:  "{{.versionName}}",
+		Resource: "{{.type|allLowercasePlural}}",
+	})
 }
 
 // Get extracts the typed informer from the context.
@@ -19,6 +19,7 @@ package generators
 import (
 	"strings"
 
+	clientgentypes "k8s.io/code-generator/cmd/client-gen/types"
 	codegennamer "k8s.io/code-generator/pkg/namer"
 	"k8s.io/gengo/namer"
 	"k8s.io/gengo/types"
@@ -52,6 +53,15 @@ func NameSystems() namer.NameSystems {
 	}
 }
 
+// resourceGroup returns the name of the API group, which is empty for the
+// core group.
+func resourceGroup(g clientgentypes.Group) string {
+	if g == "core" {
+		return ""
+	}
+	return g.String()
+}
+
 // lowercaseSingularNamer implements Namer
 type lowercaseSingularNamer struct{}
 
@@ -76,6 +76,7 @@ func Packages(context *generator.Context, arguments *args.GeneratorArgs) generat
 		var typesWithInformers []*types.Type
 		var duckTypes []*types.Type
 		var reconcilerTypes []*types.Type
+		var defaultsTypes []*types.Type
 		for _, t := range p.Types {
 			tags := MustParseClientGenTags(append(t.SecondClosestCommentLines, t.CommentLines...))
 			if tags.NeedsInformerInjection() {
@@ -87,6 +88,9 @@ func Packages(context *generator.Context, arguments *args.GeneratorArgs) generat
 			if tags.NeedsReconciler(t, customArgs) {
 				reconcilerTypes = append(reconcilerTypes, t)
 			}
+			if tags.NeedsDefaults() {
+				defaultsTypes = append(defaultsTypes, t)
+			}
 		}
 
 		if len(typesWithInformers) != 0 {
@@ -112,6 +116,14 @@ func Packages(context *generator.Context, arguments *args.GeneratorArgs) generat
 			// Generate a reconciler and controller for each type.
 			packageList = append(packageList, reconcilerPackages(versionPackagePath, groupPackageName, gv, groupGoNames[groupPackageName], boilerplate, reconcilerTypes, customArgs)...)
 		}
+
+		if len(defaultsTypes) != 0 {
+			orderer := namer.Orderer{Namer: namer.NewPrivateNamer(0)}
+			defaultsTypes = orderer.OrderTypes(defaultsTypes)
+
+			// Generate the DeepCopyWithDefaults methods next to the types.
+			packageList = append(packageList, defaultsPackage(p, boilerplate, defaultsTypes))
+		}
 	}
 
 	// Generate the client and fake.
@@ -129,6 +141,7 @@ type Tags struct {
 
 	GenerateDuck       bool
 	GenerateReconciler bool
+	GenerateDefaults   bool
 }
 
 func (t Tags) NeedsInformerInjection() bool {
@@ -139,6 +152,10 @@ func (t Tags) NeedsDuckInjection() bool {
 	return t.GenerateDuck
 }
 
+func (t Tags) NeedsDefaults() bool {
+	return t.GenerateDefaults
+}
+
 func (t Tags) NeedsReconciler(kind *types.Type, args *informergenargs.CustomArgs) bool {
 	// Overrides
 	kinds := strings.Split(args.ForceKinds, ",")
@@ -164,6 +181,8 @@ func MustParseClientGenTags(lines []string) Tags {
 
 	_, ret.GenerateReconciler = values["genreconciler"]
 
+	_, ret.GenerateDefaults = values["gendefaults"]
+
 	return ret
 }
 
@@ -214,6 +233,23 @@ func stubs(tags CommentTags) bool {
 	return has
 }
 
+// filtered returns whether to generate the constructors of the reconcilers
+// of the type wired against filtered informers, per its
+// genreconciler:filtered tag or the filtered kinds.
+func filtered(t *types.Type, tags CommentTags, customArgs *informergenargs.CustomArgs) bool {
+	for _, k := range strings.Split(customArgs.FilteredKinds, ",") {
+		if t.Name.Name == k {
+			return true
+		}
+	}
+	vals, has := tags["genreconciler"]
+	if !has {
+		return false
+	}
+	_, has = vals["filtered"]
+	return has
+}
+
 func vendorless(p string) string {
 	if pos := strings.LastIndex(p, "/vendor/"); pos != -1 {
 		return p[pos+len("/vendor/"):]
@@ -519,6 +555,7 @@ func reconcilerPackages(basePackage string, groupPkgName string, gv clientgentyp
 		nonNamespaced := isNonNamespaced(extracted)
 		isKRShaped := isKRShaped(extracted)
 		stubs := stubs(extracted)
+		filtered := filtered(t, extracted, customArgs)
 
 		packagePath := filepath.Join(packagePath, strings.ToLower(t.Name.Name))
 
@@ -542,10 +579,13 @@ func reconcilerPackages(basePackage string, groupPkgName string, gv clientgentyp
 					groupName:           gv.Group.String(),
 					clientPkg:           clientPackagePath,
 					informerPackagePath: informerPackagePath,
+					listerName:          t.Name.Name + "Lister",
+					listerPkg:           listerPackagePath,
 					schemePkg:           filepath.Join(customArgs.VersionedClientSetPackage, "scheme"),
 					reconcilerClasses:   reconcilerClasses,
 					hasReconcilerClass:  hasReconcilerClass,
 					hasStatus:           hasStatus(t),
+					filtered:            filtered,
 				})
 				return generators
 			},
@@ -741,3 +781,26 @@ func hasStatus(t *types.Type) bool {
 	}
 	return false
 }
+
+func defaultsPackage(p *types.Package, boilerplate []byte, typesToGenerate []*types.Type) generator.Package {
+	return &generator.DefaultPackage{
+		PackageName: p.Name,
+		PackagePath: p.Path,
+		HeaderText:  boilerplate,
+		GeneratorFunc: func(c *generator.Context) (generators []generator.Generator) {
+			generators = append(generators, &defaultsGenerator{
+				DefaultGen: generator.DefaultGen{
+					OptionalName: "zz_generated.defaults",
+				},
+				outputPackage:   p.Path,
+				typesToGenerate: typesToGenerate,
+				imports:         generator.NewImportTracker(),
+			})
+			return generators
+		},
+		FilterFunc: func(c *generator.Context, t *types.Type) bool {
+			tags := MustParseClientGenTags(append(t.SecondClosestCommentLines, t.CommentLines...))
+			return tags.NeedsDefaults()
+		},
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"testing"
+
+	"k8s.io/gengo/types"
+
+	informergenargs "knative.dev/pkg/codegen/cmd/injection-gen/args"
+)
+
+func TestFiltered(t *testing.T) {
+	foo := &types.Type{Name: types.Name{Name: "Foo"}}
+
+	tests := []struct {
+		name     string
+		comments []string
+		kinds    string
+		want     bool
+	}{{
+		name:     "no tag",
+		comments: []string{"+genreconciler"},
+	}, {
+		name:     "filtered tag",
+		comments: []string{"+genreconciler:filtered"},
+		want:     true,
+	}, {
+		name:     "filtered tag with a class",
+		comments: []string{"+genreconciler:class=example.com/filter.class,filtered"},
+		want:     true,
+	}, {
+		name:  "filtered kind",
+		kinds: "Bar,Foo",
+		want:  true,
+	}, {
+		name:  "other kinds",
+		kinds: "Bar,Baz",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tags := ExtractCommentTags("+", tc.comments)
+			if got := filtered(foo, tags, &informergenargs.CustomArgs{FilteredKinds: tc.kinds}); got != tc.want {
+				t.Errorf("filtered() = %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
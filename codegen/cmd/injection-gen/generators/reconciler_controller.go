@@ -37,10 +37,15 @@ type reconcilerControllerGenerator struct {
 	clientPkg           string
 	schemePkg           string
 	informerPackagePath string
+	listerName          string
+	listerPkg           string
 
 	reconcilerClasses  []string
 	hasReconcilerClass bool
 	hasStatus          bool
+	// filtered generates NewFilteredImpl, wired against the filtered
+	// informers.
+	filtered bool
 }
 
 var _ generator.Generator = (*reconcilerControllerGenerator)(nil)
@@ -72,6 +77,7 @@ func (g *reconcilerControllerGenerator) GenerateType(c *generator.Context, t *ty
 		"classes":   g.reconcilerClasses,
 		"hasClass":  g.hasReconcilerClass,
 		"hasStatus": g.hasStatus,
+		"filtered":  g.filtered,
 		"controllerImpl": c.Universe.Type(types.Name{
 			Package: "knative.dev/pkg/controller",
 			Name:    "Impl",
@@ -104,6 +110,14 @@ func (g *reconcilerControllerGenerator) GenerateType(c *generator.Context, t *ty
 			Package: g.informerPackagePath,
 			Name:    "Get",
 		}),
+		"filteredInformerGet": c.Universe.Function(types.Name{
+			Package: g.informerPackagePath + "/filtered",
+			Name:    "Get",
+		}),
+		"lister": c.Universe.Type(types.Name{
+			Package: g.listerPkg,
+			Name:    g.listerName,
+		}),
 		"schemeScheme": c.Universe.Function(types.Name{
 			Package: "k8s.io/client-go/kubernetes/scheme",
 			Name:    "Scheme",
@@ -218,16 +232,32 @@ var (
 // the provided Interface and optional Finalizer methods. OptionsFn is used to return
 // {{.controllerOptions|raw}} to be used by the internal reconciler.
 func NewImpl(ctx {{.contextContext|raw}}, r Interface{{if .hasClass}}, classValue string{{end}}, optionsFns ...{{.controllerOptionsFn|raw}}) *{{.controllerImpl|raw}} {
+	{{- if .filtered}}
+	return newImpl(ctx, r{{if .hasClass}}, classValue{{end}}, {{.informerGet|raw}}(ctx).Lister(), optionsFns...)
+}
+
+// NewFilteredImpl returns a {{.controllerImpl|raw}} like NewImpl, wired against the
+// filtered informer of the given label selector, which must be among those
+// of the context, so that it only sees the resources selected, e.g. those
+// of a tenant. The informer is also restricted to the namespace scope of the
+// context, if any.
+func NewFilteredImpl(ctx {{.contextContext|raw}}, r Interface{{if .hasClass}}, classValue string{{end}}, selector string, optionsFns ...{{.controllerOptionsFn|raw}}) *{{.controllerImpl|raw}} {
+	return newImpl(ctx, r{{if .hasClass}}, classValue{{end}}, {{.filteredInformerGet|raw}}(ctx, selector).Lister(), optionsFns...)
+}
+
+func newImpl(ctx {{.contextContext|raw}}, r Interface{{if .hasClass}}, classValue string{{end}}, lister {{.lister|raw}}, optionsFns ...{{.controllerOptionsFn|raw}}) *{{.controllerImpl|raw}} {
+	{{- end}}
 	logger := {{.loggingFromContext|raw}}(ctx)
 
 	// Check the options function input. It should be 0 or 1.
 	if len(optionsFns) > 1 {
 		logger.Fatal("Up to one options function is supported, found: ", len(optionsFns))
 	}
-
+	{{if not .filtered}}
 	{{.type|lowercaseSingular}}Informer := {{.informerGet|raw}}(ctx)
 
 	lister := {{.type|lowercaseSingular}}Informer.Lister()
+	{{end}}
 
 	var promoteFilterFunc func(obj interface{}) bool
 	var promoteFunc = func(bkt {{.reconcilerBucket|raw}}) {}
@@ -293,6 +323,12 @@ func NewImpl(ctx {{.contextContext|raw}}, r Interface{{if .hasClass}}, classValu
 		if opts.SkipStatusUpdates {
 			rec.skipStatusUpdates = true
 		}
+		if opts.StatusFieldManager != "" {
+			rec.statusFieldManager = opts.StatusFieldManager
+		}
+		if opts.StatusConflictStrategy != "" {
+			rec.statusConflictStrategy = opts.StatusConflictStrategy
+		}
 		{{- end}}
 		if opts.DemoteFunc != nil {
 			rec.DemoteFunc = opts.DemoteFunc
@@ -303,6 +339,15 @@ func NewImpl(ctx {{.contextContext|raw}}, r Interface{{if .hasClass}}, classValu
 		if opts.PromoteFunc != nil {
 			promoteFunc = opts.PromoteFunc
 		}
+		if len(opts.ReadinessGates) > 0 {
+			rec.readinessGates = opts.ReadinessGates
+		}
+		if len(opts.ReconcileHooks) > 0 {
+			rec.hooks = opts.ReconcileHooks
+		}
+		if opts.ObservationStore != nil {
+			rec.observationStore = opts.ObservationStore
+		}
 	}
 
 	rec.Recorder = createRecorder(ctx, agentName)
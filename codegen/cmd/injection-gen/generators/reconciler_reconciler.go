@@ -107,11 +107,25 @@ func (g *reconcilerReconcilerGenerator) GenerateType(c *generator.Context, t *ty
 			Package: "k8s.io/api/core/v1",
 			Name:    "EventTypeWarning",
 		}),
-		"reconcilerEvent":                c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "Event"}),
-		"reconcilerReconcilerEvent":      c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "ReconcilerEvent"}),
-		"reconcilerRetryUpdateConflicts": c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "RetryUpdateConflicts"}),
-		"reconcilerConfigStore":          c.Universe.Type(types.Name{Name: "ConfigStore", Package: "knative.dev/pkg/reconciler"}),
-		"reconcilerOnDeletionInterface":  c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "OnDeletionInterface"}),
+		"reconcilerEvent":                  c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "Event"}),
+		"reconcilerReconcilerEvent":        c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "ReconcilerEvent"}),
+		"reconcilerStructuredEvent":        c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "StructuredEvent"}),
+		"reconcilerRetryUpdateConflicts":   c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "RetryUpdateConflicts"}),
+		"reconcilerConfigStore":            c.Universe.Type(types.Name{Name: "ConfigStore", Package: "knative.dev/pkg/reconciler"}),
+		"reconcilerOnDeletionInterface":    c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "OnDeletionInterface"}),
+		"reconcilerIsPaused":               c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "IsPaused"}),
+		"reconcilerReportPaused":           c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "ReportPaused"}),
+		"reconcilerReadinessGate":          c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "ReadinessGate"}),
+		"reconcilerCheckReadinessGates":    c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "CheckReadinessGates"}),
+		"reconcilerReconcileHook":          c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "ReconcileHook"}),
+		"reconcilerObservationStore":       c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "ObservationStore"}),
+		"reconcilerWithObservationStore":   c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "WithObservationStore"}),
+		"reconcilerRunReconcileHooks":      c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "RunReconcileHooks"}),
+		"reconcilerGateRequeueDelay":       c.Universe.Constant(types.Name{Package: "knative.dev/pkg/reconciler", Name: "ReadinessGateRequeueDelay"}),
+		"reconcilerStatusApplyPatch":       c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "StatusApplyPatch"}),
+		"reconcilerStatusConflictStrategy": c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "StatusConflictStrategy"}),
+		"schemeGroupVersion":               c.Universe.Variable(types.Name{Package: t.Name.Package, Name: "SchemeGroupVersion"}),
+		"kind":                             t.Name.Name,
 		// Deps
 		"clientsetInterface": c.Universe.Type(types.Name{Name: "Interface", Package: g.clientsetPkg}),
 		"resourceLister":     c.Universe.Type(types.Name{Name: g.listerName, Package: g.listerPkg}),
@@ -178,6 +192,7 @@ func (g *reconcilerReconcilerGenerator) GenerateType(c *generator.Context, t *ty
 		"equalitySemantic":    c.Universe.Package("k8s.io/apimachinery/pkg/api/equality").Variable("Semantic"),
 		"jsonMarshal":         c.Universe.Package("encoding/json").Function("Marshal"),
 		"typesMergePatchType": c.Universe.Package("k8s.io/apimachinery/pkg/types").Constant("MergePatchType"),
+		"typesApplyPatchType": c.Universe.Package("k8s.io/apimachinery/pkg/types").Constant("ApplyPatchType"),
 		"syncRWMutex": c.Universe.Type(types.Name{
 			Package: "sync",
 			Name:    "RWMutex",
@@ -222,6 +237,18 @@ func (g *reconcilerReconcilerGenerator) GenerateType(c *generator.Context, t *ty
 			Package: "knative.dev/pkg/controller",
 			Name:    "IsRequeueKey",
 		}),
+		"controllerNewRequeueAfter": c.Universe.Function(types.Name{
+			Package: "knative.dev/pkg/controller",
+			Name:    "NewRequeueAfter",
+		}),
+		"reconcilerNewFinalizerRegistry": c.Universe.Function(types.Name{
+			Package: "knative.dev/pkg/reconciler",
+			Name:    "NewFinalizerRegistry",
+		}),
+		"reconcilerIsCleanEvent": c.Universe.Function(types.Name{
+			Package: "knative.dev/pkg/reconciler",
+			Name:    "IsCleanEvent",
+		}),
 	}
 
 	sw.Do(reconcilerInterfaceFactory, m)
@@ -273,6 +300,22 @@ type Finalizer interface {
 	FinalizeKind(ctx {{.contextContext|raw}}, o *{{.type|raw}}) {{.reconcilerEvent|raw}}
 }
 
+// NamedFinalizers defines the strongly typed interfaces to be implemented by a
+// controller finalizing {{.type|raw}} with several finalizers, e.g. one per
+// external system, independently of each other and of Finalizer.
+type NamedFinalizers interface {
+	// FinalizerNames returns the names of the finalizers to set on {{.type|raw}}.
+	FinalizerNames() []string
+
+	// FinalizeNamed implements custom logic to finalize {{.type|raw}} for the
+	// finalizer of the given name. Any changes to the objects .Status or
+	// .Finalizers will be ignored. Returning a nil or Normal type
+	// {{.reconcilerEvent|raw}} will allow that finalizer to be deleted on the
+	// resource. The resource passed to FinalizeNamed will always have a set
+	// deletion timestamp.
+	FinalizeNamed(ctx {{.contextContext|raw}}, name string, o *{{.type|raw}}) {{.reconcilerEvent|raw}}
+}
+
 // ReadOnlyInterface defines the strongly typed interfaces to be implemented by a
 // controller reconciling {{.type|raw}} if they want to process resources for which
 // they are not the leader.
@@ -284,6 +327,18 @@ type ReadOnlyInterface interface {
 
 type doReconcile func(ctx {{.contextContext|raw}}, o *{{.type|raw}}) {{.reconcilerEvent|raw}}
 
+// withHooks returns do run between the hooks, as the named method.
+func (do doReconcile) withHooks(hooks []{{.reconcilerReconcileHook|raw}}, method string) doReconcile {
+	if do == nil || len(hooks) == 0 {
+		return do
+	}
+	return func(ctx {{.contextContext|raw}}, o *{{.type|raw}}) {{.reconcilerEvent|raw}} {
+		return {{.reconcilerRunReconcileHooks|raw}}(ctx, hooks, method, o, func(ctx {{.contextContext|raw}}) {{.reconcilerEvent|raw}} {
+			return do(ctx, o)
+		})
+	}
+}
+
 // reconcilerImpl implements controller.Reconciler for {{.type|raw}} resources.
 type reconcilerImpl struct {
 	// LeaderAwareFuncs is inlined to help us implement {{.reconcilerLeaderAware|raw}}.
@@ -309,10 +364,30 @@ type reconcilerImpl struct {
 	// finalizerName is the name of the finalizer to reconcile.
 	finalizerName string
 
+	// readinessGates are the external dependencies which must be ready
+	// before reconciling the resources.
+	readinessGates []{{.reconcilerReadinessGate|raw}}
+
+	// hooks are run around every call of the methods of the reconciler.
+	hooks []{{.reconcilerReconcileHook|raw}}
+
+	// observationStore shares the observations of the resources by the
+	// replicas, if set.
+	// +optional
+	observationStore {{.reconcilerObservationStore|raw}}
+
 	{{if .hasStatus}}
 	// skipStatusUpdates configures whether or not this reconciler automatically updates
 	// the status of the reconciled resource.
 	skipStatusUpdates bool
+
+	// statusFieldManager is the field manager this reconciler applies the
+	// status of the reconciled resource as, with server-side apply, if any.
+	statusFieldManager string
+
+	// statusConflictStrategy is how this reconciler resolves the conflicts
+	// of the status updates of the reconciled resource.
+	statusConflictStrategy {{.reconcilerStatusConflictStrategy|raw}}
 	{{end}}
 
 	{{if len .classes | eq 1 }}
@@ -384,10 +459,25 @@ func NewReconciler(ctx {{.contextContext|raw}}, logger *{{.zapSugaredLogger|raw}
 		if opts.SkipStatusUpdates {
 			rec.skipStatusUpdates = true
 		}
+		if opts.StatusFieldManager != "" {
+			rec.statusFieldManager = opts.StatusFieldManager
+		}
+		if opts.StatusConflictStrategy != "" {
+			rec.statusConflictStrategy = opts.StatusConflictStrategy
+		}
 		{{- end}}
 		if opts.DemoteFunc != nil {
 			rec.DemoteFunc = opts.DemoteFunc
 		}
+		if len(opts.ReadinessGates) > 0 {
+			rec.readinessGates = opts.ReadinessGates
+		}
+		if len(opts.ReconcileHooks) > 0 {
+			rec.hooks = opts.ReconcileHooks
+		}
+		if opts.ObservationStore != nil {
+			rec.observationStore = opts.ObservationStore
+		}
 	}
 
 	return rec
@@ -420,6 +510,11 @@ func (r *reconcilerImpl) Reconcile(ctx {{.contextContext|raw}}, key string) erro
 		ctx = r.configStore.ToContext(ctx)
 	}
 
+	// If observationStore is set, attach it to the context.
+	if r.observationStore != nil {
+		ctx = {{.reconcilerWithObservationStore|raw}}(ctx, r.observationStore)
+	}
+
 	// Add the recorder to context.
 	ctx = {{.controllerWithEventRecorder|raw}}(ctx, r.Recorder)
 
@@ -468,15 +563,51 @@ func (r *reconcilerImpl) Reconcile(ctx {{.contextContext|raw}}, key string) erro
 	var reconcileEvent {{.reconcilerEvent|raw}}
 
 	name, do := s.reconcileMethodFor(resource)
+	do = do.withHooks(r.hooks, name)
 	// Append the target method to the logger.
 	logger = logger.With(zap.String("targetMethod", name))
 	switch name {
 	case {{.doReconcileKind|raw}}:
+		// Skip the reconciliation of paused resources, so that operators
+		// can freeze individual resources.
+		if {{.reconcilerIsPaused|raw}}(resource) {
+			logger.Debugf("Skip reconciling resource %q, its reconciliation is paused", key)
+			{{.reconcilerReportPaused|raw}}(ctx, defaultControllerAgentName, s.namespace)
+			{{if .isKRShaped}}
+			if !r.skipStatusUpdates {
+				reconciler.MarkPaused(resource)
+			}
+			{{end}}
+			break
+		}
+
+		// Wait for the external dependencies of the reconciler to be ready.
+		if gate, err := {{.reconcilerCheckReadinessGates|raw}}(ctx, defaultControllerAgentName, r.readinessGates); err != nil {
+			logger.Infof("Skip reconciling resource %q, readiness gate %q is not ready: %v", key, gate.Name, err)
+			{{if .isKRShaped}}
+			if !r.skipStatusUpdates {
+				reconciler.MarkGateNotReady(resource, gate.Name, err)
+			}
+			{{end}}
+			reconcileEvent = {{.controllerNewRequeueAfter|raw}}({{.reconcilerGateRequeueDelay|raw}})
+			break
+		}
+		{{if .isKRShaped}}
+		if len(r.readinessGates) > 0 && !r.skipStatusUpdates {
+			reconciler.MarkGatesReady(resource)
+		}
+		{{end}}
+
 		// Set and update the finalizer on resource if r.reconciler
 		// implements Finalizer.
 		if resource, err = r.setFinalizerIfFinalizer(ctx, resource); err != nil {
 			return {{.fmtErrorf|raw}}("failed to set finalizers: %w", err)
 		}
+		// Set and update the finalizers on resource if r.reconciler
+		// implements NamedFinalizers.
+		if resource, err = r.setNamedFinalizers(ctx, resource); err != nil {
+			return {{.fmtErrorf|raw}}("failed to set finalizers: %w", err)
+		}
 		{{if .isKRShaped}}
 		if !r.skipStatusUpdates {
 			reconciler.PreProcessReconcile(ctx, resource)
@@ -502,6 +633,18 @@ func (r *reconcilerImpl) Reconcile(ctx {{.contextContext|raw}}, key string) erro
 			return {{.fmtErrorf|raw}}("failed to clear finalizers: %w", err)
 		}
 
+		// Finalize each of the named finalizers, removing those which
+		// finalized cleanly.
+		var namedEvent {{.reconcilerEvent|raw}}
+		if resource, namedEvent, err = r.finalizeNamed(ctx, resource); err != nil {
+			return {{.fmtErrorf|raw}}("failed to clear finalizers: %w", err)
+		}
+		// The failure of a named finalizer prevails over a clean event of
+		// FinalizeKind, so that the resource is finalized again.
+		if namedEvent != nil && {{.reconcilerIsCleanEvent|raw}}(reconcileEvent) {
+			reconcileEvent = namedEvent
+		}
+
 	case {{.doObserveKind|raw}}:
 		// Observe any changes to this resource, since we are not the leader.
 		reconcileEvent = do(ctx, resource)
@@ -535,6 +678,18 @@ func (r *reconcilerImpl) Reconcile(ctx {{.contextContext|raw}}, key string) erro
 
 	// Report the reconciler event, if any.
 	if reconcileEvent != nil {
+		var structured *{{.reconcilerStructuredEvent|raw}}
+		if reconciler.EventAs(reconcileEvent, &structured) {
+			logger.Infow("Returned a structured event", zap.Any("event", reconcileEvent))
+			structured.Record(ctx, r.Recorder, defaultControllerAgentName, resource)
+
+			// the event was wrapped inside an error, consider the reconciliation as failed
+			if _, isEvent := reconcileEvent.(*reconciler.StructuredEvent); !isEvent {
+				return reconcileEvent
+			}
+			return nil
+		}
+
 		var event *{{.reconcilerReconcilerEvent|raw}}
 		if reconciler.EventAs(reconcileEvent, &event) {
 			logger.Infow("Returned an event", zap.Any("event", reconcileEvent))
@@ -564,8 +719,15 @@ func (r *reconcilerImpl) Reconcile(ctx {{.contextContext|raw}}, key string) erro
 
 var reconcilerStatusFactory = `
 func (r *reconcilerImpl) updateStatus(ctx {{.contextContext|raw}}, logger *{{.zapSugaredLogger|raw}}, existing *{{.type|raw}}, desired *{{.type|raw}}) error {
+	if r.statusFieldManager != "" {
+		return r.applyStatus(ctx, logger, existing, desired)
+	}
+
+	original := existing
 	existing = existing.DeepCopy()
 	return {{.reconcilerRetryUpdateConflicts|raw}}(func(attempts int) (err error) {
+		want := desired
+
 		// The first iteration tries to use the injectionInformer's state, subsequent attempts fetch the latest state via API.
 		if attempts > 0 {
 			{{if .nonNamespaced}}
@@ -577,20 +739,26 @@ func (r *reconcilerImpl) updateStatus(ctx {{.contextContext|raw}}, logger *{{.za
 			if err != nil {
 				return err
 			}
+
+			// Resolve the conflict with the status written since it was read.
+			want = desired.DeepCopy()
+			if err := r.statusConflictStrategy.Resolve(&original.Status, &existing.Status, &want.Status); err != nil {
+				return err
+			}
 		}
 
 		// If there's nothing to update, just return.
-		if {{.equalitySemantic|raw}}.DeepEqual(existing.Status, desired.Status) {
+		if {{.equalitySemantic|raw}}.DeepEqual(existing.Status, want.Status) {
 			return nil
 		}
 
 		if logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
-			if diff, err := {{.kmpSafeDiff|raw}}(existing.Status, desired.Status); err == nil && diff != "" {
+			if diff, err := {{.kmpSafeDiff|raw}}(existing.Status, want.Status); err == nil && diff != "" {
 				logger.Debug("Updating status with: ", diff)
 			}
 		}
 
-		existing.Status = desired.Status
+		existing.Status = want.Status
 
 		{{if .nonNamespaced}}
 		updater := r.Client.{{.group}}{{.version}}().{{.type|apiGroup}}()
@@ -601,6 +769,34 @@ func (r *reconcilerImpl) updateStatus(ctx {{.contextContext|raw}}, logger *{{.za
 		return err
 	})
 }
+
+// applyStatus applies the status of desired with server-side apply, as
+// r.statusFieldManager, leaving alone the status fields owned by the other
+// field managers.
+func (r *reconcilerImpl) applyStatus(ctx {{.contextContext|raw}}, logger *{{.zapSugaredLogger|raw}}, existing *{{.type|raw}}, desired *{{.type|raw}}) error {
+	if logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
+		if diff, err := {{.kmpSafeDiff|raw}}(existing.Status, desired.Status); err == nil && diff != "" {
+			logger.Debug("Applying status with: ", diff)
+		}
+	}
+
+	patch, err := {{.reconcilerStatusApplyPatch|raw}}({{.schemeGroupVersion|raw}}.WithKind("{{.kind}}"), r.statusFieldManager, existing, existing.Status, desired.Status)
+	if err != nil {
+		return err
+	}
+
+	{{if .nonNamespaced}}
+	patcher := r.Client.{{.group}}{{.version}}().{{.type|apiGroup}}()
+	{{else}}
+	patcher := r.Client.{{.group}}{{.version}}().{{.type|apiGroup}}(existing.Namespace)
+	{{end}}
+	force := true
+	_, err = patcher.Patch(ctx, existing.Name, {{.typesApplyPatchType|raw}}, patch, {{.metav1PatchOptions|raw}}{
+		FieldManager: r.statusFieldManager,
+		Force:        &force,
+	}, "status")
+	return err
+}
 `
 
 var reconcilerFinalizerFactory = `
@@ -633,10 +829,15 @@ func (r *reconcilerImpl) updateFinalizersFiltered(ctx {{.contextContext|raw}}, r
 		finalizers = existingFinalizers.List()
 	}
 
+	return r.patchFinalizers(ctx, resource, finalizers)
+}
+
+// patchFinalizers will set the Finalizers of the resource to finalizers.
+func (r *reconcilerImpl) patchFinalizers(ctx {{.contextContext|raw}}, resource *{{.type|raw}}, finalizers []string) (*{{.type|raw}}, error) {
 	mergePatch := map[string]interface{}{
 		"metadata": map[string]interface{}{
 			"finalizers":      finalizers,
-			"resourceVersion": existing.ResourceVersion,
+			"resourceVersion": resource.ResourceVersion,
 		},
 	}
 
@@ -653,7 +854,7 @@ func (r *reconcilerImpl) updateFinalizersFiltered(ctx {{.contextContext|raw}}, r
 	resourceName := resource.Name
 	updated, err := patcher.Patch(ctx, resourceName, {{.typesMergePatchType|raw}}, patch, {{.metav1PatchOptions|raw}}{})
 	if err != nil {
-		r.Recorder.Eventf(existing, {{.corev1EventTypeWarning|raw}}, "FinalizerUpdateFailed",
+		r.Recorder.Eventf(resource, {{.corev1EventTypeWarning|raw}}, "FinalizerUpdateFailed",
 			"Failed to update finalizers for %q: %v", resourceName, err)
 	} else {
 		r.Recorder.Eventf(updated, {{.corev1EventTypeNormal|raw}}, "FinalizerUpdate",
@@ -703,4 +904,39 @@ func (r *reconcilerImpl) clearFinalizer(ctx {{.contextContext|raw}}, resource *{
 	return r.updateFinalizersFiltered(ctx, resource, finalizers)
 }
 
+func (r *reconcilerImpl) setNamedFinalizers(ctx {{.contextContext|raw}}, resource *{{.type|raw}}) (*{{.type|raw}}, error) {
+	nf, ok := r.reconciler.(NamedFinalizers)
+	if !ok || !resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil
+	}
+
+	finalizers, changed := {{.reconcilerNewFinalizerRegistry|raw}}(nf.FinalizerNames()...).Ensure(resource.Finalizers)
+	if !changed {
+		return resource, nil
+	}
+	return r.patchFinalizers(ctx, resource, finalizers)
+}
+
+func (r *reconcilerImpl) finalizeNamed(ctx {{.contextContext|raw}}, resource *{{.type|raw}}) (*{{.type|raw}}, {{.reconcilerEvent|raw}}, error) {
+	nf, ok := r.reconciler.(NamedFinalizers)
+	if !ok || resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil, nil
+	}
+
+	finalizers, changed, event := {{.reconcilerNewFinalizerRegistry|raw}}(nf.FinalizerNames()...).Finalize(resource.Finalizers, func(name string) {{.reconcilerEvent|raw}} {
+		return nf.FinalizeNamed(ctx, name, resource)
+	})
+	if !changed {
+		return resource, event, nil
+	}
+	resource, err := r.patchFinalizers(ctx, resource, finalizers)
+	return resource, event, err
+}
+
+// finalizeNamedOnly is the FinalizeKind of the reconcilers which implement
+// NamedFinalizers but not Finalizer.
+func finalizeNamedOnly({{.contextContext|raw}}, *{{.type|raw}}) {{.reconcilerEvent|raw}} {
+	return nil
+}
+
 `
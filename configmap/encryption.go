@@ -0,0 +1,275 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// EncryptedValuePrefix marks a ConfigMap value as an encryption envelope.
+// The full envelope format is:
+//
+//	enc:v1:<key id>:<base64 wrapped data key>:<base64 nonce+ciphertext>
+//
+// The data key is a random AES-256 key that encrypts the value with AES-GCM,
+// and is itself wrapped by the key encryption key identified by <key id>.
+const EncryptedValuePrefix = "enc:v1:"
+
+// ErrNotEncrypted is returned by DecryptValue when the value is not an
+// encryption envelope.
+var ErrNotEncrypted = errors.New("value is not an encryption envelope")
+
+// KeyDecrypter unwraps data encryption keys. Implementations typically
+// delegate to a KMS plugin, or use a key encryption key mounted from a Secret.
+type KeyDecrypter interface {
+	// DecryptKey unwraps the data key that was wrapped by the key
+	// encryption key with the given id.
+	DecryptKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// KeyDecrypterFunc is an adapter to allow the use of ordinary functions as
+// KeyDecrypter, e.g. a thin wrapper around a KMS client.
+type KeyDecrypterFunc func(keyID string, wrapped []byte) ([]byte, error)
+
+// DecryptKey implements KeyDecrypter
+func (f KeyDecrypterFunc) DecryptKey(keyID string, wrapped []byte) ([]byte, error) {
+	return f(keyID, wrapped)
+}
+
+// FileKeyDecrypter unwraps data keys using AES key encryption keys read from
+// a directory, typically a mounted Secret, where each file name is a key id
+// and each file holds a raw 16, 24 or 32 byte AES key. Keys are read on every
+// call so that rotated Secrets are picked up without a restart.
+type FileKeyDecrypter struct {
+	Dir string
+}
+
+var _ KeyDecrypter = (*FileKeyDecrypter)(nil)
+
+// DecryptKey implements KeyDecrypter
+func (f *FileKeyDecrypter) DecryptKey(keyID string, wrapped []byte) ([]byte, error) {
+	if keyID == "" || keyID != filepath.Base(keyID) {
+		return nil, fmt.Errorf("invalid key id %q", keyID)
+	}
+	kek, err := os.ReadFile(filepath.Join(f.Dir, keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %q: %w", keyID, err)
+	}
+	return open(kek, wrapped)
+}
+
+// Decrypter decrypts designated keys of ConfigMaps before they are handed to
+// Observers.
+type Decrypter struct {
+	// KeyDecrypter unwraps the per-value data keys.
+	KeyDecrypter KeyDecrypter
+
+	// Keys holds the designated keys of each ConfigMap, indexed by
+	// ConfigMap name. Designated keys must hold an encryption envelope,
+	// all other keys are passed through untouched.
+	Keys map[string]sets.String
+}
+
+// DecryptValue decrypts a single envelope produced by EncryptValue. It
+// returns ErrNotEncrypted when value is not an envelope.
+func (d *Decrypter) DecryptValue(value string) (string, error) {
+	if !strings.HasPrefix(value, EncryptedValuePrefix) {
+		return "", ErrNotEncrypted
+	}
+	parts := strings.Split(strings.TrimPrefix(value, EncryptedValuePrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed envelope, expected 3 fields but got %d", len(parts))
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed data key: %w", err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed ciphertext: %w", err)
+	}
+	dek, err := d.KeyDecrypter.DecryptKey(parts[0], wrapped)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	plain, err := open(dek, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// Decrypt returns a copy of the ConfigMap with its designated keys
+// decrypted. The input is never modified. ConfigMaps without designated
+// keys are returned as is.
+func (d *Decrypter) Decrypt(cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	keys := d.Keys[cm.Name]
+	if keys.Len() == 0 {
+		return cm, nil
+	}
+	cm = cm.DeepCopy()
+	for _, k := range keys.List() {
+		v, ok := cm.Data[k]
+		if !ok {
+			continue
+		}
+		plain, err := d.DecryptValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %q in ConfigMap %s/%s: %w", k, cm.Namespace, cm.Name, err)
+		}
+		cm.Data[k] = plain
+	}
+	return cm, nil
+}
+
+// DecryptData decrypts the designated keys of the named ConfigMap in data
+// in place. It is meant to be used together with Load.
+func (d *Decrypter) DecryptData(name string, data map[string]string) error {
+	for _, k := range d.Keys[name].List() {
+		v, ok := data[k]
+		if !ok {
+			continue
+		}
+		plain, err := d.DecryptValue(v)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %q: %w", k, err)
+		}
+		data[k] = plain
+	}
+	return nil
+}
+
+// EncryptValue produces an envelope for plaintext that is decryptable by a
+// Decrypter whose KeyDecrypter has access to kek under keyID. It is mostly
+// useful for tooling and tests, as well as with FileKeyDecrypter.
+func EncryptValue(keyID string, kek []byte, plaintext string) (string, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	wrapped, err := seal(kek, dek)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	sealed, err := seal(dek, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return EncryptedValuePrefix + keyID + ":" +
+		base64.StdEncoding.EncodeToString(wrapped) + ":" +
+		base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptingWatcher wraps a Watcher so that Observers receive ConfigMaps
+// with their designated keys already decrypted. Since decryption happens
+// before the Observers run, an UntypedStore watching through it is unaware
+// of the encryption. ConfigMaps that fail to decrypt are logged and not
+// delivered, so Observers keep their last good value.
+type DecryptingWatcher struct {
+	Watcher
+	Decrypter *Decrypter
+	Logger    Logger
+}
+
+var _ DefaultingWatcher = (*DecryptingWatcher)(nil)
+
+// NewDecryptingWatcher creates a DecryptingWatcher for w.
+func NewDecryptingWatcher(w Watcher, d *Decrypter, logger Logger) *DecryptingWatcher {
+	return &DecryptingWatcher{
+		Watcher:   w,
+		Decrypter: d,
+		Logger:    logger,
+	}
+}
+
+// Watch implements Watcher
+func (w *DecryptingWatcher) Watch(name string, o ...Observer) {
+	w.Watcher.Watch(name, w.wrap(o)...)
+}
+
+// WatchWithDefault implements DefaultingWatcher. It panics if the
+// wrapped Watcher is not a DefaultingWatcher.
+func (w *DecryptingWatcher) WatchWithDefault(cm corev1.ConfigMap, o ...Observer) {
+	dw, ok := w.Watcher.(DefaultingWatcher)
+	if !ok {
+		panic(fmt.Sprintf("%T is not a DefaultingWatcher", w.Watcher))
+	}
+	dw.WatchWithDefault(cm, w.wrap(o)...)
+}
+
+func (w *DecryptingWatcher) wrap(obs []Observer) []Observer {
+	wrapped := make([]Observer, 0, len(obs))
+	for _, o := range obs {
+		o := o
+		wrapped = append(wrapped, func(cm *corev1.ConfigMap) {
+			decrypted, err := w.Decrypter.Decrypt(cm)
+			if err != nil {
+				w.Logger.Errorf("Dropping ConfigMap update: %v", err)
+				return
+			}
+			o(decrypted)
+		})
+	}
+	return wrapped
+}
+
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
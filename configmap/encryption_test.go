@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+var testKEK = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestDecrypter(t *testing.T) *Decrypter {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "primary"), testKEK, 0o600); err != nil {
+		t.Fatal("WriteFile() =", err)
+	}
+	return &Decrypter{
+		KeyDecrypter: &FileKeyDecrypter{Dir: dir},
+		Keys: map[string]sets.String{
+			"config-secret": sets.NewString("password"),
+		},
+	}
+}
+
+func mustEncrypt(t *testing.T, keyID, value string) string {
+	t.Helper()
+	enc, err := EncryptValue(keyID, testKEK, value)
+	if err != nil {
+		t.Fatal("EncryptValue() =", err)
+	}
+	return enc
+}
+
+func TestDecryptValue(t *testing.T) {
+	d := newTestDecrypter(t)
+
+	got, err := d.DecryptValue(mustEncrypt(t, "primary", "hunter2"))
+	if err != nil {
+		t.Fatal("DecryptValue() =", err)
+	}
+	if want := "hunter2"; got != want {
+		t.Errorf("DecryptValue() = %q, want: %q", got, want)
+	}
+
+	if _, err := d.DecryptValue("hunter2"); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("DecryptValue() = %v, want: %v", err, ErrNotEncrypted)
+	}
+
+	for name, v := range map[string]string{
+		"too few fields":   EncryptedValuePrefix + "primary:abc",
+		"bad data key":     EncryptedValuePrefix + "primary:!!!:abc",
+		"unknown key":      mustEncrypt(t, "secondary", "hunter2"),
+		"path in key id":   mustEncrypt(t, "../primary", "hunter2"),
+		"bad ciphertext":   mustEncrypt(t, "primary", "hunter2")[:40] + "AAAA",
+		"empty ciphertext": EncryptedValuePrefix + "primary:AAAA:",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := d.DecryptValue(v); err == nil {
+				t.Error("DecryptValue() = nil, wanted error")
+			}
+		})
+	}
+}
+
+func TestDecryptConfigMap(t *testing.T) {
+	d := newTestDecrypter(t)
+	enc := mustEncrypt(t, "primary", "hunter2")
+	in := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config-secret"},
+		Data: map[string]string{
+			"password": enc,
+			"username": "admin",
+		},
+	}
+
+	out, err := d.Decrypt(in)
+	if err != nil {
+		t.Fatal("Decrypt() =", err)
+	}
+	if got, want := out.Data["password"], "hunter2"; got != want {
+		t.Errorf("password = %q, want: %q", got, want)
+	}
+	if got, want := out.Data["username"], "admin"; got != want {
+		t.Errorf("username = %q, want: %q", got, want)
+	}
+	if got := in.Data["password"]; got != enc {
+		t.Error("Decrypt() modified its input")
+	}
+
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config-other"}}
+	if out, err := d.Decrypt(other); err != nil || out != other {
+		t.Errorf("Decrypt() = %v, %v, want input unchanged", out, err)
+	}
+
+	in.Data["password"] = "plaintext"
+	if _, err := d.Decrypt(in); err == nil {
+		t.Error("Decrypt() = nil, wanted error for plaintext designated key")
+	}
+}
+
+func TestDecryptData(t *testing.T) {
+	d := newTestDecrypter(t)
+	data := map[string]string{
+		"password": mustEncrypt(t, "primary", "hunter2"),
+	}
+	if err := d.DecryptData("config-secret", data); err != nil {
+		t.Fatal("DecryptData() =", err)
+	}
+	if got, want := data["password"], "hunter2"; got != want {
+		t.Errorf("password = %q, want: %q", got, want)
+	}
+
+	data["password"] = "plaintext"
+	if err := d.DecryptData("config-secret", data); err == nil {
+		t.Error("DecryptData() = nil, wanted error")
+	}
+}
+
+func TestDecryptingWatcher(t *testing.T) {
+	d := newTestDecrypter(t)
+	mw := &ManualWatcher{Namespace: "default"}
+	w := NewDecryptingWatcher(mw, d, TestLogger(t))
+
+	store := NewUntypedStore("test", TestLogger(t), Constructors{
+		"config-secret": func(cm *corev1.ConfigMap) (string, error) {
+			return cm.Data["password"], nil
+		},
+	})
+	store.WatchConfigs(w)
+
+	mw.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config-secret"},
+		Data: map[string]string{
+			"password": mustEncrypt(t, "primary", "hunter2"),
+		},
+	})
+	if got, want := store.UntypedLoad("config-secret"), "hunter2"; got != want {
+		t.Errorf("UntypedLoad() = %v, want: %v", got, want)
+	}
+
+	// Updates that fail to decrypt are dropped.
+	mw.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config-secret"},
+		Data: map[string]string{
+			"password": "plaintext",
+		},
+	})
+	if got, want := store.UntypedLoad("config-secret"), "hunter2"; got != want {
+		t.Errorf("UntypedLoad() = %v, want: %v", got, want)
+	}
+}
+
+func TestDecryptingWatcherWithDefault(t *testing.T) {
+	d := newTestDecrypter(t)
+	w := NewDecryptingWatcher(&ManualWatcher{Namespace: "default"}, d, TestLogger(t))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("WatchWithDefault() did not panic for a non-defaulting watcher")
+		}
+	}()
+	w.WatchWithDefault(corev1.ConfigMap{}, func(*corev1.ConfigMap) {})
+}
+
+func TestKeyDecrypterFunc(t *testing.T) {
+	d := &Decrypter{
+		KeyDecrypter: KeyDecrypterFunc(func(keyID string, wrapped []byte) ([]byte, error) {
+			if keyID != "kms" {
+				return nil, errors.New("unknown key")
+			}
+			return open(testKEK, wrapped)
+		}),
+	}
+	got, err := d.DecryptValue(mustEncrypt(t, "kms", "hunter2"))
+	if err != nil {
+		t.Fatal("DecryptValue() =", err)
+	}
+	if want := "hunter2"; got != want {
+		t.Errorf("DecryptValue() = %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GroupObserver is the signature of the callbacks that notify an observer of
+// the latest state of a group of ConfigMaps, keyed by name. An observer
+// should not modify the provided ConfigMaps, as for Observer.
+type GroupObserver func(map[string]*corev1.ConfigMap)
+
+// GroupWatcher is similar to Watcher, but also notifies observers of the
+// state of groups of ConfigMaps.
+type GroupWatcher interface {
+	Watcher
+
+	// WatchGroup is called to register callbacks to be notified when any of
+	// the named ConfigMaps changes, see WatchGroup.
+	WatchGroup(names []string, o ...GroupObserver)
+}
+
+// WatchGroup registers the observers with the watcher, to be notified with
+// the latest state of all the named ConfigMaps whenever any of them changes,
+// once they have all been observed. Unlike with an Observer per ConfigMap,
+// the observers never see a partial configuration, e.g. only the first of
+// two ConfigMaps which must be consistent with each other, and are never
+// called concurrently for the same group.
+func WatchGroup(w Watcher, names []string, o ...GroupObserver) {
+	g := &configMapGroup{
+		names:     make(map[string]struct{}, len(names)),
+		latest:    make(map[string]*corev1.ConfigMap, len(names)),
+		observers: o,
+	}
+	for _, name := range names {
+		g.names[name] = struct{}{}
+	}
+	for name := range g.names {
+		w.Watch(name, g.observe)
+	}
+}
+
+// configMapGroup collects the latest state of the ConfigMaps of a group.
+type configMapGroup struct {
+	names     map[string]struct{}
+	observers []GroupObserver
+
+	// Guards latest, and serializes the calls of the observers.
+	mu     sync.Mutex
+	latest map[string]*corev1.ConfigMap
+}
+
+// observe is the Observer of each ConfigMap of the group.
+func (g *configMapGroup) observe(cm *corev1.ConfigMap) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.latest[cm.Name] = cm
+	if len(g.latest) < len(g.names) {
+		// Wait for all the ConfigMaps to be present.
+		return
+	}
+	for _, o := range g.observers {
+		cms := make(map[string]*corev1.ConfigMap, len(g.latest))
+		for name, cm := range g.latest {
+			cms[name] = cm
+		}
+		o(cms)
+	}
+}
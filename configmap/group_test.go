@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// groupCounter records the versions of the ConfigMaps of the groups it
+// observes, e.g. "1,2" for the first version of foo and the second of bar.
+type groupCounter struct {
+	groups []string
+}
+
+func (c *groupCounter) callback(cms map[string]*corev1.ConfigMap) {
+	c.groups = append(c.groups, cms["foo"].Data["version"]+","+cms["bar"].Data["version"])
+}
+
+func versioned(name, version string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Data:       map[string]string{"version": version},
+	}
+}
+
+func TestManualWatchGroup(t *testing.T) {
+	watcher := &ManualWatcher{Namespace: "default"}
+	group := &groupCounter{}
+	watcher.WatchGroup([]string{"foo", "bar", "foo"}, group.callback)
+
+	// Not called until all the ConfigMaps are present.
+	watcher.OnChange(versioned("foo", "1"))
+	watcher.OnChange(versioned("foo", "2"))
+	if len(group.groups) != 0 {
+		t.Fatalf("Groups = %v, wanted none before bar is present", group.groups)
+	}
+
+	// Then called once per change, with all of them.
+	watcher.OnChange(versioned("bar", "1"))
+	watcher.OnChange(versioned("foo", "3"))
+	watcher.OnChange(versioned("baz", "1"))
+	watcher.OnChange(versioned("bar", "2"))
+
+	want := []string{"2,1", "3,1", "3,2"}
+	if len(group.groups) != len(want) {
+		t.Fatalf("Groups = %v, wanted %v", group.groups, want)
+	}
+	for i := range want {
+		if group.groups[i] != want[i] {
+			t.Errorf("Group %d = %s, wanted %s", i, group.groups[i], want[i])
+		}
+	}
+}
+
+func TestStaticWatchGroup(t *testing.T) {
+	watcher := NewStaticWatcher(versioned("foo", "1"), versioned("bar", "1"))
+	group := &groupCounter{}
+	watcher.WatchGroup([]string{"foo", "bar"}, group.callback)
+
+	if want := []string{"1,1"}; len(group.groups) != 1 || group.groups[0] != want[0] {
+		t.Errorf("Groups = %v, wanted %v", group.groups, want)
+	}
+}
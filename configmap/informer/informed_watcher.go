@@ -107,6 +107,9 @@ var _ configmap.Watcher = (*InformedWatcher)(nil)
 // Asserts that InformedWatcher implements DefaultingWatcher.
 var _ configmap.DefaultingWatcher = (*InformedWatcher)(nil)
 
+// Asserts that InformedWatcher implements GroupWatcher.
+var _ configmap.GroupWatcher = (*InformedWatcher)(nil)
+
 // WatchWithDefault implements DefaultingWatcher. Adding a default for the configMap being watched means that when
 // Start is called, Start will not wait for the add event from the API server.
 func (i *InformedWatcher) WatchWithDefault(cm corev1.ConfigMap, o ...configmap.Observer) {
@@ -31,7 +31,7 @@ type ManualWatcher struct {
 	observers map[string][]Observer
 }
 
-var _ Watcher = (*ManualWatcher)(nil)
+var _ GroupWatcher = (*ManualWatcher)(nil)
 
 // Watch implements Watcher
 func (w *ManualWatcher) Watch(name string, o ...Observer) {
@@ -44,6 +44,11 @@ func (w *ManualWatcher) Watch(name string, o ...Observer) {
 	w.observers[name] = append(w.observers[name], o...)
 }
 
+// WatchGroup implements GroupWatcher
+func (w *ManualWatcher) WatchGroup(names []string, o ...GroupObserver) {
+	WatchGroup(w, names, o...)
+}
+
 // ForEach implements Watcher
 func (w *ManualWatcher) ForEach(f func(string, []Observer) error) error {
 	for k, v := range w.observers {
@@ -37,8 +37,8 @@ type StaticWatcher struct {
 	cfgs map[string]*corev1.ConfigMap
 }
 
-// Asserts that fixedImpl implements Watcher.
-var _ Watcher = (*StaticWatcher)(nil)
+// Asserts that fixedImpl implements GroupWatcher.
+var _ GroupWatcher = (*StaticWatcher)(nil)
 
 // Watch implements Watcher
 func (di *StaticWatcher) Watch(name string, o ...Observer) {
@@ -52,6 +52,11 @@ func (di *StaticWatcher) Watch(name string, o ...Observer) {
 	}
 }
 
+// WatchGroup implements GroupWatcher
+func (di *StaticWatcher) WatchGroup(names []string, o ...GroupObserver) {
+	WatchGroup(di, names, o...)
+}
+
 // Start implements Watcher
 func (di *StaticWatcher) Start(<-chan struct{}) error {
 	return nil
@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// BackoffReset decides when the backoff of a key under a BackoffPolicy is
+// reset.
+type BackoffReset int
+
+const (
+	// ResetOnSuccess resets the backoff of a key when it is reconciled
+	// successfully, so that its consecutive failures of the class back off
+	// further even when failures of other classes came in between.
+	ResetOnSuccess BackoffReset = iota
+
+	// ResetOnOtherError also resets the backoff of a key when its
+	// reconciliation fails with an error of another class, so that only its
+	// consecutive failures of the class back off further.
+	ResetOnOtherError
+)
+
+// BackoffPolicy decides how keys whose reconciliation failed with a class of
+// errors are requeued, e.g. to retry flaky failures fast while backing off
+// aggressively from failures which are unlikely to go away by themselves.
+type BackoffPolicy struct {
+	// Matches returns whether the error belongs to the class of the policy,
+	// typically by looking through its wrapped errors, e.g. with
+	// ErrorClass.Matches.
+	Matches func(error) bool
+
+	// RateLimiter computes how long keys wait before being retried, e.g. a
+	// workqueue.ItemExponentialFailureRateLimiter capping the backoff.
+	RateLimiter workqueue.RateLimiter
+
+	// Reset decides when the backoff of a key is reset.
+	// Default value is ResetOnSuccess if no value is passed.
+	Reset BackoffReset
+}
+
+// ErrorClass marks the errors of a class, for BackoffPolicies to match them.
+type ErrorClass struct {
+	name string
+}
+
+// NewErrorClass returns a new ErrorClass, named for the errors it marks.
+func NewErrorClass(name string) *ErrorClass {
+	return &ErrorClass{name: name}
+}
+
+// Wrap returns an error marking the given error, if not nil, as belonging to
+// the class. The returned error wraps the given one.
+func (c *ErrorClass) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return classifiedError{class: c, e: err}
+}
+
+// Matches returns whether the error, or any error it wraps, was marked as
+// belonging to the class.
+func (c *ErrorClass) Matches(err error) bool {
+	return errors.Is(err, classifiedError{class: c})
+}
+
+// String returns the name of the class.
+func (c *ErrorClass) String() string {
+	return c.name
+}
+
+// classifiedError is an error marked as belonging to an ErrorClass.
+type classifiedError struct {
+	class *ErrorClass
+	e     error
+}
+
+var _ error = classifiedError{}
+
+// Error implements the Error() interface of error.
+func (err classifiedError) Error() string {
+	return err.e.Error()
+}
+
+// Unwrap implements the Unwrap() interface of error. It returns the error
+// wrapped inside classifiedError.
+func (err classifiedError) Unwrap() error {
+	return err.e
+}
+
+// Is implements the Is() interface of error. It returns whether the target
+// error is a classifiedError of the same class.
+func (err classifiedError) Is(target error) bool {
+	//nolint: errorlint // This check is actually fine.
+	t, ok := target.(classifiedError)
+	return ok && t.class == err.class
+}
+
+// requeueWithBackoff requeues the key per the first BackoffPolicy matching
+// the error, and returns whether one did.
+func (c *Impl) requeueWithBackoff(key types.NamespacedName, err error) bool {
+	matched := -1
+	for i, p := range c.BackoffPolicies {
+		if p.Matches(err) {
+			matched = i
+			break
+		}
+	}
+	for i, p := range c.BackoffPolicies {
+		if i != matched && p.Reset == ResetOnOtherError {
+			p.RateLimiter.Forget(key)
+		}
+	}
+	if matched < 0 {
+		return false
+	}
+
+	c.workQueue.AddAfter(key, c.BackoffPolicies[matched].RateLimiter.When(key))
+	return true
+}
+
+// forget stops tracking the failures of the key.
+func (c *Impl) forget(key types.NamespacedName) {
+	c.workQueue.Forget(key)
+	for _, p := range c.BackoffPolicies {
+		p.RateLimiter.Forget(key)
+	}
+	if c.CircuitBreaker != nil {
+		c.CircuitBreaker.forget(key)
+	}
+	c.retries.forget(key)
+}
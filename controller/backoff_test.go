@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestErrorClass(t *testing.T) {
+	transient := NewErrorClass("transient")
+	validation := NewErrorClass("validation")
+
+	err := errors.New("boom")
+	if transient.Wrap(nil) != nil {
+		t.Error("Wrap(nil) != nil")
+	}
+
+	wrapped := fmt.Errorf("reconciling: %w", transient.Wrap(err))
+	if !transient.Matches(wrapped) {
+		t.Error("Expected the wrapped error to match its class")
+	}
+	if validation.Matches(wrapped) {
+		t.Error("Expected the wrapped error not to match another class")
+	}
+	if transient.Matches(err) {
+		t.Error("Expected the unmarked error not to match")
+	}
+	if !errors.Is(wrapped, err) {
+		t.Error("Expected the marked error to wrap the original one")
+	}
+	if got, want := wrapped.Error(), "reconciling: boom"; got != want {
+		t.Errorf("Error() = %q, wanted %q", got, want)
+	}
+
+	// Errors may be marked with several classes, e.g. when rethrown.
+	both := validation.Wrap(wrapped)
+	if !transient.Matches(both) || !validation.Matches(both) {
+		t.Error("Expected the error to match both of its classes")
+	}
+}
+
+// newSlowRateLimiter returns a rate limiter with long delays, so that the
+// keys requeued by the tests of the error handling are not handed out again.
+func newSlowRateLimiter() workqueue.RateLimiter {
+	return workqueue.NewItemExponentialFailureRateLimiter(time.Hour, 24*time.Hour)
+}
+
+// newErrorHandlingImpl returns a controller with a nopReconciler and the
+// options, along with a test logger and a fake stats reporter, whose errors
+// are handled by calling its handleErr directly.
+func newErrorHandlingImpl(t *testing.T, opts ControllerOptions) *Impl {
+	t.Helper()
+	opts.Logger = TestLogger(t)
+	opts.Reporter = &FakeStatsReporter{}
+	if opts.WorkQueueName == "" {
+		opts.WorkQueueName = "Testing"
+	}
+	impl := NewContext(context.TODO(), &nopReconciler{}, opts)
+	t.Cleanup(impl.WorkQueue().ShutDown)
+	return impl
+}
+
+func TestBackoffPolicies(t *testing.T) {
+	transient := NewErrorClass("transient")
+	external := NewErrorClass("external")
+
+	transientLimiter, externalLimiter, defaultLimiter := newSlowRateLimiter(), newSlowRateLimiter(), newSlowRateLimiter()
+
+	impl := newErrorHandlingImpl(t, ControllerOptions{
+		RateLimiter: defaultLimiter,
+		BackoffPolicies: []BackoffPolicy{{
+			Matches:     transient.Matches,
+			RateLimiter: transientLimiter,
+			Reset:       ResetOnOtherError,
+		}, {
+			Matches:     external.Matches,
+			RateLimiter: externalLimiter,
+		}},
+	})
+
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	logger := TestLogger(t)
+	check := func(step string, wantTransient, wantExternal, wantDefault int) {
+		t.Helper()
+		if got := transientLimiter.NumRequeues(key); got != wantTransient {
+			t.Errorf("%s: transient requeues = %d, wanted %d", step, got, wantTransient)
+		}
+		if got := externalLimiter.NumRequeues(key); got != wantExternal {
+			t.Errorf("%s: external requeues = %d, wanted %d", step, got, wantExternal)
+		}
+		if got := defaultLimiter.NumRequeues(key); got != wantDefault {
+			t.Errorf("%s: default requeues = %d, wanted %d", step, got, wantDefault)
+		}
+	}
+
+	impl.handleErr(logger, transient.Wrap(errors.New("timeout")), key, time.Now())
+	impl.handleErr(logger, transient.Wrap(errors.New("timeout")), key, time.Now())
+	check("transient errors", 2, 0, 0)
+
+	impl.handleErr(logger, external.Wrap(errors.New("unavailable")), key, time.Now())
+	check("external error", 0, 1, 0)
+
+	impl.handleErr(logger, errors.New("unclassified"), key, time.Now())
+	check("unclassified error", 0, 1, 1)
+
+	impl.handleErr(logger, transient.Wrap(errors.New("timeout")), key, time.Now())
+	impl.handleErr(logger, external.Wrap(errors.New("unavailable")), key, time.Now())
+	check("external error after transient one", 0, 2, 1)
+
+	impl.handleErr(logger, NewPermanentError(external.Wrap(errors.New("invalid"))), key, time.Now())
+	check("permanent error", 0, 0, 0)
+}
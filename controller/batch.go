@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/logging/logkey"
+)
+
+// BatchReconciler is implemented by the Reconcilers processing several keys
+// at once, e.g. to aggregate configuration, when the BatchSize of their
+// controller is positive. The workers then drain up to BatchSize keys from
+// the work queue and hand them to ReconcileBatch, instead of calling
+// Reconcile for each. To drain the work queue, a single worker processes the
+// batches of each of its queues, whatever the concurrency of the controller.
+type BatchReconciler interface {
+	// ReconcileBatch reconciles the keys, as namespace/name strings, and
+	// returns the errors of those whose reconcile failed, by key. The keys
+	// missing from the errors succeeded. The errors are handled as those of
+	// Reconcile, e.g. NewRequeueAfter requeues a key of the batch.
+	ReconcileBatch(ctx context.Context, keys []string) map[string]error
+}
+
+// batchReconciler returns the Reconciler as a BatchReconciler when the batch
+// mode is enabled, nil otherwise.
+func (c *Impl) batchReconciler() BatchReconciler {
+	if c.BatchSize <= 0 {
+		return nil
+	}
+	br, _ := c.Reconciler.(BatchReconciler)
+	return br
+}
+
+// readyLen returns the number of keys q can return without blocking, which
+// excludes those in the lanes of a twoLaneQueue not yet moved to its
+// consumer queue.
+func readyLen(q workqueue.Interface) int {
+	if tlq, ok := q.(*twoLaneQueue); ok {
+		return tlq.consumerQueue.Len()
+	}
+	return q.Len()
+}
+
+// processNextBatchFrom processes the next batch of keys read off q, one of
+// the consumers of the workqueue: it blocks for the first key, and adds up to
+// BatchSize keys which are already queued.
+func (c *Impl) processNextBatchFrom(br BatchReconciler, q workqueue.Interface) bool {
+	obj, shutdown := q.Get()
+	if shutdown {
+		return false
+	}
+	var keys []types.NamespacedName
+	var versions []string
+	for {
+		key := obj.(types.NamespacedName)
+		c.processingPending(key)
+		if version, stale := c.versions.start(key); stale {
+			c.logger.Debugf("Skipping stale key %s at version %s", safeKey(key), version)
+			c.dropPending(key)
+			c.workQueue.Done(key)
+		} else {
+			keys = append(keys, key)
+			versions = append(versions, version)
+		}
+		if len(keys) == c.BatchSize || readyLen(q) == 0 {
+			break
+		}
+		if obj, shutdown = q.Get(); shutdown {
+			break
+		}
+	}
+	if len(keys) == 0 {
+		return true
+	}
+	keyStrs := make([]string, len(keys))
+	for i, key := range keys {
+		keyStrs[i] = safeKey(key)
+	}
+
+	c.logger.Debugf("Processing batch of %d keys from queue (depth: %d)", len(keys), c.workQueue.Len())
+
+	startTime := time.Now()
+	// Send the metrics for the current queue depth
+	c.statsReporter.ReportQueueDepth(int64(c.workQueue.Len()))
+
+	logger := c.logger.With(zap.String(logkey.TraceID, uuid.NewString()), zap.Int("batch", len(keys)))
+	ctx := logging.WithLogger(context.Background(), logger)
+	if c.Journal != nil {
+		ctx = WithJournal(ctx, c.Journal)
+	}
+
+	errs := br.ReconcileBatch(ctx, keyStrs)
+
+	failed := 0
+	for i, key := range keys {
+		err := errs[keyStrs[i]]
+		keyLogger := logger.With(zap.String(logkey.Key, keyStrs[i]))
+
+		status := trueString
+		if err != nil {
+			status = falseString
+			failed++
+			c.handleErr(keyLogger, err, key, startTime)
+		} else {
+			c.forget(key)
+			c.dropPending(key)
+			c.versions.succeeded(key, versions[i])
+		}
+		c.statsReporter.ReportReconcile(time.Since(startTime), status, key)
+
+		// Done after the key is requeued on errors, as processNextWorkItem.
+		c.workQueue.Done(key)
+	}
+	c.firstReconcile.record(c)
+	logger.Infow("Batch reconciled", zap.Int("failed", failed), zap.Duration("duration", time.Since(startTime)))
+
+	return true
+}
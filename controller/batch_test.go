@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+// batchingReconciler records its batches, failing the keys of errs.
+type batchingReconciler struct {
+	errs    map[string]error
+	batches [][]string
+}
+
+func (r *batchingReconciler) Reconcile(context.Context, string) error {
+	return errors.New("unexpected call of Reconcile")
+}
+
+func (r *batchingReconciler) ReconcileBatch(_ context.Context, keys []string) map[string]error {
+	r.batches = append(r.batches, keys)
+	errs := make(map[string]error, len(keys))
+	for _, key := range keys {
+		if err, ok := r.errs[key]; ok {
+			errs[key] = err
+		}
+	}
+	return errs
+}
+
+func TestProcessNextBatch(t *testing.T) {
+	r := &batchingReconciler{errs: map[string]error{
+		"ns/b": errors.New("transient"),
+		"ns/c": NewPermanentError(errors.New("permanent")),
+	}}
+	impl := NewContext(context.TODO(), r, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Batch",
+		Reporter:      &FakeStatsReporter{},
+		BatchSize:     3,
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+
+	br := impl.batchReconciler()
+	if br == nil {
+		t.Fatal("batchReconciler() = nil, wanted the Reconciler")
+	}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		impl.EnqueueKey(types.NamespacedName{Namespace: "ns", Name: name})
+	}
+	q := impl.workQueue.consumers()[0]
+	if err := wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return readyLen(q) == 5, nil
+	}); err != nil {
+		t.Fatal("Keys never reached the consumer queue:", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if !impl.processNextBatchFrom(br, q) {
+			t.Fatal("processNextBatchFrom() = false, wanted true")
+		}
+	}
+	var got []string
+	for _, batch := range r.batches {
+		got = append(got, batch...)
+	}
+	sort.Strings(got)
+	if want := []string{"ns/a", "ns/b", "ns/c", "ns/d", "ns/e"}; !cmp.Equal(got, want) {
+		t.Error("Reconciled keys (-want, +got):", cmp.Diff(want, got))
+	}
+	if got, want := len(r.batches[0]), 3; got != want {
+		t.Errorf("len(first batch) = %d, wanted %d", got, want)
+	}
+
+	// Only the transient failure is retried.
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		want := 0
+		if name == "b" {
+			want = 1
+		}
+		key := types.NamespacedName{Namespace: "ns", Name: name}
+		if got := impl.workQueue.NumRequeues(key); got != want {
+			t.Errorf("NumRequeues(%s) = %d, wanted %d", name, got, want)
+		}
+	}
+}
+
+func TestBatchReconcilerDisabled(t *testing.T) {
+	impl := NewContext(context.TODO(), &batchingReconciler{}, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "NoBatch",
+		Reporter:      &FakeStatsReporter{},
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+
+	if br := impl.batchReconciler(); br != nil {
+		t.Errorf("batchReconciler() = %v, wanted nil without a BatchSize", br)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/reconciler"
+)
+
+// ownedBuckets holds the leader election buckets owned by this replica, by
+// work queue name, which tag the workqueue metrics.
+var ownedBuckets = &bucketSet{owned: make(map[string]map[string]struct{})}
+
+type bucketSet struct {
+	mu    sync.RWMutex
+	owned map[string]map[string]struct{}
+}
+
+func (bs *bucketSet) promote(queue, bucket string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.owned[queue] == nil {
+		bs.owned[queue] = make(map[string]struct{})
+	}
+	bs.owned[queue][bucket] = struct{}{}
+}
+
+func (bs *bucketSet) demote(queue, bucket string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	delete(bs.owned[queue], bucket)
+	if len(bs.owned[queue]) == 0 {
+		delete(bs.owned, queue)
+	}
+}
+
+// get returns the sorted names of the buckets owned for the work queue,
+// joined by commas.
+func (bs *bucketSet) get(queue string) string {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	names := make([]string, 0, len(bs.owned[queue]))
+	for name := range bs.owned[queue] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// laneSuffixes are the suffixes of the names of the lanes of the work queues.
+var laneSuffixes = []string{"-fast", "-slow", "-high", "-consumer"}
+
+// workqueueBucket returns the buckets owned for the work queue of the lane
+// of the given name, for the workqueue metrics.
+func workqueueBucket(lane string) string {
+	queue := lane
+	for _, suffix := range laneSuffixes {
+		if q := strings.TrimSuffix(lane, suffix); q != lane {
+			queue = q
+			break
+		}
+	}
+	// The lanes of the shards of a work queue share its buckets.
+	if i := strings.LastIndex(queue, shardSuffix); i >= 0 {
+		queue = queue[:i]
+	}
+	return ownedBuckets.get(queue)
+}
+
+// bucketTracker records the buckets the wrapped reconciler is promoted for
+// in ownedBuckets.
+type bucketTracker struct {
+	reconciler.LeaderAware
+	queue string
+}
+
+var _ reconciler.LeaderAware = (*bucketTracker)(nil)
+
+// Promote implements reconciler.LeaderAware.
+func (bt *bucketTracker) Promote(b reconciler.Bucket, enq func(reconciler.Bucket, types.NamespacedName)) error {
+	if err := bt.LeaderAware.Promote(b, enq); err != nil {
+		return err
+	}
+	ownedBuckets.promote(bt.queue, b.Name())
+	return nil
+}
+
+// Demote implements reconciler.LeaderAware.
+func (bt *bucketTracker) Demote(b reconciler.Bucket) {
+	ownedBuckets.demote(bt.queue, b.Name())
+	bt.LeaderAware.Demote(b)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/reconciler"
+)
+
+type namedBucket string
+
+func (b namedBucket) Name() string {
+	return string(b)
+}
+
+func (namedBucket) Has(types.NamespacedName) bool {
+	return true
+}
+
+func TestBucketTracker(t *testing.T) {
+	var promoteErr error
+	bt := &bucketTracker{
+		LeaderAware: &reconciler.LeaderAwareFuncs{
+			PromoteFunc: func(reconciler.Bucket, func(reconciler.Bucket, types.NamespacedName)) error {
+				return promoteErr
+			},
+		},
+		queue: "tracked",
+	}
+	enq := func(reconciler.Bucket, types.NamespacedName) {}
+
+	if err := bt.Promote(namedBucket("tracked.01-of-02"), enq); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	if err := bt.Promote(namedBucket("tracked.00-of-02"), enq); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	for _, lane := range []string{"tracked", "tracked-fast", "tracked-slow", "tracked-high", "tracked-consumer", "tracked-shard-1-fast"} {
+		if got, want := workqueueBucket(lane), "tracked.00-of-02,tracked.01-of-02"; got != want {
+			t.Errorf("workqueueBucket(%q) = %q, wanted %q", lane, got, want)
+		}
+	}
+	if got := workqueueBucket("other-fast"); got != "" {
+		t.Errorf("workqueueBucket(other-fast) = %q, wanted none", got)
+	}
+
+	bt.Demote(namedBucket("tracked.01-of-02"))
+	if got, want := workqueueBucket("tracked-fast"), "tracked.00-of-02"; got != want {
+		t.Errorf("workqueueBucket() after demotion = %q, wanted %q", got, want)
+	}
+
+	// A failed promotion does not own the bucket.
+	promoteErr = errors.New("boom")
+	if err := bt.Promote(namedBucket("tracked.01-of-02"), enq); !errors.Is(err, promoteErr) {
+		t.Errorf("Promote() = %v, wanted %v", err, promoteErr)
+	}
+	if got, want := workqueueBucket("tracked-fast"), "tracked.00-of-02"; got != want {
+		t.Errorf("workqueueBucket() after a failed promotion = %q, wanted %q", got, want)
+	}
+
+	bt.Demote(namedBucket("tracked.00-of-02"))
+	if got := workqueueBucket("tracked-fast"); got != "" {
+		t.Errorf("workqueueBucket() after demotions = %q, wanted none", got)
+	}
+}
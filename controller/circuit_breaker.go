@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CircuitBreaker parks the keys which failed to reconcile Threshold times in
+// a row: rather than being requeued with backoff, they are requeued after
+// CoolDown. A key which fails again after its cool-down is parked again,
+// until it is reconciled successfully or with a permanent error.
+// Parked keys are still enqueued by the events of their objects, so that
+// fixing a broken object does not wait for the end of the cool-down.
+// A CircuitBreaker tracks the failures of the keys of a single controller.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failures of a key after which
+	// it is parked.
+	Threshold int
+
+	// CoolDown is how long a key is parked for.
+	CoolDown time.Duration
+
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// fail records a failure of the key, and returns whether it is parked.
+func (cb *CircuitBreaker) fail(key types.NamespacedName) (failures int, parked bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.failures == nil {
+		cb.failures = make(map[types.NamespacedName]int)
+	}
+	cb.failures[key]++
+	failures = cb.failures[key]
+	return failures, failures >= cb.Threshold
+}
+
+// forget stops tracking the failures of the key.
+func (cb *CircuitBreaker) forget(key types.NamespacedName) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	delete(cb.failures, key)
+}
+
+// parkIfBroken records a failure of the key and, once it failed Threshold
+// times in a row, requeues it after the cool-down and returns true.
+func (c *Impl) parkIfBroken(logger *zap.SugaredLogger, key types.NamespacedName) bool {
+	if c.CircuitBreaker == nil {
+		return false
+	}
+	failures, parked := c.CircuitBreaker.fail(key)
+	if !parked {
+		return false
+	}
+
+	c.workQueue.AddAfter(key, c.CircuitBreaker.CoolDown)
+	// The parked key is not persisted as pending, for its cool-down not to
+	// be cut short by a restart.
+	if c.pending != nil {
+		c.pending.remove(key)
+	}
+	logger.Warnw("Parking key after consecutive failures",
+		zap.Int("failures", failures), zap.Duration("coolDown", c.CircuitBreaker.CoolDown))
+	if err := reportParkedKey(c.Name, key); err != nil {
+		logger.Warnw("Failed to report the parked key", zap.Error(err))
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	rl := newSlowRateLimiter()
+	impl := newErrorHandlingImpl(t, ControllerOptions{
+		WorkQueueName: "Breaking",
+		RateLimiter:   rl,
+		CircuitBreaker: &CircuitBreaker{
+			Threshold: 3,
+			CoolDown:  time.Hour,
+		},
+	})
+
+	parked := metricstest.Expect(t, "parked_key_count").WithTags(
+		map[string]string{"reconciler": "Breaking", "namespace_name": "foo"})
+	before := parked.Value()
+
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	logger := TestLogger(t)
+	fail := func() {
+		impl.handleErr(logger, errors.New("boom"), key, time.Now())
+	}
+
+	fail()
+	fail()
+	if got, want := rl.NumRequeues(key), 2; got != want {
+		t.Errorf("Requeues before parking = %d, wanted %d", got, want)
+	}
+
+	// Parked, rather than requeued with backoff.
+	fail()
+	fail()
+	if got, want := rl.NumRequeues(key), 2; got != want {
+		t.Errorf("Requeues after parking = %d, wanted %d", got, want)
+	}
+	parked.Delta(before, 2)
+
+	// A success closes the circuit.
+	impl.forget(key)
+	fail()
+	if got, want := rl.NumRequeues(key), 1; got != want {
+		t.Errorf("Requeues after success = %d, wanted %d", got, want)
+	}
+}
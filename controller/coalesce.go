@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging/logkey"
+)
+
+// EventCoalescer enqueues the keys of the objects it is handed at most once
+// per window: the events of an object within the window of its previous
+// enqueue are coalesced into a single enqueue at the end of that window.
+// This keeps objects updated extremely frequently, e.g. by another
+// controller hot-looping on their status, from dominating the work queue.
+// The coalesced events are reported per key, to identify the hot objects.
+//
+// It is used in place of the Enqueue methods of the Impl in the event
+// handlers, e.g.:
+//
+//	coalescer := impl.NewEventCoalescer(time.Second)
+//	informer.AddEventHandler(controller.HandleAll(coalescer.Enqueue))
+type EventCoalescer struct {
+	name   string
+	window time.Duration
+	logger *zap.SugaredLogger
+
+	enqueue      func(types.NamespacedName)
+	enqueueAfter func(types.NamespacedName, time.Duration)
+	clock        clock.PassiveClock
+
+	mu sync.Mutex
+	// next holds the earliest time each key may be enqueued again without
+	// being coalesced, the end of the window of its last enqueue.
+	next      map[types.NamespacedName]time.Time
+	lastSweep time.Time
+}
+
+// NewEventCoalescer returns an EventCoalescer enqueuing into the work queue
+// of the controller, with the given window.
+func (c *Impl) NewEventCoalescer(window time.Duration) *EventCoalescer {
+	return &EventCoalescer{
+		name:         c.Name,
+		window:       window,
+		logger:       c.logger,
+		enqueue:      c.EnqueueKey,
+		enqueueAfter: c.EnqueueKeyAfter,
+		clock:        clock.RealClock{},
+		next:         make(map[types.NamespacedName]time.Time),
+	}
+}
+
+// Enqueue takes a resource, converts it into a namespace/name string,
+// and passes it to EnqueueKey.
+func (ec *EventCoalescer) Enqueue(obj interface{}) {
+	object, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		ec.logger.Errorw("Enqueue", zap.Error(err))
+		return
+	}
+	ec.EnqueueKey(types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()})
+}
+
+// EnqueueKey puts the key onto the work queue, right away if it was not
+// enqueued within the window, at the end of the window otherwise.
+func (ec *EventCoalescer) EnqueueKey(key types.NamespacedName) {
+	now := ec.clock.Now()
+
+	ec.mu.Lock()
+	ec.sweep(now)
+	next, ok := ec.next[key]
+	switch {
+	case !ok || !now.Before(next):
+		// Not hot, enqueue right away.
+		ec.next[key] = now.Add(ec.window)
+		ec.mu.Unlock()
+		ec.enqueue(key)
+		return
+	case now.Before(next.Add(-ec.window)):
+		// An enqueue at the end of the window is already scheduled.
+	default:
+		// Schedule an enqueue at the end of the window of the last one.
+		ec.next[key] = next.Add(ec.window)
+		ec.enqueueAfter(key, next.Sub(now))
+	}
+	ec.mu.Unlock()
+
+	if err := reportCoalescedEvent(ec.name, key); err != nil {
+		ec.logger.Warnw("Failed to report the coalesced event", zap.Error(err))
+	}
+	ec.logger.Debugw("Coalesced the event of a hot object", zap.String(logkey.Key, key.String()))
+}
+
+// sweep forgets the keys whose window ended, once per window.
+// It must be called with the lock held.
+func (ec *EventCoalescer) sweep(now time.Time) {
+	if now.Sub(ec.lastSweep) < ec.window {
+		return
+	}
+	ec.lastSweep = now
+	for key, next := range ec.next {
+		if !now.Before(next) {
+			delete(ec.next, key)
+		}
+	}
+}
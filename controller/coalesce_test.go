@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	testingclock "k8s.io/utils/clock/testing"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+func TestEventCoalescer(t *testing.T) {
+	impl := NewContext(context.TODO(), &nopReconciler{}, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Coalescing",
+		Reporter:      &FakeStatsReporter{},
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+
+	type enqueue struct {
+		Key   types.NamespacedName
+		Delay time.Duration
+	}
+	var got []enqueue
+	clk := testingclock.NewFakePassiveClock(time.Now())
+	ec := impl.NewEventCoalescer(time.Second)
+	ec.clock = clk
+	ec.enqueue = func(key types.NamespacedName) {
+		got = append(got, enqueue{Key: key})
+	}
+	ec.enqueueAfter = func(key types.NamespacedName, delay time.Duration) {
+		got = append(got, enqueue{Key: key, Delay: delay})
+	}
+
+	coalesced := metricstest.Expect(t, "coalesced_event_count").WithTags(
+		map[string]string{"reconciler": "Coalescing", "key": "foo/hot"})
+	before := coalesced.Value()
+
+	hot := types.NamespacedName{Namespace: "foo", Name: "hot"}
+	cold := types.NamespacedName{Namespace: "foo", Name: "cold"}
+	step := func(d time.Duration) {
+		clk.SetTime(clk.Now().Add(d))
+	}
+
+	ec.EnqueueKey(hot)
+	ec.EnqueueKey(cold)
+	step(200 * time.Millisecond)
+	// Scheduled at the end of the window.
+	ec.EnqueueKey(hot)
+	step(200 * time.Millisecond)
+	// Coalesced into the scheduled enqueue.
+	ec.EnqueueKey(hot)
+	step(time.Second)
+	// Within the window of the scheduled enqueue.
+	ec.EnqueueKey(hot)
+	step(10 * time.Second)
+	// Cooled down.
+	ec.EnqueueKey(hot)
+	ec.EnqueueKey(cold)
+
+	want := []enqueue{
+		{Key: hot},
+		{Key: cold},
+		{Key: hot, Delay: 800 * time.Millisecond},
+		{Key: hot, Delay: 600 * time.Millisecond},
+		{Key: hot},
+		{Key: cold},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Enqueues (-want, +got):", diff)
+	}
+	coalesced.Delta(before, 3)
+}
@@ -21,9 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opencensus.io/trace"
 	"golang.org/x/sync/errgroup"
 
 	"go.uber.org/zap"
@@ -52,6 +54,33 @@ const (
 	// DefaultResyncPeriod is the default duration that is used when no
 	// resync period is associated with a controllers initialization context.
 	DefaultResyncPeriod = 10 * time.Hour
+
+	// DefaultStarvationLimit is the default number of keys of higher
+	// priority processed in a row by the controllers with a priority queue
+	// after which a key of lower priority is processed.
+	DefaultStarvationLimit = 10
+)
+
+// Priority is the priority of a key in the work queue, see
+// Impl.EnqueueKeyWithPriority.
+type Priority int
+
+const (
+	// PriorityLow is the priority of the keys of the slow lane, e.g. the
+	// background cleanups and global resyncs.
+	PriorityLow Priority = iota - 1
+
+	// PriorityNormal is the priority of the keys enqueued by EnqueueKey.
+	PriorityNormal
+
+	// PriorityHigh is the priority of the keys to be processed before the
+	// others waiting in the work queue, e.g. those of user-facing resources,
+	// up to the StarvationLimit of the controller. The keys are picked by
+	// priority as the workers get them, so a key of higher priority only
+	// waits for those already handed to the workers. It requires the
+	// PriorityQueue option of the controller, without which the keys are
+	// enqueued with PriorityNormal.
+	PriorityHigh
 )
 
 var (
@@ -204,11 +233,73 @@ type Impl struct {
 	// never processing the same item simultaneously in two different workers.
 	// The slow queue is used for global resync and other background processes
 	// which are not required to complete at the highest priority.
-	workQueue *twoLaneQueue
+	workQueue controllerQueue
 
 	// Concurrency - The number of workers to use when processing the controller's workqueue.
 	Concurrency int
 
+	// BackoffPolicies decide how keys are requeued after their reconciliation
+	// failed, by the first policy matching the error. Keys are requeued per
+	// the RateLimiter of the ControllerOptions if none matches.
+	// They must be set before the controller is run.
+	BackoffPolicies []BackoffPolicy
+
+	// CircuitBreaker, if set, parks the keys failing persistently instead of
+	// requeuing them with backoff indefinitely.
+	// It must be set before the controller is run.
+	CircuitBreaker *CircuitBreaker
+
+	// MaxRetries, if positive, is the number of times a key is retried after
+	// failing to reconcile before it is handed to DeadLetter and dropped,
+	// rather than being retried forever. Changes to its object enqueue it
+	// again. They must be set before the controller is run.
+	MaxRetries int
+	DeadLetter DeadLetterFunc
+	retries    retryCounter
+
+	// ScheduleDedupeWindow collapses the schedules of a key by
+	// EnqueueKeyAfterWithJitter within this window of a pending one.
+	// It must be set before the controller is run.
+	ScheduleDedupeWindow time.Duration
+	schedules            schedules
+
+	// versions tracks the versions of the objects enqueued by
+	// EnqueueVersioned.
+	versions keyVersions
+
+	// pauser holds the workers while the controller is paused.
+	pauser pauser
+
+	// firstReconcile records the time to the first reconcile.
+	firstReconcile firstReconcile
+
+	// ReconcileTimeout, if positive, is the deadline of the context of each
+	// reconcile, after which the worker moves on and the key is retried,
+	// see ErrReconcileTimeout. A Reconciler ignoring the deadline keeps
+	// running in the background though, and its key is not handed out to
+	// another worker before it returns. Such reconciles are counted by the
+	// abandoned_reconcile_count metric, so that their leaks are visible.
+	// It must be set before the controller is run.
+	ReconcileTimeout time.Duration
+	abandoned        atomic.Int64
+
+	// Journal, if set, is passed to the Reconciler through the context of
+	// the reconciles, see GetJournal, and scanned for interrupted intents
+	// when the controller starts.
+	// It must be set before the controller is run.
+	Journal *Journal
+
+	// BatchSize, if positive and the Reconciler is a BatchReconciler, makes
+	// the workers hand the keys queued to the Reconciler in batches of up to
+	// this size, see BatchReconciler.
+	// It must be set before the controller is run.
+	BatchSize int
+
+	// pending tracks the keys of the work queue, which are persisted in
+	// pendingKeyStore at shutdown, if set through ControllerOptions.
+	pending         *pendingKeys
+	pendingKeyStore PendingKeyStore
+
 	// Sugared logger is easier to use but is not as performant as the
 	// raw logger. In performance critical paths, call logger.Desugar()
 	// and use the returned raw logger instead. In addition to the
@@ -232,6 +323,64 @@ type ControllerOptions struct { //nolint // for backcompat.
 	Reporter      StatsReporter
 	RateLimiter   workqueue.RateLimiter
 	Concurrency   int
+
+	// BackoffPolicies decide how keys are requeued after their
+	// reconciliation failed with some classes of errors, see
+	// Impl.BackoffPolicies.
+	BackoffPolicies []BackoffPolicy
+
+	// CircuitBreaker parks the keys failing persistently, see
+	// Impl.CircuitBreaker.
+	CircuitBreaker *CircuitBreaker
+
+	// MaxRetries and DeadLetter drop the keys failing persistently, see
+	// Impl.MaxRetries.
+	MaxRetries int
+	DeadLetter DeadLetterFunc
+
+	// ScheduleDedupeWindow collapses repeated schedules of a key, see
+	// Impl.ScheduleDedupeWindow.
+	ScheduleDedupeWindow time.Duration
+
+	// ReconcileTimeout bounds the duration of the reconciles, see
+	// Impl.ReconcileTimeout.
+	ReconcileTimeout time.Duration
+
+	// Journal records the side effects of the reconciles, see
+	// Impl.Journal.
+	Journal *Journal
+
+	// BatchSize hands the keys queued to a BatchReconciler in batches, see
+	// Impl.BatchSize.
+	BatchSize int
+
+	// PriorityQueue adds a lane for the keys enqueued with PriorityHigh to
+	// the work queue, and keeps the keys of lower priority from starving:
+	// after StarvationLimit keys of higher priority in a row, a waiting key
+	// of lower priority is processed. StarvationLimit defaults to
+	// DefaultStarvationLimit.
+	PriorityQueue   bool
+	StarvationLimit int
+
+	// Shards, if greater than 1, spreads the keys across this number of
+	// work queues by their hash, each with its own workers, so that the
+	// controllers of many objects do not contend on a single queue. A key is
+	// still never processed by two workers at once. Each shard gets at
+	// least one worker, and the Concurrency of the controller is spread
+	// across them.
+	Shards int
+
+	// KeyRateLimiter plugs a rate limiter per key in the work queue, e.g.
+	// NewNamespaceRateLimiters, so that the retries of a misbehaving resource
+	// do not delay the others. The keys it returns no rate limiter for are
+	// rate limited by RateLimiter.
+	KeyRateLimiter KeyRateLimiterFunc
+
+	// PendingKeys, if set, persists the keys queued but not yet processed,
+	// including those delayed by backoffs, when the controller shuts down,
+	// and enqueues them again when it starts, so that their work is not
+	// lost until a resync.
+	PendingKeys PendingKeyStore
 }
 
 // NewContext instantiates an instance of our controller that will feed work to the
@@ -240,19 +389,65 @@ func NewContext(ctx context.Context, r Reconciler, options ControllerOptions) *I
 	if options.RateLimiter == nil {
 		options.RateLimiter = workqueue.DefaultControllerRateLimiter()
 	}
+	if options.KeyRateLimiter != nil {
+		options.RateLimiter = &keyRateLimiter{
+			fallback: options.RateLimiter,
+			forKey:   options.KeyRateLimiter,
+		}
+	}
 	if options.Reporter == nil {
 		options.Reporter = MustNewStatsReporter(options.WorkQueueName, options.Logger)
 	}
 	if options.Concurrency == 0 {
 		options.Concurrency = DefaultThreadsPerController
 	}
+	if options.PriorityQueue && options.StarvationLimit <= 0 {
+		options.StarvationLimit = DefaultStarvationLimit
+	}
+	// The priority queues hand off a key at a time to the workers, or a
+	// batch at a time to a BatchReconciler.
+	handoff := 1
+	if _, ok := r.(BatchReconciler); ok && options.BatchSize > 0 {
+		handoff = options.BatchSize
+	}
+	var workQueue controllerQueue
+	switch {
+	case options.Shards > 1:
+		starvationLimit := 0
+		if options.PriorityQueue {
+			starvationLimit = options.StarvationLimit
+		}
+		workQueue = newShardedWorkQueue(options.WorkQueueName, options.RateLimiter, options.Shards, starvationLimit, handoff)
+	case options.PriorityQueue:
+		workQueue = newPriorityWorkQueue(options.WorkQueueName, options.RateLimiter, options.StarvationLimit, handoff)
+	default:
+		workQueue = newTwoLaneWorkQueue(options.WorkQueueName, options.RateLimiter)
+	}
+	var pending *pendingKeys
+	if options.PendingKeys != nil {
+		pending = &pendingKeys{}
+		workQueue = &pendingQueue{controllerQueue: workQueue, pending: pending}
+	}
 	i := &Impl{
 		Name:          options.WorkQueueName,
 		Reconciler:    r,
-		workQueue:     newTwoLaneWorkQueue(options.WorkQueueName, options.RateLimiter),
+		workQueue:     workQueue,
 		logger:        options.Logger,
 		statsReporter: options.Reporter,
 		Concurrency:   options.Concurrency,
+
+		BackoffPolicies: options.BackoffPolicies,
+		CircuitBreaker:  options.CircuitBreaker,
+		MaxRetries:      options.MaxRetries,
+		DeadLetter:      options.DeadLetter,
+
+		ScheduleDedupeWindow: options.ScheduleDedupeWindow,
+		ReconcileTimeout:     options.ReconcileTimeout,
+		Journal:              options.Journal,
+		BatchSize:            options.BatchSize,
+
+		pending:         pending,
+		pendingKeyStore: options.PendingKeys,
 	}
 
 	if t := GetTracker(ctx); t != nil {
@@ -283,6 +478,7 @@ func (c *Impl) EnqueueAfter(obj interface{}, after time.Duration) {
 // EnqueueSlowKey takes a resource, converts it into a namespace/name string,
 // and enqueues that key in the slow lane.
 func (c *Impl) EnqueueSlowKey(key types.NamespacedName) {
+	c.versions.force(key)
 	c.workQueue.SlowLane().Add(key)
 
 	if logger := c.logger.Desugar(); logger.Core().Enabled(zapcore.DebugLevel) {
@@ -414,6 +610,12 @@ func (c *Impl) EnqueueNamespaceOf(obj interface{}) {
 
 // EnqueueKey takes a namespace/name string and puts it onto the work queue.
 func (c *Impl) EnqueueKey(key types.NamespacedName) {
+	c.versions.force(key)
+	c.enqueueKey(key)
+}
+
+// enqueueKey puts the key onto the work queue.
+func (c *Impl) enqueueKey(key types.NamespacedName) {
 	c.workQueue.Add(key)
 
 	if logger := c.logger.Desugar(); logger.Core().Enabled(zapcore.DebugLevel) {
@@ -422,6 +624,26 @@ func (c *Impl) EnqueueKey(key types.NamespacedName) {
 	}
 }
 
+// EnqueueKeyWithPriority takes a namespace/name string and puts it onto the
+// lane of the work queue of the given priority.
+func (c *Impl) EnqueueKeyWithPriority(key types.NamespacedName, priority Priority) {
+	switch {
+	case priority <= PriorityLow:
+		c.EnqueueSlowKey(key)
+	case priority >= PriorityHigh && c.workQueue.hasHighLane():
+		c.versions.force(key)
+		c.workQueue.HighLane().Add(key)
+
+		if logger := c.logger.Desugar(); logger.Core().Enabled(zapcore.DebugLevel) {
+			logger.Debug(fmt.Sprintf("Adding to the high queue %s (depth(total/high): %d/%d)",
+				safeKey(key), c.workQueue.Len(), c.workQueue.HighLane().Len()),
+				zap.String(logkey.Key, key.String()))
+		}
+	default:
+		c.EnqueueKey(key)
+	}
+}
+
 // MaybeEnqueueBucketKey takes a Bucket and namespace/name string and puts it onto
 // the slow work queue.
 func (c *Impl) MaybeEnqueueBucketKey(bkt reconciler.Bucket, key types.NamespacedName) {
@@ -433,6 +655,7 @@ func (c *Impl) MaybeEnqueueBucketKey(bkt reconciler.Bucket, key types.Namespaced
 // EnqueueKeyAfter takes a namespace/name string and schedules its execution in
 // the work queue after given delay.
 func (c *Impl) EnqueueKeyAfter(key types.NamespacedName, delay time.Duration) {
+	c.versions.force(key)
 	c.workQueue.AddAfter(key, delay)
 
 	if logger := c.logger.Desugar(); logger.Core().Enabled(zapcore.DebugLevel) {
@@ -441,6 +664,23 @@ func (c *Impl) EnqueueKeyAfter(key types.NamespacedName, delay time.Duration) {
 	}
 }
 
+// EnqueueKeyAfterWithJitter schedules the key like EnqueueKeyAfter, after
+// the delay plus a random jitter of up to the given fraction of it, e.g.
+// 0.1, so that the keys scheduled at fixed intervals do not all come back
+// at once. The schedule is dropped if the key is already scheduled within
+// the ScheduleDedupeWindow of it.
+func (c *Impl) EnqueueKeyAfterWithJitter(key types.NamespacedName, delay time.Duration, jitter float64) {
+	if jitter > 0 {
+		delay = wait.Jitter(delay, jitter)
+	}
+	now := time.Now()
+	if !c.schedules.schedule(key, now.Add(delay), c.ScheduleDedupeWindow, now) {
+		c.logger.Debugf("Dropping the schedule of %s after %v, already scheduled", safeKey(key), delay)
+		return
+	}
+	c.EnqueueKeyAfter(key, delay)
+}
+
 // Run runs the controller with it's configured Concurrency
 func (c *Impl) Run(ctx context.Context) error {
 	return c.RunContext(ctx, c.Concurrency)
@@ -453,16 +693,24 @@ func (c *Impl) Run(ctx context.Context) error {
 // work items.
 func (c *Impl) RunContext(ctx context.Context, threadiness int) error {
 	sg := sync.WaitGroup{}
+	started := false
 	defer func() {
 		c.workQueue.ShutDown()
 		for c.workQueue.Len() > 0 {
 			time.Sleep(time.Millisecond * 100)
 		}
 		sg.Wait()
+		if started {
+			// Save the keys left pending once the workers stopped,
+			// including those which failed while the queue drained.
+			c.savePendingKeys()
+		}
 		runtime.HandleCrash()
 	}()
 
 	if la, ok := c.Reconciler.(reconciler.LeaderAware); ok {
+		// Track the buckets owned for the workqueue metrics.
+		la = &bucketTracker{LeaderAware: la, queue: c.Name}
 		// Build and execute an elector.
 		le, err := kle.BuildElector(ctx, la, c.Name, c.MaybeEnqueueBucketKey)
 		if err != nil {
@@ -482,20 +730,54 @@ func (c *Impl) RunContext(ctx context.Context, threadiness int) error {
 		}()
 	}
 
-	// Launch workers to process resources that get enqueued to our workqueue.
+	if c.Journal != nil {
+		if err := c.recoverJournal(ctx); err != nil {
+			return err
+		}
+	}
+	if c.pending != nil {
+		if err := c.recoverPendingKeys(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Launch workers to process resources that get enqueued to our workqueue,
+	// at least one for each of the queues they get the keys from.
 	c.logger.Info("Starting controller and workers")
+	c.firstReconcile.started = time.Now()
+	consumers := c.workQueue.consumers()
+	process := c.processNextWorkItemFrom
+	if br := c.batchReconciler(); br != nil {
+		// A single worker drains each queue, see BatchReconciler.
+		threadiness = len(consumers)
+		process = func(q workqueue.Interface) bool {
+			return c.processNextBatchFrom(br, q)
+		}
+	}
+	if threadiness < len(consumers) {
+		threadiness = len(consumers)
+	}
 	for i := 0; i < threadiness; i++ {
 		sg.Add(1)
-		go func() {
+		go func(q workqueue.Interface) {
 			defer sg.Done()
-			for c.processNextWorkItem() {
+			for {
+				c.pauser.wait(ctx)
+				if !process(q) {
+					return
+				}
 			}
-		}()
+		}(consumers[i%len(consumers)])
 	}
 
+	started = true
 	c.logger.Info("Started workers")
 	<-ctx.Done()
 	c.logger.Info("Shutting down workers")
+	// Save the pending keys before the queue is drained too, which may not
+	// complete before the process is killed. The keys in flight stay
+	// pending until reconciled, so none is lost meanwhile.
+	c.savePendingKeys()
 
 	return nil
 }
@@ -503,12 +785,27 @@ func (c *Impl) RunContext(ctx context.Context, threadiness int) error {
 // processNextWorkItem will read a single work item off the workqueue and
 // attempt to process it, by calling Reconcile on our Reconciler.
 func (c *Impl) processNextWorkItem() bool {
-	obj, shutdown := c.workQueue.Get()
+	return c.processNextWorkItemFrom(c.workQueue)
+}
+
+// processNextWorkItemFrom processes a single work item read off q, one of the
+// consumers of the workqueue.
+func (c *Impl) processNextWorkItemFrom(q workqueue.Interface) bool {
+	obj, shutdown := q.Get()
 	if shutdown {
 		return false
 	}
 	key := obj.(types.NamespacedName)
 	keyStr := safeKey(key)
+	c.processingPending(key)
+
+	version, stale := c.versions.start(key)
+	if stale {
+		c.logger.Debugf("Skipping stale key %s at version %s", keyStr, version)
+		c.dropPending(key)
+		c.workQueue.Done(key)
+		return true
+	}
 
 	c.logger.Debugf("Processing from queue %s (depth: %d)", safeKey(key), c.workQueue.Len())
 
@@ -516,19 +813,36 @@ func (c *Impl) processNextWorkItem() bool {
 	// Send the metrics for the current queue depth
 	c.statsReporter.ReportQueueDepth(int64(c.workQueue.Len()))
 
-	var err error
+	var (
+		err     error
+		span    *trace.Span
+		running <-chan struct{}
+	)
 	defer func() {
+		endReconcileSpan(span, err)
+
 		status := trueString
 		if err != nil {
 			status = falseString
 		}
 		c.statsReporter.ReportReconcile(time.Since(startTime), status, key)
+		c.firstReconcile.record(c)
 
 		// We call Done here so the workqueue knows we have finished
 		// processing this item. We also must remember to call Forget if
 		// reconcile succeeds. If a transient error occurs, we do not call
 		// Forget and put the item back to the queue with an increased
 		// delay.
+		if running != nil {
+			// The Reconciler timed out but is still running: the key
+			// stays busy until it returns, so that it is not reconciled
+			// by another worker meanwhile.
+			go func() {
+				<-running
+				c.workQueue.Done(key)
+			}()
+			return
+		}
 		c.workQueue.Done(key)
 	}()
 
@@ -536,17 +850,26 @@ func (c *Impl) processNextWorkItem() bool {
 	// to the Reconciler.
 	logger := c.logger.With(zap.String(logkey.TraceID, uuid.NewString()), zap.String(logkey.Key, keyStr))
 	ctx := logging.WithLogger(context.Background(), logger)
+	if c.Journal != nil {
+		ctx = WithJournal(ctx, c.Journal)
+	}
+
+	// Wrap the reconcile in a span, propagated to the Reconciler through
+	// the context.
+	ctx, span = c.startReconcileSpan(ctx, key)
 
 	// Run Reconcile, passing it the namespace/name string of the
 	// resource to be synced.
-	if err = c.Reconciler.Reconcile(ctx, keyStr); err != nil {
+	if running, err = c.reconcile(ctx, key); err != nil {
 		c.handleErr(logger, err, key, startTime)
 		return true
 	}
 
 	// Finally, if no error occurs we Forget this item so it does not
 	// have any delay when another change happens.
-	c.workQueue.Forget(key)
+	c.forget(key)
+	c.dropPending(key)
+	c.versions.succeeded(key, version)
 	logger.Infow("Reconcile succeeded", zap.Duration("duration", time.Since(startTime)))
 
 	return true
@@ -554,7 +877,8 @@ func (c *Impl) processNextWorkItem() bool {
 
 func (c *Impl) handleErr(logger *zap.SugaredLogger, err error, key types.NamespacedName, startTime time.Time) {
 	if IsSkipKey(err) {
-		c.workQueue.Forget(key)
+		c.forget(key)
+		c.dropPending(key)
 		return
 	}
 	if ok, delay := IsRequeueKey(err); ok {
@@ -570,12 +894,25 @@ func (c *Impl) handleErr(logger *zap.SugaredLogger, err error, key types.Namespa
 	// since controller Run might have exited by now (since while this item was
 	// being processed, queue.Len==0).
 	if !IsPermanentError(err) && !c.workQueue.ShuttingDown() {
-		c.workQueue.AddRateLimited(key)
+		if c.dropIfExhausted(logger, key, err) {
+			return
+		}
+		if c.parkIfBroken(logger, key) {
+			return
+		}
+		if !c.requeueWithBackoff(key, err) {
+			c.workQueue.AddRateLimited(key)
+		}
 		logger.Debugf("Requeuing key %s due to non-permanent error (depth: %d)", safeKey(key), c.workQueue.Len())
 		return
 	}
 
-	c.workQueue.Forget(key)
+	c.forget(key)
+	// A key failing transiently while the queue shuts down is not requeued,
+	// but stays pending to be retried at the next start.
+	if IsPermanentError(err) {
+		c.dropPending(key)
+	}
 }
 
 // GlobalResync enqueues into the slow lane all objects from the passed SharedInformer
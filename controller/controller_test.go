@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -728,6 +729,41 @@ func TestEnqueue(t *testing.T) {
 	}
 }
 
+func TestEnqueueKeyWithPriority(t *testing.T) {
+	for _, priorityQueue := range []bool{false, true} {
+		t.Run(fmt.Sprint("priority queue ", priorityQueue), func(t *testing.T) {
+			impl := NewContext(context.TODO(), &nopReconciler{}, ControllerOptions{
+				WorkQueueName: "Testing",
+				Logger:        TestLogger(t),
+				PriorityQueue: priorityQueue,
+			})
+			if got := impl.workQueue.hasHighLane(); got != priorityQueue {
+				t.Errorf("High lane = %v, wanted %v", got, priorityQueue)
+			}
+			if priorityQueue && impl.workQueue.(*twoLaneQueue).starvationLimit != DefaultStarvationLimit {
+				t.Errorf("Starvation limit = %d, wanted %d", impl.workQueue.(*twoLaneQueue).starvationLimit, DefaultStarvationLimit)
+			}
+
+			impl.EnqueueKeyWithPriority(types.NamespacedName{Namespace: "foo", Name: "low"}, PriorityLow)
+			impl.EnqueueKeyWithPriority(types.NamespacedName{Namespace: "foo", Name: "normal"}, PriorityNormal)
+			impl.EnqueueKeyWithPriority(types.NamespacedName{Namespace: "foo", Name: "high"}, PriorityHigh)
+
+			impl.WorkQueue().ShutDown()
+			gotQueue := drainWorkQueue(impl.WorkQueue())
+
+			want := []types.NamespacedName{
+				{Namespace: "foo", Name: "high"},
+				{Namespace: "foo", Name: "low"},
+				{Namespace: "foo", Name: "normal"},
+			}
+			sort.Slice(gotQueue, func(i, j int) bool { return gotQueue[i].Name < gotQueue[j].Name })
+			if diff := cmp.Diff(want, gotQueue); diff != "" {
+				t.Error("unexpected queue (-want +got):", diff)
+			}
+		})
+	}
+}
+
 const (
 	// longDelay is longer than we expect the test to run.
 	longDelay = time.Minute
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/logging"
+)
+
+// DeadLetterFunc handles a key dropped after failing to reconcile more than
+// MaxRetries times, with its last error, e.g. by emitting an event or
+// marking the resource failed. The context carries the logger of the key.
+type DeadLetterFunc func(ctx context.Context, key types.NamespacedName, err error)
+
+// retryCounter counts the consecutive failures of the keys.
+type retryCounter struct {
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// fail records a failure of the key and returns the number of consecutive
+// failures.
+func (rc *retryCounter) fail(key types.NamespacedName) int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.failures == nil {
+		rc.failures = make(map[types.NamespacedName]int)
+	}
+	rc.failures[key]++
+	return rc.failures[key]
+}
+
+// forget stops counting the failures of the key.
+func (rc *retryCounter) forget(key types.NamespacedName) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.failures, key)
+}
+
+// dropIfExhausted records a failure of the key and, once it failed more than
+// MaxRetries times in a row, hands it to the DeadLetter handler, drops it
+// and returns true.
+func (c *Impl) dropIfExhausted(logger *zap.SugaredLogger, key types.NamespacedName, err error) bool {
+	if c.MaxRetries <= 0 {
+		return false
+	}
+	failures := c.retries.fail(key)
+	if failures <= c.MaxRetries {
+		return false
+	}
+
+	logger.Warnw("Dropping key after exhausting its retries", zap.Int("failures", failures))
+	if err := reportDeadLetteredKey(c.Name, key); err != nil {
+		logger.Warnw("Failed to report the dropped key", zap.Error(err))
+	}
+	if c.DeadLetter != nil {
+		c.DeadLetter(logging.WithLogger(context.Background(), logger), key, err)
+	}
+	c.forget(key)
+	c.dropPending(key)
+	return true
+}
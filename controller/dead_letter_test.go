@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/logging"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+func TestDeadLetter(t *testing.T) {
+	rl := newSlowRateLimiter()
+	var (
+		dead    []types.NamespacedName
+		lastErr error
+	)
+	impl := newErrorHandlingImpl(t, ControllerOptions{
+		WorkQueueName: "DeadLettering",
+		RateLimiter:   rl,
+		MaxRetries:    2,
+		DeadLetter: func(ctx context.Context, key types.NamespacedName, err error) {
+			if logging.FromContext(ctx) == nil {
+				t.Error("DeadLetter got no logger")
+			}
+			dead = append(dead, key)
+			lastErr = err
+		},
+	})
+
+	deadLettered := metricstest.Expect(t, "dead_lettered_key_count").WithTags(
+		map[string]string{"reconciler": "DeadLettering", "namespace_name": "foo"})
+	before := deadLettered.Value()
+
+	key := types.NamespacedName{Namespace: "foo", Name: "poison"}
+	logger := TestLogger(t)
+	boom := errors.New("boom")
+
+	impl.handleErr(logger, boom, key, time.Now())
+	impl.handleErr(logger, boom, key, time.Now())
+	if len(dead) != 0 {
+		t.Fatalf("DeadLetter called for %v after 2 failures, wanted no call", dead)
+	}
+	if got, want := rl.NumRequeues(key), 2; got != want {
+		t.Errorf("Requeues = %d, wanted %d", got, want)
+	}
+
+	impl.handleErr(logger, boom, key, time.Now())
+	if len(dead) != 1 || dead[0] != key || !errors.Is(lastErr, boom) {
+		t.Errorf("DeadLetter calls = %v with %v, wanted [%v] with %v", dead, lastErr, key, boom)
+	}
+	// The key is dropped, and its failures forgotten.
+	if got := rl.NumRequeues(key); got != 0 {
+		t.Errorf("Requeues after dropping = %d, wanted 0", got)
+	}
+	deadLettered.Delta(before, 1)
+
+	// Enqueued again, the key gets its retries back.
+	impl.handleErr(logger, boom, key, time.Now())
+	if len(dead) != 1 {
+		t.Errorf("DeadLetter calls = %v, wanted 1", dead)
+	}
+}
@@ -0,0 +1,376 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// DefaultStarvationThreshold is the default period an informer may go
+	// without observing an event or bookmark before it is considered starved.
+	// Watch bookmarks are sent roughly every minute, so this leaves ample room.
+	DefaultStarvationThreshold = 10 * time.Minute
+
+	// DefaultStarvationCheckInterval is the default period between two
+	// health checks of an informer.
+	DefaultStarvationCheckInterval = time.Minute
+)
+
+// ResourceVersioner is the subset of cache.SharedInformer used to track the
+// progress of an informer's watch. Both events and bookmarks advance the
+// last synced resource version.
+type ResourceVersioner interface {
+	LastSyncResourceVersion() string
+}
+
+// InformerHealthChecker detects informers whose watch has silently stopped
+// delivering events, e.g. after a network partition, which would otherwise
+// leave their caches stale without any visible failure.
+type InformerHealthChecker struct {
+	// Name identifies the informer in logs and metrics.
+	Name string
+
+	// Informer is the informer to check.
+	Informer ResourceVersioner
+
+	// Threshold is the period without progress after which the informer
+	// is considered starved. Defaults to DefaultStarvationThreshold.
+	Threshold time.Duration
+
+	// Interval is the period between two checks.
+	// Defaults to DefaultStarvationCheckInterval.
+	Interval time.Duration
+
+	// ActivityExpected reports whether changes to the watched resources
+	// are expected, so that a quiet watch on an idle resource is not
+	// mistaken for a starved one. When nil, activity is always expected.
+	ActivityExpected func() bool
+
+	// OnStarved is called every time the informer is found starved, e.g.
+	// to force a re-list via RelistableListerWatcher.ForceRelist.
+	// It is optional; starvation is always logged and reported.
+	OnStarved func()
+
+	clock clock.PassiveClock
+
+	mu           sync.Mutex
+	lastVersion  string
+	lastProgress time.Time
+}
+
+func (h *InformerHealthChecker) now() time.Time {
+	if h.clock == nil {
+		return time.Now()
+	}
+	return h.clock.Now()
+}
+
+// Run checks the informer every Interval until the context is cancelled.
+func (h *InformerHealthChecker) Run(ctx context.Context) {
+	interval := h.Interval
+	if interval <= 0 {
+		interval = DefaultStarvationCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Check(ctx)
+		}
+	}
+}
+
+// Check performs a single health check and returns true if the informer
+// is healthy. A starved informer is logged, reported and handed to
+// OnStarved, after which its progress clock restarts so that a re-list is
+// not forced again before another Threshold has passed.
+func (h *InformerHealthChecker) Check(ctx context.Context) bool {
+	threshold := h.Threshold
+	if threshold <= 0 {
+		threshold = DefaultStarvationThreshold
+	}
+	now := h.now()
+	version := h.Informer.LastSyncResourceVersion()
+
+	h.mu.Lock()
+	if version != h.lastVersion || h.lastProgress.IsZero() {
+		h.lastVersion = version
+		h.lastProgress = now
+		h.mu.Unlock()
+		return true
+	}
+	idle := now.Sub(h.lastProgress)
+	if idle < threshold || (h.ActivityExpected != nil && !h.ActivityExpected()) {
+		h.mu.Unlock()
+		return true
+	}
+	h.lastProgress = now
+	h.mu.Unlock()
+
+	logging.FromContext(ctx).Warnw("Informer watch appears starved",
+		zap.String("informer", h.Name),
+		zap.String("resourceVersion", version),
+		zap.Duration("idle", idle))
+	if err := reportInformerStarved(h.Name); err != nil {
+		logging.FromContext(ctx).Warnw("Failed to report informer starvation", zap.Error(err))
+	}
+	if h.OnStarved != nil {
+		h.OnStarved()
+	}
+	return false
+}
+
+// RelistableListerWatcher wraps a cache.ListerWatcher so that the reflector
+// driving it can be forced to perform a full re-list.
+type RelistableListerWatcher struct {
+	cache.ListerWatcher
+
+	mu      sync.Mutex
+	current watch.Interface
+	relist  bool
+}
+
+var _ cache.ListerWatcher = (*RelistableListerWatcher)(nil)
+
+// NewRelistableListerWatcher wraps the given cache.ListerWatcher.
+func NewRelistableListerWatcher(lw cache.ListerWatcher) *RelistableListerWatcher {
+	return &RelistableListerWatcher{ListerWatcher: lw}
+}
+
+// Watch implements cache.Watcher. After ForceRelist it fails once with an
+// expired error, which makes the reflector fall back to a list.
+func (lw *RelistableListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.relist {
+		lw.relist = false
+		return nil, apierrors.NewResourceExpired("forced re-list")
+	}
+	w, err := lw.ListerWatcher.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+	lw.current = w
+	return w, nil
+}
+
+// ForceRelist stops the active watch and makes the next watch attempt fail,
+// so that the reflector re-lists and re-establishes its watch.
+func (lw *RelistableListerWatcher) ForceRelist() {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.relist = true
+	if lw.current != nil {
+		lw.current.Stop()
+		lw.current = nil
+	}
+}
+
+// RelistTransport forces the reflectors of the informers of a resource,
+// built from the clients it wraps the transport of, e.g. with
+// rest.Config.Wrap, to re-list, as RelistableListerWatcher does for the
+// informers whose ListerWatcher is at hand, unlike those of injection.
+type RelistTransport struct {
+	mu      sync.Mutex
+	watches map[*relistWatch]struct{}
+	// relists counts the watches to fail per path.
+	relists map[string]int
+}
+
+// Wrap wraps the transport, see rest.Config.Wrap.
+func (t *RelistTransport) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return &relistRoundTripper{transport: t, delegate: rt}
+}
+
+// ForceRelist stops the active watches of the resource, in any namespace,
+// and makes the next watch attempt of each fail, so that their reflectors
+// re-list and re-establish their watches. The informers of the same resource
+// cannot be told apart, e.g. filtered ones, so all of them re-list.
+func (t *RelistTransport) ForceRelist(resource schema.GroupVersionResource) {
+	t.mu.Lock()
+	var watches []*relistWatch
+	for w := range t.watches {
+		if w.resource != resource {
+			continue
+		}
+		watches = append(watches, w)
+		delete(t.watches, w)
+		if t.relists == nil {
+			t.relists = make(map[string]int, 1)
+		}
+		t.relists[w.path]++
+	}
+	t.mu.Unlock()
+
+	for _, w := range watches {
+		w.ReadCloser.Close()
+	}
+}
+
+type relistRoundTripper struct {
+	transport *RelistTransport
+	delegate  http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *relistRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if watch := req.URL.Query().Get("watch"); watch != "true" && watch != "1" {
+		return rt.delegate.RoundTrip(req)
+	}
+	t, path := rt.transport, req.URL.Path
+	resource, ok := pathResource(path)
+	if !ok {
+		return rt.delegate.RoundTrip(req)
+	}
+
+	t.mu.Lock()
+	if t.relists[path] > 0 {
+		if t.relists[path]--; t.relists[path] == 0 {
+			delete(t.relists, path)
+		}
+		t.mu.Unlock()
+		return expiredResponse(req), nil
+	}
+	t.mu.Unlock()
+
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	w := &relistWatch{ReadCloser: resp.Body, transport: t, path: path, resource: resource}
+	t.mu.Lock()
+	if t.watches == nil {
+		t.watches = make(map[*relistWatch]struct{}, 1)
+	}
+	t.watches[w] = struct{}{}
+	t.mu.Unlock()
+	resp.Body = w
+	return resp, nil
+}
+
+// expiredResponse returns the response of the API server to a watch from a
+// resource version which expired, i.e. an error event, making the reflector
+// re-list.
+func expiredResponse(req *http.Request) *http.Response {
+	status := apierrors.NewResourceExpired("forced re-list").Status()
+	status.Kind, status.APIVersion = "Status", "v1"
+	body, _ := json.Marshal(&metav1.WatchEvent{
+		Type:   string(watch.Error),
+		Object: runtime.RawExtension{Object: &status},
+	})
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}
+}
+
+// pathResource returns the resource of the path of a request to the API
+// server, e.g. /api/v1/namespaces/foo/pods or /apis/apps/v1/deployments.
+func pathResource(path string) (schema.GroupVersionResource, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		var resource schema.GroupVersionResource
+		var rest []string
+		switch {
+		case part == "api" && len(parts) > i+2:
+			resource.Version, rest = parts[i+1], parts[i+2:]
+		case part == "apis" && len(parts) > i+3:
+			resource.Group, resource.Version, rest = parts[i+1], parts[i+2], parts[i+3:]
+		default:
+			continue
+		}
+		if len(rest) >= 3 && rest[0] == "namespaces" {
+			rest = rest[2:]
+		}
+		resource.Resource = rest[0]
+		return resource, true
+	}
+	return schema.GroupVersionResource{}, false
+}
+
+// relistWatch is the body of a watch response, tracked until closed.
+type relistWatch struct {
+	io.ReadCloser
+	transport *RelistTransport
+	path      string
+	resource  schema.GroupVersionResource
+}
+
+// Close implements io.Closer.
+func (w *relistWatch) Close() error {
+	w.transport.mu.Lock()
+	delete(w.transport.watches, w)
+	w.transport.mu.Unlock()
+	return w.ReadCloser.Close()
+}
+
+// RunInformerHealthCheckers runs an InformerHealthChecker, with the default
+// thresholds, for each of the informers tracking their progress, until the
+// context is cancelled. The informers are named after their resources if
+// they are ResourceInformers, e.g. those of injection, and the resources of
+// the starved ones are handed to onStarved, if any, e.g.
+// RelistTransport.ForceRelist. The starvation of the other informers is only
+// logged and reported.
+func RunInformerHealthCheckers(ctx context.Context, onStarved func(schema.GroupVersionResource), informers ...Informer) {
+	var wg sync.WaitGroup
+	for _, informer := range informers {
+		rv, ok := informer.(ResourceVersioner)
+		if !ok {
+			continue
+		}
+		h := &InformerHealthChecker{
+			Name:     informerName(informer),
+			Informer: rv,
+		}
+		if ri, ok := informer.(ResourceInformer); ok && onStarved != nil {
+			resource := ri.Resource()
+			h.OnStarved = func() { onStarved(resource) }
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.Run(ctx)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+type fakeVersioner struct {
+	mu      sync.Mutex
+	version string
+}
+
+func (f *fakeVersioner) LastSyncResourceVersion() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.version
+}
+
+func (f *fakeVersioner) set(v string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.version = v
+}
+
+func TestInformerHealthChecker(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	starvedCount := metricstest.Expect(t, "informer_starved_count").WithTags(
+		map[string]string{"informer": "test-informer"})
+	before := starvedCount.Value()
+	clk := testingclock.NewFakePassiveClock(time.Now())
+	inf := &fakeVersioner{version: "1"}
+	starved := 0
+	expected := true
+	h := &InformerHealthChecker{
+		Name:             "test-informer",
+		Informer:         inf,
+		Threshold:        5 * time.Minute,
+		ActivityExpected: func() bool { return expected },
+		OnStarved:        func() { starved++ },
+		clock:            clk,
+	}
+
+	// First check records the baseline.
+	if !h.Check(ctx) {
+		t.Error("Check() = false on first check")
+	}
+
+	// Progress within the threshold is healthy.
+	clk.SetTime(clk.Now().Add(4 * time.Minute))
+	if !h.Check(ctx) {
+		t.Error("Check() = false within threshold")
+	}
+
+	// Progress resets the clock.
+	inf.set("2")
+	clk.SetTime(clk.Now().Add(4 * time.Minute))
+	if !h.Check(ctx) {
+		t.Error("Check() = false after progress")
+	}
+	clk.SetTime(clk.Now().Add(4 * time.Minute))
+	if !h.Check(ctx) {
+		t.Error("Check() = false within threshold after progress")
+	}
+
+	// No activity expected means an idle watch is fine.
+	expected = false
+	clk.SetTime(clk.Now().Add(10 * time.Minute))
+	if !h.Check(ctx) {
+		t.Error("Check() = false when no activity is expected")
+	}
+
+	expected = true
+	if h.Check(ctx) {
+		t.Error("Check() = true for a starved informer")
+	}
+	if starved != 1 {
+		t.Errorf("OnStarved called %d times, want: 1", starved)
+	}
+	starvedCount.Delta(before, 1)
+
+	// After being flagged, the informer gets another threshold to recover.
+	clk.SetTime(clk.Now().Add(time.Minute))
+	if !h.Check(ctx) {
+		t.Error("Check() = false right after being flagged")
+	}
+	if starved != 1 {
+		t.Errorf("OnStarved called %d times, want: 1", starved)
+	}
+}
+
+func TestInformerHealthCheckerDefaults(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	clk := testingclock.NewFakePassiveClock(time.Now())
+	h := &InformerHealthChecker{
+		Name:     "defaults",
+		Informer: &fakeVersioner{},
+		clock:    clk,
+	}
+	h.Check(ctx)
+	clk.SetTime(clk.Now().Add(DefaultStarvationThreshold - time.Second))
+	if !h.Check(ctx) {
+		t.Error("Check() = false before the default threshold")
+	}
+	clk.SetTime(clk.Now().Add(time.Second))
+	if h.Check(ctx) {
+		t.Error("Check() = true after the default threshold")
+	}
+}
+
+func TestRelistableListerWatcher(t *testing.T) {
+	fw := watch.NewFake()
+	lw := NewRelistableListerWatcher(&cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return nil, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return fw, nil
+		},
+	})
+
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("Watch() =", err)
+	}
+
+	lw.ForceRelist()
+	if _, ok := <-w.ResultChan(); ok {
+		t.Error("ForceRelist() did not stop the active watch")
+	}
+
+	if _, err := lw.Watch(metav1.ListOptions{}); !apierrors.IsResourceExpired(err) {
+		t.Errorf("Watch() = %v, wanted an expired error", err)
+	}
+
+	// Only the first watch after ForceRelist fails.
+	if _, err := lw.Watch(metav1.ListOptions{}); err != nil {
+		t.Error("Watch() =", err)
+	}
+}
+
+func TestRelistTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") != "true" {
+			json.NewEncoder(w).Encode(&corev1.PodList{})
+			return
+		}
+		// Stream no events until the client goes away.
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	relister := &RelistTransport{}
+	cfg := &rest.Config{Host: server.URL}
+	cfg.Wrap(relister.Wrap)
+	client := kubernetes.NewForConfigOrDie(cfg).CoreV1()
+	pods := client.Pods("foo")
+	ctx := context.Background()
+
+	w, err := pods.Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("Watch() =", err)
+	}
+	t.Cleanup(w.Stop)
+	other, err := client.ConfigMaps("foo").Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("Watch() =", err)
+	}
+	t.Cleanup(other.Stop)
+	// Other requests are passed through.
+	if _, err := pods.List(ctx, metav1.ListOptions{}); err != nil {
+		t.Error("List() =", err)
+	}
+
+	relister.ForceRelist(corev1.SchemeGroupVersion.WithResource("pods"))
+	select {
+	case _, ok := <-w.ResultChan():
+		if ok {
+			t.Error("Got an event, wanted the watch stopped")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("ForceRelist() did not stop the active watch")
+	}
+	// The watches of the other resources are left alone.
+	relister.mu.Lock()
+	for w := range relister.watches {
+		if w.resource.Resource != "configmaps" {
+			t.Error("Active watch of", w.resource)
+		}
+	}
+	if len(relister.watches) != 1 {
+		t.Errorf("Got %d active watches, wanted 1", len(relister.watches))
+	}
+	relister.mu.Unlock()
+
+	// The API server reports the expired watches with an error event.
+	w, err = pods.Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("Watch() =", err)
+	}
+	if event := <-w.ResultChan(); event.Type != watch.Error || !apierrors.IsResourceExpired(apierrors.FromObject(event.Object)) {
+		t.Errorf("Got event %v, wanted an expired error", event)
+	}
+	w.Stop()
+
+	// Only the first watch after ForceRelist fails.
+	w, err = pods.Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("Watch() =", err)
+	}
+	w.Stop()
+}
+
+func TestRelistTransportReflector(t *testing.T) {
+	var lists atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") != "true" {
+			lists.Add(1)
+			json.NewEncoder(w).Encode(&corev1.PodList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PodList"},
+				ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+			})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	relister := &RelistTransport{}
+	cfg := &rest.Config{Host: server.URL}
+	cfg.Wrap(relister.Wrap)
+	client := kubernetes.NewForConfigOrDie(cfg).CoreV1().RESTClient()
+	lw := cache.NewListWatchFromClient(client, "pods", "foo", fields.Everything())
+	r := cache.NewReflector(lw, &corev1.Pod{}, cache.NewStore(cache.MetaNamespaceKeyFunc), 0)
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	go r.Run(stopCh)
+
+	waitForLists := func(want int32) {
+		t.Helper()
+		if err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+			return lists.Load() >= want, nil
+		}); err != nil {
+			t.Fatalf("Got %d lists, wanted %d", lists.Load(), want)
+		}
+	}
+	waitForLists(1)
+	// Let the reflector watch.
+	if err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		relister.mu.Lock()
+		defer relister.mu.Unlock()
+		return len(relister.watches) == 1, nil
+	}); err != nil {
+		t.Fatal("The reflector did not watch:", err)
+	}
+
+	relister.ForceRelist(corev1.SchemeGroupVersion.WithResource("pods"))
+	waitForLists(2)
+}
+
+func TestPathResource(t *testing.T) {
+	tests := []struct {
+		path string
+		want schema.GroupVersionResource
+		ok   bool
+	}{{
+		path: "/api/v1/pods",
+		want: schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		ok:   true,
+	}, {
+		path: "/api/v1/namespaces/foo/pods",
+		want: schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		ok:   true,
+	}, {
+		path: "/api/v1/namespaces",
+		want: schema.GroupVersionResource{Version: "v1", Resource: "namespaces"},
+		ok:   true,
+	}, {
+		path: "/apis/apps/v1/namespaces/foo/deployments",
+		want: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		ok:   true,
+	}, {
+		path: "/prefix/apis/serving.knative.dev/v1/services",
+		want: schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"},
+		ok:   true,
+	}, {
+		path: "/apis/apps/v1",
+	}, {
+		path: "/healthz",
+	}}
+
+	for _, test := range tests {
+		got, ok := pathResource(test.path)
+		if got != test.want || ok != test.ok {
+			t.Errorf("pathResource(%q) = %v, %v, wanted %v, %v", test.path, got, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// DefaultMemorySampleSize is the default number of objects of each
+	// informer cache whose size is measured to estimate the cache's.
+	DefaultMemorySampleSize = 20
+
+	// DefaultMemoryAccountingInterval is the default period between two
+	// reports of the informers' memory estimates.
+	DefaultMemoryAccountingInterval = time.Minute
+)
+
+// StoreInformer is the subset of cache.SharedInformer used to account for
+// the memory of an informer's cache.
+type StoreInformer interface {
+	GetStore() cache.Store
+}
+
+// InformerMemoryEstimate is the estimated memory held by an informer cache.
+type InformerMemoryEstimate struct {
+	// Name identifies the informer.
+	Name string `json:"name"`
+
+	// Objects is the number of objects in the cache.
+	Objects int `json:"objects"`
+
+	// AverageBytes is the average serialized size of the sampled objects.
+	AverageBytes int64 `json:"averageBytes"`
+
+	// Bytes is the estimated size of the cache, that is Objects times
+	// AverageBytes.
+	Bytes int64 `json:"bytes"`
+}
+
+// InformerMemoryAccountant estimates the memory held by the caches of
+// informers, from their object counts and the serialized sizes of sampled
+// objects, so that operators can tell which informer is responsible for the
+// memory growth of a controller, e.g. to filter what it watches. The
+// serialized sizes are a proxy which underestimates the decoded objects.
+type InformerMemoryAccountant struct {
+	// SampleSize is the number of objects sampled in each cache.
+	// Defaults to DefaultMemorySampleSize.
+	SampleSize int
+
+	// Interval is the period between two reports of the estimates as
+	// metrics. Defaults to DefaultMemoryAccountingInterval.
+	Interval time.Duration
+
+	mu        sync.Mutex
+	informers []accountedInformer
+}
+
+type accountedInformer struct {
+	name     string
+	informer StoreInformer
+}
+
+var _ http.Handler = (*InformerMemoryAccountant)(nil)
+
+// Add accounts for the given informer under the given name. When the name
+// is empty, the informer is named after its resource if it is a
+// ResourceInformer, e.g. one of injection, otherwise after the type of its
+// objects.
+func (a *InformerMemoryAccountant) Add(name string, informer StoreInformer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.informers = append(a.informers, accountedInformer{name: name, informer: informer})
+}
+
+// Estimates returns the estimates of the informers, largest first.
+func (a *InformerMemoryAccountant) Estimates() []InformerMemoryEstimate {
+	a.mu.Lock()
+	informers := append([]accountedInformer(nil), a.informers...)
+	a.mu.Unlock()
+
+	sampleSize := a.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = DefaultMemorySampleSize
+	}
+
+	estimates := make([]InformerMemoryEstimate, 0, len(informers))
+	names := make(map[string]int, len(informers))
+	for _, i := range informers {
+		objs := i.informer.GetStore().List()
+		name := i.name
+		if name == "" {
+			name = informerName(i.informer)
+		}
+		// Tell apart the informers of the same name, e.g. the filtered
+		// informers of a type.
+		if names[name]++; names[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, names[name])
+		}
+
+		e := InformerMemoryEstimate{
+			Name:         name,
+			Objects:      len(objs),
+			AverageBytes: averageSize(objs, sampleSize),
+		}
+		e.Bytes = e.AverageBytes * int64(e.Objects)
+		estimates = append(estimates, e)
+	}
+
+	sort.SliceStable(estimates, func(i, j int) bool {
+		return estimates[i].Bytes > estimates[j].Bytes
+	})
+	return estimates
+}
+
+// objectTypeName returns the name of the type of the given objects.
+func objectTypeName(objs []interface{}) string {
+	if len(objs) == 0 {
+		return "unknown"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", objs[0]), "*")
+}
+
+// averageSize returns the average serialized size of up to n objects
+// sampled evenly across objs.
+func averageSize(objs []interface{}, n int) int64 {
+	if len(objs) == 0 {
+		return 0
+	}
+	if n > len(objs) {
+		n = len(objs)
+	}
+	stride := len(objs) / n
+
+	var total, sampled int64
+	for i := 0; i < n; i++ {
+		b, err := json.Marshal(objs[i*stride])
+		if err != nil {
+			continue
+		}
+		total += int64(len(b))
+		sampled++
+	}
+	if sampled == 0 {
+		return 0
+	}
+	return total / sampled
+}
+
+// Run reports the estimates as metrics every Interval until the context is
+// cancelled.
+func (a *InformerMemoryAccountant) Run(ctx context.Context) {
+	interval := a.Interval
+	if interval <= 0 {
+		interval = DefaultMemoryAccountingInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		a.report(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *InformerMemoryAccountant) report(ctx context.Context) {
+	for _, e := range a.Estimates() {
+		if err := reportInformerMemory(e.Name, e.Objects, e.Bytes); err != nil {
+			logging.FromContext(ctx).Warnw("Failed to report informer memory",
+				zap.String("informer", e.Name), zap.Error(err))
+		}
+	}
+}
+
+// ServeHTTP serves the estimates as JSON, e.g. as a debug endpoint.
+func (a *InformerMemoryAccountant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(a.Estimates()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
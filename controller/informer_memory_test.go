@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+type fakeStoreInformer struct {
+	store cache.Store
+}
+
+func (f *fakeStoreInformer) GetStore() cache.Store {
+	return f.store
+}
+
+func newFakeStoreInformer(t *testing.T, objs ...interface{}) *fakeStoreInformer {
+	t.Helper()
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, obj := range objs {
+		if err := store.Add(obj); err != nil {
+			t.Fatal("Add() =", err)
+		}
+	}
+	return &fakeStoreInformer{store: store}
+}
+
+func jsonSize(t *testing.T, obj interface{}) int64 {
+	t.Helper()
+	b, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal("Marshal() =", err)
+	}
+	return int64(len(b))
+}
+
+func TestInformerMemoryAccountant(t *testing.T) {
+	big := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "big", Namespace: "ns"},
+		Data:       map[string]string{"data": strings.Repeat("x", 1000)},
+	}
+	var secrets []interface{}
+	for i := 0; i < 3; i++ {
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprint("secret-", i), Namespace: "ns"},
+		})
+	}
+
+	a := &InformerMemoryAccountant{}
+	a.Add("", newFakeStoreInformer(t, secrets...))
+	a.Add("", newFakeStoreInformer(t, big))
+	a.Add("", newFakeStoreInformer(t, secrets[0]))
+	a.Add("empty", newFakeStoreInformer(t))
+
+	secretSize := jsonSize(t, secrets[0])
+	want := []InformerMemoryEstimate{{
+		Name:         "v1.ConfigMap",
+		Objects:      1,
+		AverageBytes: jsonSize(t, big),
+		Bytes:        jsonSize(t, big),
+	}, {
+		Name:         "v1.Secret",
+		Objects:      3,
+		AverageBytes: secretSize,
+		Bytes:        3 * secretSize,
+	}, {
+		Name:         "v1.Secret#2",
+		Objects:      1,
+		AverageBytes: secretSize,
+		Bytes:        secretSize,
+	}, {
+		Name: "empty",
+	}}
+	if got := a.Estimates(); !cmp.Equal(got, want) {
+		t.Error("Estimates() (-want, +got):", cmp.Diff(want, got))
+	}
+
+	rr := httptest.NewRecorder()
+	a.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/informers", nil))
+	var served []InformerMemoryEstimate
+	if err := json.NewDecoder(rr.Body).Decode(&served); err != nil {
+		t.Fatal("Decode() =", err)
+	}
+	if !cmp.Equal(served, want) {
+		t.Error("ServeHTTP() (-want, +got):", cmp.Diff(want, served))
+	}
+
+	// The metrics checks expect a single row.
+	a = &InformerMemoryAccountant{}
+	a.Add("", newFakeStoreInformer(t, secrets...))
+	a.report(TestContextWithLogger(t))
+	metricstest.CheckLastValueData(t, "informer_cache_objects", map[string]string{"informer": "v1.Secret"}, 3)
+	metricstest.CheckLastValueData(t, "informer_cache_bytes", map[string]string{"informer": "v1.Secret"}, float64(3*secretSize))
+}
+
+func TestInformerMemoryAccountantResources(t *testing.T) {
+	newInformer := func(resource schema.GroupVersionResource) cache.SharedIndexInformer {
+		return WithResource(cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0, cache.Indexers{}), resource)
+	}
+
+	// The informers of injection are named after their resources, even when
+	// their caches are empty or hold objects of the same type.
+	a := &InformerMemoryAccountant{}
+	a.Add("", newInformer(schema.GroupVersionResource{Version: "v1", Resource: "pods"}))
+	a.Add("", newInformer(schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"}))
+	want := []InformerMemoryEstimate{{Name: "v1/pods"}, {Name: "serving.knative.dev/v1/services"}}
+	if got := a.Estimates(); !cmp.Equal(got, want) {
+		t.Error("Estimates() (-want, +got):", cmp.Diff(want, got))
+	}
+}
+
+func TestAverageSizeSamples(t *testing.T) {
+	objs := make([]interface{}, 10)
+	for i := range objs {
+		objs[i] = strings.Repeat("x", i)
+	}
+	// Sampling 5 of 10 objects takes every other one: sizes 2, 4, 6, 8 and
+	// 10 with the quotes.
+	if got, want := averageSize(objs, 5), int64(6); got != want {
+		t.Errorf("averageSize() = %d, wanted %d", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ResourceInformer is implemented by the informers which report the
+// resource they watch, e.g. those of injection, by which they are named in
+// the logs, metrics and debug endpoints about them.
+type ResourceInformer interface {
+	Resource() schema.GroupVersionResource
+}
+
+// WithResource returns the informer, reporting the resource it watches
+// through ResourceInformer.
+func WithResource(informer cache.SharedIndexInformer, resource schema.GroupVersionResource) cache.SharedIndexInformer {
+	return &resourceInformer{SharedIndexInformer: informer, resource: resource}
+}
+
+type resourceInformer struct {
+	cache.SharedIndexInformer
+	resource schema.GroupVersionResource
+}
+
+var _ ResourceInformer = (*resourceInformer)(nil)
+
+// Resource implements ResourceInformer.
+func (i *resourceInformer) Resource() schema.GroupVersionResource {
+	return i.resource
+}
+
+// resourceName returns the name of the resource, as group/version/resource,
+// e.g. apps/v1/deployments, or v1/pods for the core group.
+func resourceName(resource schema.GroupVersionResource) string {
+	return path.Join(resource.Group, resource.Version, resource.Resource)
+}
+
+// informerName returns the name of the resource of the informer if it is a
+// ResourceInformer, otherwise the name of the type of the objects of its
+// cache.
+func informerName(informer interface{}) string {
+	if ri, ok := informer.(ResourceInformer); ok {
+		return resourceName(ri.Resource())
+	}
+	if si, ok := informer.(StoreInformer); ok {
+		return objectTypeName(si.GetStore().List())
+	}
+	return "unknown"
+}
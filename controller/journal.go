@@ -0,0 +1,305 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// IntentState is the state of an Intent recorded in a Journal.
+type IntentState string
+
+const (
+	// IntentPending is the state of an intent whose side effect is being
+	// performed, or was interrupted, e.g. by a crash of the controller.
+	IntentPending IntentState = "Pending"
+
+	// IntentDone is the state of an intent whose side effect was performed.
+	IntentDone IntentState = "Done"
+)
+
+// Intent is the record of a side effect on an external system performed by
+// the reconcile of a key, identified by an ID unique for the key, e.g. the
+// name of the side effect and the generation of the resource.
+type Intent struct {
+	Key   types.NamespacedName `json:"key"`
+	ID    string               `json:"id"`
+	State IntentState          `json:"state"`
+	Time  time.Time            `json:"time"`
+}
+
+// JournalStore persists the intents of a Journal. The intents are
+// identified by their key and ID.
+type JournalStore interface {
+	// Load returns the intent of the key with the ID, nil if none.
+	Load(ctx context.Context, key types.NamespacedName, id string) (*Intent, error)
+
+	// Save creates or updates the intent.
+	Save(ctx context.Context, intent Intent) error
+
+	// Delete deletes the intent of the key with the ID, if any.
+	Delete(ctx context.Context, key types.NamespacedName, id string) error
+
+	// List returns all the intents.
+	List(ctx context.Context) ([]Intent, error)
+}
+
+// Journal records the intents of the reconcilers before they perform side
+// effects on external systems which are not idempotent, and their
+// completion after, so that the side effects are not performed again when
+// the controller restarts, e.g.:
+//
+//	err := controller.GetJournal(ctx).Do(ctx, key, "charge/"+generation,
+//	    func(ctx context.Context, interrupted bool) error {
+//	        if interrupted && payments.Exists(ctx, chargeID) {
+//	            return nil
+//	        }
+//	        return payments.Charge(ctx, chargeID)
+//	    })
+type Journal struct {
+	// Store persists the intents.
+	Store JournalStore
+
+	// Retention is how long the intents which are done are kept, after
+	// which the recovery of the journal forgets them. Zero keeps them
+	// until they are forgotten explicitly.
+	Retention time.Duration
+
+	now func() time.Time
+}
+
+// NewJournal creates a Journal persisting its intents in the store.
+func NewJournal(store JournalStore) *Journal {
+	return &Journal{Store: store, now: time.Now}
+}
+
+// Do performs the side effect of the key with the ID at most once: it is
+// skipped if it was already done, and otherwise the intent to perform it is
+// recorded before calling fn, and marked done once fn succeeded. When fn
+// fails or the controller crashes before the side effect is marked done,
+// the intent is left pending and the next call of fn is told the previous
+// attempt was interrupted, for it to check whether the side effect happened
+// before performing it again.
+func (j *Journal) Do(ctx context.Context, key types.NamespacedName, id string, fn func(ctx context.Context, interrupted bool) error) error {
+	intent, err := j.Store.Load(ctx, key, id)
+	if err != nil {
+		return fmt.Errorf("failed to load intent %q of %s: %w", id, key, err)
+	}
+	if intent != nil && intent.State == IntentDone {
+		return nil
+	}
+
+	interrupted := intent != nil
+	if !interrupted {
+		if err := j.Store.Save(ctx, Intent{Key: key, ID: id, State: IntentPending, Time: j.now()}); err != nil {
+			return fmt.Errorf("failed to record intent %q of %s: %w", id, key, err)
+		}
+	}
+	if err := fn(ctx, interrupted); err != nil {
+		return err
+	}
+	if err := j.Store.Save(ctx, Intent{Key: key, ID: id, State: IntentDone, Time: j.now()}); err != nil {
+		return fmt.Errorf("failed to complete intent %q of %s: %w", id, key, err)
+	}
+	return nil
+}
+
+// Forget deletes the intent of the key with the ID, e.g. once the resource
+// whose reconcile performed it is deleted.
+func (j *Journal) Forget(ctx context.Context, key types.NamespacedName, id string) error {
+	return j.Store.Delete(ctx, key, id)
+}
+
+// Pending returns the intents which are pending, whose side effects were
+// interrupted unless they are being performed.
+func (j *Journal) Pending(ctx context.Context) ([]Intent, error) {
+	intents, err := j.Store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending := intents[:0]
+	for _, intent := range intents {
+		if intent.State == IntentPending {
+			pending = append(pending, intent)
+		}
+	}
+	return pending, nil
+}
+
+// recoverJournal scans the journal of the controller at startup: the keys
+// with pending intents are enqueued, for their reconciles to resolve the
+// side effects which were interrupted, and the intents done before the
+// retention are forgotten.
+func (c *Impl) recoverJournal(ctx context.Context) error {
+	intents, err := c.Journal.Store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to scan the journal: %w", err)
+	}
+	for _, intent := range intents {
+		switch {
+		case intent.State == IntentPending:
+			c.logger.Infow("Recovering interrupted intent", zap.String("intent", intent.ID), zap.Any("key", intent.Key))
+			c.EnqueueKey(intent.Key)
+		case c.Journal.Retention > 0 && c.Journal.now().Sub(intent.Time) > c.Journal.Retention:
+			if err := c.Journal.Store.Delete(ctx, intent.Key, intent.ID); err != nil {
+				c.logger.Warnw("Failed to forget expired intent", zap.String("intent", intent.ID), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// journalKey is used for associating the Journal with a context.Context.
+type journalKey struct{}
+
+// WithJournal associates the Journal with the context.
+func WithJournal(ctx context.Context, j *Journal) context.Context {
+	return context.WithValue(ctx, journalKey{}, j)
+}
+
+// GetJournal returns the Journal of the controller reconciling, from the
+// context passed to its Reconciler, nil if it has none.
+func GetJournal(ctx context.Context) *Journal {
+	j, _ := ctx.Value(journalKey{}).(*Journal)
+	return j
+}
+
+// configMapJournalStore is a JournalStore keeping the intents in the data
+// of a ConfigMap, as JSON.
+type configMapJournalStore struct {
+	client corev1client.ConfigMapInterface
+	name   string
+}
+
+// NewConfigMapJournalStore returns a JournalStore keeping the intents in the
+// ConfigMap with the name, which is created as needed. As ConfigMaps are
+// limited in size, it suits controllers performing few side effects, whose
+// intents are forgotten or expire per the retention of their Journal.
+func NewConfigMapJournalStore(client corev1client.ConfigMapInterface, name string) JournalStore {
+	return &configMapJournalStore{client: client, name: name}
+}
+
+// intentDataKey returns the key of the intent in the data of the ConfigMap,
+// which only allows some characters.
+func intentDataKey(key types.NamespacedName, id string) string {
+	sum := sha256.Sum256([]byte(key.String() + "/" + id))
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the ConfigMap, and whether it exists.
+func (s *configMapJournalStore) get(ctx context.Context) (*corev1.ConfigMap, bool, error) {
+	cm, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: s.name}}, false, nil
+	}
+	return cm, err == nil, err
+}
+
+// update applies the mutation to the data of the ConfigMap, retrying on
+// conflicts.
+func (s *configMapJournalStore) update(ctx context.Context, mutate func(data map[string]string) error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, exists, err := s.get(ctx)
+		if err != nil {
+			return err
+		}
+		cm = cm.DeepCopy()
+		if cm.Data == nil {
+			cm.Data = make(map[string]string, 1)
+		}
+		if err := mutate(cm.Data); err != nil {
+			return err
+		}
+		if !exists {
+			_, err = s.client.Create(ctx, cm, metav1.CreateOptions{})
+			if apierrs.IsAlreadyExists(err) {
+				// Created concurrently, retry as a conflict.
+				return apierrs.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		_, err = s.client.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Load implements JournalStore.
+func (s *configMapJournalStore) Load(ctx context.Context, key types.NamespacedName, id string) (*Intent, error) {
+	cm, _, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := cm.Data[intentDataKey(key, id)]
+	if !ok {
+		return nil, nil
+	}
+	intent := &Intent{}
+	if err := json.Unmarshal([]byte(raw), intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// Save implements JournalStore.
+func (s *configMapJournalStore) Save(ctx context.Context, intent Intent) error {
+	raw, err := json.Marshal(intent)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, func(data map[string]string) error {
+		data[intentDataKey(intent.Key, intent.ID)] = string(raw)
+		return nil
+	})
+}
+
+// Delete implements JournalStore.
+func (s *configMapJournalStore) Delete(ctx context.Context, key types.NamespacedName, id string) error {
+	return s.update(ctx, func(data map[string]string) error {
+		delete(data, intentDataKey(key, id))
+		return nil
+	})
+}
+
+// List implements JournalStore.
+func (s *configMapJournalStore) List(ctx context.Context) ([]Intent, error) {
+	cm, _, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	intents := make([]Intent, 0, len(cm.Data))
+	for k, raw := range cm.Data {
+		var intent Intent
+		if err := json.Unmarshal([]byte(raw), &intent); err != nil {
+			return nil, fmt.Errorf("failed to parse intent %s: %w", k, err)
+		}
+		intents = append(intents, intent)
+	}
+	return intents, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func newTestJournal() *Journal {
+	client := fakekube.NewSimpleClientset()
+	return NewJournal(NewConfigMapJournalStore(client.CoreV1().ConfigMaps("system"), "journal"))
+}
+
+func TestJournalDo(t *testing.T) {
+	ctx := context.Background()
+	j := newTestJournal()
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+
+	var calls []bool
+	boom := errors.New("boom")
+	fail := func(_ context.Context, interrupted bool) error {
+		calls = append(calls, interrupted)
+		return boom
+	}
+	succeed := func(_ context.Context, interrupted bool) error {
+		calls = append(calls, interrupted)
+		return nil
+	}
+
+	// A failure leaves the intent pending.
+	if err := j.Do(ctx, key, "charge", fail); !errors.Is(err, boom) {
+		t.Fatalf("Do() = %v, wanted %v", err, boom)
+	}
+	pending, err := j.Pending(ctx)
+	if err != nil {
+		t.Fatal("Pending() =", err)
+	}
+	if len(pending) != 1 || pending[0].Key != key || pending[0].ID != "charge" {
+		t.Errorf("Pending() = %v, wanted the intent charge of %v", pending, key)
+	}
+
+	// The next attempt is told of the interruption, and completes the intent.
+	if err := j.Do(ctx, key, "charge", succeed); err != nil {
+		t.Fatal("Do() =", err)
+	}
+	// Once done, the side effect is not performed again.
+	if err := j.Do(ctx, key, "charge", succeed); err != nil {
+		t.Fatal("Do() =", err)
+	}
+	if want := []bool{false, true}; len(calls) != len(want) || calls[0] != want[0] || calls[1] != want[1] {
+		t.Errorf("Calls = %v, wanted %v", calls, want)
+	}
+	if pending, err := j.Pending(ctx); err != nil || len(pending) != 0 {
+		t.Errorf("Pending() = %v, %v, wanted none", pending, err)
+	}
+
+	// Forgotten, the intent is performed again.
+	if err := j.Forget(ctx, key, "charge"); err != nil {
+		t.Fatal("Forget() =", err)
+	}
+	if err := j.Do(ctx, key, "charge", succeed); err != nil {
+		t.Fatal("Do() =", err)
+	}
+	if len(calls) != 3 || calls[2] {
+		t.Errorf("Calls = %v, wanted a third uninterrupted call", calls)
+	}
+}
+
+func TestJournalRecovery(t *testing.T) {
+	ctx := context.Background()
+	j := newTestJournal()
+	j.Retention = time.Hour
+	now := time.Now()
+
+	interrupted := types.NamespacedName{Namespace: "foo", Name: "interrupted"}
+	expired := types.NamespacedName{Namespace: "foo", Name: "expired"}
+	recent := types.NamespacedName{Namespace: "foo", Name: "recent"}
+	for _, intent := range []Intent{
+		{Key: interrupted, ID: "a", State: IntentPending, Time: now.Add(-2 * time.Hour)},
+		{Key: expired, ID: "a", State: IntentDone, Time: now.Add(-2 * time.Hour)},
+		{Key: recent, ID: "a", State: IntentDone, Time: now},
+	} {
+		if err := j.Store.Save(ctx, intent); err != nil {
+			t.Fatal("Save() =", err)
+		}
+	}
+
+	impl := NewContext(context.TODO(), &nopReconciler{}, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Journal",
+		Reporter:      &FakeStatsReporter{},
+		Journal:       j,
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+
+	if err := impl.recoverJournal(ctx); err != nil {
+		t.Fatal("recoverJournal() =", err)
+	}
+	if got := impl.WorkQueue().Len(); got != 1 {
+		t.Fatalf("Queue length = %d, wanted 1", got)
+	}
+	if got, _ := impl.WorkQueue().Get(); got != interrupted {
+		t.Errorf("Enqueued key = %v, wanted %v", got, interrupted)
+	}
+
+	intents, err := j.Store.List(ctx)
+	if err != nil {
+		t.Fatal("List() =", err)
+	}
+	if len(intents) != 2 {
+		t.Errorf("Intents = %v, wanted the expired one forgotten", intents)
+	}
+	for _, intent := range intents {
+		if intent.Key == expired {
+			t.Errorf("Intent %v was not forgotten", intent)
+		}
+	}
+}
+
+func TestGetJournal(t *testing.T) {
+	ctx := context.Background()
+	if j := GetJournal(ctx); j != nil {
+		t.Errorf("GetJournal() = %v, wanted nil", j)
+	}
+	j := newTestJournal()
+	if got := GetJournal(WithJournal(ctx, j)); got != j {
+		t.Errorf("GetJournal() = %v, wanted %v", got, j)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// KeyRateLimiterFunc returns the rate limiter of the retries of a key, e.g.
+// one per namespace so that the failures of a namespace do not delay the
+// retries of the others, or nil for the key to be rate limited by the
+// RateLimiter of the ControllerOptions. It must return the same rate limiter
+// for a key every time.
+type KeyRateLimiterFunc func(key types.NamespacedName) workqueue.RateLimiter
+
+// NewNamespaceRateLimiters returns a KeyRateLimiterFunc giving the keys of
+// each namespace their own rate limiter, created by newRateLimiter, e.g.
+// workqueue.DefaultControllerRateLimiter, on first use.
+func NewNamespaceRateLimiters(newRateLimiter func() workqueue.RateLimiter) KeyRateLimiterFunc {
+	var (
+		mu       sync.Mutex
+		limiters = make(map[string]workqueue.RateLimiter)
+	)
+	return func(key types.NamespacedName) workqueue.RateLimiter {
+		mu.Lock()
+		defer mu.Unlock()
+		rl, ok := limiters[key.Namespace]
+		if !ok {
+			rl = newRateLimiter()
+			limiters[key.Namespace] = rl
+		}
+		return rl
+	}
+}
+
+// keyRateLimiter is a workqueue.RateLimiter delegating to the rate limiter
+// of each key.
+type keyRateLimiter struct {
+	fallback workqueue.RateLimiter
+	forKey   KeyRateLimiterFunc
+}
+
+var _ workqueue.RateLimiter = (*keyRateLimiter)(nil)
+
+func (r *keyRateLimiter) rateLimiter(item interface{}) workqueue.RateLimiter {
+	if key, ok := item.(types.NamespacedName); ok {
+		if rl := r.forKey(key); rl != nil {
+			return rl
+		}
+	}
+	return r.fallback
+}
+
+// When implements workqueue.RateLimiter.
+func (r *keyRateLimiter) When(item interface{}) time.Duration {
+	return r.rateLimiter(item).When(item)
+}
+
+// Forget implements workqueue.RateLimiter.
+func (r *keyRateLimiter) Forget(item interface{}) {
+	r.rateLimiter(item).Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimiter.
+func (r *keyRateLimiter) NumRequeues(item interface{}) int {
+	return r.rateLimiter(item).NumRequeues(item)
+}
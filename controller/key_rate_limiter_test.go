@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestNamespaceRateLimiters(t *testing.T) {
+	created := 0
+	forKey := NewNamespaceRateLimiters(func() workqueue.RateLimiter {
+		created++
+		return workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Second)
+	})
+
+	foo1 := forKey(types.NamespacedName{Namespace: "foo", Name: "one"})
+	foo2 := forKey(types.NamespacedName{Namespace: "foo", Name: "two"})
+	bar := forKey(types.NamespacedName{Namespace: "bar", Name: "one"})
+	if foo1 != foo2 {
+		t.Error("The keys of a namespace got different rate limiters")
+	}
+	if foo1 == bar {
+		t.Error("The keys of different namespaces got the same rate limiter")
+	}
+	if created != 2 {
+		t.Errorf("Created %d rate limiters, wanted 2", created)
+	}
+}
+
+func TestKeyRateLimiter(t *testing.T) {
+	noisyLimiter, defaultLimiter := newSlowRateLimiter(), newSlowRateLimiter()
+	noisy := types.NamespacedName{Namespace: "noisy", Name: "neighbour"}
+	quiet := types.NamespacedName{Namespace: "quiet", Name: "neighbour"}
+
+	impl := newErrorHandlingImpl(t, ControllerOptions{
+		RateLimiter: defaultLimiter,
+		KeyRateLimiter: func(key types.NamespacedName) workqueue.RateLimiter {
+			if key.Namespace == noisy.Namespace {
+				return noisyLimiter
+			}
+			return nil
+		},
+	})
+
+	logger := TestLogger(t)
+	for i := 0; i < 3; i++ {
+		impl.handleErr(logger, errors.New("boom"), noisy, time.Now())
+	}
+	impl.handleErr(logger, errors.New("boom"), quiet, time.Now())
+
+	if got, want := noisyLimiter.NumRequeues(noisy), 3; got != want {
+		t.Errorf("noisy requeues = %d, wanted %d", got, want)
+	}
+	if got := defaultLimiter.NumRequeues(noisy); got != 0 {
+		t.Errorf("noisy requeues of the default rate limiter = %d, wanted 0", got)
+	}
+	if got, want := defaultLimiter.NumRequeues(quiet), 1; got != want {
+		t.Errorf("quiet requeues = %d, wanted %d", got, want)
+	}
+
+	impl.handleErr(logger, NewPermanentError(errors.New("boom")), noisy, time.Now())
+	if got := noisyLimiter.NumRequeues(noisy); got != 0 {
+		t.Errorf("noisy requeues after a permanent error = %d, wanted 0", got)
+	}
+}
@@ -36,6 +36,17 @@ type Options struct {
 	// updates (default) or skip them if this is set to true.
 	SkipStatusUpdates bool
 
+	// StatusFieldManager configures this reconciler to update the status with
+	// server-side apply, as the field manager of this name, so that the status
+	// fields owned by other controllers are not clobbered. By default the
+	// whole status is updated. It is not supported by generic.NewTyped.
+	StatusFieldManager string
+
+	// StatusConflictStrategy configures how this reconciler resolves the
+	// conflicts of its status updates. By default the status of the
+	// reconciliation is written over the latest one.
+	StatusConflictStrategy reconciler.StatusConflictStrategy
+
 	// DemoteFunc configures the demote function this reconciler uses
 	DemoteFunc func(b reconciler.Bucket)
 
@@ -50,6 +61,20 @@ type Options struct {
 	// PromoteFunc is called when a reconciler is promoted for the given bucket
 	// The provided function must not block execution.
 	PromoteFunc func(bkt reconciler.Bucket)
+
+	// ReadinessGates are the external dependencies which must be ready
+	// before the resources are reconciled. While one is not, the resources
+	// are requeued instead.
+	ReadinessGates []reconciler.ReadinessGate
+
+	// ReconcileHooks are run around every call of the methods of the
+	// reconciler, see reconciler.RunReconcileHooks.
+	ReconcileHooks []reconciler.ReconcileHook
+
+	// ObservationStore is attached to the context of the calls of the
+	// methods of the reconciler, for the observers to publish their
+	// observations of the resources and the leaders to list them.
+	ObservationStore reconciler.ObservationStore
 }
 
 // OptionsFn is a callback method signature that accepts an Impl and returns
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PausedControllersKey is the key of the ConfigMaps watched with
+// UpdatePausedFromConfigMap listing the names of the controllers to pause,
+// separated by commas or whitespace, or * for all of them.
+const PausedControllersKey = "paused"
+
+// pauser holds the workers of a controller while it is paused.
+type pauser struct {
+	mu sync.Mutex
+	// resumed is closed when the controller is resumed, nil while it is
+	// running.
+	resumed chan struct{}
+}
+
+func (p *pauser) pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resumed != nil {
+		return false
+	}
+	p.resumed = make(chan struct{})
+	return true
+}
+
+func (p *pauser) resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resumed == nil {
+		return false
+	}
+	close(p.resumed)
+	p.resumed = nil
+	return true
+}
+
+// wait blocks while the controller is paused, or until the context is done.
+func (p *pauser) wait(ctx context.Context) {
+	p.mu.Lock()
+	resumed := p.resumed
+	p.mu.Unlock()
+	if resumed == nil {
+		return
+	}
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+	}
+}
+
+func (p *pauser) paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.resumed != nil
+}
+
+// Pause stops the workers of the controller from getting keys off the work
+// queue, e.g. for maintenance, once they are done with the key they are
+// reconciling or waiting for. The keys are still enqueued, and the informers
+// keep their caches warm, so that the controller catches up when resumed. A
+// paused controller still drains its work queue when it stops.
+func (c *Impl) Pause() {
+	if c.pauser.pause() {
+		c.logger.Info("Pausing controller")
+	}
+}
+
+// Resume resumes the workers of the controller after Pause.
+func (c *Impl) Resume() {
+	if c.pauser.resume() {
+		c.logger.Info("Resuming controller")
+	}
+}
+
+// IsPaused returns whether the controller is paused.
+func (c *Impl) IsPaused() bool {
+	return c.pauser.paused()
+}
+
+// UpdatePausedFromConfigMap returns a helper func that can be used to pause
+// and resume the controllers by their name per the PausedControllersKey of
+// a ConfigMap. The controllers missing from it are resumed.
+func UpdatePausedFromConfigMap(controllers ...*Impl) func(configMap *corev1.ConfigMap) {
+	return func(configMap *corev1.ConfigMap) {
+		paused := make(map[string]struct{})
+		for _, name := range strings.FieldsFunc(configMap.Data[PausedControllersKey], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\n'
+		}) {
+			paused[name] = struct{}{}
+		}
+		_, all := paused["*"]
+		for _, c := range controllers {
+			if _, ok := paused[c.Name]; ok || all {
+				c.Pause()
+			} else {
+				c.Resume()
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestPauseResume(t *testing.T) {
+	r := &CountingReconciler{}
+	impl := NewContext(context.TODO(), r, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Paused",
+		Reporter:      &FakeStatsReporter{},
+	})
+
+	impl.Pause()
+	if !impl.IsPaused() {
+		t.Error("IsPaused() = false after Pause()")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		impl.RunContext(ctx, 2)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-doneCh
+	})
+
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+	time.Sleep(50 * time.Millisecond)
+	if got := r.count.Load(); got != 0 {
+		t.Errorf("count while paused = %d, wanted 0", got)
+	}
+
+	impl.Resume()
+	if impl.IsPaused() {
+		t.Error("IsPaused() = true after Resume()")
+	}
+	if err := wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return r.count.Load() == 1, nil
+	}); err != nil {
+		t.Fatal("The key was never reconciled after Resume():", err)
+	}
+
+	// A paused controller drains its queue when it stops.
+	impl.Pause()
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "baz"})
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "qux"})
+	cancel()
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the paused controller to stop.")
+	case <-doneCh:
+	}
+	if got, want := r.count.Load(), int32(3); got != want {
+		t.Errorf("count = %d, wanted %d", got, want)
+	}
+}
+
+func TestUpdatePausedFromConfigMap(t *testing.T) {
+	newImpl := func(name string) *Impl {
+		impl := NewContext(context.TODO(), &nopReconciler{}, ControllerOptions{
+			Logger:        TestLogger(t),
+			WorkQueueName: name,
+			Reporter:      &FakeStatsReporter{},
+		})
+		t.Cleanup(impl.WorkQueue().ShutDown)
+		return impl
+	}
+	a, b, c := newImpl("a"), newImpl("b"), newImpl("c")
+	update := UpdatePausedFromConfigMap(a, b, c)
+
+	tests := []struct {
+		name   string
+		paused string
+		want   []bool
+	}{{
+		name:   "some",
+		paused: "a, c",
+		want:   []bool{true, false, true},
+	}, {
+		name:   "others",
+		paused: "b\n",
+		want:   []bool{false, true, false},
+	}, {
+		name:   "all",
+		paused: "*",
+		want:   []bool{true, true, true},
+	}, {
+		name: "none",
+		want: []bool{false, false, false},
+	}}
+
+	for _, tc := range tests {
+		update(&corev1.ConfigMap{Data: map[string]string{PausedControllersKey: tc.paused}})
+		for i, impl := range []*Impl{a, b, c} {
+			if got := impl.IsPaused(); got != tc.want[i] {
+				t.Errorf("%s: %s.IsPaused() = %v, wanted %v", tc.name, impl.Name, got, tc.want[i])
+			}
+		}
+	}
+}
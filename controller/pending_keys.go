@@ -0,0 +1,331 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// pendingKeysSaveTimeout bounds the saving of the pending keys at shutdown,
+// whose context is already cancelled.
+const pendingKeysSaveTimeout = 10 * time.Second
+
+// PendingKeyStore persists the keys queued but not yet reconciled by a
+// controller when it shuts down, for them to be enqueued again when it
+// starts, instead of waiting for a resync. The keys given up on, i.e. failing
+// permanently, dead-lettered or parked, are not persisted.
+type PendingKeyStore interface {
+	// Save replaces the persisted keys.
+	Save(ctx context.Context, keys []types.NamespacedName) error
+
+	// Load returns the persisted keys.
+	Load(ctx context.Context) ([]types.NamespacedName, error)
+}
+
+// pendingKeys is the set of the keys in a work queue, including those whose
+// addition is delayed and those being processed. Each key is tagged with the
+// sequence number of its last addition, so that a key added again while it is
+// processed stays pending once it is dropped.
+type pendingKeys struct {
+	mu   sync.Mutex
+	seq  uint64
+	keys map[types.NamespacedName]uint64
+	// inFlight has the sequence numbers of the keys being processed, as of
+	// when they were taken off the queue.
+	inFlight map[types.NamespacedName]uint64
+}
+
+func (pk *pendingKeys) add(item interface{}) {
+	key, ok := item.(types.NamespacedName)
+	if !ok {
+		return
+	}
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+	if pk.keys == nil {
+		pk.keys = make(map[types.NamespacedName]uint64)
+	}
+	pk.seq++
+	pk.keys[key] = pk.seq
+}
+
+// start records that the key is taken off the queue.
+func (pk *pendingKeys) start(key types.NamespacedName) {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+	if pk.inFlight == nil {
+		pk.inFlight = make(map[types.NamespacedName]uint64)
+	}
+	pk.inFlight[key] = pk.keys[key]
+}
+
+// drop removes the key, unless it was added again since it was taken off
+// the queue.
+func (pk *pendingKeys) drop(key types.NamespacedName) {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+	if s, ok := pk.inFlight[key]; ok && pk.keys[key] != s {
+		return
+	}
+	delete(pk.keys, key)
+}
+
+// remove removes the key, even if it was added again.
+func (pk *pendingKeys) remove(key types.NamespacedName) {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+	delete(pk.keys, key)
+}
+
+// done records that the key is done being processed.
+func (pk *pendingKeys) done(item interface{}) {
+	key, ok := item.(types.NamespacedName)
+	if !ok {
+		return
+	}
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+	delete(pk.inFlight, key)
+}
+
+// list returns the keys, sorted.
+func (pk *pendingKeys) list() []types.NamespacedName {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+	keys := make([]types.NamespacedName, 0, len(pk.keys))
+	for key := range pk.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
+
+// pendingLane tracks the keys added to a lane of a work queue.
+type pendingLane struct {
+	workqueue.RateLimitingInterface
+	pending *pendingKeys
+}
+
+// Add implements workqueue.Interface.
+func (pl *pendingLane) Add(item interface{}) {
+	pl.pending.add(item)
+	pl.RateLimitingInterface.Add(item)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (pl *pendingLane) AddAfter(item interface{}, duration time.Duration) {
+	pl.pending.add(item)
+	pl.RateLimitingInterface.AddAfter(item, duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (pl *pendingLane) AddRateLimited(item interface{}) {
+	pl.pending.add(item)
+	pl.RateLimitingInterface.AddRateLimited(item)
+}
+
+// pendingQueue tracks the keys added to a controllerQueue, through any of
+// its lanes. The keys stay pending while they are processed, until they are
+// reconciled or dropped, see dropPending.
+type pendingQueue struct {
+	controllerQueue
+	pending *pendingKeys
+}
+
+var _ controllerQueue = (*pendingQueue)(nil)
+
+// Add implements workqueue.Interface.
+func (pq *pendingQueue) Add(item interface{}) {
+	pq.pending.add(item)
+	pq.controllerQueue.Add(item)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (pq *pendingQueue) AddAfter(item interface{}, duration time.Duration) {
+	pq.pending.add(item)
+	pq.controllerQueue.AddAfter(item, duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (pq *pendingQueue) AddRateLimited(item interface{}) {
+	pq.pending.add(item)
+	pq.controllerQueue.AddRateLimited(item)
+}
+
+// Done implements workqueue.Interface.
+func (pq *pendingQueue) Done(item interface{}) {
+	pq.pending.done(item)
+	pq.controllerQueue.Done(item)
+}
+
+// SlowLane implements controllerQueue.
+func (pq *pendingQueue) SlowLane() workqueue.RateLimitingInterface {
+	return &pendingLane{RateLimitingInterface: pq.controllerQueue.SlowLane(), pending: pq.pending}
+}
+
+// HighLane implements controllerQueue.
+func (pq *pendingQueue) HighLane() workqueue.RateLimitingInterface {
+	return &pendingLane{RateLimitingInterface: pq.controllerQueue.HighLane(), pending: pq.pending}
+}
+
+// processingPending records that the key is taken off the queue, if the
+// pending keys are tracked.
+func (c *Impl) processingPending(key types.NamespacedName) {
+	if c.pending != nil {
+		c.pending.start(key)
+	}
+}
+
+// dropPending removes the key processed from the pending keys, as it is
+// reconciled, or given up on, and not requeued. It stays pending if it was
+// added again meanwhile.
+func (c *Impl) dropPending(key types.NamespacedName) {
+	if c.pending != nil {
+		c.pending.drop(key)
+	}
+}
+
+// savePendingKeys persists the keys pending when the controller shuts down.
+func (c *Impl) savePendingKeys() {
+	if c.pending == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pendingKeysSaveTimeout)
+	defer cancel()
+	keys := c.pending.list()
+	if err := c.pendingKeyStore.Save(ctx, keys); err != nil {
+		c.logger.Errorw("Failed to save the pending keys", zap.Int("keys", len(keys)), zap.Error(err))
+		return
+	}
+	c.logger.Infof("Saved %d pending keys", len(keys))
+}
+
+// recoverPendingKeys enqueues the keys pending when the controller last shut
+// down, and clears them from the store.
+func (c *Impl) recoverPendingKeys(ctx context.Context) error {
+	keys, err := c.pendingKeyStore.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load the pending keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	c.logger.Infof("Recovering %d pending keys", len(keys))
+	for _, key := range keys {
+		c.EnqueueKey(key)
+	}
+	if err := c.pendingKeyStore.Save(ctx, nil); err != nil {
+		return fmt.Errorf("failed to clear the pending keys: %w", err)
+	}
+	return nil
+}
+
+// pendingKeysDataKey is the key of the pending keys in the data of the
+// ConfigMap of a configMapPendingKeyStore.
+const pendingKeysDataKey = "keys"
+
+// configMapPendingKeyStore is a PendingKeyStore keeping the keys in the data
+// of a ConfigMap, one namespace/name per line.
+type configMapPendingKeyStore struct {
+	client corev1client.ConfigMapInterface
+	name   string
+}
+
+// NewConfigMapPendingKeyStore returns a PendingKeyStore keeping the keys in
+// the ConfigMap with the name, which is created as needed. As the keys are
+// saved by the replica shutting down and recovered by the replica starting,
+// the replicas of a controller sharing the work with leader election
+// should each use their own ConfigMap, e.g. named after their pod in a
+// StatefulSet.
+func NewConfigMapPendingKeyStore(client corev1client.ConfigMapInterface, name string) PendingKeyStore {
+	return &configMapPendingKeyStore{client: client, name: name}
+}
+
+// Save implements PendingKeyStore.
+func (s *configMapPendingKeyStore) Save(ctx context.Context, keys []types.NamespacedName) error {
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key.String()
+	}
+	data := strings.Join(lines, "\n")
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			if len(keys) == 0 {
+				return nil
+			}
+			_, err = s.client.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name},
+				Data:       map[string]string{pendingKeysDataKey: data},
+			}, metav1.CreateOptions{})
+			if apierrs.IsAlreadyExists(err) {
+				// Created concurrently, retry as a conflict.
+				return apierrs.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		} else if err != nil {
+			return err
+		}
+		cm = cm.DeepCopy()
+		if cm.Data == nil {
+			cm.Data = make(map[string]string, 1)
+		}
+		cm.Data[pendingKeysDataKey] = data
+		_, err = s.client.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Load implements PendingKeyStore.
+func (s *configMapPendingKeyStore) Load(ctx context.Context) ([]types.NamespacedName, error) {
+	cm, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var keys []types.NamespacedName
+	for _, line := range strings.Split(cm.Data[pendingKeysDataKey], "\n") {
+		if line == "" {
+			continue
+		}
+		// Save writes the keys as namespace/name, or /name for the
+		// cluster-scoped resources: a line without a separator, e.g. edited
+		// by hand, is taken as the name of a cluster-scoped resource.
+		namespace, name, ok := strings.Cut(line, string(types.Separator))
+		if !ok {
+			namespace, name = "", line
+		}
+		keys = append(keys, types.NamespacedName{Namespace: namespace, Name: name})
+	}
+	return keys, nil
+}
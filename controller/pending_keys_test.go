@@ -0,0 +1,282 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/types"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func newTestPendingKeyStore() PendingKeyStore {
+	client := fakekube.NewSimpleClientset()
+	return NewConfigMapPendingKeyStore(client.CoreV1().ConfigMaps("system"), "pending")
+}
+
+func TestConfigMapPendingKeyStore(t *testing.T) {
+	ctx := context.Background()
+	store := newTestPendingKeyStore()
+
+	if keys, err := store.Load(ctx); err != nil || len(keys) != 0 {
+		t.Fatalf("Load() = %v, %v, wanted no keys", keys, err)
+	}
+	// Clearing the keys does not create the ConfigMap.
+	if err := store.Save(ctx, nil); err != nil {
+		t.Fatal("Save() =", err)
+	}
+
+	want := []types.NamespacedName{{Namespace: "foo", Name: "bar"}, {Name: "cluster-scoped"}}
+	if err := store.Save(ctx, want); err != nil {
+		t.Fatal("Save() =", err)
+	}
+	got, err := store.Load(ctx)
+	if err != nil {
+		t.Fatal("Load() =", err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Error("Load() (-want, +got) =", cmp.Diff(want, got))
+	}
+
+	if err := store.Save(ctx, nil); err != nil {
+		t.Fatal("Save() =", err)
+	}
+	if keys, err := store.Load(ctx); err != nil || len(keys) != 0 {
+		t.Errorf("Load() = %v, %v, wanted no keys", keys, err)
+	}
+}
+
+// keyRecordingReconciler sends the keys it reconciles.
+type keyRecordingReconciler struct {
+	keys chan string
+}
+
+func (r *keyRecordingReconciler) Reconcile(_ context.Context, key string) error {
+	r.keys <- key
+	return nil
+}
+
+func TestPendingKeysSurviveRestart(t *testing.T) {
+	store := newTestPendingKeyStore()
+	delayed := types.NamespacedName{Namespace: "foo", Name: "delayed"}
+	processed := types.NamespacedName{Namespace: "foo", Name: "processed"}
+
+	run := func(impl *Impl) (context.CancelFunc, chan struct{}) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			impl.RunContext(ctx, 1)
+		}()
+		return cancel, done
+	}
+	newImpl := func(r Reconciler) *Impl {
+		return NewContext(context.TODO(), r, ControllerOptions{
+			Logger:        TestLogger(t),
+			WorkQueueName: "PendingKeys",
+			Reporter:      &FakeStatsReporter{},
+			PendingKeys:   store,
+		})
+	}
+	wait := func(r *keyRecordingReconciler, want types.NamespacedName) {
+		t.Helper()
+		select {
+		case got := <-r.keys:
+			if got != want.String() {
+				t.Fatalf("Reconciled %s, wanted %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s to be reconciled", want)
+		}
+	}
+
+	// The first controller shuts down with a delayed key pending.
+	first := &keyRecordingReconciler{keys: make(chan string, 10)}
+	impl := newImpl(first)
+	impl.EnqueueKeyAfter(delayed, time.Hour)
+	cancel, done := run(impl)
+	impl.EnqueueKey(processed)
+	wait(first, processed)
+	cancel()
+	<-done
+
+	keys, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatal("Load() =", err)
+	}
+	if want := []types.NamespacedName{delayed}; !cmp.Equal(keys, want) {
+		t.Error("Saved keys (-want, +got) =", cmp.Diff(want, keys))
+	}
+
+	// The next controller reconciles it right away.
+	second := &keyRecordingReconciler{keys: make(chan string, 10)}
+	impl = newImpl(second)
+	cancel, done = run(impl)
+	wait(second, delayed)
+	cancel()
+	<-done
+
+	// Once recovered, the keys are cleared, and none is pending.
+	if keys, err := store.Load(context.Background()); err != nil || len(keys) != 0 {
+		t.Errorf("Load() = %v, %v, wanted no keys", keys, err)
+	}
+}
+
+// blockingReconciler signals the keys it starts to reconcile, and returns
+// the error it is then sent.
+type blockingReconciler struct {
+	started chan string
+	errs    chan error
+}
+
+func (r *blockingReconciler) Reconcile(_ context.Context, key string) error {
+	r.started <- key
+	return <-r.errs
+}
+
+func TestPendingKeysInFlightAtShutdown(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []types.NamespacedName
+	}{{
+		name: "failed",
+		err:  errors.New("transient"),
+		want: []types.NamespacedName{{Namespace: "foo", Name: "bar"}},
+	}, {
+		name: "permanent",
+		err:  NewPermanentError(errors.New("permanent")),
+		want: []types.NamespacedName{},
+	}, {
+		name: "succeeded",
+		want: []types.NamespacedName{},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newTestPendingKeyStore()
+			r := &blockingReconciler{started: make(chan string, 1), errs: make(chan error, 1)}
+			impl := NewContext(context.TODO(), r, ControllerOptions{
+				Logger:        TestLogger(t),
+				WorkQueueName: "PendingKeys",
+				Reporter:      &FakeStatsReporter{},
+				PendingKeys:   store,
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				impl.RunContext(ctx, 1)
+			}()
+
+			impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+			<-r.started
+			// The controller shuts down while the key is reconciled.
+			cancel()
+			r.errs <- test.err
+			<-done
+
+			keys, err := store.Load(context.Background())
+			if err != nil {
+				t.Fatal("Load() =", err)
+			}
+			if !cmp.Equal(keys, test.want, cmpopts.EquateEmpty()) {
+				t.Error("Saved keys (-want, +got) =", cmp.Diff(test.want, keys))
+			}
+		})
+	}
+}
+
+func TestPendingKeysAddedWhileProcessing(t *testing.T) {
+	pk := &pendingKeys{}
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+
+	pk.add(key)
+	pk.start(key)
+	pk.add(key)
+	pk.drop(key)
+	pk.done(key)
+	if got, want := pk.list(), []types.NamespacedName{key}; !cmp.Equal(got, want) {
+		t.Error("Pending keys (-want, +got) =", cmp.Diff(want, got))
+	}
+
+	pk.start(key)
+	pk.drop(key)
+	pk.done(key)
+	if got := pk.list(); len(got) != 0 {
+		t.Error("Pending keys =", got)
+	}
+}
+
+func TestPendingKeysGivenUp(t *testing.T) {
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	transient := errors.New("transient")
+
+	tests := []struct {
+		name     string
+		opts     ControllerOptions
+		err      error
+		failures int
+		pending  bool
+	}{{
+		name:     "requeued",
+		err:      transient,
+		failures: 2,
+		pending:  true,
+	}, {
+		name:     "permanent",
+		err:      NewPermanentError(transient),
+		failures: 1,
+	}, {
+		name:     "dead-lettered",
+		opts:     ControllerOptions{MaxRetries: 1},
+		err:      transient,
+		failures: 2,
+	}, {
+		name:     "parked",
+		opts:     ControllerOptions{CircuitBreaker: &CircuitBreaker{Threshold: 1, CoolDown: time.Hour}},
+		err:      transient,
+		failures: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.PendingKeys = newTestPendingKeyStore()
+			test.opts.RateLimiter = newSlowRateLimiter()
+			impl := newErrorHandlingImpl(t, test.opts)
+
+			impl.EnqueueKey(key)
+			for i := 0; i < test.failures; i++ {
+				impl.processingPending(key)
+				impl.handleErr(impl.logger, test.err, key, time.Now())
+				impl.workQueue.Done(key)
+			}
+
+			if got := len(impl.pending.list()) == 1; got != test.pending {
+				t.Errorf("Pending = %v, wanted %v", got, test.pending)
+			}
+		})
+	}
+}
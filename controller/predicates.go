@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Predicate decides which events of an informer are handled, before their
+// objects are enqueued. Unlike the filters of a
+// cache.FilteringResourceEventHandler, it sees both the old and the new
+// objects of the updates, e.g. to skip the updates of their status only.
+// A nil func lets all the events of its type through.
+type Predicate struct {
+	Create func(obj interface{}) bool
+	Update func(oldObj, newObj interface{}) bool
+	Delete func(obj interface{}) bool
+}
+
+func (p Predicate) create(obj interface{}) bool {
+	return p.Create == nil || p.Create(obj)
+}
+
+func (p Predicate) update(oldObj, newObj interface{}) bool {
+	return p.Update == nil || p.Update(oldObj, newObj)
+}
+
+func (p Predicate) delete(obj interface{}) bool {
+	return p.Delete == nil || p.Delete(obj)
+}
+
+// PredicateFunc returns the Predicate applying a filter, e.g. FilterController,
+// to the objects of all the events, the new ones of the updates.
+func PredicateFunc(f func(obj interface{}) bool) Predicate {
+	return Predicate{
+		Create: f,
+		Update: func(_, newObj interface{}) bool { return f(newObj) },
+		Delete: f,
+	}
+}
+
+// And returns the Predicate letting through the events all the predicates
+// let through.
+func And(predicates ...Predicate) Predicate {
+	return Predicate{
+		Create: func(obj interface{}) bool {
+			for _, p := range predicates {
+				if !p.create(obj) {
+					return false
+				}
+			}
+			return true
+		},
+		Update: func(oldObj, newObj interface{}) bool {
+			for _, p := range predicates {
+				if !p.update(oldObj, newObj) {
+					return false
+				}
+			}
+			return true
+		},
+		Delete: func(obj interface{}) bool {
+			for _, p := range predicates {
+				if !p.delete(obj) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// Or returns the Predicate letting through the events any of the predicates
+// lets through.
+func Or(predicates ...Predicate) Predicate {
+	return Predicate{
+		Create: func(obj interface{}) bool {
+			for _, p := range predicates {
+				if p.create(obj) {
+					return true
+				}
+			}
+			return false
+		},
+		Update: func(oldObj, newObj interface{}) bool {
+			for _, p := range predicates {
+				if p.update(oldObj, newObj) {
+					return true
+				}
+			}
+			return false
+		},
+		Delete: func(obj interface{}) bool {
+			for _, p := range predicates {
+				if p.delete(obj) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// Not returns the Predicate letting through the events the predicate does
+// not let through.
+func Not(p Predicate) Predicate {
+	return Predicate{
+		Create: func(obj interface{}) bool { return !p.create(obj) },
+		Update: func(oldObj, newObj interface{}) bool { return !p.update(oldObj, newObj) },
+		Delete: func(obj interface{}) bool { return !p.delete(obj) },
+	}
+}
+
+// updatePredicate returns the Predicate letting through all the adds and
+// deletes, and the updates whose objects differ per changed. Updates of
+// objects without metadata are let through.
+func updatePredicate(changed func(oldObj, newObj metav1.Object) bool) Predicate {
+	return Predicate{
+		Update: func(oldObj, newObj interface{}) bool {
+			o, err := meta.Accessor(oldObj)
+			if err != nil {
+				return true
+			}
+			n, err := meta.Accessor(newObj)
+			if err != nil {
+				return true
+			}
+			return changed(o, n)
+		},
+	}
+}
+
+// GenerationChanged lets through the updates changing the generation of the
+// objects, i.e. their spec, skipping the updates of their status or metadata
+// only, but for their deletion. The updates of the objects without a
+// generation, e.g. ConfigMaps, are let through.
+var GenerationChanged = updatePredicate(func(oldObj, newObj metav1.Object) bool {
+	if oldObj.GetGeneration() == 0 && newObj.GetGeneration() == 0 {
+		return true
+	}
+	if (oldObj.GetDeletionTimestamp() == nil) != (newObj.GetDeletionTimestamp() == nil) {
+		return true
+	}
+	return oldObj.GetGeneration() != newObj.GetGeneration()
+})
+
+// ResourceVersionChanged skips the updates of the periodic resyncs of the
+// informers, which do not change the objects.
+var ResourceVersionChanged = updatePredicate(func(oldObj, newObj metav1.Object) bool {
+	return oldObj.GetResourceVersion() != newObj.GetResourceVersion()
+})
+
+// LabelsChanged lets through the updates changing the labels of the objects.
+var LabelsChanged = updatePredicate(func(oldObj, newObj metav1.Object) bool {
+	return !equality.Semantic.DeepEqual(oldObj.GetLabels(), newObj.GetLabels())
+})
+
+// AnnotationsChanged lets through the updates changing the annotations of
+// the objects.
+var AnnotationsChanged = updatePredicate(func(oldObj, newObj metav1.Object) bool {
+	return !equality.Semantic.DeepEqual(oldObj.GetAnnotations(), newObj.GetAnnotations())
+})
+
+// HandleWithPredicates wraps the handler into a cache.ResourceEventHandler
+// passing it the events all the predicates let through, e.g.:
+//
+//	informer.AddEventHandler(controller.HandleWithPredicates(
+//	    controller.HandleAll(impl.Enqueue),
+//	    controller.PredicateFunc(controller.FilterController(&v1.Foo{})),
+//	    controller.Or(controller.GenerationChanged, controller.LabelsChanged)))
+func HandleWithPredicates(h cache.ResourceEventHandler, predicates ...Predicate) cache.ResourceEventHandler {
+	p := And(predicates...)
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if p.create(obj) {
+				h.OnAdd(obj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if p.update(oldObj, newObj) {
+				h.OnUpdate(oldObj, newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if p.delete(obj) {
+				h.OnDelete(obj)
+			}
+		},
+	}
+}
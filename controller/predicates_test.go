@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	. "knative.dev/pkg/testing"
+)
+
+func resourceWith(generation int64, resourceVersion string, labels map[string]string) *Resource {
+	return &Resource{ObjectMeta: metav1.ObjectMeta{
+		Name:            "foo",
+		Namespace:       "bar",
+		Generation:      generation,
+		ResourceVersion: resourceVersion,
+		Labels:          labels,
+	}}
+}
+
+func TestUpdatePredicates(t *testing.T) {
+	deleted := resourceWith(1, "3", nil)
+	deleted.DeletionTimestamp = &metav1.Time{}
+
+	tests := []struct {
+		name      string
+		predicate Predicate
+		old, new  interface{}
+		want      bool
+	}{{
+		name:      "generation changed",
+		predicate: GenerationChanged,
+		old:       resourceWith(1, "1", nil),
+		new:       resourceWith(2, "2", nil),
+		want:      true,
+	}, {
+		name:      "status only update",
+		predicate: GenerationChanged,
+		old:       resourceWith(1, "1", nil),
+		new:       resourceWith(1, "2", nil),
+	}, {
+		name:      "deletion",
+		predicate: GenerationChanged,
+		old:       resourceWith(1, "1", nil),
+		new:       deleted,
+		want:      true,
+	}, {
+		name:      "no generation",
+		predicate: GenerationChanged,
+		old:       resourceWith(0, "1", nil),
+		new:       resourceWith(0, "2", nil),
+		want:      true,
+	}, {
+		name:      "resync",
+		predicate: ResourceVersionChanged,
+		old:       resourceWith(1, "1", nil),
+		new:       resourceWith(1, "1", nil),
+	}, {
+		name:      "new resource version",
+		predicate: ResourceVersionChanged,
+		old:       resourceWith(1, "1", nil),
+		new:       resourceWith(1, "2", nil),
+		want:      true,
+	}, {
+		name:      "labels changed",
+		predicate: LabelsChanged,
+		old:       resourceWith(1, "1", map[string]string{"a": "b"}),
+		new:       resourceWith(1, "2", map[string]string{"a": "c"}),
+		want:      true,
+	}, {
+		name:      "labels unchanged",
+		predicate: LabelsChanged,
+		old:       resourceWith(1, "1", map[string]string{"a": "b"}),
+		new:       resourceWith(2, "2", map[string]string{"a": "b"}),
+	}, {
+		name:      "annotations unchanged",
+		predicate: AnnotationsChanged,
+		old:       resourceWith(1, "1", nil),
+		new:       resourceWith(2, "2", nil),
+	}, {
+		name:      "not an object",
+		predicate: GenerationChanged,
+		old:       "foo",
+		new:       "bar",
+		want:      true,
+	}, {
+		name:      "or",
+		predicate: Or(GenerationChanged, LabelsChanged),
+		old:       resourceWith(1, "1", nil),
+		new:       resourceWith(1, "2", map[string]string{"a": "b"}),
+		want:      true,
+	}, {
+		name:      "and",
+		predicate: And(GenerationChanged, LabelsChanged),
+		old:       resourceWith(1, "1", nil),
+		new:       resourceWith(1, "2", map[string]string{"a": "b"}),
+	}, {
+		name:      "not",
+		predicate: Not(ResourceVersionChanged),
+		old:       resourceWith(1, "1", nil),
+		new:       resourceWith(1, "1", nil),
+		want:      true,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.predicate.update(tc.old, tc.new); got != tc.want {
+				t.Errorf("Update = %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleWithPredicates(t *testing.T) {
+	var added, updated, deleted int
+	h := HandleWithPredicates(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { added++ },
+		UpdateFunc: func(interface{}, interface{}) { updated++ },
+		DeleteFunc: func(interface{}) { deleted++ },
+	}, PredicateFunc(FilterWithName("foo")), GenerationChanged)
+
+	foo := resourceWith(1, "1", nil)
+	other := resourceWith(1, "1", nil)
+	other.Name = "other"
+
+	h.OnAdd(foo)
+	h.OnAdd(other)
+	h.OnUpdate(foo, resourceWith(1, "2", nil))
+	h.OnUpdate(foo, resourceWith(2, "3", nil))
+	h.OnDelete(foo)
+	h.OnDelete(cache.DeletedFinalStateUnknown{Key: "bar/other", Obj: other})
+
+	if added != 1 || updated != 1 || deleted != 1 {
+		t.Errorf("Handled adds/updates/deletes = %d/%d/%d, wanted 1/1/1", added, updated, deleted)
+	}
+}
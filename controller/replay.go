@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync/atomic"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// ReplayCache notifies the handler, and only it, of each of the objects in
+// the cache of the informer as if they had just been added. This brings a
+// handler up to date when it starts handling the events of an informer which
+// has already synced, without a global resync spamming all the handlers of
+// the informer.
+//
+// Note that handlers added to a started informer with AddEventHandler are
+// already notified of the objects in its cache. ReplayCache is for handlers
+// which were registered upfront but ignored the events so far, see
+// DynamicHandler.
+func ReplayCache(si cache.SharedInformer, handler cache.ResourceEventHandler) {
+	for _, obj := range si.GetStore().List() {
+		handler.OnAdd(obj)
+	}
+}
+
+// DynamicHandler is a cache.ResourceEventHandler which drops the events of
+// its informer until it is enabled, e.g. when a reconciler is enabled at
+// runtime, and then passes them to its delegate. The delegate is notified
+// of the objects in the cache of the informer when the handler is enabled.
+type DynamicHandler struct {
+	informer cache.SharedInformer
+	delegate cache.ResourceEventHandler
+	enabled  atomic.Bool
+}
+
+var _ cache.ResourceEventHandler = (*DynamicHandler)(nil)
+
+// NewDynamicHandler returns a disabled DynamicHandler passing the events of
+// the informer to the delegate once enabled, and registers it with the
+// informer.
+func NewDynamicHandler(si cache.SharedInformer, delegate cache.ResourceEventHandler) (*DynamicHandler, error) {
+	d := &DynamicHandler{
+		informer: si,
+		delegate: delegate,
+	}
+	if _, err := si.AddEventHandler(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Enable starts passing the events to the delegate, after replaying the
+// cache of the informer to it. It is a no-op if the handler is enabled.
+func (d *DynamicHandler) Enable() {
+	if d.enabled.Swap(true) {
+		return
+	}
+	// Events received from now on are passed on, so objects added during
+	// the replay may be notified twice, but none are missed.
+	ReplayCache(d.informer, d.delegate)
+}
+
+// Disable stops passing the events to the delegate.
+func (d *DynamicHandler) Disable() {
+	d.enabled.Store(false)
+}
+
+// Enabled returns whether the events are passed to the delegate.
+func (d *DynamicHandler) Enabled() bool {
+	return d.enabled.Load()
+}
+
+// OnAdd implements cache.ResourceEventHandler
+func (d *DynamicHandler) OnAdd(obj interface{}) {
+	if d.enabled.Load() {
+		d.delegate.OnAdd(obj)
+	}
+}
+
+// OnUpdate implements cache.ResourceEventHandler
+func (d *DynamicHandler) OnUpdate(oldObj, newObj interface{}) {
+	if d.enabled.Load() {
+		d.delegate.OnUpdate(oldObj, newObj)
+	}
+}
+
+// OnDelete implements cache.ResourceEventHandler
+func (d *DynamicHandler) OnDelete(obj interface{}) {
+	if d.enabled.Load() {
+		d.delegate.OnDelete(obj)
+	}
+}
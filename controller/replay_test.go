@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// recordingHandler records the names of the objects it is notified of.
+type recordingHandler struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (r *recordingHandler) record(obj interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names = append(r.names, obj.(*corev1.Pod).Name)
+}
+
+func (r *recordingHandler) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := append([]string(nil), r.names...)
+	sort.Strings(ret)
+	return ret
+}
+
+func (r *recordingHandler) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    r.record,
+		UpdateFunc: func(_, obj interface{}) { r.record(obj) },
+		DeleteFunc: r.record,
+	}
+}
+
+func pod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+}
+
+func TestReplayCache(t *testing.T) {
+	si := cache.NewSharedInformer(nil, &corev1.Pod{}, 0)
+	for _, name := range []string{"b", "a"} {
+		si.GetStore().Add(pod(name))
+	}
+
+	r := &recordingHandler{}
+	ReplayCache(si, r.handler())
+
+	if got, want := r.get(), []string{"a", "b"}; !cmp.Equal(got, want) {
+		t.Errorf("Notified objects = %v, want: %v", got, want)
+	}
+}
+
+func TestDynamicHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher := watch.NewFake()
+	si := cache.NewSharedInformer(&cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return &corev1.PodList{Items: []corev1.Pod{*pod("a"), *pod("b")}}, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return watcher, nil
+		},
+	}, &corev1.Pod{}, 0)
+	go si.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), si.HasSynced) {
+		t.Fatal("Informer failed to sync")
+	}
+
+	r := &recordingHandler{}
+	d, err := NewDynamicHandler(si, r.handler())
+	if err != nil {
+		t.Fatal("NewDynamicHandler() =", err)
+	}
+
+	// Events are dropped while disabled.
+	watcher.Add(pod("c"))
+	waitForStore(t, si, 3)
+	if got := r.get(); len(got) != 0 {
+		t.Errorf("Notified objects while disabled = %v, want none", got)
+	}
+
+	// The cache is replayed when enabled.
+	d.Enable()
+	if !d.Enabled() {
+		t.Error("Enabled() = false after Enable()")
+	}
+	if got, want := r.get(), []string{"a", "b", "c"}; !cmp.Equal(got, want) {
+		t.Errorf("Notified objects after Enable() = %v, want: %v", got, want)
+	}
+	// Enabling twice doesn't replay again.
+	d.Enable()
+	if got, want := r.get(), []string{"a", "b", "c"}; !cmp.Equal(got, want) {
+		t.Errorf("Notified objects after second Enable() = %v, want: %v", got, want)
+	}
+
+	// Events are passed on while enabled.
+	watcher.Add(pod("d"))
+	want := []string{"a", "b", "c", "d"}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return cmp.Equal(r.get(), want), nil
+	}); err != nil {
+		t.Errorf("Notified objects while enabled = %v, want: %v", r.get(), want)
+	}
+
+	d.Disable()
+	watcher.Delete(pod("a"))
+	waitForStore(t, si, 3)
+	if got := r.get(); !cmp.Equal(got, want) {
+		t.Errorf("Notified objects after Disable() = %v, want: %v", got, want)
+	}
+}
+
+func waitForStore(t *testing.T, si cache.SharedInformer, n int) {
+	t.Helper()
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(si.GetStore().List()) == n, nil
+	}); err != nil {
+		t.Fatalf("Store has %d objects, want: %d", len(si.GetStore().List()), n)
+	}
+}
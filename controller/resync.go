@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	fieldName      = "metadata.name"
+	fieldNamespace = "metadata.namespace"
+)
+
+// LabelIndexName returns the name of the index of the informers by the
+// values of the given label key, as added by LabelIndexers.
+func LabelIndexName(key string) string {
+	return "label:" + key
+}
+
+// LabelIndexers returns the indexers of objects by the values of the given
+// label keys, for SelectorGlobalResync to find the objects selected by these
+// labels without walking the entire cache, e.g.:
+//
+//	informer.Informer().AddIndexers(controller.LabelIndexers("tenant"))
+func LabelIndexers(keys ...string) cache.Indexers {
+	indexers := make(cache.Indexers, len(keys))
+	for _, key := range keys {
+		key := key
+		indexers[LabelIndexName(key)] = func(obj interface{}) ([]string, error) {
+			object, err := meta.Accessor(obj)
+			if err != nil {
+				return nil, err
+			}
+			if v, ok := object.GetLabels()[key]; ok {
+				return []string{v}, nil
+			}
+			return nil, nil
+		}
+	}
+	return indexers
+}
+
+// SelectorGlobalResync enqueues into the slow lane the objects of the
+// informer matching the label and field selectors, either of which may be
+// nil to select everything. The field selector supports the metadata.name
+// and metadata.namespace fields. Rather than walking the entire cache, the
+// objects are looked up in the namespace index of the informer when the
+// field selector requires a namespace, or in an index of LabelIndexers when
+// the label selector requires values of an indexed label.
+func (c *Impl) SelectorGlobalResync(si cache.SharedIndexInformer, ls labels.Selector, fs fields.Selector) {
+	if c.workQueue.ShuttingDown() {
+		return
+	}
+	if ls == nil {
+		ls = labels.Everything()
+	}
+	if fs == nil {
+		fs = fields.Everything()
+	}
+
+	for _, obj := range selectorCandidates(si.GetIndexer(), ls, fs) {
+		object, err := meta.Accessor(obj)
+		if err != nil {
+			c.logger.Errorw("SelectorGlobalResync", zap.Error(err))
+			continue
+		}
+		if !ls.Matches(labels.Set(object.GetLabels())) || !fs.Matches(fields.Set{
+			fieldName:      object.GetName(),
+			fieldNamespace: object.GetNamespace(),
+		}) {
+			continue
+		}
+		c.EnqueueSlow(obj)
+	}
+}
+
+// selectorCandidates returns the objects which may match the selectors, from
+// the first index of the indexer serving them, or all of them otherwise.
+func selectorCandidates(indexer cache.Indexer, ls labels.Selector, fs fields.Selector) []interface{} {
+	indexers := indexer.GetIndexers()
+	if ns, ok := fs.RequiresExactMatch(fieldNamespace); ok {
+		if _, ok := indexers[cache.NamespaceIndex]; ok {
+			if objs, err := indexer.ByIndex(cache.NamespaceIndex, ns); err == nil {
+				return objs
+			}
+		}
+	}
+
+	reqs, _ := ls.Requirements()
+	for _, req := range reqs {
+		switch req.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+		default:
+			continue
+		}
+		name := LabelIndexName(req.Key())
+		if _, ok := indexers[name]; !ok {
+			continue
+		}
+		var objs []interface{}
+		for _, v := range req.Values().List() {
+			byValue, err := indexer.ByIndex(name, v)
+			if err != nil {
+				return indexer.List()
+			}
+			objs = append(objs, byValue...)
+		}
+		return objs
+	}
+	return indexer.List()
+}
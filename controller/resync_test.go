@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+	. "knative.dev/pkg/testing"
+)
+
+type indexInformer struct {
+	cache.SharedIndexInformer
+	indexer cache.Indexer
+}
+
+func (i *indexInformer) GetIndexer() cache.Indexer {
+	return i.indexer
+}
+
+// listCountingIndexer counts the calls to List, i.e. the walks of the
+// entire cache.
+type listCountingIndexer struct {
+	cache.Indexer
+	lists int
+}
+
+func (i *listCountingIndexer) List() []interface{} {
+	i.lists++
+	return i.Indexer.List()
+}
+
+func TestSelectorGlobalResync(t *testing.T) {
+	indexers := LabelIndexers("tenant")
+	indexers[cache.NamespaceIndex] = cache.MetaNamespaceIndexFunc
+	indexer := &listCountingIndexer{Indexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, indexers)}
+	for _, r := range []struct{ ns, name, tenant string }{
+		{"foo", "one", "a"},
+		{"foo", "two", "b"},
+		{"bar", "one", "a"},
+		{"bar", "two", ""},
+	} {
+		obj := &Resource{ObjectMeta: metav1.ObjectMeta{Namespace: r.ns, Name: r.name}}
+		if r.tenant != "" {
+			obj.Labels = map[string]string{"tenant": r.tenant}
+		}
+		if err := indexer.Add(obj); err != nil {
+			t.Fatal("Add() =", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		labels    string
+		fields    string
+		want      []string
+		wantLists int
+	}{{
+		name: "everything",
+		want: []string{"bar/one", "bar/two", "foo/one", "foo/two"},
+		// Without selectors, the cache is walked.
+		wantLists: 1,
+	}, {
+		name:   "namespace",
+		fields: "metadata.namespace=foo",
+		want:   []string{"foo/one", "foo/two"},
+	}, {
+		name:   "namespace and label",
+		labels: "tenant=a",
+		fields: "metadata.namespace=foo",
+		want:   []string{"foo/one"},
+	}, {
+		name:   "label",
+		labels: "tenant in (a,b)",
+		want:   []string{"bar/one", "foo/one", "foo/two"},
+	}, {
+		name:      "name",
+		fields:    "metadata.name=two",
+		want:      []string{"bar/two", "foo/two"},
+		wantLists: 1,
+	}, {
+		name:      "unindexed label",
+		labels:    "!tenant",
+		want:      []string{"bar/two"},
+		wantLists: 1,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ls, err := labels.Parse(tc.labels)
+			if err != nil {
+				t.Fatal("labels.Parse() =", err)
+			}
+			fs, err := fields.ParseSelector(tc.fields)
+			if err != nil {
+				t.Fatal("fields.ParseSelector() =", err)
+			}
+
+			impl := NewContext(context.TODO(), &nopReconciler{}, ControllerOptions{
+				Logger:        TestLogger(t),
+				WorkQueueName: "Testing",
+				Reporter:      &FakeStatsReporter{},
+			})
+			t.Cleanup(impl.WorkQueue().ShutDown)
+			indexer.lists = 0
+
+			impl.SelectorGlobalResync(&indexInformer{indexer: indexer}, ls, fs)
+
+			if indexer.lists != tc.wantLists {
+				t.Errorf("Walked the cache %d times, wanted %d", indexer.lists, tc.wantLists)
+			}
+			// The slow lane is moved into the queue asynchronously.
+			if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+				return impl.WorkQueue().Len() == len(tc.want), nil
+			}); err != nil {
+				t.Fatalf("Queue length = %d, wanted %d", impl.WorkQueue().Len(), len(tc.want))
+			}
+			got := make([]string, 0, len(tc.want))
+			for range tc.want {
+				key, _ := impl.WorkQueue().Get()
+				got = append(got, key.(types.NamespacedName).String())
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Error("Enqueued keys (-want, +got):", diff)
+			}
+		})
+	}
+}
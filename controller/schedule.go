@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// schedules tracks the times the keys are scheduled for by
+// EnqueueKeyAfterWithJitter, to collapse repeated schedules.
+type schedules struct {
+	mu        sync.Mutex
+	at        map[types.NamespacedName]time.Time
+	lastSweep time.Time
+}
+
+// schedule records that the key is scheduled at the given time, unless it
+// already is within the window of it, and returns whether it was.
+func (s *schedules) schedule(key types.NamespacedName, at time.Time, window time.Duration, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	if prev, ok := s.at[key]; ok && prev.After(now) {
+		if d := at.Sub(prev); d <= window && d >= -window {
+			return false
+		}
+	}
+	if s.at == nil {
+		s.at = make(map[types.NamespacedName]time.Time)
+	}
+	s.at[key] = at
+	return true
+}
+
+// sweep forgets the past schedules, once a minute. It must be called with
+// the lock held.
+func (s *schedules) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, at := range s.at {
+		if !at.After(now) {
+			delete(s.at, key)
+		}
+	}
+}
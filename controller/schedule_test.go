@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestSchedules(t *testing.T) {
+	var s schedules
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	now := time.Now()
+	window := 10 * time.Second
+
+	tests := []struct {
+		name string
+		now  time.Time
+		at   time.Time
+		want bool
+	}{{
+		name: "first",
+		now:  now,
+		at:   now.Add(time.Minute),
+		want: true,
+	}, {
+		name: "later within the window",
+		now:  now,
+		at:   now.Add(time.Minute + 5*time.Second),
+	}, {
+		name: "earlier within the window",
+		now:  now.Add(time.Second),
+		at:   now.Add(time.Minute - 10*time.Second),
+	}, {
+		name: "outside the window",
+		now:  now.Add(time.Second),
+		at:   now.Add(2 * time.Minute),
+		want: true,
+	}, {
+		name: "after the previous one passed",
+		now:  now.Add(3 * time.Minute),
+		at:   now.Add(3*time.Minute + time.Second),
+		want: true,
+	}}
+
+	for _, tc := range tests {
+		if got := s.schedule(key, tc.at, window, tc.now); got != tc.want {
+			t.Errorf("%s: schedule() = %v, wanted %v", tc.name, got, tc.want)
+		}
+	}
+
+	// Past schedules are swept.
+	s.schedule(types.NamespacedName{Name: "other"}, now.Add(5*time.Minute), window, now.Add(5*time.Minute))
+	if _, ok := s.at[key]; ok {
+		t.Error("The past schedule was not swept")
+	}
+}
+
+func TestEnqueueKeyAfterWithJitter(t *testing.T) {
+	impl := NewContext(context.TODO(), &nopReconciler{}, ControllerOptions{
+		Logger:               TestLogger(t),
+		WorkQueueName:        "Testing",
+		Reporter:             &FakeStatsReporter{},
+		ScheduleDedupeWindow: time.Minute,
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	before := time.Now()
+	impl.EnqueueKeyAfterWithJitter(key, time.Hour, 0.5)
+	at := impl.schedules.at[key]
+	if min, max := before.Add(time.Hour), time.Now().Add(90*time.Minute); at.Before(min) || at.After(max) {
+		t.Errorf("Scheduled at %v, wanted between %v and %v", at, min, max)
+	}
+
+	// Collapsed into the first schedule.
+	impl.EnqueueKeyAfterWithJitter(key, time.Until(at)+30*time.Second, 0)
+	if got := impl.schedules.at[key]; !got.Equal(at) {
+		t.Errorf("Scheduled at %v, wanted %v", got, at)
+	}
+
+	impl.EnqueueKeyAfterWithJitter(key, 10*time.Millisecond, 0)
+	if got := impl.schedules.at[key]; !got.Before(at) {
+		t.Errorf("Scheduled at %v, wanted the earlier schedule", got)
+	}
+	if got, shutdown := impl.WorkQueue().Get(); shutdown || got != key {
+		t.Errorf("Get() = %v, %v, wanted %v", got, shutdown, key)
+	}
+}
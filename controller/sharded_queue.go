@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// shardSuffix separates the name of a work queue from the index of its
+// shards, in the names of their lanes.
+const shardSuffix = "-shard-"
+
+// controllerQueue is the work queue of a controller: a twoLaneQueue, or a
+// shardedQueue of them.
+type controllerQueue interface {
+	workqueue.RateLimitingInterface
+
+	// SlowLane gives direct access to the slow queue.
+	SlowLane() workqueue.RateLimitingInterface
+	// HighLane gives direct access to the high queue, or the fast queue when
+	// created without priorities.
+	HighLane() workqueue.RateLimitingInterface
+
+	// hasHighLane returns whether the queue was created with priorities.
+	hasHighLane() bool
+	// consumers returns the queues the workers get the keys from, each of
+	// which must have its own workers.
+	consumers() []workqueue.Interface
+}
+
+var (
+	_ controllerQueue = (*twoLaneQueue)(nil)
+	_ controllerQueue = (*shardedQueue)(nil)
+)
+
+// shardedQueue is a work queue made of several twoLaneQueues, the shards,
+// across which the keys are spread by their hash, so that the controllers
+// of many objects do not contend on a single queue. As a key always goes to
+// the same shard, a key is never processed by two workers at once.
+type shardedQueue struct {
+	*shardedLanes // of the shards
+	shards        []*twoLaneQueue
+	slowLane      *shardedLanes
+	highLane      *shardedLanes
+}
+
+// newShardedWorkQueue creates a work queue of the given number of shards,
+// with priorities if starvationLimit is positive, see newPriorityWorkQueue
+// for handoff.
+func newShardedWorkQueue(name string, rl workqueue.RateLimiter, shards, starvationLimit, handoff int) *shardedQueue {
+	sq := &shardedQueue{shards: make([]*twoLaneQueue, shards)}
+	all := make([]workqueue.RateLimitingInterface, shards)
+	slow := make([]workqueue.RateLimitingInterface, shards)
+	high := make([]workqueue.RateLimitingInterface, shards)
+	for i := range sq.shards {
+		shardName := name + shardSuffix + strconv.Itoa(i)
+		if starvationLimit > 0 {
+			sq.shards[i] = newPriorityWorkQueue(shardName, rl, starvationLimit, handoff)
+		} else {
+			sq.shards[i] = newTwoLaneWorkQueue(shardName, rl)
+		}
+		all[i] = sq.shards[i]
+		slow[i] = sq.shards[i].SlowLane()
+		high[i] = sq.shards[i].HighLane()
+	}
+	sq.shardedLanes = &shardedLanes{lanes: all}
+	sq.slowLane = &shardedLanes{lanes: slow}
+	sq.highLane = &shardedLanes{lanes: high}
+	return sq
+}
+
+// SlowLane implements controllerQueue.
+func (sq *shardedQueue) SlowLane() workqueue.RateLimitingInterface {
+	return sq.slowLane
+}
+
+// HighLane implements controllerQueue.
+func (sq *shardedQueue) HighLane() workqueue.RateLimitingInterface {
+	return sq.highLane
+}
+
+func (sq *shardedQueue) hasHighLane() bool {
+	return sq.shards[0].hasHighLane()
+}
+
+func (sq *shardedQueue) consumers() []workqueue.Interface {
+	consumers := make([]workqueue.Interface, len(sq.shards))
+	for i, shard := range sq.shards {
+		consumers[i] = shard
+	}
+	return consumers
+}
+
+// shardOf returns the index of the shard of the item among n.
+func shardOf(item interface{}, n int) int {
+	h := fnv.New32a()
+	if key, ok := item.(types.NamespacedName); ok {
+		h.Write([]byte(key.Namespace))
+		h.Write([]byte{types.Separator})
+		h.Write([]byte(key.Name))
+	} else {
+		fmt.Fprint(h, item)
+	}
+	return int(h.Sum32() % uint32(n))
+}
+
+// shardedLanes is a workqueue.RateLimitingInterface spreading the items
+// across lanes by their hash.
+type shardedLanes struct {
+	lanes []workqueue.RateLimitingInterface
+
+	// items fans the items of the lanes in for Get.
+	fanIn sync.Once
+	items chan interface{}
+}
+
+var _ workqueue.RateLimitingInterface = (*shardedLanes)(nil)
+
+func (sl *shardedLanes) lane(item interface{}) workqueue.RateLimitingInterface {
+	return sl.lanes[shardOf(item, len(sl.lanes))]
+}
+
+// Add implements workqueue.Interface.
+func (sl *shardedLanes) Add(item interface{}) {
+	sl.lane(item).Add(item)
+}
+
+// Len implements workqueue.Interface, summing the lengths of the lanes.
+func (sl *shardedLanes) Len() int {
+	l := 0
+	for _, lane := range sl.lanes {
+		l += lane.Len()
+	}
+	return l
+}
+
+// Get implements workqueue.Interface. It gets the items of all the lanes,
+// for the callers other than the workers of the controller, which get them
+// from their own shard.
+func (sl *shardedLanes) Get() (interface{}, bool) {
+	sl.fanIn.Do(func() {
+		sl.items = make(chan interface{})
+		var wg sync.WaitGroup
+		for _, lane := range sl.lanes {
+			wg.Add(1)
+			go func(lane workqueue.Interface) {
+				defer wg.Done()
+				for {
+					item, shutdown := lane.Get()
+					if shutdown {
+						return
+					}
+					sl.items <- item
+				}
+			}(lane)
+		}
+		go func() {
+			wg.Wait()
+			close(sl.items)
+		}()
+	})
+	item, ok := <-sl.items
+	return item, !ok
+}
+
+// Done implements workqueue.Interface.
+func (sl *shardedLanes) Done(item interface{}) {
+	sl.lane(item).Done(item)
+}
+
+// ShutDown implements workqueue.Interface.
+func (sl *shardedLanes) ShutDown() {
+	for _, lane := range sl.lanes {
+		lane.ShutDown()
+	}
+}
+
+// ShutDownWithDrain implements workqueue.Interface.
+func (sl *shardedLanes) ShutDownWithDrain() {
+	for _, lane := range sl.lanes {
+		lane.ShutDownWithDrain()
+	}
+}
+
+// ShuttingDown implements workqueue.Interface.
+func (sl *shardedLanes) ShuttingDown() bool {
+	return sl.lanes[0].ShuttingDown()
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (sl *shardedLanes) AddAfter(item interface{}, duration time.Duration) {
+	sl.lane(item).AddAfter(item, duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (sl *shardedLanes) AddRateLimited(item interface{}) {
+	sl.lane(item).AddRateLimited(item)
+}
+
+// Forget implements workqueue.RateLimitingInterface.
+func (sl *shardedLanes) Forget(item interface{}) {
+	sl.lane(item).Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimitingInterface.
+func (sl *shardedLanes) NumRequeues(item interface{}) int {
+	return sl.lane(item).NumRequeues(item)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestShardedQueueRouting(t *testing.T) {
+	sq := newShardedWorkQueue("sharded", workqueue.DefaultControllerRateLimiter(), 4, 0, 1)
+	t.Cleanup(sq.ShutDown)
+
+	if got := len(sq.consumers()); got != 4 {
+		t.Fatalf("Consumers = %d, wanted 4", got)
+	}
+	if sq.hasHighLane() {
+		t.Error("hasHighLane() = true, wanted false")
+	}
+
+	const keys = 100
+	for i := 0; i < keys; i++ {
+		key := types.NamespacedName{Namespace: "ns", Name: fmt.Sprint("key-", i)}
+		sq.Add(key)
+		// Adding a key again is deduplicated by its shard.
+		sq.Add(key)
+	}
+	// The keys move from the lanes to the consumer queue of the shards.
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return sq.Len() == keys, nil
+	}); err != nil {
+		t.Errorf("Len() = %d, wanted %d", sq.Len(), keys)
+	}
+
+	for i, shard := range sq.shards {
+		for j := shard.Len(); j > 0; j-- {
+			item, _ := shard.Get()
+			if got := shardOf(item, len(sq.shards)); got != i {
+				t.Errorf("Key %v in shard %d, wanted %d", item, i, got)
+			}
+			shard.Done(item)
+		}
+	}
+}
+
+func TestShardedQueueWithPriorities(t *testing.T) {
+	sq := newShardedWorkQueue("sharded-priority", workqueue.DefaultControllerRateLimiter(), 2, DefaultStarvationLimit, 1)
+	t.Cleanup(sq.ShutDown)
+
+	if !sq.hasHighLane() {
+		t.Error("hasHighLane() = false, wanted true")
+	}
+	key := types.NamespacedName{Namespace: "ns", Name: "high"}
+	sq.HighLane().Add(key)
+
+	// Get fans the items of the shards in.
+	if item, shutdown := sq.Get(); shutdown || item != key {
+		t.Errorf("Get() = %v, %v, wanted %v", item, shutdown, key)
+	}
+}
+
+// concurrencyReconciler records the keys it reconciles, and fails the test
+// when a key is reconciled by two workers at once.
+type concurrencyReconciler struct {
+	t *testing.T
+
+	mu         sync.Mutex
+	inFlight   sets.String
+	reconciled sets.String
+	wg         sync.WaitGroup
+}
+
+func (r *concurrencyReconciler) Reconcile(_ context.Context, key string) error {
+	r.mu.Lock()
+	if r.inFlight.Has(key) {
+		r.t.Error("Key reconciled concurrently:", key)
+	}
+	r.inFlight.Insert(key)
+	first := !r.reconciled.Has(key)
+	r.reconciled.Insert(key)
+	r.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	r.mu.Lock()
+	r.inFlight.Delete(key)
+	r.mu.Unlock()
+	if first {
+		r.wg.Done()
+	}
+	return nil
+}
+
+func TestShardedController(t *testing.T) {
+	r := &concurrencyReconciler{t: t, inFlight: sets.NewString(), reconciled: sets.NewString()}
+	impl := NewContext(context.TODO(), r, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Sharded",
+		Reporter:      &FakeStatsReporter{},
+		Shards:        4,
+	})
+
+	const keys = 50
+	r.wg.Add(keys)
+	for i := 0; i < keys; i++ {
+		key := types.NamespacedName{Namespace: "ns", Name: fmt.Sprint("key-", i)}
+		impl.EnqueueKey(key)
+		impl.EnqueueSlowKey(key)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		// Fewer workers than shards still process all of them.
+		errCh <- impl.RunContext(ctx, 2)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Error("Timed out waiting for the keys to be reconciled")
+	}
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Error("RunContext() =", err)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/kmeta"
+)
+
+// keyVersions tracks the resource versions of the objects enqueued by
+// EnqueueVersioned, and those last reconciled successfully, to skip the
+// reconciles of versions already reconciled.
+type keyVersions struct {
+	mu sync.Mutex
+	// enqueued is the version of the latest enqueue of the keys since their
+	// processing started, empty when they must be reconciled regardless.
+	enqueued map[types.NamespacedName]string
+	// reconciled is the version of the last successful reconcile of the
+	// keys.
+	reconciled map[types.NamespacedName]string
+}
+
+// observe records that the key is enqueued for the version, unless it is
+// already enqueued to be reconciled regardless.
+func (kv *keyVersions) observe(key types.NamespacedName, version string) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if v, ok := kv.enqueued[key]; ok && v == "" {
+		return
+	}
+	if kv.enqueued == nil {
+		kv.enqueued = make(map[types.NamespacedName]string)
+	}
+	kv.enqueued[key] = version
+}
+
+// force records that the key is enqueued to be reconciled regardless of its
+// version, when it has one.
+func (kv *keyVersions) force(key types.NamespacedName) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	_, enqueued := kv.enqueued[key]
+	_, reconciled := kv.reconciled[key]
+	if !enqueued && !reconciled {
+		return
+	}
+	if kv.enqueued == nil {
+		kv.enqueued = make(map[types.NamespacedName]string)
+	}
+	kv.enqueued[key] = ""
+}
+
+// start returns the version of the key whose processing starts, and whether
+// it is stale, i.e. already reconciled.
+func (kv *keyVersions) start(key types.NamespacedName) (string, bool) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	version, ok := kv.enqueued[key]
+	if !ok {
+		// Requeued, e.g. after a failure.
+		return "", false
+	}
+	delete(kv.enqueued, key)
+	if version == "" {
+		// Forced, the reconcile is recorded as unversioned.
+		delete(kv.reconciled, key)
+		return "", false
+	}
+	return version, kv.reconciled[key] == version
+}
+
+// succeeded records the version of the key was reconciled.
+func (kv *keyVersions) succeeded(key types.NamespacedName, version string) {
+	if version == "" {
+		return
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if kv.reconciled == nil {
+		kv.reconciled = make(map[types.NamespacedName]string)
+	}
+	kv.reconciled[key] = version
+}
+
+// EnqueueVersioned enqueues the object like Enqueue, recording its resource
+// version: when its key is processed, the reconcile is skipped if that
+// version was already reconciled successfully, e.g. after bursts of updates
+// of the object or the resyncs of its informer. The keys enqueued by the
+// other methods are always reconciled, so deletions must be handled with
+// Enqueue, e.g.:
+//
+//	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+//	    AddFunc:    impl.EnqueueVersioned,
+//	    UpdateFunc: controller.PassNew(impl.EnqueueVersioned),
+//	    DeleteFunc: impl.Enqueue,
+//	})
+func (c *Impl) EnqueueVersioned(obj interface{}) {
+	object, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		c.logger.Errorw("EnqueueVersioned", zap.Error(err))
+		return
+	}
+	key := types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}
+	c.versions.observe(key, object.GetResourceVersion())
+	c.enqueueKey(key)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+	. "knative.dev/pkg/testing"
+)
+
+// failingReconciler counts its reconciles, failing them while err is set.
+type failingReconciler struct {
+	count int
+	err   error
+}
+
+func (r *failingReconciler) Reconcile(context.Context, string) error {
+	r.count++
+	return r.err
+}
+
+func TestEnqueueVersioned(t *testing.T) {
+	r := &failingReconciler{}
+	impl := NewContext(context.TODO(), r, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Versioned",
+		Reporter:      &FakeStatsReporter{},
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+
+	at := func(version string) *Resource {
+		return &Resource{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "foo",
+			Name:            "bar",
+			ResourceVersion: version,
+		}}
+	}
+
+	steps := []struct {
+		name    string
+		enqueue func()
+		err     error
+		want    int
+	}{{
+		name:    "first version",
+		enqueue: func() { impl.EnqueueVersioned(at("1")) },
+		want:    1,
+	}, {
+		name:    "same version",
+		enqueue: func() { impl.EnqueueVersioned(at("1")) },
+		want:    1,
+	}, {
+		name: "newer version after the same",
+		enqueue: func() {
+			impl.EnqueueVersioned(at("1"))
+			impl.EnqueueVersioned(at("2"))
+		},
+		want: 2,
+	}, {
+		name:    "unversioned enqueue",
+		enqueue: func() { impl.Enqueue(at("2")) },
+		want:    3,
+	}, {
+		name:    "versioned after unversioned",
+		enqueue: func() { impl.EnqueueVersioned(at("2")) },
+		want:    4,
+	}, {
+		name:    "failed version",
+		enqueue: func() { impl.EnqueueVersioned(at("3")) },
+		err:     NewPermanentError(errors.New("boom")),
+		want:    5,
+	}, {
+		name:    "failed version again",
+		enqueue: func() { impl.EnqueueVersioned(at("3")) },
+		want:    6,
+	}, {
+		name:    "reconciled version",
+		enqueue: func() { impl.EnqueueVersioned(at("3")) },
+		want:    6,
+	}}
+
+	for _, step := range steps {
+		r.err = step.err
+		step.enqueue()
+		if !impl.processNextWorkItem() {
+			t.Fatalf("%s: processNextWorkItem() = false, wanted true", step.name)
+		}
+		if r.count != step.want {
+			t.Errorf("%s: reconciles = %d, wanted %d", step.name, r.count, step.want)
+		}
+	}
+}
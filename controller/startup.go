@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+)
+
+// RecordInformerSyncs records the time each of the informers takes to sync
+// from now, as the metrics.PhaseInformerSync phase named after the type of
+// their objects, e.g. to be called right before starting them. The informers
+// of the same type, e.g. filtered ones, are recorded under the same name. It
+// returns without waiting for the informers to sync.
+func RecordInformerSyncs(ctx context.Context, informers ...Informer) {
+	start := time.Now()
+	for _, informer := range informers {
+		informer := informer
+		go func() {
+			if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+				return
+			}
+			d := time.Since(start)
+			name := "unknown"
+			if si, ok := informer.(StoreInformer); ok {
+				name = objectTypeName(si.GetStore().List())
+			}
+			metrics.RecordPhase(ctx, metrics.PhaseInformerSync, name, d)
+		}()
+	}
+}
+
+// firstReconcile records the time from the start of the workers of a
+// controller to its first reconcile, see metrics.PhaseFirstReconcile.
+type firstReconcile struct {
+	// started is set before the workers start, and is zero when the
+	// controller is not run, e.g. in the tests processing the items.
+	started time.Time
+	once    sync.Once
+}
+
+// record records the first reconcile of the controller, if it was started.
+func (f *firstReconcile) record(c *Impl) {
+	if f.started.IsZero() {
+		return
+	}
+	f.once.Do(func() {
+		ctx := logging.WithLogger(context.Background(), c.logger)
+		metrics.RecordPhase(ctx, metrics.PhaseFirstReconcile, c.Name, time.Since(f.started))
+	})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+// errorCounter counts the errors of the expectations on the metrics, to
+// wait for them to hold.
+type errorCounter struct {
+	errors int
+}
+
+func (*errorCounter) Helper() {}
+
+func (e *errorCounter) Error(...interface{}) {
+	e.errors++
+}
+
+// waitForPhase waits for the phase to be recorded count times.
+func waitForPhase(t *testing.T, phase, name string, count float64) {
+	t.Helper()
+	tags := map[string]string{"phase": phase, "name": name}
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		ec := &errorCounter{}
+		metricstest.Expect(ec, "process_phase_duration").WithTags(tags).Exists().Equals(count)
+		if ec.errors == 0 {
+			return
+		}
+	}
+	metricstest.Expect(t, "process_phase_duration").WithTags(tags).Exists().Equals(count)
+}
+
+// syncedStoreInformer is a StoreInformer which has synced.
+type syncedStoreInformer struct {
+	*fakeStoreInformer
+}
+
+func (syncedStoreInformer) HasSynced() bool { return true }
+
+func (syncedStoreInformer) Run(<-chan struct{}) {}
+
+func TestRecordInformerSyncs(t *testing.T) {
+	metricstest.ResetViews(t, "process_phase_duration")
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns"}}
+
+	RecordInformerSyncs(TestContextWithLogger(t),
+		syncedStoreInformer{newFakeStoreInformer(t, cm)},
+		syncedStoreInformer{newFakeStoreInformer(t)})
+
+	waitForPhase(t, metrics.PhaseInformerSync, "v1.ConfigMap", 1)
+	waitForPhase(t, metrics.PhaseInformerSync, "unknown", 1)
+}
+
+func TestFirstReconcile(t *testing.T) {
+	metricstest.ResetViews(t, "process_phase_duration")
+	r := &keyRecordingReconciler{keys: make(chan string, 10)}
+	impl := NewContext(context.TODO(), r, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "FirstReconcile",
+		Reporter:      &FakeStatsReporter{},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		impl.RunContext(ctx, 1)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	for _, name := range []string{"first", "second"} {
+		impl.EnqueueKey(types.NamespacedName{Namespace: "ns", Name: name})
+		select {
+		case <-r.keys:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for", name, "to be reconciled")
+		}
+	}
+
+	// Only the first reconcile is recorded.
+	waitForPhase(t, metrics.PhaseFirstReconcile, "FirstReconcile", 1)
+}
@@ -36,6 +36,14 @@ var (
 	workQueueDepthStat   = stats.Int64("work_queue_depth", "Depth of the work queue", stats.UnitDimensionless)
 	reconcileCountStat   = stats.Int64("reconcile_count", "Number of reconcile operations", stats.UnitDimensionless)
 	reconcileLatencyStat = stats.Int64("reconcile_latency", "Latency of reconcile operations", stats.UnitMilliseconds)
+	informerStarvedStat  = stats.Int64("informer_starved_count", "Number of times an informer watch was found starved", stats.UnitDimensionless)
+	informerObjectsStat  = stats.Int64("informer_cache_objects", "Number of objects in an informer cache", stats.UnitDimensionless)
+	informerBytesStat    = stats.Int64("informer_cache_bytes", "Estimated memory held by an informer cache", stats.UnitBytes)
+	parkedKeysStat       = stats.Int64("parked_key_count", "Number of times a key was parked after failing persistently", stats.UnitDimensionless)
+	deadLetteredStat     = stats.Int64("dead_lettered_key_count", "Number of keys dropped after exhausting their retries", stats.UnitDimensionless)
+	coalescedEventsStat  = stats.Int64("coalesced_event_count", "Number of events of hot objects coalesced into the enqueue of their key", stats.UnitDimensionless)
+	reconcileTimeoutStat = stats.Int64("reconcile_timeout_count", "Number of reconcile operations which timed out", stats.UnitDimensionless)
+	abandonedStat        = stats.Int64("abandoned_reconcile_count", "Number of reconcile operations which timed out and are still running", stats.UnitDimensionless)
 
 	// reconcileDistribution defines the bucket boundaries for the histogram of reconcile latency metric.
 	// Bucket boundaries are 10ms, 100ms, 1s, 10s, 30s and 60s.
@@ -48,6 +56,8 @@ var (
 	// - characters are printable US-ASCII
 	reconcilerTagKey = tag.MustNewKey("reconciler")
 	successTagKey    = tag.MustNewKey("success")
+	informerTagKey   = tag.MustNewKey("informer")
+	keyTagKey        = tag.MustNewKey("key")
 
 	// NamespaceTagKey marks metrics with a namespace.
 	NamespaceTagKey = tag.MustNewKey(metricskey.LabelNamespaceName)
@@ -91,6 +101,7 @@ func init() {
 			"How long in seconds the longest outstanding workqueue item has been in flight.",
 			stats.UnitSeconds,
 		),
+		Bucket: workqueueBucket,
 	}
 	workqueue.SetProvider(wp)
 
@@ -127,6 +138,46 @@ func init() {
 		Measure:     reconcileLatencyStat,
 		Aggregation: reconcileDistribution,
 		TagKeys:     []tag.Key{reconcilerTagKey, successTagKey, NamespaceTagKey},
+	}, {
+		Description: "Number of times an informer watch was found starved",
+		Measure:     informerStarvedStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{informerTagKey},
+	}, {
+		Description: "Number of objects in an informer cache",
+		Measure:     informerObjectsStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{informerTagKey},
+	}, {
+		Description: "Estimated memory held by an informer cache",
+		Measure:     informerBytesStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{informerTagKey},
+	}, {
+		Description: "Number of times a key was parked after failing persistently",
+		Measure:     parkedKeysStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{reconcilerTagKey, NamespaceTagKey},
+	}, {
+		Description: "Number of keys dropped after exhausting their retries",
+		Measure:     deadLetteredStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{reconcilerTagKey, NamespaceTagKey},
+	}, {
+		Description: "Number of events of hot objects coalesced into the enqueue of their key",
+		Measure:     coalescedEventsStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{reconcilerTagKey, keyTagKey},
+	}, {
+		Description: "Number of reconcile operations which timed out",
+		Measure:     reconcileTimeoutStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{reconcilerTagKey, NamespaceTagKey},
+	}, {
+		Description: "Number of reconcile operations which timed out and are still running",
+		Measure:     abandonedStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{reconcilerTagKey},
 	}}
 	views = append(views, wp.DefaultViews()...)
 	views = append(views, cp.DefaultViews()...)
@@ -203,3 +254,112 @@ func (r *reporter) ReportReconcile(duration time.Duration, success string, key t
 		reconcileLatencyStat.M(duration.Milliseconds()))
 	return nil
 }
+
+// reportInformerStarved reports that the named informer was found starved.
+func reportInformerStarved(informer string) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(informerTagKey, informer),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, informerStarvedStat.M(1))
+	return nil
+}
+
+// reportInformerMemory reports the object count and estimated memory of the
+// named informer's cache.
+func reportInformerMemory(informer string, objects int, bytes int64) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(informerTagKey, informer),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordBatch(ctx, informerObjectsStat.M(int64(objects)), informerBytesStat.M(bytes))
+	return nil
+}
+
+// reportCoalescedEvent reports that an event of the object of the key was
+// coalesced by the named reconciler. Only the keys of hot objects have their
+// events coalesced, which keeps the cardinality of the key tag low.
+func reportCoalescedEvent(reconciler string, key types.NamespacedName) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(reconcilerTagKey, reconciler),
+		tag.Insert(keyTagKey, key.String()),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, coalescedEventsStat.M(1))
+	return nil
+}
+
+// reportParkedKey reports that the key was parked by the circuit breaker of
+// the named reconciler.
+func reportParkedKey(reconciler string, key types.NamespacedName) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(reconcilerTagKey, reconciler),
+		tag.Insert(NamespaceTagKey, key.Namespace),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, parkedKeysStat.M(1))
+	return nil
+}
+
+// reportDeadLetteredKey reports that the key was dropped by the named
+// reconciler after exhausting its retries.
+func reportDeadLetteredKey(reconciler string, key types.NamespacedName) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(reconcilerTagKey, reconciler),
+		tag.Insert(NamespaceTagKey, key.Namespace),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, deadLetteredStat.M(1))
+	return nil
+}
+
+// reportReconcileTimeout reports that the reconcile of the key by the named
+// reconciler timed out.
+func reportReconcileTimeout(reconciler string, key types.NamespacedName) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(reconcilerTagKey, reconciler),
+		tag.Insert(NamespaceTagKey, key.Namespace),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, reconcileTimeoutStat.M(1))
+	return nil
+}
+
+// reportAbandonedReconciles reports the number of the reconciles of the
+// named reconciler which timed out and are still running.
+func reportAbandonedReconciles(reconciler string, n int64) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(reconcilerTagKey, reconciler),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, abandonedStat.M(n))
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/logging"
+)
+
+// ErrReconcileTimeout is wrapped by the errors of the reconciles which took
+// longer than the ReconcileTimeout of their controller.
+var ErrReconcileTimeout = errors.New("reconcile timed out")
+
+// reconcile runs the Reconciler on the key. With a ReconcileTimeout, the
+// context of the Reconciler has a deadline, and the worker moves on once it
+// passes, even if the Reconciler ignores it, so that a hung call does not
+// block the worker forever. The timeout is a transient error.
+//
+// The Reconciler abandoned by the worker may still be running though, in
+// which case the returned channel is closed once it returns, and the key
+// must be kept busy in the workqueue until then, so that no other worker
+// reconciles it concurrently. The channel is nil otherwise.
+func (c *Impl) reconcile(ctx context.Context, key types.NamespacedName) (<-chan struct{}, error) {
+	keyStr := safeKey(key)
+	if c.ReconcileTimeout <= 0 {
+		return nil, c.Reconciler.Reconcile(ctx, keyStr)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.ReconcileTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Reconciler.Reconcile(ctx, keyStr)
+	}()
+
+	var (
+		err     error
+		running <-chan struct{}
+	)
+	select {
+	case err = <-done:
+		if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, err
+		}
+	case <-ctx.Done():
+		running = c.abandon(ctx, done)
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			// The context of the reconcile was cancelled, e.g. on
+			// shutdown, which is not a timeout.
+			return running, ctx.Err()
+		}
+	}
+
+	if rerr := reportReconcileTimeout(c.Name, key); rerr != nil {
+		logging.FromContext(ctx).Warnw("Failed to report the reconcile timeout", zap.Error(rerr))
+	}
+	if err != nil {
+		return running, fmt.Errorf("%w after %v: %v", ErrReconcileTimeout, c.ReconcileTimeout, err)
+	}
+	return running, fmt.Errorf("%w after %v", ErrReconcileTimeout, c.ReconcileTimeout)
+}
+
+// abandon counts the Reconciler still running, as the
+// abandoned_reconcile_count metric, until it sends its result on done, and
+// returns a channel closed then.
+func (c *Impl) abandon(ctx context.Context, done <-chan error) <-chan struct{} {
+	logger := logging.FromContext(ctx)
+	report := func(n int64) {
+		if err := reportAbandonedReconciles(c.Name, n); err != nil {
+			logger.Warnw("Failed to report the abandoned reconciles", zap.Error(err))
+		}
+	}
+
+	report(c.abandoned.Add(1))
+	running := make(chan struct{})
+	go func() {
+		defer close(running)
+		err := <-done
+		report(c.abandoned.Add(-1))
+		logger.Infow("Abandoned reconcile returned", zap.Error(err))
+	}()
+	return running
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+// hangingReconciler blocks until released, ignoring its context unless
+// honorContext is set.
+type hangingReconciler struct {
+	honorContext bool
+	release      chan struct{}
+}
+
+func (r *hangingReconciler) Reconcile(ctx context.Context, _ string) error {
+	if r.honorContext {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	<-r.release
+	return nil
+}
+
+func TestReconcileTimeout(t *testing.T) {
+	m := metricstest.Expect(t, "reconcile_timeout_count").WithTags(
+		map[string]string{"reconciler": "Timeout", "namespace_name": "foo"})
+	before := m.Value()
+	for _, honor := range []bool{true, false} {
+		r := &hangingReconciler{honorContext: honor, release: make(chan struct{})}
+		t.Cleanup(func() { close(r.release) })
+		impl := NewContext(context.TODO(), r, ControllerOptions{
+			Logger:           TestLogger(t),
+			WorkQueueName:    "Timeout",
+			Reporter:         &FakeStatsReporter{},
+			ReconcileTimeout: 10 * time.Millisecond,
+		})
+		t.Cleanup(impl.WorkQueue().ShutDown)
+
+		key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+		running, err := impl.reconcile(context.Background(), key)
+		if !errors.Is(err, ErrReconcileTimeout) {
+			t.Errorf("reconcile() = %v, wanted %v", err, ErrReconcileTimeout)
+		}
+		if IsPermanentError(err) {
+			t.Errorf("reconcile() = %v, wanted a transient error", err)
+		}
+		if !honor && running == nil {
+			t.Error("reconcile() returned no channel for the running Reconciler")
+		}
+	}
+	m.Delta(before, 2)
+}
+
+func TestReconcileTimeoutKeepsKeyBusy(t *testing.T) {
+	r := &hangingReconciler{release: make(chan struct{})}
+	impl := NewContext(context.TODO(), r, ControllerOptions{
+		Logger:           TestLogger(t),
+		WorkQueueName:    "Abandoning",
+		Reporter:         &FakeStatsReporter{},
+		RateLimiter:      workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond),
+		ReconcileTimeout: 10 * time.Millisecond,
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+	abandoned := metricstest.Expect(t, "abandoned_reconcile_count").WithTags(
+		map[string]string{"reconciler": "Abandoning"})
+
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	impl.EnqueueKey(key)
+	impl.processNextWorkItem()
+	abandoned.Equals(1)
+
+	// The key is retried, but not before the Reconciler returns.
+	time.Sleep(50 * time.Millisecond)
+	if got := impl.WorkQueue().Len(); got != 0 {
+		t.Errorf("WorkQueue().Len() = %d while the Reconciler runs, wanted 0", got)
+	}
+
+	close(r.release)
+	if err := wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return impl.WorkQueue().Len() == 1, nil
+	}); err != nil {
+		t.Error("The key was not retried once the Reconciler returned:", err)
+	}
+	abandoned.Equals(0)
+}
+
+func TestReconcileCancelled(t *testing.T) {
+	impl := NewContext(context.TODO(), &hangingReconciler{honorContext: true}, ControllerOptions{
+		Logger:           TestLogger(t),
+		WorkQueueName:    "Cancelled",
+		Reporter:         &FakeStatsReporter{},
+		ReconcileTimeout: time.Minute,
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := impl.reconcile(ctx, types.NamespacedName{Namespace: "foo", Name: "bar"})
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrReconcileTimeout) {
+		t.Errorf("reconcile() = %v, wanted %v", err, context.Canceled)
+	}
+}
+
+func TestReconcileWithinTimeout(t *testing.T) {
+	impl := NewContext(context.TODO(), &errorReconciler{}, ControllerOptions{
+		Logger:           TestLogger(t),
+		WorkQueueName:    "WithinTimeout",
+		Reporter:         &FakeStatsReporter{},
+		ReconcileTimeout: time.Minute,
+	})
+	t.Cleanup(impl.WorkQueue().ShutDown)
+
+	_, err := impl.reconcile(context.Background(), types.NamespacedName{Namespace: "foo", Name: "bar"})
+	var fe *fakeError
+	if !errors.As(err, &fe) || errors.Is(err, ErrReconcileTimeout) {
+		t.Errorf("reconcile() = %v, wanted the error of the Reconciler", err)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"go.opencensus.io/trace"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// reconcileSpanName is the name of the spans wrapping the reconciles.
+	reconcileSpanName = "controller.Reconcile"
+
+	// The outcomes of the reconciles, as recorded on their spans.
+	outcomeSuccess   = "success"
+	outcomeSkipped   = "skipped"
+	outcomeRequeued  = "requeued"
+	outcomePermanent = "permanent_error"
+	outcomeError     = "error"
+)
+
+// startReconcileSpan starts the span of the reconcile of the key, which the
+// returned context carries so that the spans of the client calls made by the
+// Reconciler nest under it. Whether the span is sampled and exported is
+// governed by the tracing configuration, see knative.dev/pkg/tracing.
+func (c *Impl) startReconcileSpan(ctx context.Context, key types.NamespacedName) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, reconcileSpanName)
+	if span.IsRecordingEvents() {
+		span.AddAttributes(
+			trace.StringAttribute("reconciler", c.Name),
+			trace.StringAttribute("key", safeKey(key)),
+			trace.Int64Attribute("retries", int64(c.workQueue.NumRequeues(key))),
+		)
+	}
+	return ctx, span
+}
+
+// endReconcileSpan records the outcome of the reconcile on its span and ends
+// it.
+func endReconcileSpan(span *trace.Span, err error) {
+	defer span.End()
+	if !span.IsRecordingEvents() {
+		return
+	}
+	outcome := reconcileOutcome(err)
+	span.AddAttributes(trace.StringAttribute("outcome", outcome))
+	if outcome == outcomePermanent || outcome == outcomeError {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+}
+
+// reconcileOutcome classifies the error returned by a reconcile.
+func reconcileOutcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case IsSkipKey(err):
+		return outcomeSkipped
+	case IsPermanentError(err):
+		return outcomePermanent
+	}
+	if ok, _ := IsRequeueKey(err); ok {
+		return outcomeRequeued
+	}
+	return outcomeError
+}
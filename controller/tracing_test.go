@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.opencensus.io/trace"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+// spanReconciler returns its error, checking that the context carries the
+// span of the reconcile.
+type spanReconciler struct {
+	t   *testing.T
+	err error
+}
+
+func (r *spanReconciler) Reconcile(ctx context.Context, _ string) error {
+	if trace.FromContext(ctx) == nil {
+		r.t.Error("Reconcile got no span in its context")
+	}
+	return r.err
+}
+
+func TestReconcileSpan(t *testing.T) {
+	rec := &spanRecorder{}
+	trace.RegisterExporter(rec)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	t.Cleanup(func() {
+		trace.UnregisterExporter(rec)
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+	})
+
+	tests := []struct {
+		name    string
+		err     error
+		outcome string
+		status  int32
+	}{{
+		name:    "success",
+		outcome: outcomeSuccess,
+	}, {
+		name:    "skipped",
+		err:     NewSkipKey("foo/bar"),
+		outcome: outcomeSkipped,
+	}, {
+		name:    "requeued",
+		err:     NewRequeueImmediately(),
+		outcome: outcomeRequeued,
+	}, {
+		name:    "permanent",
+		err:     NewPermanentError(errors.New("bad")),
+		outcome: outcomePermanent,
+		status:  trace.StatusCodeUnknown,
+	}, {
+		name:    "error",
+		err:     errors.New("boom"),
+		outcome: outcomeError,
+		status:  trace.StatusCodeUnknown,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec.spans = nil
+			impl := NewContext(context.TODO(), &spanReconciler{t: t, err: test.err}, ControllerOptions{
+				Logger:        TestLogger(t),
+				WorkQueueName: "Tracing",
+				Reporter:      &FakeStatsReporter{},
+			})
+			t.Cleanup(impl.WorkQueue().ShutDown)
+
+			key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+			impl.EnqueueKey(key)
+			impl.processNextWorkItem()
+
+			if len(rec.spans) != 1 {
+				t.Fatalf("Exported spans = %d, wanted 1", len(rec.spans))
+			}
+			span := rec.spans[0]
+			if span.Name != reconcileSpanName {
+				t.Errorf("Span name = %q, wanted %q", span.Name, reconcileSpanName)
+			}
+			want := map[string]interface{}{
+				"reconciler": "Tracing",
+				"key":        "foo/bar",
+				"retries":    int64(0),
+				"outcome":    test.outcome,
+			}
+			for k, v := range want {
+				if got := span.Attributes[k]; got != v {
+					t.Errorf("Attribute %q = %v, wanted %v", k, got, v)
+				}
+			}
+			if span.Code != test.status {
+				t.Errorf("Status code = %d, wanted %d", span.Code, test.status)
+			}
+		})
+	}
+}
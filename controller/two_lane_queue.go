@@ -16,15 +16,25 @@ limitations under the License.
 
 package controller
 
-import "k8s.io/client-go/util/workqueue"
+import (
+	"reflect"
+
+	"k8s.io/client-go/util/workqueue"
+)
 
 // twoLaneQueue is a rate limited queue that wraps around two queues
 // -- fast queue (anonymously aliased), whose contents are processed with priority.
 // -- slow queue (slowLane queue), whose contents are processed if fast queue has no items.
 // All the default methods operate on the fast queue, unless noted otherwise.
+// When created with priorities, it also wraps around a high queue (highLane
+// queue), whose contents are processed before the fast queue's, and keeps the
+// lower queues from starving. The items are then handed off to the consumer
+// queue as they are got, for the lanes to be picked by priority at each Get
+// rather than when the items arrive.
 type twoLaneQueue struct {
 	workqueue.RateLimitingInterface
 	slowLane workqueue.RateLimitingInterface
+	highLane workqueue.RateLimitingInterface
 	// consumerQueue is necessary to ensure that we're not reconciling
 	// the same object at the exact same time (e.g. if it had been enqueued
 	// in both fast and slow and is the only object there).
@@ -34,11 +44,43 @@ type twoLaneQueue struct {
 
 	fastChan chan interface{}
 	slowChan chan interface{}
+	highChan chan interface{}
+
+	// starvationLimit is the number of items of higher lanes processed in a
+	// row after which an item waiting in a lower lane is processed, or 0 to
+	// always process the higher lanes first.
+	starvationLimit int
+
+	// handoff is the number of items moved to the consumer queue ahead of
+	// the calls to Get, or 0 to move the items as soon as they arrive. taken
+	// signals the consumer thread that an item was got.
+	handoff int
+	taken   chan struct{}
 }
 
 // Creates a new twoLaneQueue.
 func newTwoLaneWorkQueue(name string, rl workqueue.RateLimiter) *twoLaneQueue {
-	tlq := &twoLaneQueue{
+	tlq := newLanes(name, rl)
+	tlq.start()
+	return tlq
+}
+
+// Creates a new twoLaneQueue with a high lane, which keeps the lower lanes
+// from starving past the given limit, and hands off up to handoff items at a
+// time to the consumer queue.
+func newPriorityWorkQueue(name string, rl workqueue.RateLimiter, starvationLimit, handoff int) *twoLaneQueue {
+	tlq := newLanes(name, rl)
+	tlq.highLane = workqueue.NewNamedRateLimitingQueue(rl, name+"-high")
+	tlq.highChan = make(chan interface{})
+	tlq.starvationLimit = starvationLimit
+	tlq.handoff = handoff
+	tlq.taken = make(chan struct{}, 1)
+	tlq.start()
+	return tlq
+}
+
+func newLanes(name string, rl workqueue.RateLimiter) *twoLaneQueue {
+	return &twoLaneQueue{
 		RateLimitingInterface: workqueue.NewNamedRateLimitingQueue(
 			rl,
 			name+"-fast",
@@ -52,12 +94,17 @@ func newTwoLaneWorkQueue(name string, rl workqueue.RateLimiter) *twoLaneQueue {
 		fastChan:      make(chan interface{}),
 		slowChan:      make(chan interface{}),
 	}
+}
+
+func (tlq *twoLaneQueue) start() {
 	// Run consumer thread.
 	go tlq.runConsumer()
 	// Run producer threads.
 	go process(tlq.RateLimitingInterface, tlq.fastChan)
 	go process(tlq.slowLane, tlq.slowChan)
-	return tlq
+	if tlq.highLane != nil {
+		go process(tlq.highLane, tlq.highChan)
+	}
 }
 
 func process(q workqueue.Interface, ch chan interface{}) {
@@ -74,59 +121,135 @@ func process(q workqueue.Interface, ch chan interface{}) {
 	}
 }
 
+// consumerLane is a lane read by the consumer thread.
+type consumerLane struct {
+	ch   <-chan interface{}
+	open bool
+	// starved counts the items of the higher lanes processed since this
+	// lane's last item.
+	starved int
+}
+
 func (tlq *twoLaneQueue) runConsumer() {
-	// Shutdown flags.
-	fast, slow := true, true
-	// When both producer queues are shutdown stop the consumerQueue.
+	// The lanes, by decreasing priority.
+	var lanes []*consumerLane
+	if tlq.highLane != nil {
+		lanes = append(lanes, &consumerLane{ch: tlq.highChan, open: true})
+	}
+	lanes = append(lanes,
+		&consumerLane{ch: tlq.fastChan, open: true},
+		&consumerLane{ch: tlq.slowChan, open: true})
+
+	// When all producer queues are shutdown stop the consumerQueue.
 	defer tlq.consumerQueue.ShutDown()
 	// While any of the queues is still running, try to read off of them.
-	for fast || slow {
-		// By default drain the fast lane.
-		// Channels in select are picked random, so first
-		// we have a select that only looks at the fast lane queue.
-		if fast {
-			select {
-			case item, ok := <-tlq.fastChan:
-				if !ok {
-					// This queue is shutdown and drained. Stop looking at it.
-					fast = false
-					continue
-				}
-				tlq.consumerQueue.Add(item)
-				continue
-			default:
-				// This immediately exits the wait if the fast chan is empty.
+	for anyOpen(lanes) {
+		// Wait for the items handed off to be got, for the next one to be
+		// picked among those waiting then.
+		tlq.waitHandoff()
+		// First give an item to the lowest lane starving, if any is
+		// waiting in it.
+		if tlq.starvationLimit > 0 && tlq.tryStarving(lanes) {
+			continue
+		}
+		// Then drain the lanes by priority. Channels in select are picked
+		// randomly, so first we try each lane on its own.
+		if tlq.tryByPriority(lanes) {
+			continue
+		}
+		// If no lane had items, we can wait on all of them. Obviously if
+		// suddenly several are populated at the same time there's a chance
+		// that a lower one would be picked first, but this should be a rare
+		// occasion not to really worry about it.
+		cases := make([]reflect.SelectCase, 0, len(lanes))
+		open := make([]int, 0, len(lanes))
+		for i, l := range lanes {
+			if l.open {
+				cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(l.ch)})
+				open = append(open, i)
 			}
 		}
+		chosen, item, ok := reflect.Select(cases)
+		tlq.received(lanes, open[chosen], item.Interface(), ok)
+	}
+}
 
-		// If the fast lane queue had no items, we can select from both.
-		// Obviously if suddenly both are populated at the same time there's a
-		// 50% chance that the slow would be picked first, but this should be
-		// a rare occasion not to really worry about it.
+// waitHandoff blocks while the consumer queue has handoff items waiting.
+func (tlq *twoLaneQueue) waitHandoff() {
+	for tlq.handoff > 0 && tlq.consumerQueue.Len() >= tlq.handoff {
+		<-tlq.taken
+	}
+}
+
+func anyOpen(lanes []*consumerLane) bool {
+	for _, l := range lanes {
+		if l.open {
+			return true
+		}
+	}
+	return false
+}
+
+// tryStarving moves an item from the lowest lane which reached the
+// starvation limit, if it has one waiting, returning whether it did.
+func (tlq *twoLaneQueue) tryStarving(lanes []*consumerLane) bool {
+	for i := len(lanes) - 1; i > 0; i-- {
+		l := lanes[i]
+		if !l.open || l.starved < tlq.starvationLimit {
+			continue
+		}
 		select {
-		case item, ok := <-tlq.fastChan:
-			if !ok {
-				// This queue is shutdown and drained. Stop looking at it.
-				fast = false
-				continue
-			}
-			tlq.consumerQueue.Add(item)
-		case item, ok := <-tlq.slowChan:
-			if !ok {
-				// This queue is shutdown and drained. Stop looking at it.
-				slow = false
-				continue
-			}
-			tlq.consumerQueue.Add(item)
+		case item, ok := <-l.ch:
+			tlq.received(lanes, i, item, ok)
+			return true
+		default:
+			// Nothing is waiting, so the lane is not starving.
+			l.starved = 0
 		}
 	}
+	return false
+}
+
+// tryByPriority moves an item from the highest lane which has one waiting,
+// returning whether it did.
+func (tlq *twoLaneQueue) tryByPriority(lanes []*consumerLane) bool {
+	for i, l := range lanes {
+		if !l.open {
+			continue
+		}
+		select {
+		case item, ok := <-l.ch:
+			tlq.received(lanes, i, item, ok)
+			return true
+		default:
+			// This immediately exits the wait if the lane is empty.
+		}
+	}
+	return false
+}
+
+// received handles the item received from the i-th lane.
+func (tlq *twoLaneQueue) received(lanes []*consumerLane, i int, item interface{}, ok bool) {
+	if !ok {
+		// This queue is shutdown and drained. Stop looking at it.
+		lanes[i].open = false
+		return
+	}
+	tlq.consumerQueue.Add(item)
+	lanes[i].starved = 0
+	for _, l := range lanes[i+1:] {
+		l.starved++
+	}
 }
 
 // Shutdown implements workqueue.Interface.
-// Shutdown shuts down both queues.
+// Shutdown shuts down all the queues.
 func (tlq *twoLaneQueue) ShutDown() {
 	tlq.RateLimitingInterface.ShutDown()
 	tlq.slowLane.ShutDown()
+	if tlq.highLane != nil {
+		tlq.highLane.ShutDown()
+	}
 }
 
 // Done implements workqueue.Interface.
@@ -141,16 +264,45 @@ func (tlq *twoLaneQueue) Done(i interface{}) {
 // It gets the item from fast lane if it has anything, alternatively
 // the slow lane.
 func (tlq *twoLaneQueue) Get() (interface{}, bool) {
-	return tlq.consumerQueue.Get()
+	item, shutdown := tlq.consumerQueue.Get()
+	if tlq.taken != nil {
+		select {
+		case tlq.taken <- struct{}{}:
+		default:
+			// The consumer thread is already signaled.
+		}
+	}
+	return item, shutdown
 }
 
 // Len returns the sum of lengths.
 // NB: actual _number_ of unique object might be less than this sum.
 func (tlq *twoLaneQueue) Len() int {
-	return tlq.RateLimitingInterface.Len() + tlq.slowLane.Len() + tlq.consumerQueue.Len()
+	l := tlq.RateLimitingInterface.Len() + tlq.slowLane.Len() + tlq.consumerQueue.Len()
+	if tlq.highLane != nil {
+		l += tlq.highLane.Len()
+	}
+	return l
 }
 
 // SlowLane gives direct access to the slow queue.
 func (tlq *twoLaneQueue) SlowLane() workqueue.RateLimitingInterface {
 	return tlq.slowLane
 }
+
+// HighLane gives direct access to the high queue, or the fast queue when
+// created without priorities.
+func (tlq *twoLaneQueue) HighLane() workqueue.RateLimitingInterface {
+	if tlq.highLane == nil {
+		return tlq.RateLimitingInterface
+	}
+	return tlq.highLane
+}
+
+func (tlq *twoLaneQueue) hasHighLane() bool {
+	return tlq.highLane != nil
+}
+
+func (tlq *twoLaneQueue) consumers() []workqueue.Interface {
+	return []workqueue.Interface{tlq}
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
 )
@@ -199,3 +200,115 @@ func TestOrder(t *testing.T) {
 		q.Done(v)
 	}
 }
+
+func TestPriorityLanes(t *testing.T) {
+	tests := []struct {
+		name            string
+		starvationLimit int
+		want            []string
+	}{{
+		name: "by priority",
+		want: []string{"h1", "h2", "h3", "h4", "h5", "f1", "f2", "s1", "s2"},
+	}, {
+		name:            "starvation limit",
+		starvationLimit: 2,
+		want:            []string{"h1", "h2", "s1", "f1", "h3", "s2", "h4", "f2", "h5"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lane := func(items ...string) chan interface{} {
+				ch := make(chan interface{}, len(items))
+				for _, i := range items {
+					ch <- i
+				}
+				close(ch)
+				return ch
+			}
+			// Drive the consumer by hand, with all the items waiting.
+			tlq := &twoLaneQueue{
+				highLane:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "high"),
+				consumerQueue:   workqueue.NewNamed("consumer"),
+				highChan:        lane("h1", "h2", "h3", "h4", "h5"),
+				fastChan:        lane("f1", "f2"),
+				slowChan:        lane("s1", "s2"),
+				starvationLimit: test.starvationLimit,
+			}
+			tlq.runConsumer()
+
+			var got []string
+			for {
+				item, shutdown := tlq.consumerQueue.Get()
+				if shutdown {
+					break
+				}
+				got = append(got, item.(string))
+				tlq.consumerQueue.Done(item)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Order = %v, wanted %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPriorityHandoff(t *testing.T) {
+	q := newPriorityWorkQueue("handoff", workqueue.DefaultControllerRateLimiter(), DefaultStarvationLimit, 1)
+	t.Cleanup(q.ShutDown)
+
+	for _, item := range []string{"f1", "f2", "f3", "f4", "f5"} {
+		q.Add(item)
+	}
+	// A single item is handed off ahead of Get, and the next one is held by
+	// the producer of the fast lane.
+	if wait.PollImmediate(10*time.Millisecond, 250*time.Millisecond, func() (bool, error) {
+		return q.consumerQueue.Len() == 1 && q.RateLimitingInterface.Len() == 3, nil
+	}) != nil {
+		t.Fatalf("Queue lengths were never 1 and 3, got %d and %d", q.consumerQueue.Len(), q.RateLimitingInterface.Len())
+	}
+	q.HighLane().Add("h1")
+	if wait.PollImmediate(10*time.Millisecond, 250*time.Millisecond, func() (bool, error) {
+		return q.highLane.Len() == 0, nil
+	}) != nil {
+		t.Fatal("High lane length was never 0")
+	}
+
+	// The high item only waits for the one already handed off.
+	var got []string
+	for i := 0; i < 3; i++ {
+		item, _ := q.Get()
+		got = append(got, item.(string))
+		q.Done(item)
+	}
+	if want := []string{"f1", "h1", "f2"}; !cmp.Equal(got, want) {
+		t.Errorf("Order = %v, wanted %v", got, want)
+	}
+}
+
+func TestHighLane(t *testing.T) {
+	q := newTwoLaneWorkQueue("no-high-lane", workqueue.DefaultControllerRateLimiter())
+	t.Cleanup(q.ShutDown)
+	if q.HighLane() != q.RateLimitingInterface {
+		t.Error("HighLane() is not the fast lane without priorities")
+	}
+
+	q = newPriorityWorkQueue("high-lane", workqueue.DefaultControllerRateLimiter(), DefaultStarvationLimit, 1)
+	q.HighLane().Add("1")
+	if wait.PollImmediate(10*time.Millisecond, 250*time.Millisecond, func() (bool, error) {
+		return q.Len() == 1, nil
+	}) != nil {
+		t.Error("Queue length was never 1")
+	}
+	k, _ := q.Get()
+	if got, want := k.(string), "1"; got != want {
+		t.Errorf("Get() = %q, wanted %q", got, want)
+	}
+	q.Done(k)
+	q.ShutDown()
+	if !q.HighLane().ShuttingDown() {
+		t.Error("ShutDown did not propagate to the high queue")
+	}
+	if _, done := q.Get(); !done {
+		t.Error("Get did not return positive shutdown signal")
+	}
+}
@@ -22,7 +22,9 @@ import (
 	"log"
 	"math"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -35,9 +37,14 @@ type ClientConfig struct {
 	ServerURL  string  // ServerURL - The address of the Kubernetes API server. Overrides any value in kubeconfig.
 	Burst      int     // Burst - Maximum burst for throttle.
 	QPS        float64 // QPS - Maximum QPS to the server from the client.
-	Kubeconfig string  // Kubeconfig - Path to a kubeconfig. Current casing is present for backwards compatibility
+	Kubeconfig string  // Kubeconfig - Paths to kubeconfigs, merged as in KUBECONFIG. Current casing is present for backwards compatibility
+	Context    string  // Context - The kubeconfig context to use (defaults to current context in kubeconfig)
 }
 
+// inClusterConfig returns the config of the service account of the pod we
+// run in, and can be replaced by tests.
+var inClusterConfig = rest.InClusterConfig
+
 func (c *ClientConfig) InitFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.Cluster, "cluster", "", "Defaults to the current cluster in kubeconfig.")
 
@@ -45,7 +52,9 @@ func (c *ClientConfig) InitFlags(fs *flag.FlagSet) {
 		"The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 
 	fs.StringVar(&c.Kubeconfig, "kubeconfig", os.Getenv("KUBECONFIG"),
-		"Path to a kubeconfig. Only required if out-of-cluster.")
+		"Path to a kubeconfig, or list of paths merged as in KUBECONFIG. Only required if out-of-cluster.")
+
+	fs.StringVar(&c.Context, "context", "", "Defaults to the current context in kubeconfig.")
 
 	fs.IntVar(&c.Burst, "kube-api-burst", int(envVarOrDefault("KUBE_API_BURST", 0)), "Maximum burst for throttle.")
 
@@ -70,29 +79,91 @@ func (c *ClientConfig) GetRESTConfig() (*rest.Config, error) {
 		return nil, fmt.Errorf("provided QPS value %f must be >0 and <3.4+e38", c.QPS)
 	}
 
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	overrides := &clientcmd.ConfigOverrides{}
+	config, source, err := c.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	log.Print("Using Kubernetes credentials from ", source)
+
+	config.QPS = float32(c.QPS)
+	config.Burst = c.Burst
 
-	if c.Kubeconfig != "" {
-		loadingRules.ExplicitPath = c.Kubeconfig
+	return config, nil
+}
+
+// restConfig returns the config to reach the API server and a description
+// of where its credentials come from. The kubeconfigs given explicitly, by
+// flag or KUBECONFIG, come first, then the in-cluster config when running
+// in a pod, then the default kubeconfig of the user. When the kubeconfigs
+// given explicitly are all missing or empty, the in-cluster config is used
+// if possible.
+func (c *ClientConfig) restConfig() (*rest.Config, string, error) {
+	paths := filepath.SplitList(c.Kubeconfig)
+	explicit := len(paths) > 0 || c.Context != "" || c.Cluster != ""
+
+	if !explicit {
+		if config, err := c.inClusterConfig(); err == nil {
+			return config, "the in-cluster service account", nil
+		}
 	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if len(paths) > 0 {
+		// The missing files of the precedence list are skipped, as with
+		// KUBECONFIG, and the others merged.
+		loadingRules.Precedence = paths
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: c.Context}
 	if c.Cluster != "" {
 		overrides.Context = clientcmdapi.Context{Cluster: c.Cluster}
 	} else if c.ServerURL != "" {
 		overrides.ClusterInfo = clientcmdapi.Cluster{Server: c.ServerURL}
 	}
 
-	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		loadingRules,
-		overrides,
-	).ClientConfig()
-
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	config, err := clientConfig.ClientConfig()
+	if clientcmd.IsEmptyConfig(err) && len(paths) > 0 {
+		if icc, icErr := c.inClusterConfig(); icErr == nil {
+			return icc, fmt.Sprintf("the in-cluster service account, as kubeconfig %s is missing or empty", c.Kubeconfig), nil
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create client config: %w", err)
+		return nil, "", fmt.Errorf("failed to create client config: %w", err)
 	}
 
-	config.QPS = float32(c.QPS)
-	config.Burst = c.Burst
+	contextName := c.Context
+	if contextName == "" {
+		raw, err := clientConfig.RawConfig()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		contextName = raw.CurrentContext
+	}
+	files := loadingRules.GetLoadingPrecedence()
+	existing := files[:0:0]
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			existing = append(existing, f)
+		}
+	}
+	source := fmt.Sprintf("context %q of kubeconfig %s", contextName, strings.Join(existing, string(filepath.ListSeparator)))
+	if c.Cluster != "" {
+		source += fmt.Sprintf(", with cluster %q", c.Cluster)
+	} else if c.ServerURL != "" {
+		source += fmt.Sprintf(", with server %s", c.ServerURL)
+	}
+	return config, source, nil
+}
 
+// inClusterConfig returns the in-cluster config, pointed at ServerURL if set.
+func (c *ClientConfig) inClusterConfig() (*rest.Config, error) {
+	config, err := inClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	if c.ServerURL != "" {
+		config.Host = c.ServerURL
+	}
 	return config, nil
 }
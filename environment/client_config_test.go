@@ -18,10 +18,14 @@ package environment
 
 import (
 	"flag"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/rest"
 )
 
 func TestInitFlag(t *testing.T) {
@@ -48,3 +52,132 @@ func TestInitFlag(t *testing.T) {
 		t.Errorf("ClientConfig mismatch: diff(-want,+got):\n%s", cmp.Diff(expect, c))
 	}
 }
+
+const (
+	clustersKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: one
+  cluster:
+    server: https://one.example.com
+- name: two
+  cluster:
+    server: https://two.example.com
+users:
+- name: admin
+  user:
+    token: secret
+`
+	contextsKubeconfig = `apiVersion: v1
+kind: Config
+current-context: first
+contexts:
+- name: first
+  context:
+    cluster: one
+    user: admin
+- name: second
+  context:
+    cluster: two
+    user: admin
+`
+)
+
+func writeKubeconfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal("Failed to write kubeconfig:", err)
+	}
+	return path
+}
+
+func TestRESTConfig(t *testing.T) {
+	clusters := writeKubeconfig(t, "clusters", clustersKubeconfig)
+	contexts := writeKubeconfig(t, "contexts", contextsKubeconfig)
+	missing := filepath.Join(t.TempDir(), "missing")
+	list := func(paths ...string) string {
+		return strings.Join(paths, string(filepath.ListSeparator))
+	}
+
+	tests := []struct {
+		name       string
+		config     ClientConfig
+		inCluster  bool
+		wantHost   string
+		wantSource string
+		wantErr    bool
+	}{{
+		name:       "merged kubeconfigs",
+		config:     ClientConfig{Kubeconfig: list(contexts, clusters)},
+		wantHost:   "https://one.example.com",
+		wantSource: `context "first" of kubeconfig ` + list(contexts, clusters),
+	}, {
+		name:       "context override",
+		config:     ClientConfig{Kubeconfig: list(contexts, clusters), Context: "second"},
+		wantHost:   "https://two.example.com",
+		wantSource: `context "second" of kubeconfig ` + list(contexts, clusters),
+	}, {
+		name:       "missing kubeconfig skipped",
+		config:     ClientConfig{Kubeconfig: list(missing, contexts, clusters)},
+		inCluster:  true,
+		wantHost:   "https://one.example.com",
+		wantSource: `context "first" of kubeconfig ` + list(contexts, clusters),
+	}, {
+		name:       "server override",
+		config:     ClientConfig{Kubeconfig: list(contexts, clusters), ServerURL: "https://other.example.com"},
+		wantHost:   "https://other.example.com",
+		wantSource: `context "first" of kubeconfig ` + list(contexts, clusters) + ", with server https://other.example.com",
+	}, {
+		name:       "in-cluster",
+		inCluster:  true,
+		wantHost:   "https://in-cluster.example.com",
+		wantSource: "the in-cluster service account",
+	}, {
+		name:       "in-cluster with server override",
+		config:     ClientConfig{ServerURL: "https://other.example.com"},
+		inCluster:  true,
+		wantHost:   "https://other.example.com",
+		wantSource: "the in-cluster service account",
+	}, {
+		name:       "missing kubeconfig in-cluster fallback",
+		config:     ClientConfig{Kubeconfig: missing},
+		inCluster:  true,
+		wantHost:   "https://in-cluster.example.com",
+		wantSource: "the in-cluster service account, as kubeconfig " + missing + " is missing or empty",
+	}, {
+		name:    "missing kubeconfig out of cluster",
+		config:  ClientConfig{Kubeconfig: missing},
+		wantErr: true,
+	}, {
+		name:    "unknown context",
+		config:  ClientConfig{Kubeconfig: list(contexts, clusters), Context: "third"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func(f func() (*rest.Config, error)) { inClusterConfig = f }(inClusterConfig)
+			inClusterConfig = func() (*rest.Config, error) {
+				if !test.inCluster {
+					return nil, rest.ErrNotInCluster
+				}
+				return &rest.Config{Host: "https://in-cluster.example.com"}, nil
+			}
+
+			config, source, err := test.config.restConfig()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("restConfig() = %v, wanted error: %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if config.Host != test.wantHost {
+				t.Errorf("Host = %q, wanted %q", config.Host, test.wantHost)
+			}
+			if source != test.wantSource {
+				t.Errorf("source = %q, wanted %q", source, test.wantSource)
+			}
+		})
+	}
+}
@@ -51,7 +51,8 @@ EXTERNAL_INFORMER_PKG="k8s.io/client-go/informers" \
     k8s.io/api \
     "${K8S_TYPES}" \
     --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt \
-    --force-genreconciler-kinds "Namespace,ConfigMap,Deployment,Secret,Pod,CronJob,NetworkPolicy,Node,ValidatingWebhookConfiguration,MutatingWebhookConfiguration,ServiceAccount"
+    --force-genreconciler-kinds "Namespace,ConfigMap,Deployment,Secret,Pod,CronJob,NetworkPolicy,Node,ValidatingWebhookConfiguration,MutatingWebhookConfiguration,ServiceAccount" \
+    --filtered-genreconciler-kinds "Namespace,ConfigMap,Deployment,Secret,Pod,CronJob,NetworkPolicy,Node,ValidatingWebhookConfiguration,MutatingWebhookConfiguration,ServiceAccount"
 
 OUTPUT_PKG="knative.dev/pkg/client/injection/apiextensions" \
 VERSIONED_CLIENTSET_PKG="k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset" \
@@ -60,7 +61,8 @@ VERSIONED_CLIENTSET_PKG="k8s.io/apiextensions-apiserver/pkg/client/clientset/cli
     k8s.io/apiextensions-apiserver/pkg/apis \
     "apiextensions:v1beta1,v1" \
     --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt \
-    --force-genreconciler-kinds "CustomResourceDefinition"
+    --force-genreconciler-kinds "CustomResourceDefinition" \
+    --filtered-genreconciler-kinds "CustomResourceDefinition"
 
 group "Knative Codegen"
 
@@ -84,6 +86,15 @@ go run k8s.io/code-generator/cmd/deepcopy-gen  --input-dirs \
   -O zz_generated.deepcopy \
   --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
 
+# Verify that the Populate methods of the Duck types set all their fields.
+go run ./codegen/cmd/conformance-gen --input-dirs \
+  $(echo \
+  ${REPO_ROOT_DIR}/apis/duck/v1 \
+  ${REPO_ROOT_DIR}/apis/duck/v1beta1 \
+  ${REPO_ROOT_DIR}/apis/duck/v1alpha1 \
+  | sed "s/ /,/g") \
+  --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
+
 group "Update deps post-codegen"
 
 # Make sure our dependencies are up-to-date
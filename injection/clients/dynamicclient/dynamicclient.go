@@ -28,6 +28,7 @@ import (
 
 func init() {
 	injection.Default.RegisterClient(withClient)
+	injection.RegisterKey("knative.dev/pkg/injection/clients/dynamicclient", Key{})
 }
 
 // Key is used as the key for associating information
@@ -19,6 +19,7 @@ package configmap
 import (
 	"context"
 
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	v1 "k8s.io/client-go/informers/core/v1"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection"
@@ -28,6 +29,7 @@ import (
 
 func init() {
 	injection.Default.RegisterInformer(withInformer)
+	injection.RegisterKey("knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/configmap", Key{})
 }
 
 // Key is used for associating the Informer inside the context.Context.
@@ -36,7 +38,11 @@ type Key struct{}
 func withInformer(ctx context.Context) (context.Context, controller.Informer) {
 	f := factory.Get(ctx)
 	inf := f.Core().V1().ConfigMaps()
-	return context.WithValue(ctx, Key{}, inf), inf.Informer()
+	return context.WithValue(ctx, Key{}, inf), controller.WithResource(inf.Informer(), schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "configmaps",
+	})
 }
 
 // Get extracts the typed informer from the context.
@@ -29,6 +29,7 @@ import (
 
 func init() {
 	injection.Default.RegisterInformerFactory(withInformerFactory)
+	injection.RegisterKey("knative.dev/pkg/injection/clients/namespacedkube/informers/factory", Key{})
 }
 
 // Key is used as the key for associating information with a context.Context.
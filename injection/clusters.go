@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"knative.dev/pkg/controller"
+)
+
+const (
+	// ClusterLabelKey is the label of the Secrets holding the kubeconfigs
+	// of the workload clusters, whose value is the logical name of the
+	// cluster.
+	ClusterLabelKey = "injection.knative.dev/cluster"
+
+	// KubeconfigSecretKey is the key of the kubeconfig in the data of the
+	// Secrets of the workload clusters.
+	KubeconfigSecretKey = "kubeconfig"
+)
+
+// ClusterConfigsFromSecrets returns the configs of the workload clusters,
+// keyed by their logical names, per the kubeconfigs of the Secrets labeled
+// with ClusterLabelKey, see KubeconfigSecretKey.
+func ClusterConfigsFromSecrets(ctx context.Context, client corev1client.SecretInterface) (map[string]*rest.Config, error) {
+	secrets, err := client.List(ctx, metav1.ListOptions{LabelSelector: ClusterLabelKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the Secrets of the clusters: %w", err)
+	}
+	cfgs := make(map[string]*rest.Config, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		name := secret.Labels[ClusterLabelKey]
+		if name == "" {
+			return nil, fmt.Errorf("the Secret %s/%s does not name its cluster", secret.Namespace, secret.Name)
+		}
+		if _, ok := cfgs[name]; ok {
+			return nil, fmt.Errorf("the cluster %q has several Secrets", name)
+		}
+		kubeconfig, ok := secret.Data[KubeconfigSecretKey]
+		if !ok {
+			return nil, fmt.Errorf("the Secret %s/%s has no %s", secret.Namespace, secret.Name, KubeconfigSecretKey)
+		}
+		cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the kubeconfig of the cluster %q: %w", name, err)
+		}
+		cfgs[name] = cfg
+	}
+	return cfgs, nil
+}
+
+// clustersKey is the key that the contexts of the clusters are associated
+// with.
+type clustersKey struct{}
+
+// clusterNameKey is the key that the name of a cluster is associated with
+// in its context.
+type clusterNameKey struct{}
+
+// SetupClusterInformers runs the injectors of the given Interface, e.g.
+// Default, against a context per cluster, with the config of the cluster,
+// as SetupInformers does for the cluster the process runs in. The contexts
+// of the clusters are then accessed with GetForCluster. The resulting
+// context is returned along with the informers of all the clusters, which
+// is suitable for passing to controller.StartInformers().
+func SetupClusterInformers(ctx context.Context, inj Interface, cfgs map[string]*rest.Config) (context.Context, []controller.Informer) {
+	clusters := make(map[string]context.Context, len(cfgs))
+	var informers []controller.Informer
+	for name, cfg := range cfgs {
+		clusterCtx := context.WithValue(ctx, clusterNameKey{}, name)
+		clusterCtx = WithConfig(clusterCtx, cfg)
+		clusterCtx, clusterInformers := inj.SetupInformers(clusterCtx, cfg)
+		clusters[name] = clusterCtx
+		informers = append(informers, clusterInformers...)
+	}
+	return context.WithValue(ctx, clustersKey{}, clusters), informers
+}
+
+// GetForCluster returns the context holding the clients and informers of
+// the named cluster, set up by SetupClusterInformers, e.g. for
+// kubeclient.Get(injection.GetForCluster(ctx, "west")), or nil if the
+// cluster is unknown. The context of a cluster otherwise holds the values of
+// the context it was set up from.
+func GetForCluster(ctx context.Context, name string) context.Context {
+	clusters, _ := ctx.Value(clustersKey{}).(map[string]context.Context)
+	return clusters[name]
+}
+
+// GetClusterNames returns the names of the clusters set up by
+// SetupClusterInformers, sorted.
+func GetClusterNames(ctx context.Context) []string {
+	clusters, _ := ctx.Value(clustersKey{}).(map[string]context.Context)
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetClusterName returns the name of the cluster of the context returned by
+// GetForCluster, or "" for the cluster the process runs in.
+func GetClusterName(ctx context.Context) string {
+	value := ctx.Value(clusterNameKey{})
+	if value == nil {
+		return ""
+	}
+	return value.(string)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	"knative.dev/pkg/controller"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://%s.example.com
+contexts:
+- name: context
+  context:
+    cluster: cluster
+    user: user
+current-context: context
+users:
+- name: user
+  user:
+    token: secret
+`
+
+func clusterSecret(name, cluster, kubeconfig string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "system",
+			Name:      name,
+			Labels:    map[string]string{ClusterLabelKey: cluster},
+		},
+		Data: map[string][]byte{KubeconfigSecretKey: []byte(kubeconfig)},
+	}
+}
+
+func TestClusterConfigsFromSecrets(t *testing.T) {
+	kubeconfig := func(cluster string) string {
+		return fmt.Sprintf(testKubeconfig, cluster)
+	}
+	unlabeled := clusterSecret("unlabeled", "", "invalid")
+	unlabeled.Labels = nil
+	tests := []struct {
+		name    string
+		secrets []*corev1.Secret
+		want    map[string]string
+		wantErr bool
+	}{{
+		name: "clusters",
+		secrets: []*corev1.Secret{
+			clusterSecret("east", "east", kubeconfig("east")),
+			clusterSecret("west", "west", kubeconfig("west")),
+			unlabeled,
+		},
+		want: map[string]string{
+			"east": "https://east.example.com",
+			"west": "https://west.example.com",
+		},
+	}, {
+		name:    "no cluster name",
+		secrets: []*corev1.Secret{clusterSecret("east", "", kubeconfig("east"))},
+		wantErr: true,
+	}, {
+		name: "same cluster",
+		secrets: []*corev1.Secret{
+			clusterSecret("east", "east", kubeconfig("east")),
+			clusterSecret("east-again", "east", kubeconfig("east")),
+		},
+		wantErr: true,
+	}, {
+		name:    "invalid kubeconfig",
+		secrets: []*corev1.Secret{clusterSecret("east", "east", "invalid")},
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fakekube.NewSimpleClientset()
+			for _, s := range tc.secrets {
+				if _, err := client.CoreV1().Secrets(s.Namespace).Create(context.Background(), s, metav1.CreateOptions{}); err != nil {
+					t.Fatal("Create() =", err)
+				}
+			}
+
+			cfgs, err := ClusterConfigsFromSecrets(context.Background(), client.CoreV1().Secrets("system"))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ClusterConfigsFromSecrets() = %v, wanted error: %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			got := make(map[string]string, len(cfgs))
+			for name, cfg := range cfgs {
+				got[name] = cfg.Host
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Error("Hosts (-want, +got) =", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+// hostKey is the key that injectHost associates the host of the config of
+// the cluster with.
+type hostKey struct{}
+
+func injectHost(ctx context.Context, cfg *rest.Config) context.Context {
+	return context.WithValue(ctx, hostKey{}, cfg.Host)
+}
+
+func TestSetupClusterInformers(t *testing.T) {
+	i := &impl{}
+	i.RegisterClient(injectHost)
+	i.RegisterInformer(func(ctx context.Context) (context.Context, controller.Informer) {
+		return ctx, &fakeInformer{}
+	})
+
+	ctx := injectHost(context.Background(), &rest.Config{Host: "management"})
+	ctx, informers := SetupClusterInformers(ctx, i, map[string]*rest.Config{
+		"east": {Host: "east"},
+		"west": {Host: "west"},
+	})
+
+	if got, want := len(informers), 2; got != want {
+		t.Errorf("len(informers) = %d, wanted %d", got, want)
+	}
+	if got, want := GetClusterNames(ctx), []string{"east", "west"}; !cmp.Equal(got, want) {
+		t.Error("GetClusterNames() (-want, +got) =", cmp.Diff(want, got))
+	}
+	if got := ctx.Value(hostKey{}); got != "management" {
+		t.Errorf("Host = %v, wanted management", got)
+	}
+	if got := GetClusterName(ctx); got != "" {
+		t.Errorf("GetClusterName() = %q, wanted none", got)
+	}
+	for _, name := range []string{"east", "west"} {
+		clusterCtx := GetForCluster(ctx, name)
+		if clusterCtx == nil {
+			t.Fatalf("GetForCluster(%s) = nil", name)
+		}
+		if got := clusterCtx.Value(hostKey{}); got != name {
+			t.Errorf("Host of %s = %v, wanted %s", name, got, name)
+		}
+		if got := GetClusterName(clusterCtx); got != name {
+			t.Errorf("GetClusterName() = %q, wanted %s", got, name)
+		}
+		if got := GetConfig(clusterCtx); got == nil || got.Host != name {
+			t.Errorf("GetConfig() = %v, wanted the config of %s", got, name)
+		}
+	}
+	if got := GetForCluster(ctx, "north"); got != nil {
+		t.Error("GetForCluster(north) =", got)
+	}
+}
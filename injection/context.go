@@ -20,6 +20,8 @@ import (
 	"context"
 
 	"k8s.io/client-go/rest"
+
+	"knative.dev/pkg/controller"
 )
 
 // nsKey is the key that namespaces are associated with on
@@ -83,3 +85,22 @@ func GetResourceVersion(ctx context.Context) string {
 	}
 	return value.(string)
 }
+
+// informersKey is the key that the informers set up by
+// EnableInjectionOrDie are associated with.
+type informersKey struct{}
+
+// WithInformers associates the given informers with the context.
+func WithInformers(ctx context.Context, informers []controller.Informer) context.Context {
+	return context.WithValue(ctx, informersKey{}, informers)
+}
+
+// GetInformers gets the informers associated with the context, which
+// are the ones started by the callback of EnableInjectionOrDie.
+func GetInformers(ctx context.Context) []controller.Informer {
+	value := ctx.Value(informersKey{})
+	if value == nil {
+		return nil
+	}
+	return value.([]controller.Informer)
+}
@@ -21,6 +21,9 @@ import (
 	"testing"
 
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/controller"
 )
 
 func TestContextNamespace(t *testing.T) {
@@ -71,3 +74,18 @@ func TestResourceVersion(t *testing.T) {
 		t.Errorf("GetResourceVersion() = %v, wanted %v", got, want)
 	}
 }
+
+func TestContextInformers(t *testing.T) {
+	ctx := context.Background()
+
+	if got := GetInformers(ctx); got != nil {
+		t.Errorf("GetInformers() = %v, wanted nil", got)
+	}
+
+	want := []controller.Informer{cache.NewSharedInformer(nil, nil, 0)}
+	ctx = WithInformers(ctx, want)
+
+	if got := GetInformers(ctx); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("GetInformers() = %v, wanted %v", got, want)
+	}
+}
@@ -95,5 +95,6 @@ func (i *impl) SetupInformers(ctx context.Context, cfg *rest.Config) (context.Co
 		informers = append(informers, filteredinfs...)
 
 	}
+	setTransform(ctx, informers)
 	return ctx, informers
 }
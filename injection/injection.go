@@ -58,6 +58,7 @@ func EnableInjectionOrDie(ctx context.Context, cfg *rest.Config) (context.Contex
 	ctx = WithConfig(ctx, cfg)
 
 	ctx, informers := Default.SetupInformers(ctx, cfg)
+	ctx = WithInformers(ctx, informers)
 
 	return ctx, func() {
 		logging.FromContext(ctx).Info("Starting informers...")
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// keyRegistry holds the context keys registered with RegisterKey.
+var keyRegistry = struct {
+	m      sync.RWMutex
+	byName map[string]interface{}
+	byKey  map[interface{}]string
+}{
+	byName: make(map[string]interface{}),
+	byKey:  make(map[interface{}]string),
+}
+
+// RegisterKey registers key, the key with which a value is injected into
+// contexts, under a friendly name, which by convention is the import path of
+// the package injecting the value. Registered keys are listed by Dump.
+//
+// RegisterKey panics if the name is already registered with another key, or
+// the key under another name, so that colliding injections are detected when
+// the packages are initialized rather than when the values are fetched.
+func RegisterKey(name string, key interface{}) {
+	if key == nil || !reflect.TypeOf(key).Comparable() {
+		panic(fmt.Sprintf("injection key %q of type %T is not comparable", name, key))
+	}
+
+	keyRegistry.m.Lock()
+	defer keyRegistry.m.Unlock()
+
+	if existing, ok := keyRegistry.byName[name]; ok {
+		if existing == key {
+			return
+		}
+		panic(fmt.Sprintf("injection key %q is already registered with key %T", name, existing))
+	}
+	if existing, ok := keyRegistry.byKey[key]; ok {
+		panic(fmt.Sprintf("injection key %T is already registered as %q, cannot register it as %q", key, existing, name))
+	}
+	keyRegistry.byName[name] = key
+	keyRegistry.byKey[key] = name
+}
+
+// InjectedKey describes a registered key and the value injected with it into
+// a context.
+type InjectedKey struct {
+	// Name is the name the key is registered under.
+	Name string
+
+	// Type is the type of the value injected with the key, or empty if the
+	// context holds no value for the key.
+	Type string
+}
+
+// Injected returns whether a value is injected with the key.
+func (ik InjectedKey) Injected() bool {
+	return ik.Type != ""
+}
+
+// String implements fmt.Stringer.
+func (ik InjectedKey) String() string {
+	if !ik.Injected() {
+		return ik.Name + ": <not injected>"
+	}
+	return ik.Name + ": " + ik.Type
+}
+
+// Dump returns the registered keys sorted by name, along with the type of
+// the values injected with them into the given context. This is meant to
+// troubleshoot values missing from the context, e.g. because the package
+// injecting them isn't linked into the binary.
+func Dump(ctx context.Context) []InjectedKey {
+	keyRegistry.m.RLock()
+	defer keyRegistry.m.RUnlock()
+
+	ret := make([]InjectedKey, 0, len(keyRegistry.byName))
+	for name, key := range keyRegistry.byName {
+		ik := InjectedKey{Name: name}
+		if v := ctx.Value(key); v != nil {
+			ik.Type = fmt.Sprintf("%T", v)
+		}
+		ret = append(ret, ik)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"testing"
+)
+
+type (
+	fooKey       struct{}
+	barKey       struct{}
+	bazKey       struct{}
+	selectorKey  struct{ selector string }
+	unregistered struct{}
+)
+
+func TestRegisterKey(t *testing.T) {
+	RegisterKey("test/foo", fooKey{})
+	// Registering the same key under the same name again is a no-op.
+	RegisterKey("test/foo", fooKey{})
+	RegisterKey("test/selector", selectorKey{selector: "a=b"})
+
+	tests := []struct {
+		name    string
+		keyName string
+		key     interface{}
+	}{{
+		name:    "name collision",
+		keyName: "test/foo",
+		key:     barKey{},
+	}, {
+		name:    "key collision",
+		keyName: "test/other-foo",
+		key:     fooKey{},
+	}, {
+		name:    "key collision with field",
+		keyName: "test/other-selector",
+		key:     selectorKey{selector: "a=b"},
+	}, {
+		name:    "nil key",
+		keyName: "test/nil",
+	}, {
+		name:    "non-comparable key",
+		keyName: "test/slice",
+		key:     []string{"a"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("RegisterKey(%q, %T) did not panic", tc.keyName, tc.key)
+				}
+			}()
+			RegisterKey(tc.keyName, tc.key)
+		})
+	}
+}
+
+func TestDump(t *testing.T) {
+	RegisterKey("test/dump/baz", bazKey{})
+	RegisterKey("test/dump/selector", selectorKey{selector: "c=d"})
+
+	ctx := context.WithValue(context.Background(), bazKey{}, "value")
+	ctx = context.WithValue(ctx, unregistered{}, 42)
+
+	got := map[string]InjectedKey{}
+	var names []string
+	for _, ik := range Dump(ctx) {
+		got[ik.Name] = ik
+		names = append(names, ik.Name)
+	}
+
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Errorf("Dump() is not sorted by name: %v", names)
+			break
+		}
+	}
+
+	if ik := got["test/dump/baz"]; !ik.Injected() || ik.Type != "string" {
+		t.Errorf("Dump()[test/dump/baz] = %v, wanted an injected string", ik)
+	}
+	if ik, ok := got["test/dump/selector"]; !ok || ik.Injected() {
+		t.Errorf("Dump()[test/dump/selector] = %v, wanted a registered key without value", ik)
+	}
+	if got, want := got["test/dump/baz"].String(), "test/dump/baz: string"; got != want {
+		t.Errorf("String() = %q, wanted %q", got, want)
+	}
+	if got, want := got["test/dump/selector"].String(), "test/dump/selector: <not injected>"; got != want {
+		t.Errorf("String() = %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedmain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/system"
+)
+
+const (
+	// TerminationMessagePathEnvKey is the environment variable overriding
+	// where the diagnostics bundle is written when a component dies.
+	TerminationMessagePathEnvKey = "K_TERMINATION_MESSAGE_PATH"
+
+	// DefaultTerminationMessagePath is the default terminationMessagePath
+	// of Kubernetes containers.
+	DefaultTerminationMessagePath = "/dev/termination-log"
+
+	// maxTerminationMessageSize is the size above which Kubernetes
+	// truncates termination messages.
+	maxTerminationMessageSize = 4096
+
+	// maxRecentErrors is the number of error logs kept for the bundle.
+	maxRecentErrors = 10
+
+	// configMapsTimeout bounds the time spent reading the configmaps
+	// snapshot, since the API server may be what we failed to reach.
+	configMapsTimeout = 2 * time.Second
+)
+
+// Diagnostics is the bundle written to stderr and to the termination
+// message path when a component dies, to help triaging CrashLoopBackOffs.
+type Diagnostics struct {
+	// Component is the name of the component which died.
+	Component string `json:"component"`
+
+	// Time is when the component died.
+	Time time.Time `json:"time"`
+
+	// Message is the message of the fatal log.
+	Message string `json:"message"`
+
+	// Error is the error attached to the fatal log, if any.
+	Error string `json:"error,omitempty"`
+
+	// Env is a summary of the Knative related environment variables.
+	// Values of variables which look sensitive are redacted.
+	Env map[string]string `json:"env,omitempty"`
+
+	// ConfigMaps holds the SHA-256 of the data of the configmaps in the
+	// system namespace, keyed by name, or the error preventing reading them.
+	ConfigMaps map[string]string `json:"configMaps,omitempty"`
+
+	// Informers is the sync state of the injected informers.
+	Informers *InformersStatus `json:"informers,omitempty"`
+
+	// RecentErrors are the last errors logged before dying, oldest first.
+	RecentErrors []string `json:"recentErrors,omitempty"`
+}
+
+// InformersStatus summarizes the sync state of the injected informers.
+type InformersStatus struct {
+	Total  int `json:"total"`
+	Synced int `json:"synced"`
+}
+
+// WithDiagnostics returns a logger which records its recent errors, and
+// writes a Diagnostics bundle before exiting on fatal logs. The context is
+// expected to be initialized with injection.
+func WithDiagnostics(ctx context.Context, component string, logger *zap.SugaredLogger) *zap.SugaredLogger {
+	d := &diagnosticsHook{
+		ctx:       ctx,
+		component: component,
+		path:      terminationMessagePath(),
+		stderr:    os.Stderr,
+		exit:      zapcore.WriteThenFatal,
+	}
+	return logger.WithOptions(zap.Hooks(d.record), zap.WithFatalHook(d))
+}
+
+// diagnosticsHook is the zap hook building the Diagnostics bundle.
+type diagnosticsHook struct {
+	ctx       context.Context
+	component string
+	path      string
+	stderr    io.Writer
+	// exit is chained after writing the bundle to stop the control flow.
+	exit zapcore.CheckWriteHook
+
+	mu     sync.Mutex
+	recent []string
+}
+
+var _ zapcore.CheckWriteHook = (*diagnosticsHook)(nil)
+
+func (d *diagnosticsHook) record(e zapcore.Entry) error {
+	if e.Level < zapcore.ErrorLevel {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recent = append(d.recent, e.Message)
+	if len(d.recent) > maxRecentErrors {
+		d.recent = d.recent[len(d.recent)-maxRecentErrors:]
+	}
+	return nil
+}
+
+// OnWrite implements zapcore.CheckWriteHook
+func (d *diagnosticsHook) OnWrite(ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	var err error
+	for _, f := range fields {
+		if e, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType {
+			err = e
+			break
+		}
+	}
+
+	d.mu.Lock()
+	recent := append([]string(nil), d.recent...)
+	d.mu.Unlock()
+	// The fatal entry itself has already been recorded as a recent error.
+	if n := len(recent); n > 0 && recent[n-1] == ce.Message {
+		recent = recent[:n-1]
+	}
+
+	diag := collectDiagnostics(d.ctx, d.component, ce.Message, err)
+	diag.Time = ce.Time
+	diag.RecentErrors = recent
+	diag.write(d.stderr, d.path)
+
+	d.exit.OnWrite(ce, fields)
+}
+
+// writeDiagnostics writes the Diagnostics bundle for failures happening
+// before the logger is set up.
+func writeDiagnostics(ctx context.Context, component, msg string, err error) {
+	collectDiagnostics(ctx, component, msg, err).write(os.Stderr, terminationMessagePath())
+}
+
+func collectDiagnostics(ctx context.Context, component, msg string, err error) *Diagnostics {
+	diag := &Diagnostics{
+		Component: component,
+		Time:      time.Now(),
+		Message:   msg,
+		Env:       envSummary(os.Environ()),
+	}
+	if err != nil {
+		diag.Error = err.Error()
+	}
+	if infs := injection.GetInformers(ctx); len(infs) > 0 {
+		diag.Informers = &InformersStatus{Total: len(infs)}
+		for _, inf := range infs {
+			if inf.HasSynced() {
+				diag.Informers.Synced++
+			}
+		}
+	}
+	if ctx.Value(kubeclient.Key{}) != nil {
+		diag.ConfigMaps = configMapHashes(ctx)
+	}
+	return diag
+}
+
+// envSummary returns the Knative related environment variables.
+func envSummary(environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		if !isDiagnosticEnv(k) {
+			continue
+		}
+		if isSensitiveEnv(k) {
+			v = "<redacted>"
+		}
+		env[k] = v
+	}
+	return env
+}
+
+func isDiagnosticEnv(k string) bool {
+	switch k {
+	case system.NamespaceEnvKey, system.ResourceLabelEnvKey, "POD_NAME", "GOMAXPROCS", "METRICS_DOMAIN", "KUBERNETES_SERVICE_HOST":
+		return true
+	}
+	return strings.HasPrefix(k, "K_") || strings.HasPrefix(k, "CONFIG_") || strings.HasPrefix(k, "WEBHOOK_")
+}
+
+func isSensitiveEnv(k string) bool {
+	k = strings.ToUpper(k)
+	if strings.HasSuffix(k, "_NAME") {
+		// e.g. WEBHOOK_SECRET_NAME only names the secret.
+		return false
+	}
+	for _, s := range []string{"SECRET", "TOKEN", "PASSWORD", "CREDENTIAL", "KEY"} {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// configMapHashes returns the SHA-256 of the data of each configmap in the
+// system namespace, so config drift can be spotted without leaking it.
+func configMapHashes(ctx context.Context) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, configMapsTimeout)
+	defer cancel()
+
+	cms, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return map[string]string{"<error>": err.Error()}
+	}
+	hashes := make(map[string]string, len(cms.Items))
+	for _, cm := range cms.Items {
+		h := sha256.New()
+		for _, k := range sets.StringKeySet(cm.Data).List() {
+			fmt.Fprintf(h, "%s=%s\n", k, cm.Data[k])
+		}
+		for _, k := range sets.StringKeySet(cm.BinaryData).List() {
+			fmt.Fprintf(h, "%s=%x\n", k, cm.BinaryData[k])
+		}
+		hashes[cm.Name] = hex.EncodeToString(h.Sum(nil))
+	}
+	return hashes
+}
+
+// write writes the bundle to w, and to the termination message path if it
+// exists. The latter is trimmed of its least useful parts to fit the size
+// Kubernetes keeps.
+func (d *Diagnostics) write(w io.Writer, path string) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "%s\n", b)
+
+	if path == "" {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	trimmed := *d
+	for _, trim := range []func(){
+		func() { trimmed.ConfigMaps = nil },
+		func() { trimmed.Env = nil },
+		func() { trimmed.RecentErrors = nil },
+	} {
+		if len(b) <= maxTerminationMessageSize {
+			break
+		}
+		trim()
+		if b, err = json.Marshal(trimmed); err != nil {
+			return
+		}
+	}
+	if len(b) > maxTerminationMessageSize {
+		b = b[:maxTerminationMessageSize]
+	}
+	os.WriteFile(path, b, 0o644) //nolint:errcheck // Best effort, we are dying anyway.
+}
+
+func terminationMessagePath() string {
+	if p, ok := os.LookupEnv(TerminationMessagePathEnvKey); ok {
+		return p
+	}
+	return DefaultTerminationMessagePath
+}
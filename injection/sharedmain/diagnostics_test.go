@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedmain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/system"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+type fakeInformer bool
+
+func (fakeInformer) Run(<-chan struct{}) {}
+
+func (f fakeInformer) HasSynced() bool { return bool(f) }
+
+func TestDiagnosticsOnFatal(t *testing.T) {
+	t.Setenv("K_THREADS_PER_CONTROLLER", "2")
+	t.Setenv("K_SINK_TOKEN", "hunter2")
+
+	ctx, _ := fakekubeclient.With(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "config-logging",
+			Namespace: system.Namespace(),
+		},
+		Data: map[string]string{"loglevel.controller": "debug"},
+	})
+	ctx = injection.WithInformers(ctx, []controller.Informer{fakeInformer(true), fakeInformer(false)})
+
+	path := filepath.Join(t.TempDir(), "termination-log")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal("WriteFile() =", err)
+	}
+	var stderr bytes.Buffer
+	d := &diagnosticsHook{
+		ctx:       ctx,
+		component: "controller",
+		path:      path,
+		stderr:    &stderr,
+		exit:      zapcore.WriteThenGoexit,
+	}
+	logger := zaptest.NewLogger(t, zaptest.WrapOptions(zap.Hooks(d.record), zap.WithFatalHook(d))).Sugar()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Info("Starting")
+		logger.Error("Something went wrong")
+		logger.Fatalw("Failed to start informers", zap.Error(errors.New("timed out")))
+		t.Error("Fatalw() did not stop the control flow")
+	}()
+	<-done
+
+	var got Diagnostics
+	if err := json.Unmarshal(stderr.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal(%s) = %v", stderr.String(), err)
+	}
+	if got.Component != "controller" || got.Message != "Failed to start informers" || got.Error != "timed out" {
+		t.Errorf("Diagnostics = %+v", got)
+	}
+	if want := []string{"Something went wrong"}; !cmp.Equal(got.RecentErrors, want) {
+		t.Error("RecentErrors (-want, +got) =", cmp.Diff(want, got.RecentErrors))
+	}
+	if want := (&InformersStatus{Total: 2, Synced: 1}); !cmp.Equal(got.Informers, want) {
+		t.Error("Informers (-want, +got) =", cmp.Diff(want, got.Informers))
+	}
+	if got.Env["K_THREADS_PER_CONTROLLER"] != "2" || got.Env["K_SINK_TOKEN"] != "<redacted>" {
+		t.Errorf("Env = %v", got.Env)
+	}
+	if h := got.ConfigMaps["config-logging"]; len(h) != 64 {
+		t.Errorf("ConfigMaps = %v, want a SHA-256 for config-logging", got.ConfigMaps)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("ReadFile() =", err)
+	}
+	if !bytes.Equal(b, bytes.TrimSpace(stderr.Bytes())) {
+		t.Errorf("termination message = %s, want: %s", b, stderr.String())
+	}
+}
+
+func TestDiagnosticsWriteTrimmed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "termination-log")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal("WriteFile() =", err)
+	}
+
+	d := &Diagnostics{
+		Component:    "webhook",
+		Message:      "Failed to create webhook",
+		ConfigMaps:   map[string]string{"big": strings.Repeat("x", maxTerminationMessageSize)},
+		RecentErrors: []string{"boom"},
+	}
+	var stderr bytes.Buffer
+	d.write(&stderr, path)
+
+	if !strings.Contains(stderr.String(), `"big"`) {
+		t.Error("stderr lacks the configmaps")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("ReadFile() =", err)
+	}
+	var got Diagnostics
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal(%s) = %v", b, err)
+	}
+	if got.ConfigMaps != nil || !cmp.Equal(got.RecentErrors, []string{"boom"}) {
+		t.Errorf("termination message = %s, want it trimmed of configmaps only", b)
+	}
+
+	// A missing termination message path is not created.
+	missing := filepath.Join(t.TempDir(), "missing")
+	d.write(&stderr, missing)
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Stat() = %v, want: not exist", err)
+	}
+}
@@ -18,6 +18,7 @@ package sharedmain
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
@@ -127,6 +128,10 @@ func GetObservabilityConfig(ctx context.Context) (*metrics.ObservabilityConfig,
 // Both Context and Config are optional.
 // Deprecated: use injection.EnableInjectionOrDie
 func EnableInjectionOrDie(ctx context.Context, cfg *rest.Config) context.Context {
+	// Let the controllers and webhooks add shutdown hooks, run once the
+	// context is done.
+	ctx = signals.WithShutdownHooks(ctx)
+
 	ctx, startInformers := injection.EnableInjectionOrDie(ctx, cfg)
 	go startInformers()
 	return ctx
@@ -155,7 +160,9 @@ func MainNamed(ctx context.Context, component string, ctors ...injection.NamedCo
 	disabledControllers := flag.String("disable-controllers", "", "Comma-separated list of disabled controllers.")
 
 	// HACK: This parses flags, so the above should be set once this runs.
+	parsed := metrics.StartPhase(metrics.PhaseFlagParse, "")
 	cfg := injection.ParseAndGetRESTConfigOrDie()
+	parsed(ctx)
 
 	enabledCtors := enabledControllers(strings.Split(*disabledControllers, ","), ctors)
 	MainWithConfig(ctx, component, cfg, toControllerConstructors(enabledCtors)...)
@@ -201,7 +208,9 @@ func MainWithContext(ctx context.Context, component string, ctors ...injection.C
 			"issue upstream!")
 
 	// HACK: This parses flags, so the above should be set once this runs.
+	parsed := metrics.StartPhase(metrics.PhaseFlagParse, "")
 	cfg := injection.ParseAndGetRESTConfigOrDie()
+	parsed(ctx)
 
 	if *disableHighAvailability {
 		ctx = WithHADisabled(ctx)
@@ -210,6 +219,18 @@ func MainWithContext(ctx context.Context, component string, ctors ...injection.C
 	MainWithConfig(ctx, component, cfg, ctors...)
 }
 
+// informerMemoryPath is the debug endpoint of the profiling server serving
+// the memory estimates of the informer caches.
+const informerMemoryPath = "/debug/informers"
+
+// processStart approximates the start of the process, from which the
+// startup is timed.
+var processStart = time.Now()
+
+// shutdownHooksTimeout bounds the time the shutdown hooks, added with
+// signals.OnShutdown, have to complete, e.g. to drain the connections.
+const shutdownHooksTimeout = 30 * time.Second
+
 type haDisabledKey struct{}
 
 // WithHADisabled signals to MainWithConfig that it should not set up an appropriate leader elector for this component.
@@ -241,11 +262,25 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 		cfg.Burst = len(ctors) * rest.DefaultBurst
 	}
 
+	// Let the informers whose watch starves be forced to re-list, if enabled.
+	var relister *controller.RelistTransport
+	if informerHealthChecks(ctx) {
+		relister = &controller.RelistTransport{}
+		cfg.Wrap(relister.Wrap)
+	}
+
+	clientInit := time.Now()
 	ctx, startInformers := injection.EnableInjectionOrDie(ctx, cfg)
+	clientInitDuration := time.Since(clientInit)
 
 	logger, atomicLevel := SetupLoggerOrDie(ctx, component)
+	// Write a diagnostics bundle if we die, to ease triaging crash loops.
+	logger = WithDiagnostics(ctx, component, logger)
 	defer flush(logger)
 	ctx = logging.WithLogger(ctx, logger)
+	metrics.RecordPhase(ctx, metrics.PhaseClientInit, "", clientInitDuration)
+	// Help troubleshooting values missing from the context.
+	logger.Debugw("Injected context keys", "keys", injection.Dump(ctx))
 
 	// Override client-go's warning handler to give us nicely printed warnings.
 	rest.SetDefaultWarningHandler(&logging.WarningHandler{Logger: logger})
@@ -273,6 +308,9 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 	controllers, webhooks := ControllersAndWebhooksFromCtors(ctx, cmw, ctors...)
 	WatchLoggingConfigOrDie(ctx, cmw, logger, atomicLevel, component)
 	WatchObservabilityConfigOrDie(ctx, cmw, profilingHandler, logger, component)
+	if name := pauseConfigMap(ctx); name != "" {
+		WatchPauseConfigMap(cmw, name, controllers...)
+	}
 
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(profilingServer.ListenAndServe)
@@ -301,14 +339,57 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 		})
 	}
 
-	// Start the injection clients and informers.
+	// Start the additional servers, sharing the TLS configuration and the
+	// drain behavior of the webhook.
+	var (
+		tlsConfig   *tls.Config
+		gracePeriod time.Duration
+	)
+	if wh != nil {
+		tlsConfig, gracePeriod = wh.TLSConfig(), wh.Options.GracePeriod
+	}
+	for _, s := range serversFrom(ctx) {
+		s := s
+		eg.Go(func() error {
+			return runServer(egCtx, s, tlsConfig, gracePeriod)
+		})
+	}
+
+	// Start the injection clients and informers, timing their syncs.
+	controller.RecordInformerSyncs(ctx, injection.GetInformers(ctx)...)
 	startInformers()
 
+	// Estimate the memory held by the informer caches, if enabled, to tell
+	// which ones grow the controller's memory.
+	if informerMemoryAccounting(ctx) {
+		accountant := &controller.InformerMemoryAccountant{}
+		for _, informer := range injection.GetInformers(ctx) {
+			if si, ok := informer.(controller.StoreInformer); ok {
+				accountant.Add("", si)
+			}
+		}
+		profilingHandler.Handle(informerMemoryPath, accountant)
+		eg.Go(func() error {
+			accountant.Run(ctx)
+			return nil
+		})
+	}
+
+	// Re-list the informers whose watch silently stops delivering events,
+	// e.g. after a network partition, rather than leaving their caches stale.
+	if relister != nil {
+		eg.Go(func() error {
+			controller.RunInformerHealthCheckers(ctx, relister.ForceRelist, injection.GetInformers(ctx)...)
+			return nil
+		})
+	}
+
 	// Wait for webhook informers to sync.
 	if wh != nil {
 		wh.InformersHaveSynced()
 	}
 	logger.Info("Starting controllers...")
+	metrics.RecordPhase(ctx, metrics.PhaseStartup, "", time.Since(processStart))
 	eg.Go(func() error {
 		return controller.StartAll(ctx, controllers...)
 	})
@@ -324,11 +405,18 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 	// returns an error.
 	<-egCtx.Done()
 
+	hooksRun := metrics.StartPhase(metrics.PhaseShutdownHooks, "")
+	if err := signals.RunShutdownHooks(ctx, shutdownHooksTimeout); err != nil {
+		logger.Errorw("Error while running shutdown hooks", zap.Error(err))
+	}
+	hooksRun(ctx)
+	drained := metrics.StartPhase(metrics.PhaseShutdownDrain, "")
 	profilingServer.Shutdown(context.Background())
 	// Don't forward ErrServerClosed as that indicates we're already shutting down.
 	if err := eg.Wait(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Errorw("Error while running server", zap.Error(err))
 	}
+	drained(ctx)
 }
 
 type healthProbesDisabledKey struct{}
@@ -342,6 +430,60 @@ func healthProbesDisabled(ctx context.Context) bool {
 	return ctx.Value(healthProbesDisabledKey{}) != nil
 }
 
+type informerHealthChecksKey struct{}
+
+// WithInformerHealthChecks signals to MainWithConfig that it should check
+// that the watches of the informers keep progressing, and force those of the
+// resources whose informers starve to re-list, see
+// controller.InformerHealthChecker. The informers are expected to progress,
+// through events or bookmarks, within controller.DefaultStarvationThreshold.
+func WithInformerHealthChecks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, informerHealthChecksKey{}, struct{}{})
+}
+
+func informerHealthChecks(ctx context.Context) bool {
+	return ctx.Value(informerHealthChecksKey{}) != nil
+}
+
+type informerMemoryAccountingKey struct{}
+
+// WithInformerMemoryAccounting signals to MainWithConfig that it should
+// estimate the memory held by the caches of the informers, named after their
+// resources, which are reported as metrics and served by the profiling
+// server under /debug/informers. The estimates sample the caches
+// periodically, see controller.InformerMemoryAccountant.
+func WithInformerMemoryAccounting(ctx context.Context) context.Context {
+	return context.WithValue(ctx, informerMemoryAccountingKey{}, struct{}{})
+}
+
+func informerMemoryAccounting(ctx context.Context) bool {
+	return ctx.Value(informerMemoryAccountingKey{}) != nil
+}
+
+type pauseConfigMapKey struct{}
+
+// WithPauseConfigMap signals to MainWithConfig that it should pause and
+// resume the controllers per the ConfigMap of the given name in the system
+// namespace, listing the names of the controllers to pause under
+// controller.PausedControllersKey. The controllers run while the ConfigMap
+// does not exist.
+func WithPauseConfigMap(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, pauseConfigMapKey{}, name)
+}
+
+func pauseConfigMap(ctx context.Context) string {
+	name, _ := ctx.Value(pauseConfigMapKey{}).(string)
+	return name
+}
+
+// WatchPauseConfigMap pauses and resumes the controllers per the ConfigMap of
+// the given name, see WithPauseConfigMap.
+func WatchPauseConfigMap(cmw *cminformer.InformedWatcher, name string, controllers ...*controller.Impl) {
+	cmw.WatchWithDefault(corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: system.Namespace()},
+	}, controller.UpdatePausedFromConfigMap(controllers...))
+}
+
 func flush(logger *zap.SugaredLogger) {
 	logger.Sync()
 	metrics.FlushExporter()
@@ -352,6 +494,7 @@ func flush(logger *zap.SugaredLogger) {
 func SetupLoggerOrDie(ctx context.Context, component string) (*zap.SugaredLogger, zap.AtomicLevel) {
 	loggingConfig, err := GetLoggingConfig(ctx)
 	if err != nil {
+		writeDiagnostics(ctx, component, "Error reading/parsing logging configuration", err)
 		log.Fatal("Error reading/parsing logging configuration: ", err)
 	}
 	l, level := logging.NewLoggerFromConfig(loggingConfig, component)
@@ -411,7 +554,9 @@ func SetupConfigMapWatchOrDie(ctx context.Context, logger *zap.SugaredLogger) *c
 func WatchLoggingConfigOrDie(ctx context.Context, cmw *cminformer.InformedWatcher, logger *zap.SugaredLogger, atomicLevel zap.AtomicLevel, component string) {
 	if _, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(ctx, logging.ConfigMapName(),
 		metav1.GetOptions{}); err == nil {
-		cmw.Watch(logging.ConfigMapName(), logging.UpdateLevelFromConfigMap(logger, atomicLevel, component))
+		cmw.Watch(logging.ConfigMapName(),
+			logging.UpdateLevelFromConfigMap(logger, atomicLevel, component),
+			logging.UpdateRedactionFromConfigMap(logger))
 	} else if !apierrors.IsNotFound(err) {
 		logger.Fatalw("Error reading ConfigMap "+logging.ConfigMapName(), zap.Error(err))
 	}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedmain
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/network/handlers"
+)
+
+// Server is an additional HTTP server run by MainWithConfig alongside the
+// webhook and profiling servers, e.g. for a custom admin API or a data-plane
+// endpoint. It answers the health checks until the process shuts down, then
+// drains and shuts down with the webhook.
+type Server struct {
+	// Name identifies the server in the logs.
+	Name string
+
+	// Port is the port to serve on.
+	Port int
+
+	// Handler serves the requests.
+	Handler http.Handler
+
+	// TLS serves with the TLS configuration of the webhook, whose certificate
+	// rotates with its secret. It requires the component to run webhooks
+	// serving with TLS.
+	TLS bool
+
+	// GracePeriod is how long to wait after failing readiness probes before
+	// shutting down. It defaults to the GracePeriod of the webhook, if any.
+	GracePeriod time.Duration
+
+	// listener is only used in testing so we don't get port conflicts.
+	listener net.Listener
+}
+
+type serversKey struct{}
+
+// WithServers registers additional HTTP servers for MainWithConfig to run,
+// with the lifecycle of the webhook. It adds to the servers already
+// registered on the context.
+func WithServers(ctx context.Context, servers ...Server) context.Context {
+	existing := serversFrom(ctx)
+	all := make([]Server, 0, len(existing)+len(servers))
+	all = append(append(all, existing...), servers...)
+	return context.WithValue(ctx, serversKey{}, all)
+}
+
+func serversFrom(ctx context.Context) []Server {
+	servers, _ := ctx.Value(serversKey{}).([]Server)
+	return servers
+}
+
+// runServer serves with the server until ctx is done, then drains it and
+// shuts it down. The tlsConfig is that of the webhook, if any, and its
+// gracePeriod the default of the server's.
+func runServer(ctx context.Context, s Server, tlsConfig *tls.Config, gracePeriod time.Duration) error {
+	logger := logging.FromContext(ctx).With(zap.String("server", s.Name))
+	if s.TLS && tlsConfig == nil {
+		return fmt.Errorf("server %q requires TLS, but the webhook serves without TLS", s.Name)
+	}
+	if !s.TLS {
+		tlsConfig = nil
+	}
+	if s.GracePeriod != 0 {
+		gracePeriod = s.GracePeriod
+	}
+
+	drainer := &handlers.Drainer{
+		Inner:       s.Handler,
+		QuietPeriod: gracePeriod,
+	}
+	server := &http.Server{
+		Addr:              fmt.Sprint(":", s.Port),
+		Handler:           logging.NewRequestLoggingHandler(logger, drainer),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: time.Minute,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		listener := s.listener
+		if listener == nil {
+			var err error
+			if listener, err = net.Listen("tcp", server.Addr); err != nil {
+				errCh <- err
+				return
+			}
+		}
+		if tlsConfig != nil {
+			errCh <- server.ServeTLS(listener, "", "")
+		} else {
+			errCh <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server %q failed: %w", s.Name, err)
+	case <-ctx.Done():
+	}
+
+	// As we start to shutdown, disable keep-alives to avoid clients hanging
+	// onto connections, and start failing readiness probes.
+	logger.Info("Draining server...")
+	server.SetKeepAlivesEnabled(false)
+	drainer.Drain()
+	if err := server.Shutdown(context.Background()); err != nil {
+		return fmt.Errorf("failed to shut down server %q: %w", s.Name, err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server %q failed: %w", s.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedmain
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestWithServers(t *testing.T) {
+	ctx := WithServers(context.Background(), Server{Name: "admin"})
+	ctx = WithServers(ctx, Server{Name: "data"}, Server{Name: "debug"})
+
+	servers := serversFrom(ctx)
+	if len(servers) != 3 || servers[0].Name != "admin" || servers[2].Name != "debug" {
+		t.Errorf("serversFrom() = %v, wanted admin, data and debug", servers)
+	}
+	if got := serversFrom(context.Background()); len(got) != 0 {
+		t.Errorf("serversFrom() = %v, wanted none", got)
+	}
+}
+
+func TestRunServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Listen() =", err)
+	}
+	s := Server{
+		Name: "admin",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "admin")
+		}),
+		GracePeriod: 10 * time.Millisecond,
+		listener:    listener,
+	}
+
+	ctx, cancel := context.WithCancel(logtesting.TestContextWithLogger(t))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- runServer(ctx, s, nil, time.Minute)
+	}()
+
+	url := "http://" + listener.Addr().String()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "admin" {
+		t.Errorf("Get() = %q, wanted %q", body, "admin")
+	}
+
+	// The health checks are answered by the drainer.
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("User-Agent", "kube-probe/1.26")
+	if resp, err := http.DefaultClient.Do(req); err != nil {
+		t.Fatal("probe =", err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusOK {
+		t.Errorf("probe = %d, wanted %d", resp.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Error("runServer() =", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("runServer() did not return after the context was done")
+	}
+}
+
+func TestRunServerRequiresTLS(t *testing.T) {
+	s := Server{Name: "data", TLS: true, Handler: http.NotFoundHandler()}
+	if err := runServer(logtesting.TestContextWithLogger(t), s, nil, 0); err == nil {
+		t.Error("runServer() = nil, wanted an error without the TLS configuration of the webhook")
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// transformKey is the key that the informer transform is associated with.
+type transformKey struct{}
+
+// WithInformerTransform associates a transform with the context, which the
+// informers set up by SetupInformers apply to the objects before caching
+// them, e.g. StripManagedFields. Transforms associated several times are
+// applied in order. The informers created outside of SetupInformers, e.g.
+// by the informer factories in the controller constructors, are not
+// affected.
+func WithInformerTransform(ctx context.Context, transform cache.TransformFunc) context.Context {
+	if prev := GetInformerTransform(ctx); prev != nil {
+		next := transform
+		transform = func(obj interface{}) (interface{}, error) {
+			obj, err := prev(obj)
+			if err != nil {
+				return nil, err
+			}
+			return next(obj)
+		}
+	}
+	return context.WithValue(ctx, transformKey{}, transform)
+}
+
+// GetInformerTransform gets the informer transform associated with the
+// context, or nil.
+func GetInformerTransform(ctx context.Context) cache.TransformFunc {
+	value := ctx.Value(transformKey{})
+	if value == nil {
+		return nil
+	}
+	return value.(cache.TransformFunc)
+}
+
+// StripManagedFields is an informer transform removing the managed fields
+// and the last applied configuration annotation of kubectl from the cached
+// objects, which are rarely used by controllers but often make up most of
+// the memory of their caches.
+func StripManagedFields(obj interface{}) (interface{}, error) {
+	// Objects without metadata, e.g. cache.DeletedFinalStateUnknown, are
+	// left alone.
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+		if annotations := accessor.GetAnnotations(); annotations != nil {
+			if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+				delete(annotations, corev1.LastAppliedConfigAnnotation)
+				accessor.SetAnnotations(annotations)
+			}
+		}
+	}
+	return obj, nil
+}
+
+// transformer is implemented by the informers supporting transforms, like
+// cache.SharedIndexInformer.
+type transformer interface {
+	SetTransform(cache.TransformFunc) error
+}
+
+// setTransform sets the informer transform of the context on the informers.
+func setTransform(ctx context.Context, informers []controller.Informer) {
+	transform := GetInformerTransform(ctx)
+	if transform == nil {
+		return
+	}
+	for _, inf := range informers {
+		t, ok := inf.(transformer)
+		if !ok {
+			continue
+		}
+		if err := t.SetTransform(transform); err != nil {
+			logging.FromContext(ctx).Warnw("Failed to set the informer transform", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/controller"
+)
+
+func TestStripManagedFields(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: `{"big":"json"}`,
+				"keep":                             "me",
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+	}
+
+	got, err := StripManagedFields(pod)
+	if err != nil {
+		t.Fatal("StripManagedFields() =", err)
+	}
+	want := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Annotations: map[string]string{"keep": "me"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("StripManagedFields() (-want, +got):", diff)
+	}
+
+	tombstone := cache.DeletedFinalStateUnknown{Key: "foo", Obj: pod}
+	if got, err := StripManagedFields(tombstone); err != nil || !cmp.Equal(got, tombstone) {
+		t.Errorf("StripManagedFields(tombstone) = %v, %v, wanted it unchanged", got, err)
+	}
+}
+
+func TestWithInformerTransform(t *testing.T) {
+	ctx := context.Background()
+	if GetInformerTransform(ctx) != nil {
+		t.Error("GetInformerTransform() != nil without a transform")
+	}
+
+	appender := func(s string) cache.TransformFunc {
+		return func(obj interface{}) (interface{}, error) {
+			return obj.(string) + s, nil
+		}
+	}
+	ctx = WithInformerTransform(ctx, appender("a"))
+	ctx = WithInformerTransform(ctx, appender("b"))
+	if got, err := GetInformerTransform(ctx)(""); err != nil || got != "ab" {
+		t.Errorf("GetInformerTransform()() = %v, %v, wanted ab", got, err)
+	}
+
+	boom := errors.New("boom")
+	failing := WithInformerTransform(WithInformerTransform(context.Background(),
+		func(interface{}) (interface{}, error) { return nil, boom }), appender("c"))
+	if _, err := GetInformerTransform(failing)(""); !errors.Is(err, boom) {
+		t.Errorf("GetInformerTransform()() = %v, wanted %v", err, boom)
+	}
+}
+
+type transformingInformer struct {
+	controller.Informer
+	transform cache.TransformFunc
+	err       error
+}
+
+func (i *transformingInformer) SetTransform(transform cache.TransformFunc) error {
+	i.transform = transform
+	return i.err
+}
+
+func TestSetupInformersTransform(t *testing.T) {
+	informer := &transformingInformer{}
+	started := &transformingInformer{err: errors.New("informer has already started")}
+	i := &impl{}
+	i.RegisterInformer(func(ctx context.Context) (context.Context, controller.Informer) {
+		return ctx, informer
+	})
+	i.RegisterFilteredInformers(func(ctx context.Context) (context.Context, []controller.Informer) {
+		return ctx, []controller.Informer{started, nil}
+	})
+
+	i.SetupInformers(context.Background(), &rest.Config{})
+	if informer.transform != nil {
+		t.Error("SetupInformers() set a transform without one in the context")
+	}
+
+	ctx := WithInformerTransform(context.Background(), StripManagedFields)
+	i.SetupInformers(ctx, &rest.Config{})
+	if informer.transform == nil || started.transform == nil {
+		t.Error("SetupInformers() did not set the transform of the context")
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kmeta
+
+import (
+	"crypto/md5" //nolint:gosec // No strong cryptography needed.
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SpecHashOptions customizes SpecHash.
+type SpecHashOptions struct {
+	// Labels are the keys of the labels included in the hash.
+	Labels []string
+
+	// Annotations are the keys of the annotations included in the hash.
+	Annotations []string
+
+	// UnorderedLists are the paths of the lists whose order is irrelevant,
+	// e.g. "spec.template.spec.containers.env", whose items are sorted
+	// before hashing. The paths are the names of the fields from the root of
+	// the resource, separated by dots, the lists on the way being traversed
+	// item by item.
+	UnorderedLists []string
+
+	// Encoding is the encoding of the hash, HexEncoding by default.
+	Encoding HashEncoding
+}
+
+// SpecHash returns a stable hash of the spec of the resource, i.e. of all
+// its fields but apiVersion, kind, metadata and status, e.g. to be stored in
+// an annotation of a child resource to detect when its desired spec changes,
+// or in its pod template to trigger a rollout. The spec is normalized first,
+// so that equivalent specs have the same hash: the fields are ordered, the
+// fields unset, i.e. null, empty strings, objects or lists, are dropped, and
+// the lists of opts.UnorderedLists are sorted. The other fields, including
+// false and 0, are kept, as they may differ from the defaults of the fields.
+func SpecHash(obj interface{}, opts SpecHashOptions) (string, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the resource: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return "", fmt.Errorf("failed to unmarshal the resource: %w", err)
+	}
+
+	spec := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		switch name {
+		case "apiVersion", "kind", "metadata", "status":
+		default:
+			spec[name] = value
+		}
+	}
+	for _, path := range opts.UnorderedLists {
+		if err := sortLists(spec, strings.Split(path, ".")); err != nil {
+			return "", fmt.Errorf("failed to sort %s: %w", path, err)
+		}
+	}
+
+	meta, _ := fields["metadata"].(map[string]interface{})
+	hashed := map[string]interface{}{
+		"spec": spec,
+		"metadata": map[string]interface{}{
+			"labels":      selectKeys(meta["labels"], opts.Labels),
+			"annotations": selectKeys(meta["annotations"], opts.Annotations),
+		},
+	}
+	// The keys of the maps are sorted by json.Marshal.
+	b, err = json.Marshal(normalize(hashed))
+	if err != nil {
+		return "", err
+	}
+	return opts.Encoding.encode(md5.Sum(b)), nil //nolint:gosec // No strong cryptography needed.
+}
+
+// selectKeys returns the values of the keys of the JSON object.
+func selectKeys(v interface{}, keys []string) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	selected := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if value, ok := m[key]; ok {
+			selected[key] = value
+		}
+	}
+	return selected
+}
+
+// normalize returns the JSON value without its unset fields, nil if it is
+// unset itself.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for name, value := range v {
+			if value := normalize(value); value != nil {
+				out[name] = value
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			// The items are kept in place, unset or not.
+			out[i] = normalize(value)
+		}
+		return out
+	case string:
+		if v == "" {
+			return nil
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// sortLists sorts the lists at the path of the JSON value, by the JSON
+// forms of their normalized items.
+func sortLists(v interface{}, path []string) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return nil
+		}
+		value, ok := v[path[0]]
+		if !ok {
+			return nil
+		}
+		if list, ok := value.([]interface{}); ok && len(path) == 1 {
+			return sortList(list)
+		}
+		return sortLists(value, path[1:])
+	case []interface{}:
+		for _, item := range v {
+			if err := sortLists(item, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortList(list []interface{}) error {
+	type item struct {
+		value interface{}
+		key   string
+	}
+	items := make([]item, len(list))
+	for i, value := range list {
+		b, err := json.Marshal(normalize(value))
+		if err != nil {
+			return err
+		}
+		items[i] = item{value: value, key: string(b)}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].key < items[j].key })
+	for i := range items {
+		list[i] = items[i].value
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kmeta
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func hashedDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Labels:      map[string]string{"app": "foo", "version": "1"},
+			Annotations: map[string]string{"owner": "bar"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "foo",
+						Image: "foo:v1",
+						Env: []corev1.EnvVar{
+							{Name: "A", Value: "1"},
+							{Name: "B", Value: "2"},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestSpecHash(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*appsv1.Deployment)
+		opts   SpecHashOptions
+		same   bool
+	}{{
+		name:   "metadata",
+		change: func(d *appsv1.Deployment) { d.Name, d.Labels["version"] = "bar", "2" },
+		same:   true,
+	}, {
+		name:   "status",
+		change: func(d *appsv1.Deployment) { d.Status.Replicas = 3 },
+		same:   true,
+	}, {
+		name: "empty fields",
+		change: func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.NodeSelector = map[string]string{}
+			d.Spec.Template.Spec.Containers[0].Args = []string{}
+		},
+		same: true,
+	}, {
+		name:   "spec",
+		change: func(d *appsv1.Deployment) { d.Spec.Template.Spec.Containers[0].Image = "foo:v2" },
+	}, {
+		name: "zero field",
+		change: func(d *appsv1.Deployment) {
+			var zero int32
+			d.Spec.Replicas = &zero
+		},
+	}, {
+		name: "ordered list",
+		change: func(d *appsv1.Deployment) {
+			env := d.Spec.Template.Spec.Containers[0].Env
+			env[0], env[1] = env[1], env[0]
+		},
+	}, {
+		name: "unordered list",
+		change: func(d *appsv1.Deployment) {
+			env := d.Spec.Template.Spec.Containers[0].Env
+			env[0], env[1] = env[1], env[0]
+		},
+		opts: SpecHashOptions{UnorderedLists: []string{"spec.template.spec.containers.env"}},
+		same: true,
+	}, {
+		name:   "included label",
+		change: func(d *appsv1.Deployment) { d.Labels["version"] = "2" },
+		opts:   SpecHashOptions{Labels: []string{"version"}},
+	}, {
+		name:   "excluded label",
+		change: func(d *appsv1.Deployment) { d.Labels["app"] = "bar" },
+		opts:   SpecHashOptions{Labels: []string{"version"}},
+		same:   true,
+	}, {
+		name:   "included annotation",
+		change: func(d *appsv1.Deployment) { delete(d.Annotations, "owner") },
+		opts:   SpecHashOptions{Annotations: []string{"owner"}},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := hashedDeployment()
+			before, err := SpecHash(d, tc.opts)
+			if err != nil {
+				t.Fatal("SpecHash() =", err)
+			}
+			tc.change(d)
+			after, err := SpecHash(d, tc.opts)
+			if err != nil {
+				t.Fatal("SpecHash() =", err)
+			}
+			if same := before == after; same != tc.same {
+				t.Errorf("SpecHash() = %s, then %s, wanted the same: %v", before, after, tc.same)
+			}
+		})
+	}
+}
+
+func TestSpecHashEncoding(t *testing.T) {
+	hex, err := SpecHash(hashedDeployment(), SpecHashOptions{})
+	if err != nil {
+		t.Fatal("SpecHash() =", err)
+	}
+	if len(hex) != 32 {
+		t.Errorf("len(SpecHash()) = %d, wanted 32", len(hex))
+	}
+	b36, err := SpecHash(hashedDeployment(), SpecHashOptions{Encoding: Base36Encoding})
+	if err != nil {
+		t.Fatal("SpecHash() =", err)
+	}
+	if len(b36) != 25 {
+		t.Errorf("len(SpecHash(Base36Encoding)) = %d, wanted 25", len(b36))
+	}
+}
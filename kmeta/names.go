@@ -19,18 +19,50 @@ package kmeta
 import (
 	"crypto/md5" //nolint:gosec // No strong cryptography needed.
 	"fmt"
+	"math/big"
 	"regexp"
+	"sort"
+	"strings"
 )
 
 // The longest name supported by the K8s is 63.
-// These constants
+const longest = 63
+
+var isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]*$`)
+
+// HashEncoding is the encoding of the hashes in the names shortened by
+// ChildNameWithEncoding.
+type HashEncoding int
+
 const (
-	longest = 63
-	md5Len  = 32
-	head    = longest - md5Len // How much to truncate to fit the hash.
+	// HexEncoding encodes hashes in 32 hexadecimal characters, like ChildName.
+	HexEncoding HashEncoding = iota
+
+	// Base36Encoding encodes hashes in 25 lowercase alphanumeric characters,
+	// leaving more of the parent and suffix in the names.
+	Base36Encoding
+
+	// Base62Encoding encodes hashes in 22 mixed case alphanumeric characters.
+	// The names are then not valid DNS-1123 names, which must be lowercase,
+	// but are fine for e.g. label values.
+	Base62Encoding
 )
 
-var isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]*$`)
+// encode returns the fixed width encoding of the given hash.
+func (e HashEncoding) encode(h [md5.Size]byte) string {
+	switch e {
+	case Base36Encoding:
+		return padHash(new(big.Int).SetBytes(h[:]).Text(36), 25)
+	case Base62Encoding:
+		return padHash(new(big.Int).SetBytes(h[:]).Text(62), 22)
+	default:
+		return fmt.Sprintf("%x", h)
+	}
+}
+
+func padHash(h string, width int) string {
+	return strings.Repeat("0", width-len(h)) + h
+}
 
 // ChildName generates a name for the resource based upon the parent resource and suffix.
 // If the concatenated name is longer than K8s permits the name is hashed and truncated to permit
@@ -39,13 +71,30 @@ var isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]*$`)
 // and `parent|hash|suffix` will be returned, where parent and suffix will be trimmed to
 // fit (prefix of parent at most of length 31, and prefix of suffix at most length 30).
 func ChildName(parent, suffix string) string {
+	return ChildNameWithEncoding(parent, suffix, HexEncoding)
+}
+
+// ChildNameWithEncoding is like ChildName, but encodes the hashes of the
+// shortened names with the given encoding. The shorter encodings keep more
+// of the parent and suffix readable.
+func ChildNameWithEncoding(parent, suffix string, enc HashEncoding) string {
+	n, _ := childName(parent, suffix, enc)
+	return n
+}
+
+// childName returns the name for the parent and suffix, along with its
+// readable part, i.e. the name without its hash if it was shortened.
+func childName(parent, suffix string, enc HashEncoding) (string, string) {
 	n := parent
 	if len(parent) > (longest - len(suffix)) {
+		//nolint:gosec // No strong cryptography needed.
+		h := enc.encode(md5.Sum([]byte(parent)))
+		head := longest - len(h) // How much to truncate to fit the hash.
 		// If the suffix is longer than the longest allowed suffix, then
 		// we hash the whole combined string and use that as the suffix.
 		if head-len(suffix) <= 0 {
 			//nolint:gosec // No strong cryptography needed.
-			h := md5.Sum([]byte(parent + suffix))
+			h = enc.encode(md5.Sum([]byte(parent + suffix)))
 			// 1. trim parent, if needed
 			if head < len(parent) {
 				parent = parent[:head]
@@ -53,16 +102,90 @@ func ChildName(parent, suffix string) string {
 			// Format the return string, if it's shorter than longest: pad with
 			// beginning of the suffix. This happens, for example, when parent is
 			// short, but the suffix is very long.
-			ret := parent + fmt.Sprintf("%x", h)
+			ret := parent + h
 			if d := longest - len(ret); d > 0 {
 				ret += suffix[:d]
 			}
-			return makeValidName(ret)
+			ret = makeValidName(ret)
+			readable := parent
+			if len(ret) > len(parent)+len(h) {
+				readable += ret[len(parent)+len(h):]
+			}
+			return ret, readable
 		}
-		//nolint:gosec // No strong cryptography needed.
-		n = fmt.Sprintf("%s%x", parent[:head-len(suffix)], md5.Sum([]byte(parent)))
+		n = parent[:head-len(suffix)] + h
+		return n + suffix, parent[:head-len(suffix)] + suffix
 	}
-	return n + suffix
+	return n + suffix, n + suffix
+}
+
+// ChildNameInput is a parent and suffix ChildName is called with.
+type ChildNameInput struct {
+	Parent, Suffix string
+}
+
+// ChildNameCollision is a group of distinct inputs whose names collide.
+type ChildNameCollision struct {
+	// Name is the colliding name, or for near-collisions the readable part
+	// the names share.
+	Name string
+
+	// Exact is whether the names are identical. Otherwise they were
+	// shortened and only differ in their hashes, so that they can't be
+	// told apart without looking them up.
+	Exact bool
+
+	// Inputs are the colliding inputs, sorted.
+	Inputs []ChildNameInput
+}
+
+// AuditChildNames reports the collisions and the near-collisions among the
+// names generated with the given encoding for the given inputs. The heavier
+// the names are truncated, the more of them only differ in their hashes.
+// The collisions are sorted by name, exact ones first.
+func AuditChildNames(enc HashEncoding, inputs ...ChildNameInput) []ChildNameCollision {
+	byName := make(map[string]map[ChildNameInput]struct{}, len(inputs))
+	byReadable := make(map[string]map[ChildNameInput]struct{}, len(inputs))
+	add := func(m map[string]map[ChildNameInput]struct{}, key string, in ChildNameInput) {
+		if m[key] == nil {
+			m[key] = make(map[ChildNameInput]struct{}, 1)
+		}
+		m[key][in] = struct{}{}
+	}
+	for _, in := range inputs {
+		n, readable := childName(in.Parent, in.Suffix, enc)
+		add(byName, n, in)
+		if readable != n {
+			add(byReadable, readable, in)
+		}
+	}
+
+	var collisions []ChildNameCollision
+	for exact, m := range map[bool]map[string]map[ChildNameInput]struct{}{true: byName, false: byReadable} {
+		for name, ins := range m {
+			if len(ins) < 2 {
+				continue
+			}
+			c := ChildNameCollision{Name: name, Exact: exact, Inputs: make([]ChildNameInput, 0, len(ins))}
+			for in := range ins {
+				c.Inputs = append(c.Inputs, in)
+			}
+			sort.Slice(c.Inputs, func(i, j int) bool {
+				if c.Inputs[i].Parent != c.Inputs[j].Parent {
+					return c.Inputs[i].Parent < c.Inputs[j].Parent
+				}
+				return c.Inputs[i].Suffix < c.Inputs[j].Suffix
+			})
+			collisions = append(collisions, c)
+		}
+	}
+	sort.Slice(collisions, func(i, j int) bool {
+		if collisions[i].Exact != collisions[j].Exact {
+			return collisions[i].Exact
+		}
+		return collisions[i].Name < collisions[j].Name
+	})
+	return collisions
 }
 
 // If due to trimming above we're terminating the string with a non-alphanumeric
@@ -77,3 +77,93 @@ func TestChildName(t *testing.T) {
 		})
 	}
 }
+
+func TestChildNameWithEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent string
+		suffix string
+		enc    HashEncoding
+		want   string
+	}{{
+		name:   "hex is ChildName",
+		parent: strings.Repeat("f", 63),
+		suffix: "-deployment",
+		enc:    HexEncoding,
+		want:   "ffffffffffffffffffff105d7597f637e83cc711605ac3ea4957-deployment",
+	}, {
+		name:   "short names are not hashed",
+		parent: "asdf",
+		suffix: "-deployment",
+		enc:    Base36Encoding,
+		want:   "asdf-deployment",
+	}, {
+		name:   "base36 keeps more of the parent",
+		parent: strings.Repeat("f", 63),
+		suffix: "-deployment",
+		enc:    Base36Encoding,
+		want:   "fffffffffffffffffffffffffff0yvmrghyrfdm67npdxi93wo5z-deployment",
+	}, {
+		name:   "base36 long suffix",
+		parent: "a",
+		suffix: strings.Repeat("f", 63),
+		enc:    Base36Encoding,
+		want:   "aarhr807w5p772bs1cljq9zu38fffffffffffffffffffffffffffffffffffff",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ChildNameWithEncoding(test.parent, test.suffix, test.enc)
+			if errs := validation.IsDNS1123Subdomain(got); len(errs) != 0 {
+				t.Errorf("Invalid DNS1123 Subdomain %s\n\n Errors: %v", got, errs)
+			}
+			if got != test.want {
+				t.Errorf("ChildNameWithEncoding() = %s, wanted %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestChildNameBase62(t *testing.T) {
+	got := ChildNameWithEncoding(strings.Repeat("f", 63), "-deployment", Base62Encoding)
+	if len(got) != 63 {
+		t.Errorf("len(ChildNameWithEncoding()) = %d, wanted 63", len(got))
+	}
+	if want := strings.Repeat("f", 30); !strings.HasPrefix(got, want) {
+		t.Errorf("ChildNameWithEncoding() = %s, wanted prefix %s", got, want)
+	}
+	if errs := validation.IsValidLabelValue(got); len(errs) != 0 {
+		t.Errorf("Invalid label value %s: %v", got, errs)
+	}
+}
+
+func TestAuditChildNames(t *testing.T) {
+	long := strings.Repeat("a", 60)
+	got := AuditChildNames(HexEncoding,
+		ChildNameInput{Parent: long + "1", Suffix: "-deployment"},
+		ChildNameInput{Parent: long + "2", Suffix: "-deployment"},
+		ChildNameInput{Parent: "short", Suffix: "-deployment"},
+		// The same name from distinct inputs.
+		ChildNameInput{Parent: "foo-", Suffix: "bar"},
+		ChildNameInput{Parent: "foo", Suffix: "-bar"},
+	)
+	want := []ChildNameCollision{{
+		Name:  "foo-bar",
+		Exact: true,
+		Inputs: []ChildNameInput{
+			{Parent: "foo", Suffix: "-bar"},
+			{Parent: "foo-", Suffix: "bar"},
+		},
+	}, {
+		Name:  strings.Repeat("a", 20) + "-deployment",
+		Exact: false,
+		Inputs: []ChildNameInput{
+			{Parent: long + "1", Suffix: "-deployment"},
+			{Parent: long + "2", Suffix: "-deployment"},
+		},
+	}}
+	if !cmp.Equal(got, want) {
+		t.Error("AuditChildNames (-want, +got):", cmp.Diff(want, got))
+	}
+
+}
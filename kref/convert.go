@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kref
+
+import (
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/tracker"
+)
+
+var (
+	// ErrUnresolvedGroup is returned when converting a KReference with a
+	// Group but no APIVersion, which must be resolved first, see
+	// KReferenceResolver.ResolveGroup.
+	ErrUnresolvedGroup = errors.New("the group of the reference is not resolved to an apiVersion")
+
+	// ErrSelectorReference is returned when converting a tracker.Reference
+	// selecting its referents by labels, rather than by name.
+	ErrSelectorReference = errors.New("the reference has a selector")
+)
+
+// GroupVersion returns the API group and version of the KReference: those
+// of its APIVersion, checked against its Group if both are set, or its Group
+// and an empty version. The core group is the empty string, e.g. for "v1".
+func GroupVersion(kr *duckv1.KReference) (schema.GroupVersion, error) {
+	if kr.APIVersion == "" {
+		return schema.GroupVersion{Group: kr.Group}, nil
+	}
+	gv, err := schema.ParseGroupVersion(kr.APIVersion)
+	if err != nil {
+		return schema.GroupVersion{}, err
+	}
+	if kr.Group != "" && gv.Group != kr.Group {
+		return schema.GroupVersion{}, fmt.Errorf("apiVersion %q is not of group %q", kr.APIVersion, kr.Group)
+	}
+	return gv, nil
+}
+
+// Validate validates that the KReference can be converted to the other types
+// of references: its APIVersion is well formed and agrees with its Group, and
+// the Group is resolved to an APIVersion.
+func Validate(kr *duckv1.KReference) *apis.FieldError {
+	if kr.APIVersion == "" {
+		if kr.Group != "" {
+			return &apis.FieldError{
+				Message: ErrUnresolvedGroup.Error(),
+				Paths:   []string{"group"},
+			}
+		}
+		return apis.ErrMissingField("apiVersion")
+	}
+	if _, err := schema.ParseGroupVersion(kr.APIVersion); err != nil {
+		return apis.ErrInvalidValue(kr.APIVersion, "apiVersion", err.Error())
+	}
+	if _, err := GroupVersion(kr); err != nil {
+		return &apis.FieldError{
+			Message: "both apiVersion and group are specified and they refer to different API groups",
+			Paths:   []string{"apiVersion", "group"},
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
+
+// apiVersion returns the APIVersion of the KReference, failing when it can't
+// be converted.
+func apiVersion(kr *duckv1.KReference) (string, error) {
+	if kr.APIVersion == "" && kr.Group != "" {
+		return "", ErrUnresolvedGroup
+	}
+	if _, err := GroupVersion(kr); err != nil {
+		return "", err
+	}
+	return kr.APIVersion, nil
+}
+
+// FromObjectReference returns the KReference of the ObjectReference. Its
+// UID, ResourceVersion and FieldPath have no equivalent and are dropped.
+func FromObjectReference(ref corev1.ObjectReference) *duckv1.KReference {
+	return &duckv1.KReference{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Namespace:  ref.Namespace,
+		Name:       ref.Name,
+	}
+}
+
+// ToObjectReference returns the ObjectReference of the KReference, whose
+// Group must be resolved to an APIVersion. Its Address has no equivalent and
+// is dropped.
+func ToObjectReference(kr *duckv1.KReference) (corev1.ObjectReference, error) {
+	av, err := apiVersion(kr)
+	if err != nil {
+		return corev1.ObjectReference{}, err
+	}
+	return corev1.ObjectReference{
+		APIVersion: av,
+		Kind:       kr.Kind,
+		Namespace:  kr.Namespace,
+		Name:       kr.Name,
+	}, nil
+}
+
+// FromTrackerReference returns the KReference of the tracker.Reference,
+// which must reference its referent by name.
+func FromTrackerReference(ref tracker.Reference) (*duckv1.KReference, error) {
+	if ref.Selector != nil {
+		return nil, ErrSelectorReference
+	}
+	return &duckv1.KReference{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Namespace:  ref.Namespace,
+		Name:       ref.Name,
+	}, nil
+}
+
+// ToTrackerReference returns the tracker.Reference of the KReference, whose
+// Group must be resolved to an APIVersion for the tracker to match the
+// referent.
+func ToTrackerReference(kr *duckv1.KReference) (tracker.Reference, error) {
+	av, err := apiVersion(kr)
+	if err != nil {
+		return tracker.Reference{}, err
+	}
+	return tracker.Reference{
+		APIVersion: av,
+		Kind:       kr.Kind,
+		Namespace:  kr.Namespace,
+		Name:       kr.Name,
+	}, nil
+}
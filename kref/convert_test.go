@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kref
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	. "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/tracker"
+)
+
+func TestGroupVersion(t *testing.T) {
+	tests := map[string]struct {
+		input   *KReference
+		want    schema.GroupVersion
+		wantErr bool
+	}{
+		"apiVersion": {
+			input: &KReference{APIVersion: "serving.knative.dev/v1"},
+			want:  schema.GroupVersion{Group: "serving.knative.dev", Version: "v1"},
+		},
+		"core group": {
+			input: &KReference{APIVersion: "v1"},
+			want:  schema.GroupVersion{Version: "v1"},
+		},
+		"group": {
+			input: &KReference{Group: "serving.knative.dev"},
+			want:  schema.GroupVersion{Group: "serving.knative.dev"},
+		},
+		"matching apiVersion and group": {
+			input: &KReference{APIVersion: "serving.knative.dev/v1", Group: "serving.knative.dev"},
+			want:  schema.GroupVersion{Group: "serving.knative.dev", Version: "v1"},
+		},
+		"group prefix of the apiVersion": {
+			input:   &KReference{APIVersion: "serving.knative.dev/v1", Group: "serving"},
+			wantErr: true,
+		},
+		"invalid apiVersion": {
+			input:   &KReference{APIVersion: "a/b/c"},
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := GroupVersion(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("GroupVersion() = %v, wanted error %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("GroupVersion() = %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		input   *KReference
+		wantErr bool
+	}{
+		"valid":                       {input: &KReference{APIVersion: "apps/v1"}},
+		"core group":                  {input: &KReference{APIVersion: "v1"}},
+		"missing apiVersion":          {input: &KReference{}, wantErr: true},
+		"unresolved group":            {input: &KReference{Group: "apps"}, wantErr: true},
+		"invalid apiVersion":          {input: &KReference{APIVersion: "a/b/c"}, wantErr: true},
+		"mismatched apiVersion/group": {input: &KReference{APIVersion: "apps/v1", Group: "batch"}, wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := Validate(tc.input); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() = %v, wanted error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestObjectReferenceConversion(t *testing.T) {
+	or := corev1.ObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "default",
+		Name:       "nginx",
+		UID:        "dropped",
+		FieldPath:  "dropped",
+	}
+	kr := FromObjectReference(or)
+	want := &KReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "nginx"}
+	if !cmp.Equal(kr, want) {
+		t.Error("FromObjectReference (-want, +got) =", cmp.Diff(want, kr))
+	}
+
+	got, err := ToObjectReference(kr)
+	if err != nil {
+		t.Fatal("ToObjectReference() =", err)
+	}
+	or.UID, or.FieldPath = "", ""
+	if got != or {
+		t.Errorf("ToObjectReference() = %v, wanted %v", got, or)
+	}
+
+	if _, err := ToObjectReference(&KReference{Group: "apps", Kind: "Deployment", Name: "nginx"}); !errors.Is(err, ErrUnresolvedGroup) {
+		t.Errorf("ToObjectReference() = %v, wanted %v", err, ErrUnresolvedGroup)
+	}
+	if _, err := ToObjectReference(&KReference{APIVersion: "apps/v1", Group: "batch"}); err == nil {
+		t.Error("ToObjectReference() = nil, wanted an error for mismatched apiVersion and group")
+	}
+}
+
+func TestTrackerReferenceConversion(t *testing.T) {
+	ref := tracker.Reference{APIVersion: "v1", Kind: "Service", Namespace: "default", Name: "svc"}
+	kr, err := FromTrackerReference(ref)
+	if err != nil {
+		t.Fatal("FromTrackerReference() =", err)
+	}
+	want := &KReference{APIVersion: "v1", Kind: "Service", Namespace: "default", Name: "svc"}
+	if !cmp.Equal(kr, want) {
+		t.Error("FromTrackerReference (-want, +got) =", cmp.Diff(want, kr))
+	}
+
+	got, err := ToTrackerReference(kr)
+	if err != nil {
+		t.Fatal("ToTrackerReference() =", err)
+	}
+	if !cmp.Equal(got, ref) {
+		t.Error("ToTrackerReference (-want, +got) =", cmp.Diff(ref, got))
+	}
+
+	ref.Name = ""
+	ref.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "svc"}}
+	if _, err := FromTrackerReference(ref); !errors.Is(err, ErrSelectorReference) {
+		t.Errorf("FromTrackerReference() = %v, wanted %v", err, ErrSelectorReference)
+	}
+	if _, err := ToTrackerReference(&KReference{Group: "apps"}); !errors.Is(err, ErrUnresolvedGroup) {
+		t.Errorf("ToTrackerReference() = %v, wanted %v", err, ErrUnresolvedGroup)
+	}
+}
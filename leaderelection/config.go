@@ -57,6 +57,7 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		cm.AsDuration("retry-period", &config.RetryPeriod),
 
 		cm.AsUint32("buckets", &config.Buckets),
+		cm.AsBool("leader-preference", &config.LeaderPreference),
 
 		cm.CollectMapEntriesWithPrefix("map-lease-prefix", &config.LeaseNamesPrefixMapping),
 	); err != nil {
@@ -87,6 +88,10 @@ type Config struct {
 	RenewDeadline           time.Duration
 	RetryPeriod             time.Duration
 	LeaseNamesPrefixMapping map[string]string
+
+	// LeaderPreference makes the leases land on the replicas of the highest
+	// Priority, see ComponentConfig.
+	LeaderPreference bool
 }
 
 type lecfg struct{}
@@ -115,6 +120,8 @@ func (c *Config) GetComponentConfig(name string) ComponentConfig {
 		RenewDeadline:           c.RenewDeadline,
 		RetryPeriod:             c.RetryPeriod,
 		LeaseNamesPrefixMapping: c.LeaseNamesPrefixMapping,
+		LeaderPreference:        c.LeaderPreference,
+		Priority:                priorityFromEnv(),
 	}
 }
 
@@ -151,6 +158,15 @@ type ComponentConfig struct {
 	// from <component>.<package>.<reconciler_type_name> to the
 	// associated value when using standardBuilder.
 	LeaseNamesPrefixMapping map[string]string
+
+	// LeaderPreference makes the replicas of a lower Priority yield their
+	// leases to those of a higher one, e.g. so that leadership lands on the
+	// upgraded replicas first during a rollout.
+	LeaderPreference bool
+	// Priority is the preference of the replica for leadership, when
+	// LeaderPreference is set. GetComponentConfig reads it from the
+	// LEADER_ELECTION_PRIORITY environment variable, 0 by default.
+	Priority int32
 }
 
 // statefulSetID is a envconfig Decodable controller ordinal and name.
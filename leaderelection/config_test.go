@@ -203,6 +203,18 @@ func TestNewConfigFromMap(t *testing.T) {
 				"reconciler": "reconciler1",
 			},
 		},
+	}, {
+		name: "ok config, leader preference",
+		data: map[string]string{
+			"leader-preference": "true",
+		},
+		want: Config{
+			Buckets:          1,
+			LeaseDuration:    60 * time.Second,
+			RenewDeadline:    40 * time.Second,
+			RetryPeriod:      10 * time.Second,
+			LeaderPreference: true,
+		},
 	}}
 
 	for _, tc := range tt {
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -30,6 +31,7 @@ import (
 
 	"knative.dev/pkg/hash"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/network"
 	"knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
@@ -135,6 +137,9 @@ func (b *standardBuilder) buildElector(ctx context.Context, la reconciler.Leader
 		// Use a local var which won't change across the for loop since it is
 		// used in a callback asynchronously.
 		bkt := bkt
+		// Record the time to the first acquisition of the bucket only.
+		acquired := metrics.StartPhase(metrics.PhaseLeaderAcquisition, bkt.Name())
+		var acquiredOnce sync.Once
 		rl, err := resourcelock.New(knativeResourceLock,
 			system.Namespace(), // use namespace we are running in
 			bkt.Name(),
@@ -156,6 +161,7 @@ func (b *standardBuilder) buildElector(ctx context.Context, la reconciler.Leader
 			Callbacks: leaderelection.LeaderCallbacks{
 				OnStartedLeading: func(context.Context) {
 					logger.Infof("%q has started leading %q", rl.Identity(), bkt.Name())
+					acquiredOnce.Do(func() { acquired(ctx) })
 					if err := la.Promote(bkt, enq); err != nil {
 						// TODO(mattmoor): We expect this to effectively never happen,
 						// but if it does, we should support wrapping `le` in an elector
@@ -178,6 +184,18 @@ func (b *standardBuilder) buildElector(ctx context.Context, la reconciler.Leader
 		// if lec.WatchDog != nil {
 		// 	lec.WatchDog.SetLeaderElection(le)
 		// }
+		if b.lec.LeaderPreference {
+			electors = append(electors, &preferringElector{
+				leases:   b.kc.CoordinationV1().Leases(system.Namespace()),
+				name:     bkt.Name(),
+				id:       rl.Identity(),
+				priority: b.lec.Priority,
+				lec:      b.lec,
+				elector:  le,
+				now:      time.Now,
+			})
+			continue
+		}
 		electors = append(electors, &runUntilCancelled{Elector: le})
 	}
 	return &runAll{les: electors}, nil
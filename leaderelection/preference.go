@@ -0,0 +1,190 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// priorityEnv is the environment variable holding the leadership
+	// priority of the replica, see ComponentConfig.Priority.
+	priorityEnv = "LEADER_ELECTION_PRIORITY"
+
+	// The annotations of the leases announcing the candidate of the highest
+	// priority, which the others yield the lease to.
+	preferredHolderAnnotation    = "leaderelection.knative.dev/preferred-holder"
+	preferredPriorityAnnotation  = "leaderelection.knative.dev/preferred-priority"
+	preferredRenewTimeAnnotation = "leaderelection.knative.dev/preferred-renew-time"
+)
+
+// priorityFromEnv returns the leadership priority of the replica, from the
+// LEADER_ELECTION_PRIORITY environment variable, or 0.
+func priorityFromEnv() int32 {
+	p, err := strconv.ParseInt(os.Getenv(priorityEnv), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(p)
+}
+
+// preferringElector campaigns for a lease unless a candidate of a higher
+// priority announced itself on the lease, in which case it steps down if it
+// is leading, so that leadership lands on the preferred candidates, e.g. the
+// upgraded replicas during a rollout. It announces itself when its priority
+// is the highest of the fresh announcements.
+//
+// The coordination.k8s.io LeaseCandidate API serves the same purpose, but is
+// not available to the clients of this package yet, so the announcements are
+// annotations of the lease.
+type preferringElector struct {
+	leases   coordinationv1client.LeaseInterface
+	name     string
+	id       string
+	priority int32
+	lec      ComponentConfig
+
+	// elector is the single-term elector of the lease.
+	elector Elector
+
+	now func() time.Time
+}
+
+// Run implements Elector
+func (pe *preferringElector) Run(ctx context.Context) {
+	ticker := time.NewTicker(pe.lec.RetryPeriod)
+	defer ticker.Stop()
+
+	var t *term
+	defer func() {
+		if t != nil {
+			t.stop()
+		}
+	}()
+
+	for {
+		yield := pe.yields(ctx)
+		switch {
+		case yield && t != nil:
+			logging.FromContext(ctx).Infof("%q yields %q to a candidate of a higher priority", pe.id, pe.name)
+			t.stop()
+			t = nil
+		case !yield && t == nil:
+			t = pe.startTerm(ctx)
+		}
+
+		var termDone <-chan struct{}
+		if t != nil {
+			termDone = t.done
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-termDone:
+			// The term ended, campaign again.
+			t.stop()
+			t = nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// term is a term of the single-term elector.
+type term struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (pe *preferringElector) startTerm(ctx context.Context) *term {
+	ctx, cancel := context.WithCancel(ctx)
+	t := &term{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(t.done)
+		pe.elector.Run(ctx)
+	}()
+	return t
+}
+
+// stop ends the term, releasing the lease if it is leading.
+func (t *term) stop() {
+	t.cancel()
+	<-t.done
+}
+
+// yields announces the candidate on the lease when its priority is the
+// highest of the fresh announcements, and returns whether it should yield
+// the lease to another candidate announced with a higher priority.
+func (pe *preferringElector) yields(ctx context.Context) bool {
+	lease, err := pe.leases.Get(ctx, pe.name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			logging.FromContext(ctx).Warnw("Failed to get lease "+pe.name, "error", err)
+		}
+		// Campaign as usual, the elector creates the lease.
+		return false
+	}
+
+	if holder, priority, ok := pe.preferred(lease); ok && holder != pe.id {
+		if priority > pe.priority {
+			return true
+		}
+		if priority == pe.priority {
+			return false
+		}
+	}
+	if pe.priority <= 0 {
+		// Only the prioritized candidates announce themselves.
+		return false
+	}
+
+	lease = lease.DeepCopy()
+	if lease.Annotations == nil {
+		lease.Annotations = make(map[string]string, 3)
+	}
+	lease.Annotations[preferredHolderAnnotation] = pe.id
+	lease.Annotations[preferredPriorityAnnotation] = strconv.Itoa(int(pe.priority))
+	lease.Annotations[preferredRenewTimeAnnotation] = pe.now().UTC().Format(time.RFC3339)
+	if _, err := pe.leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil && !apierrs.IsConflict(err) {
+		logging.FromContext(ctx).Warnw("Failed to announce the candidate on lease "+pe.name, "error", err)
+	}
+	return false
+}
+
+// preferred returns the candidate announced on the lease and its priority,
+// if its announcement is fresh, i.e. renewed within a lease duration.
+func (pe *preferringElector) preferred(lease *coordinationv1.Lease) (string, int32, bool) {
+	holder := lease.Annotations[preferredHolderAnnotation]
+	priority, err := strconv.ParseInt(lease.Annotations[preferredPriorityAnnotation], 10, 32)
+	if holder == "" || err != nil {
+		return "", 0, false
+	}
+	renewed, err := time.Parse(time.RFC3339, lease.Annotations[preferredRenewTimeAnnotation])
+	if err != nil || pe.now().Sub(renewed) > pe.lec.LeaseDuration {
+		return "", 0, false
+	}
+	return holder, int32(priority), true
+}
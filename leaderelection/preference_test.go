@@ -0,0 +1,188 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+var preferenceNow = time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func announcedLease(holder string, priority int, renewed time.Time) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "lease",
+			Namespace: "knative-testing",
+			Annotations: map[string]string{
+				preferredHolderAnnotation:    holder,
+				preferredPriorityAnnotation:  strconv.Itoa(priority),
+				preferredRenewTimeAnnotation: renewed.Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+func TestPreferringElectorYields(t *testing.T) {
+	tests := []struct {
+		name         string
+		lease        *coordinationv1.Lease
+		priority     int32
+		wantYield    bool
+		wantAnnounce bool
+	}{{
+		name:     "no lease",
+		priority: 1,
+	}, {
+		name:         "no announcement",
+		lease:        &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "lease", Namespace: "knative-testing"}},
+		priority:     1,
+		wantAnnounce: true,
+	}, {
+		name:     "no announcement without priority",
+		lease:    &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "lease", Namespace: "knative-testing"}},
+		priority: 0,
+	}, {
+		name:      "higher priority announced",
+		lease:     announcedLease("other", 2, preferenceNow.Add(-time.Second)),
+		priority:  1,
+		wantYield: true,
+	}, {
+		name:         "stale higher priority announced",
+		lease:        announcedLease("other", 2, preferenceNow.Add(-time.Hour)),
+		priority:     1,
+		wantAnnounce: true,
+	}, {
+		name:     "same priority announced",
+		lease:    announcedLease("other", 1, preferenceNow.Add(-time.Second)),
+		priority: 1,
+	}, {
+		name:         "lower priority announced",
+		lease:        announcedLease("other", 1, preferenceNow.Add(-time.Second)),
+		priority:     2,
+		wantAnnounce: true,
+	}, {
+		name:         "announcement renewed",
+		lease:        announcedLease("me", 1, preferenceNow.Add(-time.Second)),
+		priority:     1,
+		wantAnnounce: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kc := fakekube.NewSimpleClientset()
+			leases := kc.CoordinationV1().Leases("knative-testing")
+			if tc.lease != nil {
+				leases.Create(context.Background(), tc.lease, metav1.CreateOptions{})
+			}
+			pe := &preferringElector{
+				leases:   leases,
+				name:     "lease",
+				id:       "me",
+				priority: tc.priority,
+				lec:      ComponentConfig{LeaseDuration: 15 * time.Second},
+				now:      func() time.Time { return preferenceNow },
+			}
+
+			if got := pe.yields(context.Background()); got != tc.wantYield {
+				t.Errorf("yields() = %v, wanted %v", got, tc.wantYield)
+			}
+			lease, err := leases.Get(context.Background(), "lease", metav1.GetOptions{})
+			if err != nil {
+				return
+			}
+			announced := lease.Annotations[preferredHolderAnnotation] == "me" &&
+				lease.Annotations[preferredRenewTimeAnnotation] == preferenceNow.Format(time.RFC3339)
+			if announced != tc.wantAnnounce {
+				t.Errorf("Announced = %v, wanted %v (annotations: %v)", announced, tc.wantAnnounce, lease.Annotations)
+			}
+		})
+	}
+}
+
+// termElector counts the terms it runs, each lasting until cancelled.
+type termElector struct {
+	running atomic.Int32
+	terms   atomic.Int32
+}
+
+func (te *termElector) Run(ctx context.Context) {
+	te.terms.Add(1)
+	te.running.Store(1)
+	<-ctx.Done()
+	te.running.Store(0)
+}
+
+func TestPreferringElectorStepsDown(t *testing.T) {
+	kc := fakekube.NewSimpleClientset()
+	leases := kc.CoordinationV1().Leases("knative-testing")
+	leases.Create(context.Background(), &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "lease", Namespace: "knative-testing"},
+	}, metav1.CreateOptions{})
+
+	te := &termElector{}
+	pe := &preferringElector{
+		leases:   leases,
+		name:     "lease",
+		id:       "me",
+		priority: 1,
+		lec:      ComponentConfig{LeaseDuration: time.Minute, RetryPeriod: 10 * time.Millisecond},
+		elector:  te,
+		now:      time.Now,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pe.Run(ctx)
+	}()
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return te.running.Load() == 1, nil
+	}); err != nil {
+		t.Fatal("The elector never campaigned:", err)
+	}
+
+	// A candidate of a higher priority announces itself.
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		_, err := leases.Update(context.Background(), announcedLease("other", 2, time.Now()), metav1.UpdateOptions{})
+		return err == nil, nil
+	}); err != nil {
+		t.Fatal("Failed to announce the other candidate:", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return te.running.Load() == 0, nil
+	}); err != nil {
+		t.Fatal("The elector did not step down:", err)
+	}
+
+	cancel()
+	<-done
+	if got := te.terms.Load(); got != 1 {
+		t.Errorf("Terms = %d, wanted 1", got)
+	}
+}
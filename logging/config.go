@@ -92,6 +92,10 @@ func NewLoggerFromConfig(config *Config, name string, opts ...zap.Option) (*zap.
 		componentLvl = lvl.String()
 	}
 
+	// The redaction rules are validated by NewConfigFromMap.
+	if redaction, err := redactionOption(config); err == nil {
+		opts = append(opts, redaction)
+	}
 	logger, level := NewLogger(config.LoggingConfig, componentLvl, opts...)
 	return logger.Named(name), level
 }
@@ -134,6 +138,13 @@ func zapConfigFromJSON(configJSON string) (*zap.Config, error) {
 type Config struct {
 	LoggingConfig string
 	LoggingLevel  map[string]zapcore.Level
+
+	// RedactPatterns are the regular expressions whose matches are redacted
+	// from the log messages and textual fields, and RedactFields the names
+	// of the fields whose values are redacted, by the loggers created by
+	// NewLoggerFromConfig.
+	RedactPatterns []string
+	RedactFields   []string
 }
 
 type lcfg struct{}
@@ -177,6 +188,9 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 			}
 		}
 	}
+	if err := parseRedaction(data, lc); err != nil {
+		return nil, err
+	}
 	return lc, nil
 }
 
@@ -262,9 +276,16 @@ func ConfigToJSON(cfg *Config) (string, error) {
 		return "", nil
 	}
 
-	jsonCfg, err := json.Marshal(map[string]string{
+	data := map[string]string{
 		loggerConfigKey: cfg.LoggingConfig,
-	})
+	}
+	if len(cfg.RedactPatterns) > 0 {
+		data[redactPatternsKey] = strings.Join(cfg.RedactPatterns, "\n")
+	}
+	if len(cfg.RedactFields) > 0 {
+		data[redactFieldsKey] = strings.Join(cfg.RedactFields, ",")
+	}
+	jsonCfg, err := json.Marshal(data)
 	return string(jsonCfg), err
 }
 
@@ -403,6 +403,15 @@ func TestLoggingConfig(t *testing.T) {
 			LoggingLevel:  map[string]zapcore.Level{},
 		},
 		want: `{"zap-logger-config":"{}"}`,
+	}, {
+		name: "redaction",
+		cfg: &Config{
+			LoggingConfig:  "{}",
+			LoggingLevel:   map[string]zapcore.Level{},
+			RedactPatterns: []string{`secret=\S+`, "token"},
+			RedactFields:   []string{"password"},
+		},
+		want: `{"redact.fields":"password","redact.patterns":"secret=\\S+\ntoken","zap-logger-config":"{}"}`,
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -0,0 +1,52 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging/logkey"
+)
+
+// RequestIDHeader is the header carrying the ID of an HTTP request. Requests
+// without it are assigned a new ID, which is returned in the response.
+const RequestIDHeader = "X-Request-Id"
+
+// NewRequestLoggingHandler returns a handler which stores in the context of
+// each request a logger derived from the given one and annotated with the
+// ID, method, path and remote address of the request, before passing it on
+// to next. Handlers can then retrieve it uniformly with FromContext.
+func NewRequestLoggingHandler(logger *zap.SugaredLogger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		l := logger.With(
+			zap.String(logkey.RequestID, id),
+			zap.String(logkey.Method, r.Method),
+			zap.String(logkey.Path, r.URL.Path),
+			zap.String(logkey.Remote, r.RemoteAddr),
+		)
+		next.ServeHTTP(w, r.WithContext(WithLogger(r.Context(), l)))
+	})
+}
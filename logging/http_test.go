@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"knative.dev/pkg/logging/logkey"
+)
+
+func TestNewRequestLoggingHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+	}{{
+		name: "generated request id",
+	}, {
+		name:      "request id from header",
+		requestID: "abc-123",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := zap.New(zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+				zapcore.AddSync(&buf),
+				zap.DebugLevel,
+			)).Sugar()
+
+			h := NewRequestLoggingHandler(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				FromContext(r.Context()).Info("handling")
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/some/path?x=y", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			if tc.requestID != "" {
+				req.Header.Set(RequestIDHeader, tc.requestID)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if id == "" {
+				t.Fatalf("Response header %s is empty", RequestIDHeader)
+			}
+			if tc.requestID != "" && id != tc.requestID {
+				t.Errorf("Response header %s = %q, want: %q", RequestIDHeader, id, tc.requestID)
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Unmarshal(%q) = %v", buf.String(), err)
+			}
+			for key, want := range map[string]string{
+				logkey.RequestID: id,
+				logkey.Method:    http.MethodPost,
+				logkey.Path:      "/some/path",
+				logkey.Remote:    "10.0.0.1:1234",
+			} {
+				if got := entry[key]; got != want {
+					t.Errorf("Log field %s = %v, want: %v", key, got, want)
+				}
+			}
+		})
+	}
+}
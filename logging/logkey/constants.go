@@ -59,6 +59,18 @@ const (
 	// KubernetesService is the key used to represent a Kubernetes service name in logs
 	KubernetesService = "knative.dev/k8sservice"
 
+	// RequestID is the key used to represent the ID of an HTTP request in logs
+	RequestID = "knative.dev/requestid"
+
+	// Method is the key used to represent the method of an HTTP request in logs
+	Method = "knative.dev/method"
+
+	// Path is the key used to represent the URL path of an HTTP request in logs
+	Path = "knative.dev/path"
+
+	// Remote is the key used to represent the remote address of an HTTP request in logs
+	Remote = "knative.dev/remote"
+
 	// Commit is the logging key used to represent the VCS revision that the
 	// Knative component was built from
 	Commit = "commit"
@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	redactPatternsKey = "redact.patterns"
+	redactFieldsKey   = "redact.fields"
+
+	// Redacted replaces the redacted parts of the log output.
+	Redacted = "[REDACTED]"
+)
+
+// redactionRules are the compiled redaction rules of a Config.
+type redactionRules struct {
+	patterns []*regexp.Regexp
+	fields   map[string]struct{}
+}
+
+func newRedactionRules(config *Config) (*redactionRules, error) {
+	rules := &redactionRules{fields: make(map[string]struct{}, len(config.RedactFields))}
+	for _, p := range config.RedactPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		rules.patterns = append(rules.patterns, re)
+	}
+	for _, f := range config.RedactFields {
+		rules.fields[f] = struct{}{}
+	}
+	return rules, nil
+}
+
+func (r *redactionRules) empty() bool {
+	return len(r.patterns) == 0 && len(r.fields) == 0
+}
+
+func (r *redactionRules) redact(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllLiteralString(s, Redacted)
+	}
+	return s
+}
+
+// redactFields returns the fields with those named by the rules replaced
+// by Redacted, and the patterns of the rules redacted from the textual
+// ones: strings, errors and fmt.Stringers.
+func (r *redactionRules) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = f
+		if _, ok := r.fields[f.Key]; ok {
+			redacted[i] = zap.String(f.Key, Redacted)
+			continue
+		}
+		if len(r.patterns) == 0 {
+			continue
+		}
+		switch f.Type {
+		case zapcore.StringType:
+			redacted[i].String = r.redact(f.String)
+		case zapcore.ByteStringType:
+			redacted[i] = zap.ByteString(f.Key, []byte(r.redact(string(f.Interface.([]byte)))))
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok && err != nil {
+				redacted[i] = zap.String(f.Key, r.redact(err.Error()))
+			}
+		case zapcore.StringerType:
+			if s, ok := f.Interface.(fmt.Stringer); ok {
+				redacted[i] = zap.String(f.Key, r.redact(s.String()))
+			}
+		}
+	}
+	return redacted
+}
+
+// redactingCore is a zapcore.Core redacting the log entries and fields per
+// rules which can be updated at runtime.
+type redactingCore struct {
+	zapcore.Core
+	rules *atomic.Value // of *redactionRules
+}
+
+func (c *redactingCore) load() *redactionRules {
+	return c.rules.Load().(*redactionRules)
+}
+
+// With implements zapcore.Core.
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	if rules := c.load(); !rules.empty() {
+		fields = rules.redactFields(fields)
+	}
+	return &redactingCore{Core: c.Core.With(fields), rules: c.rules}
+}
+
+// Check implements zapcore.Core.
+func (c *redactingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if rules := c.load(); !rules.empty() {
+		entry.Message = rules.redact(entry.Message)
+		entry.Stack = rules.redact(entry.Stack)
+		fields = rules.redactFields(fields)
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// redactionOption returns the option wrapping the core of a logger to
+// redact its output per the rules of the config.
+func redactionOption(config *Config) (zap.Option, error) {
+	rules, err := newRedactionRules(config)
+	if err != nil {
+		return nil, err
+	}
+	v := &atomic.Value{}
+	v.Store(rules)
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactingCore{Core: core, rules: v}
+	}), nil
+}
+
+// UpdateRedactionFromConfigMap returns a helper func that can be used to
+// update the redaction rules of a logger created by NewLoggerFromConfig when
+// the logging config map is updated. The fields added to the logger with
+// With before the update keep the redaction of the previous rules.
+func UpdateRedactionFromConfigMap(logger *zap.SugaredLogger) func(configMap *corev1.ConfigMap) {
+	return func(configMap *corev1.ConfigMap) {
+		core, ok := logger.Desugar().Core().(*redactingCore)
+		if !ok {
+			logger.Error("The logger does not support redaction, it was not created by NewLoggerFromConfig.")
+			return
+		}
+		config, err := NewConfigFromConfigMap(configMap)
+		if err != nil {
+			logger.Errorw("Failed to parse the logging configmap. Previous redaction rules will be used.", zap.Error(err))
+			return
+		}
+		rules, err := newRedactionRules(config)
+		if err != nil {
+			logger.Errorw("Failed to parse the redaction rules. Previous redaction rules will be used.", zap.Error(err))
+			return
+		}
+		core.rules.Store(rules)
+	}
+}
+
+// parseRedaction reads the redaction rules of the logging config map: the
+// regular expressions, one per line, whose matches are redacted from the
+// messages and textual fields, and the comma-separated names of the fields
+// whose values are redacted.
+func parseRedaction(data map[string]string, lc *Config) error {
+	for _, line := range strings.Split(data[redactPatternsKey], "\n") {
+		if p := strings.TrimSpace(line); p != "" {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+			}
+			lc.RedactPatterns = append(lc.RedactPatterns, p)
+		}
+	}
+	for _, f := range strings.Split(data[redactFieldsKey], ",") {
+		if f := strings.TrimSpace(f); f != "" {
+			lc.RedactFields = append(lc.RedactFields, f)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewConfigRedaction(t *testing.T) {
+	c, err := NewConfigFromMap(map[string]string{
+		redactPatternsKey: "password=\\S+\n\n  Bearer [A-Za-z0-9.]+  \n",
+		redactFieldsKey:   "token, apiKey,",
+	})
+	if err != nil {
+		t.Fatal("NewConfigFromMap() =", err)
+	}
+	if diff := cmp.Diff([]string{`password=\S+`, `Bearer [A-Za-z0-9.]+`}, c.RedactPatterns); diff != "" {
+		t.Error("RedactPatterns (-want, +got):", diff)
+	}
+	if diff := cmp.Diff([]string{"token", "apiKey"}, c.RedactFields); diff != "" {
+		t.Error("RedactFields (-want, +got):", diff)
+	}
+
+	if _, err := NewConfigFromMap(map[string]string{redactPatternsKey: "("}); err == nil {
+		t.Error("NewConfigFromMap() with an invalid pattern succeeded, wanted an error")
+	}
+}
+
+type stringer string
+
+func (s stringer) String() string {
+	return string(s)
+}
+
+func TestRedaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), zapcore.AddSync(buf), zapcore.DebugLevel)
+	option, err := redactionOption(&Config{})
+	if err != nil {
+		t.Fatal("redactionOption() =", err)
+	}
+	logger := zap.New(core, option).Sugar()
+	log := func(msg string, kvs ...interface{}) string {
+		t.Helper()
+		buf.Reset()
+		logger.Infow(msg, kvs...)
+		return strings.TrimSpace(buf.String())
+	}
+
+	// Without rules, nothing is redacted.
+	if got, want := log("password=hunter2", "token", "abc"), `{"msg":"password=hunter2","token":"abc"}`; got != want {
+		t.Errorf("Log = %s, wanted %s", got, want)
+	}
+
+	UpdateRedactionFromConfigMap(logger)(&corev1.ConfigMap{Data: map[string]string{
+		redactPatternsKey: `password=\S+`,
+		redactFieldsKey:   "token",
+	}})
+
+	tests := []struct {
+		name string
+		msg  string
+		kvs  []interface{}
+		want string
+	}{{
+		name: "message",
+		msg:  "login with password=hunter2 failed",
+		want: `{"msg":"login with [REDACTED] failed"}`,
+	}, {
+		name: "field name",
+		msg:  "request",
+		kvs:  []interface{}{"token", 42, "user", "alice"},
+		want: `{"msg":"request","token":"[REDACTED]","user":"alice"}`,
+	}, {
+		name: "textual fields",
+		msg:  "failed",
+		kvs: []interface{}{
+			zap.Error(errors.New("dial: password=hunter2")),
+			zap.Stringer("url", stringer("https://host?password=hunter2")),
+			zap.ByteString("body", []byte("password=hunter2")),
+			zap.Int("code", 500),
+		},
+		want: `{"msg":"failed","error":"dial: [REDACTED]","url":"https://host?[REDACTED]","body":"[REDACTED]","code":500}`,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := log(tc.msg, tc.kvs...); got != tc.want {
+				t.Errorf("Log = %s, wanted %s", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("with", func(t *testing.T) {
+		logger := logger.With("token", "abc")
+		buf.Reset()
+		logger.Info("password=hunter2")
+		if got, want := strings.TrimSpace(buf.String()), `{"msg":"[REDACTED]","token":"[REDACTED]"}`; got != want {
+			t.Errorf("Log = %s, wanted %s", got, want)
+		}
+	})
+
+	t.Run("invalid update", func(t *testing.T) {
+		UpdateRedactionFromConfigMap(logger)(&corev1.ConfigMap{Data: map[string]string{
+			redactPatternsKey: "(",
+		}})
+		if got, want := log("password=hunter2"), `{"msg":"[REDACTED]"}`; got != want {
+			t.Errorf("Log = %s, wanted %s", got, want)
+		}
+	})
+}
@@ -121,6 +121,11 @@ func (mc *metricsConfig) record(ctx context.Context, mss []stats.Measurement, ro
 	}
 	ros = append(ros, opt)
 
+	ctx, err = withTagMutators(ctx)
+	if err != nil {
+		return err
+	}
+
 	return stats.RecordWithOptions(ctx, append(ros, stats.WithMeasurements(mss...))...)
 }
 
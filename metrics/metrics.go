@@ -47,8 +47,24 @@ var (
 	tagHost = tag.MustNewKey("host")
 	// tagPath is used to associate the path to which the HTTP request as made.
 	tagPath = tag.MustNewKey("path")
+	// tagBucket is used to associate the leader election buckets owned by
+	// this replica with the metrics of a workqueue.
+	tagBucket = tag.MustNewKey("bucket")
 )
 
+// withBucket returns the mutators, with the one tagging the buckets returned
+// by bucket if they are set.
+func withBucket(mutators []tag.Mutator, bucket func() string) []tag.Mutator {
+	if bucket == nil {
+		return mutators
+	}
+	b := bucket()
+	if b == "" {
+		return mutators
+	}
+	return append(mutators[:len(mutators):len(mutators)], tag.Upsert(tagBucket, b))
+}
+
 type counterMetric struct {
 	mutators []tag.Mutator
 	measure  *stats.Int64Measure
@@ -66,6 +82,7 @@ func (m counterMetric) Inc() {
 
 type gaugeMetric struct {
 	mutators []tag.Mutator
+	bucket   func() string
 	measure  *stats.Int64Measure
 	total    atomic.Int64
 }
@@ -75,17 +92,18 @@ var _ workqueue.GaugeMetric = (*gaugeMetric)(nil)
 // Inc implements CounterMetric
 func (m *gaugeMetric) Inc() {
 	total := m.total.Add(1)
-	Record(context.Background(), m.measure.M(total), stats.WithTags(m.mutators...))
+	Record(context.Background(), m.measure.M(total), stats.WithTags(withBucket(m.mutators, m.bucket)...))
 }
 
 // Dec implements GaugeMetric
 func (m *gaugeMetric) Dec() {
 	total := m.total.Add(-1)
-	Record(context.Background(), m.measure.M(total), stats.WithTags(m.mutators...))
+	Record(context.Background(), m.measure.M(total), stats.WithTags(withBucket(m.mutators, m.bucket)...))
 }
 
 type floatMetric struct {
 	mutators []tag.Mutator
+	bucket   func() string
 	measure  *stats.Float64Measure
 }
 
@@ -98,7 +116,7 @@ var (
 
 // Observe implements SummaryMetric
 func (m floatMetric) Observe(v float64) {
-	Record(context.Background(), m.measure.M(v), stats.WithTags(m.mutators...))
+	Record(context.Background(), m.measure.M(v), stats.WithTags(withBucket(m.mutators, m.bucket)...))
 }
 
 // Set implements GaugeMetric
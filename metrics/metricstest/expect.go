@@ -0,0 +1,194 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricstest
+
+import (
+	"reflect"
+	"testing"
+
+	"go.opencensus.io/metric/metricproducer"
+	"go.opencensus.io/stats/view"
+)
+
+// Expectation asserts the data recorded for a view, built with Expect, e.g.:
+//
+//	m := metricstest.Expect(t, "reconcile_count").WithTags(map[string]string{
+//	    "reconciler": "test", "success": "true",
+//	})
+//	before := m.Value()
+//	reconcile()
+//	m.Delta(before, 1)
+type Expectation struct {
+	t    ti
+	name string
+	tags map[string]string
+}
+
+// Expect returns an Expectation on the data of the view with the name.
+func Expect(t ti, name string) *Expectation {
+	return &Expectation{t: t, name: name}
+}
+
+// WithTags restricts the Expectation to the data recorded with exactly the
+// tags, whereas the view must otherwise have a single row of data.
+func (e *Expectation) WithTags(tags map[string]string) *Expectation {
+	e.tags = tags
+	return e
+}
+
+// row returns the row of data of the Expectation, nil if none.
+func (e *Expectation) row() *view.Row {
+	e.t.Helper()
+	rows, err := readRowsFromAllMeters(e.name)
+	if err != nil {
+		e.t.Error(err)
+		return nil
+	}
+	if e.tags == nil {
+		if len(rows) > 1 {
+			e.t.Error("Expected 1 row", "metric", e.name, "got", len(rows))
+			return nil
+		}
+		if len(rows) == 1 {
+			return rows[0]
+		}
+		return nil
+	}
+	for _, row := range rows {
+		if rowHasTags(row, e.tags) {
+			return row
+		}
+	}
+	return nil
+}
+
+// rowHasTags returns whether the row has exactly the tags.
+func rowHasTags(row *view.Row, tags map[string]string) bool {
+	if len(row.Tags) != len(tags) {
+		return false
+	}
+	for _, tag := range row.Tags {
+		if want, ok := tags[tag.Key.Name()]; !ok || tag.Value != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Exists asserts data was recorded.
+func (e *Expectation) Exists() *Expectation {
+	e.t.Helper()
+	if e.row() == nil {
+		e.t.Error("No data reported when data was expected", "metric", e.name, "tags", e.tags)
+	}
+	return e
+}
+
+// NotExists asserts no data was recorded.
+func (e *Expectation) NotExists() *Expectation {
+	e.t.Helper()
+	if row := e.row(); row != nil {
+		e.t.Error("Unexpected data reported when no data was expected", "metric", e.name, "tags", e.tags, "data", row.Data)
+	}
+	return e
+}
+
+// Value returns the value of the data recorded, zero if none: the count of
+// the counts and distributions, the sum of the sums and the last value of
+// the last values.
+func (e *Expectation) Value() float64 {
+	e.t.Helper()
+	row := e.row()
+	if row == nil {
+		return 0
+	}
+	switch data := row.Data.(type) {
+	case *view.CountData:
+		return float64(data.Value)
+	case *view.DistributionData:
+		return float64(data.Count)
+	case *view.SumData:
+		return data.Value
+	case *view.LastValueData:
+		return data.Value
+	default:
+		e.t.Error("Unsupported data", "metric", e.name, "got", reflect.TypeOf(row.Data))
+		return 0
+	}
+}
+
+// Equals asserts the Value of the data recorded.
+func (e *Expectation) Equals(want float64) *Expectation {
+	e.t.Helper()
+	if got := e.Value(); got != want {
+		e.t.Error("Wrong value", "metric", e.name, "tags", e.tags, "got", got, "want", want)
+	}
+	return e
+}
+
+// Delta asserts the Value of the data recorded grew by want since it was
+// before, e.g. to count the records of an operation regardless of those of
+// the previous tests.
+func (e *Expectation) Delta(before, want float64) *Expectation {
+	e.t.Helper()
+	if got := e.Value() - before; got != want {
+		e.t.Error("Wrong delta", "metric", e.name, "tags", e.tags, "got", got, "want", want)
+	}
+	return e
+}
+
+// CountInRange asserts the count of the distribution recorded is within
+// [min, max].
+func (e *Expectation) CountInRange(min, max int64) *Expectation {
+	e.t.Helper()
+	row := e.row()
+	if row == nil {
+		e.t.Error("No data reported when data was expected", "metric", e.name, "tags", e.tags)
+		return e
+	}
+	data, ok := row.Data.(*view.DistributionData)
+	if !ok {
+		e.t.Error("want DistributionData", "metric", e.name, "got", reflect.TypeOf(row.Data))
+		return e
+	}
+	if data.Count < min || data.Count > max {
+		e.t.Error("Distribution count out of range", "metric", e.name, "tags", e.tags, "got", data.Count, "want in", [2]int64{min, max})
+	}
+	return e
+}
+
+// ResetViews clears the data recorded for the registered views with the
+// names, now and once the test completes, so that the tests do not see the
+// data of each other.
+func ResetViews(t testing.TB, names ...string) {
+	t.Helper()
+	reset := func() {
+		for _, producer := range metricproducer.GlobalManager().GetAll() {
+			meter := producer.(view.Meter)
+			for _, name := range names {
+				if v := meter.Find(name); v != nil {
+					meter.Unregister(v)
+					if err := meter.Register(v); err != nil {
+						t.Error("Failed to register view again", "metric", name, "error", err)
+					}
+				}
+			}
+		}
+	}
+	reset()
+	t.Cleanup(reset)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricstest
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// errorRecorder records the errors of the assertions.
+type errorRecorder struct {
+	errs int
+}
+
+func (*errorRecorder) Helper() {}
+
+func (r *errorRecorder) Error(...interface{}) {
+	r.errs++
+}
+
+func TestExpect(t *testing.T) {
+	ops := stats.Int64("expect_ops", "Test operations", stats.UnitDimensionless)
+	latency := stats.Float64("expect_latency", "Test latency", stats.UnitMilliseconds)
+	tagKey := tag.MustNewKey("result")
+	opsView := &view.View{Measure: ops, Aggregation: view.Count(), TagKeys: []tag.Key{tagKey}}
+	latencyView := &view.View{Measure: latency, Aggregation: view.Distribution(1, 10, 100)}
+	if err := view.Register(opsView, latencyView); err != nil {
+		t.Fatal("Register() =", err)
+	}
+	t.Cleanup(func() { view.Unregister(opsView, latencyView) })
+	ResetViews(t, "expect_ops", "expect_latency")
+
+	record := func(result string, n int) {
+		ctx, err := tag.New(context.Background(), tag.Upsert(tagKey, result))
+		if err != nil {
+			t.Fatal("tag.New() =", err)
+		}
+		for i := 0; i < n; i++ {
+			stats.Record(ctx, ops.M(1), latency.M(float64(i)))
+		}
+	}
+
+	ok := map[string]string{"result": "ok"}
+	failed := map[string]string{"result": "failed"}
+	Expect(t, "expect_ops").WithTags(ok).NotExists()
+
+	record("ok", 2)
+	m := Expect(t, "expect_ops").WithTags(ok).Exists().Equals(2)
+	before := m.Value()
+	record("ok", 3)
+	record("failed", 1)
+	m.Delta(before, 3)
+	Expect(t, "expect_ops").WithTags(failed).Equals(1)
+	Expect(t, "expect_latency").CountInRange(5, 6)
+
+	// The failed assertions are reported.
+	for name, assert := range map[string]func(ti){
+		"missing tags":   func(r ti) { Expect(r, "expect_ops").WithTags(map[string]string{"result": "other"}).Exists() },
+		"unexpected":     func(r ti) { Expect(r, "expect_ops").WithTags(ok).NotExists() },
+		"wrong value":    func(r ti) { Expect(r, "expect_ops").WithTags(ok).Equals(1) },
+		"wrong delta":    func(r ti) { Expect(r, "expect_ops").WithTags(ok).Delta(0, 1) },
+		"several rows":   func(r ti) { Expect(r, "expect_ops").Exists() },
+		"out of range":   func(r ti) { Expect(r, "expect_latency").CountInRange(1, 2) },
+		"not histogram":  func(r ti) { Expect(r, "expect_ops").WithTags(ok).CountInRange(1, 10) },
+		"missing metric": func(r ti) { Expect(r, "expect_other").Exists() },
+	} {
+		r := &errorRecorder{}
+		assert(r)
+		if r.errs == 0 {
+			t.Errorf("%s: no error reported, wanted one", name)
+		}
+	}
+
+	ResetViews(t, "expect_ops")
+	Expect(t, "expect_ops").WithTags(ok).NotExists()
+	Expect(t, "expect_latency").Exists()
+}
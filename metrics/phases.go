@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging"
+)
+
+// The phases of the startup and shutdown of the processes, whose durations
+// are recorded with RecordPhase.
+const (
+	// PhaseFlagParse is the parsing of the flags, and of the kubeconfig.
+	PhaseFlagParse = "flag_parse"
+
+	// PhaseClientInit is the creation of the clients and informers.
+	PhaseClientInit = "client_init"
+
+	// PhaseInformerSync is the initial sync of an informer, named after the
+	// type of its objects.
+	PhaseInformerSync = "informer_sync"
+
+	// PhaseLeaderAcquisition is the first acquisition of the leadership of a
+	// bucket, named after the bucket.
+	PhaseLeaderAcquisition = "leader_acquisition"
+
+	// PhaseFirstReconcile is the time from the start of a controller to its
+	// first reconcile, named after the controller.
+	PhaseFirstReconcile = "first_reconcile"
+
+	// PhaseStartup is the time from the start of the process to the start
+	// of its controllers.
+	PhaseStartup = "startup"
+
+	// PhaseShutdownHooks is the run of the shutdown hooks.
+	PhaseShutdownHooks = "shutdown_hooks"
+
+	// PhaseShutdownDrain is the time the controllers and servers take to
+	// stop once the shutdown hooks ran.
+	PhaseShutdownDrain = "shutdown_drain"
+)
+
+var (
+	phaseDuration = stats.Float64(
+		"process_phase_duration",
+		"The duration of a phase of the startup or shutdown of the process.",
+		stats.UnitSeconds)
+
+	tagPhase = tag.MustNewKey("phase")
+
+	// phaseDurationBounds are the upper bounds in seconds of the buckets of
+	// the durations.
+	phaseDurationBounds = []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60, 120, 300, 600}
+
+	registerPhaseViews sync.Once
+)
+
+// PhaseViews returns the views of the durations of the phases, which are
+// registered by RecordPhase.
+func PhaseViews() []*view.View {
+	return []*view.View{{
+		Description: phaseDuration.Description(),
+		Measure:     phaseDuration,
+		Aggregation: view.Distribution(phaseDurationBounds...),
+		TagKeys:     []tag.Key{tagPhase, tagName},
+	}}
+}
+
+// RecordPhase records the duration of the phase of the startup or shutdown
+// of the process, e.g. PhaseInformerSync, along with the name of what it
+// applies to, if any, e.g. the type of the objects of the informer, as the
+// process_phase_duration metric, and logs it, so that slow rollouts can be
+// diagnosed.
+func RecordPhase(ctx context.Context, phase, name string, d time.Duration) {
+	ensurePhaseViews(ctx)
+
+	logging.FromContext(ctx).Infow("Phase completed",
+		zap.String("phase", phase), zap.String("name", name), zap.Duration("duration", d))
+	mutators := []tag.Mutator{tag.Upsert(tagPhase, phase)}
+	if name != "" {
+		mutators = append(mutators, tag.Upsert(tagName, name))
+	}
+	pctx, err := tag.New(ctx, mutators...)
+	if err != nil {
+		return
+	}
+	Record(pctx, phaseDuration.M(d.Seconds()))
+}
+
+// ensurePhaseViews registers the PhaseViews once.
+func ensurePhaseViews(ctx context.Context) {
+	registerPhaseViews.Do(func() {
+		if err := view.Register(PhaseViews()...); err != nil {
+			logging.FromContext(ctx).Warnw("Failed to register the views of the phases", zap.Error(err))
+		}
+	})
+}
+
+// StartPhase returns a func recording the duration of the phase, see
+// RecordPhase, up to its call.
+func StartPhase(phase, name string) func(context.Context) {
+	start := time.Now()
+	return func(ctx context.Context) {
+		RecordPhase(ctx, phase, name, time.Since(start))
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestRecordPhase(t *testing.T) {
+	InitForTesting()
+	ctx := context.Background()
+	ensurePhaseViews(ctx)
+	metricstest.ResetViews(t, "process_phase_duration")
+
+	RecordPhase(ctx, PhaseInformerSync, "v1.ConfigMap", 2*time.Second)
+	RecordPhase(ctx, PhaseInformerSync, "v1.ConfigMap", 4*time.Second)
+	StartPhase(PhaseShutdownHooks, "")(ctx)
+
+	metricstest.Expect(t, "process_phase_duration").WithTags(map[string]string{
+		"phase": PhaseInformerSync,
+		"name":  "v1.ConfigMap",
+	}).Exists().Equals(2)
+	metricstest.Expect(t, "process_phase_duration").WithTags(map[string]string{
+		"phase": PhaseShutdownHooks,
+	}).Exists().Equals(1)
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	// DefaultSLOWindows are the rolling windows of the multi-window burn
+	// rate alerts: a fast burn is alerted on the 5m and 1h windows, and a
+	// slow burn on the 30m and 6h ones.
+	DefaultSLOWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+	// SLOQuantiles are the quantiles of the latencies exposed by SLOTracker.
+	SLOQuantiles = []float64{0.5, 0.9, 0.99}
+
+	sloSuccessRatio = stats.Float64(
+		"slo_success_ratio",
+		"The ratio of the successful events over the window.",
+		stats.UnitDimensionless)
+	sloBurnRate = stats.Float64(
+		"slo_burn_rate",
+		"The rate at which the error budget is spent over the window, 1 spending it exactly by the end of the SLO period.",
+		stats.UnitDimensionless)
+	sloLatency = stats.Float64(
+		"slo_latency",
+		"The estimated quantile of the latencies over the window.",
+		stats.UnitMilliseconds)
+
+	tagWindow   = tag.MustNewKey("window")
+	tagQuantile = tag.MustNewKey("quantile")
+
+	// sloLatencyBounds are the upper bounds in milliseconds of the buckets
+	// of the latencies, which the quantiles are estimated to.
+	sloLatencyBounds = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000, 10000, 30000, 60000}
+)
+
+// SLO is a service level objective on the ratio of successful events, e.g.
+// reconciles, over rolling windows.
+type SLO struct {
+	// Name identifies the SLO in the metrics.
+	Name string
+
+	// Objective is the target ratio of successful events, e.g. 0.99.
+	Objective float64
+
+	// Windows are the rolling windows over which the metrics are computed.
+	// Defaults to DefaultSLOWindows.
+	Windows []time.Duration
+
+	// Resolution is the granularity of the windows. Defaults to a minute.
+	Resolution time.Duration
+}
+
+// SLOTracker computes the derived metrics of an SLO in the process: the
+// ratio of successful events and the burn rate of the error budget, and the
+// quantiles of the latencies, over rolling windows. They are exposed as
+// metrics tagged with the SLO name and window, so that burn rate alerts
+// don't need a recording rules pipeline.
+type SLOTracker struct {
+	slo SLO
+	now func() time.Time
+
+	mu sync.Mutex
+	// buckets is a ring of the events of each period of Resolution.
+	buckets []sloBucket
+}
+
+type sloBucket struct {
+	start          time.Time
+	success, total int64
+	// latencies counts the latencies by bucket of sloLatencyBounds, the
+	// last one counting those above all bounds.
+	latencies []int64
+}
+
+// NewSLOTracker creates the SLOTracker of the SLO.
+func NewSLOTracker(slo SLO) *SLOTracker {
+	if len(slo.Windows) == 0 {
+		slo.Windows = DefaultSLOWindows
+	}
+	if slo.Resolution <= 0 {
+		slo.Resolution = time.Minute
+	}
+	longest := slo.Windows[0]
+	for _, w := range slo.Windows {
+		if w > longest {
+			longest = w
+		}
+	}
+	return &SLOTracker{
+		slo:     slo,
+		now:     time.Now,
+		buckets: make([]sloBucket, int(longest/slo.Resolution)+1),
+	}
+}
+
+// bucket returns the bucket of the current period, which must be called
+// with the lock held.
+func (t *SLOTracker) bucket() *sloBucket {
+	start := t.now().Truncate(t.slo.Resolution)
+	b := &t.buckets[int(start.UnixNano()/int64(t.slo.Resolution))%len(t.buckets)]
+	if !b.start.Equal(start) {
+		*b = sloBucket{start: start, latencies: make([]int64, len(sloLatencyBounds)+1)}
+	}
+	return b
+}
+
+// Record records an event, successful or not.
+func (t *SLOTracker) Record(success bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.bucket()
+	b.total++
+	if success {
+		b.success++
+	}
+}
+
+// RecordLatency records the latency of an event, e.g. the time a key spent
+// in the work queue.
+func (t *SLOTracker) RecordLatency(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.bucket()
+	i := 0
+	for i < len(sloLatencyBounds) && ms > sloLatencyBounds[i] {
+		i++
+	}
+	b.latencies[i]++
+}
+
+// window sums the buckets of the window, which must be called with the lock
+// held.
+func (t *SLOTracker) window(w time.Duration) sloBucket {
+	since := t.now().Truncate(t.slo.Resolution).Add(-w)
+	sum := sloBucket{latencies: make([]int64, len(sloLatencyBounds)+1)}
+	for _, b := range t.buckets {
+		if !b.start.After(since) {
+			continue
+		}
+		sum.success += b.success
+		sum.total += b.total
+		for i, n := range b.latencies {
+			sum.latencies[i] += n
+		}
+	}
+	return sum
+}
+
+// SuccessRatio returns the ratio of the successful events over the window,
+// and false when no event was recorded in it.
+func (t *SLOTracker) SuccessRatio(w time.Duration) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sum := t.window(w)
+	if sum.total == 0 {
+		return 0, false
+	}
+	return float64(sum.success) / float64(sum.total), true
+}
+
+// BurnRate returns the rate at which the error budget of the SLO is spent
+// over the window, 1 meaning it is spent exactly by the end of the SLO
+// period, and false when no event was recorded in it.
+func (t *SLOTracker) BurnRate(w time.Duration) (float64, bool) {
+	ratio, ok := t.SuccessRatio(w)
+	if !ok {
+		return 0, false
+	}
+	return burnRate(ratio, t.slo.Objective), true
+}
+
+func burnRate(ratio, objective float64) float64 {
+	budget := 1 - objective
+	if budget <= 0 {
+		// Without an error budget, any error burns it all.
+		if ratio < 1 {
+			return 1
+		}
+		return 0
+	}
+	return (1 - ratio) / budget
+}
+
+// Latency returns the estimated quantile of the latencies over the window,
+// the upper bound of the bucket it falls in, and false when no latency was
+// recorded in it. The latencies above all bounds are estimated to the
+// highest bound.
+func (t *SLOTracker) Latency(w time.Duration, quantile float64) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return latencyQuantile(t.window(w).latencies, quantile)
+}
+
+func latencyQuantile(latencies []int64, quantile float64) (time.Duration, bool) {
+	var total int64
+	for _, n := range latencies {
+		total += n
+	}
+	if total == 0 {
+		return 0, false
+	}
+	rank := quantile * float64(total)
+	var seen int64
+	for i, n := range latencies {
+		seen += n
+		if float64(seen) >= rank && i < len(sloLatencyBounds) {
+			return time.Duration(sloLatencyBounds[i] * float64(time.Millisecond)), true
+		}
+	}
+	return time.Duration(sloLatencyBounds[len(sloLatencyBounds)-1] * float64(time.Millisecond)), true
+}
+
+// Start records the metrics of the SLO every period until the context is
+// cancelled.
+func (t *SLOTracker) Start(ctx context.Context, period time.Duration) {
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.report(ctx)
+			}
+		}
+	}()
+}
+
+// report records the metrics of the windows in which events were recorded.
+func (t *SLOTracker) report(ctx context.Context) {
+	for _, w := range t.slo.Windows {
+		wctx, err := tag.New(ctx, tag.Upsert(tagName, t.slo.Name), tag.Upsert(tagWindow, w.String()))
+		if err != nil {
+			continue
+		}
+		t.mu.Lock()
+		sum := t.window(w)
+		t.mu.Unlock()
+
+		if sum.total > 0 {
+			ratio := float64(sum.success) / float64(sum.total)
+			Record(wctx, sloSuccessRatio.M(ratio))
+			Record(wctx, sloBurnRate.M(burnRate(ratio, t.slo.Objective)))
+		}
+		for _, q := range SLOQuantiles {
+			latency, ok := latencyQuantile(sum.latencies, q)
+			if !ok {
+				break
+			}
+			qctx, err := tag.New(wctx, tag.Upsert(tagQuantile, strconv.FormatFloat(q, 'f', -1, 64)))
+			if err != nil {
+				continue
+			}
+			Record(qctx, sloLatency.M(float64(latency)/float64(time.Millisecond)))
+		}
+	}
+}
+
+// DefaultViews returns a list of views suitable for passing to view.Register.
+// They are the same for all the SLOTrackers.
+func (t *SLOTracker) DefaultViews() []*view.View {
+	return []*view.View{{
+		Description: sloSuccessRatio.Description(),
+		Measure:     sloSuccessRatio,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{tagName, tagWindow},
+	}, {
+		Description: sloBurnRate.Description(),
+		Measure:     sloBurnRate,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{tagName, tagWindow},
+	}, {
+		Description: sloLatency.Description(),
+		Measure:     sloLatency,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{tagName, tagWindow, tagQuantile},
+	}}
+}
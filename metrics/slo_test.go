@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestSLOTracker(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewSLOTracker(SLO{
+		Name:      "reconcile",
+		Objective: 0.9,
+		Windows:   []time.Duration{5 * time.Minute, time.Hour},
+	})
+	tracker.now = func() time.Time { return now }
+
+	if _, ok := tracker.SuccessRatio(time.Hour); ok {
+		t.Error("SuccessRatio() = true without events")
+	}
+	if _, ok := tracker.Latency(time.Hour, 0.5); ok {
+		t.Error("Latency() = true without latencies")
+	}
+
+	// An hour ago, all good.
+	for i := 0; i < 8; i++ {
+		tracker.Record(true)
+	}
+	now = now.Add(58 * time.Minute)
+	// Lately, half of the events fail.
+	for i := 0; i < 2; i++ {
+		tracker.Record(true)
+		tracker.Record(false)
+	}
+
+	check := func(w time.Duration, wantRatio, wantBurn float64) {
+		t.Helper()
+		if got, ok := tracker.SuccessRatio(w); !ok || math.Abs(got-wantRatio) > 1e-9 {
+			t.Errorf("SuccessRatio(%v) = %v, %v, wanted %v", w, got, ok, wantRatio)
+		}
+		if got, ok := tracker.BurnRate(w); !ok || math.Abs(got-wantBurn) > 1e-9 {
+			t.Errorf("BurnRate(%v) = %v, %v, wanted %v", w, got, ok, wantBurn)
+		}
+	}
+	check(5*time.Minute, 0.5, 5)
+	check(time.Hour, 10.0/12, (1-10.0/12)/0.1)
+
+	// The first events leave the hour window.
+	now = now.Add(5 * time.Minute)
+	check(time.Hour, 0.5, 5)
+	if _, ok := tracker.SuccessRatio(time.Minute); ok {
+		t.Error("SuccessRatio(1m) = true without recent events")
+	}
+}
+
+func TestSLOTrackerLatency(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewSLOTracker(SLO{Name: "queue", Objective: 0.99})
+	tracker.now = func() time.Time { return now }
+
+	for i := 0; i < 90; i++ {
+		tracker.RecordLatency(3 * time.Millisecond)
+	}
+	for i := 0; i < 9; i++ {
+		tracker.RecordLatency(150 * time.Millisecond)
+	}
+	tracker.RecordLatency(5 * time.Minute)
+
+	for q, want := range map[float64]time.Duration{
+		0.5:  5 * time.Millisecond,
+		0.9:  5 * time.Millisecond,
+		0.95: 200 * time.Millisecond,
+		0.99: 200 * time.Millisecond,
+		1:    time.Minute,
+	} {
+		if got, ok := tracker.Latency(5*time.Minute, q); !ok || got != want {
+			t.Errorf("Latency(%v) = %v, %v, wanted %v", q, got, ok, want)
+		}
+	}
+}
+
+func TestSLOTrackerMetrics(t *testing.T) {
+	InitForTesting()
+	// Report a single quantile, for the latency metric to have a single row.
+	quantiles := SLOQuantiles
+	SLOQuantiles = []float64{0.99}
+	t.Cleanup(func() { SLOQuantiles = quantiles })
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewSLOTracker(SLO{
+		Name:      "reconcile",
+		Objective: 0.75,
+		Windows:   []time.Duration{5 * time.Minute},
+	})
+	tracker.now = func() time.Time { return now }
+	views := tracker.DefaultViews()
+	if err := view.Register(views...); err != nil {
+		t.Fatal("view.Register() =", err)
+	}
+	t.Cleanup(func() { view.Unregister(views...) })
+
+	tracker.Record(true)
+	tracker.Record(false)
+	tracker.RecordLatency(40 * time.Millisecond)
+	tracker.report(context.Background())
+
+	tags := map[string]string{"name": "reconcile", "window": "5m0s"}
+	metricstest.CheckLastValueData(t, "slo_success_ratio", tags, 0.5)
+	metricstest.CheckLastValueData(t, "slo_burn_rate", tags, 2)
+	tags["quantile"] = "0.99"
+	metricstest.CheckLastValueData(t, "slo_latency", tags, 50)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// tagMutators holds the tag mutators registered with RegisterTagMutators.
+var tagMutators = struct {
+	m      sync.Mutex
+	byName map[string][]tag.Mutator
+	// all is the []tag.Mutator of all the registered mutators, ordered by
+	// name, which is loaded on every recording.
+	all atomic.Value
+}{
+	byName: make(map[string][]tag.Mutator),
+}
+
+// RegisterTagMutators registers mutators applied to the tags of all the
+// metrics recorded through Record and RecordBatch, e.g. to tag them with
+// the cluster name or the region without plumbing these through every call
+// site. The mutators are registered under a name, e.g. the one of the
+// component setting them, and replace the ones previously registered under
+// that name, so that they can be updated live. Registering no mutators
+// unregisters the name.
+//
+// The mutators are applied to the tags of the recording context, so that
+// tag.Insert mutators leave the tags set by the call sites alone. As with
+// any tag, they are only exported for the views listing their keys in their
+// TagKeys.
+func RegisterTagMutators(name string, mutators ...tag.Mutator) {
+	tagMutators.m.Lock()
+	defer tagMutators.m.Unlock()
+
+	if len(mutators) == 0 {
+		delete(tagMutators.byName, name)
+	} else {
+		tagMutators.byName[name] = mutators
+	}
+
+	names := make([]string, 0, len(tagMutators.byName))
+	for name := range tagMutators.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var all []tag.Mutator
+	for _, name := range names {
+		all = append(all, tagMutators.byName[name]...)
+	}
+	tagMutators.all.Store(all)
+}
+
+// TagMutatorsFromEnv registers under the given name the mutators inserting
+// the tags with the given keys and the values of the corresponding
+// environment variables. Tags whose variable is unset or empty are left
+// alone.
+func TagMutatorsFromEnv(name string, envs map[tag.Key]string) {
+	var mutators []tag.Mutator
+	for key, env := range envs {
+		if v := os.Getenv(env); v != "" {
+			mutators = append(mutators, tag.Insert(key, v))
+		}
+	}
+	RegisterTagMutators(name, mutators...)
+}
+
+// TagMutatorsConfigMapWatcher returns a helper func which registers under
+// the given name the mutators inserting the tags with the given keys and the
+// values of the corresponding data keys of the ConfigMap, so that they are
+// updated whenever the ConfigMap changes. Tags whose data key is missing or
+// empty are left alone.
+func TagMutatorsConfigMapWatcher(name string, keys map[string]tag.Key) func(*corev1.ConfigMap) {
+	return func(cm *corev1.ConfigMap) {
+		var mutators []tag.Mutator
+		for dataKey, key := range keys {
+			if v := cm.Data[dataKey]; v != "" {
+				mutators = append(mutators, tag.Insert(key, v))
+			}
+		}
+		RegisterTagMutators(name, mutators...)
+	}
+}
+
+// withTagMutators returns the context with the registered tag mutators
+// applied.
+func withTagMutators(ctx context.Context) (context.Context, error) {
+	all, _ := tagMutators.all.Load().([]tag.Mutator)
+	if len(all) == 0 {
+		return ctx, nil
+	}
+	return tag.New(ctx, all...)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestTagMutators(t *testing.T) {
+	clusterKey := tag.MustNewKey("cluster")
+	regionKey := tag.MustNewKey("region")
+	measure := stats.Int64("tagged", "Tagged measure", stats.UnitDimensionless)
+	v := &view.View{
+		Measure:     measure,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{clusterKey, regionKey},
+	}
+	view.Register(v)
+	t.Cleanup(func() {
+		view.Unregister(v)
+		RegisterTagMutators("env")
+		RegisterTagMutators("config")
+	})
+	setCurMetricsConfig(&metricsConfig{})
+
+	t.Setenv("CLUSTER_NAME", "the-cluster")
+	TagMutatorsFromEnv("env", map[tag.Key]string{
+		clusterKey: "CLUSTER_NAME",
+		regionKey:  "REGION_UNSET",
+	})
+	watcher := TagMutatorsConfigMapWatcher("config", map[string]tag.Key{
+		"region": regionKey,
+	})
+
+	tests := []struct {
+		name  string
+		setup func()
+		ctx   context.Context
+		value int64
+		want  map[string]string
+	}{{
+		name:  "from env",
+		value: 1,
+		want:  map[string]string{"cluster": "the-cluster"},
+	}, {
+		name: "from config map",
+		setup: func() {
+			watcher(&corev1.ConfigMap{Data: map[string]string{"region": "us-east1"}})
+		},
+		value: 2,
+		want:  map[string]string{"cluster": "the-cluster", "region": "us-east1"},
+	}, {
+		name: "config map updated",
+		setup: func() {
+			watcher(&corev1.ConfigMap{Data: map[string]string{"region": "eu-west1"}})
+		},
+		value: 3,
+		want:  map[string]string{"cluster": "the-cluster", "region": "eu-west1"},
+	}, {
+		name: "call site takes precedence",
+		ctx: func() context.Context {
+			ctx, _ := tag.New(context.Background(), tag.Upsert(clusterKey, "call-site"))
+			return ctx
+		}(),
+		value: 4,
+		want:  map[string]string{"cluster": "call-site", "region": "eu-west1"},
+	}, {
+		name: "config map key removed",
+		setup: func() {
+			watcher(&corev1.ConfigMap{})
+		},
+		value: 5,
+		want:  map[string]string{"cluster": "the-cluster"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.setup != nil {
+				tc.setup()
+			}
+			ctx := tc.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			// Start from a fresh view so that only the latest row is left.
+			view.Unregister(v)
+			view.Register(v)
+
+			Record(ctx, measure.M(tc.value))
+			metricstest.CheckLastValueData(t, measure.Name(), tc.want, float64(tc.value))
+		})
+	}
+}
@@ -33,6 +33,33 @@ type WorkqueueProvider struct {
 	LongestRunningProcessorSeconds *stats.Float64Measure
 	Retries                        *stats.Int64Measure
 	WorkDuration                   *stats.Float64Measure
+
+	// Bucket, when set, returns the leader election buckets owned by this
+	// replica for the workqueue of the given name, e.g. their names joined
+	// by commas, or the empty string if there are none. The Depth, Latency
+	// and WorkDuration metrics are then tagged with it, to tell which
+	// buckets are backed up.
+	Bucket func(name string) string
+}
+
+// bucket returns the func returning the buckets of the named workqueue.
+func (wp *WorkqueueProvider) bucket(name string) func() string {
+	if wp.Bucket == nil {
+		return nil
+	}
+	return func() string {
+		return wp.Bucket(name)
+	}
+}
+
+// bucketView returns the view of the metric, tagged with the buckets when
+// Bucket is set.
+func (wp *WorkqueueProvider) bucketView(m stats.Measure, agg *view.Aggregation) *view.View {
+	v := measureView(m, agg)
+	if wp.Bucket != nil {
+		v.TagKeys = append(v.TagKeys, tagBucket)
+	}
+	return v
 }
 
 var _ workqueue.MetricsProvider = (*WorkqueueProvider)(nil)
@@ -54,26 +81,28 @@ func (wp *WorkqueueProvider) AddsView() *view.View {
 func (wp *WorkqueueProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
 	return &gaugeMetric{
 		mutators: []tag.Mutator{tag.Insert(tagName, name)},
+		bucket:   wp.bucket(name),
 		measure:  wp.Depth,
 	}
 }
 
 // DepthView returns a view of the Depth metric.
 func (wp *WorkqueueProvider) DepthView() *view.View {
-	return measureView(wp.Depth, view.LastValue())
+	return wp.bucketView(wp.Depth, view.LastValue())
 }
 
 // NewLatencyMetric implements MetricsProvider
 func (wp *WorkqueueProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
 	return floatMetric{
 		mutators: []tag.Mutator{tag.Insert(tagName, name)},
+		bucket:   wp.bucket(name),
 		measure:  wp.Latency,
 	}
 }
 
 // LatencyView returns a view of the Latency metric.
 func (wp *WorkqueueProvider) LatencyView() *view.View {
-	return measureView(wp.Latency, view.Distribution(BucketsNBy10(1e-08, 10)...))
+	return wp.bucketView(wp.Latency, view.Distribution(BucketsNBy10(1e-08, 10)...))
 }
 
 // NewLongestRunningProcessorSecondsMetric implements MetricsProvider
@@ -119,13 +148,14 @@ func (wp *WorkqueueProvider) UnfinishedWorkSecondsView() *view.View {
 func (wp *WorkqueueProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
 	return floatMetric{
 		mutators: []tag.Mutator{tag.Insert(tagName, name)},
+		bucket:   wp.bucket(name),
 		measure:  wp.WorkDuration,
 	}
 }
 
 // WorkDurationView returns a view of the WorkDuration metric.
 func (wp *WorkqueueProvider) WorkDurationView() *view.View {
-	return measureView(wp.WorkDuration, view.Distribution(BucketsNBy10(1e-08, 10)...))
+	return wp.bucketView(wp.WorkDuration, view.Distribution(BucketsNBy10(1e-08, 10)...))
 }
 
 // DefaultViews returns a list of views suitable for passing to view.Register
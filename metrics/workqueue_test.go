@@ -151,3 +151,42 @@ func TestWorkqueueMetrics(t *testing.T) {
 		t.Errorf("Get() = %v, false; want true", got)
 	}
 }
+
+func TestWorkqueueBucketMetrics(t *testing.T) {
+	buckets := map[string]string{"owner": "bucket-00-of-02,bucket-01-of-02"}
+	wp := &WorkqueueProvider{
+		Adds:         newInt64("bucketed_adds"),
+		Depth:        newInt64("bucketed_depth"),
+		Latency:      newFloat64("bucketed_latency"),
+		WorkDuration: newFloat64("bucketed_work_duration"),
+		Bucket: func(name string) string {
+			return buckets[name]
+		},
+	}
+
+	// Reset the metrics configuration to avoid leaked state from other tests.
+	InitForTesting()
+
+	views := []*view.View{wp.AddsView(), wp.DepthView(), wp.LatencyView(), wp.WorkDurationView()}
+	if err := view.Register(views...); err != nil {
+		t.Fatal("view.Register() =", err)
+	}
+	defer view.Unregister(views...)
+
+	wp.NewAddsMetric("owner").Inc()
+	wp.NewDepthMetric("owner").Inc()
+	wp.NewDepthMetric("other").Inc()
+	wp.NewLatencyMetric("owner").Observe(1)
+	wp.NewWorkDurationMetric("owner").Observe(1)
+
+	wantDepth := metricstest.IntMetric("bucketed_depth", 1, map[string]string{"name": "other"})
+	wantDepth.Values = append(wantDepth.Values,
+		metricstest.IntMetric("bucketed_depth", 1, map[string]string{"name": "owner", "bucket": buckets["owner"]}).Values...)
+	// The adds are not tagged with the buckets.
+	metricstest.AssertMetric(t,
+		metricstest.IntMetric("bucketed_adds", 1, map[string]string{"name": "owner"}),
+		wantDepth)
+	for _, name := range []string{"bucketed_latency", "bucketed_work_duration"} {
+		metricstest.CheckDistributionData(t, name, map[string]string{"name": "owner", "bucket": buckets["owner"]}, 1, 1, 1)
+	}
+}
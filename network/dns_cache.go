@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultDNSCacheTTL bounds how long a DNSCache keeps the addresses of a
+	// host, that of the records of the cluster DNS.
+	DefaultDNSCacheTTL = 5 * time.Second
+
+	// DefaultDNSCacheNegativeTTL bounds how long a DNSCache remembers that a
+	// host does not exist.
+	DefaultDNSCacheNegativeTTL = time.Second
+
+	// dnsCacheMaxEntries bounds the number of hosts of a DNSCache.
+	dnsCacheMaxEntries = 1024
+)
+
+// TTLResolver resolves the addresses of the hosts, along with the TTL of
+// their records, negative if it is not known.
+type TTLResolver interface {
+	LookupIPAddrTTL(ctx context.Context, host string) ([]net.IPAddr, time.Duration, error)
+}
+
+// stdResolver is the TTLResolver of a net.Resolver, which does not expose
+// the TTL of the records.
+type stdResolver struct {
+	*net.Resolver
+}
+
+// LookupIPAddrTTL implements TTLResolver.
+func (r stdResolver) LookupIPAddrTTL(ctx context.Context, host string) ([]net.IPAddr, time.Duration, error) {
+	addrs, err := r.LookupIPAddr(ctx, host)
+	return addrs, -1, err
+}
+
+// DNSCache caches the addresses of the hosts, and the hosts which do not
+// exist. The entries expire after the TTL of their records, bounded by the
+// TTLs of the cache, or after those TTLs when the resolver does not know the
+// TTL of the records, as that of the standard library. When a lookup fails
+// temporarily, the addresses which expired are used, so that flaky DNS
+// servers do not fail the connections to known hosts.
+type DNSCache struct {
+	resolver    TTLResolver
+	ttl         time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	lookups singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []net.IPAddr
+	err     error
+	expires time.Time
+}
+
+// NewDNSCache creates a DNSCache resolving the hosts through the default
+// resolver, keeping their addresses for the ttl, and remembering the hosts
+// which do not exist for the negativeTTL.
+func NewDNSCache(ttl, negativeTTL time.Duration) *DNSCache {
+	return NewDNSCacheWithResolver(stdResolver{net.DefaultResolver}, ttl, negativeTTL)
+}
+
+// NewDNSCacheWithResolver is same with NewDNSCache but resolves the hosts
+// through the resolver, keeping their addresses for the TTL of their records
+// up to the ttl, and remembering the hosts which do not exist up to the
+// negativeTTL.
+func NewDNSCacheWithResolver(resolver TTLResolver, ttl, negativeTTL time.Duration) *DNSCache {
+	return &DNSCache{
+		resolver:    resolver,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		now:         time.Now,
+		entries:     make(map[string]dnsEntry),
+	}
+}
+
+// LookupIPAddr returns the addresses of the host, from the cache unless they
+// expired.
+func (c *DNSCache) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return c.lookup(ctx, host, true /*negative*/)
+}
+
+// lookup returns the addresses of the host, from the cache unless they
+// expired. Unless negative is set, the host is resolved again when the cache
+// remembers that it does not exist.
+func (c *DNSCache) lookup(ctx context.Context, host string, negative bool) ([]net.IPAddr, error) {
+	c.mu.Lock()
+	entry, cached := c.entries[host]
+	c.mu.Unlock()
+	if cached && c.now().Before(entry.expires) && (negative || entry.err == nil) {
+		return entry.addrs, entry.err
+	}
+
+	// Concurrent lookups of the same host share the answer.
+	ch := c.lookups.DoChan(host, func() (interface{}, error) {
+		addrs, ttl, err := c.resolver.LookupIPAddrTTL(ctx, host)
+		return dnsAnswer{addrs: addrs, ttl: ttl}, err
+	})
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	answer, _ := res.Val.(dnsAnswer)
+	addrs, err := answer.addrs, res.Err
+
+	var dnsErr *net.DNSError
+	switch {
+	case err == nil:
+		c.store(host, dnsEntry{addrs: addrs, expires: c.now().Add(boundTTL(answer.ttl, c.ttl))})
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		c.store(host, dnsEntry{err: err, expires: c.now().Add(boundTTL(answer.ttl, c.negativeTTL))})
+	case cached && entry.err == nil:
+		// Serve the stale addresses rather than failing.
+		return entry.addrs, nil
+	}
+	return addrs, err
+}
+
+type dnsAnswer struct {
+	addrs []net.IPAddr
+	ttl   time.Duration
+}
+
+// boundTTL returns the TTL of the records, bounded by max, or max if it is
+// not known.
+func boundTTL(ttl, max time.Duration) time.Duration {
+	if ttl < 0 || ttl > max {
+		return max
+	}
+	return ttl
+}
+
+// Forget removes the host from the cache, e.g. when its addresses are not
+// reachable anymore.
+func (c *DNSCache) Forget(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, host)
+}
+
+func (c *DNSCache) store(host string, entry dnsEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[host]; !ok && len(c.entries) >= dnsCacheMaxEntries {
+		c.evictLocked()
+	}
+	c.entries[host] = entry
+}
+
+// evictLocked makes room for an entry: the expired entries are removed, or
+// an arbitrary one if none has expired.
+func (c *DNSCache) evictLocked() {
+	now := c.now()
+	for host, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, host)
+		}
+	}
+	if len(c.entries) < dnsCacheMaxEntries {
+		return
+	}
+	for host := range c.entries {
+		delete(c.entries, host)
+		return
+	}
+}
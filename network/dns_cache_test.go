@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeResolver answers with its addresses or error, and the TTL of the
+// records unless zero, counting the lookups.
+type fakeResolver struct {
+	addrs   []net.IPAddr
+	ttl     time.Duration
+	err     error
+	lookups int
+}
+
+func (r *fakeResolver) LookupIPAddrTTL(context.Context, string) ([]net.IPAddr, time.Duration, error) {
+	r.lookups++
+	if r.ttl == 0 {
+		return r.addrs, -1, r.err
+	}
+	return r.addrs, r.ttl, r.err
+}
+
+func TestDNSCache(t *testing.T) {
+	ctx := context.Background()
+	addrs := []net.IPAddr{{IP: net.ParseIP("10.0.0.1")}, {IP: net.ParseIP("fd00::1")}}
+	notFound := &net.DNSError{Err: "no such host", Name: "foo", IsNotFound: true}
+	temporary := &net.DNSError{Err: "server misbehaving", Name: "foo", IsTemporary: true}
+
+	now := time.Now()
+	res := &fakeResolver{addrs: addrs}
+	cache := NewDNSCacheWithResolver(res, 5*time.Second, time.Second)
+	cache.now = func() time.Time { return now }
+
+	lookupNegative := func(negative bool, wantAddrs []net.IPAddr, wantErr error, wantLookups int) {
+		t.Helper()
+		got, err := cache.lookup(ctx, "foo", negative)
+		if !errors.Is(err, wantErr) {
+			t.Errorf("LookupIPAddr() = %v, wanted %v", err, wantErr)
+		}
+		if !cmp.Equal(got, wantAddrs) {
+			t.Errorf("LookupIPAddr() = %v, wanted %v", got, wantAddrs)
+		}
+		if res.lookups != wantLookups {
+			t.Errorf("Lookups = %d, wanted %d", res.lookups, wantLookups)
+		}
+	}
+	lookup := func(wantAddrs []net.IPAddr, wantErr error, wantLookups int) {
+		t.Helper()
+		lookupNegative(true, wantAddrs, wantErr, wantLookups)
+	}
+
+	// The addresses are cached for the TTL.
+	lookup(addrs, nil, 1)
+	now = now.Add(4 * time.Second)
+	lookup(addrs, nil, 1)
+
+	// Once expired, the stale addresses are served on temporary failures.
+	now = now.Add(2 * time.Second)
+	res.addrs, res.err = nil, temporary
+	lookup(addrs, nil, 2)
+
+	// The hosts which do not exist are cached for the negative TTL.
+	res.err = notFound
+	lookup(nil, notFound, 3)
+	lookup(nil, notFound, 3)
+	now = now.Add(2 * time.Second)
+	res.addrs, res.err = addrs, nil
+	lookup(addrs, nil, 4)
+
+	// Forgotten hosts are resolved again.
+	cache.Forget("foo")
+	lookup(addrs, nil, 5)
+
+	// The hosts remembered as not existing are resolved again unless
+	// negative.
+	cache.Forget("foo")
+	res.addrs, res.err = nil, notFound
+	lookup(nil, notFound, 6)
+	res.addrs, res.err = addrs, nil
+	lookupNegative(false, addrs, nil, 7)
+	lookupNegative(false, addrs, nil, 7)
+
+	// The TTL of the records is respected, up to that of the cache.
+	cache.Forget("foo")
+	res.ttl = 2 * time.Second
+	lookup(addrs, nil, 8)
+	now = now.Add(3 * time.Second)
+	lookup(addrs, nil, 9)
+	res.ttl = time.Minute
+	now = now.Add(3 * time.Second)
+	lookup(addrs, nil, 10)
+	now = now.Add(4 * time.Second)
+	lookup(addrs, nil, 10)
+	now = now.Add(2 * time.Second)
+	lookup(addrs, nil, 11)
+}
+
+func TestDNSCacheEviction(t *testing.T) {
+	cache := NewDNSCacheWithResolver(&fakeResolver{addrs: []net.IPAddr{{IP: net.ParseIP("10.0.0.1")}}},
+		time.Minute, time.Minute)
+	for i := 0; i < dnsCacheMaxEntries+10; i++ {
+		if _, err := cache.LookupIPAddr(context.Background(), net.IPv4(10, 0, byte(i/256), byte(i)).String()); err != nil {
+			t.Fatal("LookupIPAddr() =", err)
+		}
+	}
+	if got := len(cache.entries); got > dnsCacheMaxEntries {
+		t.Errorf("Entries = %d, wanted at most %d", got, dnsCacheMaxEntries)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -82,6 +83,11 @@ type Drainer struct {
 	// HealthCheckUAPrefixes are the additional user agent prefixes that trigger the
 	// drainer's health check
 	HealthCheckUAPrefixes []string
+
+	// OnDrain is an optional function called asynchronously when Drain
+	// starts draining, e.g. to drain the websocket connections of the process
+	// (see websocket.ManagedConnection.Drain).
+	OnDrain func()
 }
 
 // Ensure Drainer implements http.Handler
@@ -144,6 +150,9 @@ func (d *Drainer) Drain() {
 		d.drainCh = drainCh
 		d.resetCh = resetCh
 		d.timer = timer
+		if d.OnDrain != nil {
+			go d.OnDrain()
+		}
 		return drainCh
 	}()
 
@@ -185,6 +194,26 @@ func (d *Drainer) Reset() {
 	}
 }
 
+// DrainAndShutdown gracefully stops the server serving through the Drainer,
+// e.g. once its listener has been handed off to the process replacing it
+// (see network.HandoffListener): it blocks in Drain until no request has
+// been received for QuietPeriod, and then shuts the server down, closing its
+// listeners and waiting for in-flight requests to complete or ctx to be done.
+func (d *Drainer) DrainAndShutdown(ctx context.Context, server *http.Server) error {
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		d.Drain()
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return server.Shutdown(ctx)
+}
+
 func (d *Drainer) resetTimer() {
 	if func() bool {
 		d.RLock()
@@ -17,6 +17,8 @@ limitations under the License.
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -586,3 +588,63 @@ func TestResetWithActiveRequests(t *testing.T) {
 	// We need requests to be active for a bit
 	time.Sleep(time.Second)
 }
+
+func TestDrainAndShutdown(t *testing.T) {
+	d := &Drainer{
+		Inner:       http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		QuietPeriod: 100 * time.Millisecond,
+	}
+	server := httptest.NewServer(d)
+	defer server.Close()
+
+	start := time.Now()
+	if err := d.DrainAndShutdown(context.Background(), server.Config); err != nil {
+		t.Fatal("DrainAndShutdown() =", err)
+	}
+	if took := time.Since(start); took < d.QuietPeriod {
+		t.Errorf("DrainAndShutdown() returned after %v, before the QuietPeriod", took)
+	}
+
+	// The listener is closed.
+	if _, err := http.Get(server.URL); err == nil {
+		t.Error("Get() succeeded after DrainAndShutdown")
+	}
+}
+
+func TestDrainAndShutdownCanceled(t *testing.T) {
+	d := &Drainer{
+		Inner:       http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		QuietPeriod: time.Hour,
+	}
+	server := httptest.NewServer(d)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := d.DrainAndShutdown(ctx, server.Config); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DrainAndShutdown() = %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestOnDrain(t *testing.T) {
+	called := make(chan struct{}, 2)
+	d := &Drainer{
+		Inner:       http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		QuietPeriod: 10 * time.Millisecond,
+		OnDrain:     func() { called <- struct{}{} },
+	}
+	d.Drain()
+	// Draining again does not call OnDrain again.
+	d.Drain()
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("OnDrain was not called")
+	}
+	select {
+	case <-called:
+		t.Error("OnDrain was called twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
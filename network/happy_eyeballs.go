@@ -0,0 +1,231 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// defaultFallbackDelay is how long the addresses of the first family are
+// tried alone before those of the other family, as for net.Dialer.
+const defaultFallbackDelay = 300 * time.Millisecond
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// dialCached dials the address, resolving its host through the cache and
+// racing its addresses of both families. The hosts the cache remembers as not
+// existing are resolved again, so that the Services just created can be
+// dialed. The host is forgotten by the cache when its addresses are not
+// reachable, but not when they refuse the connection.
+func dialCached(ctx context.Context, dialer *net.Dialer, cache *DNSCache, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || cache == nil || net.ParseIP(host) != nil {
+		return dialer.DialContext(ctx, network, address)
+	}
+	addrs, err := cache.lookup(ctx, host, false /*negative*/)
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+	}
+	addrs = filterAddrs(network, addrs)
+	if len(addrs) == 0 {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: &net.AddrError{Err: "no suitable address found", Addr: host}}
+	}
+
+	fallbackDelay := dialer.FallbackDelay
+	if fallbackDelay <= 0 {
+		fallbackDelay = defaultFallbackDelay
+	}
+	c, err := dialHappyEyeballs(ctx, dialer.DialContext, network, addrs, port, fallbackDelay)
+	if isUnreachable(err) {
+		cache.Forget(host)
+	}
+	return c, err
+}
+
+// dialCachedTLS is same with dialCached but completes the TLS handshake, like
+// tls.DialWithDialer: the timeout of the dialer covers the handshake, and the
+// server name is that of the host unless set by the config.
+func dialCachedTLS(ctx context.Context, dialer *net.Dialer, cache *DNSCache, network, address string, tlsConf *tls.Config) (net.Conn, error) {
+	if dialer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dialer.Timeout)
+		defer cancel()
+	}
+	c, err := dialCached(ctx, dialer, cache, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConf.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		tlsConf = tlsConf.Clone()
+		tlsConf.ServerName = host
+	}
+	tc := tls.Client(c, tlsConf)
+	if err := tc.HandshakeContext(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return tc, nil
+}
+
+// isUnreachable returns whether the dial error means that the address is not
+// reachable anymore, e.g. as the pod behind it is gone.
+func isUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var errNet net.Error
+	return (errors.As(err, &errNet) && errNet.Timeout()) ||
+		errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH)
+}
+
+// filterAddrs returns the addresses of the families of the network.
+func filterAddrs(network string, addrs []net.IPAddr) []net.IPAddr {
+	var want func(net.IP) bool
+	switch network {
+	case "tcp4", "udp4":
+		want = func(ip net.IP) bool { return ip.To4() != nil }
+	case "tcp6", "udp6":
+		want = func(ip net.IP) bool { return ip.To4() == nil }
+	default:
+		return addrs
+	}
+	filtered := make([]net.IPAddr, 0, len(addrs))
+	for _, addr := range addrs {
+		if want(addr.IP) {
+			filtered = append(filtered, addr)
+		}
+	}
+	return filtered
+}
+
+// dialHappyEyeballs dials the addresses per RFC 6555: the addresses of the
+// family of the first one, the primaries, are dialed in order, and after the
+// fallbackDelay, or as soon as they all failed, the addresses of the other
+// family are dialed in order concurrently. The first connection established
+// is returned, or the error of the primaries.
+func dialHappyEyeballs(ctx context.Context, dial dialFunc, network string, addrs []net.IPAddr, port string, fallbackDelay time.Duration) (net.Conn, error) {
+	primaries, fallbacks := partitionAddrs(addrs)
+	if len(fallbacks) == 0 {
+		return dialSerial(ctx, dial, network, primaries, port)
+	}
+
+	type dialResult struct {
+		net.Conn
+		error
+		primary bool
+		done    bool
+	}
+	results := make(chan dialResult) // Unbuffered.
+	returned := make(chan struct{})
+	defer close(returned)
+
+	race := func(ctx context.Context, primary bool) {
+		addrs := primaries
+		if !primary {
+			addrs = fallbacks
+		}
+		c, err := dialSerial(ctx, dial, network, addrs, port)
+		select {
+		case results <- dialResult{Conn: c, error: err, primary: primary, done: true}:
+		case <-returned:
+			if c != nil {
+				c.Close()
+			}
+		}
+	}
+
+	var primary, fallback dialResult
+
+	primaryCtx, primaryCancel := context.WithCancel(ctx)
+	defer primaryCancel()
+	go race(primaryCtx, true)
+
+	fallbackTimer := time.NewTimer(fallbackDelay)
+	defer fallbackTimer.Stop()
+	fallbackCtx, fallbackCancel := context.WithCancel(ctx)
+	defer fallbackCancel()
+
+	for {
+		select {
+		case <-fallbackTimer.C:
+			go race(fallbackCtx, false)
+
+		case res := <-results:
+			if res.error == nil {
+				return res.Conn, nil
+			}
+			if res.primary {
+				primary = res
+			} else {
+				fallback = res
+			}
+			if primary.done && fallback.done {
+				return nil, primary.error
+			}
+			if res.primary && fallbackTimer.Stop() {
+				// The primaries failed before the fallback delay: start
+				// the fallbacks right away.
+				fallbackTimer.Reset(0)
+			}
+		}
+	}
+}
+
+// dialSerial dials the addresses in order, returning the first connection
+// established, or the first error.
+func dialSerial(ctx context.Context, dial dialFunc, network string, addrs []net.IPAddr, port string) (net.Conn, error) {
+	var firstErr error
+	for _, addr := range addrs {
+		c, err := dial(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return c, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// partitionAddrs splits the addresses into those of the family of the first
+// one, and the others, keeping their order.
+func partitionAddrs(addrs []net.IPAddr) (primaries, fallbacks []net.IPAddr) {
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+	isV4 := addrs[0].IP.To4() != nil
+	for _, addr := range addrs {
+		if (addr.IP.To4() != nil) == isV4 {
+			primaries = append(primaries, addr)
+		} else {
+			fallbacks = append(fallbacks, addr)
+		}
+	}
+	return primaries, fallbacks
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeConn is the connection to the address it was dialed at.
+type fakeConn struct {
+	net.Conn
+	address string
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func TestDialHappyEyeballs(t *testing.T) {
+	v4 := net.IPAddr{IP: net.ParseIP("10.0.0.1")}
+	v4b := net.IPAddr{IP: net.ParseIP("10.0.0.2")}
+	v6 := net.IPAddr{IP: net.ParseIP("fd00::1")}
+	refused := errors.New("connection refused")
+
+	const (
+		hang = iota
+		fail
+		succeed
+	)
+	tests := []struct {
+		name    string
+		addrs   []net.IPAddr
+		dials   map[string]int
+		want    string
+		wantErr error
+	}{{
+		name:  "single family, first fails",
+		addrs: []net.IPAddr{v4, v4b},
+		dials: map[string]int{"10.0.0.1:80": fail, "10.0.0.2:80": succeed},
+		want:  "10.0.0.2:80",
+	}, {
+		name:  "primary hangs, fallback wins",
+		addrs: []net.IPAddr{v6, v4},
+		dials: map[string]int{"[fd00::1]:80": hang, "10.0.0.1:80": succeed},
+		want:  "10.0.0.1:80",
+	}, {
+		name:  "primary fails, fallback wins",
+		addrs: []net.IPAddr{v6, v4},
+		dials: map[string]int{"[fd00::1]:80": fail, "10.0.0.1:80": succeed},
+		want:  "10.0.0.1:80",
+	}, {
+		name:  "primary wins",
+		addrs: []net.IPAddr{v4, v6},
+		dials: map[string]int{"10.0.0.1:80": succeed, "[fd00::1]:80": succeed},
+		want:  "10.0.0.1:80",
+	}, {
+		name:    "all fail",
+		addrs:   []net.IPAddr{v6, v4},
+		dials:   map[string]int{"[fd00::1]:80": fail, "10.0.0.1:80": fail},
+		wantErr: refused,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dial := func(ctx context.Context, _, address string) (net.Conn, error) {
+				switch tc.dials[address] {
+				case succeed:
+					return &fakeConn{address: address}, nil
+				case fail:
+					return nil, refused
+				default:
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			c, err := dialHappyEyeballs(ctx, dial, "tcp", tc.addrs, "80", 10*time.Millisecond)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("dialHappyEyeballs() = %v, wanted %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := c.(*fakeConn).address; got != tc.want {
+				t.Errorf("Dialed %s, wanted %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDialCached(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(s.Close)
+	_, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	if err != nil {
+		t.Fatal("SplitHostPort() =", err)
+	}
+
+	res := &fakeResolver{addrs: []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}}
+	cache := NewDNSCacheWithResolver(res, time.Minute, time.Minute)
+	dialer := &net.Dialer{Timeout: time.Second}
+
+	for i := 0; i < 2; i++ {
+		c, err := dialCached(context.Background(), dialer, cache, "tcp", net.JoinHostPort("example.com", port))
+		if err != nil {
+			t.Fatal("dialCached() =", err)
+		}
+		c.Close()
+	}
+	if res.lookups != 1 {
+		t.Errorf("Lookups = %d, wanted 1", res.lookups)
+	}
+
+	// The host is not forgotten when its addresses refuse the connection.
+	s.Close()
+	if _, err := dialCached(context.Background(), dialer, cache, "tcp", net.JoinHostPort("example.com", port)); err == nil {
+		t.Fatal("dialCached() = nil, wanted an error")
+	}
+	if _, ok := cache.entries["example.com"]; !ok {
+		t.Error("The host was forgotten")
+	}
+	if _, err := dialCached(context.Background(), dialer, cache, "tcp6", net.JoinHostPort("example.com", port)); err == nil {
+		t.Error("dialCached(tcp6) = nil, wanted an error for the lack of IPv6 addresses")
+	}
+}
+
+func TestDialCachedNotFound(t *testing.T) {
+	notFound := &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}
+	res := &fakeResolver{err: notFound}
+	cache := NewDNSCacheWithResolver(res, time.Minute, time.Minute)
+	dialer := &net.Dialer{Timeout: time.Second}
+
+	if _, err := dialCached(context.Background(), dialer, cache, "tcp", "example.com:80"); !errors.Is(err, notFound) {
+		t.Fatalf("dialCached() = %v, wanted %v", err, notFound)
+	}
+	// The Service was just created: the host is resolved again.
+	res.addrs, res.err = []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}, nil
+	if _, err := dialCached(context.Background(), dialer, cache, "tcp", "example.com:80"); errors.Is(err, notFound) {
+		t.Error("dialCached() = the cached error, wanted the host resolved again")
+	}
+	if res.lookups != 2 {
+		t.Errorf("Lookups = %d, wanted 2", res.lookups)
+	}
+}
+
+func TestDialCachedTLS(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(s.Close)
+	_, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	if err != nil {
+		t.Fatal("SplitHostPort() =", err)
+	}
+
+	res := &fakeResolver{addrs: []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}}
+	cache := NewDNSCacheWithResolver(res, time.Minute, time.Minute)
+	dialer := &net.Dialer{Timeout: time.Second}
+	// The certificate of the server is valid for example.com, which is the
+	// server name unless set by the config.
+	tlsConf := &tls.Config{RootCAs: s.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+
+	for i := 0; i < 2; i++ {
+		c, err := dialCachedTLS(context.Background(), dialer, cache, "tcp", net.JoinHostPort("example.com", port), tlsConf)
+		if err != nil {
+			t.Fatal("dialCachedTLS() =", err)
+		}
+		c.Close()
+	}
+	if res.lookups != 1 {
+		t.Errorf("Lookups = %d, wanted 1", res.lookups)
+	}
+	if tlsConf.ServerName != "" {
+		t.Errorf("ServerName = %q, wanted the config untouched", tlsConf.ServerName)
+	}
+}
+
+func TestIsUnreachable(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{{
+		name: "no error",
+	}, {
+		name: "timeout",
+		err:  &net.OpError{Op: "dial", Err: context.DeadlineExceeded},
+		want: true,
+	}, {
+		name: "host unreachable",
+		err:  &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)},
+		want: true,
+	}, {
+		name: "network unreachable",
+		err:  &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ENETUNREACH)},
+		want: true,
+	}, {
+		name: "connection refused",
+		err:  &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isUnreachable(tc.err); got != tc.want {
+				t.Errorf("isUnreachable() = %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
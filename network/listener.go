@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// ListenFDEnvKey is the environment variable through which a process hands
+// the file descriptor of its listener off to the process replacing it, so
+// that no connection is refused while the replacement starts.
+const ListenFDEnvKey = "K_LISTEN_FD"
+
+// ErrReusePortUnsupported is returned by ListenReusePort on platforms which
+// don't support the SO_REUSEPORT socket option.
+var ErrReusePortUnsupported = errors.New("SO_REUSEPORT is not supported on this platform")
+
+// ListenReusePort announces on the local network address with the
+// SO_REUSEPORT socket option set, so that a new process can bind the same
+// address while the current one drains its connections.
+func ListenReusePort(ctx context.Context, network, address string) (net.Listener, error) {
+	lc := net.ListenConfig{Control: reusePort}
+	return lc.Listen(ctx, network, address)
+}
+
+// Listen returns the listener handed off by the parent process through
+// ListenFDEnvKey, if any, and otherwise announces on the local network
+// address with ListenReusePort.
+func Listen(ctx context.Context, network, address string) (net.Listener, error) {
+	if fd := os.Getenv(ListenFDEnvKey); fd != "" {
+		return inheritedListener(fd)
+	}
+	return ListenReusePort(ctx, network, address)
+}
+
+func inheritedListener(value string) (net.Listener, error) {
+	fd, err := strconv.Atoi(value)
+	if err != nil || fd < 0 {
+		return nil, fmt.Errorf("invalid %s %q", ListenFDEnvKey, value)
+	}
+	f := os.NewFile(uintptr(fd), "listener")
+	if f == nil {
+		return nil, fmt.Errorf("invalid %s %q", ListenFDEnvKey, value)
+	}
+	// FileListener duplicates the descriptor, so close the inherited one.
+	defer f.Close()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inherit listener from %s %q: %w", ListenFDEnvKey, value, err)
+	}
+	// The listener is not handed further down to our own children.
+	os.Unsetenv(ListenFDEnvKey)
+	return l, nil
+}
+
+// HandoffListener prepares cmd, the process replacing the current one, to
+// inherit the listener: its file is passed to cmd and advertised through
+// ListenFDEnvKey, for the new process to pick it up with Listen. The
+// returned file should be closed once cmd has started.
+func HandoffListener(l net.Listener, cmd *exec.Cmd) (*os.File, error) {
+	fl, ok := l.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, fmt.Errorf("listener %T can't be handed off", l)
+	}
+	f, err := fl.File()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the listener's file: %w", err)
+	}
+
+	cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	// The extra files are numbered after stdin, stdout and stderr.
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", ListenFDEnvKey, 2+len(cmd.ExtraFiles)))
+	return f, nil
+}
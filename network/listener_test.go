@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestListenReusePort(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SO_REUSEPORT is not supported on windows")
+	}
+
+	l1, err := ListenReusePort(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("ListenReusePort() =", err)
+	}
+	defer l1.Close()
+
+	// A second listener can bind the same address.
+	l2, err := ListenReusePort(context.Background(), "tcp", l1.Addr().String())
+	if err != nil {
+		t.Fatal("ListenReusePort() =", err)
+	}
+	defer l2.Close()
+
+	// Without SO_REUSEPORT it can't.
+	if l3, err := net.Listen("tcp", l1.Addr().String()); err == nil {
+		l3.Close()
+		t.Error("Listen() succeeded on an address bound without SO_REUSEPORT")
+	}
+}
+
+func TestListenInherited(t *testing.T) {
+	parent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Listen() =", err)
+	}
+	defer parent.Close()
+
+	// Hand the listener off as if to a child process.
+	cmd := &exec.Cmd{Env: []string{}}
+	f, err := HandoffListener(parent, cmd)
+	if err != nil {
+		t.Fatal("HandoffListener() =", err)
+	}
+	if got, want := len(cmd.ExtraFiles), 1; got != want {
+		t.Fatalf("len(ExtraFiles) = %d, want: %d", got, want)
+	}
+	if got, want := cmd.Env, []string{ListenFDEnvKey + "=3"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("Env = %v, want: %v", got, want)
+	}
+
+	// In this process, the descriptor isn't 3, so advertise the real one.
+	t.Setenv(ListenFDEnvKey, fmt.Sprint(f.Fd()))
+	child, err := Listen(context.Background(), "tcp", "does-not-matter")
+	if err != nil {
+		t.Fatal("Listen() =", err)
+	}
+	defer child.Close()
+	if _, ok := os.LookupEnv(ListenFDEnvKey); ok {
+		t.Errorf("%s is still set after inheriting the listener", ListenFDEnvKey)
+	}
+
+	if child.Addr().String() != parent.Addr().String() {
+		t.Errorf("Addr() = %s, want: %s", child.Addr(), parent.Addr())
+	}
+
+	// The parent stops accepting, connections are accepted by the child.
+	parent.Close()
+	go func() {
+		if conn, err := net.Dial("tcp", child.Addr().String()); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := child.Accept()
+	if err != nil {
+		t.Fatal("Accept() =", err)
+	}
+	conn.Close()
+}
+
+func TestListenInvalidFD(t *testing.T) {
+	t.Setenv(ListenFDEnvKey, "not-a-number")
+	if _, err := Listen(context.Background(), "tcp", "127.0.0.1:0"); err == nil {
+		t.Error("Listen() = nil, wanted an error")
+	}
+}
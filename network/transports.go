@@ -67,7 +67,16 @@ var DialWithBackOff = NewBackoffDialer(backOffTemplate)
 // between tries.
 func NewBackoffDialer(backoffConfig wait.Backoff) func(context.Context, string, string) (net.Conn, error) {
 	return func(ctx context.Context, network, address string) (net.Conn, error) {
-		return dialBackOffHelper(ctx, network, address, backoffConfig, nil)
+		return dialBackOffHelper(ctx, network, address, backoffConfig, nil, nil)
+	}
+}
+
+// NewCachingBackoffDialer is same with NewBackoffDialer but resolves the hosts
+// through the cache, and races their IPv4 and IPv6 addresses per RFC 6555
+// ("happy eyeballs").
+func NewCachingBackoffDialer(backoffConfig wait.Backoff, cache *DNSCache) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialBackOffHelper(ctx, network, address, backoffConfig, nil, cache)
 	}
 }
 
@@ -77,11 +86,19 @@ var DialTLSWithBackOff = NewTLSBackoffDialer(backOffTemplate)
 // NewTLSBackoffDialer is same with NewBackoffDialer but takes tls config.
 func NewTLSBackoffDialer(backoffConfig wait.Backoff) func(context.Context, string, string, *tls.Config) (net.Conn, error) {
 	return func(ctx context.Context, network, address string, tlsConf *tls.Config) (net.Conn, error) {
-		return dialBackOffHelper(ctx, network, address, backoffConfig, tlsConf)
+		return dialBackOffHelper(ctx, network, address, backoffConfig, tlsConf, nil)
+	}
+}
+
+// NewCachingTLSBackoffDialer is same with NewCachingBackoffDialer but takes tls
+// config.
+func NewCachingTLSBackoffDialer(backoffConfig wait.Backoff, cache *DNSCache) func(context.Context, string, string, *tls.Config) (net.Conn, error) {
+	return func(ctx context.Context, network, address string, tlsConf *tls.Config) (net.Conn, error) {
+		return dialBackOffHelper(ctx, network, address, backoffConfig, tlsConf, cache)
 	}
 }
 
-func dialBackOffHelper(ctx context.Context, network, address string, bo wait.Backoff, tlsConf *tls.Config) (net.Conn, error) {
+func dialBackOffHelper(ctx context.Context, network, address string, bo wait.Backoff, tlsConf *tls.Config, cache *DNSCache) (net.Conn, error) {
 	dialer := &net.Dialer{
 		Timeout:   bo.Duration, // Initial duration.
 		KeepAlive: 5 * time.Second,
@@ -93,10 +110,15 @@ func dialBackOffHelper(ctx context.Context, network, address string, bo wait.Bac
 			c   net.Conn
 			err error
 		)
-		if tlsConf == nil {
+		switch {
+		case cache == nil && tlsConf == nil:
 			c, err = dialer.DialContext(ctx, network, address)
-		} else {
+		case cache == nil:
 			c, err = tls.DialWithDialer(dialer, network, address, tlsConf)
+		case tlsConf == nil:
+			c, err = dialCached(ctx, dialer, cache, network, address)
+		default:
+			c, err = dialCachedTLS(ctx, dialer, cache, network, address, tlsConf)
 		}
 		if err != nil {
 			var errNet net.Error
@@ -27,6 +27,8 @@ import (
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/pkg/logging"
 )
 
 const (
@@ -46,6 +48,7 @@ const (
 // whether the handler is active
 type Handler struct {
 	enabled *atomic.Bool
+	mux     *http.ServeMux
 	handler http.Handler
 	log     *zap.SugaredLogger
 }
@@ -68,7 +71,8 @@ func NewHandler(logger *zap.SugaredLogger, enableProfiling bool) *Handler {
 
 	return &Handler{
 		enabled: &enabled,
-		handler: mux,
+		mux:     mux,
+		handler: logging.NewRequestLoggingHandler(logger, mux),
 		log:     logger,
 	}
 }
@@ -81,6 +85,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Handle registers an additional debug handler for the given pattern, which
+// is served alongside the profiling data while profiling is enabled.
+func (h *Handler) Handle(pattern string, handler http.Handler) {
+	h.mux.Handle(pattern, handler)
+}
+
 func ReadProfilingFlag(config map[string]string) (bool, error) {
 	profiling, ok := config[profilingKey]
 	if !ok {
@@ -101,3 +101,28 @@ func TestUpdateFromConfigMap(t *testing.T) {
 		})
 	}
 }
+
+func TestHandle(t *testing.T) {
+	handler := NewHandler(zap.NewNop().Sugar(), false)
+	handler.Handle("/debug/foo", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	for _, enabled := range []bool{false, true} {
+		handler.enabled.Store(enabled)
+		req, err := http.NewRequest(http.MethodGet, "/debug/foo", nil)
+		if err != nil {
+			t.Fatal("Error creating request:", err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		want := http.StatusNotFound
+		if enabled {
+			want = http.StatusTeapot
+		}
+		if rr.Code != want {
+			t.Errorf("StatusCode with profiling enabled %v: %v, want: %v", enabled, rr.Code, want)
+		}
+	}
+}
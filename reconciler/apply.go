@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// StatusApplyPatch returns the server-side apply patch of the status of the
+// existing resource to desiredStatus, for the given field manager. The patch
+// holds the fields of desiredStatus which differ from existingStatus, and
+// those the field manager already owns, per the managed fields of existing.
+// Thereby the status fields owned by other field managers are left alone,
+// and those the field manager owns but no longer sets are removed.
+func StatusApplyPatch(gvk schema.GroupVersionKind, fieldManager string, existing metav1.Object, existingStatus, desiredStatus interface{}) ([]byte, error) {
+	before, err := toJSONMap(existingStatus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the existing status: %w", err)
+	}
+	after, err := toJSONMap(desiredStatus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the desired status: %w", err)
+	}
+	owned, err := ownedStatusFields(existing, fieldManager)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{"name": existing.GetName()}
+	if ns := existing.GetNamespace(); ns != "" {
+		metadata["namespace"] = ns
+	}
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   metadata,
+		"status":     mergeJSONMaps(ownedValues(after, owned), changedValues(before, after)),
+	})
+}
+
+// ownedStatusFields returns the status fields which the field manager
+// applied, as the FieldsV1 tree under "f:status".
+func ownedStatusFields(obj metav1.Object, fieldManager string) (map[string]interface{}, error) {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != fieldManager || entry.Operation != metav1.ManagedFieldsOperationApply ||
+			entry.Subresource != "status" || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, fmt.Errorf("failed to parse the managed fields of %q: %w", fieldManager, err)
+		}
+		status, _ := fields["f:status"].(map[string]interface{})
+		return status, nil
+	}
+	return nil, nil
+}
+
+// ownedValues returns the values of the fields of the FieldsV1 tree owned,
+// down to the nested objects whose fields are owned individually.
+func ownedValues(values, owned map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(owned))
+	for key, sub := range owned {
+		if !strings.HasPrefix(key, "f:") {
+			continue
+		}
+		name := strings.TrimPrefix(key, "f:")
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		subFields, _ := sub.(map[string]interface{})
+		if nested, ok := value.(map[string]interface{}); ok && hasFieldKeys(subFields) {
+			out[name] = ownedValues(nested, subFields)
+		} else {
+			out[name] = value
+		}
+	}
+	return out
+}
+
+// hasFieldKeys returns whether the FieldsV1 tree owns fields of an object.
+func hasFieldKeys(fields map[string]interface{}) bool {
+	for key := range fields {
+		if strings.HasPrefix(key, "f:") {
+			return true
+		}
+	}
+	return false
+}
+
+// changedValues returns the values of after which differ from those of
+// before, down to the nested objects.
+func changedValues(before, after map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for name, value := range after {
+		old, ok := before[name]
+		if ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		oldMap, oldIsMap := old.(map[string]interface{})
+		newMap, newIsMap := value.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			out[name] = changedValues(oldMap, newMap)
+		} else {
+			out[name] = value
+		}
+	}
+	return out
+}
+
+// mergeJSONMaps returns the values of a overlaid with those of b, down to
+// the nested objects.
+func mergeJSONMaps(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a)+len(b))
+	for name, value := range a {
+		out[name] = value
+	}
+	for name, value := range b {
+		aMap, aIsMap := out[name].(map[string]interface{})
+		bMap, bIsMap := value.(map[string]interface{})
+		if aIsMap && bIsMap {
+			out[name] = mergeJSONMaps(aMap, bMap)
+		} else {
+			out[name] = value
+		}
+	}
+	return out
+}
+
+func toJSONMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
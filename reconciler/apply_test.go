@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type applyStatus struct {
+	Phase    string            `json:"phase,omitempty"`
+	Replicas int               `json:"replicas,omitempty"`
+	Address  *applyAddress     `json:"address,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type applyAddress struct {
+	URL  string `json:"url,omitempty"`
+	Host string `json:"host,omitempty"`
+}
+
+func TestStatusApplyPatch(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}
+	managed := func(manager, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager:     manager,
+			Operation:   metav1.ManagedFieldsOperationApply,
+			Subresource: "status",
+			FieldsV1:    &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		managed  []metav1.ManagedFieldsEntry
+		existing applyStatus
+		desired  applyStatus
+		want     map[string]interface{}
+	}{{
+		name:     "first apply",
+		existing: applyStatus{Phase: "Pending", Replicas: 3},
+		desired:  applyStatus{Phase: "Ready", Replicas: 3},
+		want:     map[string]interface{}{"phase": "Ready"},
+	}, {
+		name:     "owned fields are kept",
+		managed:  []metav1.ManagedFieldsEntry{managed("foo-controller", `{"f:status":{"f:replicas":{}}}`)},
+		existing: applyStatus{Phase: "Pending", Replicas: 3},
+		desired:  applyStatus{Phase: "Ready", Replicas: 3},
+		want:     map[string]interface{}{"phase": "Ready", "replicas": float64(3)},
+	}, {
+		name: "fields of the other managers are left alone",
+		managed: []metav1.ManagedFieldsEntry{
+			managed("bar-controller", `{"f:status":{"f:phase":{}}}`),
+			managed("foo-controller", `{"f:status":{"f:address":{"f:url":{}}}}`),
+		},
+		existing: applyStatus{Phase: "Ready", Address: &applyAddress{URL: "http://a", Host: "a"}},
+		desired:  applyStatus{Phase: "Ready", Address: &applyAddress{URL: "http://b", Host: "a"}, Labels: map[string]string{"x": "y"}},
+		want: map[string]interface{}{
+			"address": map[string]interface{}{"url": "http://b"},
+			"labels":  map[string]interface{}{"x": "y"},
+		},
+	}, {
+		name:     "owned fields no longer set are omitted",
+		managed:  []metav1.ManagedFieldsEntry{managed("foo-controller", `{"f:status":{"f:phase":{},"f:replicas":{}}}`)},
+		existing: applyStatus{Phase: "Ready", Replicas: 3},
+		desired:  applyStatus{Phase: "Ready"},
+		want:     map[string]interface{}{"phase": "Ready"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			existing := &metav1.ObjectMeta{Name: "foo", Namespace: "bar", ManagedFields: tc.managed}
+			b, err := StatusApplyPatch(gvk, "foo-controller", existing, tc.existing, tc.desired)
+			if err != nil {
+				t.Fatal("StatusApplyPatch() =", err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal("Unmarshal() =", err)
+			}
+			want := map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Foo",
+				"metadata":   map[string]interface{}{"name": "foo", "namespace": "bar"},
+				"status":     tc.want,
+			}
+			if !cmp.Equal(got, want) {
+				t.Error("StatusApplyPatch() (-want, +got) =", cmp.Diff(want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// StatusConflictStrategy is how the generated reconcilers resolve the
+// conflicts of their status updates, i.e. when the status was written by
+// another since the reconciliation read it.
+type StatusConflictStrategy string
+
+const (
+	// StatusLastWriterWins writes the status of the reconciliation over the
+	// latest one. This is the default.
+	StatusLastWriterWins StatusConflictStrategy = ""
+
+	// StatusMergeConditions writes the status of the reconciliation over the
+	// latest one, except for the conditions which the reconciliation did not
+	// change: those keep their latest value, so the concurrent updates of the
+	// other conditions are preserved.
+	StatusMergeConditions StatusConflictStrategy = "MergeConditions"
+
+	// StatusFailOnConflict fails the status update, so that the resource is
+	// requeued and reconciled again from its latest state.
+	StatusFailOnConflict StatusConflictStrategy = "FailOnConflict"
+)
+
+// ErrStatusConflict is returned by StatusConflictStrategy.Resolve for
+// StatusFailOnConflict.
+var ErrStatusConflict = errors.New("the status was updated since it was read")
+
+// Resolve resolves the conflict of the status update of a resource per the
+// strategy: it sets desired, the status of the reconciliation, to the status
+// to write over latest, the status written since the reconciliation read
+// original. The statuses are pointers to the status structs. It returns an
+// error to fail the update instead.
+func (s StatusConflictStrategy) Resolve(original, latest, desired interface{}) error {
+	switch s {
+	case StatusLastWriterWins:
+		return nil
+	case StatusFailOnConflict:
+		return ErrStatusConflict
+	case StatusMergeConditions:
+		return mergeConditions(original, latest, desired)
+	default:
+		return fmt.Errorf("unknown status conflict strategy %q", s)
+	}
+}
+
+// mergeConditions sets the conditions of desired to those of latest, with
+// the changes of the conditions between original and desired, by type.
+func mergeConditions(original, latest, desired interface{}) error {
+	before, err := conditionsByType(original)
+	if err != nil {
+		return err
+	}
+	current, err := conditionsByType(latest)
+	if err != nil {
+		return err
+	}
+	status, err := toJSONMap(desired)
+	if err != nil {
+		return err
+	}
+	wanted, _ := status["conditions"].([]interface{})
+
+	// Start from the latest conditions, in their order.
+	latestStatus, err := toJSONMap(latest)
+	if err != nil {
+		return err
+	}
+	merged, _ := latestStatus["conditions"].([]interface{})
+	merged = append([]interface{}(nil), merged...)
+
+	seen := make(map[string]bool, len(wanted))
+	for _, c := range wanted {
+		typ := conditionType(c)
+		seen[typ] = true
+		if old, ok := before[typ]; ok && reflect.DeepEqual(old, c) {
+			// Unchanged by the reconciliation.
+			continue
+		}
+		if _, ok := current[typ]; ok {
+			merged = replaceCondition(merged, typ, c)
+		} else {
+			merged = append(merged, c)
+		}
+	}
+	// Remove the conditions removed by the reconciliation.
+	for typ := range before {
+		if !seen[typ] {
+			merged = replaceCondition(merged, typ, nil)
+		}
+	}
+
+	if len(merged) == 0 {
+		delete(status, "conditions")
+	} else {
+		status["conditions"] = merged
+	}
+	b, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	// Reset desired, so that the fields absent from the JSON are cleared.
+	v := reflect.ValueOf(desired)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("desired must be a pointer, got %T", desired)
+	}
+	v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	return json.Unmarshal(b, desired)
+}
+
+// conditionsByType returns the JSON conditions of the status, by type.
+func conditionsByType(status interface{}) (map[string]interface{}, error) {
+	m, err := toJSONMap(status)
+	if err != nil {
+		return nil, err
+	}
+	conditions, _ := m["conditions"].([]interface{})
+	byType := make(map[string]interface{}, len(conditions))
+	for _, c := range conditions {
+		byType[conditionType(c)] = c
+	}
+	return byType, nil
+}
+
+func conditionType(condition interface{}) string {
+	c, _ := condition.(map[string]interface{})
+	typ, _ := c["type"].(string)
+	return typ
+}
+
+// replaceCondition replaces the condition of the given type with c, or
+// removes it if c is nil.
+func replaceCondition(conditions []interface{}, typ string, c interface{}) []interface{} {
+	out := conditions[:0]
+	for _, existing := range conditions {
+		if conditionType(existing) != typ {
+			out = append(out, existing)
+		} else if c != nil {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestStatusConflictStrategy(t *testing.T) {
+	cond := func(typ apis.ConditionType, status corev1.ConditionStatus) apis.Condition {
+		return apis.Condition{Type: typ, Status: status}
+	}
+	original := duckv1.Status{
+		ObservedGeneration: 1,
+		Conditions: duckv1.Conditions{
+			cond("Ready", corev1.ConditionUnknown),
+			cond("A", corev1.ConditionUnknown),
+			cond("B", corev1.ConditionUnknown),
+			cond("C", corev1.ConditionTrue),
+		},
+	}
+	// Another writer set B and added D since the status was read.
+	latest := duckv1.Status{
+		ObservedGeneration: 1,
+		Conditions: duckv1.Conditions{
+			cond("Ready", corev1.ConditionUnknown),
+			cond("A", corev1.ConditionUnknown),
+			cond("B", corev1.ConditionTrue),
+			cond("C", corev1.ConditionTrue),
+			cond("D", corev1.ConditionFalse),
+		},
+	}
+	// The reconciliation set A, added E and removed C.
+	desired := duckv1.Status{
+		ObservedGeneration: 2,
+		Conditions: duckv1.Conditions{
+			cond("Ready", corev1.ConditionUnknown),
+			cond("A", corev1.ConditionTrue),
+			cond("B", corev1.ConditionUnknown),
+			cond("E", corev1.ConditionTrue),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		strategy StatusConflictStrategy
+		want     duckv1.Status
+		wantErr  error
+	}{{
+		name:     "last writer wins",
+		strategy: StatusLastWriterWins,
+		want:     desired,
+	}, {
+		name:     "fail on conflict",
+		strategy: StatusFailOnConflict,
+		want:     desired,
+		wantErr:  ErrStatusConflict,
+	}, {
+		name:     "merge conditions",
+		strategy: StatusMergeConditions,
+		want: duckv1.Status{
+			ObservedGeneration: 2,
+			Conditions: duckv1.Conditions{
+				cond("Ready", corev1.ConditionUnknown),
+				cond("A", corev1.ConditionTrue),
+				cond("B", corev1.ConditionTrue),
+				cond("D", corev1.ConditionFalse),
+				cond("E", corev1.ConditionTrue),
+			},
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := *desired.DeepCopy()
+			if err := tc.strategy.Resolve(&original, &latest, &got); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Resolve() = %v, wanted %v", err, tc.wantErr)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Error("Resolve() (-want, +got) =", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+
+	if err := StatusConflictStrategy("Bogus").Resolve(&original, &latest, &duckv1.Status{}); err == nil {
+		t.Error("Resolve() = nil, wanted an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"knative.dev/pkg/apis/duck"
+)
+
+// AnyField matches any field of an object, or any item of a list, in the
+// paths ignored by DetectDrift.
+const AnyField = "*"
+
+// Drift is how an observed resource differs from the desired one.
+type Drift struct {
+	// Fields are the paths of the fields of the observed resource which
+	// differ from the desired ones, e.g. "spec.replicas", sorted.
+	Fields []string
+
+	// Patch is the JSON patch of the observed resource to the desired
+	// state, to be sent with types.JSONPatchType.
+	Patch duck.JSONPatch
+}
+
+// DriftOption customizes DetectDrift.
+type DriftOption func(*driftOptions)
+
+type driftOptions struct {
+	ignored [][]string
+}
+
+// IgnoreField has DetectDrift ignore the field at the path, given field by
+// field from the root of the resource, e.g. "metadata", "annotations",
+// "serving.knative.dev/creator". AnyField matches any field or list item,
+// e.g. "spec", "template", "spec", "containers", AnyField, "image".
+func IgnoreField(path ...string) DriftOption {
+	return func(o *driftOptions) {
+		o.ignored = append(o.ignored, path)
+	}
+}
+
+// DetectDrift returns how the observed resource drifted from the desired
+// one, or nil when it did not, e.g. to update a child resource only when
+// needed. Only the fields set in desired are compared, as the others are
+// typically defaulted by the API server or other controllers: the observed
+// resource has drifted when it differs semantically, per
+// equality.Semantic, from itself with the fields of desired applied, where
+// the items of the lists of the same length are applied one by one, and the
+// others replace the lists. The status of the resources, and the fields
+// ignored per the options, are never compared.
+//
+// desired and observed are pointers to resources of the same type, e.g.
+// *appsv1.Deployment, or unstructured.Unstructured.
+func DetectDrift(desired, observed interface{}, opts ...DriftOption) (*Drift, error) {
+	o := &driftOptions{ignored: [][]string{{"status"}}}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	want, err := toJSONMap(desired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the desired resource: %w", err)
+	}
+	have, err := toJSONMap(observed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the observed resource: %w", err)
+	}
+	for _, path := range o.ignored {
+		dropField(want, path)
+	}
+	applied := applyFields(have, want).(map[string]interface{})
+
+	// Compare the typed resources, for the semantic equality of e.g. the
+	// quantities. The observed resource is also round-tripped through JSON,
+	// which e.g. truncates its timestamps.
+	typ := reflect.TypeOf(observed).Elem()
+	haveObj, err := fromJSONMap(have, typ)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the observed resource: %w", err)
+	}
+	appliedObj, err := fromJSONMap(applied, typ)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the applied resource: %w", err)
+	}
+	if equality.Semantic.DeepEqual(haveObj, appliedObj) {
+		return nil, nil
+	}
+
+	patch, err := duck.CreatePatch(have, applied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the patch: %w", err)
+	}
+	fields := make([]string, 0, len(patch))
+	for _, op := range patch {
+		fields = append(fields, strings.ReplaceAll(strings.TrimPrefix(op.Path, "/"), "/", "."))
+	}
+	sort.Strings(fields)
+	return &Drift{Fields: fields, Patch: patch}, nil
+}
+
+// fromJSONMap returns a pointer to the value of the type unmarshaled from m.
+func fromJSONMap(m map[string]interface{}, typ reflect.Type) (interface{}, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.New(typ).Interface()
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// applyFields returns the JSON value have with the fields set in want
+// applied, see DetectDrift. The nulls of want are not applied, as they are
+// the zero values of e.g. the creation timestamps.
+func applyFields(have, want interface{}) interface{} {
+	switch want := want.(type) {
+	case nil:
+		return have
+	case map[string]interface{}:
+		haveMap, _ := have.(map[string]interface{})
+		out := make(map[string]interface{}, len(haveMap)+len(want))
+		for name, value := range haveMap {
+			out[name] = value
+		}
+		for name, value := range want {
+			if applied := applyFields(haveMap[name], value); applied != nil {
+				out[name] = applied
+			}
+		}
+		return out
+	case []interface{}:
+		haveList, ok := have.([]interface{})
+		if !ok || len(haveList) != len(want) {
+			return want
+		}
+		out := make([]interface{}, len(want))
+		for i := range want {
+			out[i] = applyFields(haveList[i], want[i])
+		}
+		return out
+	default:
+		return want
+	}
+}
+
+// dropField removes the field at the path from the JSON value.
+func dropField(v interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			if path[0] != AnyField && path[0] != name {
+				continue
+			}
+			if len(path) == 1 {
+				delete(v, name)
+			} else {
+				dropField(value, path[1:])
+			}
+		}
+	case []interface{}:
+		if path[0] != AnyField {
+			return
+		}
+		for _, value := range v {
+			dropField(value, path[1:])
+		}
+	}
+}
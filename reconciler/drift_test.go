@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func desiredDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "foo",
+			Labels:    map[string]string{"app": "foo"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(2),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "foo",
+						Image: "foo:v1",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// observedDeployment returns the desired Deployment as defaulted and
+// annotated by the API server and other controllers.
+func observedDeployment() *appsv1.Deployment {
+	d := desiredDeployment()
+	d.ResourceVersion = "42"
+	d.CreationTimestamp = metav1.Now()
+	d.Annotations = map[string]string{"deployment.kubernetes.io/revision": "1"}
+	d.Spec.RevisionHistoryLimit = pointer.Int32(10)
+	c := &d.Spec.Template.Spec.Containers[0]
+	c.TerminationMessagePath = corev1.TerminationMessagePathDefault
+	c.ImagePullPolicy = corev1.PullIfNotPresent
+	c.Resources.Requests[corev1.ResourceCPU] = resource.MustParse("1000m")
+	d.Status.Replicas = 2
+	return d
+}
+
+func TestDetectDrift(t *testing.T) {
+	tests := []struct {
+		name     string
+		desired  func(*appsv1.Deployment)
+		observed func(*appsv1.Deployment)
+		opts     []DriftOption
+		want     []string
+	}{{
+		name: "defaulted",
+	}, {
+		name:    "changed field",
+		desired: func(d *appsv1.Deployment) { d.Spec.Replicas = pointer.Int32(3) },
+		want:    []string{"spec.replicas"},
+	}, {
+		name: "changed list item",
+		desired: func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers[0].Image = "foo:v2"
+		},
+		want: []string{"spec.template.spec.containers.0.image"},
+	}, {
+		name: "added list item",
+		desired: func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{
+				Name:  "bar",
+				Image: "bar:v1",
+			})
+		},
+		want: []string{
+			"spec.template.spec.containers.0.imagePullPolicy",
+			"spec.template.spec.containers.0.terminationMessagePath",
+			"spec.template.spec.containers.1",
+		},
+	}, {
+		name:     "changed label",
+		observed: func(d *appsv1.Deployment) { d.Labels["app"] = "bar" },
+		want:     []string{"metadata.labels.app"},
+	}, {
+		name:    "ignored field",
+		desired: func(d *appsv1.Deployment) { d.Spec.Replicas = pointer.Int32(3) },
+		opts:    []DriftOption{IgnoreField("spec", "replicas")},
+	}, {
+		name: "ignored field of any item",
+		desired: func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers[0].Image = "foo:v2"
+		},
+		opts: []DriftOption{IgnoreField("spec", "template", "spec", "containers", AnyField, "image")},
+	}, {
+		name:    "status",
+		desired: func(d *appsv1.Deployment) { d.Status.Replicas = 5 },
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			desired, observed := desiredDeployment(), observedDeployment()
+			if tc.desired != nil {
+				tc.desired(desired)
+			}
+			if tc.observed != nil {
+				tc.observed(observed)
+			}
+			before := observed.DeepCopy()
+
+			drift, err := DetectDrift(desired, observed, tc.opts...)
+			if err != nil {
+				t.Fatal("DetectDrift() =", err)
+			}
+			if !cmp.Equal(observed, before) {
+				t.Error("DetectDrift() modified the observed resource (-before, +after):", cmp.Diff(before, observed))
+			}
+			if tc.want == nil {
+				if drift != nil {
+					t.Fatalf("DetectDrift() = %v, %s, wanted no drift", drift.Fields, drift.Patch)
+				}
+				return
+			}
+			if drift == nil {
+				t.Fatalf("DetectDrift() = nil, wanted drift of %v", tc.want)
+			}
+			if !cmp.Equal(drift.Fields, tc.want) {
+				t.Error("Fields (-want, +got) =", cmp.Diff(tc.want, drift.Fields))
+			}
+
+			// Once patched, the observed resource no longer drifts.
+			rawPatch, err := drift.Patch.MarshalJSON()
+			if err != nil {
+				t.Fatal("MarshalJSON() =", err)
+			}
+			patch, err := jsonpatch.DecodePatch(rawPatch)
+			if err != nil {
+				t.Fatal("DecodePatch() =", err)
+			}
+			raw, err := json.Marshal(observed)
+			if err != nil {
+				t.Fatal("Marshal() =", err)
+			}
+			if raw, err = patch.Apply(raw); err != nil {
+				t.Fatal("Apply() =", err)
+			}
+			patched := &appsv1.Deployment{}
+			if err := json.Unmarshal(raw, patched); err != nil {
+				t.Fatal("Unmarshal() =", err)
+			}
+			if drift, err := DetectDrift(desired, patched, tc.opts...); err != nil || drift != nil {
+				t.Errorf("DetectDrift(patched) = %v, %v, wanted no drift", drift, err)
+			}
+		})
+	}
+}
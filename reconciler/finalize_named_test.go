@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/client/injection/kube/reconciler/core/v1/configmap"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/reconciler"
+)
+
+// finalizingReconciler finalizes the ConfigMaps both with FinalizeKind and
+// with a named finalizer, with the generated reconciler of the ConfigMaps.
+type finalizingReconciler struct {
+	finalizeKind  reconciler.Event
+	finalizeNamed reconciler.Event
+}
+
+var (
+	_ configmap.Finalizer       = (*finalizingReconciler)(nil)
+	_ configmap.NamedFinalizers = (*finalizingReconciler)(nil)
+)
+
+func (r *finalizingReconciler) ReconcileKind(context.Context, *corev1.ConfigMap) reconciler.Event {
+	return nil
+}
+
+func (r *finalizingReconciler) FinalizeKind(context.Context, *corev1.ConfigMap) reconciler.Event {
+	return r.finalizeKind
+}
+
+func (r *finalizingReconciler) FinalizerNames() []string {
+	return []string{"external"}
+}
+
+func (r *finalizingReconciler) FinalizeNamed(context.Context, string, *corev1.ConfigMap) reconciler.Event {
+	return r.finalizeNamed
+}
+
+func TestFinalizeKindAndNamed(t *testing.T) {
+	boom := errors.New("boom")
+	kindFailed := errors.New("kind failed")
+	normal := reconciler.NewEvent(corev1.EventTypeNormal, "Finalized", "finalized")
+
+	tests := []struct {
+		name          string
+		finalizeKind  reconciler.Event
+		finalizeNamed reconciler.Event
+		want          error
+	}{{
+		name: "both clean",
+	}, {
+		name:         "normal event and named failure",
+		finalizeKind: normal,
+		// The failure of the named finalizer is not dropped.
+		finalizeNamed: boom,
+		want:          boom,
+	}, {
+		name:          "clean and named failure",
+		finalizeNamed: boom,
+		want:          boom,
+	}, {
+		name:          "both failures",
+		finalizeKind:  kindFailed,
+		finalizeNamed: boom,
+		want:          kindFailed,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := logtesting.TestContextWithLogger(t)
+			now := metav1.Now()
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         "ns",
+					Name:              "foo",
+					DeletionTimestamp: &now,
+					Finalizers:        []string{"configmaps.core", "external"},
+				},
+			}
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			indexer.Add(cm)
+
+			r := configmap.NewReconciler(ctx, logtesting.TestLogger(t), fake.NewSimpleClientset(cm),
+				corev1listers.NewConfigMapLister(indexer), record.NewFakeRecorder(10),
+				&finalizingReconciler{finalizeKind: tc.finalizeKind, finalizeNamed: tc.finalizeNamed})
+			r.(reconciler.LeaderAware).Promote(reconciler.UniversalBucket(), func(reconciler.Bucket, types.NamespacedName) {})
+
+			err := r.Reconcile(ctx, "ns/foo")
+			if (tc.want == nil) != (err == nil) || (tc.want != nil && !errors.Is(err, tc.want)) {
+				t.Errorf("Reconcile() = %v, wanted %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// FinalizerRegistry is the set of the finalizers managed by a reconciler
+// with several finalizers, e.g. one per external system it cleans up after,
+// each finalized independently of the others. The generated reconcilers
+// build it from the FinalizerNames of their NamedFinalizers.
+type FinalizerRegistry struct {
+	names []string
+}
+
+// NewFinalizerRegistry returns the registry of the finalizers of the given
+// names.
+func NewFinalizerRegistry(names ...string) FinalizerRegistry {
+	return FinalizerRegistry{names: sets.NewString(names...).List()}
+}
+
+// Names returns the names of the finalizers of the registry, sorted.
+func (fr FinalizerRegistry) Names() []string {
+	return fr.names
+}
+
+// Ensure returns the finalizers with those of the registry added, and
+// whether any was missing.
+func (fr FinalizerRegistry) Ensure(finalizers []string) ([]string, bool) {
+	existing := sets.NewString(finalizers...)
+	changed := false
+	for _, name := range fr.names {
+		if !existing.Has(name) {
+			finalizers = append(finalizers, name)
+			changed = true
+		}
+	}
+	return finalizers, changed
+}
+
+// Finalize calls finalize for each finalizer of the registry still among
+// the finalizers. It returns the finalizers without those finalized cleanly,
+// i.e. for which finalize returned nil or a Normal type Event, whether any
+// was removed, and the first of the other events, if any. The finalizers not
+// finalized cleanly are kept, to be finalized again later.
+func (fr FinalizerRegistry) Finalize(finalizers []string, finalize func(name string) Event) ([]string, bool, Event) {
+	existing := sets.NewString(finalizers...)
+	removed := false
+	var result Event
+	for _, name := range fr.names {
+		if !existing.Has(name) {
+			continue
+		}
+		if event := finalize(name); !IsCleanEvent(event) {
+			if result == nil {
+				result = event
+			}
+			continue
+		}
+		existing.Delete(name)
+		removed = true
+	}
+	if !removed {
+		return finalizers, false, result
+	}
+	return existing.List(), true, result
+}
+
+// IsCleanEvent returns whether the event lets a finalizer be removed, i.e.
+// whether it is nil or a Normal type Event.
+func IsCleanEvent(event Event) bool {
+	if event == nil {
+		return true
+	}
+	var e *ReconcilerEvent
+	return EventAs(event, &e) && e.EventType == corev1.EventTypeNormal
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFinalizerRegistryEnsure(t *testing.T) {
+	fr := NewFinalizerRegistry("s3.example.com", "dns.example.com", "s3.example.com")
+	if got, want := fr.Names(), []string{"dns.example.com", "s3.example.com"}; !cmp.Equal(got, want) {
+		t.Errorf("Names() = %v, wanted %v", got, want)
+	}
+
+	tests := []struct {
+		name        string
+		finalizers  []string
+		want        []string
+		wantChanged bool
+	}{{
+		name:        "none set",
+		want:        []string{"dns.example.com", "s3.example.com"},
+		wantChanged: true,
+	}, {
+		name:        "some set",
+		finalizers:  []string{"other", "s3.example.com"},
+		want:        []string{"other", "s3.example.com", "dns.example.com"},
+		wantChanged: true,
+	}, {
+		name:       "all set",
+		finalizers: []string{"s3.example.com", "dns.example.com"},
+		want:       []string{"s3.example.com", "dns.example.com"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, changed := fr.Ensure(tc.finalizers)
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("Ensure() = %v, wanted %v", got, tc.want)
+			}
+			if changed != tc.wantChanged {
+				t.Errorf("Ensure() changed = %v, wanted %v", changed, tc.wantChanged)
+			}
+		})
+	}
+}
+
+func TestFinalizerRegistryFinalize(t *testing.T) {
+	fr := NewFinalizerRegistry("dns.example.com", "s3.example.com", "db.example.com")
+	failed := errors.New("bucket not empty")
+
+	tests := []struct {
+		name        string
+		finalizers  []string
+		events      map[string]Event
+		want        []string
+		wantCalls   []string
+		wantChanged bool
+		wantEvent   error
+	}{{
+		name:        "all clean",
+		finalizers:  []string{"other", "dns.example.com", "s3.example.com"},
+		events:      map[string]Event{"s3.example.com": NewEvent(corev1.EventTypeNormal, "Emptied", "bucket emptied")},
+		want:        []string{"other"},
+		wantCalls:   []string{"dns.example.com", "s3.example.com"},
+		wantChanged: true,
+	}, {
+		name:        "one failed",
+		finalizers:  []string{"dns.example.com", "s3.example.com", "db.example.com"},
+		events:      map[string]Event{"s3.example.com": failed},
+		want:        []string{"s3.example.com"},
+		wantCalls:   []string{"db.example.com", "dns.example.com", "s3.example.com"},
+		wantChanged: true,
+		wantEvent:   failed,
+	}, {
+		name:       "warning event",
+		finalizers: []string{"s3.example.com"},
+		events:     map[string]Event{"s3.example.com": NewEvent(corev1.EventTypeWarning, "NotEmpty", "%w", failed)},
+		want:       []string{"s3.example.com"},
+		wantCalls:  []string{"s3.example.com"},
+		wantEvent:  failed,
+	}, {
+		name:       "none set",
+		finalizers: []string{"other"},
+		want:       []string{"other"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			got, changed, event := fr.Finalize(tc.finalizers, func(name string) Event {
+				calls = append(calls, name)
+				return tc.events[name]
+			})
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("Finalize() = %v, wanted %v", got, tc.want)
+			}
+			if changed != tc.wantChanged {
+				t.Errorf("Finalize() changed = %v, wanted %v", changed, tc.wantChanged)
+			}
+			if !errors.Is(event, tc.wantEvent) {
+				t.Errorf("Finalize() event = %v, wanted %v", event, tc.wantEvent)
+			}
+			if !cmp.Equal(calls, tc.wantCalls) {
+				t.Errorf("Finalize() called %v, wanted %v", calls, tc.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/metrics"
+)
+
+const (
+	// ConditionDependenciesReady is set on the KRShaped resources of the
+	// generated reconcilers with readiness gates, to False while a gate is
+	// not ready and to True once they all are. Its severity is Warning or
+	// Info, so it does not affect the readiness of the resource.
+	ConditionDependenciesReady apis.ConditionType = "DependenciesReady"
+
+	// ReadinessGateRequeueDelay is the delay after which the generated
+	// reconcilers retry the reconciliation of the resources held by a gate
+	// which is not ready.
+	ReadinessGateRequeueDelay = 10 * time.Second
+
+	gateNotReadyReason = "ReadinessGateNotReady"
+)
+
+// ReadinessGate is an external dependency, e.g. "database migrated" or "mesh
+// sidecar ready", which the generated reconcilers wait for before
+// reconciling the resources. Gates are given to the generated reconcilers
+// through the ReadinessGates of their controller.Options.
+type ReadinessGate struct {
+	// Name identifies the gate in the condition and metric.
+	Name string
+
+	// Check returns nil when the dependency is ready, or why it is not. It
+	// is called before every reconciliation so it should be cheap, e.g. by
+	// reading a state which is kept up to date in the background.
+	Check func(ctx context.Context) error
+}
+
+var (
+	gateReadyStat = stats.Int64("reconcile_gate_ready", "Whether the readiness gates of the reconcilers are ready (1) or not (0)", stats.UnitDimensionless)
+
+	gateReconcilerTagKey = tag.MustNewKey("reconciler")
+	gateNameTagKey       = tag.MustNewKey("gate")
+)
+
+func init() {
+	if err := view.Register(&view.View{
+		Description: "Whether the readiness gates of the reconcilers are ready (1) or not (0)",
+		Measure:     gateReadyStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{gateReconcilerTagKey, gateNameTagKey},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// CheckReadinessGates checks the gates of the named reconciler, reporting
+// their readiness, and returns the first one which is not ready with the
+// reason why, or nil if they all are.
+func CheckReadinessGates(ctx context.Context, reconciler string, gates []ReadinessGate) (*ReadinessGate, error) {
+	var (
+		notReady *ReadinessGate
+		reason   error
+	)
+	for i := range gates {
+		gate := &gates[i]
+		err := gate.Check(ctx)
+		reportGate(ctx, reconciler, gate.Name, err == nil)
+		if err != nil && notReady == nil {
+			notReady, reason = gate, err
+		}
+	}
+	return notReady, reason
+}
+
+func reportGate(ctx context.Context, reconciler, gate string, ready bool) {
+	ctx, err := tag.New(ctx,
+		tag.Insert(gateReconcilerTagKey, reconciler),
+		tag.Insert(gateNameTagKey, gate))
+	if err != nil {
+		return
+	}
+	var v int64
+	if ready {
+		v = 1
+	}
+	metrics.Record(ctx, gateReadyStat.M(v))
+}
+
+// MarkGateNotReady sets the DependenciesReady condition of the resource to
+// False, with the gate which is not ready and why.
+func MarkGateNotReady(resource duckv1.KRShaped, gate string, reason error) {
+	resource.GetConditionSet().Manage(resource.GetStatus()).SetCondition(apis.Condition{
+		Type:     ConditionDependenciesReady,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   gateNotReadyReason,
+		Message:  fmt.Sprintf("Readiness gate %q is not ready: %v", gate, reason),
+	})
+}
+
+// MarkGatesReady sets the DependenciesReady condition of the resource to
+// True.
+func MarkGatesReady(resource duckv1.KRShaped) {
+	resource.GetConditionSet().Manage(resource.GetStatus()).SetCondition(apis.Condition{
+		Type:     ConditionDependenciesReady,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	})
+}
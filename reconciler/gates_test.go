@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+func TestCheckReadinessGates(t *testing.T) {
+	ready := func(context.Context) error { return nil }
+	notMigrated := errors.New("migration 42 pending")
+
+	tests := []struct {
+		name     string
+		gates    []ReadinessGate
+		wantGate string
+		wantErr  error
+	}{{
+		name: "no gates",
+	}, {
+		name:  "all ready",
+		gates: []ReadinessGate{{Name: "sidecar", Check: ready}},
+	}, {
+		name: "not ready",
+		gates: []ReadinessGate{
+			{Name: "sidecar", Check: ready},
+			{Name: "database", Check: func(context.Context) error { return notMigrated }},
+			{Name: "cache", Check: func(context.Context) error { return errors.New("cold") }},
+		},
+		wantGate: "database",
+		wantErr:  notMigrated,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gate, err := CheckReadinessGates(context.Background(), "foo-controller", tc.gates)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("CheckReadinessGates() = %v, want: %v", err, tc.wantErr)
+			}
+			var gotGate string
+			if gate != nil {
+				gotGate = gate.Name
+			}
+			if gotGate != tc.wantGate {
+				t.Errorf("CheckReadinessGates() gate = %q, want: %q", gotGate, tc.wantGate)
+			}
+		})
+	}
+}
+
+func TestReportGate(t *testing.T) {
+	reportGate(context.Background(), "bar-controller", "database", false)
+
+	metricstest.Expect(t, "reconcile_gate_ready").WithTags(map[string]string{
+		"reconciler": "bar-controller",
+		"gate":       "database",
+	}).Exists().Equals(0)
+}
+
+func TestMarkGates(t *testing.T) {
+	resource := makeResource()
+
+	MarkGateNotReady(resource, "database", errors.New("migration 42 pending"))
+
+	cond := resource.Status.GetCondition(ConditionDependenciesReady)
+	if cond == nil || cond.Status != corev1.ConditionFalse || cond.Severity != apis.ConditionSeverityWarning {
+		t.Fatalf("DependenciesReady condition = %+v, want False with Warning severity", cond)
+	}
+	if want := `Readiness gate "database" is not ready: migration 42 pending`; cond.Message != want {
+		t.Errorf("DependenciesReady message = %q, want: %q", cond.Message, want)
+	}
+	// The readiness is not affected.
+	if rc := resource.Status.GetCondition(apis.ConditionReady); rc.Status != corev1.ConditionTrue {
+		t.Errorf("Ready condition = %s, want: True", rc.Status)
+	}
+
+	MarkGatesReady(resource)
+	if cond := resource.Status.GetCondition(ConditionDependenciesReady); cond == nil || cond.Status != corev1.ConditionTrue {
+		t.Errorf("DependenciesReady condition = %+v, want True", cond)
+	}
+}
@@ -0,0 +1,685 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generic provides the machinery of the reconcilers generated by
+// genreconciler for any kind, with type parameters instead of code
+// generation, so that new controllers do not need it.
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/kmp"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+)
+
+// Interface is implemented by the reconcilers of the resources of type T.
+type Interface[T kmeta.Accessor] interface {
+	// ReconcileKind implements custom logic to reconcile the resource. Any
+	// changes to its .Status or .Finalizers are propagated to the stored
+	// resource. The resource passed to ReconcileKind always has an empty
+	// deletion timestamp.
+	ReconcileKind(ctx context.Context, o T) reconciler.Event
+}
+
+// Finalizer is implemented by the reconcilers finalizing the resources of
+// type T.
+type Finalizer[T kmeta.Accessor] interface {
+	// FinalizeKind implements custom logic to finalize the resource. Any
+	// changes to its .Status or .Finalizers are ignored. Returning a nil or
+	// Normal type reconciler.Event allows the finalizer to be deleted on the
+	// resource. The resource passed to FinalizeKind always has a set deletion
+	// timestamp.
+	FinalizeKind(ctx context.Context, o T) reconciler.Event
+}
+
+// NamedFinalizers is implemented by the reconcilers finalizing the resources
+// of type T with several finalizers, e.g. one per external system,
+// independently of each other and of Finalizer.
+type NamedFinalizers[T kmeta.Accessor] interface {
+	// FinalizerNames returns the names of the finalizers to set on the
+	// resources.
+	FinalizerNames() []string
+
+	// FinalizeNamed implements custom logic to finalize the resource for the
+	// finalizer of the given name. Any changes to its .Status or .Finalizers
+	// are ignored. Returning a nil or Normal type reconciler.Event allows
+	// that finalizer to be deleted on the resource. The resource passed to
+	// FinalizeNamed always has a set deletion timestamp.
+	FinalizeNamed(ctx context.Context, name string, o T) reconciler.Event
+}
+
+// ReadOnlyInterface is implemented by the reconcilers of the resources of
+// type T processing them while they are not the leader.
+type ReadOnlyInterface[T kmeta.Accessor] interface {
+	// ObserveKind implements logic to observe the resource. It must not
+	// write to the API.
+	ObserveKind(ctx context.Context, o T) reconciler.Event
+}
+
+// Lister reads the resources of type T from the cache of an informer.
+type Lister[T kmeta.Accessor] interface {
+	// Get returns the resource with the name, in the namespace unless it is
+	// cluster scoped.
+	Get(namespace, name string) (T, error)
+	// List returns all the resources matching the selector.
+	List(selector labels.Selector) ([]T, error)
+}
+
+// Client writes the resources of type T to the API server.
+type Client[T kmeta.Accessor] interface {
+	// Get returns the resource with the name from the API server.
+	Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (T, error)
+	// UpdateStatus updates the status of the resource.
+	UpdateStatus(ctx context.Context, o T, opts metav1.UpdateOptions) (T, error)
+	// Patch patches the resource with the name.
+	Patch(ctx context.Context, namespace, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions) (T, error)
+}
+
+// ListerFuncs is a Lister of functions, e.g. adapting a generated lister:
+//
+//	generic.ListerFuncs[*v1.Foo]{
+//	    GetFunc: func(namespace, name string) (*v1.Foo, error) {
+//	        return lister.Foos(namespace).Get(name)
+//	    },
+//	    ListFunc: lister.List,
+//	}
+type ListerFuncs[T kmeta.Accessor] struct {
+	GetFunc  func(namespace, name string) (T, error)
+	ListFunc func(selector labels.Selector) ([]T, error)
+}
+
+var _ Lister[kmeta.Accessor] = ListerFuncs[kmeta.Accessor]{}
+
+// Get implements Lister.
+func (l ListerFuncs[T]) Get(namespace, name string) (T, error) {
+	return l.GetFunc(namespace, name)
+}
+
+// List implements Lister.
+func (l ListerFuncs[T]) List(selector labels.Selector) ([]T, error) {
+	return l.ListFunc(selector)
+}
+
+// ClientFuncs is a Client of functions, e.g. adapting a generated client.
+type ClientFuncs[T kmeta.Accessor] struct {
+	GetFunc          func(ctx context.Context, namespace, name string, opts metav1.GetOptions) (T, error)
+	UpdateStatusFunc func(ctx context.Context, o T, opts metav1.UpdateOptions) (T, error)
+	PatchFunc        func(ctx context.Context, namespace, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions) (T, error)
+}
+
+var _ Client[kmeta.Accessor] = ClientFuncs[kmeta.Accessor]{}
+
+// Get implements Client.
+func (c ClientFuncs[T]) Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (T, error) {
+	return c.GetFunc(ctx, namespace, name, opts)
+}
+
+// UpdateStatus implements Client.
+func (c ClientFuncs[T]) UpdateStatus(ctx context.Context, o T, opts metav1.UpdateOptions) (T, error) {
+	return c.UpdateStatusFunc(ctx, o, opts)
+}
+
+// Patch implements Client.
+func (c ClientFuncs[T]) Patch(ctx context.Context, namespace, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions) (T, error) {
+	return c.PatchFunc(ctx, namespace, name, pt, data, opts)
+}
+
+type doReconcile[T kmeta.Accessor] func(ctx context.Context, o T) reconciler.Event
+
+// withHooks returns do run between the hooks, as the named method.
+func (do doReconcile[T]) withHooks(hooks []reconciler.ReconcileHook, method string) doReconcile[T] {
+	if do == nil || len(hooks) == 0 {
+		return do
+	}
+	return func(ctx context.Context, o T) reconciler.Event {
+		return reconciler.RunReconcileHooks(ctx, hooks, method, o, func(ctx context.Context) reconciler.Event {
+			return do(ctx, o)
+		})
+	}
+}
+
+// reconcilerImpl implements controller.Reconciler for the resources of type
+// T, as the reconcilers generated by genreconciler.
+type reconcilerImpl[T kmeta.Accessor] struct {
+	// LeaderAwareFuncs is inlined to help us implement reconciler.LeaderAware.
+	reconciler.LeaderAwareFuncs
+
+	client   Client[T]
+	lister   Lister[T]
+	recorder record.EventRecorder
+
+	// agentName is the name of the controller, e.g. in its metrics.
+	agentName string
+
+	configStore       reconciler.ConfigStore
+	reconciler        Interface[T]
+	finalizerName     string
+	readinessGates    []reconciler.ReadinessGate
+	hooks             []reconciler.ReconcileHook
+	observationStore  reconciler.ObservationStore
+	skipStatusUpdates bool
+
+	statusConflictStrategy reconciler.StatusConflictStrategy
+}
+
+var _ controller.Reconciler = (*reconcilerImpl[kmeta.Accessor])(nil)
+var _ reconciler.LeaderAware = (*reconcilerImpl[kmeta.Accessor])(nil)
+
+// NewTyped returns a controller.Reconciler of the resources of type T,
+// calling r as the reconcilers generated by genreconciler call theirs: it
+// records the events of the reconciles, updates the status and the finalizer
+// of the resources, and is leader aware. The agentName is the default name
+// of the finalizer of the resources, and of the controller in the metrics.
+// The status is that of the Status field of the resources, if any, and is
+// updated as a whole: controller.Options.StatusFieldManager is not supported.
+// The conditions of the resources which are duckv1.KRShaped are managed as
+// by the generated reconcilers, e.g. the paused and readiness gate ones.
+func NewTyped[T kmeta.Accessor](ctx context.Context, agentName string, client Client[T], lister Lister[T], recorder record.EventRecorder, r Interface[T], options ...controller.Options) controller.Reconciler {
+	logger := logging.FromContext(ctx)
+	// Check the options function input. It should be 0 or 1.
+	if len(options) > 1 {
+		logger.Fatal("Up to one options struct is supported, found: ", len(options))
+	}
+
+	// Fail fast when users inadvertently implement the other LeaderAware interface.
+	// For the typed reconcilers, Promote shouldn't take any arguments.
+	if _, ok := r.(reconciler.LeaderAware); ok {
+		logger.Fatalf("%T implements the incorrect LeaderAware interface. Promote() should not take an argument as NewTyped handles the enqueuing automatically.", r)
+	}
+
+	rec := &reconcilerImpl[T]{
+		LeaderAwareFuncs: reconciler.LeaderAwareFuncs{
+			PromoteFunc: func(bkt reconciler.Bucket, enq func(reconciler.Bucket, types.NamespacedName)) error {
+				all, err := lister.List(labels.Everything())
+				if err != nil {
+					return err
+				}
+				for _, elt := range all {
+					enq(bkt, types.NamespacedName{
+						Namespace: elt.GetNamespace(),
+						Name:      elt.GetName(),
+					})
+				}
+				return nil
+			},
+		},
+		client:        client,
+		lister:        lister,
+		recorder:      recorder,
+		agentName:     agentName,
+		reconciler:    r,
+		finalizerName: agentName,
+	}
+
+	for _, opts := range options {
+		if opts.ConfigStore != nil {
+			rec.configStore = opts.ConfigStore
+		}
+		if opts.FinalizerName != "" {
+			rec.finalizerName = opts.FinalizerName
+		}
+		if opts.AgentName != "" {
+			rec.agentName = opts.AgentName
+		}
+		if opts.SkipStatusUpdates {
+			rec.skipStatusUpdates = true
+		}
+		if opts.StatusFieldManager != "" {
+			// Applying the status needs the kind of the resources, and
+			// patches of their status subresource, which a Client lacks.
+			logger.Fatalf("StatusFieldManager %q is not supported by NewTyped, which updates the whole status", opts.StatusFieldManager)
+		}
+		if opts.StatusConflictStrategy != "" {
+			rec.statusConflictStrategy = opts.StatusConflictStrategy
+		}
+		if opts.DemoteFunc != nil {
+			rec.DemoteFunc = opts.DemoteFunc
+		}
+		if len(opts.ReadinessGates) > 0 {
+			rec.readinessGates = opts.ReadinessGates
+		}
+		if len(opts.ReconcileHooks) > 0 {
+			rec.hooks = opts.ReconcileHooks
+		}
+		if opts.ObservationStore != nil {
+			rec.observationStore = opts.ObservationStore
+		}
+	}
+
+	return rec
+}
+
+// reconcileMethodFor returns the method of the reconciler to call for the
+// resource, nil if none.
+func (r *reconcilerImpl[T]) reconcileMethodFor(o T, isLeader bool) (string, doReconcile[T]) {
+	if o.GetDeletionTimestamp().IsZero() {
+		if isLeader {
+			return reconciler.DoReconcileKind, r.reconciler.ReconcileKind
+		} else if roi, ok := r.reconciler.(ReadOnlyInterface[T]); ok {
+			return reconciler.DoObserveKind, roi.ObserveKind
+		}
+	} else if fin, ok := r.reconciler.(Finalizer[T]); isLeader && ok {
+		return reconciler.DoFinalizeKind, fin.FinalizeKind
+	} else if _, ok := r.reconciler.(NamedFinalizers[T]); isLeader && ok {
+		return reconciler.DoFinalizeKind, finalizeNamedOnly[T]
+	}
+	return "unknown", nil
+}
+
+// Reconcile implements controller.Reconciler
+func (r *reconcilerImpl[T]) Reconcile(ctx context.Context, key string) error {
+	logger := logging.FromContext(ctx)
+
+	// Convert the namespace/name string into a distinct namespace and name.
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		logger.Error("Invalid resource key: ", key)
+		return nil
+	}
+	isLeader := r.IsLeaderFor(types.NamespacedName{Namespace: namespace, Name: name})
+
+	// If we are not the leader, and we don't implement the ReadOnly
+	// observer interface, then take a fast-path out.
+	if _, isROI := r.reconciler.(ReadOnlyInterface[T]); !isLeader && !isROI {
+		return controller.NewSkipKey(key)
+	}
+
+	// If configStore is set, attach the frozen configuration to the context.
+	if r.configStore != nil {
+		ctx = r.configStore.ToContext(ctx)
+	}
+
+	// If observationStore is set, attach it to the context.
+	if r.observationStore != nil {
+		ctx = reconciler.WithObservationStore(ctx, r.observationStore)
+	}
+
+	// Add the recorder to context.
+	ctx = controller.WithEventRecorder(ctx, r.recorder)
+
+	original, err := r.lister.Get(namespace, name)
+	if apierrs.IsNotFound(err) {
+		// The resource may no longer exist, in which case we stop processing and call
+		// the ObserveDeletion handler if appropriate.
+		logger.Debugf("Resource %q no longer exists", key)
+		if del, ok := r.reconciler.(reconciler.OnDeletionInterface); ok {
+			return del.ObserveDeletion(ctx, types.NamespacedName{
+				Namespace: namespace,
+				Name:      name,
+			})
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Don't modify the informers copy.
+	resource := original.DeepCopyObject().(T)
+
+	var reconcileEvent reconciler.Event
+
+	// The conditions of the KRShaped resources are managed unless the status
+	// updates are skipped.
+	kr, isKRShaped := kmeta.Accessor(resource).(duckv1.KRShaped)
+	isKRShaped = isKRShaped && !r.skipStatusUpdates
+
+	method, do := r.reconcileMethodFor(resource, isLeader)
+	do = do.withHooks(r.hooks, method)
+	// Append the target method to the logger.
+	logger = logger.With(zap.String("targetMethod", method))
+	switch method {
+	case reconciler.DoReconcileKind:
+		// Skip the reconciliation of paused resources, so that operators
+		// can freeze individual resources.
+		if reconciler.IsPaused(resource) {
+			logger.Debugf("Skip reconciling resource %q, its reconciliation is paused", key)
+			reconciler.ReportPaused(ctx, r.agentName, namespace)
+			if isKRShaped {
+				reconciler.MarkPaused(kr)
+			}
+			break
+		}
+
+		// Wait for the external dependencies of the reconciler to be ready.
+		if gate, err := reconciler.CheckReadinessGates(ctx, r.agentName, r.readinessGates); err != nil {
+			logger.Infof("Skip reconciling resource %q, readiness gate %q is not ready: %v", key, gate.Name, err)
+			if isKRShaped {
+				reconciler.MarkGateNotReady(kr, gate.Name, err)
+			}
+			reconcileEvent = controller.NewRequeueAfter(reconciler.ReadinessGateRequeueDelay)
+			break
+		}
+		if len(r.readinessGates) > 0 && isKRShaped {
+			reconciler.MarkGatesReady(kr)
+		}
+
+		// Set and update the finalizer on resource if r.reconciler
+		// implements Finalizer.
+		if resource, err = r.setFinalizerIfFinalizer(ctx, resource); err != nil {
+			return fmt.Errorf("failed to set finalizers: %w", err)
+		}
+		// Set and update the finalizers on resource if r.reconciler
+		// implements NamedFinalizers.
+		if resource, err = r.setNamedFinalizers(ctx, resource); err != nil {
+			return fmt.Errorf("failed to set finalizers: %w", err)
+		}
+		// The resource may have been patched with the finalizers.
+		kr, _ = kmeta.Accessor(resource).(duckv1.KRShaped)
+		if isKRShaped {
+			reconciler.PreProcessReconcile(ctx, kr)
+		}
+
+		// Reconcile this copy of the resource and then write back any status
+		// updates regardless of whether the reconciliation errored out.
+		reconcileEvent = do(ctx, resource)
+
+		if isKRShaped {
+			reconciler.PostProcessReconcile(ctx, kr, kmeta.Accessor(original).(duckv1.KRShaped))
+		}
+
+	case reconciler.DoFinalizeKind:
+		// For finalizing reconcilers, if this resource being marked for deletion
+		// and reconciled cleanly (nil or normal event), remove the finalizer.
+		reconcileEvent = do(ctx, resource)
+
+		if resource, err = r.clearFinalizer(ctx, resource, reconcileEvent); err != nil {
+			return fmt.Errorf("failed to clear finalizers: %w", err)
+		}
+
+		// Finalize each of the named finalizers, removing those which
+		// finalized cleanly.
+		var namedEvent reconciler.Event
+		if resource, namedEvent, err = r.finalizeNamed(ctx, resource); err != nil {
+			return fmt.Errorf("failed to clear finalizers: %w", err)
+		}
+		// The failure of a named finalizer prevails over a clean event of
+		// FinalizeKind, so that the resource is finalized again.
+		if namedEvent != nil && reconciler.IsCleanEvent(reconcileEvent) {
+			reconcileEvent = namedEvent
+		}
+
+	case reconciler.DoObserveKind:
+		// Observe any changes to this resource, since we are not the leader.
+		reconcileEvent = do(ctx, resource)
+	}
+
+	// Synchronize the status.
+	originalStatus, hasStatus := statusOf(original)
+	switch {
+	case r.skipStatusUpdates || !hasStatus:
+		// This reconciler implementation is configured to skip resource
+		// updates, or the resources have no status.
+	case equality.Semantic.DeepEqual(originalStatus.Interface(), mustStatusOf(resource).Interface()):
+		// If we didn't change anything then don't call updateStatus.
+		// This is important because the copy we loaded from the informer's
+		// cache may be stale and we don't want to overwrite a prior update
+		// to status with this stale state.
+	case !isLeader:
+		// High-availability reconcilers may have many replicas watching the resource, but only
+		// the elected leader is expected to write modifications.
+		logger.Warn("Saw status changes when we aren't the leader!")
+	default:
+		if err = r.updateStatus(ctx, logger, original, resource); err != nil {
+			logger.Warnw("Failed to update resource status", zap.Error(err))
+			r.recorder.Eventf(resource, corev1.EventTypeWarning, "UpdateFailed",
+				"Failed to update status for %q: %v", resource.GetName(), err)
+			return err
+		}
+	}
+
+	// Report the reconciler event, if any.
+	if reconcileEvent != nil {
+		var structured *reconciler.StructuredEvent
+		if reconciler.EventAs(reconcileEvent, &structured) {
+			logger.Infow("Returned a structured event", zap.Any("event", reconcileEvent))
+			structured.Record(ctx, r.recorder, r.agentName, resource)
+
+			// the event was wrapped inside an error, consider the reconciliation as failed
+			if _, isEvent := reconcileEvent.(*reconciler.StructuredEvent); !isEvent {
+				return reconcileEvent
+			}
+			return nil
+		}
+
+		var event *reconciler.ReconcilerEvent
+		if reconciler.EventAs(reconcileEvent, &event) {
+			logger.Infow("Returned an event", zap.Any("event", reconcileEvent))
+			r.recorder.Event(resource, event.EventType, event.Reason, event.Error())
+
+			// the event was wrapped inside an error, consider the reconciliation as failed
+			if _, isEvent := reconcileEvent.(*reconciler.ReconcilerEvent); !isEvent {
+				return reconcileEvent
+			}
+			return nil
+		}
+
+		if controller.IsSkipKey(reconcileEvent) {
+			// This is a wrapped error, don't emit an event.
+		} else if ok, _ := controller.IsRequeueKey(reconcileEvent); ok {
+			// This is a wrapped error, don't emit an event.
+		} else {
+			logger.Errorw("Returned an error", zap.Error(reconcileEvent))
+			r.recorder.Event(resource, corev1.EventTypeWarning, "InternalError", reconcileEvent.Error())
+		}
+		return reconcileEvent
+	}
+
+	return nil
+}
+
+// statusOf returns the Status field of the resource, if it has one.
+func statusOf(o kmeta.Accessor) (reflect.Value, bool) {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	status := v.Elem().FieldByName("Status")
+	return status, status.IsValid()
+}
+
+func mustStatusOf(o kmeta.Accessor) reflect.Value {
+	status, _ := statusOf(o)
+	return status
+}
+
+func (r *reconcilerImpl[T]) updateStatus(ctx context.Context, logger *zap.SugaredLogger, existing, desired T) error {
+	original := existing
+	existing = existing.DeepCopyObject().(T)
+	return reconciler.RetryUpdateConflicts(func(attempts int) (err error) {
+		desiredStatus := mustStatusOf(desired)
+
+		// The first iteration tries to use the informer's state, subsequent attempts fetch the latest state via API.
+		if attempts > 0 {
+			existing, err = r.client.Get(ctx, desired.GetNamespace(), desired.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			// Resolve the conflict with the status written since it was read.
+			desiredStatus = mustStatusOf(desired.DeepCopyObject().(T))
+			if err := r.statusConflictStrategy.Resolve(mustStatusOf(original).Addr().Interface(),
+				mustStatusOf(existing).Addr().Interface(), desiredStatus.Addr().Interface()); err != nil {
+				return err
+			}
+		}
+
+		// If there's nothing to update, just return.
+		existingStatus := mustStatusOf(existing)
+		if equality.Semantic.DeepEqual(existingStatus.Interface(), desiredStatus.Interface()) {
+			return nil
+		}
+
+		if logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
+			if diff, err := kmp.SafeDiff(existingStatus.Interface(), desiredStatus.Interface()); err == nil && diff != "" {
+				logger.Debug("Updating status with: ", diff)
+			}
+		}
+
+		existingStatus.Set(desiredStatus)
+
+		_, err = r.client.UpdateStatus(ctx, existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// updateFinalizersFiltered will update the Finalizers of the resource.
+// It only updates the finalizer of the reconciler.
+func (r *reconcilerImpl[T]) updateFinalizersFiltered(ctx context.Context, resource T, desiredFinalizers sets.String) (T, error) {
+	var finalizers []string
+
+	// If there's nothing to update, just return.
+	existingFinalizers := sets.NewString(resource.GetFinalizers()...)
+
+	if desiredFinalizers.Has(r.finalizerName) {
+		if existingFinalizers.Has(r.finalizerName) {
+			// Nothing to do.
+			return resource, nil
+		}
+		// Add the finalizer.
+		finalizers = append(resource.GetFinalizers(), r.finalizerName)
+	} else {
+		if !existingFinalizers.Has(r.finalizerName) {
+			// Nothing to do.
+			return resource, nil
+		}
+		// Remove the finalizer.
+		existingFinalizers.Delete(r.finalizerName)
+		finalizers = existingFinalizers.List()
+	}
+
+	return r.patchFinalizers(ctx, resource, finalizers)
+}
+
+// patchFinalizers will set the Finalizers of the resource to finalizers.
+func (r *reconcilerImpl[T]) patchFinalizers(ctx context.Context, resource T, finalizers []string) (T, error) {
+	mergePatch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": resource.GetResourceVersion(),
+		},
+	}
+
+	patch, err := json.Marshal(mergePatch)
+	if err != nil {
+		return resource, err
+	}
+
+	updated, err := r.client.Patch(ctx, resource.GetNamespace(), resource.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		r.recorder.Eventf(resource, corev1.EventTypeWarning, "FinalizerUpdateFailed",
+			"Failed to update finalizers for %q: %v", resource.GetName(), err)
+		return resource, err
+	}
+	r.recorder.Eventf(updated, corev1.EventTypeNormal, "FinalizerUpdate",
+		"Updated %q finalizers", resource.GetName())
+	return updated, nil
+}
+
+func (r *reconcilerImpl[T]) setFinalizerIfFinalizer(ctx context.Context, resource T) (T, error) {
+	if _, ok := r.reconciler.(Finalizer[T]); !ok {
+		return resource, nil
+	}
+
+	finalizers := sets.NewString(resource.GetFinalizers()...)
+
+	// If this resource is not being deleted, mark the finalizer.
+	if resource.GetDeletionTimestamp().IsZero() {
+		finalizers.Insert(r.finalizerName)
+	}
+
+	// Synchronize the finalizers filtered by r.finalizerName.
+	return r.updateFinalizersFiltered(ctx, resource, finalizers)
+}
+
+func (r *reconcilerImpl[T]) clearFinalizer(ctx context.Context, resource T, reconcileEvent reconciler.Event) (T, error) {
+	if _, ok := r.reconciler.(Finalizer[T]); !ok {
+		return resource, nil
+	}
+	if resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil
+	}
+
+	finalizers := sets.NewString(resource.GetFinalizers()...)
+
+	if reconcileEvent != nil {
+		var event *reconciler.ReconcilerEvent
+		if reconciler.EventAs(reconcileEvent, &event) {
+			if event.EventType == corev1.EventTypeNormal {
+				finalizers.Delete(r.finalizerName)
+			}
+		}
+	} else {
+		finalizers.Delete(r.finalizerName)
+	}
+
+	// Synchronize the finalizers filtered by r.finalizerName.
+	return r.updateFinalizersFiltered(ctx, resource, finalizers)
+}
+
+func (r *reconcilerImpl[T]) setNamedFinalizers(ctx context.Context, resource T) (T, error) {
+	nf, ok := r.reconciler.(NamedFinalizers[T])
+	if !ok || !resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil
+	}
+
+	finalizers, changed := reconciler.NewFinalizerRegistry(nf.FinalizerNames()...).Ensure(resource.GetFinalizers())
+	if !changed {
+		return resource, nil
+	}
+	return r.patchFinalizers(ctx, resource, finalizers)
+}
+
+func (r *reconcilerImpl[T]) finalizeNamed(ctx context.Context, resource T) (T, reconciler.Event, error) {
+	nf, ok := r.reconciler.(NamedFinalizers[T])
+	if !ok || resource.GetDeletionTimestamp().IsZero() {
+		return resource, nil, nil
+	}
+
+	finalizers, changed, event := reconciler.NewFinalizerRegistry(nf.FinalizerNames()...).Finalize(resource.GetFinalizers(), func(name string) reconciler.Event {
+		return nf.FinalizeNamed(ctx, name, resource)
+	})
+	if !changed {
+		return resource, event, nil
+	}
+	resource, err := r.patchFinalizers(ctx, resource, finalizers)
+	return resource, event, err
+}
+
+// finalizeNamedOnly is the FinalizeKind of the reconcilers which implement
+// NamedFinalizers but not Finalizer.
+func finalizeNamedOnly[T kmeta.Accessor](context.Context, T) reconciler.Event {
+	return nil
+}
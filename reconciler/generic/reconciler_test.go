@@ -0,0 +1,419 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/reconciler"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+const agentName = "namespace-controller"
+
+// namespaceReconciler sets the phase of the namespaces it reconciles, and
+// counts their finalizations.
+type namespaceReconciler struct {
+	finalized int
+}
+
+func (r *namespaceReconciler) ReconcileKind(_ context.Context, ns *corev1.Namespace) reconciler.Event {
+	ns.Status.Phase = corev1.NamespaceActive
+	return nil
+}
+
+func (r *namespaceReconciler) FinalizeKind(context.Context, *corev1.Namespace) reconciler.Event {
+	r.finalized++
+	return nil
+}
+
+func newNamespaceReconciler(t *testing.T, r Interface[*corev1.Namespace], objs ...*corev1.Namespace) (controller.Reconciler, *fake.Clientset) {
+	return newNamespaceReconcilerWithOptions(t, r, controller.Options{}, objs...)
+}
+
+func newNamespaceReconcilerWithOptions(t *testing.T, r Interface[*corev1.Namespace], opts controller.Options, objs ...*corev1.Namespace) (controller.Reconciler, *fake.Clientset) {
+	client := fake.NewSimpleClientset()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, obj := range objs {
+		client.Tracker().Add(obj)
+		indexer.Add(obj)
+	}
+	lister := corelisters.NewNamespaceLister(indexer)
+	namespaces := client.CoreV1().Namespaces()
+
+	rec := NewTyped[*corev1.Namespace](TestContextWithLogger(t), agentName,
+		ClientFuncs[*corev1.Namespace]{
+			GetFunc: func(ctx context.Context, _, name string, opts metav1.GetOptions) (*corev1.Namespace, error) {
+				return namespaces.Get(ctx, name, opts)
+			},
+			UpdateStatusFunc: namespaces.UpdateStatus,
+			PatchFunc: func(ctx context.Context, _, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions) (*corev1.Namespace, error) {
+				return namespaces.Patch(ctx, name, pt, data, opts)
+			},
+		},
+		ListerFuncs[*corev1.Namespace]{
+			GetFunc: func(_, name string) (*corev1.Namespace, error) {
+				return lister.Get(name)
+			},
+			ListFunc: lister.List,
+		},
+		record.NewFakeRecorder(10), r, opts)
+	return rec, client
+}
+
+func promote(t *testing.T, r controller.Reconciler) {
+	t.Helper()
+	if err := r.(reconciler.LeaderAware).Promote(reconciler.UniversalBucket(), func(reconciler.Bucket, types.NamespacedName) {}); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+}
+
+func TestReconcileKind(t *testing.T) {
+	r := &namespaceReconciler{}
+	rec, client := newNamespaceReconciler(t, r, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+	})
+	ctx := TestContextWithLogger(t)
+
+	// Not the leader, the key is skipped.
+	if err := rec.Reconcile(ctx, "foo"); !controller.IsSkipKey(err) {
+		t.Errorf("Reconcile() = %v, wanted a skipped key", err)
+	}
+
+	promote(t, rec)
+	if err := rec.Reconcile(ctx, "foo"); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+
+	// The finalizer is patched in, and the status updated.
+	var patched, updated bool
+	for _, action := range client.Actions() {
+		switch a := action.(type) {
+		case clientgotesting.PatchAction:
+			want := `{"metadata":{"finalizers":["` + agentName + `"],"resourceVersion":""}}`
+			if got := string(a.GetPatch()); got != want {
+				t.Errorf("Patch = %s, wanted %s", got, want)
+			}
+			patched = true
+		case clientgotesting.UpdateAction:
+			if a.GetSubresource() != "status" {
+				t.Errorf("Update of subresource %q, wanted status", a.GetSubresource())
+			}
+			if got := a.GetObject().(*corev1.Namespace).Status.Phase; got != corev1.NamespaceActive {
+				t.Errorf("Status.Phase = %q, wanted %q", got, corev1.NamespaceActive)
+			}
+			updated = true
+		}
+	}
+	if !patched || !updated {
+		t.Errorf("Patched finalizers: %v, updated status: %v, wanted both", patched, updated)
+	}
+
+	// A missing resource is not an error.
+	if err := rec.Reconcile(ctx, "missing"); err != nil {
+		t.Error("Reconcile(missing) =", err)
+	}
+}
+
+func TestReconcileHooks(t *testing.T) {
+	var calls []string
+	hook := reconciler.ReconcileHook{
+		Name: "audit",
+		BeforeReconcile: func(ctx context.Context, method string, resource metav1.Object) (context.Context, error) {
+			calls = append(calls, "before "+method+" "+resource.GetName())
+			return ctx, nil
+		},
+		AfterReconcile: func(_ context.Context, method string, resource metav1.Object, event reconciler.Event) reconciler.Event {
+			calls = append(calls, "after "+method+" "+string(resource.(*corev1.Namespace).Status.Phase))
+			return event
+		},
+	}
+	rec, _ := newNamespaceReconcilerWithOptions(t, &namespaceReconciler{}, controller.Options{
+		ReconcileHooks: []reconciler.ReconcileHook{hook},
+	}, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
+	ctx := TestContextWithLogger(t)
+	promote(t, rec)
+
+	if err := rec.Reconcile(ctx, "foo"); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	want := []string{"before ReconcileKind foo", "after ReconcileKind Active"}
+	if !cmp.Equal(calls, want) {
+		t.Error("Hook calls (-want, +got) =", cmp.Diff(want, calls))
+	}
+}
+
+// storeCheckingReconciler checks the ObservationStore of its context.
+type storeCheckingReconciler struct {
+	got reconciler.ObservationStore
+}
+
+func (r *storeCheckingReconciler) ReconcileKind(ctx context.Context, _ *corev1.Namespace) reconciler.Event {
+	r.got = reconciler.GetObservationStore(ctx)
+	return nil
+}
+
+func TestObservationStore(t *testing.T) {
+	store := reconciler.NewConfigMapObservationStore(fake.NewSimpleClientset().CoreV1().ConfigMaps("system"), agentName, "replica")
+	r := &storeCheckingReconciler{}
+	rec, _ := newNamespaceReconcilerWithOptions(t, r, controller.Options{
+		ObservationStore: store,
+	}, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
+	ctx := TestContextWithLogger(t)
+	promote(t, rec)
+
+	if err := rec.Reconcile(ctx, "foo"); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	if r.got != store {
+		t.Errorf("ObservationStore = %v, wanted %v", r.got, store)
+	}
+}
+
+func TestFinalizeKind(t *testing.T) {
+	r := &namespaceReconciler{}
+	now := metav1.Now()
+	rec, client := newNamespaceReconciler(t, r, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{agentName, "other"},
+		},
+	})
+	ctx := TestContextWithLogger(t)
+	promote(t, rec)
+
+	if err := rec.Reconcile(ctx, "foo"); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	if r.finalized != 1 {
+		t.Errorf("FinalizeKind() calls = %d, wanted 1", r.finalized)
+	}
+	got, err := client.CoreV1().Namespaces().Get(ctx, "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if want := []string{"other"}; len(got.Finalizers) != 1 || got.Finalizers[0] != want[0] {
+		t.Errorf("Finalizers = %v, wanted %v", got.Finalizers, want)
+	}
+}
+
+// namedFinalizingReconciler finalizes the namespaces with the external
+// finalizer, returning its event.
+type namedFinalizingReconciler struct {
+	namespaceReconciler
+	event reconciler.Event
+}
+
+var _ NamedFinalizers[*corev1.Namespace] = (*namedFinalizingReconciler)(nil)
+
+func (r *namedFinalizingReconciler) FinalizerNames() []string {
+	return []string{"external"}
+}
+
+func (r *namedFinalizingReconciler) FinalizeNamed(context.Context, string, *corev1.Namespace) reconciler.Event {
+	r.finalized++
+	return r.event
+}
+
+// withoutFinalizeKind hides the FinalizeKind of the reconciler.
+type withoutFinalizeKind struct {
+	*namedFinalizingReconciler
+	FinalizeKind struct{}
+}
+
+func TestNamedFinalizers(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	r := &namedFinalizingReconciler{}
+	rec, client := newNamespaceReconciler(t, withoutFinalizeKind{namedFinalizingReconciler: r},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
+	promote(t, rec)
+
+	// The named finalizer is patched in.
+	if err := rec.Reconcile(ctx, "foo"); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	var patched bool
+	for _, action := range client.Actions() {
+		if a, ok := action.(clientgotesting.PatchAction); ok {
+			want := `{"metadata":{"finalizers":["external"],"resourceVersion":""}}`
+			if got := string(a.GetPatch()); got != want {
+				t.Errorf("Patch = %s, wanted %s", got, want)
+			}
+			patched = true
+		}
+	}
+	if !patched {
+		t.Error("The finalizers were not patched")
+	}
+
+	now := metav1.Now()
+	deleted := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{"external", "other"},
+		},
+	}
+
+	// The named finalizer is kept while it fails.
+	r.event = errors.New("external system is down")
+	rec, client = newNamespaceReconciler(t, withoutFinalizeKind{namedFinalizingReconciler: r}, deleted)
+	promote(t, rec)
+	if err := rec.Reconcile(ctx, "foo"); !errors.Is(err, r.event) {
+		t.Errorf("Reconcile() = %v, wanted %v", err, r.event)
+	}
+	if got := client.Actions(); len(got) != 0 {
+		t.Errorf("Actions = %v, wanted none", got)
+	}
+
+	// The named finalizer is removed once it finalized cleanly.
+	r.event = nil
+	if err := rec.Reconcile(ctx, "foo"); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	got, err := client.CoreV1().Namespaces().Get(ctx, "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if want := []string{"other"}; !cmp.Equal(got.Finalizers, want) {
+		t.Errorf("Finalizers = %v, wanted %v", got.Finalizers, want)
+	}
+	if r.finalized != 2 {
+		t.Errorf("FinalizeNamed() calls = %d, wanted 2", r.finalized)
+	}
+}
+
+// krReconciler marks the KResources it reconciles ready.
+type krReconciler struct{}
+
+func (krReconciler) ReconcileKind(_ context.Context, o *duckv1.KResource) reconciler.Event {
+	o.GetConditionSet().Manage(o.GetStatus()).MarkTrue(apis.ConditionReady)
+	return nil
+}
+
+// newKResourceReconciler returns a reconciler of the KResource, and the
+// status it was last updated with.
+func newKResourceReconciler(t *testing.T, o *duckv1.KResource, gates ...reconciler.ReadinessGate) (controller.Reconciler, *duckv1.Status) {
+	status := &duckv1.Status{}
+	rec := NewTyped[*duckv1.KResource](TestContextWithLogger(t), agentName,
+		ClientFuncs[*duckv1.KResource]{
+			UpdateStatusFunc: func(_ context.Context, o *duckv1.KResource, _ metav1.UpdateOptions) (*duckv1.KResource, error) {
+				*status = o.Status
+				return o, nil
+			},
+		},
+		ListerFuncs[*duckv1.KResource]{
+			GetFunc: func(string, string) (*duckv1.KResource, error) {
+				return o, nil
+			},
+			ListFunc: func(labels.Selector) ([]*duckv1.KResource, error) {
+				return []*duckv1.KResource{o}, nil
+			},
+		},
+		record.NewFakeRecorder(10), krReconciler{}, controller.Options{ReadinessGates: gates})
+	promote(t, rec)
+	return rec, status
+}
+
+func TestKRShapedConditions(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	notReady := errors.New("not ready")
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		gateErr     error
+		wantErr     bool
+		want        map[apis.ConditionType]corev1.ConditionStatus
+		wantObserve bool
+	}{{
+		name:        "paused",
+		annotations: map[string]string{reconciler.PausedAnnotationKey: "true"},
+		want:        map[apis.ConditionType]corev1.ConditionStatus{reconciler.ConditionPaused: corev1.ConditionTrue},
+	}, {
+		name:    "gate not ready",
+		gateErr: notReady,
+		wantErr: true,
+		want:    map[apis.ConditionType]corev1.ConditionStatus{reconciler.ConditionDependenciesReady: corev1.ConditionFalse},
+	}, {
+		name: "reconciled",
+		want: map[apis.ConditionType]corev1.ConditionStatus{
+			reconciler.ConditionDependenciesReady: corev1.ConditionTrue,
+			apis.ConditionReady:                   corev1.ConditionTrue,
+		},
+		wantObserve: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, status := newKResourceReconciler(t, &duckv1.KResource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "bar",
+					Generation:  2,
+					Annotations: tc.annotations,
+				},
+			}, reconciler.ReadinessGate{
+				Name:  "dependency",
+				Check: func(context.Context) error { return tc.gateErr },
+			})
+
+			if err := rec.Reconcile(ctx, "bar/foo"); (err != nil) != tc.wantErr {
+				t.Fatalf("Reconcile() = %v, wanted an error: %v", err, tc.wantErr)
+			}
+			for typ, want := range tc.want {
+				if got := status.GetCondition(typ); got == nil || got.Status != want {
+					t.Errorf("Condition %s = %v, wanted %s", typ, got, want)
+				}
+			}
+			if got := status.ObservedGeneration == 2; got != tc.wantObserve {
+				t.Errorf("ObservedGeneration = %d, wanted the generation observed: %v", status.ObservedGeneration, tc.wantObserve)
+			}
+		})
+	}
+}
+
+func TestPromoteEnqueuesAll(t *testing.T) {
+	rec, _ := newNamespaceReconciler(t, &namespaceReconciler{},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bar"}})
+
+	var enqueued []types.NamespacedName
+	if err := rec.(reconciler.LeaderAware).Promote(reconciler.UniversalBucket(), func(_ reconciler.Bucket, key types.NamespacedName) {
+		enqueued = append(enqueued, key)
+	}); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	if len(enqueued) != 2 {
+		t.Errorf("Promote() enqueued %v, wanted both namespaces", enqueued)
+	}
+}
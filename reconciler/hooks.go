@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReconcileHook is a cross-cutting behavior, e.g. acquiring a lock, pushing
+// an audit record or enriching the logger, which the generated reconcilers
+// run around every call of the methods of their reconciler. Hooks are given
+// to the generated reconcilers through the ReconcileHooks of their
+// controller.Options.
+type ReconcileHook struct {
+	// Name identifies the hook.
+	Name string
+
+	// BeforeReconcile, if set, is called before the method of the
+	// reconciler, DoReconcileKind, DoFinalizeKind or DoObserveKind, is
+	// called for the resource. It returns the context to call the method
+	// with, e.g. the given one with an enriched logger. When it returns an
+	// error, the method is not called and the error is the result of the
+	// reconciliation.
+	BeforeReconcile func(ctx context.Context, method string, resource metav1.Object) (context.Context, error)
+
+	// AfterReconcile, if set, is called after the method of the reconciler
+	// with the event or error it returned, and returns the result of the
+	// reconciliation, usually that event.
+	AfterReconcile func(ctx context.Context, method string, resource metav1.Object, event Event) Event
+}
+
+// RunReconcileHooks calls do for the resource between the hooks: their
+// BeforeReconcile are called in order, and the AfterReconcile of those whose
+// BeforeReconcile succeeded in the reverse order, like deferred calls, so
+// that hooks acquiring a resource before the reconciliation can release it
+// after whatever happened.
+func RunReconcileHooks(ctx context.Context, hooks []ReconcileHook, method string, resource metav1.Object, do func(context.Context) Event) Event {
+	for i, hook := range hooks {
+		if hook.BeforeReconcile == nil {
+			continue
+		}
+		hookCtx, err := hook.BeforeReconcile(ctx, method, resource)
+		if err != nil {
+			return runAfterReconcileHooks(ctx, hooks[:i], method, resource, err)
+		}
+		ctx = hookCtx
+	}
+	return runAfterReconcileHooks(ctx, hooks, method, resource, do(ctx))
+}
+
+func runAfterReconcileHooks(ctx context.Context, hooks []ReconcileHook, method string, resource metav1.Object, event Event) Event {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if after := hooks[i].AfterReconcile; after != nil {
+			event = after(ctx, method, resource, event)
+		}
+	}
+	return event
+}
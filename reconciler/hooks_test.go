@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type hookKey struct{}
+
+func TestRunReconcileHooks(t *testing.T) {
+	resource := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}
+	errLocked := errors.New("locked")
+	errFailed := errors.New("failed")
+
+	var calls []string
+	hook := func(name string, beforeErr error) ReconcileHook {
+		return ReconcileHook{
+			Name: name,
+			BeforeReconcile: func(ctx context.Context, method string, _ metav1.Object) (context.Context, error) {
+				calls = append(calls, "before "+name)
+				if beforeErr != nil {
+					return nil, beforeErr
+				}
+				return context.WithValue(ctx, hookKey{}, name), nil
+			},
+			AfterReconcile: func(ctx context.Context, method string, _ metav1.Object, event Event) Event {
+				calls = append(calls, "after "+name)
+				return event
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		hooks     []ReconcileHook
+		result    Event
+		want      Event
+		wantCalls []string
+		wantCtx   interface{}
+	}{{
+		name:      "no hooks",
+		result:    errFailed,
+		want:      errFailed,
+		wantCalls: []string{"do"},
+	}, {
+		name:      "around",
+		hooks:     []ReconcileHook{hook("lock", nil), hook("audit", nil)},
+		want:      nil,
+		wantCalls: []string{"before lock", "before audit", "do", "after audit", "after lock"},
+		wantCtx:   "audit",
+	}, {
+		name:      "before fails",
+		hooks:     []ReconcileHook{hook("audit", nil), hook("lock", errLocked), hook("logger", nil)},
+		want:      errLocked,
+		wantCalls: []string{"before audit", "before lock", "after audit"},
+	}, {
+		name:      "only after",
+		hooks:     []ReconcileHook{{Name: "wrap", AfterReconcile: func(context.Context, string, metav1.Object, Event) Event { return errFailed }}},
+		want:      errFailed,
+		wantCalls: []string{"do"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = nil
+			got := RunReconcileHooks(context.Background(), tc.hooks, DoReconcileKind, resource, func(ctx context.Context) Event {
+				calls = append(calls, "do")
+				if v := ctx.Value(hookKey{}); v != tc.wantCtx {
+					t.Errorf("Context value = %v, wanted %v", v, tc.wantCtx)
+				}
+				return tc.result
+			})
+			if !errors.Is(got, tc.want) {
+				t.Errorf("RunReconcileHooks() = %v, wanted %v", got, tc.want)
+			}
+			if !cmp.Equal(calls, tc.wantCalls) {
+				t.Error("Calls (-want, +got) =", cmp.Diff(tc.wantCalls, calls))
+			}
+		})
+	}
+}
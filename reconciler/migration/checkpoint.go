@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CheckpointStore persists the progress of migrations, as the key
+// (namespace/name) of the last object migrated before the migration was
+// interrupted. Resuming relies on the API server listing objects in the
+// order of their keys, as it does for the resources stored in etcd.
+type CheckpointStore interface {
+	// Load returns the checkpoint of the resource, or "" if there is none.
+	Load(ctx context.Context, gvr schema.GroupVersionResource) (string, error)
+
+	// Save stores the checkpoint of the resource, clearing it when empty.
+	Save(ctx context.Context, gvr schema.GroupVersionResource, checkpoint string) error
+}
+
+// ConfigMapCheckpoints is a CheckpointStore keeping the checkpoints in a
+// ConfigMap, which is created as needed. A post-install job may for instance
+// keep them in a ConfigMap deleted along with the job.
+type ConfigMapCheckpoints struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+}
+
+var _ CheckpointStore = (*ConfigMapCheckpoints)(nil)
+
+// Load implements CheckpointStore.
+func (c *ConfigMapCheckpoints) Load(ctx context.Context, gvr schema.GroupVersionResource) (string, error) {
+	cm, err := c.Client.CoreV1().ConfigMaps(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return cm.Data[checkpointKey(gvr)], nil
+}
+
+// Save implements CheckpointStore.
+func (c *ConfigMapCheckpoints) Save(ctx context.Context, gvr schema.GroupVersionResource, checkpoint string) error {
+	client := c.Client.CoreV1().ConfigMaps(c.Namespace)
+	cm, err := client.Get(ctx, c.Name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		if checkpoint == "" {
+			return nil
+		}
+		_, err = client.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: c.Namespace,
+				Name:      c.Name,
+			},
+			Data: map[string]string{checkpointKey(gvr): checkpoint},
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	key := checkpointKey(gvr)
+	if cm.Data[key] == checkpoint {
+		return nil
+	}
+	cm = cm.DeepCopy()
+	if checkpoint == "" {
+		delete(cm.Data, key)
+	} else {
+		if cm.Data == nil {
+			cm.Data = make(map[string]string, 1)
+		}
+		cm.Data[key] = checkpoint
+	}
+	_, err = client.Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// checkpointKey returns a valid ConfigMap key for the resource, e.g.
+// "v1.services.serving.knative.dev".
+func checkpointKey(gvr schema.GroupVersionResource) string {
+	return strings.TrimSuffix(gvr.Version+"."+gvr.Resource+"."+gvr.Group, ".")
+}
@@ -0,0 +1,22 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration contains a utility to transform the statuses and
+// metadata of all the objects of a resource, e.g. from a post-install job
+// migrating them after an upgrade. The migration runs page by page with
+// bounded concurrency and rate, reports its progress, and can resume from a
+// checkpoint after it was interrupted.
+package migration
@@ -0,0 +1,291 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"knative.dev/pkg/apis/duck"
+	"knative.dev/pkg/logging"
+)
+
+// Transform mutates the given object as the migration requires. It is passed
+// a copy of the object, which is patched with the changes made to it, if
+// any: the changes to its status through the status subresource, and the
+// others, e.g. to its annotations, through the resource itself.
+// Transforms must be idempotent, as objects may be transformed again when a
+// migration is resumed.
+type Transform func(ctx context.Context, obj *unstructured.Unstructured) error
+
+// Progress reports the progress of a migration.
+type Progress struct {
+	// Listed is the number of objects listed so far.
+	Listed int
+	// Skipped is the number of objects skipped because they had been
+	// migrated before the checkpoint the migration resumed from.
+	Skipped int
+	// Patched is the number of objects patched so far.
+	Patched int
+	// Unchanged is the number of objects which the Transform left unchanged,
+	// or which were deleted before they could be patched.
+	Unchanged int
+}
+
+// Patcher applies a Transform to all the objects of a resource.
+type Patcher struct {
+	client    dynamic.Interface
+	transform Transform
+
+	namespace   string
+	concurrency int
+	pageSize    int64
+	limiter     flowcontrol.RateLimiter
+	checkpoints CheckpointStore
+	progress    func(Progress)
+}
+
+// Option configures a Patcher.
+type Option func(*Patcher)
+
+// WithNamespace restricts the migration to the objects of the namespace.
+func WithNamespace(namespace string) Option {
+	return func(p *Patcher) {
+		p.namespace = namespace
+	}
+}
+
+// WithConcurrency sets how many objects are transformed and patched
+// concurrently, 1 by default.
+func WithConcurrency(concurrency int) Option {
+	return func(p *Patcher) {
+		p.concurrency = concurrency
+	}
+}
+
+// WithPageSize sets how many objects are listed at once, 500 by default.
+// The checkpoint is saved after each page.
+func WithPageSize(size int64) Option {
+	return func(p *Patcher) {
+		p.pageSize = size
+	}
+}
+
+// WithRateLimit limits the patch requests to the given rate per second, with
+// the given burst. The requests are not limited by default.
+func WithRateLimit(qps float32, burst int) Option {
+	return func(p *Patcher) {
+		p.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	}
+}
+
+// WithCheckpoints makes the Patcher save its progress to the store, and
+// resume from there when run again after it was interrupted.
+func WithCheckpoints(store CheckpointStore) Option {
+	return func(p *Patcher) {
+		p.checkpoints = store
+	}
+}
+
+// WithProgress registers a function called with the progress of the
+// migration after each page.
+func WithProgress(f func(Progress)) Option {
+	return func(p *Patcher) {
+		p.progress = f
+	}
+}
+
+// NewPatcher returns a Patcher applying the Transform.
+func NewPatcher(client dynamic.Interface, transform Transform, opts ...Option) *Patcher {
+	p := &Patcher{
+		client:      client,
+		transform:   transform,
+		namespace:   metav1.NamespaceAll,
+		concurrency: 1,
+		pageSize:    500,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Patch applies the Transform to all the objects of the resource, resuming
+// after the checkpoint if any. It stops at the first error, after which it
+// may be run again to resume the migration.
+func (p *Patcher) Patch(ctx context.Context, gvr schema.GroupVersionResource) (Progress, error) {
+	logger := logging.FromContext(ctx).With(zap.String("resource", gvr.String()))
+	client := p.client.Resource(gvr)
+
+	var checkpoint string
+	if p.checkpoints != nil {
+		var err error
+		if checkpoint, err = p.checkpoints.Load(ctx, gvr); err != nil {
+			return Progress{}, fmt.Errorf("failed to load the checkpoint of %s: %w", gvr, err)
+		}
+		if checkpoint != "" {
+			logger.Infof("Resuming the migration after %s", checkpoint)
+		}
+	}
+
+	var (
+		progress Progress
+		mu       sync.Mutex
+	)
+	opts := metav1.ListOptions{Limit: p.pageSize}
+	for {
+		list, err := client.Namespace(p.namespace).List(ctx, opts)
+		if err != nil {
+			return progress, fmt.Errorf("failed to list %s: %w", gvr, err)
+		}
+
+		eg, egCtx := errgroup.WithContext(ctx)
+		eg.SetLimit(p.concurrency)
+		for i := range list.Items {
+			obj := &list.Items[i]
+			progress.Listed++
+			if checkpoint != "" && objectKey(obj) <= checkpoint {
+				progress.Skipped++
+				continue
+			}
+			eg.Go(func() error {
+				// Stop migrating objects after the first error.
+				if err := egCtx.Err(); err != nil {
+					return err
+				}
+				patched, err := p.patch(egCtx, client, obj)
+				if err != nil {
+					return fmt.Errorf("failed to migrate %s %s: %w", gvr, objectKey(obj), err)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if patched {
+					progress.Patched++
+				} else {
+					progress.Unchanged++
+				}
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return progress, err
+		}
+
+		if n := len(list.Items); n > 0 && p.checkpoints != nil {
+			if key := objectKey(&list.Items[n-1]); key > checkpoint {
+				checkpoint = key
+				if err := p.checkpoints.Save(ctx, gvr, checkpoint); err != nil {
+					return progress, fmt.Errorf("failed to save the checkpoint of %s: %w", gvr, err)
+				}
+			}
+		}
+		logger.Infof("Migration progress: %+v", progress)
+		if p.progress != nil {
+			p.progress(progress)
+		}
+
+		if opts.Continue = list.GetContinue(); opts.Continue == "" {
+			break
+		}
+	}
+
+	// Start over the next time, rather than skipping all the objects.
+	if p.checkpoints != nil {
+		if err := p.checkpoints.Save(ctx, gvr, ""); err != nil {
+			return progress, fmt.Errorf("failed to clear the checkpoint of %s: %w", gvr, err)
+		}
+	}
+	return progress, nil
+}
+
+// patch transforms the object and patches the changes, returning whether it
+// was patched.
+func (p *Patcher) patch(ctx context.Context, client dynamic.NamespaceableResourceInterface, obj *unstructured.Unstructured) (bool, error) {
+	after := obj.DeepCopy()
+	if err := p.transform(ctx, after); err != nil {
+		return false, err
+	}
+
+	beforeStatus, hasBeforeStatus := obj.Object["status"]
+	afterStatus, hasAfterStatus := after.Object["status"]
+	delete(obj.Object, "status")
+	delete(after.Object, "status")
+
+	patched := false
+	for _, change := range []struct {
+		before, after interface{}
+		subresources  []string
+	}{{
+		before: obj.Object,
+		after:  after.Object,
+	}, {
+		before:       statusOf(beforeStatus, hasBeforeStatus),
+		after:        statusOf(afterStatus, hasAfterStatus),
+		subresources: []string{"status"},
+	}} {
+		patch, err := duck.CreateMergePatch(change.before, change.after)
+		if err != nil {
+			return false, err
+		}
+		if string(patch) == "{}" {
+			continue
+		}
+
+		if p.limiter != nil {
+			if err := p.limiter.Wait(ctx); err != nil {
+				return false, err
+			}
+		}
+		_, err = client.Namespace(obj.GetNamespace()).Patch(ctx, obj.GetName(),
+			types.MergePatchType, patch, metav1.PatchOptions{}, change.subresources...)
+		if apierrs.IsNotFound(err) {
+			// The object was deleted in the meantime.
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		patched = true
+	}
+	return patched, nil
+}
+
+func statusOf(status interface{}, ok bool) map[string]interface{} {
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"status": status}
+}
+
+// objectKey returns the key of the object, which orders the objects as they
+// are listed.
+func objectKey(obj *unstructured.Unstructured) string {
+	if ns := obj.GetNamespace(); ns != "" {
+		return ns + "/" + obj.GetName()
+	}
+	return obj.GetName()
+}
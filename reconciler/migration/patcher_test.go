@@ -0,0 +1,287 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+var thingsGVR = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "things"}
+
+func thing(namespace, name, phase string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("example.com/v1")
+	u.SetKind("Thing")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	if phase != "" {
+		u.Object["status"] = map[string]interface{}{"phase": phase}
+	}
+	return u
+}
+
+// fakeClient is a fake dynamic client listing the things in the order of
+// their keys, by pages as large as the limit of the requests, which the fake
+// clients don't support.
+type fakeClient struct {
+	*dynamicfake.FakeDynamicClient
+}
+
+func newClient(objs ...runtime.Object) *fakeClient {
+	return &fakeClient{dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{thingsGVR: "ThingList"}, objs...)}
+}
+
+func (c *fakeClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &fakeResource{NamespaceableResourceInterface: c.FakeDynamicClient.Resource(gvr)}
+}
+
+type fakeResource struct {
+	dynamic.NamespaceableResourceInterface
+}
+
+func (r *fakeResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &fakeNamespacedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns)}
+}
+
+type fakeNamespacedResource struct {
+	dynamic.ResourceInterface
+}
+
+func (r *fakeNamespacedResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	all, err := r.ResourceInterface.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all.Items, func(i, j int) bool {
+		return objectKey(&all.Items[i]) < objectKey(&all.Items[j])
+	})
+
+	list := &unstructured.UnstructuredList{Object: all.Object}
+	for i := range all.Items {
+		if opts.Continue != "" && objectKey(&all.Items[i]) <= opts.Continue {
+			continue
+		}
+		list.Items = append(list.Items, all.Items[i])
+	}
+	if opts.Limit > 0 && int64(len(list.Items)) > opts.Limit {
+		list.Items = list.Items[:opts.Limit]
+		list.SetContinue(objectKey(&list.Items[opts.Limit-1]))
+	}
+	return list, nil
+}
+
+func migrate(_ context.Context, obj *unstructured.Unstructured) error {
+	if obj.GetName() == "broken" {
+		return errors.New("broken")
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations["migrated"] = "true"
+	obj.SetAnnotations(annotations)
+	if obj.Object["status"] != nil {
+		return unstructured.SetNestedField(obj.Object, "v2", "status", "phase")
+	}
+	return nil
+}
+
+func TestPatch(t *testing.T) {
+	ctx := logtesting.TestContextWithLogger(t)
+
+	migrated := thing("b", "migrated", "v2")
+	migrated.SetAnnotations(map[string]string{"migrated": "true"})
+	client := newClient(
+		thing("a", "one", "v1"),
+		thing("a", "two", ""),
+		migrated,
+		thing("c", "three", "v1"),
+	)
+
+	var progresses []Progress
+	p := NewPatcher(client, migrate,
+		WithPageSize(2),
+		WithConcurrency(2),
+		WithRateLimit(1000, 10),
+		WithProgress(func(p Progress) {
+			progresses = append(progresses, p)
+		}))
+	got, err := p.Patch(ctx, thingsGVR)
+	if err != nil {
+		t.Fatal("Patch() =", err)
+	}
+	want := Progress{Listed: 4, Patched: 3, Unchanged: 1}
+	if got != want {
+		t.Errorf("Patch() = %+v, wanted %+v", got, want)
+	}
+	if len(progresses) != 2 {
+		t.Errorf("Progress was reported %d times, wanted 2", len(progresses))
+	}
+
+	var patched []string
+	for _, action := range client.Actions() {
+		if pa, ok := action.(clientgotesting.PatchActionImpl); ok {
+			patched = append(patched, pa.GetName()+"/"+pa.GetSubresource())
+		}
+	}
+	sort.Strings(patched)
+	// The things with a status are patched twice.
+	if want := []string{"one/", "one/status", "three/", "three/status", "two/"}; !cmp.Equal(patched, want) {
+		t.Error("Patches (-want, +got):", cmp.Diff(want, patched))
+	}
+
+	for _, obj := range []*unstructured.Unstructured{thing("a", "one", ""), thing("c", "three", "")} {
+		got, err := client.Resource(thingsGVR).Namespace(obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("Get() =", err)
+		}
+		if got.GetAnnotations()["migrated"] != "true" {
+			t.Errorf("%s annotations = %v, wanted migrated", objectKey(got), got.GetAnnotations())
+		}
+		if phase, _, _ := unstructured.NestedString(got.Object, "status", "phase"); phase != "v2" {
+			t.Errorf("%s phase = %q, wanted v2", objectKey(got), phase)
+		}
+	}
+}
+
+func TestPatchResume(t *testing.T) {
+	ctx := logtesting.TestContextWithLogger(t)
+
+	client := newClient(
+		thing("a", "one", "v1"),
+		thing("a", "two", "v1"),
+		thing("b", "broken", "v1"),
+		thing("c", "three", "v1"),
+	)
+	store := &ConfigMapCheckpoints{
+		Client:    kubefake.NewSimpleClientset(),
+		Namespace: "knative-testing",
+		Name:      "migration",
+	}
+
+	if _, err := NewPatcher(client, migrate, WithPageSize(2), WithCheckpoints(store)).Patch(ctx, thingsGVR); err == nil {
+		t.Fatal("Patch() = nil, wanted an error")
+	}
+	if got, err := store.Load(ctx, thingsGVR); err != nil {
+		t.Fatal("Load() =", err)
+	} else if want := "a/two"; got != want {
+		t.Errorf("Load() = %q, wanted %q", got, want)
+	}
+
+	// Fix the broken thing, and resume the migration.
+	if err := client.Tracker().Delete(thingsGVR, "b", "broken"); err != nil {
+		t.Fatal("Delete() =", err)
+	}
+	if err := client.Tracker().Create(thingsGVR, thing("b", "fixed", "v1"), "b"); err != nil {
+		t.Fatal("Create() =", err)
+	}
+	got, err := NewPatcher(client, migrate, WithPageSize(2), WithCheckpoints(store)).Patch(ctx, thingsGVR)
+	if err != nil {
+		t.Fatal("Patch() =", err)
+	}
+	if want := (Progress{Listed: 4, Skipped: 2, Patched: 2}); got != want {
+		t.Errorf("Patch() = %+v, wanted %+v", got, want)
+	}
+
+	// The checkpoint is cleared once the migration is complete.
+	if got, err := store.Load(ctx, thingsGVR); err != nil {
+		t.Fatal("Load() =", err)
+	} else if got != "" {
+		t.Errorf("Load() = %q, wanted none", got)
+	}
+}
+
+func TestPatchConcurrency(t *testing.T) {
+	ctx := logtesting.TestContextWithLogger(t)
+
+	var objs []runtime.Object
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		objs = append(objs, thing("ns", name, ""))
+	}
+	client := newClient(objs...)
+
+	var (
+		mu               sync.Mutex
+		inFlight, maxFly int
+		release          = make(chan struct{})
+		releaseOnce      sync.Once
+	)
+	transform := func(ctx context.Context, obj *unstructured.Unstructured) error {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxFly {
+			maxFly = inFlight
+		}
+		if inFlight == 3 {
+			releaseOnce.Do(func() { close(release) })
+		}
+		mu.Unlock()
+
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return migrate(ctx, obj)
+	}
+
+	got, err := NewPatcher(client, transform, WithConcurrency(3)).Patch(ctx, thingsGVR)
+	if err != nil {
+		t.Fatal("Patch() =", err)
+	}
+	if want := (Progress{Listed: 8, Patched: 8}); got != want {
+		t.Errorf("Patch() = %+v, wanted %+v", got, want)
+	}
+	if maxFly != 3 {
+		t.Errorf("Concurrent transforms = %d, wanted 3", maxFly)
+	}
+}
+
+func TestCheckpointKey(t *testing.T) {
+	tests := []struct {
+		gvr  schema.GroupVersionResource
+		want string
+	}{{
+		gvr:  thingsGVR,
+		want: "v1.things.example.com",
+	}, {
+		gvr:  schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		want: "v1.pods",
+	}}
+
+	for _, test := range tests {
+		if got := checkpointKey(test.gvr); got != test.want {
+			t.Errorf("checkpointKey(%v) = %q, wanted %q", test.gvr, got, test.want)
+		}
+	}
+}
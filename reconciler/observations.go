@@ -0,0 +1,279 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// ObserverLabelKey labels the ConfigMaps of the observations of the
+	// replicas of a component, with the name of the component.
+	ObserverLabelKey = "reconcile.knative.dev/observer"
+
+	// ObserverReplicaAnnotationKey annotates the ConfigMap of the
+	// observations of a replica with the name of the replica.
+	ObserverReplicaAnnotationKey = "reconcile.knative.dev/replica"
+
+	// ObservationRefreshInterval is how often an unchanged observation is
+	// published again, so that the leader can tell the observations of the
+	// replicas which are gone by their age.
+	ObservationRefreshInterval = 30 * time.Second
+)
+
+// Observation is what a replica observed of a resource, e.g. the warmness of
+// its cache or its local health, for the leader of the bucket of the
+// resource to aggregate into its status.
+type Observation struct {
+	// Replica is the name of the replica which observed the resource.
+	Replica string `json:"-"`
+
+	// Time is when the replica last published the observation.
+	Time metav1.Time `json:"time"`
+
+	// Data is the observation, as JSON.
+	Data json.RawMessage `json:"data"`
+}
+
+// Into decodes the data of the observation into the value.
+func (o *Observation) Into(v interface{}) error {
+	return json.Unmarshal(o.Data, v)
+}
+
+// ObservationStore shares the observations of the resources by the replicas
+// of a reconciler. The replicas which are not the leader of the bucket of a
+// resource, and so only observe it with ObserveKind, publish their
+// observations, which the leader lists in ReconcileKind. ObservationStores
+// are given to the generated reconcilers through the ObservationStore of
+// their controller.Options, which attach them to the context of the calls of
+// their methods, see PublishObservation and ListObservations.
+type ObservationStore interface {
+	// Publish publishes the observation of the resource by this replica,
+	// encoded as JSON, or deletes it when the observation is nil.
+	Publish(ctx context.Context, key types.NamespacedName, observation interface{}) error
+
+	// List returns the observations of the resource by all the replicas,
+	// sorted by replica.
+	List(ctx context.Context, key types.NamespacedName) ([]Observation, error)
+}
+
+type observationStoreKey struct{}
+
+// WithObservationStore attaches the ObservationStore to the context.
+func WithObservationStore(ctx context.Context, store ObservationStore) context.Context {
+	return context.WithValue(ctx, observationStoreKey{}, store)
+}
+
+// GetObservationStore returns the ObservationStore of the context, nil if
+// none.
+func GetObservationStore(ctx context.Context) ObservationStore {
+	store, _ := ctx.Value(observationStoreKey{}).(ObservationStore)
+	return store
+}
+
+// PublishObservation publishes the observation of the resource by this
+// replica through the ObservationStore of the context, if any.
+func PublishObservation(ctx context.Context, resource metav1.Object, observation interface{}) error {
+	store := GetObservationStore(ctx)
+	if store == nil {
+		return nil
+	}
+	return store.Publish(ctx, types.NamespacedName{Namespace: resource.GetNamespace(), Name: resource.GetName()}, observation)
+}
+
+// ListObservations returns the observations of the resource by the replicas
+// published at most maxAge ago, or all of them if maxAge is zero, through the
+// ObservationStore of the context. It returns none if there is no store.
+func ListObservations(ctx context.Context, resource metav1.Object, maxAge time.Duration) ([]Observation, error) {
+	store := GetObservationStore(ctx)
+	if store == nil {
+		return nil, nil
+	}
+	observations, err := store.List(ctx, types.NamespacedName{Namespace: resource.GetNamespace(), Name: resource.GetName()})
+	if err != nil || maxAge <= 0 {
+		return observations, err
+	}
+	fresh := observations[:0]
+	for _, o := range observations {
+		if time.Since(o.Time.Time) <= maxAge {
+			fresh = append(fresh, o)
+		}
+	}
+	return fresh, nil
+}
+
+// configMapObservationStore is an ObservationStore keeping the observations
+// of each replica in its own ConfigMap, so that the replicas do not contend
+// on their updates, keyed by namespace_name.
+type configMapObservationStore struct {
+	client    corev1client.ConfigMapInterface
+	component string
+	replica   string
+	now       func() time.Time
+
+	// published are the observations last published by this replica, not
+	// to update the ConfigMap again when they did not change.
+	mu        sync.Mutex
+	published map[string]Observation
+}
+
+// NewConfigMapObservationStore returns an ObservationStore keeping the
+// observations of the replicas of the component in ConfigMaps labeled with
+// ObserverLabelKey. The replica, e.g. the name of its pod, must be a valid
+// part of the name of a ConfigMap. The ConfigMaps of the replicas which are
+// gone are left behind, so the observations should be listed with a maxAge
+// of a few ObservationRefreshInterval.
+func NewConfigMapObservationStore(client corev1client.ConfigMapInterface, component, replica string) ObservationStore {
+	return &configMapObservationStore{
+		client:    client,
+		component: component,
+		replica:   replica,
+		now:       time.Now,
+		published: make(map[string]Observation),
+	}
+}
+
+// observationDataKey returns the key of the observations of the resource in
+// the data of the ConfigMaps. Neither namespaces nor names have underscores.
+func observationDataKey(key types.NamespacedName) string {
+	return key.Namespace + "_" + key.Name
+}
+
+// Publish implements ObservationStore.
+func (s *configMapObservationStore) Publish(ctx context.Context, key types.NamespacedName, observation interface{}) error {
+	dataKey := observationDataKey(key)
+	s.mu.Lock()
+	last, published := s.published[dataKey]
+	delete(s.published, dataKey)
+	s.mu.Unlock()
+
+	var (
+		o     Observation
+		value []byte
+	)
+	if observation != nil {
+		data, err := json.Marshal(observation)
+		if err != nil {
+			return fmt.Errorf("failed to encode the observation: %w", err)
+		}
+		if published && bytes.Equal(last.Data, data) && s.now().Sub(last.Time.Time) < ObservationRefreshInterval {
+			s.remember(dataKey, last)
+			return nil
+		}
+		o = Observation{Time: metav1.NewTime(s.now()), Data: data}
+		if value, err = json.Marshal(o); err != nil {
+			return fmt.Errorf("failed to encode the observation: %w", err)
+		}
+	}
+
+	if err := s.update(ctx, dataKey, value); err != nil {
+		return err
+	}
+	if value != nil {
+		s.remember(dataKey, o)
+	}
+	return nil
+}
+
+func (s *configMapObservationStore) remember(dataKey string, o Observation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published[dataKey] = o
+}
+
+// update sets the value of the key in the ConfigMap of this replica, or
+// deletes it when the value is nil.
+func (s *configMapObservationStore) update(ctx context.Context, dataKey string, value []byte) error {
+	name := s.component + "-observations-" + s.replica
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.client.Get(ctx, name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			if value == nil {
+				return nil
+			}
+			_, err = s.client.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Labels:      map[string]string{ObserverLabelKey: s.component},
+					Annotations: map[string]string{ObserverReplicaAnnotationKey: s.replica},
+				},
+				Data: map[string]string{dataKey: string(value)},
+			}, metav1.CreateOptions{})
+			if apierrs.IsAlreadyExists(err) {
+				// Created concurrently, retry as a conflict.
+				return apierrs.NewConflict(corev1.Resource("configmaps"), name, err)
+			}
+			return err
+		} else if err != nil {
+			return err
+		}
+		if _, ok := cm.Data[dataKey]; !ok && value == nil {
+			return nil
+		}
+		cm = cm.DeepCopy()
+		if value == nil {
+			delete(cm.Data, dataKey)
+		} else {
+			if cm.Data == nil {
+				cm.Data = make(map[string]string, 1)
+			}
+			cm.Data[dataKey] = string(value)
+		}
+		_, err = s.client.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// List implements ObservationStore.
+func (s *configMapObservationStore) List(ctx context.Context, key types.NamespacedName) ([]Observation, error) {
+	cms, err := s.client.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{ObserverLabelKey: s.component}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	dataKey := observationDataKey(key)
+	var observations []Observation
+	for _, cm := range cms.Items {
+		value, ok := cm.Data[dataKey]
+		if !ok {
+			continue
+		}
+		var o Observation
+		if err := json.Unmarshal([]byte(value), &o); err != nil {
+			return nil, fmt.Errorf("failed to decode the observation of ConfigMap %s: %w", cm.Name, err)
+		}
+		o.Replica = cm.Annotations[ObserverReplicaAnnotationKey]
+		observations = append(observations, o)
+	}
+	sort.Slice(observations, func(i, j int) bool { return observations[i].Replica < observations[j].Replica })
+	return observations, nil
+}
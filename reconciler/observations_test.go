@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+)
+
+func keyOf(o metav1.Object) types.NamespacedName {
+	return types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}
+}
+
+type cacheObservation struct {
+	Warm bool `json:"warm"`
+}
+
+func TestConfigMapObservationStore(t *testing.T) {
+	client := fakekube.NewSimpleClientset()
+	cms := client.CoreV1().ConfigMaps("system")
+	now := time.Now().Add(-time.Hour)
+	replica := func(name string) *configMapObservationStore {
+		s := NewConfigMapObservationStore(cms, "controller", name).(*configMapObservationStore)
+		s.now = func() time.Time { return now }
+		return s
+	}
+	a, b := replica("controller-a"), replica("controller-b")
+	resource := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}
+
+	// The observers publish their observations.
+	ctx := context.Background()
+	if err := PublishObservation(WithObservationStore(ctx, b), resource, cacheObservation{Warm: true}); err != nil {
+		t.Fatal("PublishObservation() =", err)
+	}
+	if err := PublishObservation(WithObservationStore(ctx, a), resource, cacheObservation{Warm: false}); err != nil {
+		t.Fatal("PublishObservation() =", err)
+	}
+
+	// The leader lists them.
+	observations, err := ListObservations(WithObservationStore(ctx, a), resource, 0)
+	if err != nil {
+		t.Fatal("ListObservations() =", err)
+	}
+	if len(observations) != 2 {
+		t.Fatalf("ListObservations() = %v, wanted 2 observations", observations)
+	}
+	for i, want := range []struct {
+		replica string
+		warm    bool
+	}{{"controller-a", false}, {"controller-b", true}} {
+		var got cacheObservation
+		if err := observations[i].Into(&got); err != nil {
+			t.Fatal("Into() =", err)
+		}
+		if observations[i].Replica != want.replica || got.Warm != want.warm {
+			t.Errorf("Observation %d = %s %+v, wanted %s %v", i, observations[i].Replica, got, want.replica, want.warm)
+		}
+	}
+
+	// Unchanged observations are only published again once they are due
+	// for a refresh.
+	client.ClearActions()
+	if err := b.Publish(ctx, keyOf(resource), cacheObservation{Warm: true}); err != nil {
+		t.Fatal("Publish() =", err)
+	}
+	if got := len(client.Actions()); got != 0 {
+		t.Errorf("Actions = %d, wanted none for an unchanged observation", got)
+	}
+	now = now.Add(ObservationRefreshInterval)
+	if err := b.Publish(ctx, keyOf(resource), cacheObservation{Warm: true}); err != nil {
+		t.Fatal("Publish() =", err)
+	}
+	if got := len(client.Actions()); got == 0 {
+		t.Error("Actions = 0, wanted the observation to be refreshed")
+	}
+
+	// The observations published too long ago are not listed.
+	observations, err = ListObservations(WithObservationStore(ctx, a), resource, time.Since(now)+time.Second)
+	if err != nil {
+		t.Fatal("ListObservations() =", err)
+	}
+	if len(observations) != 1 || observations[0].Replica != "controller-b" {
+		t.Errorf("ListObservations() = %v, wanted the fresh observation of controller-b", observations)
+	}
+
+	// Nil observations are deleted.
+	if err := PublishObservation(WithObservationStore(ctx, b), resource, nil); err != nil {
+		t.Fatal("PublishObservation() =", err)
+	}
+	observations, err = a.List(ctx, keyOf(resource))
+	if err != nil {
+		t.Fatal("List() =", err)
+	}
+	if len(observations) != 1 || observations[0].Replica != "controller-a" {
+		t.Errorf("List() = %v, wanted the observation of controller-a", observations)
+	}
+}
+
+func TestObservationsWithoutStore(t *testing.T) {
+	resource := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}
+	if err := PublishObservation(context.Background(), resource, cacheObservation{}); err != nil {
+		t.Error("PublishObservation() =", err)
+	}
+	if observations, err := ListObservations(context.Background(), resource, 0); err != nil || observations != nil {
+		t.Errorf("ListObservations() = %v, %v, wanted none", observations, err)
+	}
+}
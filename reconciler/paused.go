@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+const (
+	// PausedAnnotationKey is the annotation which, when set to "true" on a
+	// resource, makes the generated reconcilers skip its reconciliation.
+	// Finalization still happens, so that paused resources can be deleted.
+	PausedAnnotationKey = "reconcile.knative.dev/paused"
+
+	// ConditionPaused is set to True on the KRShaped resources whose
+	// reconciliation is paused. Its severity is Info, so it does not
+	// affect the readiness of the resource.
+	ConditionPaused apis.ConditionType = "Paused"
+
+	pausedReason = "ReconciliationPaused"
+)
+
+var (
+	pausedCountStat = stats.Int64("reconcile_paused_count", "Number of reconciliations skipped on paused resources", stats.UnitDimensionless)
+
+	pausedReconcilerTagKey = tag.MustNewKey("reconciler")
+	pausedNamespaceTagKey  = tag.MustNewKey(metricskey.LabelNamespaceName)
+)
+
+func init() {
+	if err := view.Register(&view.View{
+		Description: "Number of reconciliations skipped on paused resources",
+		Measure:     pausedCountStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{pausedReconcilerTagKey, pausedNamespaceTagKey},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// IsPaused returns whether the reconciliation of the resource is paused
+// through the PausedAnnotationKey annotation.
+func IsPaused(obj metav1.Object) bool {
+	return strings.EqualFold(obj.GetAnnotations()[PausedAnnotationKey], "true")
+}
+
+// MarkPaused sets the Paused condition of the resource to True.
+func MarkPaused(resource duckv1.KRShaped) {
+	resource.GetConditionSet().Manage(resource.GetStatus()).SetCondition(apis.Condition{
+		Type:     ConditionPaused,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+		Reason:   pausedReason,
+		Message:  fmt.Sprintf("Reconciliation is paused by the %q annotation", PausedAnnotationKey),
+	})
+}
+
+// ReportPaused records that the named reconciler skipped the reconciliation
+// of a paused resource in the given namespace.
+func ReportPaused(ctx context.Context, reconciler, namespace string) {
+	ctx, err := tag.New(ctx,
+		tag.Insert(pausedReconcilerTagKey, reconciler),
+		tag.Insert(pausedNamespaceTagKey, namespace))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, pausedCountStat.M(1))
+}
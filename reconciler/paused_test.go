@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+func TestIsPaused(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{{
+		name: "no annotations",
+	}, {
+		name:        "true",
+		annotations: map[string]string{PausedAnnotationKey: "true"},
+		want:        true,
+	}, {
+		name:        "case insensitive",
+		annotations: map[string]string{PausedAnnotationKey: "True"},
+		want:        true,
+	}, {
+		name:        "false",
+		annotations: map[string]string{PausedAnnotationKey: "false"},
+	}, {
+		name:        "other annotation",
+		annotations: map[string]string{"foo": "true"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tc.annotations}
+			if got := IsPaused(obj); got != tc.want {
+				t.Errorf("IsPaused() = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMarkPaused(t *testing.T) {
+	resource := makeResource()
+
+	MarkPaused(resource)
+
+	cond := resource.Status.GetCondition(ConditionPaused)
+	if cond == nil || cond.Status != corev1.ConditionTrue || cond.Severity != apis.ConditionSeverityInfo {
+		t.Errorf("Paused condition = %+v, want True with Info severity", cond)
+	}
+	// The readiness is not affected.
+	if rc := resource.Status.GetCondition(apis.ConditionReady); rc.Status != corev1.ConditionTrue {
+		t.Errorf("Ready condition = %s, want: True", rc.Status)
+	}
+
+	// Reconciling the resource again clears the condition.
+	PreProcessReconcile(context.Background(), resource)
+	if cond := resource.Status.GetCondition(ConditionPaused); cond != nil {
+		t.Errorf("Paused condition = %+v, want: nil", cond)
+	}
+}
+
+func TestReportPaused(t *testing.T) {
+	paused := metricstest.Expect(t, "reconcile_paused_count").WithTags(map[string]string{
+		"reconciler":     "foo-controller",
+		"namespace_name": "ns",
+	})
+	before := paused.Value()
+
+	ReportPaused(context.Background(), "foo-controller", "ns")
+	ReportPaused(context.Background(), "foo-controller", "ns")
+
+	paused.Delta(before, 2)
+}
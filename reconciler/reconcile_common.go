@@ -65,6 +65,11 @@ func PreProcessReconcile(ctx context.Context, resource duckv1.KRShaped) {
 	manager := condSet.Manage(newStatus)
 	manager.InitializeConditions()
 
+	// The reconciliation is no longer paused, if it was.
+	if manager.GetCondition(ConditionPaused) != nil {
+		manager.ClearCondition(ConditionPaused)
+	}
+
 	if newStatus.ObservedGeneration != resource.GetGeneration() {
 		// Reset Ready/Successful to unknown. The reconciler is expected to overwrite this.
 		manager.MarkUnknown(condSet.GetTopLevelConditionType(), failedGenerationBump, "unsuccessfully observed a new generation")
@@ -0,0 +1,238 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+// EventReason is the reason of a StructuredEvent, from a taxonomy of the
+// reconciliation outcomes which is meant to be alerted on. Reconcilers may
+// define their own reasons, in UpperCamelCase.
+type EventReason string
+
+const (
+	// ReasonReconciled is the reason of the successful reconciliations.
+	ReasonReconciled EventReason = "Reconciled"
+	// ReasonReconcileFailed is the reason of the failed reconciliations.
+	ReasonReconcileFailed EventReason = "ReconcileFailed"
+	// ReasonInvalidSpec is the reason of the reconciliations of resources
+	// whose spec cannot be reconciled.
+	ReasonInvalidSpec EventReason = "InvalidSpec"
+	// ReasonDependencyNotReady is the reason of the reconciliations waiting
+	// on another resource, which should be among the related objects.
+	ReasonDependencyNotReady EventReason = "DependencyNotReady"
+	// ReasonDependencyFailed is the reason of the reconciliations failed by
+	// another resource, which should be among the related objects.
+	ReasonDependencyFailed EventReason = "DependencyFailed"
+	// ReasonFinalized is the reason of the successful finalizations.
+	ReasonFinalized EventReason = "Finalized"
+	// ReasonFinalizeFailed is the reason of the failed finalizations.
+	ReasonFinalizeFailed EventReason = "FinalizeFailed"
+)
+
+// EventSeverity is the severity of a StructuredEvent.
+type EventSeverity string
+
+const (
+	// SeverityInfo is the severity of the expected outcomes, recorded as
+	// Normal events. The finalizers are removed on Info events.
+	SeverityInfo EventSeverity = "Info"
+	// SeverityWarning is the severity of the outcomes which may resolve by
+	// themselves, recorded as Warning events.
+	SeverityWarning EventSeverity = "Warning"
+	// SeverityError is the severity of the outcomes which need attention,
+	// recorded as Warning events.
+	SeverityError EventSeverity = "Error"
+)
+
+const (
+	// EventSeverityAnnotationKey is the annotation of the recorded structured
+	// events holding their severity.
+	EventSeverityAnnotationKey = "reconcile.knative.dev/severity"
+	// EventRelatedAnnotationKey is the annotation of the recorded structured
+	// events holding their related objects, as comma separated
+	// Kind:namespace/name references.
+	EventRelatedAnnotationKey = "reconcile.knative.dev/related"
+)
+
+var (
+	eventCountStat = stats.Int64("reconcile_event_count", "Number of structured events returned by the reconcilers", stats.UnitDimensionless)
+
+	eventReconcilerTagKey = tag.MustNewKey("reconciler")
+	eventNamespaceTagKey  = tag.MustNewKey(metricskey.LabelNamespaceName)
+	eventReasonTagKey     = tag.MustNewKey("reason")
+	eventSeverityTagKey   = tag.MustNewKey("severity")
+)
+
+func init() {
+	if err := view.Register(&view.View{
+		Description: "Number of structured events returned by the reconcilers",
+		Measure:     eventCountStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{eventReconcilerTagKey, eventNamespaceTagKey, eventReasonTagKey, eventSeverityTagKey},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// StructuredEvent is an Event of a reason from a taxonomy and a severity,
+// whose message is a template, and which may refer to the objects it is
+// related to. The generated reconcilers record the structured events with
+// their related objects and severity as annotations, and count them by
+// reason and severity.
+type StructuredEvent struct {
+	Reason   EventReason
+	Severity EventSeverity
+	// Template is the text/template of the message, executed with Data.
+	Template string
+	Data     interface{}
+	// Related are the references to the objects the event is related to.
+	Related []corev1.ObjectReference
+
+	// tmpl is the Template parsed by NewStructuredEvent, nil if it failed
+	// to parse.
+	tmpl *template.Template
+}
+
+// make sure StructuredEvent implements error.
+var _ error = (*StructuredEvent)(nil)
+
+// NewStructuredEvent returns a StructuredEvent, whose message is the given
+// text/template executed with data.
+func NewStructuredEvent(severity EventSeverity, reason EventReason, messageTemplate string, data interface{}, related ...corev1.ObjectReference) Event {
+	// The message is formatted several times per recorded event, so the
+	// template is parsed once.
+	tmpl, _ := parseEventTemplate(reason, messageTemplate)
+	return &StructuredEvent{
+		Reason:   reason,
+		Severity: severity,
+		Template: messageTemplate,
+		Data:     data,
+		Related:  related,
+		tmpl:     tmpl,
+	}
+}
+
+// parseEventTemplate parses the template of the message of an event.
+func parseEventTemplate(reason EventReason, messageTemplate string) (*template.Template, error) {
+	return template.New(string(reason)).Option("missingkey=zero").Parse(messageTemplate)
+}
+
+// EventType returns the type of the Kubernetes event of the severity.
+func (e *StructuredEvent) EventType() string {
+	if e.Severity == SeverityInfo {
+		return corev1.EventTypeNormal
+	}
+	return corev1.EventTypeWarning
+}
+
+// Error returns the message of the event, i.e. its template executed with
+// its data, or the template itself if it fails to execute. The template of
+// the events not built with NewStructuredEvent is parsed on every call.
+func (e *StructuredEvent) Error() string {
+	tmpl := e.tmpl
+	if tmpl == nil {
+		var err error
+		if tmpl, err = parseEventTemplate(e.Reason, e.Template); err != nil {
+			return e.Template
+		}
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, e.Data); err != nil {
+		return e.Template
+	}
+	return b.String()
+}
+
+// Is returns whether the target is a StructuredEvent of the same reason and
+// severity, or a ReconcilerEvent of the same type and reason.
+func (e *StructuredEvent) Is(target error) bool {
+	var se *StructuredEvent
+	if errors.As(target, &se) {
+		return se != nil && se.Reason == e.Reason && se.Severity == e.Severity
+	}
+	var re *ReconcilerEvent
+	if errors.As(target, &re) {
+		return re != nil && re.EventType == e.EventType() && re.Reason == string(e.Reason)
+	}
+	return false
+}
+
+// As allows StructuredEvents to be treated as ReconcilerEvents, e.g. to
+// decide whether to remove the finalizers.
+func (e *StructuredEvent) As(target interface{}) bool {
+	if re, ok := target.(**ReconcilerEvent); ok {
+		*re = &ReconcilerEvent{
+			EventType: e.EventType(),
+			Reason:    string(e.Reason),
+			Format:    "%s",
+			Args:      []interface{}{e.Error()},
+		}
+		return true
+	}
+	return false
+}
+
+// Record records the event about the object with the recorder and counts
+// it for the named reconciler.
+func (e *StructuredEvent) Record(ctx context.Context, recorder record.EventRecorder, reconciler string, object runtime.Object) {
+	annotations := map[string]string{EventSeverityAnnotationKey: string(e.Severity)}
+	if len(e.Related) > 0 {
+		refs := make([]string, 0, len(e.Related))
+		for _, ref := range e.Related {
+			refs = append(refs, fmt.Sprintf("%s:%s/%s", ref.Kind, ref.Namespace, ref.Name))
+		}
+		annotations[EventRelatedAnnotationKey] = strings.Join(refs, ",")
+	}
+	recorder.AnnotatedEventf(object, annotations, e.EventType(), string(e.Reason), "%s", e.Error())
+
+	var namespace string
+	if obj, ok := object.(interface{ GetNamespace() string }); ok {
+		namespace = obj.GetNamespace()
+	}
+	ReportEvent(ctx, reconciler, namespace, e.Reason, e.Severity)
+}
+
+// ReportEvent counts a structured event of the given reason and severity of
+// the named reconciler in the given namespace.
+func ReportEvent(ctx context.Context, reconciler, namespace string, reason EventReason, severity EventSeverity) {
+	ctx, err := tag.New(ctx,
+		tag.Insert(eventReconcilerTagKey, reconciler),
+		tag.Insert(eventNamespaceTagKey, namespace),
+		tag.Insert(eventReasonTagKey, string(reason)),
+		tag.Insert(eventSeverityTagKey, string(severity)))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, eventCountStat.M(1))
+}
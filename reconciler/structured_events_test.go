@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+func TestStructuredEventMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{{
+		name: "templated",
+		event: NewStructuredEvent(SeverityWarning, ReasonDependencyNotReady,
+			"{{.Kind}} {{.Name}} is not ready", map[string]string{"Kind": "Service", "Name": "foo"}),
+		want: "Service foo is not ready",
+	}, {
+		name:  "missing key",
+		event: NewStructuredEvent(SeverityInfo, ReasonReconciled, "Reconciled {{.Name}}", map[string]string{}),
+		want:  "Reconciled ",
+	}, {
+		name:  "invalid template",
+		event: NewStructuredEvent(SeverityError, ReasonInvalidSpec, "Invalid {{.Name", nil),
+		want:  "Invalid {{.Name",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.event.Error(); got != tc.want {
+				t.Errorf("Error() = %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStructuredEventIsAs(t *testing.T) {
+	event := NewStructuredEvent(SeverityError, ReasonReconcileFailed, "failed", nil)
+	wrapped := fmt.Errorf("wrapped: %w", event)
+
+	if !EventIs(wrapped, NewStructuredEvent(SeverityError, ReasonReconcileFailed, "", nil)) {
+		t.Error("Expected the error to be a [Error, ReconcileFailed] structured event")
+	}
+	if EventIs(wrapped, NewStructuredEvent(SeverityWarning, ReasonReconcileFailed, "", nil)) {
+		t.Error("Did not expect the error to be a [Warning, ReconcileFailed] structured event")
+	}
+	if !EventIs(wrapped, NewEvent(corev1.EventTypeWarning, string(ReasonReconcileFailed), "")) {
+		t.Error("Expected the error to be a [Warning, ReconcileFailed] event")
+	}
+
+	var re *ReconcilerEvent
+	if !EventAs(wrapped, &re) {
+		t.Fatal("Expected the error to be a ReconcilerEvent")
+	}
+	want := &ReconcilerEvent{
+		EventType: corev1.EventTypeWarning,
+		Reason:    string(ReasonReconcileFailed),
+		Format:    "%s",
+		Args:      []interface{}{"failed"},
+	}
+	if !cmp.Equal(re, want) {
+		t.Error("EventAs() (-want, +got) =", cmp.Diff(want, re))
+	}
+
+	// Info events let the finalizers be removed.
+	if !IsCleanEvent(NewStructuredEvent(SeverityInfo, ReasonFinalized, "", nil)) {
+		t.Error("Expected an Info event to be clean")
+	}
+	if IsCleanEvent(NewStructuredEvent(SeverityWarning, ReasonFinalizeFailed, "", nil)) {
+		t.Error("Did not expect a Warning event to be clean")
+	}
+}
+
+type recordedEvent struct {
+	annotations                map[string]string
+	eventType, reason, message string
+}
+
+// annotatingRecorder records the annotated events.
+type annotatingRecorder struct {
+	events []recordedEvent
+}
+
+func (r *annotatingRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+func (r *annotatingRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+func (r *annotatingRecorder) AnnotatedEventf(_ runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.events = append(r.events, recordedEvent{
+		annotations: annotations,
+		eventType:   eventtype,
+		reason:      reason,
+		message:     fmt.Sprintf(messageFmt, args...),
+	})
+}
+
+func TestStructuredEventRecord(t *testing.T) {
+	recorder := &annotatingRecorder{}
+	object := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}
+	event := &StructuredEvent{
+		Reason:   ReasonDependencyFailed,
+		Severity: SeverityError,
+		Template: "{{.}} failed",
+		Data:     "bar",
+		Related: []corev1.ObjectReference{{
+			Kind:      "Secret",
+			Namespace: "ns",
+			Name:      "bar",
+		}, {
+			Kind: "Node",
+			Name: "baz",
+		}},
+	}
+
+	count := metricstest.Expect(t, "reconcile_event_count").WithTags(map[string]string{
+		"reconciler":     "foo-controller",
+		"namespace_name": "ns",
+		"reason":         "DependencyFailed",
+		"severity":       "Error",
+	})
+	before := count.Value()
+
+	event.Record(context.Background(), recorder, "foo-controller", object)
+	event.Record(context.Background(), recorder, "foo-controller", object)
+
+	want := recordedEvent{
+		annotations: map[string]string{
+			EventSeverityAnnotationKey: "Error",
+			EventRelatedAnnotationKey:  "Secret:ns/bar,Node:/baz",
+		},
+		eventType: corev1.EventTypeWarning,
+		reason:    "DependencyFailed",
+		message:   "bar failed",
+	}
+	if got := recorder.events[0]; !cmp.Equal(got, want, cmp.AllowUnexported(recordedEvent{})) {
+		t.Error("Recorded event (-want, +got) =", cmp.Diff(want, got, cmp.AllowUnexported(recordedEvent{})))
+	}
+
+	count.Delta(before, 2)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"knative.dev/pkg/kmp"
+)
+
+// UpdateGoldenEnvKey is the environment variable which, when true, makes
+// the table tests write their golden files rather than verify them, e.g.
+// with `KNATIVE_UPDATE_GOLDEN=true go test ./pkg/reconciler/...`. It is not
+// a flag, so as not to clash with the flags of the tests.
+const UpdateGoldenEnvKey = "KNATIVE_UPDATE_GOLDEN"
+
+// updateGolden returns whether the golden files are written, see
+// UpdateGoldenEnvKey.
+func updateGolden() bool {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnvKey))
+	return update
+}
+
+// goldenObjects is the content of a golden file. The objects are kept in
+// their JSON form, rather than typed, as the golden file does not say their
+// types, and as this is the form they are diffed in.
+type goldenObjects struct {
+	Creates       []interface{} `json:"creates,omitempty"`
+	Updates       []interface{} `json:"updates,omitempty"`
+	StatusUpdates []interface{} `json:"statusUpdates,omitempty"`
+}
+
+// goldenPath returns the path of the golden file of the row.
+func (r *TableRow) goldenPath() string {
+	if filepath.IsAbs(r.Golden) {
+		return r.Golden
+	}
+	return filepath.Join("testdata", r.Golden)
+}
+
+// verifyGolden verifies the objects of the Create, Update and status Update
+// calls against the golden file of the row, or writes it, see
+// UpdateGoldenEnvKey.
+func (r *TableRow) verifyGolden(t *testing.T, actions Actions) {
+	t.Helper()
+	if len(r.WantCreates) > 0 || len(r.WantUpdates) > 0 || len(r.WantStatusUpdates) > 0 {
+		t.Error("Golden replaces WantCreates, WantUpdates and WantStatusUpdates, which must not be set")
+	}
+
+	got, err := newGoldenObjects(actions)
+	if err != nil {
+		t.Fatal("Failed to serialize the objects:", err)
+	}
+	path := r.goldenPath()
+
+	if updateGolden() {
+		b, err := yaml.Marshal(got)
+		if err != nil {
+			t.Fatal("yaml.Marshal() =", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal("MkdirAll() =", err)
+		}
+		if err := os.WriteFile(path, b, 0o644); err != nil { //nolint:gosec // Checked in with the tests.
+			t.Fatal("WriteFile() =", err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Golden file %s does not exist, run the tests with %s=true to write it", path, UpdateGoldenEnvKey)
+	} else if err != nil {
+		t.Fatal("ReadFile() =", err)
+	}
+	var want goldenObjects
+	if err := yaml.Unmarshal(b, &want); err != nil {
+		t.Fatalf("Failed to parse the golden file %s: %v", path, err)
+	}
+	if diff, err := kmp.SafeDiff(want, *got); err != nil {
+		t.Error("Failed to diff the objects:", err)
+	} else if diff != "" {
+		t.Errorf("Unexpected objects, run the tests with %s=true if expected (-%s, +got):\n%s", UpdateGoldenEnvKey, path, diff)
+	}
+}
+
+// newGoldenObjects returns the objects of the Create, Update and status
+// Update calls in their JSON form.
+func newGoldenObjects(actions Actions) (*goldenObjects, error) {
+	g := &goldenObjects{}
+	for _, create := range actions.Creates {
+		obj, err := goldenObject(create.GetObject())
+		if err != nil {
+			return nil, err
+		}
+		g.Creates = append(g.Creates, obj)
+	}
+	for _, u := range []struct {
+		subresource string
+		objs        *[]interface{}
+	}{{"", &g.Updates}, {"status", &g.StatusUpdates}} {
+		for _, action := range filterUpdatesWithSubresource(u.subresource, actions.Updates) {
+			obj, err := goldenObject(action.GetObject())
+			if err != nil {
+				return nil, err
+			}
+			*u.objs = append(*u.objs, obj)
+		}
+	}
+	return g, nil
+}
+
+// goldenObject returns the JSON form of the object, without the
+// lastTransitionTime of its conditions, which the table tests ignore.
+func goldenObject(obj runtime.Object) (interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", objKey(obj), err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	dropLastTransitionTimes(v)
+	return v, nil
+}
+
+func dropLastTransitionTimes(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		delete(v, "lastTransitionTime")
+		for _, e := range v {
+			dropLastTransitionTimes(e)
+		}
+	case []interface{}:
+		for _, e := range v {
+			dropLastTransitionTimes(e)
+		}
+	}
+}
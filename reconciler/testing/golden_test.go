@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/controller"
+)
+
+// goldenReconciler creates a ConfigMap and updates the status of a
+// Namespace.
+type goldenReconciler struct {
+	client *fakekube.Clientset
+}
+
+func (r *goldenReconciler) Reconcile(ctx context.Context, _ string) error {
+	cm := configMap("created", "")
+	cm.Data = map[string]string{"foo": "bar"}
+	if _, err := r.client.CoreV1().ConfigMaps("ns").Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	ns, err := r.client.CoreV1().Namespaces().Get(ctx, "ns", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	ns.Status.Conditions = []corev1.NamespaceCondition{{
+		Type:               "Ready",
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+	}}
+	_, err = r.client.CoreV1().Namespaces().UpdateStatus(ctx, ns, metav1.UpdateOptions{})
+	return err
+}
+
+func goldenRow(golden string) TableRow {
+	return TableRow{
+		Name:    "golden",
+		Key:     "ns/created",
+		Objects: []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}},
+		Golden:  golden,
+	}
+}
+
+func goldenFactory(t *testing.T, r *TableRow) (controller.Reconciler, ActionRecorderList, EventList) {
+	client := fakekube.NewSimpleClientset(r.Objects...)
+	return &goldenReconciler{client: client}, ActionRecorderList{client}, EventList{Recorder: record.NewFakeRecorder(1)}
+}
+
+func TestGolden(t *testing.T) {
+	row := goldenRow("golden.yaml")
+	row.Test(t, goldenFactory)
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	t.Setenv(UpdateGoldenEnvKey, "true")
+
+	path := filepath.Join(t.TempDir(), "golden", "update.yaml")
+	row := goldenRow(path)
+	row.Test(t, goldenFactory)
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("ReadFile() =", err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "golden.yaml"))
+	if err != nil {
+		t.Fatal("ReadFile() =", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("Golden file = %s, wanted %s", got, want)
+	}
+
+	// The golden file written is then verified.
+	t.Setenv(UpdateGoldenEnvKey, "false")
+	row.Test(t, goldenFactory)
+}
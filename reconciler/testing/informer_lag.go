@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// maxPermutedCacheEvents bounds the CacheEvents of the rows whose events
+// are permuted, as their 720 orders already take a while to test.
+const maxPermutedCacheEvents = 6
+
+// CacheEvent is the change of an object delivered to the informer caches.
+type CacheEvent struct {
+	// Type is watch.Added, watch.Modified or watch.Deleted.
+	Type watch.EventType
+
+	// Object is the object added, modified or deleted.
+	Object runtime.Object
+}
+
+// Added returns the CacheEvent of the addition of the object.
+func Added(obj runtime.Object) CacheEvent {
+	return CacheEvent{Type: watch.Added, Object: obj}
+}
+
+// Modified returns the CacheEvent of the modification of the object.
+func Modified(obj runtime.Object) CacheEvent {
+	return CacheEvent{Type: watch.Modified, Object: obj}
+}
+
+// Deleted returns the CacheEvent of the deletion of the object.
+func Deleted(obj runtime.Object) CacheEvent {
+	return CacheEvent{Type: watch.Deleted, Object: obj}
+}
+
+// LagsInformers returns whether the informer caches of the row lag behind
+// the API server, in which case the factories should build their listers
+// from CacheObjects, and their clients from Objects, and not add the objects
+// created by the reconciler to the listers.
+func (r *TableRow) LagsInformers() bool {
+	return r.CachedObjects != nil || len(r.CacheEvents) > 0
+}
+
+// CacheObjects returns the state of the world as seen by the informer
+// caches: the CachedObjects, or Objects if not set, to which the CacheEvents
+// are applied in order, as an informer would.
+func (r *TableRow) CacheObjects() []runtime.Object {
+	base := r.CachedObjects
+	if base == nil {
+		base = r.Objects
+	}
+	if len(r.CacheEvents) == 0 {
+		return base
+	}
+
+	objs := make([]runtime.Object, len(base))
+	copy(objs, base)
+	index := func(obj runtime.Object) int {
+		key := objKey(obj)
+		for i, o := range objs {
+			if objKey(o) == key {
+				return i
+			}
+		}
+		return -1
+	}
+	for _, event := range r.CacheEvents {
+		i := index(event.Object)
+		switch {
+		case event.Type == watch.Deleted:
+			// Deleting an object the cache does not hold is a no-op.
+			if i >= 0 {
+				objs = append(objs[:i], objs[i+1:]...)
+			}
+		case i >= 0:
+			objs[i] = event.Object
+		default:
+			objs = append(objs, event.Object)
+		}
+	}
+	return objs
+}
+
+// testCacheEventOrders runs the row for every order of its CacheEvents.
+func (r *TableRow) testCacheEventOrders(t *testing.T, factory Factory) {
+	t.Helper()
+	if len(r.CacheEvents) > maxPermutedCacheEvents {
+		t.Fatalf("Cannot permute %d cache events, at most %d are supported", len(r.CacheEvents), maxPermutedCacheEvents)
+	}
+	permute(r.CacheEvents, func(events []CacheEvent) {
+		row := *r
+		row.PermuteCacheEvents = false
+		row.CacheEvents = events
+		t.Run(describeCacheEvents(events), func(t *testing.T) {
+			t.Helper()
+			row.Test(t, factory)
+		})
+	})
+}
+
+// permute calls f with every permutation of the events, generated with
+// Heap's algorithm.
+func permute(events []CacheEvent, f func([]CacheEvent)) {
+	perm := make([]CacheEvent, len(events))
+	copy(perm, events)
+	var generate func(k int)
+	generate = func(k int) {
+		if k <= 1 {
+			events := make([]CacheEvent, len(perm))
+			copy(events, perm)
+			f(events)
+			return
+		}
+		generate(k - 1)
+		for i := 0; i < k-1; i++ {
+			if k%2 == 0 {
+				perm[i], perm[k-1] = perm[k-1], perm[i]
+			} else {
+				perm[0], perm[k-1] = perm[k-1], perm[0]
+			}
+			generate(k - 1)
+		}
+	}
+	generate(len(perm))
+}
+
+// describeCacheEvents names the order of the events, e.g. in subtests.
+func describeCacheEvents(events []CacheEvent) string {
+	descs := make([]string, len(events))
+	for i, event := range events {
+		descs[i] = fmt.Sprintf("%s %s", event.Type, objKey(event.Object))
+	}
+	return strings.Join(descs, ",")
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/controller"
+)
+
+func configMap(name, resourceVersion string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "ns",
+		Name:            name,
+		ResourceVersion: resourceVersion,
+	}}
+}
+
+func TestCacheObjects(t *testing.T) {
+	tests := []struct {
+		name string
+		row  TableRow
+		want []runtime.Object
+		lags bool
+	}{{
+		name: "in sync",
+		row:  TableRow{Objects: []runtime.Object{configMap("foo", "2")}},
+		want: []runtime.Object{configMap("foo", "2")},
+	}, {
+		name: "stale cache",
+		row: TableRow{
+			Objects:       []runtime.Object{configMap("foo", "2"), configMap("bar", "1")},
+			CachedObjects: []runtime.Object{configMap("foo", "1"), configMap("baz", "1")},
+		},
+		want: []runtime.Object{configMap("foo", "1"), configMap("baz", "1")},
+		lags: true,
+	}, {
+		name: "empty cache",
+		row: TableRow{
+			Objects:       []runtime.Object{configMap("foo", "2")},
+			CachedObjects: []runtime.Object{},
+		},
+		want: []runtime.Object{},
+		lags: true,
+	}, {
+		name: "out of order events",
+		row: TableRow{
+			Objects: []runtime.Object{configMap("foo", "3"), configMap("bar", "1")},
+			CacheEvents: []CacheEvent{
+				Modified(configMap("foo", "3")),
+				Modified(configMap("foo", "2")),
+				Deleted(configMap("bar", "1")),
+				Deleted(configMap("missing", "1")),
+				Added(configMap("baz", "1")),
+			},
+		},
+		want: []runtime.Object{configMap("foo", "2"), configMap("baz", "1")},
+		lags: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.row.CacheObjects(); !cmp.Equal(got, tc.want) {
+				t.Error("CacheObjects() (-want, +got) =", cmp.Diff(tc.want, got))
+			}
+			if got := tc.row.LagsInformers(); got != tc.lags {
+				t.Errorf("LagsInformers() = %v, wanted %v", got, tc.lags)
+			}
+		})
+	}
+}
+
+type nopReconciler struct{}
+
+func (nopReconciler) Reconcile(context.Context, string) error { return nil }
+
+func TestPermuteCacheEvents(t *testing.T) {
+	row := TableRow{
+		Name: "permuted",
+		Key:  "ns/foo",
+		CacheEvents: []CacheEvent{
+			Added(configMap("foo", "1")),
+			Modified(configMap("foo", "2")),
+			Deleted(configMap("foo", "2")),
+		},
+		PermuteCacheEvents: true,
+	}
+
+	orders := sets.NewString()
+	caches := map[string]int{}
+	row.Test(t, func(t *testing.T, r *TableRow) (controller.Reconciler, ActionRecorderList, EventList) {
+		orders.Insert(describeCacheEvents(r.CacheEvents))
+		cache := "empty"
+		if objs := r.CacheObjects(); len(objs) == 1 {
+			cache = objs[0].(*corev1.ConfigMap).ResourceVersion
+		}
+		caches[cache]++
+		return nopReconciler{}, nil, EventList{Recorder: record.NewFakeRecorder(1)}
+	})
+
+	if got := orders.Len(); got != 6 {
+		t.Errorf("Orders = %d, wanted 6", got)
+	}
+	// The cache is empty when the deletion is delivered last, and otherwise
+	// holds the version of the object delivered last.
+	want := map[string]int{"empty": 2, "1": 2, "2": 2}
+	if !cmp.Equal(caches, want) {
+		t.Error("Caches (-want, +got) =", cmp.Diff(want, caches))
+	}
+}
@@ -51,6 +51,24 @@ type TableRow struct {
 	// Objects holds the state of the world at the onset of reconciliation.
 	Objects []runtime.Object
 
+	// CachedObjects, when set, holds the state of the world as seen by the
+	// informer caches, i.e. the listers, which then lag behind Objects, that
+	// of the API server, i.e. the clients. For example, the caches may miss
+	// the objects created recently, still hold those deleted recently, or
+	// hold older versions of them. See CacheObjects.
+	CachedObjects []runtime.Object
+
+	// CacheEvents are delivered in order to the informer caches, starting
+	// from CachedObjects or Objects, before reconciliation. They may be out
+	// of order, e.g. the deletion of an object before its creation, or an
+	// older version of an object after a newer one. See CacheObjects.
+	CacheEvents []CacheEvent
+
+	// PermuteCacheEvents runs this row for every order of its CacheEvents,
+	// to verify the reconciler gets to the same result whatever the order
+	// the events are delivered in.
+	PermuteCacheEvents bool
+
 	// Key is the parameter to reconciliation.
 	// This has the form "namespace/name".
 	Key string
@@ -58,6 +76,14 @@ type TableRow struct {
 	// WantErr holds whether we should expect the reconciliation to result in an error.
 	WantErr bool
 
+	// Golden, when set, is the path of the golden file holding the objects
+	// of the Create, Update and status Update calls we expect during
+	// reconciliation, in YAML, instead of WantCreates, WantUpdates and
+	// WantStatusUpdates. Relative paths are relative to the testdata
+	// directory. The golden file is written when the tests run with
+	// UpdateGoldenEnvKey set.
+	Golden string
+
 	// WantCreates holds the ordered list of Create calls we expect during reconciliation.
 	WantCreates []runtime.Object
 
@@ -139,6 +165,10 @@ type Factory func(*testing.T, *TableRow) (controller.Reconciler, ActionRecorderL
 // Test executes the single table test.
 func (r *TableRow) Test(t *testing.T, factory Factory) {
 	t.Helper()
+	if r.PermuteCacheEvents {
+		r.testCacheEventOrders(t, factory)
+		return
+	}
 	c, recorderList, eventList := factory(t, r)
 
 	// Set the Reconciler for PostConditions to access it post-Reconcile()
@@ -168,6 +198,105 @@ func (r *TableRow) Test(t *testing.T, factory Factory) {
 		t.Errorf("Error capturing actions by verb: %q", err)
 	}
 
+	if r.Golden != "" {
+		r.verifyGolden(t, actions)
+	} else {
+		r.verifyWantedObjects(t, actions, expectedNamespace)
+	}
+
+	updates := filterUpdatesWithSubresource("", actions.Updates)
+	statusUpdates := filterUpdatesWithSubresource("status", actions.Updates)
+	if len(statusUpdates)+len(updates) != len(actions.Updates) {
+		var unexpected []runtime.Object
+
+		for _, update := range actions.Updates {
+			if update.GetSubresource() != "status" && update.GetSubresource() != "" {
+				unexpected = append(unexpected, update.GetObject())
+			}
+		}
+
+		t.Errorf("Unexpected subresource updates occurred %#v", unexpected)
+	}
+
+	// Build a set of unique strings that represent type-name{-namespace}.
+	// Adding type will help catch the bugs where several similarly named
+	// resources are deleted (and some should or should not).
+	gotDeletes := make(sets.String, len(actions.Deletes))
+	for _, w := range actions.Deletes {
+		n := w.GetResource().Resource + "~~" + w.GetName()
+		if !r.SkipNamespaceValidation {
+			n += "~~" + w.GetNamespace()
+		}
+		gotDeletes.Insert(n)
+	}
+	wantDeletes := make(sets.String, len(actions.Deletes))
+	for _, w := range r.WantDeletes {
+		n := w.GetResource().Resource + "~~" + w.GetName()
+		if !r.SkipNamespaceValidation {
+			n += "~~" + w.GetNamespace()
+		}
+		wantDeletes.Insert(n)
+	}
+	if !gotDeletes.Equal(wantDeletes) {
+		if extra := gotDeletes.Difference(wantDeletes); len(extra) > 0 {
+			t.Error("Extra or unexpected deletes:", extra.UnsortedList())
+		}
+		if missing := wantDeletes.Difference(gotDeletes); len(missing) > 0 {
+			t.Error("Missing deletes:", missing.UnsortedList())
+		}
+	}
+
+	for i, want := range r.WantPatches {
+		if i >= len(actions.Patches) {
+			t.Errorf("Missing patch: %#v; raw: %s", want, string(want.GetPatch()))
+			continue
+		}
+
+		got := actions.Patches[i]
+		if got.GetName() != want.GetName() {
+			t.Errorf("Unexpected patch[%d]: %#v", i, got)
+		}
+		if (!r.SkipNamespaceValidation && got.GetNamespace() != expectedNamespace) &&
+			(!r.SkipNamespaceValidation && got.GetResource().GroupResource().Resource != "namespaces" &&
+				got.GetName() != expectedNamespace) {
+			t.Errorf("Unexpected patch[%d]: %#v", i, got)
+		}
+		if got, want := string(got.GetPatch()), string(want.GetPatch()); got != want {
+			t.Errorf("Unexpected patch(-want, +got):\n%s", cmp.Diff(want, got))
+		}
+	}
+	if got, want := len(actions.Patches), len(r.WantPatches); got > want {
+		for _, extra := range actions.Patches[want:] {
+			t.Errorf("Extra patch: %#v; raw: %s", extra, string(extra.GetPatch()))
+		}
+	}
+
+	gotEvents := eventList.Events()
+	for i, want := range r.WantEvents {
+		if i >= len(gotEvents) {
+			t.Error("Missing event:", want)
+			continue
+		}
+
+		if !cmp.Equal(want, gotEvents[i]) {
+			t.Errorf("Unexpected event(-want, +got):\n%s", cmp.Diff(want, gotEvents[i]))
+		}
+	}
+	if got, want := len(gotEvents), len(r.WantEvents); got > want {
+		for _, extra := range gotEvents[want:] {
+			t.Error("Extra event:", extra)
+		}
+	}
+
+	for _, verify := range r.PostConditions {
+		verify(t, r)
+	}
+}
+
+// verifyWantedObjects verifies the objects of the Create, Update and status
+// Update calls against WantCreates, WantUpdates and WantStatusUpdates.
+func (r *TableRow) verifyWantedObjects(t *testing.T, actions Actions, expectedNamespace string) {
+	t.Helper()
 	effectiveOpts := append(r.CmpOpts, defaultCmpOpts...)
 	// Previous state is used to diff resource expected state for update requests that were missed.
 	objPrevState := make(map[string]runtime.Object, len(r.Objects))
@@ -273,92 +402,6 @@ func (r *TableRow) Test(t *testing.T, factory Factory) {
 				cmp.Diff(wo, oldObj, effectiveOpts...))
 		}
 	}
-
-	if len(statusUpdates)+len(updates) != len(actions.Updates) {
-		var unexpected []runtime.Object
-
-		for _, update := range actions.Updates {
-			if update.GetSubresource() != "status" && update.GetSubresource() != "" {
-				unexpected = append(unexpected, update.GetObject())
-			}
-		}
-
-		t.Errorf("Unexpected subresource updates occurred %#v", unexpected)
-	}
-
-	// Build a set of unique strings that represent type-name{-namespace}.
-	// Adding type will help catch the bugs where several similarly named
-	// resources are deleted (and some should or should not).
-	gotDeletes := make(sets.String, len(actions.Deletes))
-	for _, w := range actions.Deletes {
-		n := w.GetResource().Resource + "~~" + w.GetName()
-		if !r.SkipNamespaceValidation {
-			n += "~~" + w.GetNamespace()
-		}
-		gotDeletes.Insert(n)
-	}
-	wantDeletes := make(sets.String, len(actions.Deletes))
-	for _, w := range r.WantDeletes {
-		n := w.GetResource().Resource + "~~" + w.GetName()
-		if !r.SkipNamespaceValidation {
-			n += "~~" + w.GetNamespace()
-		}
-		wantDeletes.Insert(n)
-	}
-	if !gotDeletes.Equal(wantDeletes) {
-		if extra := gotDeletes.Difference(wantDeletes); len(extra) > 0 {
-			t.Error("Extra or unexpected deletes:", extra.UnsortedList())
-		}
-		if missing := wantDeletes.Difference(gotDeletes); len(missing) > 0 {
-			t.Error("Missing deletes:", missing.UnsortedList())
-		}
-	}
-
-	for i, want := range r.WantPatches {
-		if i >= len(actions.Patches) {
-			t.Errorf("Missing patch: %#v; raw: %s", want, string(want.GetPatch()))
-			continue
-		}
-
-		got := actions.Patches[i]
-		if got.GetName() != want.GetName() {
-			t.Errorf("Unexpected patch[%d]: %#v", i, got)
-		}
-		if (!r.SkipNamespaceValidation && got.GetNamespace() != expectedNamespace) &&
-			(!r.SkipNamespaceValidation && got.GetResource().GroupResource().Resource != "namespaces" &&
-				got.GetName() != expectedNamespace) {
-			t.Errorf("Unexpected patch[%d]: %#v", i, got)
-		}
-		if got, want := string(got.GetPatch()), string(want.GetPatch()); got != want {
-			t.Errorf("Unexpected patch(-want, +got):\n%s", cmp.Diff(want, got))
-		}
-	}
-	if got, want := len(actions.Patches), len(r.WantPatches); got > want {
-		for _, extra := range actions.Patches[want:] {
-			t.Errorf("Extra patch: %#v; raw: %s", extra, string(extra.GetPatch()))
-		}
-	}
-
-	gotEvents := eventList.Events()
-	for i, want := range r.WantEvents {
-		if i >= len(gotEvents) {
-			t.Error("Missing event:", want)
-			continue
-		}
-
-		if !cmp.Equal(want, gotEvents[i]) {
-			t.Errorf("Unexpected event(-want, +got):\n%s", cmp.Diff(want, gotEvents[i]))
-		}
-	}
-	if got, want := len(gotEvents), len(r.WantEvents); got > want {
-		for _, extra := range gotEvents[want:] {
-			t.Error("Extra event:", extra)
-		}
-	}
-
-	for _, verify := range r.PostConditions {
-		verify(t, r)
-	}
 }
 
 func filterUpdatesWithSubresource(
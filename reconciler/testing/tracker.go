@@ -18,6 +18,7 @@ package testing
 
 import (
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -36,7 +37,7 @@ type FakeTracker struct {
 	references map[tracker.Reference]map[types.NamespacedName]struct{}
 }
 
-var _ tracker.Interface = (*FakeTracker)(nil)
+var _ tracker.LeaseInterface = (*FakeTracker)(nil)
 
 // OnChanged implements OnChanged.
 func (*FakeTracker) OnChanged(interface{}) {}
@@ -120,6 +121,14 @@ func (n *FakeTracker) TrackReference(ref tracker.Reference, obj interface{}) err
 	return nil
 }
 
+// TrackReferenceWithLease implements tracker.LeaseInterface.
+func (n *FakeTracker) TrackReferenceWithLease(ref tracker.Reference, obj interface{}, _ time.Duration) error {
+	return n.TrackReference(ref, obj)
+}
+
+// PruneExpired implements tracker.LeaseInterface.
+func (*FakeTracker) PruneExpired() {}
+
 // References returns the list of objects being tracked
 func (n *FakeTracker) References() []tracker.Reference {
 	n.Lock()
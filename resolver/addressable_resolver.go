@@ -115,6 +115,17 @@ func (r *URIResolver) URIFromDestinationV1(ctx context.Context, dest duckv1.Dest
 	return addr.URL, nil
 }
 
+// URIFromDestinationV1WithVariables resolves a v1.Destination whose URI may be
+// a URI template (see duckv1.WithURITemplates) into a URL, expanding the
+// template with the given variables.
+func (r *URIResolver) URIFromDestinationV1WithVariables(ctx context.Context, dest duckv1.Destination, parent interface{}, vars map[string]string) (*apis.URL, error) {
+	u, err := r.URIFromDestinationV1(ctx, dest, parent)
+	if err != nil {
+		return nil, err
+	}
+	return duckv1.ExpandURITemplate(u, vars)
+}
+
 func (r *URIResolver) URIFromKReference(ctx context.Context, ref *duckv1.KReference, parent interface{}) (*apis.URL, error) {
 	dest := duckv1.Destination{
 		Ref: ref,
@@ -799,6 +799,83 @@ func TestAddressableFromDestinationScheme(t *testing.T) {
 	}
 }
 
+func TestURIFromDestinationV1WithVariables(t *testing.T) {
+	tests := map[string]struct {
+		objects []runtime.Object
+		dest    duckv1.Destination
+		vars    map[string]string
+		wantURI string
+		wantErr string
+	}{"templated URI": {
+		dest: duckv1.Destination{
+			URI: &apis.URL{
+				Scheme:   "http",
+				Host:     "example.com",
+				Path:     "/events/{type}",
+				RawQuery: "source={source}",
+			},
+		},
+		vars:    map[string]string{"type": "dev.knative.foo", "source": "/apis/v1"},
+		wantURI: "http://example.com/events/dev.knative.foo?source=%2Fapis%2Fv1",
+	}, "ref with templated relative URI": {
+		objects: []runtime.Object{
+			getAddressable(),
+		},
+		dest: duckv1.Destination{
+			Ref: addressableKnativeRef(),
+			URI: &apis.URL{
+				Path: "/events/{type}",
+			},
+		},
+		vars:    map[string]string{"type": "dev.knative.foo"},
+		wantURI: addressableDNS + "/events/dev.knative.foo",
+	}, "URI without template": {
+		dest: duckv1.Destination{
+			URI: &apis.URL{
+				Scheme: "http",
+				Host:   "example.com",
+				Path:   "/foo",
+			},
+		},
+		wantURI: "http://example.com/foo",
+	}, "undefined variable": {
+		dest: duckv1.Destination{
+			URI: &apis.URL{
+				Scheme: "http",
+				Host:   "example.com",
+				Path:   "/events/{type}",
+			},
+		},
+		wantErr: "undefined URI template variables: type",
+	}}
+
+	for n, tc := range tests {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := fakedynamicclient.With(context.Background(), scheme.Scheme, tc.objects...)
+			ctx = addressable.WithDuck(ctx)
+			r := resolver.NewURIResolverFromTracker(ctx, tracker.New(func(types.NamespacedName) {}, 0))
+
+			uri, gotErr := r.URIFromDestinationV1WithVariables(ctx, tc.dest, getAddressable(), tc.vars)
+			if gotErr != nil {
+				if tc.wantErr != "" {
+					if got, want := gotErr.Error(), tc.wantErr; got != want {
+						t.Errorf("Unexpected error (-want, +got) =\n%s", cmp.Diff(want, got))
+					}
+				} else {
+					t.Error("Unexpected error:", gotErr)
+				}
+				return
+			}
+			if tc.wantErr != "" {
+				t.Error("Expected error:", tc.wantErr)
+			}
+			if got, want := uri.String(), tc.wantURI; got != want {
+				t.Errorf("Unexpected object (-want, +got) =\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
 func TestAddressableFromDestinationV1CACerts(t *testing.T) {
 	certDestination := "CA CERT FOR DESTINATION"
 	tests := map[string]struct {
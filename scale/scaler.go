@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scale
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	pkgapisduck "knative.dev/pkg/apis/duck"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/client/injection/ducks/duck/v1/scalable"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/tracker"
+)
+
+// scaleSubresource is the name of the subresource of scalable workloads.
+const scaleSubresource = "scale"
+
+// Scaler reads and scales workloads generically: their status is read from
+// a Scalable duck informer, and their replicas are changed through their
+// /scale subresource.
+type Scaler struct {
+	client        dynamic.Interface
+	tracker       tracker.Interface
+	listerFactory func(schema.GroupVersionResource) (cache.GenericLister, error)
+}
+
+// NewScalerFromTracker constructs a new Scaler with context and a tracker,
+// which is notified when the workloads read through Get change.
+func NewScalerFromTracker(ctx context.Context, t tracker.Interface) *Scaler {
+	ret := &Scaler{
+		client:  dynamicclient.Get(ctx),
+		tracker: t,
+	}
+
+	informerFactory := &pkgapisduck.CachedInformerFactory{
+		Delegate: &pkgapisduck.EnqueueInformerFactory{
+			Delegate:     scalable.Get(ctx),
+			EventHandler: controller.HandleAll(ret.tracker.OnChanged),
+		},
+	}
+
+	ret.listerFactory = func(gvr schema.GroupVersionResource) (cache.GenericLister, error) {
+		_, l, err := informerFactory.Get(ctx, gvr)
+		return l, err
+	}
+
+	return ret
+}
+
+func workloadResource(ref duckv1.WorkloadReference) schema.GroupVersionResource {
+	gvr, _ := meta.UnsafeGuessKindToResource(ref.GroupVersionKind())
+	return gvr
+}
+
+// Get returns the workload referenced from the given namespace, viewed as a
+// Scalable, and tracks it on behalf of parent. The returned object must not
+// be modified.
+func (s *Scaler) Get(ctx context.Context, namespace string, ref duckv1.WorkloadReference, parent interface{}) (*duckv1.Scalable, error) {
+	if err := s.tracker.TrackReference(tracker.Reference{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Namespace:  namespace,
+		Name:       ref.Name,
+	}, parent); err != nil {
+		return nil, fmt.Errorf("failed to track workload %s %s/%s: %w", ref.Kind, namespace, ref.Name, err)
+	}
+
+	gvr := workloadResource(ref)
+	lister, err := s.listerFactory(gvr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lister for %s: %w", gvr.String(), err)
+	}
+	obj, err := lister.ByNamespace(namespace).Get(ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workload %s/%s: %w", namespace, ref.Name, err)
+	}
+	workload, ok := obj.(*duckv1.Scalable)
+	if !ok {
+		return nil, fmt.Errorf("%s(%T) is not a Scalable", ref.Name, obj)
+	}
+	return workload, nil
+}
+
+// GetScale returns the /scale subresource of the workload referenced from
+// the given namespace.
+func (s *Scaler) GetScale(ctx context.Context, namespace string, ref duckv1.WorkloadReference) (*autoscalingv1.Scale, error) {
+	u, err := s.client.Resource(workloadResource(ref)).Namespace(namespace).Get(ctx, ref.Name, metav1.GetOptions{}, scaleSubresource)
+	if err != nil {
+		return nil, err
+	}
+	return toScale(u)
+}
+
+// SetReplicas sets the desired replicas of the workload referenced from the
+// given namespace through its /scale subresource, and returns the updated
+// scale.
+func (s *Scaler) SetReplicas(ctx context.Context, namespace string, ref duckv1.WorkloadReference, replicas int32) (*autoscalingv1.Scale, error) {
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	u, err := s.client.Resource(workloadResource(ref)).Namespace(namespace).Patch(ctx, ref.Name,
+		types.MergePatchType, patch, metav1.PatchOptions{}, scaleSubresource)
+	if err != nil {
+		return nil, err
+	}
+	return toScale(u)
+}
+
+func toScale(u *unstructured.Unstructured) (*autoscalingv1.Scale, error) {
+	scale := &autoscalingv1.Scale{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, scale); err != nil {
+		return nil, fmt.Errorf("failed to convert %s to a Scale: %w", u.GetName(), err)
+	}
+	return scale, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scale_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgotesting "k8s.io/client-go/testing"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/client/injection/ducks/duck/v1/scalable"
+	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
+	"knative.dev/pkg/scale"
+	"knative.dev/pkg/tracker"
+)
+
+const (
+	testNS       = "testnamespace"
+	workloadName = "workload"
+)
+
+var workloadRef = duckv1.WorkloadReference{
+	APIVersion: "example.dev/v1",
+	Kind:       "Workload",
+	Name:       workloadName,
+}
+
+func newScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	gv := schema.GroupVersion{Group: "example.dev", Version: "v1"}
+	s.AddKnownTypeWithName(gv.WithKind("Workload"), &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(gv.WithKind("WorkloadList"), &unstructured.UnstructuredList{})
+	return s
+}
+
+func workload() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.dev/v1",
+		"kind":       "Workload",
+		"metadata": map[string]interface{}{
+			"namespace":  testNS,
+			"name":       workloadName,
+			"generation": int64(2),
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "workload"},
+			},
+		},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(2),
+			"replicas":           int64(3),
+			"readyReplicas":      int64(2),
+		},
+	}}
+}
+
+func scaleObject(replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v1",
+		"kind":       "Scale",
+		"metadata": map[string]interface{}{
+			"namespace": testNS,
+			"name":      workloadName,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+		"status": map[string]interface{}{
+			"replicas": int64(3),
+			"selector": "app=workload",
+		},
+	}}
+}
+
+func TestScalerGet(t *testing.T) {
+	ctx, _ := fakedynamicclient.With(context.Background(), newScheme(), workload())
+	ctx = scalable.WithDuck(ctx)
+	s := scale.NewScalerFromTracker(ctx, tracker.New(func(types.NamespacedName) {}, 0))
+
+	got, err := s.Get(ctx, testNS, workloadRef, workload())
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if got.DesiredReplicas() != 3 || got.Status.ReadyReplicas != 2 || got.IsScaledUp() {
+		t.Errorf("Get() = %+v", got)
+	}
+
+	missing := workloadRef
+	missing.Name = "missing"
+	if _, err := s.Get(ctx, testNS, missing, workload()); err == nil {
+		t.Error("Get() = nil, wanted error for a missing workload")
+	}
+}
+
+func TestScalerScale(t *testing.T) {
+	ctx, client := fakedynamicclient.With(context.Background(), newScheme(), workload())
+	ctx = scalable.WithDuck(ctx)
+
+	replicas := int64(3)
+	client.PrependReactor("get", "workloads", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		return true, scaleObject(replicas), nil
+	})
+	client.PrependReactor("patch", "workloads", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		patch := action.(clientgotesting.PatchAction)
+		if patch.GetPatchType() != types.MergePatchType {
+			t.Errorf("PatchType = %v, want: %v", patch.GetPatchType(), types.MergePatchType)
+		}
+		var body struct {
+			Spec struct {
+				Replicas int64 `json:"replicas"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(patch.GetPatch(), &body); err != nil {
+			t.Fatal("Unmarshal() =", err)
+		}
+		replicas = body.Spec.Replicas
+		return true, scaleObject(replicas), nil
+	})
+
+	s := scale.NewScalerFromTracker(ctx, tracker.New(func(types.NamespacedName) {}, 0))
+
+	got, err := s.GetScale(ctx, testNS, workloadRef)
+	if err != nil {
+		t.Fatal("GetScale() =", err)
+	}
+	if got.Spec.Replicas != 3 || got.Status.Selector != "app=workload" {
+		t.Errorf("GetScale() = %+v", got)
+	}
+
+	got, err = s.SetReplicas(ctx, testNS, workloadRef, 5)
+	if err != nil {
+		t.Fatal("SetReplicas() =", err)
+	}
+	if got.Spec.Replicas != 5 {
+		t.Errorf("SetReplicas().Spec.Replicas = %d, want: 5", got.Spec.Replicas)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signals
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ShutdownHook is run when the process shuts down, until the context is
+// done, e.g. to drain its connections.
+type ShutdownHook func(ctx context.Context) error
+
+type shutdownHooksKey struct{}
+
+type shutdownHooks struct {
+	mu    sync.Mutex
+	hooks []ShutdownHook
+}
+
+// WithShutdownHooks returns a context to which shutdown hooks can be added
+// with OnShutdown, for RunShutdownHooks to run them.
+func WithShutdownHooks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shutdownHooksKey{}, &shutdownHooks{})
+}
+
+// OnShutdown adds the hook to the shutdown hooks of the context, and returns
+// false if the context has none, see WithShutdownHooks.
+func OnShutdown(ctx context.Context, hook ShutdownHook) bool {
+	sh, ok := ctx.Value(shutdownHooksKey{}).(*shutdownHooks)
+	if !ok {
+		return false
+	}
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.hooks = append(sh.hooks, hook)
+	return true
+}
+
+// RunShutdownHooks runs the shutdown hooks of the context concurrently, with
+// a context timing out after the given timeout as the context is usually
+// done by then, and returns once they all have returned, with their errors.
+func RunShutdownHooks(ctx context.Context, timeout time.Duration) error {
+	sh, ok := ctx.Value(shutdownHooksKey{}).(*shutdownHooks)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	sh.mu.Lock()
+	hooks := append([]ShutdownHook(nil), sh.hooks...)
+	sh.mu.Unlock()
+
+	errs := make([]error, len(hooks))
+	var wg sync.WaitGroup
+	for i, hook := range hooks {
+		wg.Add(1)
+		go func(i int, hook ShutdownHook) {
+			defer wg.Done()
+			errs[i] = hook(ctx)
+		}(i, hook)
+	}
+	wg.Wait()
+
+	var msgs []string
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("shutdown hooks failed: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
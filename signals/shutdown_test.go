@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signals
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	if OnShutdown(context.Background(), func(context.Context) error { return nil }) {
+		t.Error("OnShutdown() = true without shutdown hooks, wanted false")
+	}
+	if err := RunShutdownHooks(context.Background(), time.Second); err != nil {
+		t.Error("RunShutdownHooks() =", err)
+	}
+
+	ctx := WithShutdownHooks(context.Background())
+	var ran int32
+	ok := func(context.Context) error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	}
+	failing := func(context.Context) error {
+		atomic.AddInt32(&ran, 1)
+		return errors.New("boom")
+	}
+	for _, hook := range []ShutdownHook{ok, failing, ok} {
+		if !OnShutdown(ctx, hook) {
+			t.Fatal("OnShutdown() = false, wanted true")
+		}
+	}
+
+	err := RunShutdownHooks(ctx, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("RunShutdownHooks() = %v, wanted the error of the failing hook", err)
+	}
+	if got := atomic.LoadInt32(&ran); got != 3 {
+		t.Errorf("Hooks run = %d, wanted 3", got)
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package duckcrd installs throwaway CRDs implementing a duck type, so that
+// the controllers consuming duck types can be e2e tested without depending
+// on the CRDs of another project:
+//
+//	crd := duckcrd.Install(ctx, t, apixClient, duckcrd.Addressable)
+//	obj := crd.Create(ctx, t, dynamicClient, namespace, "my-addressable")
+//	ref := crd.Reference(obj)
+//
+// The CRDs, and hence their instances, are deleted when the test completes.
+package duckcrd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apixclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/network"
+	"knative.dev/pkg/ptr"
+	"knative.dev/pkg/test"
+	"knative.dev/pkg/test/helpers"
+)
+
+const (
+	// groupSuffix is the suffix of the random API groups of the CRDs.
+	groupSuffix = ".duck.e2e.knative.dev"
+	version     = "v1"
+
+	pollInterval = time.Second
+	pollTimeout  = time.Minute
+)
+
+// T is the subset of testing.T used by the package.
+type T interface {
+	Name() string
+	Helper()
+	Cleanup(func())
+	Log(args ...interface{})
+	Fatal(args ...interface{})
+}
+
+// Type is a duck type the CRDs can implement.
+type Type struct {
+	// Kind is the kind of the CRDs implementing the duck type.
+	Kind string
+
+	// Labels are the labels of the CRDs, declaring the duck type they
+	// implement for e.g. the aggregated ClusterRoles.
+	Labels map[string]string
+
+	// schema returns the schema of the spec and the status of the CRDs.
+	schema func() (spec, status apixv1.JSONSchemaProps)
+
+	// instance populates the spec and the status of the named instance.
+	instance func(obj *unstructured.Unstructured) error
+}
+
+var (
+	// Addressable is the Addressable duck type. The instances are addressed
+	// by the hostname of a service of their name.
+	Addressable = Type{
+		Kind:   "Addressable",
+		Labels: map[string]string{"duck.knative.dev/addressable": "true"},
+		schema: func() (spec, status apixv1.JSONSchemaProps) {
+			return object(nil), object(map[string]apixv1.JSONSchemaProps{
+				"address": object(map[string]apixv1.JSONSchemaProps{
+					"url": {Type: "string"},
+				}),
+			})
+		},
+		instance: func(obj *unstructured.Unstructured) error {
+			url := "http://" + network.GetServiceHostname(obj.GetName(), obj.GetNamespace())
+			return unstructured.SetNestedField(obj.Object, url, "status", "address", "url")
+		},
+	}
+
+	// PodSpecable is the PodSpecable duck type. The instances have a pod
+	// template running a single container.
+	PodSpecable = Type{
+		Kind:   "PodSpecable",
+		Labels: map[string]string{"duck.knative.dev/podspecable": "true"},
+		schema: func() (spec, status apixv1.JSONSchemaProps) {
+			return object(map[string]apixv1.JSONSchemaProps{
+				"template": preserveUnknownFields(),
+			}), object(nil)
+		},
+		instance: func(obj *unstructured.Unstructured) error {
+			return unstructured.SetNestedField(obj.Object, map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": obj.GetName()},
+				},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{
+						"name":  "user-container",
+						"image": "nginx:1.7.9",
+					}},
+				},
+			}, "spec", "template")
+		},
+	}
+)
+
+// CRD is a CRD installed by Install.
+type CRD struct {
+	// GVR is the resource of the CRD.
+	GVR schema.GroupVersionResource
+
+	// GVK is the kind of the CRD.
+	GVK schema.GroupVersionKind
+
+	duck Type
+}
+
+// Install installs a CRD implementing the given duck type with a random API
+// group, waits for it to be established, and registers its deletion as a
+// cleanup of the test.
+func Install(ctx context.Context, t T, client apixclient.Interface, duck Type) *CRD {
+	t.Helper()
+
+	group := strings.ToLower(helpers.RandomString()) + groupSuffix
+	plural := strings.ToLower(duck.Kind) + "s"
+	crd := &CRD{
+		GVR:  schema.GroupVersionResource{Group: group, Version: version, Resource: plural},
+		GVK:  schema.GroupVersionKind{Group: group, Version: version, Kind: duck.Kind},
+		duck: duck,
+	}
+
+	crds := client.ApiextensionsV1().CustomResourceDefinitions()
+	created, err := crds.Create(ctx, crd.definition(), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Failed to create CRD %s: %v", crd.GVR.GroupResource(), err))
+	}
+
+	cleanup := func() {
+		if err := crds.Delete(context.Background(), created.Name, metav1.DeleteOptions{}); err != nil {
+			t.Log("Failed to delete CRD", created.Name, ":", err)
+		}
+	}
+	t.Cleanup(cleanup)
+	test.OnInterrupt(cleanup)
+
+	if err := wait.PollImmediate(pollInterval, pollTimeout, func() (bool, error) {
+		got, err := crds.Get(ctx, created.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return established(got), nil
+	}); err != nil {
+		t.Fatal(fmt.Sprintf("CRD %s was never established: %v", created.Name, err))
+	}
+	return crd
+}
+
+// Instance returns the named instance of the CRD, with the fields of the
+// duck type populated.
+func (c *CRD) Instance(namespace, name string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(c.GVK)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	if err := c.duck.instance(obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Create creates the named instance of the CRD.
+func (c *CRD) Create(ctx context.Context, t T, client dynamic.Interface, namespace, name string) *unstructured.Unstructured {
+	t.Helper()
+
+	obj, err := c.Instance(namespace, name)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Failed to make %s %s/%s: %v", c.GVK.Kind, namespace, name, err))
+	}
+	created, err := client.Resource(c.GVR).Namespace(namespace).Create(ctx, obj, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Failed to create %s %s/%s: %v", c.GVK.Kind, namespace, name, err))
+	}
+	return created
+}
+
+// Reference returns the KReference to the given instance of the CRD.
+func (c *CRD) Reference(obj *unstructured.Unstructured) *duckv1.KReference {
+	return &duckv1.KReference{
+		APIVersion: c.GVK.GroupVersion().String(),
+		Kind:       c.GVK.Kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+func (c *CRD) definition() *apixv1.CustomResourceDefinition {
+	spec, status := c.duck.schema()
+	return &apixv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   c.GVR.GroupResource().String(),
+			Labels: c.duck.Labels,
+		},
+		Spec: apixv1.CustomResourceDefinitionSpec{
+			Group: c.GVR.Group,
+			Names: apixv1.CustomResourceDefinitionNames{
+				Kind:     c.GVK.Kind,
+				ListKind: c.GVK.Kind + "List",
+				Plural:   c.GVR.Resource,
+				Singular: strings.ToLower(c.GVK.Kind),
+			},
+			Scope: apixv1.NamespaceScoped,
+			Versions: []apixv1.CustomResourceDefinitionVersion{{
+				Name:    version,
+				Served:  true,
+				Storage: true,
+				Schema: &apixv1.CustomResourceValidation{
+					OpenAPIV3Schema: ptrTo(object(map[string]apixv1.JSONSchemaProps{
+						"spec":   spec,
+						"status": status,
+					})),
+				},
+			}},
+		},
+	}
+}
+
+func established(crd *apixv1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apixv1.Established {
+			return cond.Status == apixv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func object(properties map[string]apixv1.JSONSchemaProps) apixv1.JSONSchemaProps {
+	return apixv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: properties,
+	}
+}
+
+func preserveUnknownFields() apixv1.JSONSchemaProps {
+	return apixv1.JSONSchemaProps{
+		Type:                   "object",
+		XPreserveUnknownFields: ptr.Bool(true),
+	}
+}
+
+func ptrTo(props apixv1.JSONSchemaProps) *apixv1.JSONSchemaProps {
+	return &props
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package duckcrd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	fakeapixclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// newAPIExtensionsClient returns a client establishing the CRDs as they
+// are created.
+func newAPIExtensionsClient() *fakeapixclient.Clientset {
+	client := fakeapixclient.NewSimpleClientset()
+	client.PrependReactor("create", "customresourcedefinitions", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		crd := action.(clientgotesting.CreateAction).GetObject().(*apixv1.CustomResourceDefinition)
+		crd.Status.Conditions = []apixv1.CustomResourceDefinitionCondition{{
+			Type:   apixv1.Established,
+			Status: apixv1.ConditionTrue,
+		}}
+		return false, nil, nil
+	})
+	return client
+}
+
+func TestInstall(t *testing.T) {
+	tests := []struct {
+		duck  Type
+		check func(*testing.T, map[string]interface{})
+	}{{
+		duck: Addressable,
+		check: func(t *testing.T, obj map[string]interface{}) {
+			var addressable duckv1.AddressableType
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &addressable); err != nil {
+				t.Fatal("FromUnstructured() =", err)
+			}
+			want := "http://thing.ns.svc.cluster.local"
+			if got := addressable.Status.Address.URL.String(); got != want {
+				t.Errorf("status.address.url = %s, wanted %s", got, want)
+			}
+		},
+	}, {
+		duck: PodSpecable,
+		check: func(t *testing.T, obj map[string]interface{}) {
+			var podSpecable duckv1.WithPod
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &podSpecable); err != nil {
+				t.Fatal("FromUnstructured() =", err)
+			}
+			if got := len(podSpecable.Spec.Template.Spec.Containers); got != 1 {
+				t.Errorf("Containers = %d, wanted 1", got)
+			}
+		},
+	}}
+
+	for _, tc := range tests {
+		apixClient := newAPIExtensionsClient()
+		var crd *CRD
+
+		t.Run(tc.duck.Kind, func(t *testing.T) {
+			ctx := context.Background()
+			crd = Install(ctx, t, apixClient, tc.duck)
+
+			if !strings.HasSuffix(crd.GVR.Group, groupSuffix) || crd.GVR.Resource != strings.ToLower(tc.duck.Kind)+"s" {
+				t.Errorf("GVR = %v, wanted a random group", crd.GVR)
+			}
+			got, err := apixClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, crd.GVR.GroupResource().String(), metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("Get(CRD) =", err)
+			}
+			if diff := cmp.Diff(tc.duck.Labels, got.Labels); diff != "" {
+				t.Error("CRD labels (-want, +got) =", diff)
+			}
+
+			dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{crd.GVR: tc.duck.Kind + "List"})
+			obj := crd.Create(ctx, t, dynamicClient, "ns", "thing")
+			if obj.GroupVersionKind() != crd.GVK {
+				t.Errorf("GroupVersionKind() = %v, wanted %v", obj.GroupVersionKind(), crd.GVK)
+			}
+			tc.check(t, obj.Object)
+
+			want := &duckv1.KReference{
+				APIVersion: crd.GVK.GroupVersion().String(),
+				Kind:       tc.duck.Kind,
+				Namespace:  "ns",
+				Name:       "thing",
+			}
+			if diff := cmp.Diff(want, crd.Reference(obj)); diff != "" {
+				t.Error("Reference() (-want, +got) =", diff)
+			}
+		})
+
+		// The CRD is deleted as the test completes.
+		_, err := apixClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.Background(), crd.GVR.GroupResource().String(), metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("Get(CRD) = %v, wanted it to be deleted", err)
+		}
+	}
+}
@@ -40,11 +40,31 @@ import (
 // When OnChanged is called by the informer for a particular
 // GroupVersionKind, the provided callback is called with the "key"
 // of each object actively watching the changed object.
-func New(callback func(types.NamespacedName), lease time.Duration) Interface {
-	return &impl{
+//
+// The returned tracker also implements LeaseInterface.
+func New(callback func(types.NamespacedName), lease time.Duration, opts ...Option) Interface {
+	i := &impl{
 		leaseDuration: lease,
 		cb:            callback,
 	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Option configures the tracker returned by New.
+type Option func(*impl)
+
+// WithExpiryCallback registers a callback which is called with the key of
+// the tracking object and the tracked reference each time a lease lapses
+// without having been renewed, and is removed from the tracker. Expired
+// leases are removed as the tracker observes changes to the referenced
+// objects, or when PruneExpired is called.
+func WithExpiryCallback(cb func(types.NamespacedName, Reference)) Option {
+	return func(i *impl) {
+		i.expiryCb = cb
+	}
 }
 
 type impl struct {
@@ -57,14 +77,16 @@ type impl struct {
 	inexact map[Reference]matchers
 
 	// The amount of time that an object may watch another
-	// before having to renew the lease.
+	// before having to renew the lease, unless a different
+	// duration is passed to TrackReferenceWithLease.
 	leaseDuration time.Duration
 
-	cb func(types.NamespacedName)
+	cb       func(types.NamespacedName)
+	expiryCb func(types.NamespacedName, Reference)
 }
 
 // Check that impl implements Interface.
-var _ Interface = (*impl)(nil)
+var _ LeaseInterface = (*impl)(nil)
 
 // set is a map from keys to expirations
 type set map[types.NamespacedName]time.Time
@@ -77,6 +99,9 @@ type matcher struct {
 	// The selector to complete the match.
 	selector labels.Selector
 
+	// The reference as tracked, reported when the lease expires.
+	ref Reference
+
 	// When this lease expires.
 	expiry time.Time
 }
@@ -91,7 +116,13 @@ func (i *impl) Track(ref corev1.ObjectReference, obj interface{}) error {
 	}, obj)
 }
 
+// TrackReference implements Interface.
 func (i *impl) TrackReference(ref Reference, obj interface{}) error {
+	return i.TrackReferenceWithLease(ref, obj, i.leaseDuration)
+}
+
+// TrackReferenceWithLease implements LeaseInterface.
+func (i *impl) TrackReferenceWithLease(ref Reference, obj interface{}, lease time.Duration) error {
 	invalidFields := map[string][]string{
 		"APIVersion": validation.IsQualifiedName(ref.APIVersion),
 		"Kind":       validation.IsCIdentifier(ref.Kind),
@@ -170,8 +201,8 @@ func (i *impl) TrackReference(ref Reference, obj interface{}) error {
 			// registrations.
 			keys = append(keys, key)
 		}
-		// Overwrite the key with a new expiration.
-		l[key] = time.Now().Add(i.leaseDuration)
+		// Slide the expiration forward.
+		l[key] = renew(l[key], lease)
 
 		i.exact[ref] = l
 		return nil
@@ -189,7 +220,8 @@ func (i *impl) TrackReference(ref Reference, obj interface{}) error {
 		l = matchers{}
 	}
 
-	if m, ok := l[key]; !ok || isExpired(m.expiry) {
+	m, ok := l[key]
+	if !ok || isExpired(m.expiry) {
 		// When covering an uncovered key, immediately call the
 		// registered callback to ensure that the following pattern
 		// doesn't create problems:
@@ -203,10 +235,12 @@ func (i *impl) TrackReference(ref Reference, obj interface{}) error {
 		// registrations.
 		keys = append(keys, key)
 	}
-	// Overwrite the key with a new expiration.
+	// Overwrite the key with the new selector, sliding the expiration
+	// forward.
 	l[key] = matcher{
 		selector: selector,
-		expiry:   time.Now().Add(i.leaseDuration),
+		ref:      ref,
+		expiry:   renew(m.expiry, lease),
 	}
 
 	i.inexact[partialRef] = l
@@ -217,6 +251,80 @@ func isExpired(expiry time.Time) bool {
 	return time.Now().After(expiry)
 }
 
+// renew returns the expiration of a lease renewed for the given duration.
+// Renewing never shortens a lease which is still active, so that tracking a
+// reference with a short lease does not cut a longer one.
+func renew(expiry time.Time, lease time.Duration) time.Time {
+	if renewed := time.Now().Add(lease); renewed.After(expiry) {
+		return renewed
+	}
+	return expiry
+}
+
+// expiredLease is a lapsed lease, pending to be reported to the expiry
+// callback.
+type expiredLease struct {
+	key types.NamespacedName
+	ref Reference
+}
+
+// notifyExpired calls the expiry callback for each of the given leases. It
+// must be called without the lock held.
+func (i *impl) notifyExpired(expired []expiredLease) {
+	if i.expiryCb == nil {
+		return
+	}
+	for _, e := range expired {
+		i.expiryCb(e.key, e.ref)
+	}
+}
+
+// pruneExact removes the expired keys tracking ref, and returns them.
+// The lock must be held.
+func (i *impl) pruneExact(ref Reference, s set, expired []expiredLease) []expiredLease {
+	for key, expiry := range s {
+		if isExpired(expiry) {
+			delete(s, key)
+			expired = append(expired, expiredLease{key: key, ref: ref})
+		}
+	}
+	if len(s) == 0 {
+		delete(i.exact, ref)
+	}
+	return expired
+}
+
+// pruneInexact removes the expired keys tracking partialRef by selector, and
+// returns them. The lock must be held.
+func (i *impl) pruneInexact(partialRef Reference, ms matchers, expired []expiredLease) []expiredLease {
+	for key, m := range ms {
+		if isExpired(m.expiry) {
+			delete(ms, key)
+			expired = append(expired, expiredLease{key: key, ref: m.ref})
+		}
+	}
+	if len(ms) == 0 {
+		delete(i.inexact, partialRef)
+	}
+	return expired
+}
+
+// PruneExpired implements LeaseInterface.
+func (i *impl) PruneExpired() {
+	var expired []expiredLease
+	func() {
+		i.m.Lock()
+		defer i.m.Unlock()
+		for ref, s := range i.exact {
+			expired = i.pruneExact(ref, s, expired)
+		}
+		for ref, ms := range i.inexact {
+			expired = i.pruneInexact(ref, ms, expired)
+		}
+	}()
+	i.notifyExpired(expired)
+}
+
 // OnChanged implements Interface.
 func (i *impl) OnChanged(obj interface{}) {
 	observers := i.GetObservers(obj)
@@ -241,7 +349,14 @@ func (i *impl) GetObservers(obj interface{}) []types.NamespacedName {
 		Name:       or.Name,
 	}
 
-	var keys []types.NamespacedName
+	var (
+		keys    []types.NamespacedName
+		expired []expiredLease
+	)
+	// Call the expiry callback without the lock held.
+	defer func() {
+		i.notifyExpired(expired)
+	}()
 
 	i.m.Lock()
 	defer i.m.Unlock()
@@ -249,37 +364,25 @@ func (i *impl) GetObservers(obj interface{}) []types.NamespacedName {
 	// Handle exact matches.
 	s, ok := i.exact[ref]
 	if ok {
-		for key, expiry := range s {
-			// If the expiration has lapsed, then delete the key.
-			if isExpired(expiry) {
-				delete(s, key)
-				continue
-			}
+		// Remove the keys whose lease has lapsed.
+		expired = i.pruneExact(ref, s, expired)
+		for key := range s {
 			keys = append(keys, key)
 		}
-		if len(s) == 0 {
-			delete(i.exact, ref)
-		}
 	}
 
 	// Handle inexact matches.
 	ref.Name = ""
 	ms, ok := i.inexact[ref]
 	if ok {
+		// Remove the keys whose lease has lapsed.
+		expired = i.pruneInexact(ref, ms, expired)
 		ls := labels.Set(item.GetLabels())
 		for key, m := range ms {
-			// If the expiration has lapsed, then delete the key.
-			if isExpired(m.expiry) {
-				delete(ms, key)
-				continue
-			}
 			if m.selector.Matches(ls) {
 				keys = append(keys, key)
 			}
 		}
-		if len(s) == 0 {
-			delete(i.exact, ref)
-		}
 	}
 
 	return keys
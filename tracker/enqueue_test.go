@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -810,3 +811,163 @@ func TestHappyPathsByBoth(t *testing.T) {
 		}
 	}
 }
+
+func TestLeasePerReference(t *testing.T) {
+	trk := New(func(types.NamespacedName) {}, 10*time.Millisecond).(LeaseInterface)
+
+	thing1 := &Resource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "ref.knative.dev/v1alpha1",
+			Kind:       "Thing1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "foo",
+		},
+	}
+	or := kmeta.ObjectReference(thing1)
+	ref := Reference{
+		APIVersion: or.APIVersion,
+		Kind:       or.Kind,
+		Namespace:  or.Namespace,
+		Name:       or.Name,
+	}
+	thing2 := &Resource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "refer.knative.dev/v1alpha1",
+			Kind:       "Thing2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "bar",
+		},
+	}
+
+	if err := trk.TrackReferenceWithLease(ref, thing2, time.Hour); err != nil {
+		t.Fatal("TrackReferenceWithLease() =", err)
+	}
+	// Tracking with the shorter default lease doesn't shorten the lease.
+	if err := trk.TrackReference(ref, thing2); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+
+	// Outlives the default lease.
+	time.Sleep(50 * time.Millisecond)
+	if got, want := len(trk.GetObservers(thing1)), 1; got != want {
+		t.Fatalf("len(GetObservers()) = %v, wanted %v", got, want)
+	}
+}
+
+func TestSlidingRenewal(t *testing.T) {
+	trk := New(func(types.NamespacedName) {}, 50*time.Millisecond)
+
+	thing1 := &Resource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "ref.knative.dev/v1alpha1",
+			Kind:       "Thing1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "foo",
+			Labels:    map[string]string{"foo": "bar"},
+		},
+	}
+	ref := Reference{
+		APIVersion: "ref.knative.dev/v1alpha1",
+		Kind:       "Thing1",
+		Namespace:  "ns",
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"foo": "bar"},
+		},
+	}
+	thing2 := &Resource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "bar",
+		},
+	}
+
+	// Each renewal extends the lease from the time it happens, so the lease
+	// outlives its initial duration.
+	for i := 0; i < 4; i++ {
+		if err := trk.TrackReference(ref, thing2); err != nil {
+			t.Fatal("TrackReference() =", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got, want := len(trk.GetObservers(thing1)), 1; got != want {
+		t.Fatalf("len(GetObservers()) = %v, wanted %v", got, want)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if got, want := len(trk.GetObservers(thing1)), 0; got != want {
+		t.Fatalf("len(GetObservers()) = %v, wanted %v", got, want)
+	}
+}
+
+func TestExpiryCallback(t *testing.T) {
+	type expiry struct {
+		key types.NamespacedName
+		ref Reference
+	}
+	var got []expiry
+	trk := New(func(types.NamespacedName) {}, 10*time.Millisecond,
+		WithExpiryCallback(func(key types.NamespacedName, ref Reference) {
+			got = append(got, expiry{key: key, ref: ref})
+		})).(LeaseInterface)
+
+	exact := Reference{
+		APIVersion: "ref.knative.dev/v1alpha1",
+		Kind:       "Thing1",
+		Namespace:  "ns",
+		Name:       "foo",
+	}
+	inexact := Reference{
+		APIVersion: "ref.knative.dev/v1alpha1",
+		Kind:       "Thing1",
+		Namespace:  "ns",
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"foo": "bar"},
+		},
+	}
+	thing2 := &Resource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "bar",
+		},
+	}
+	key := types.NamespacedName{Namespace: "default", Name: "bar"}
+
+	if err := trk.TrackReference(exact, thing2); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+	if err := trk.TrackReference(inexact, thing2); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+
+	// Nothing has expired yet.
+	trk.PruneExpired()
+	if len(got) != 0 {
+		t.Fatalf("Expired leases = %v, wanted none", got)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	trk.PruneExpired()
+	if len(got) != 2 {
+		t.Fatalf("Expired leases = %v, wanted 2", got)
+	}
+	for _, e := range got {
+		if e.key != key {
+			t.Errorf("Expired key = %v, wanted %v", e.key, key)
+		}
+		if !cmp.Equal(e.ref, exact) && !cmp.Equal(e.ref, inexact) {
+			t.Errorf("Expired ref = %v, wanted one of %v, %v", e.ref, exact, inexact)
+		}
+	}
+
+	// Expired leases are only reported once.
+	trk.PruneExpired()
+	if len(got) != 2 {
+		t.Fatalf("Expired leases = %v, wanted 2", got)
+	}
+}
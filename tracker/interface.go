@@ -19,6 +19,7 @@ package tracker
 import (
 	"context"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -83,6 +84,23 @@ type Interface interface {
 	OnDeletedObserver(obj interface{})
 }
 
+// LeaseInterface extends Interface for trackers which support lease durations
+// per tracked reference.
+type LeaseInterface interface {
+	Interface
+
+	// TrackReferenceWithLease tells us that "obj" is tracking changes to
+	// the referenced object for the given lease duration, instead of the
+	// tracker's default one. Tracking the same reference again slides the
+	// lease forward, but never shortens an active lease.
+	TrackReferenceWithLease(ref Reference, obj interface{}, lease time.Duration) error
+
+	// PruneExpired removes the leases which have lapsed, calling the
+	// expiry callback for each of them. Expired leases are otherwise only
+	// removed as changes to the referenced objects are observed.
+	PruneExpired()
+}
+
 // GroupVersionKind returns the GroupVersion of the object referenced.
 func (ref *Reference) GroupVersionKind() schema.GroupVersionKind {
 	gv, _ := schema.ParseGroupVersion(ref.APIVersion)
@@ -103,6 +121,18 @@ func (ref *Reference) ObjectReference() corev1.ObjectReference {
 	}
 }
 
+// ReferenceFromObjectReference returns the tracker Reference of the
+// ObjectReference, the inverse of ObjectReference. The UID, ResourceVersion
+// and FieldPath of the ObjectReference are not tracked and are dropped.
+func ReferenceFromObjectReference(ref corev1.ObjectReference) Reference {
+	return Reference{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Namespace:  ref.Namespace,
+		Name:       ref.Name,
+	}
+}
+
 // ValidateObjectReference validates that the Reference uses a subset suitable for
 // translation to a corev1.ObjectReference.  This helper is intended to simplify
 // validating a particular (narrow) use of tracker.Reference.
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/apis"
@@ -45,6 +46,33 @@ func TestGroupVersionKind(t *testing.T) {
 	}
 }
 
+func TestReferenceFromObjectReference(t *testing.T) {
+	or := corev1.ObjectReference{
+		APIVersion:      "apps/v1",
+		Kind:            "Deployment",
+		Namespace:       "default",
+		Name:            "nginx",
+		UID:             "not-tracked",
+		ResourceVersion: "42",
+	}
+
+	ref := ReferenceFromObjectReference(or)
+	want := Reference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "default",
+		Name:       "nginx",
+	}
+	if !cmp.Equal(ref, want) {
+		t.Error("ReferenceFromObjectReference (-want, +got) =", cmp.Diff(want, ref))
+	}
+	// Round trips, but for the fields which are not tracked.
+	or.UID, or.ResourceVersion = "", ""
+	if got := ref.ObjectReference(); got != or {
+		t.Errorf("ObjectReference() = %v, wanted %v", got, or)
+	}
+}
+
 func TestValidateObjectReference(t *testing.T) {
 	tests := []struct {
 		name string
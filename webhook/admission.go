@@ -26,7 +26,7 @@ import (
 	"strings"
 	"time"
 
-	"go.uber.org/zap"
+	"go.opencensus.io/trace"
 	admissionv1 "k8s.io/api/admission/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"knative.dev/pkg/apis"
@@ -77,8 +77,11 @@ func MakeErrorStatus(reason string, args ...interface{}) *admissionv1.AdmissionR
 	}
 }
 
-func admissionHandler(rootLogger *zap.SugaredLogger, stats StatsReporter, c AdmissionController, synced <-chan struct{}) http.HandlerFunc {
+func admissionHandler(stats StatsReporter, audit AuditSink, c AdmissionController, synced <-chan struct{}) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		spanCtx, span := startAdmissionSpan(r, c.Path())
+		defer span.End()
+
 		if _, ok := c.(StatelessAdmissionController); ok {
 			// Stateless admission controllers do not require Informers to have
 			// finished syncing before Admit is called.
@@ -89,16 +92,21 @@ func admissionHandler(rootLogger *zap.SugaredLogger, stats StatsReporter, c Admi
 		}
 
 		var ttStart = time.Now()
-		logger := rootLogger
+		logger := logging.FromContext(r.Context())
 		logger.Infof("Webhook ServeHTTP request=%#v", r)
 
 		var review admissionv1.AdmissionReview
 		bodyBuffer := bytes.Buffer{}
-		if err := json.NewDecoder(io.TeeReader(r.Body, &bodyBuffer)).Decode(&review); err != nil {
+		_, decodeSpan := trace.StartSpan(spanCtx, decodeSpanName)
+		err := json.NewDecoder(io.TeeReader(r.Body, &bodyBuffer)).Decode(&review)
+		endSpan(decodeSpan, trace.StatusCodeInvalidArgument, err)
+		if err != nil {
+			span.SetStatus(trace.Status{Code: trace.StatusCodeInvalidArgument, Message: err.Error()})
 			http.Error(w, fmt.Sprint("could not decode body:", err), http.StatusBadRequest)
 			return
 		}
 		r.Body = io.NopCloser(&bodyBuffer)
+		addRequestAttributes(span, review.Request)
 
 		logger = logger.With(
 			logkey.Kind, review.Request.Kind.String(),
@@ -110,8 +118,9 @@ func admissionHandler(rootLogger *zap.SugaredLogger, stats StatsReporter, c Admi
 			logkey.UserInfo, review.Request.UserInfo.Username,
 		)
 
-		ctx := logging.WithLogger(r.Context(), logger)
+		ctx := logging.WithLogger(spanCtx, logger)
 		ctx = apis.WithHTTPRequest(ctx, r)
+		ctx = WithWarnings(ctx)
 
 		response := admissionv1.AdmissionReview{
 			// Use the same type meta as the request - this is required by the K8s API
@@ -120,7 +129,14 @@ func admissionHandler(rootLogger *zap.SugaredLogger, stats StatsReporter, c Admi
 			TypeMeta: review.TypeMeta,
 		}
 
-		reviewResponse := c.Admit(ctx, review.Request)
+		admitCtx, admitSpan := trace.StartSpan(ctx, callbackSpanName)
+		reviewResponse := c.Admit(admitCtx, review.Request)
+		if admitSpan.IsRecordingEvents() {
+			admitSpan.AddAttributes(trace.BoolAttribute("allowed", reviewResponse.Allowed))
+		}
+		admitSpan.End()
+		// Attach the warnings added through AddWarning, e.g. by callbacks.
+		reviewResponse.Warnings = append(reviewResponse.Warnings, GetWarnings(ctx)...)
 		var patchType string
 		if reviewResponse.PatchType != nil {
 			patchType = string(*reviewResponse.PatchType)
@@ -151,7 +167,14 @@ func admissionHandler(rootLogger *zap.SugaredLogger, stats StatsReporter, c Admi
 		logger.Infof("remote admission controller audit annotations=%#v", reviewResponse.AuditAnnotations)
 		logger.Debugf("AdmissionReview patch={ type: %s, body: %s }", patchType, string(reviewResponse.Patch))
 
-		if err := json.NewEncoder(w).Encode(response); err != nil {
+		if audit != nil {
+			audit.Record(ctx, newAuditRecord(review.Request, reviewResponse))
+		}
+
+		_, writeSpan := trace.StartSpan(ctx, writeSpanName)
+		err = json.NewEncoder(w).Encode(response)
+		endSpan(writeSpan, trace.StatusCodeInternal, err)
+		if err != nil {
 			http.Error(w, fmt.Sprint("could not encode response:", err), http.StatusInternalServerError)
 			return
 		}
@@ -36,6 +36,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	kubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics/metricstest"
 	_ "knative.dev/pkg/metrics/testing"
 )
@@ -43,6 +44,8 @@ import (
 type fixedAdmissionController struct {
 	path     string
 	response *admissionv1.AdmissionResponse
+	// warnings are added with AddWarning when admitting.
+	warnings []string
 }
 
 var _ AdmissionController = (*fixedAdmissionController)(nil)
@@ -58,6 +61,9 @@ func (fac *fixedAdmissionController) Admit(ctx context.Context, req *admissionv1
 	} else if r.URL.Path != fac.path {
 		panic("wrong path!")
 	}
+	for _, w := range fac.warnings {
+		AddWarning(ctx, w)
+	}
 	return fac.response
 }
 
@@ -285,6 +291,7 @@ func TestAdmissionValidResponseForResource(t *testing.T) {
 		t.Fatal("http.NewRequest() =", err)
 	}
 	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add(logging.RequestIDHeader, "bazinga-request")
 
 	doneCh := make(chan struct{})
 	launchedCh := make(chan struct{})
@@ -302,6 +309,9 @@ func TestAdmissionValidResponseForResource(t *testing.T) {
 			t.Errorf("Response status code = %v, wanted %v", got, want)
 			return
 		}
+		if got, want := response.Header.Get(logging.RequestIDHeader), "bazinga-request"; got != want {
+			t.Errorf("Response header %s = %q, wanted %q", logging.RequestIDHeader, got, want)
+		}
 
 		defer response.Body.Close()
 		responseBody, err := io.ReadAll(response.Body)
@@ -464,11 +474,12 @@ func TestAdmissionInvalidResponseForResource(t *testing.T) {
 
 func TestAdmissionWarningResponseForResource(t *testing.T) {
 	// Test that our single warning below (with newlines) should be turned into
-	// these three warnings
-	expectedWarnings := []string{"everything is not fine.", "like really", "for sure"}
+	// these three warnings, followed by the one added through AddWarning
+	expectedWarnings := []string{"everything is not fine.", "like really", "for sure", "added by a callback"}
 	ac := &fixedAdmissionController{
 		path:     "/warnmeplease",
 		response: &admissionv1.AdmissionResponse{Warnings: []string{"everything is not fine.\nlike really\nfor sure"}},
+		warnings: []string{"added by a callback"},
 	}
 	wh, serverURL, ctx, cancel, err := testSetup(t, ac)
 	if err != nil {
@@ -561,8 +572,8 @@ func TestAdmissionWarningResponseForResource(t *testing.T) {
 	}
 
 	warnings := reviewResponse.Response.Warnings
-	if len(warnings) != 3 {
-		t.Errorf("Received unexpected warnings, wanted 3 got: %s", reviewResponse.Response.Warnings)
+	if len(warnings) != len(expectedWarnings) {
+		t.Errorf("Received unexpected warnings, wanted %d got: %s", len(expectedWarnings), reviewResponse.Response.Warnings)
 	}
 	for i, w := range warnings {
 		if expectedWarnings[i] != w {
@@ -0,0 +1,320 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/logging"
+)
+
+// AuditRecord is the record of an admission request and of the decision
+// taken on it.
+type AuditRecord struct {
+	// Time is when the decision was taken.
+	Time time.Time `json:"time"`
+
+	// UID is the UID of the admission request.
+	UID types.UID `json:"uid"`
+
+	// UserInfo is who made the request.
+	UserInfo authenticationv1.UserInfo `json:"userInfo"`
+
+	// Kind, Namespace, Name and SubResource identify the object.
+	Kind        metav1.GroupVersionKind `json:"kind"`
+	Namespace   string                  `json:"namespace,omitempty"`
+	Name        string                  `json:"name,omitempty"`
+	SubResource string                  `json:"subResource,omitempty"`
+
+	// Operation is the operation being performed.
+	Operation admissionv1.Operation `json:"operation"`
+
+	// DryRun is whether the request was a dry run.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Allowed is the decision on the request, and Code and Message the
+	// reason of the rejections.
+	Allowed bool   `json:"allowed"`
+	Code    int32  `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Patch summarizes the patch of the response as the operations and
+	// paths it holds, e.g. "add /spec/replicas", leaving out their values
+	// which may be sensitive.
+	Patch []string `json:"patch,omitempty"`
+}
+
+// AuditSink keeps the records of the admission requests, e.g. to comply
+// with audit requirements. Record is called for every admission request
+// once it is decided, so it must not block.
+type AuditSink interface {
+	Record(ctx context.Context, record *AuditRecord)
+}
+
+// newAuditRecord returns the record of the given request and response.
+func newAuditRecord(req *admissionv1.AdmissionRequest, resp *admissionv1.AdmissionResponse) *AuditRecord {
+	record := &AuditRecord{
+		Time:        time.Now(),
+		UID:         req.UID,
+		UserInfo:    req.UserInfo,
+		Kind:        req.Kind,
+		Namespace:   req.Namespace,
+		Name:        req.Name,
+		SubResource: req.SubResource,
+		Operation:   req.Operation,
+		DryRun:      req.DryRun != nil && *req.DryRun,
+		Allowed:     resp.Allowed,
+	}
+	if resp.Result != nil {
+		record.Code = resp.Result.Code
+		record.Message = resp.Result.Message
+	}
+	if len(resp.Patch) != 0 {
+		var patch []jsonpatch.JsonPatchOperation
+		if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+			record.Patch = []string{fmt.Sprint("unparsable patch: ", err)}
+		}
+		for _, op := range patch {
+			record.Patch = append(record.Patch, op.Operation+" "+op.Path)
+		}
+	}
+	return record
+}
+
+// ZapAuditSink writes the audit records to a logger, e.g. to rely on the
+// log collection of the cluster.
+type ZapAuditSink struct {
+	Logger *zap.Logger
+}
+
+var _ AuditSink = (*ZapAuditSink)(nil)
+
+// Record implements AuditSink.
+func (s *ZapAuditSink) Record(_ context.Context, r *AuditRecord) {
+	s.Logger.Info("Admission request audit",
+		zap.Time("time", r.Time),
+		zap.String("uid", string(r.UID)),
+		zap.String("user", r.UserInfo.Username),
+		zap.Strings("groups", r.UserInfo.Groups),
+		zap.String("kind", r.Kind.String()),
+		zap.String("namespace", r.Namespace),
+		zap.String("name", r.Name),
+		zap.String("subResource", r.SubResource),
+		zap.String("operation", string(r.Operation)),
+		zap.Bool("dryRun", r.DryRun),
+		zap.Bool("allowed", r.Allowed),
+		zap.Int32("code", r.Code),
+		zap.String("message", r.Message),
+		zap.Strings("patch", r.Patch))
+}
+
+const (
+	// DefaultAuditBatchSize is the default number of records sent at once
+	// by the HTTPAuditSink.
+	DefaultAuditBatchSize = 100
+
+	// DefaultAuditFlushInterval is the default period after which the
+	// HTTPAuditSink sends the records it holds, even short of a batch.
+	DefaultAuditFlushInterval = 5 * time.Second
+
+	// DefaultAuditMaxBuffered is the default number of records held by the
+	// HTTPAuditSink while its endpoint fails, beyond which the oldest ones
+	// are dropped.
+	DefaultAuditMaxBuffered = 10000
+)
+
+// HTTPAuditSink sends the audit records in batches, as JSON arrays POSTed
+// to an endpoint, retrying the failed batches on the next flush. It only
+// sends them while running, see Run.
+type HTTPAuditSink struct {
+	// URL is the endpoint the batches are POSTed to.
+	URL string
+
+	// Client sends the batches. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// BatchSize is the number of records sent at once, and the number of
+	// records triggering a flush. Defaults to DefaultAuditBatchSize.
+	BatchSize int
+
+	// FlushInterval is the period between two flushes.
+	// Defaults to DefaultAuditFlushInterval.
+	FlushInterval time.Duration
+
+	// MaxBuffered is the number of records held while the endpoint fails.
+	// Defaults to DefaultAuditMaxBuffered.
+	MaxBuffered int
+
+	mu      sync.Mutex
+	records []*AuditRecord
+	dropped int
+	flushCh chan struct{}
+	once    sync.Once
+}
+
+var _ AuditSink = (*HTTPAuditSink)(nil)
+
+func (s *HTTPAuditSink) init() {
+	s.once.Do(func() {
+		s.flushCh = make(chan struct{}, 1)
+	})
+}
+
+func (s *HTTPAuditSink) batchSize() int {
+	if s.BatchSize <= 0 {
+		return DefaultAuditBatchSize
+	}
+	return s.BatchSize
+}
+
+// Record implements AuditSink.
+func (s *HTTPAuditSink) Record(_ context.Context, r *AuditRecord) {
+	s.init()
+	maxBuffered := s.MaxBuffered
+	if maxBuffered <= 0 {
+		maxBuffered = DefaultAuditMaxBuffered
+	}
+
+	s.mu.Lock()
+	s.records = append(s.records, r)
+	if over := len(s.records) - maxBuffered; over > 0 {
+		s.records = s.records[over:]
+		s.dropped += over
+	}
+	full := len(s.records) >= s.batchSize()
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Dropped returns the number of records dropped so far because the endpoint
+// was failing.
+func (s *HTTPAuditSink) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Run sends the records every FlushInterval, or as soon as a batch is full,
+// until the context is cancelled, after which the records left are sent a
+// last time.
+func (s *HTTPAuditSink) Run(ctx context.Context) {
+	s.init()
+	interval := s.FlushInterval
+	if interval <= 0 {
+		interval = DefaultAuditFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := logging.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			// Send the records left on a context that is still alive.
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			defer cancel()
+			if err := s.Flush(ctx); err != nil {
+				logger.Errorw("Failed to send the last audit records", zap.Error(err))
+			}
+			return
+		case <-ticker.C:
+		case <-s.flushCh:
+		}
+		if err := s.Flush(ctx); err != nil {
+			logger.Warnw("Failed to send audit records", zap.Error(err))
+		}
+	}
+}
+
+// Flush sends the records held, batch by batch, stopping at the first
+// failure, whose records are kept to be retried.
+func (s *HTTPAuditSink) Flush(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		n := len(s.records)
+		if n > s.batchSize() {
+			n = s.batchSize()
+		}
+		batch := s.records[:n:n]
+		dropped := s.dropped
+		s.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := s.send(ctx, batch); err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		// The oldest records, i.e. those of the batch, may have been
+		// dropped while sending.
+		if left := len(batch) - (s.dropped - dropped); left > 0 {
+			s.records = s.records[left:]
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *HTTPAuditSink) send(ctx context.Context, batch []*AuditRecord) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body for the connection to be reused.
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit endpoint responded %s", resp.Status)
+	}
+	return nil
+}
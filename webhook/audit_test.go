@@ -0,0 +1,250 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"knative.dev/pkg/ptr"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+type recordingAuditSink struct {
+	mu      sync.Mutex
+	records []*AuditRecord
+}
+
+func (s *recordingAuditSink) Record(_ context.Context, r *AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, r)
+}
+
+func TestAdmissionHandlerAudit(t *testing.T) {
+	patchType := admissionv1.PatchTypeJSONPatch
+	c := &fixedAdmissionController{
+		path: "/admit",
+		response: &admissionv1.AdmissionResponse{
+			Allowed:   true,
+			PatchType: &patchType,
+			Patch:     []byte(`[{"op":"add","path":"/spec/replicas","value":1},{"op":"remove","path":"/spec/secret"}]`),
+		},
+	}
+	sink := &recordingAuditSink{}
+	synced := make(chan struct{})
+	close(synced)
+
+	review := admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "1234",
+			Kind:      metav1.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1", Kind: "Resource"},
+			Namespace: "ns",
+			Name:      "foo",
+			Operation: admissionv1.Create,
+			UserInfo:  authenticationv1.UserInfo{Username: "alice", Groups: []string{"devs"}},
+			DryRun:    ptr.Bool(true),
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal("Marshal() =", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/admit", bytes.NewReader(body))
+	req = req.WithContext(TestContextWithLogger(t))
+	admissionHandler(nil, sink, c, synced).ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []*AuditRecord{{
+		UID:       "1234",
+		UserInfo:  authenticationv1.UserInfo{Username: "alice", Groups: []string{"devs"}},
+		Kind:      metav1.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1", Kind: "Resource"},
+		Namespace: "ns",
+		Name:      "foo",
+		Operation: admissionv1.Create,
+		DryRun:    true,
+		Allowed:   true,
+		Patch:     []string{"add /spec/replicas", "remove /spec/secret"},
+	}}
+	if !cmp.Equal(sink.records, want, cmpopts.IgnoreFields(AuditRecord{}, "Time")) {
+		t.Error("Records (-want, +got):", cmp.Diff(want, sink.records, cmpopts.IgnoreFields(AuditRecord{}, "Time")))
+	}
+}
+
+func TestNewAuditRecordRejection(t *testing.T) {
+	resp := MakeErrorStatus("validation failed: %s", "nope")
+	got := newAuditRecord(&admissionv1.AdmissionRequest{Operation: admissionv1.Update}, resp)
+	if got.Allowed || got.Code != http.StatusBadRequest || got.Message != "validation failed: nope" {
+		t.Errorf("newAuditRecord() = %+v, wanted a rejection with its reason", got)
+	}
+}
+
+func TestZapAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zap.InfoLevel)
+	sink := &ZapAuditSink{Logger: zap.New(core)}
+	sink.Record(context.Background(), &AuditRecord{
+		UID:       "1234",
+		UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+		Operation: admissionv1.Delete,
+		Allowed:   true,
+	})
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		t.Fatalf("Unmarshal(%s) = %v", buf.String(), err)
+	}
+	for k, want := range map[string]interface{}{
+		"uid":       "1234",
+		"user":      "alice",
+		"operation": "DELETE",
+		"allowed":   true,
+	} {
+		if got := fields[k]; got != want {
+			t.Errorf("Field %s = %v, wanted %v", k, got, want)
+		}
+	}
+}
+
+// auditEndpoint records the batches it receives, failing while failing is
+// set.
+type auditEndpoint struct {
+	mu      sync.Mutex
+	failing bool
+	batches [][]*AuditRecord
+}
+
+func (e *auditEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failing {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var batch []*AuditRecord
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	e.batches = append(e.batches, batch)
+}
+
+func (e *auditEndpoint) uids() [][]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	uids := make([][]string, 0, len(e.batches))
+	for _, b := range e.batches {
+		var u []string
+		for _, r := range b {
+			u = append(u, string(r.UID))
+		}
+		uids = append(uids, u)
+	}
+	return uids
+}
+
+func record(sink AuditSink, uids ...int) {
+	for _, uid := range uids {
+		sink.Record(context.Background(), &AuditRecord{UID: types.UID(fmt.Sprint(uid))})
+	}
+}
+
+func TestHTTPAuditSinkFlush(t *testing.T) {
+	endpoint := &auditEndpoint{failing: true}
+	s := httptest.NewServer(endpoint)
+	defer s.Close()
+
+	sink := &HTTPAuditSink{URL: s.URL, BatchSize: 2, MaxBuffered: 4}
+	record(sink, 1, 2, 3)
+	if err := sink.Flush(context.Background()); err == nil {
+		t.Error("Flush() = nil, wanted an error while the endpoint fails")
+	}
+
+	// The oldest records are dropped beyond MaxBuffered.
+	record(sink, 4, 5)
+	if got, want := sink.Dropped(), 1; got != want {
+		t.Errorf("Dropped() = %d, wanted %d", got, want)
+	}
+
+	endpoint.mu.Lock()
+	endpoint.failing = false
+	endpoint.mu.Unlock()
+	if err := sink.Flush(context.Background()); err != nil {
+		t.Fatal("Flush() =", err)
+	}
+	want := [][]string{{"2", "3"}, {"4", "5"}}
+	if got := endpoint.uids(); !cmp.Equal(got, want) {
+		t.Error("Batches (-want, +got):", cmp.Diff(want, got))
+	}
+
+	// Nothing is left to send.
+	if err := sink.Flush(context.Background()); err != nil {
+		t.Fatal("Flush() =", err)
+	}
+	if got := endpoint.uids(); len(got) != len(want) {
+		t.Errorf("Got %d batches, wanted %d", len(got), len(want))
+	}
+}
+
+func TestHTTPAuditSinkRun(t *testing.T) {
+	endpoint := &auditEndpoint{}
+	s := httptest.NewServer(endpoint)
+	defer s.Close()
+
+	// A long interval, for the flushes to be triggered by full batches and
+	// by the shutdown.
+	sink := &HTTPAuditSink{URL: s.URL, BatchSize: 2, FlushInterval: time.Hour}
+	ctx, cancel := context.WithCancel(TestContextWithLogger(t))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sink.Run(ctx)
+	}()
+
+	record(sink, 1, 2)
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(endpoint.uids()) == 1, nil
+	}); err != nil {
+		t.Fatal("Timed out waiting for the full batch to be sent:", err)
+	}
+
+	record(sink, 3)
+	cancel()
+	<-done
+
+	want := [][]string{{"1", "2"}, {"3"}}
+	if got := endpoint.uids(); !cmp.Equal(got, want) {
+		t.Error("Batches (-want, +got):", cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+)
+
+// certificateCache holds the webhook serving certificate, parsed from the
+// serving secret whenever the secret informer observes a change. This lets
+// the certificate be rotated externally (e.g. by cert-manager) and picked up
+// on the next handshake, without parsing the key pair on every handshake.
+type certificateCache struct {
+	logger *zap.SugaredLogger
+
+	keyName, certName, ocspName string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	// resourceVersion is the version of the secret cert was parsed from.
+	resourceVersion string
+}
+
+func newCertificateCache(logger *zap.SugaredLogger, opts *Options) *certificateCache {
+	keyName, certName, _ := opts.SecretDataKeys()
+	return &certificateCache{
+		logger:   logger,
+		keyName:  keyName,
+		certName: certName,
+		ocspName: getOCSPStapleNameOrDefault(opts.ServerOCSPStapleName),
+	}
+}
+
+// handler returns the event handler keeping the cache in sync with the
+// named secret.
+func (c *certificateCache) handler(namespace, name string) cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterWithNameAndNamespace(namespace, name),
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: c.update,
+			UpdateFunc: func(_, obj interface{}) {
+				c.update(obj)
+			},
+			DeleteFunc: func(interface{}) {
+				c.logger.Warn("Webhook serving certificate secret was deleted")
+				c.set(nil, "")
+			},
+		},
+	}
+}
+
+func (c *certificateCache) update(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	c.mu.RLock()
+	unchanged := c.cert != nil && c.resourceVersion == secret.ResourceVersion
+	c.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	cert, err := c.parse(secret)
+	if err != nil {
+		// Keep serving the previous certificate, if any, rather than
+		// failing every handshake on a bad rotation.
+		c.logger.Errorw("Failed to parse webhook serving certificate", zap.Error(err))
+		return
+	}
+	c.set(cert, secret.ResourceVersion)
+}
+
+func (c *certificateCache) parse(secret *corev1.Secret) (*tls.Certificate, error) {
+	serverKey, ok := secret.Data[c.keyName]
+	if !ok {
+		c.logger.Warn("server key missing")
+		return nil, nil
+	}
+	serverCert, ok := secret.Data[c.certName]
+	if !ok {
+		c.logger.Warn("server cert missing")
+		return nil, nil
+	}
+	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		return nil, err
+	}
+	if staple, ok := secret.Data[c.ocspName]; ok && len(staple) > 0 {
+		cert.OCSPStaple = staple
+	}
+	return &cert, nil
+}
+
+func (c *certificateCache) set(cert *tls.Certificate, resourceVersion string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cert != nil && c.cert != nil {
+		c.logger.Infow("Webhook serving certificate was rotated", zap.String("expiration", expiration(cert)))
+	}
+	c.cert = cert
+	c.resourceVersion = resourceVersion
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+// If we return (nil, error) the client sees - 'tls: internal error"
+// If we return (nil, nil) the client sees - 'tls: no certificates configured'
+//
+// We'll return (nil, nil) when we don't have a certificate.
+func (c *certificateCache) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+func expiration(cert *tls.Certificate) string {
+	if len(cert.Certificate) == 0 {
+		return ""
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return ""
+	}
+	return parsed.NotAfter.String()
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func servingSecret(t *testing.T, resourceVersion string, staple []byte) *corev1.Secret {
+	t.Helper()
+	key, cert, _, err := certresources.CreateCerts(context.Background(), "webhook", system.Namespace(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal("CreateCerts() =", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "webhook-certs",
+			Namespace:       system.Namespace(),
+			ResourceVersion: resourceVersion,
+		},
+		Data: map[string][]byte{
+			certresources.ServerKey:  key,
+			certresources.ServerCert: cert,
+		},
+	}
+	if staple != nil {
+		secret.Data[certresources.OCSPStaple] = staple
+	}
+	return secret
+}
+
+func TestCertificateCache(t *testing.T) {
+	opts := newDefaultOptions()
+	c := newCertificateCache(TestLogger(t), &opts)
+	handler := c.handler(system.Namespace(), opts.SecretName)
+
+	if cert, err := c.GetCertificate(nil); cert != nil || err != nil {
+		t.Fatalf("GetCertificate() = %v, %v, want: nil, nil", cert, err)
+	}
+
+	handler.OnAdd(servingSecret(t, "1", nil))
+	first, err := c.GetCertificate(nil)
+	if err != nil || first == nil {
+		t.Fatalf("GetCertificate() = %v, %v, want a certificate", first, err)
+	}
+	if first.OCSPStaple != nil {
+		t.Errorf("OCSPStaple = %v, want: nil", first.OCSPStaple)
+	}
+
+	// Other secrets are ignored.
+	other := servingSecret(t, "2", nil)
+	other.Name = "other"
+	handler.OnUpdate(nil, other)
+	if got, _ := c.GetCertificate(nil); got != first {
+		t.Error("GetCertificate() changed after an unrelated secret was updated")
+	}
+
+	// A rotation is picked up, along with its OCSP staple.
+	staple := []byte("staple")
+	handler.OnUpdate(nil, servingSecret(t, "3", staple))
+	rotated, _ := c.GetCertificate(nil)
+	if rotated == first {
+		t.Fatal("GetCertificate() did not change after the secret was rotated")
+	}
+	if !bytes.Equal(rotated.OCSPStaple, staple) {
+		t.Errorf("OCSPStaple = %q, want: %q", rotated.OCSPStaple, staple)
+	}
+
+	// A malformed rotation keeps the last good certificate.
+	bad := servingSecret(t, "4", nil)
+	bad.Data[certresources.ServerKey] = []byte("garbage")
+	handler.OnUpdate(nil, bad)
+	if got, _ := c.GetCertificate(nil); got != rotated {
+		t.Error("GetCertificate() changed after a malformed rotation")
+	}
+
+	handler.OnDelete(bad)
+	if cert, _ := c.GetCertificate(nil); cert != nil {
+		t.Errorf("GetCertificate() = %v after the secret was deleted, want: nil", cert)
+	}
+}
+
+func TestCABundleSecretName(t *testing.T) {
+	opts := newDefaultOptions()
+	if got, want := opts.CABundleSecretName(), opts.SecretName; got != want {
+		t.Errorf("CABundleSecretName() = %q, want: %q", got, want)
+	}
+	opts.CASecretName = "webhook-ca"
+	if got, want := opts.CABundleSecretName(), "webhook-ca"; got != want {
+		t.Errorf("CABundleSecretName() = %q, want: %q", got, want)
+	}
+}
+
+type fakeProvider struct {
+	CertificateProvider
+}
+
+func (fakeProvider) SecretDataKeys() (string, string, string) {
+	return "tls.key", "tls.crt", "ca.crt"
+}
+
+func TestSecretDataKeys(t *testing.T) {
+	tests := []struct {
+		name                          string
+		opts                          Options
+		wantKey, wantCert, wantCACert string
+	}{{
+		name:       "defaults",
+		wantKey:    certresources.ServerKey,
+		wantCert:   certresources.ServerCert,
+		wantCACert: certresources.CACert,
+	}, {
+		name:       "provider",
+		opts:       Options{CertificateProvider: fakeProvider{}},
+		wantKey:    "tls.key",
+		wantCert:   "tls.crt",
+		wantCACert: "ca.crt",
+	}, {
+		name: "options override the provider",
+		opts: Options{
+			CertificateProvider:   fakeProvider{},
+			ServerPrivateKeyName:  "key.pem",
+			ServerCertificateName: "cert.pem",
+			CACertificateName:     "ca.pem",
+		},
+		wantKey:    "key.pem",
+		wantCert:   "cert.pem",
+		wantCACert: "ca.pem",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			key, cert, caCert := tc.opts.SecretDataKeys()
+			if key != tc.wantKey || cert != tc.wantCert || caCert != tc.wantCACert {
+				t.Errorf("SecretDataKeys() = (%q, %q, %q), want: (%q, %q, %q)",
+					key, cert, caCert, tc.wantKey, tc.wantCert, tc.wantCACert)
+			}
+			if got := tc.opts.CABundleKey(); got != tc.wantCACert {
+				t.Errorf("CABundleKey() = %q, want: %q", got, tc.wantCACert)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+)
+
+// CertificateProvider provisions the certificates of the webhook: the
+// serving key pair and the CA certificate stored in the secret named by
+// Options.SecretName. When no provider is set in the Options, the
+// certificates controller generates self-signed certificates.
+type CertificateProvider interface {
+	// SecretDataKeys returns the names of the secret's data keys under
+	// which the provider stores the serving private key, the serving
+	// certificate and the CA certificate. The names set in the Options
+	// take precedence over these.
+	SecretDataKeys() (serverKey, serverCert, caCert string)
+
+	// NewController returns the controller provisioning the certificates.
+	NewController(ctx context.Context, cmw configmap.Watcher) *controller.Impl
+}
@@ -17,12 +17,16 @@ limitations under the License.
 package certificates
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -46,6 +50,21 @@ type reconciler struct {
 	secretlister corelisters.SecretLister
 	key          types.NamespacedName
 	serviceName  string
+
+	// external is set when the CA and serving certs are kept in separate
+	// secrets, in which case they are rotated by another system and must
+	// not be overwritten.
+	external bool
+
+	// duration is the lifetime of the generated certificates, the default
+	// one of certresources.MakeSecret if zero.
+	duration time.Duration
+	// renewBefore is how long before they expire certificates are renewed,
+	// one day if zero.
+	renewBefore time.Duration
+	// signingCASecretName is the name of the secret holding the CA signing
+	// the serving certificates, if any.
+	signingCASecretName string
 }
 
 var _ controller.Reconciler = (*reconciler)(nil)
@@ -63,6 +82,11 @@ func (r *reconciler) Reconcile(ctx context.Context, key string) error {
 func (r *reconciler) reconcileCertificate(ctx context.Context) error {
 	logger := logging.FromContext(ctx)
 
+	if r.external {
+		logger.Debugf("Certificate secret %q is managed externally", r.key.Name)
+		return nil
+	}
+
 	secret, err := r.secretlister.Secrets(r.key.Namespace).Get(r.key.Name)
 	if apierrors.IsNotFound(err) {
 		// The secret should be created explicitly by a higher-level system
@@ -74,12 +98,27 @@ func (r *reconciler) reconcileCertificate(ctx context.Context) error {
 		return err
 	}
 
+	renewBefore := r.renewBefore
+	if renewBefore == 0 {
+		renewBefore = oneDay
+	}
+
+	var ca *corev1.Secret
+	if r.signingCASecretName != "" {
+		ca, err = r.secretlister.Secrets(r.key.Namespace).Get(r.signingCASecretName)
+		if err != nil {
+			return fmt.Errorf("failed to get the signing CA secret %q: %w", r.signingCASecretName, err)
+		}
+	}
+
 	if _, haskey := secret.Data[certresources.ServerKey]; !haskey {
 		logger.Infof("Certificate secret %q is missing key %q", r.key.Name, certresources.ServerKey)
 	} else if _, haskey := secret.Data[certresources.ServerCert]; !haskey {
 		logger.Infof("Certificate secret %q is missing key %q", r.key.Name, certresources.ServerCert)
 	} else if _, haskey := secret.Data[certresources.CACert]; !haskey {
 		logger.Infof("Certificate secret %q is missing key %q", r.key.Name, certresources.CACert)
+	} else if ca != nil && !bytes.Equal(secret.Data[certresources.CACert], ca.Data[corev1.TLSCertKey]) {
+		logger.Infof("Certificate secret %q is not signed by the CA of %q", r.key.Name, r.signingCASecretName)
 	} else {
 		// Check the expiration date of the certificate to see if it needs to be updated
 		cert, err := tls.X509KeyPair(secret.Data[certresources.ServerCert], secret.Data[certresources.ServerKey])
@@ -89,7 +128,11 @@ func (r *reconciler) reconcileCertificate(ctx context.Context) error {
 			certData, err := x509.ParseCertificate(cert.Certificate[0])
 			if err != nil {
 				logger.Errorw("Error parsing certificate", zap.Error(err))
-			} else if time.Now().Add(oneDay).Before(certData.NotAfter) {
+			} else if time.Now().Add(renewBefore).Before(certData.NotAfter) {
+				return nil
+			} else if ca != nil && !certData.NotAfter.Before(caNotAfter(ca)) {
+				// Renewing would not extend the certificate past the CA.
+				logger.Warnf("Certificate secret %q expires with the CA of %q, which must be renewed", r.key.Name, r.signingCASecretName)
 				return nil
 			}
 		}
@@ -98,11 +141,66 @@ func (r *reconciler) reconcileCertificate(ctx context.Context) error {
 	secret = secret.DeepCopy()
 
 	// One of the secret's keys is missing, so synthesize a new one and update the secret.
-	newSecret, err := certresources.MakeSecret(ctx, r.key.Name, r.key.Namespace, r.serviceName)
+	data, err := r.makeCertificates(ctx, ca)
 	if err != nil {
 		return err
 	}
-	secret.Data = newSecret.Data
+	secret.Data = data
 	_, err = r.client.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
 	return err
 }
+
+// makeCertificates returns the data of the certificate secret, with a new
+// serving certificate signed by the CA of the given secret if any, or else
+// by a new CA.
+func (r *reconciler) makeCertificates(ctx context.Context, ca *corev1.Secret) (map[string][]byte, error) {
+	duration := r.duration
+	if duration == 0 {
+		if ca == nil {
+			newSecret, err := certresources.MakeSecret(ctx, r.key.Name, r.key.Namespace, r.serviceName)
+			if err != nil {
+				return nil, err
+			}
+			return newSecret.Data, nil
+		}
+		duration = certresources.DefaultDuration
+	}
+	notAfter := time.Now().Add(duration)
+
+	if ca == nil {
+		serverKey, serverCert, caCert, err := certresources.CreateCerts(ctx, r.serviceName, r.key.Namespace, notAfter)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{
+			certresources.ServerKey:  serverKey,
+			certresources.ServerCert: serverCert,
+			certresources.CACert:     caCert,
+		}, nil
+	}
+
+	serverKey, serverCert, err := certresources.CreateCertsWithCA(ctx, r.serviceName, r.key.Namespace, notAfter,
+		ca.Data[corev1.TLSCertKey], ca.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with the CA of %q: %w", r.signingCASecretName, err)
+	}
+	return map[string][]byte{
+		certresources.ServerKey:  serverKey,
+		certresources.ServerCert: serverCert,
+		certresources.CACert:     ca.Data[corev1.TLSCertKey],
+	}, nil
+}
+
+// caNotAfter returns the expiration date of the CA certificate of the
+// secret, or the zero time if it can't be parsed.
+func caNotAfter(ca *corev1.Secret) time.Time {
+	block, _ := pem.Decode(ca.Data[corev1.TLSCertKey])
+	if block == nil {
+		return time.Time{}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}
+	}
+	return cert.NotAfter
+}
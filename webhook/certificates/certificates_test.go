@@ -18,10 +18,19 @@ package certificates
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+
 	kubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	_ "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret/fake"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -31,7 +40,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/system"
@@ -220,6 +231,41 @@ func TestReconcileMakeSecretFailure(t *testing.T) {
 	}))
 }
 
+func TestReconcileExternal(t *testing.T) {
+	const secretName = "webhook-secret"
+
+	table := TableTest{{
+		Name: "missing server key is left alone",
+		Key:  system.Namespace() + "/does not matter",
+		Objects: []runtime.Object{&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: system.Namespace(),
+			},
+			Data: map[string][]byte{
+				certresources.ServerCert: []byte("present"),
+			},
+		}},
+	}, {
+		Name:    "certificate expiring soon is left alone",
+		Key:     system.Namespace() + "/does not matter",
+		Objects: []runtime.Object{secretWithCertData(t, time.Now().Add(time.Hour))},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &reconciler{
+			client:       kubeclient.Get(ctx),
+			secretlister: listers.GetSecretLister(),
+			key: types.NamespacedName{
+				Namespace: system.Namespace(),
+				Name:      secretName,
+			},
+			serviceName: "webhook-service",
+			external:    true,
+		}
+	}))
+}
+
 func TestNew(t *testing.T) {
 	ctx, _ := SetupFakeContext(t)
 	ctx = webhook.WithOptions(ctx, webhook.Options{})
@@ -268,3 +314,187 @@ func secretWithCertData(t *testing.T, expiration time.Time) *corev1.Secret {
 		},
 	}
 }
+
+func TestReconcileWithOptions(t *testing.T) {
+	const (
+		secretName   = "webhook-secret"
+		caSecretName = "operator-ca"
+		serviceName  = "webhook-service"
+	)
+	ca := makeCASecret(t, caSecretName, time.Now().Add(30*24*time.Hour))
+	otherCA := makeCASecret(t, caSecretName, time.Now().Add(30*24*time.Hour))
+	expiringCA := makeCASecret(t, caSecretName, time.Now().Add(12*time.Hour))
+
+	tests := []struct {
+		name           string
+		secret         *corev1.Secret
+		ca             *corev1.Secret
+		duration       time.Duration
+		renewBefore    time.Duration
+		wantErr        bool
+		wantUpdate     bool
+		wantExpiration time.Duration
+	}{{
+		name:           "renewed within the rotation window",
+		secret:         secretWithCertData(t, time.Now().Add(25*time.Hour)),
+		duration:       48 * time.Hour,
+		renewBefore:    36 * time.Hour,
+		wantUpdate:     true,
+		wantExpiration: 48 * time.Hour,
+	}, {
+		name:        "not renewed outside the rotation window",
+		secret:      secretWithCertData(t, time.Now().Add(37*time.Hour)),
+		duration:    48 * time.Hour,
+		renewBefore: 36 * time.Hour,
+	}, {
+		name:           "self-signed certificate signed by the CA",
+		secret:         secretWithCertData(t, time.Now().Add(7*24*time.Hour)),
+		ca:             ca,
+		wantUpdate:     true,
+		wantExpiration: certresources.DefaultDuration,
+	}, {
+		name:           "renewed when the CA changes",
+		secret:         secretSignedBy(t, otherCA, time.Now().Add(7*24*time.Hour)),
+		ca:             ca,
+		duration:       72 * time.Hour,
+		wantUpdate:     true,
+		wantExpiration: 72 * time.Hour,
+	}, {
+		name:   "signed by the current CA",
+		secret: secretSignedBy(t, ca, time.Now().Add(7*24*time.Hour)),
+		ca:     ca,
+	}, {
+		name:   "not renewed past the CA",
+		secret: secretSignedBy(t, expiringCA, time.Now().Add(48*time.Hour)),
+		ca:     expiringCA,
+	}, {
+		name:    "missing CA",
+		secret:  secretWithCertData(t, time.Now().Add(7*24*time.Hour)),
+		ca:      &corev1.Secret{},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _ := SetupFakeContext(t)
+			client := kubeclient.Get(ctx)
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, s := range []*corev1.Secret{test.secret, test.ca} {
+				if s == nil || s.Name == "" {
+					continue
+				}
+				if _, err := client.CoreV1().Secrets(s.Namespace).Create(ctx, s, metav1.CreateOptions{}); err != nil {
+					t.Fatal("Create() =", err)
+				}
+				indexer.Add(s)
+			}
+
+			r := &reconciler{
+				client:       client,
+				secretlister: corelisters.NewSecretLister(indexer),
+				key: types.NamespacedName{
+					Namespace: system.Namespace(),
+					Name:      secretName,
+				},
+				serviceName: serviceName,
+				duration:    test.duration,
+				renewBefore: test.renewBefore,
+			}
+			if test.ca != nil {
+				r.signingCASecretName = caSecretName
+			}
+
+			err := r.reconcileCertificate(ctx)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("reconcileCertificate() = %v, wanted error: %v", err, test.wantErr)
+			}
+
+			got, err := client.CoreV1().Secrets(system.Namespace()).Get(ctx, secretName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("Get() =", err)
+			}
+			if updated := !cmp.Equal(got.Data, test.secret.Data); updated != test.wantUpdate {
+				t.Fatalf("Updated = %v, wanted %v", updated, test.wantUpdate)
+			}
+			if !test.wantUpdate {
+				return
+			}
+
+			cert, err := tls.X509KeyPair(got.Data[certresources.ServerCert], got.Data[certresources.ServerKey])
+			if err != nil {
+				t.Fatal("X509KeyPair() =", err)
+			}
+			leaf, err := x509.ParseCertificate(cert.Certificate[0])
+			if err != nil {
+				t.Fatal("ParseCertificate() =", err)
+			}
+			if expiration := time.Until(leaf.NotAfter); expiration > test.wantExpiration || expiration < test.wantExpiration-time.Minute {
+				t.Errorf("Certificate expires in %v, wanted %v", expiration, test.wantExpiration)
+			}
+
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(got.Data[certresources.CACert]) {
+				t.Fatal("Failed to parse the CA cert")
+			}
+			if _, err := leaf.Verify(x509.VerifyOptions{Roots: pool, DNSName: serviceName + "." + system.Namespace() + ".svc"}); err != nil {
+				t.Error("Verify() =", err)
+			}
+			if test.ca != nil && !cmp.Equal(got.Data[certresources.CACert], test.ca.Data[corev1.TLSCertKey]) {
+				t.Error("The CA cert is not the signing CA")
+			}
+		})
+	}
+}
+
+// makeCASecret returns a kubernetes.io/tls secret holding an RSA CA.
+func makeCASecret(t *testing.T, name string, notAfter time.Time) *corev1.Secret {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal("GenerateKey() =", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal("CreateCertificate() =", err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: system.Namespace(),
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		},
+	}
+}
+
+func secretSignedBy(t *testing.T, ca *corev1.Secret, expiration time.Time) *corev1.Secret {
+	t.Helper()
+	serverKey, serverCert, err := certresources.CreateCertsWithCA(context.Background(), "webhook-service", system.Namespace(), expiration,
+		ca.Data[corev1.TLSCertKey], ca.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		t.Fatal("CreateCertsWithCA() =", err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "webhook-secret",
+			Namespace: system.Namespace(),
+		},
+		Data: map[string][]byte{
+			certresources.ServerKey:  serverKey,
+			certresources.ServerCert: serverCert,
+			certresources.CACert:     ca.Data[corev1.TLSCertKey],
+		},
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/network"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// CertificateGVR is the resource of cert-manager Certificates.
+var CertificateGVR = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "certificates",
+}
+
+type reconciler struct {
+	pkgreconciler.LeaderAwareFuncs
+
+	client      dynamic.Interface
+	key         types.NamespacedName
+	serviceName string
+	provider    *Provider
+}
+
+var _ controller.Reconciler = (*reconciler)(nil)
+var _ pkgreconciler.LeaderAware = (*reconciler)(nil)
+
+// Reconcile implements controller.Reconciler
+func (r *reconciler) Reconcile(ctx context.Context, key string) error {
+	if r.IsLeaderFor(r.key) {
+		// only reconcile the certificate when we are leader.
+		return r.reconcileCertificate(ctx)
+	}
+	return controller.NewSkipKey(key)
+}
+
+func (r *reconciler) reconcileCertificate(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	desired := MakeCertificate(r.key.Name, r.key.Namespace, r.serviceName, r.provider)
+
+	certificates := r.client.Resource(CertificateGVR).Namespace(r.key.Namespace)
+	current, err := certificates.Get(ctx, r.key.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		logger.Infof("Creating certificate %q", r.key.Name)
+		_, err = certificates.Create(ctx, desired, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return fmt.Errorf("failed to get certificate %q: %w", r.key.Name, err)
+	}
+
+	if equality.Semantic.DeepEqual(current.Object["spec"], desired.Object["spec"]) {
+		return nil
+	}
+
+	// Don't modify the informer copy.
+	want := current.DeepCopy()
+	want.Object["spec"] = desired.Object["spec"]
+	logger.Infof("Updating certificate %q", r.key.Name)
+	_, err = certificates.Update(ctx, want, metav1.UpdateOptions{})
+	return err
+}
+
+// MakeCertificate returns the cert-manager Certificate issuing the serving
+// certificate of the named webhook service into the named secret.
+func MakeCertificate(secretName, namespace, serviceName string, p *Provider) *unstructured.Unstructured {
+	dnsNames := []interface{}{
+		serviceName,
+		serviceName + "." + namespace,
+		serviceName + "." + namespace + ".svc",
+		network.GetServiceHostname(serviceName, namespace),
+	}
+
+	issuerRef := map[string]interface{}{
+		"name": p.IssuerRef.Name,
+	}
+	if p.IssuerRef.Kind != "" {
+		issuerRef["kind"] = p.IssuerRef.Kind
+	}
+	if p.IssuerRef.Group != "" {
+		issuerRef["group"] = p.IssuerRef.Group
+	}
+
+	spec := map[string]interface{}{
+		"secretName": secretName,
+		"commonName": serviceName + "." + namespace + ".svc",
+		"dnsNames":   dnsNames,
+		"issuerRef":  issuerRef,
+		"usages":     []interface{}{"server auth", "digital signature", "key encipherment"},
+		"privateKey": map[string]interface{}{
+			"algorithm":      "ECDSA",
+			"rotationPolicy": "Always",
+		},
+	}
+	if p.Duration != 0 {
+		spec["duration"] = p.Duration.String()
+	}
+	if p.RenewBefore != 0 {
+		spec["renewBefore"] = p.RenewBefore.String()
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": CertificateGVR.GroupVersion().String(),
+		"kind":       "Certificate",
+		"metadata": map[string]interface{}{
+			"name":      secretName,
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}}
+}
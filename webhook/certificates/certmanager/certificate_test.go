@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	_ "knative.dev/pkg/injection/clients/dynamicclient/fake"
+	_ "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret/fake"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	"knative.dev/pkg/webhook"
+	"knative.dev/pkg/webhook/certificates"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+const (
+	secretName  = "webhook-secret"
+	serviceName = "webhook-service"
+)
+
+var provider = &Provider{
+	IssuerRef: IssuerReference{
+		Name: "knative-issuer",
+		Kind: "ClusterIssuer",
+	},
+	Duration: 24 * time.Hour,
+}
+
+func TestMakeCertificate(t *testing.T) {
+	got := MakeCertificate(secretName, "ns", serviceName, provider)
+
+	want := map[string]interface{}{
+		"secretName": secretName,
+		"commonName": "webhook-service.ns.svc",
+		"dnsNames": []interface{}{
+			"webhook-service",
+			"webhook-service.ns",
+			"webhook-service.ns.svc",
+			"webhook-service.ns.svc.cluster.local",
+		},
+		"issuerRef": map[string]interface{}{
+			"name": "knative-issuer",
+			"kind": "ClusterIssuer",
+		},
+		"usages": []interface{}{"server auth", "digital signature", "key encipherment"},
+		"privateKey": map[string]interface{}{
+			"algorithm":      "ECDSA",
+			"rotationPolicy": "Always",
+		},
+		"duration": "24h0m0s",
+	}
+	if diff := cmp.Diff(want, got.Object["spec"]); diff != "" {
+		t.Error("MakeCertificate().spec (-want, +got) =", diff)
+	}
+	if got.GetName() != secretName || got.GetNamespace() != "ns" || got.GetKind() != "Certificate" {
+		t.Errorf("MakeCertificate() = %s %s/%s", got.GetKind(), got.GetNamespace(), got.GetName())
+	}
+}
+
+func newReconciler(ctx context.Context, objs ...runtime.Object) (*reconciler, func(name string) *unstructured.Unstructured) {
+	_, client := fakedynamicclient.With(ctx, runtime.NewScheme(), objs...)
+
+	r := &reconciler{
+		client: client,
+		key: types.NamespacedName{
+			Namespace: system.Namespace(),
+			Name:      secretName,
+		},
+		serviceName: serviceName,
+		provider:    provider,
+	}
+	get := func(name string) *unstructured.Unstructured {
+		cert, err := client.Resource(CertificateGVR).Namespace(system.Namespace()).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil
+		}
+		return cert
+	}
+	return r, get
+}
+
+func TestReconcile(t *testing.T) {
+	desired := MakeCertificate(secretName, system.Namespace(), serviceName, provider)
+
+	stale := desired.DeepCopy()
+	stale.Object["spec"].(map[string]interface{})["commonName"] = "stale"
+	stale.SetLabels(map[string]string{"keep": "me"})
+
+	tests := []struct {
+		name string
+		objs []runtime.Object
+	}{{
+		name: "creates the certificate",
+	}, {
+		name: "certificate up to date",
+		objs: []runtime.Object{desired.DeepCopy()},
+	}, {
+		name: "updates the certificate",
+		objs: []runtime.Object{stale},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, get := newReconciler(context.Background(), tc.objs...)
+			if err := r.Promote(pkgreconciler.UniversalBucket(), func(pkgreconciler.Bucket, types.NamespacedName) {}); err != nil {
+				t.Fatal("Promote() =", err)
+			}
+
+			if err := r.Reconcile(context.Background(), "does not matter"); err != nil {
+				t.Fatal("Reconcile() =", err)
+			}
+
+			got := get(secretName)
+			if got == nil {
+				t.Fatal("Certificate was not created")
+			}
+			if diff := cmp.Diff(desired.Object["spec"], got.Object["spec"]); diff != "" {
+				t.Error("Certificate spec (-want, +got) =", diff)
+			}
+			if len(tc.objs) > 0 && len(tc.objs[0].(*unstructured.Unstructured).GetLabels()) != len(got.GetLabels()) {
+				t.Errorf("Certificate labels = %v, should have been kept", got.GetLabels())
+			}
+		})
+	}
+}
+
+func TestReconcileNotLeader(t *testing.T) {
+	r, get := newReconciler(context.Background())
+
+	err := r.Reconcile(context.Background(), "does not matter")
+	if !controller.IsSkipKey(err) {
+		t.Errorf("Reconcile() = %v, wanted a skip key", err)
+	}
+	if get(secretName) != nil {
+		t.Error("Certificate was created without being the leader")
+	}
+}
+
+func TestSecretDataKeys(t *testing.T) {
+	opts := &webhook.Options{CertificateProvider: provider}
+	if got, want := opts.CABundleKey(), "ca.crt"; got != want {
+		t.Errorf("CABundleKey() = %s, want: %s", got, want)
+	}
+}
+
+func TestNewController(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	ctx = webhook.WithOptions(ctx, webhook.Options{
+		ServiceName:         serviceName,
+		SecretName:          secretName,
+		CertificateProvider: provider,
+	})
+
+	// The certificates controller delegates to the provider.
+	c := certificates.NewController(ctx, configmap.NewStaticWatcher())
+	if _, ok := c.Reconciler.(*reconciler); !ok {
+		t.Fatalf("Reconciler = %T, want: %T", c.Reconciler, &reconciler{})
+	}
+
+	la := c.Reconciler.(pkgreconciler.LeaderAware)
+	if err := la.Promote(pkgreconciler.UniversalBucket(), c.MaybeEnqueueBucketKey); err != nil {
+		t.Error("Promote() =", err)
+	}
+
+	// Queue has async moving parts so if we check at the wrong moment, this might still be 0.
+	if wait.PollImmediate(10*time.Millisecond, 250*time.Millisecond, func() (bool, error) {
+		return c.WorkQueue().Len() == 1, nil
+	}) != nil {
+		t.Error("Queue length was never 1")
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certmanager provisions the webhook certificates through
+// cert-manager, as an alternative to the self-signed certificates.
+//
+// It is selected by setting the Provider as the CertificateProvider of the
+// webhook Options:
+//
+//	ctx = webhook.WithOptions(ctx, webhook.Options{
+//		ServiceName: "webhook",
+//		SecretName:  "webhook-certs",
+//		CertificateProvider: &certmanager.Provider{
+//			IssuerRef: certmanager.IssuerReference{
+//				Name: "knative-issuer",
+//				Kind: "ClusterIssuer",
+//			},
+//		},
+//	})
+//
+// The certificates controller then maintains a cert-manager Certificate
+// which is issued into the webhook secret, and the webhook reads the serving
+// key pair and the CA bundle from the keys cert-manager populates.
+package certmanager
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	secretinformer "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	"knative.dev/pkg/webhook"
+)
+
+// CACertKey is the key of the CA cert in the secrets issued by cert-manager.
+const CACertKey = "ca.crt"
+
+// IssuerReference references the cert-manager issuer signing the webhook
+// certificate.
+type IssuerReference struct {
+	// Name of the issuer.
+	Name string
+
+	// Kind of the issuer, Issuer or ClusterIssuer.
+	// cert-manager defaults it to Issuer if empty.
+	Kind string
+
+	// Group of the issuer.
+	// cert-manager defaults it to cert-manager.io if empty.
+	Group string
+}
+
+// Provider implements webhook.CertificateProvider by having cert-manager
+// issue the webhook certificate.
+type Provider struct {
+	// IssuerRef references the issuer signing the certificate.
+	IssuerRef IssuerReference
+
+	// Duration is the requested lifetime of the certificate.
+	// cert-manager defaults it to 90 days if zero.
+	Duration time.Duration
+
+	// RenewBefore is how long before its expiry the certificate is renewed.
+	// cert-manager defaults it to a third of the Duration if zero.
+	RenewBefore time.Duration
+}
+
+var _ webhook.CertificateProvider = (*Provider)(nil)
+
+// SecretDataKeys implements webhook.CertificateProvider
+func (*Provider) SecretDataKeys() (serverKey, serverCert, caCert string) {
+	return corev1.TLSPrivateKeyKey, corev1.TLSCertKey, CACertKey
+}
+
+// NewController implements webhook.CertificateProvider
+func (p *Provider) NewController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	secretInformer := secretinformer.Get(ctx)
+	options := webhook.GetOptions(ctx)
+
+	key := types.NamespacedName{
+		Namespace: system.Namespace(),
+		Name:      options.SecretName,
+	}
+
+	r := &reconciler{
+		LeaderAwareFuncs: pkgreconciler.LeaderAwareFuncs{
+			// Enqueue the key whenever we become leader.
+			PromoteFunc: func(bkt pkgreconciler.Bucket, enq func(pkgreconciler.Bucket, types.NamespacedName)) error {
+				enq(bkt, key)
+				return nil
+			},
+		},
+		key:         key,
+		serviceName: options.ServiceName,
+		provider:    p,
+
+		client: dynamicclient.Get(ctx),
+	}
+
+	const queueName = "WebhookCertificates"
+	c := controller.NewContext(ctx, r, controller.ControllerOptions{WorkQueueName: queueName, Logger: logging.FromContext(ctx).Named(queueName)})
+
+	// Reconcile when the issued secret changes, e.g. when it is deleted.
+	secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterWithNameAndNamespace(key.Namespace, key.Name),
+		// It doesn't matter what we enqueue because we will always Reconcile
+		// the named Certificate resource.
+		Handler: controller.HandleAll(c.Enqueue),
+	})
+
+	return c
+}
@@ -24,6 +24,8 @@ import (
 	secretinformer "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret"
 
 	"k8s.io/apimachinery/pkg/types"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -37,13 +39,31 @@ import (
 // In order for it to bootstrap, an empty secret should be created with the
 // expected name (and lifecycle managed accordingly), and thereafter this controller
 // will ensure it has the appropriate shape for the webhook.
+// When the webhook Options specify a CertificateProvider, the controller
+// of the provider is returned instead.
 func NewController(
 	ctx context.Context,
 	cmw configmap.Watcher,
 ) *controller.Impl {
+	options := webhook.GetOptions(ctx)
+	if options.CertificateProvider != nil {
+		return options.CertificateProvider.NewController(ctx, cmw)
+	}
 
-	client := kubeclient.Get(ctx)
-	secretInformer := secretinformer.Get(ctx)
+	return NewControllerWithInformers(ctx, kubeclient.Get(ctx), secretinformer.Get(ctx))
+}
+
+// NewControllerWithInformers constructs the controller of NewController
+// without relying on injection, from the given client and secret informer,
+// which must cover the system namespace. The CertificateProvider of the
+// webhook Options is not supported, since its controller relies on
+// injection. The caller is responsible for running the informer, see
+// webhook.Standalone.
+func NewControllerWithInformers(
+	ctx context.Context,
+	client kubernetes.Interface,
+	secretInformer corev1informers.SecretInformer,
+) *controller.Impl {
 	options := webhook.GetOptions(ctx)
 
 	key := types.NamespacedName{
@@ -61,6 +81,11 @@ func NewController(
 		},
 		key:         key,
 		serviceName: options.ServiceName,
+		external:    options.CABundleSecretName() != options.SecretName,
+
+		duration:            options.CertificateDuration,
+		renewBefore:         options.CertificateRenewBefore,
+		signingCASecretName: options.SigningCASecretName,
 
 		client:       client,
 		secretlister: secretInformer.Lister(),
@@ -77,5 +102,13 @@ func NewController(
 		Handler: controller.HandleAll(c.Enqueue),
 	})
 
+	if wh.signingCASecretName != "" {
+		// Reconcile when the signing CA changes.
+		secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: controller.FilterWithNameAndNamespace(key.Namespace, wh.signingCASecretName),
+			Handler:    controller.HandleAll(c.EnqueueSentinel(key)),
+		})
+	}
+
 	return c
 }
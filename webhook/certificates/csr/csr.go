@@ -0,0 +1,262 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csr
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/network"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// PendingKeyKey is the key of the secret's data holding the private key of
+// the pending CertificateSigningRequest, until its certificate is issued.
+const PendingKeyKey = "pending-key.pem"
+
+type reconciler struct {
+	pkgreconciler.LeaderAwareFuncs
+
+	client       kubernetes.Interface
+	secretlister corelisters.SecretLister
+	key          types.NamespacedName
+	serviceName  string
+	provider     *Provider
+
+	// The names of the secret's data keys.
+	serverKey, serverCert, caCert string
+}
+
+var _ controller.Reconciler = (*reconciler)(nil)
+var _ pkgreconciler.LeaderAware = (*reconciler)(nil)
+
+// Reconcile implements controller.Reconciler
+func (r *reconciler) Reconcile(ctx context.Context, key string) error {
+	if r.IsLeaderFor(r.key) {
+		// only reconcile the certificate when we are leader.
+		return r.reconcileCertificate(ctx)
+	}
+	return controller.NewSkipKey(key)
+}
+
+func (r *reconciler) reconcileCertificate(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	secret, err := r.secretlister.Secrets(r.key.Namespace).Get(r.key.Name)
+	if apierrors.IsNotFound(err) {
+		// The secret should be created explicitly by a higher-level system
+		// that's responsible for install/updates.  We simply populate the
+		// secret information.
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get certificate secret %q: %w", r.key.Name, err)
+	}
+
+	pendingKey, pending := secret.Data[PendingKeyKey]
+	if !pending {
+		if !r.needsRenewal(ctx, secret) {
+			return nil
+		}
+		return r.requestCertificate(ctx, secret)
+	}
+
+	// Don't use a lister, which may not know yet about the request created
+	// right before storing the pending key.
+	name := RequestName(r.key)
+	csr, err := r.client.CertificatesV1().CertificateSigningRequests().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return r.requestCertificate(ctx, secret)
+	} else if err != nil {
+		return fmt.Errorf("failed to get certificate signing request %q: %w", name, err)
+	}
+
+	for _, cond := range csr.Status.Conditions {
+		switch cond.Type {
+		case certificatesv1.CertificateDenied, certificatesv1.CertificateFailed:
+			// Leave it up to an operator to delete the request to try again.
+			return controller.NewPermanentError(fmt.Errorf("certificate signing request %q is %s: %s", name, cond.Type, cond.Message))
+		}
+	}
+	if len(csr.Status.Certificate) == 0 {
+		logger.Infof("Waiting for certificate signing request %q to be issued", name)
+		return nil
+	}
+
+	if _, err := tls.X509KeyPair(csr.Status.Certificate, pendingKey); err != nil {
+		logger.Warnf("Certificate issued for request %q doesn't match the pending key: %v", name, err)
+		return r.requestCertificate(ctx, secret)
+	}
+
+	// Don't modify the informer copy.
+	secret = secret.DeepCopy()
+	secret.Data[r.serverKey] = pendingKey
+	secret.Data[r.serverCert] = csr.Status.Certificate
+	secret.Data[r.caCert] = r.provider.CABundle
+	delete(secret.Data, PendingKeyKey)
+	logger.Infof("Updating certificate secret %q with the certificate issued for %q", r.key.Name, name)
+	if _, err := r.client.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return r.deleteRequest(ctx)
+}
+
+// needsRenewal returns whether the secret lacks a valid certificate, or
+// holds one which is due for renewal.
+func (r *reconciler) needsRenewal(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logging.FromContext(ctx)
+
+	cert, err := tls.X509KeyPair(secret.Data[r.serverCert], secret.Data[r.serverKey])
+	if err != nil {
+		logger.Infof("Certificate secret %q has no valid key pair: %v", r.key.Name, err)
+		return true
+	}
+	certData, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		logger.Infof("Certificate secret %q has an invalid certificate: %v", r.key.Name, err)
+		return true
+	}
+
+	renewBefore := r.provider.RenewBefore
+	if renewBefore == 0 {
+		renewBefore = certData.NotAfter.Sub(certData.NotBefore) / 3
+	}
+	return !time.Now().Add(renewBefore).Before(certData.NotAfter)
+}
+
+// requestCertificate generates a new private key, stores it as the pending
+// key of the secret and requests a certificate for it, replacing any
+// previous request.
+func (r *reconciler) requestCertificate(ctx context.Context, secret *corev1.Secret) error {
+	logger := logging.FromContext(ctx)
+	name := RequestName(r.key)
+
+	keyPEM, requestPEM, err := MakeRequest(r.serviceName, r.key.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// Don't modify the informer copy.
+	secret = secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte, 1)
+	}
+	secret.Data[PendingKeyKey] = keyPEM
+	if _, err := r.client.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	if err := r.deleteRequest(ctx); err != nil {
+		return err
+	}
+
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    requestPEM,
+			SignerName: r.provider.SignerName,
+			Usages:     usages,
+		},
+	}
+	if r.provider.Duration != 0 {
+		seconds := int32(r.provider.Duration.Seconds())
+		csr.Spec.ExpirationSeconds = &seconds
+	}
+
+	logger.Infof("Creating certificate signing request %q", name)
+	csrs := r.client.CertificatesV1().CertificateSigningRequests()
+	csr, err = csrs.Create(ctx, csr, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create certificate signing request %q: %w", name, err)
+	}
+
+	if !r.provider.AutoApprove {
+		return nil
+	}
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:           certificatesv1.CertificateApproved,
+		Status:         corev1.ConditionTrue,
+		Reason:         "WebhookAutoApproved",
+		Message:        "Approved by the webhook requesting its serving certificate",
+		LastUpdateTime: metav1.Now(),
+	})
+	if _, err := csrs.UpdateApproval(ctx, name, csr, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to approve certificate signing request %q: %w", name, err)
+	}
+	return nil
+}
+
+// deleteRequest deletes the CertificateSigningRequest of the secret, if any.
+func (r *reconciler) deleteRequest(ctx context.Context) error {
+	err := r.client.CertificatesV1().CertificateSigningRequests().Delete(ctx, RequestName(r.key), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete certificate signing request %q: %w", RequestName(r.key), err)
+	}
+	return nil
+}
+
+// MakeRequest generates a private key and the PEM encoded x509 certificate
+// request of a serving certificate for the named webhook service.
+func MakeRequest(serviceName, namespace string) (keyPEM, requestPEM []byte, err error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	commonName := serviceName + "." + namespace + ".svc"
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+		DNSNames: []string{
+			serviceName,
+			serviceName + "." + namespace,
+			commonName,
+			network.GetServiceHostname(serviceName, namespace),
+		},
+	}
+	requestBytes, err := x509.CreateCertificateRequest(rand.Reader, template, privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate request: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: requestBytes}), nil
+}
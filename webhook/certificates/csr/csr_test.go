@@ -0,0 +1,423 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csr
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	_ "knative.dev/pkg/client/injection/kube/client/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/certificates/v1/certificatesigningrequest/fake"
+	_ "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret/fake"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	"knative.dev/pkg/webhook"
+	"knative.dev/pkg/webhook/certificates"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+const (
+	secretName  = "webhook-secret"
+	serviceName = "webhook-service"
+	signerName  = "example.com/webhook-serving"
+)
+
+func TestMakeRequest(t *testing.T) {
+	keyPEM, requestPEM, err := MakeRequest(serviceName, "ns")
+	if err != nil {
+		t.Fatal("MakeRequest() =", err)
+	}
+
+	if block, _ := pem.Decode(keyPEM); block == nil {
+		t.Error("MakeRequest() returned an invalid private key")
+	} else if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		t.Error("ParsePKCS8PrivateKey() =", err)
+	}
+
+	block, _ := pem.Decode(requestPEM)
+	if block == nil {
+		t.Fatal("MakeRequest() returned an invalid certificate request")
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal("ParseCertificateRequest() =", err)
+	}
+	if got, want := req.Subject.CommonName, "webhook-service.ns.svc"; got != want {
+		t.Errorf("CommonName = %s, want: %s", got, want)
+	}
+	wantDNSNames := []string{
+		"webhook-service",
+		"webhook-service.ns",
+		"webhook-service.ns.svc",
+		"webhook-service.ns.svc.cluster.local",
+	}
+	if diff := cmp.Diff(wantDNSNames, req.DNSNames); diff != "" {
+		t.Error("DNSNames (-want, +got) =", diff)
+	}
+}
+
+// testSigner signs certificate requests like a signer of the
+// CertificateSigningRequest API would.
+type testSigner struct {
+	key    *ecdsa.PrivateKey
+	cert   *x509.Certificate
+	caPEM  []byte
+	serial int64
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("GenerateKey() =", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-signer"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal("CreateCertificate() =", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal("ParseCertificate() =", err)
+	}
+	return &testSigner{
+		key:    key,
+		cert:   cert,
+		caPEM:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		serial: 1,
+	}
+}
+
+func (s *testSigner) sign(t *testing.T, requestPEM []byte, lifetime time.Duration) []byte {
+	t.Helper()
+	block, _ := pem.Decode(requestPEM)
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal("ParseCertificateRequest() =", err)
+	}
+	s.serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(s.serial),
+		Subject:      req.Subject,
+		DNSNames:     req.DNSNames,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(lifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, s.cert, req.PublicKey, s.key)
+	if err != nil {
+		t.Fatal("CreateCertificate() =", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+type testReconciler struct {
+	*reconciler
+	client  *fakekubeclientset.Clientset
+	secrets cache.Indexer
+}
+
+func newReconciler(t *testing.T, p *Provider) *testReconciler {
+	t.Helper()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: system.Namespace(),
+		},
+	}
+	client := fakekubeclientset.NewSimpleClientset(secret)
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	secrets.Add(secret)
+
+	r := &reconciler{
+		client:       client,
+		secretlister: corelisters.NewSecretLister(secrets),
+		key: types.NamespacedName{
+			Namespace: system.Namespace(),
+			Name:      secretName,
+		},
+		serviceName: serviceName,
+		provider:    p,
+		serverKey:   corev1.TLSPrivateKeyKey,
+		serverCert:  corev1.TLSCertKey,
+		caCert:      CACertKey,
+	}
+	if err := r.Promote(pkgreconciler.UniversalBucket(), func(pkgreconciler.Bucket, types.NamespacedName) {}); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	return &testReconciler{reconciler: r, client: client, secrets: secrets}
+}
+
+// reconcile runs a reconciliation and syncs the lister with the client, as
+// the informer would.
+func (r *testReconciler) reconcile(t *testing.T) error {
+	t.Helper()
+	err := r.Reconcile(context.Background(), "does not matter")
+	r.secrets.Update(r.secret(t))
+	return err
+}
+
+func (r *testReconciler) secret(t *testing.T) *corev1.Secret {
+	t.Helper()
+	secret, err := r.client.CoreV1().Secrets(system.Namespace()).Get(context.Background(), secretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Get(secret) =", err)
+	}
+	return secret
+}
+
+func (r *testReconciler) request(t *testing.T) *certificatesv1.CertificateSigningRequest {
+	t.Helper()
+	csr, err := r.client.CertificatesV1().CertificateSigningRequests().Get(context.Background(), RequestName(r.key), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		t.Fatal("Get(csr) =", err)
+	}
+	return csr
+}
+
+func (r *testReconciler) updateRequestStatus(t *testing.T, csr *certificatesv1.CertificateSigningRequest) {
+	t.Helper()
+	if _, err := r.client.CertificatesV1().CertificateSigningRequests().UpdateStatus(context.Background(), csr, metav1.UpdateOptions{}); err != nil {
+		t.Fatal("UpdateStatus(csr) =", err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	signer := newTestSigner(t)
+	r := newReconciler(t, &Provider{
+		SignerName:  signerName,
+		CABundle:    signer.caPEM,
+		Duration:    time.Hour,
+		AutoApprove: true,
+	})
+
+	// The empty secret gets a pending key, and the certificate is requested.
+	if err := r.reconcile(t); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	pendingKey := r.secret(t).Data[PendingKeyKey]
+	if len(pendingKey) == 0 {
+		t.Fatal("Secret has no pending key")
+	}
+	csr := r.request(t)
+	if csr == nil {
+		t.Fatal("Certificate signing request was not created")
+	}
+	if got, want := csr.Spec.SignerName, signerName; got != want {
+		t.Errorf("SignerName = %s, want: %s", got, want)
+	}
+	if diff := cmp.Diff(usages, csr.Spec.Usages); diff != "" {
+		t.Error("Usages (-want, +got) =", diff)
+	}
+	if got := csr.Spec.ExpirationSeconds; got == nil || *got != 3600 {
+		t.Errorf("ExpirationSeconds = %v, want: 3600", got)
+	}
+	if len(csr.Status.Conditions) != 1 || csr.Status.Conditions[0].Type != certificatesv1.CertificateApproved {
+		t.Errorf("Conditions = %v, want the request to be approved", csr.Status.Conditions)
+	}
+
+	// Nothing happens until the certificate is issued.
+	if err := r.reconcile(t); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	if got := r.secret(t).Data[PendingKeyKey]; !cmp.Equal(got, pendingKey) {
+		t.Error("Pending key changed while waiting for the certificate")
+	}
+
+	// The issued certificate is stored in the secret.
+	csr.Status.Certificate = signer.sign(t, csr.Spec.Request, 24*time.Hour)
+	r.updateRequestStatus(t, csr)
+	if err := r.reconcile(t); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	want := map[string][]byte{
+		corev1.TLSPrivateKeyKey: pendingKey,
+		corev1.TLSCertKey:       csr.Status.Certificate,
+		CACertKey:               signer.caPEM,
+	}
+	if diff := cmp.Diff(want, r.secret(t).Data); diff != "" {
+		t.Error("Secret data (-want, +got) =", diff)
+	}
+	if r.request(t) != nil {
+		t.Error("Certificate signing request was not deleted")
+	}
+
+	// The certificate is up to date.
+	if err := r.reconcile(t); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	if r.request(t) != nil {
+		t.Error("Certificate was requested while up to date")
+	}
+}
+
+func TestReconcileRenewal(t *testing.T) {
+	signer := newTestSigner(t)
+	r := newReconciler(t, &Provider{
+		SignerName: signerName,
+		CABundle:   signer.caPEM,
+	})
+
+	// Issue a certificate with less than a third of its lifetime left.
+	keyPEM, requestPEM, err := MakeRequest(serviceName, system.Namespace())
+	if err != nil {
+		t.Fatal("MakeRequest() =", err)
+	}
+	secret := r.secret(t)
+	secret.Data = map[string][]byte{
+		corev1.TLSPrivateKeyKey: keyPEM,
+		corev1.TLSCertKey:       signer.sign(t, requestPEM, time.Second),
+		CACertKey:               signer.caPEM,
+	}
+	if _, err := r.client.CoreV1().Secrets(secret.Namespace).Update(context.Background(), secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Update(secret) =", err)
+	}
+	r.secrets.Update(secret)
+
+	if err := r.reconcile(t); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	csr := r.request(t)
+	if csr == nil {
+		t.Fatal("Certificate was not renewed")
+	}
+	if len(csr.Status.Conditions) != 0 {
+		t.Errorf("Conditions = %v, want the request not to be approved", csr.Status.Conditions)
+	}
+	// The current certificate is served until the new one is issued.
+	if got := r.secret(t).Data[corev1.TLSPrivateKeyKey]; !cmp.Equal(got, keyPEM) {
+		t.Error("Serving key was replaced before the certificate was issued")
+	}
+}
+
+func TestReconcileDenied(t *testing.T) {
+	r := newReconciler(t, &Provider{SignerName: signerName})
+
+	if err := r.reconcile(t); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	csr := r.request(t)
+	csr.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{{
+		Type:    certificatesv1.CertificateDenied,
+		Status:  corev1.ConditionTrue,
+		Message: "not today",
+	}}
+	r.updateRequestStatus(t, csr)
+
+	err := r.reconcile(t)
+	if !controller.IsPermanentError(err) {
+		t.Errorf("Reconcile() = %v, wanted a permanent error", err)
+	}
+	if r.request(t) == nil {
+		t.Error("Denied certificate signing request was deleted")
+	}
+
+	// Deleting the request makes it request the certificate anew.
+	if err := r.client.CertificatesV1().CertificateSigningRequests().Delete(context.Background(), csr.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatal("Delete(csr) =", err)
+	}
+	if err := r.reconcile(t); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	if csr := r.request(t); csr == nil || len(csr.Status.Conditions) != 0 {
+		t.Errorf("Certificate signing request = %v, wanted a new request", csr)
+	}
+}
+
+func TestReconcileNotLeader(t *testing.T) {
+	r := newReconciler(t, &Provider{SignerName: signerName})
+	r.Demote(pkgreconciler.UniversalBucket())
+
+	err := r.reconcile(t)
+	if !controller.IsSkipKey(err) {
+		t.Errorf("Reconcile() = %v, wanted a skip key", err)
+	}
+	if r.request(t) != nil {
+		t.Error("Certificate was requested without being the leader")
+	}
+}
+
+func TestSecretDataKeys(t *testing.T) {
+	opts := &webhook.Options{CertificateProvider: &Provider{}}
+	if got, want := opts.CABundleKey(), "ca.crt"; got != want {
+		t.Errorf("CABundleKey() = %s, want: %s", got, want)
+	}
+}
+
+func TestNewController(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	ctx = webhook.WithOptions(ctx, webhook.Options{
+		ServiceName:         serviceName,
+		SecretName:          secretName,
+		CertificateProvider: &Provider{SignerName: signerName},
+	})
+
+	// The certificates controller delegates to the provider.
+	c := certificates.NewController(ctx, configmap.NewStaticWatcher())
+	r, ok := c.Reconciler.(*reconciler)
+	if !ok {
+		t.Fatalf("Reconciler = %T, want: %T", c.Reconciler, &reconciler{})
+	}
+	if r.serverKey != corev1.TLSPrivateKeyKey || r.serverCert != corev1.TLSCertKey || r.caCert != CACertKey {
+		t.Errorf("Secret data keys = (%s, %s, %s), want the provider's", r.serverKey, r.serverCert, r.caCert)
+	}
+
+	if err := r.Promote(pkgreconciler.UniversalBucket(), c.MaybeEnqueueBucketKey); err != nil {
+		t.Error("Promote() =", err)
+	}
+
+	// Queue has async moving parts so if we check at the wrong moment, this might still be 0.
+	if wait.PollImmediate(10*time.Millisecond, 250*time.Millisecond, func() (bool, error) {
+		return c.WorkQueue().Len() == 1, nil
+	}) != nil {
+		t.Error("Queue length was never 1")
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package csr provisions the webhook certificates through the Kubernetes
+// CertificateSigningRequest API, as an alternative to the self-signed
+// certificates for clusters with strict PKI requirements.
+//
+// It is selected by setting the Provider as the CertificateProvider of the
+// webhook Options:
+//
+//	ctx = webhook.WithOptions(ctx, webhook.Options{
+//		ServiceName: "webhook",
+//		SecretName:  "webhook-certs",
+//		CertificateProvider: &csr.Provider{
+//			SignerName:  "example.com/webhook-serving",
+//			CABundle:    signerCA,
+//			AutoApprove: true,
+//		},
+//	})
+//
+// The certificates controller then generates a private key, requests a
+// serving certificate for it from the signer, and stores the issued key pair
+// along with the CA bundle of the signer in the webhook secret. The
+// certificate is requested anew when it is due for renewal.
+package csr
+
+import (
+	"context"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	csrinformer "knative.dev/pkg/client/injection/kube/informers/certificates/v1/certificatesigningrequest"
+	secretinformer "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	"knative.dev/pkg/webhook"
+)
+
+// CACertKey is the key of the CA cert in the secrets populated by the
+// Provider.
+const CACertKey = "ca.crt"
+
+// Provider implements webhook.CertificateProvider by requesting the webhook
+// certificate through the CertificateSigningRequest API.
+type Provider struct {
+	// SignerName is the name of the signer issuing the certificate, e.g.
+	// the one of a cluster signer or of an external signer.
+	SignerName string
+
+	// CABundle is the PEM encoded CA certificate of the signer, which is
+	// provided to the k8s apiserver to verify the serving certificate. The
+	// CertificateSigningRequest API doesn't expose it.
+	CABundle []byte
+
+	// Duration is the requested lifetime of the certificate.
+	// The signer decides on the lifetime if zero.
+	Duration time.Duration
+
+	// RenewBefore is how long before its expiry the certificate is renewed.
+	// The certificate is renewed when a third of its lifetime is left if
+	// zero.
+	RenewBefore time.Duration
+
+	// AutoApprove makes the webhook approve its own requests, which requires
+	// its service account to be allowed to approve requests for the signer.
+	// Otherwise the requests are left for an approver to approve.
+	AutoApprove bool
+}
+
+var _ webhook.CertificateProvider = (*Provider)(nil)
+
+// SecretDataKeys implements webhook.CertificateProvider
+func (*Provider) SecretDataKeys() (serverKey, serverCert, caCert string) {
+	return corev1.TLSPrivateKeyKey, corev1.TLSCertKey, CACertKey
+}
+
+// NewController implements webhook.CertificateProvider
+func (p *Provider) NewController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	secretInformer := secretinformer.Get(ctx)
+	csrInformer := csrinformer.Get(ctx)
+	options := webhook.GetOptions(ctx)
+
+	key := types.NamespacedName{
+		Namespace: system.Namespace(),
+		Name:      options.SecretName,
+	}
+	serverKey, serverCert, caCert := options.SecretDataKeys()
+
+	r := &reconciler{
+		LeaderAwareFuncs: pkgreconciler.LeaderAwareFuncs{
+			// Enqueue the key whenever we become leader.
+			PromoteFunc: func(bkt pkgreconciler.Bucket, enq func(pkgreconciler.Bucket, types.NamespacedName)) error {
+				enq(bkt, key)
+				return nil
+			},
+		},
+		key:         key,
+		serviceName: options.ServiceName,
+		provider:    p,
+		serverKey:   serverKey,
+		serverCert:  serverCert,
+		caCert:      caCert,
+
+		client:       kubeclient.Get(ctx),
+		secretlister: secretInformer.Lister(),
+	}
+
+	const queueName = "WebhookCertificates"
+	c := controller.NewContext(ctx, r, controller.ControllerOptions{WorkQueueName: queueName, Logger: logging.FromContext(ctx).Named(queueName)})
+
+	// It doesn't matter what we enqueue because we will always Reconcile
+	// the named secret.
+	secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterWithNameAndNamespace(key.Namespace, key.Name),
+		Handler:    controller.HandleAll(c.Enqueue),
+	})
+	// Reconcile when the request is approved or issued.
+	csrInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterWithName(RequestName(key)),
+		Handler: controller.HandleAll(func(interface{}) {
+			c.EnqueueKey(key)
+		}),
+	})
+
+	return c
+}
+
+// RequestName returns the name of the CertificateSigningRequest of the
+// webhook secret with the given key.
+func RequestName(key types.NamespacedName) string {
+	return key.Namespace + "-" + key.Name
+}
+
+// usages are the key usages requested for the serving certificate.
+var usages = []certificatesv1.KeyUsage{
+	certificatesv1.UsageDigitalSignature,
+	certificatesv1.UsageKeyEncipherment,
+	certificatesv1.UsageServerAuth,
+}
@@ -18,13 +18,16 @@ package resources
 
 import (
 	"context"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
@@ -142,40 +145,74 @@ func createCA(ctx context.Context, name, namespace string, notAfter time.Time) (
 // client to verify the server authentication chain. notAfter specifies
 // the expiration date.
 func CreateCerts(ctx context.Context, name, namespace string, notAfter time.Time) (serverKey, serverCert, caCert []byte, err error) {
-	logger := logging.FromContext(ctx)
 	// First create a CA certificate and private key
 	caKey, caCertificate, caCertificatePEM, err := createCA(ctx, name, namespace, notAfter)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	// Then create the private key for the serving cert
+	servKeyPEM, servCertPEM, err := createServerCert(ctx, name, namespace, notAfter, caCertificate, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return servKeyPEM, servCertPEM, caCertificatePEM, nil
+}
+
+// CreateCertsWithCA creates and returns a certificate and key for the server
+// signed by the given CA, whose PEM-encoded certificate and key are e.g. the
+// tls.crt and tls.key of a kubernetes.io/tls secret. notAfter specifies the
+// expiration date, which is capped to that of the CA certificate.
+func CreateCertsWithCA(ctx context.Context, name, namespace string, notAfter time.Time, caCertPEM, caKeyPEM []byte) (serverKey, serverCert []byte, err error) {
+	ca, err := tls.X509KeyPair(caCertPEM, caKeyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load the CA key pair: %w", err)
+	}
+	caCertificate, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the CA certificate: %w", err)
+	}
+	if !caCertificate.IsCA {
+		return nil, nil, errors.New("the CA certificate is not a CA")
+	}
+	if notAfter.After(caCertificate.NotAfter) {
+		notAfter = caCertificate.NotAfter
+	}
+
+	return createServerCert(ctx, name, namespace, notAfter, caCertificate, ca.PrivateKey)
+}
+
+func createServerCert(ctx context.Context, name, namespace string, notAfter time.Time, caCertificate *x509.Certificate, caKey crypto.PrivateKey) (serverKey, serverCert []byte, err error) {
+	logger := logging.FromContext(ctx)
+
+	// Create the private key for the serving cert
 	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
 		logger.Errorw("error generating random key", zap.Error(err))
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 	publicKey := privateKey.Public()
 
 	servCertTemplate, err := createServerCertTemplate(name, namespace, notAfter)
 	if err != nil {
 		logger.Errorw("failed to create the server certificate template", zap.Error(err))
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
+	// Let the signature algorithm follow the CA key, which may not be ECDSA.
+	servCertTemplate.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
 
 	// create a certificate which wraps the server's public key, sign it with the CA private key
 	_, servCertPEM, err := createCert(servCertTemplate, caCertificate, publicKey, caKey)
 	if err != nil {
 		logger.Errorw("error signing server certificate template", zap.Error(err))
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 	privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
 	if err != nil {
 		logger.Errorw("error marshaling private key", zap.Error(err))
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 	servKeyPEM := pem.EncodeToMemory(&pem.Block{
 		Type: "PRIVATE KEY", Bytes: privKeyBytes,
 	})
-	return servKeyPEM, servCertPEM, caCertificatePEM, nil
+	return servKeyPEM, servCertPEM, nil
 }
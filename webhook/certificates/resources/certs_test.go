@@ -18,6 +18,7 @@ package resources
 
 import (
 	"crypto/ecdsa"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -88,6 +89,54 @@ func TestCreateCerts(t *testing.T) {
 	}
 }
 
+func TestCreateCertsWithCA(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	caNotAfter := time.Now().AddDate(0, 0, 30)
+	caKey, _, caCertPEM, err := createCA(ctx, "operator-ca", "knative-webhook", caNotAfter)
+	if err != nil {
+		t.Fatal("createCA() =", err)
+	}
+	caKeyBytes, err := x509.MarshalPKCS8PrivateKey(caKey)
+	if err != nil {
+		t.Fatal("MarshalPKCS8PrivateKey() =", err)
+	}
+	caKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: caKeyBytes})
+
+	// The expiration date is capped to the one of the CA.
+	sKey, serverCertPEM, err := CreateCertsWithCA(ctx, "got-the-hook", "knative-webhook", caNotAfter.AddDate(1, 0, 0), caCertPEM, caKeyPEM)
+	if err != nil {
+		t.Fatal("CreateCertsWithCA() =", err)
+	}
+	if _, err := tls.X509KeyPair(serverCertPEM, sKey); err != nil {
+		t.Fatal("X509KeyPair() =", err)
+	}
+	sCert, err := validCertificate(serverCertPEM, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := validCertificate(caCertPEM, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sCert.CheckSignatureFrom(caCert); err != nil {
+		t.Error("Failed to verify that the signature on server certificate is from the CA cert", err)
+	}
+	if !sCert.NotAfter.Equal(caCert.NotAfter) {
+		t.Errorf("NotAfter = %v, wanted %v", sCert.NotAfter, caCert.NotAfter)
+	}
+	if got, want := sCert.Subject.CommonName, "got-the-hook.knative-webhook.svc"; got != want {
+		t.Errorf("CommonName = %q, wanted %q", got, want)
+	}
+
+	// The serving certificate can't sign certificates.
+	if _, _, err := CreateCertsWithCA(ctx, "got-the-hook", "knative-webhook", caNotAfter, serverCertPEM, sKey); err == nil {
+		t.Error("CreateCertsWithCA() = nil, wanted an error for a non-CA certificate")
+	}
+	if _, _, err := CreateCertsWithCA(ctx, "got-the-hook", "knative-webhook", caNotAfter, caCertPEM, []byte("garbage")); err == nil {
+		t.Error("CreateCertsWithCA() = nil, wanted an error for a malformed key")
+	}
+}
+
 func validCertificate(cert []byte, t *testing.T) (*x509.Certificate, error) {
 	t.Helper()
 	const certificate = "CERTIFICATE"
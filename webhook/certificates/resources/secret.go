@@ -32,8 +32,12 @@ const (
 	// CACert is the name of the key associated with the certificate of the CA for
 	// the keypair.
 	CACert = "ca-cert.pem"
+	// OCSPStaple is the name of the optional key associated with a DER-encoded
+	// OCSP response for the secret's public key.
+	OCSPStaple = "ocsp-staple.der"
 
-	oneWeek = 7 * 24 * time.Hour
+	// DefaultDuration is the lifetime of the certificates of MakeSecret.
+	DefaultDuration = 7 * 24 * time.Hour
 )
 
 // MakeSecret synthesizes a Kubernetes Secret object with the keys specified by
@@ -43,7 +47,7 @@ var MakeSecret = MakeSecretInternal
 
 // MakeSecretInternal is only public so MakeSecret can be restored in testing.  Use MakeSecret.
 func MakeSecretInternal(ctx context.Context, name, namespace, serviceName string) (*corev1.Secret, error) {
-	serverKey, serverCert, caCert, err := CreateCerts(ctx, serviceName, namespace, time.Now().Add(oneWeek))
+	serverKey, serverCert, caCert, err := CreateCerts(ctx, serviceName, namespace, time.Now().Add(DefaultDuration))
 	if err != nil {
 		return nil, err
 	}
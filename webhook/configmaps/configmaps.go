@@ -42,7 +42,6 @@ import (
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/webhook"
-	certresources "knative.dev/pkg/webhook/certificates/resources"
 )
 
 // reconciler implements the AdmissionController for ConfigMaps
@@ -59,6 +58,7 @@ type reconciler struct {
 	secretlister corelisters.SecretLister
 
 	secretName string
+	caCertKey  string
 }
 
 var _ controller.Reconciler = (*reconciler)(nil)
@@ -80,9 +80,9 @@ func (ac *reconciler) Reconcile(ctx context.Context, key string) error {
 		return err
 	}
 
-	caCert, ok := secret.Data[certresources.CACert]
+	caCert, ok := secret.Data[ac.caCertKey]
 	if !ok {
-		return fmt.Errorf("secret %q is missing %q key", ac.secretName, certresources.CACert)
+		return fmt.Errorf("secret %q is missing %q key", ac.secretName, ac.caCertKey)
 	}
 
 	return ac.reconcileValidatingWebhook(ctx, caCert)
@@ -62,7 +62,8 @@ func NewAdmissionController(
 		path: path,
 
 		constructors: make(map[string]reflect.Value),
-		secretName:   options.SecretName,
+		secretName:   options.CABundleSecretNameFor(path),
+		caCertKey:    options.CABundleKey(),
 
 		client:       client,
 		vwhlister:    vwhInformer.Lister(),
@@ -320,6 +320,7 @@ func TestReconcile(t *testing.T) {
 
 			constructors: make(map[string]reflect.Value),
 			secretName:   secretName,
+			caCertKey:    certresources.CACert,
 		}
 
 		for configName, constructor := range validations {
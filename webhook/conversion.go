@@ -38,10 +38,10 @@ type ConversionController interface {
 	Convert(context.Context, *apixv1.ConversionRequest) *apixv1.ConversionResponse
 }
 
-func conversionHandler(rootLogger *zap.SugaredLogger, stats StatsReporter, c ConversionController) http.HandlerFunc {
+func conversionHandler(stats StatsReporter, c ConversionController) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var ttStart = time.Now()
-		logger := rootLogger
+		logger := logging.FromContext(r.Context())
 		logger.Infof("Webhook ServeHTTP request=%#v", r)
 
 		var review apixv1.ConversionReview
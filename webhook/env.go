@@ -32,6 +32,10 @@ const (
 	secretNameEnvKey = "WEBHOOK_SECRET_NAME" //nolint:gosec // This is not a hardcoded credential
 
 	tlsMinVersionEnvKey = "WEBHOOK_TLS_MIN_VERSION"
+
+	tlsCipherSuitesEnvKey = "WEBHOOK_TLS_CIPHER_SUITES"
+
+	tlsCurvePreferencesEnvKey = "WEBHOOK_TLS_CURVE_PREFERENCES"
 )
 
 // PortFromEnv returns the webhook port set by portEnvKey, or default port if env var is not set.
@@ -82,3 +86,33 @@ func TLSMinVersionFromEnv(defaultTLSMinVersion uint16) uint16 {
 		panic(fmt.Sprintf("the environment variable %q has to be either '1.2' or '1.3'", tlsMinVersionEnvKey))
 	}
 }
+
+// TLSCipherSuitesFromEnv returns the cipher suites set by
+// tlsCipherSuitesEnvKey as a comma separated list of names, see
+// ParseCipherSuites, or the default cipher suites if the env var is not set.
+func TLSCipherSuitesFromEnv(defaultCipherSuites []uint16) []uint16 {
+	cipherSuites := os.Getenv(tlsCipherSuitesEnvKey)
+	if cipherSuites == "" {
+		return defaultCipherSuites
+	}
+	ids, err := ParseCipherSuites(cipherSuites)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse the environment variable %q: %v", tlsCipherSuitesEnvKey, err))
+	}
+	return ids
+}
+
+// TLSCurvePreferencesFromEnv returns the curves set by
+// tlsCurvePreferencesEnvKey as a comma separated list of names, see
+// ParseCurvePreferences, or the default curves if the env var is not set.
+func TLSCurvePreferencesFromEnv(defaultCurves []tls.CurveID) []tls.CurveID {
+	curves := os.Getenv(tlsCurvePreferencesEnvKey)
+	if curves == "" {
+		return defaultCurves
+	}
+	ids, err := ParseCurvePreferences(curves)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse the environment variable %q: %v", tlsCurvePreferencesEnvKey, err))
+	}
+	return ids
+}
@@ -19,6 +19,8 @@ package webhook
 import (
 	"crypto/tls"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 const (
@@ -221,3 +223,55 @@ func TestTLSMinVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestTLSCipherSuites(t *testing.T) {
+	defaults := []uint16{tls.TLS_AES_128_GCM_SHA256}
+
+	t.Run("unset", func(t *testing.T) {
+		if got := TLSCipherSuitesFromEnv(defaults); !cmp.Equal(got, defaults) {
+			t.Errorf("TLSCipherSuitesFromEnv = %v, want: %v", got, defaults)
+		}
+	})
+	t.Run("valid", func(t *testing.T) {
+		t.Setenv(tlsCipherSuitesEnvKey, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+		want := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+		if got := TLSCipherSuitesFromEnv(defaults); !cmp.Equal(got, want) {
+			t.Errorf("TLSCipherSuitesFromEnv = %v, want: %v", got, want)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv(tlsCipherSuitesEnvKey, "TLS_FOO")
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Did not panic")
+			}
+		}()
+		TLSCipherSuitesFromEnv(defaults)
+	})
+}
+
+func TestTLSCurvePreferences(t *testing.T) {
+	defaults := []tls.CurveID{tls.X25519}
+
+	t.Run("unset", func(t *testing.T) {
+		if got := TLSCurvePreferencesFromEnv(defaults); !cmp.Equal(got, defaults) {
+			t.Errorf("TLSCurvePreferencesFromEnv = %v, want: %v", got, defaults)
+		}
+	})
+	t.Run("valid", func(t *testing.T) {
+		t.Setenv(tlsCurvePreferencesEnvKey, "CurveP256,CurveP384")
+		want := []tls.CurveID{tls.CurveP256, tls.CurveP384}
+		if got := TLSCurvePreferencesFromEnv(defaults); !cmp.Equal(got, want) {
+			t.Errorf("TLSCurvePreferencesFromEnv = %v, want: %v", got, want)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv(tlsCurvePreferencesEnvKey, "P192")
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Did not panic")
+			}
+		}()
+		TLSCurvePreferencesFromEnv(defaults)
+	})
+}
@@ -80,3 +80,17 @@ func getSecretDataKeyNamesOrDefault(sKey string, sCert string) (serverKey string
 	}
 	return serverKey, serverCert
 }
+
+func getOCSPStapleNameOrDefault(name string) string {
+	if name != "" {
+		return name
+	}
+	return resources.OCSPStaple
+}
+
+func getCACertNameOrDefault(name string) string {
+	if name != "" {
+		return name
+	}
+	return resources.CACert
+}
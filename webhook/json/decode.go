@@ -19,7 +19,11 @@ package json
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 var (
@@ -84,6 +88,105 @@ func Decode(bites []byte, target interface{}, disallowUnknownFields bool) error
 	return dec.Decode(target)
 }
 
+// DecodeUnknownFields parses the json byte array to the target object like
+// Decode does when unknown fields are allowed, dropping them, and returns
+// the paths of the unknown fields it dropped, e.g. "spec.template.foo", so
+// that they can be reported. As with Decode, the fields of the Object's
+// metadata are not checked.
+//
+// The unknown fields are detected by decoding strictly and located by
+// comparing the object with its round trip through the target's type, so
+// that unknown fields set to an empty value may only be reported by name.
+func DecodeUnknownFields(bites []byte, target interface{}) ([]string, error) {
+	if err := json.Unmarshal(bites, target); err != nil {
+		return nil, err
+	}
+
+	strict := reflect.New(reflect.TypeOf(target).Elem()).Interface()
+	strictErr := Decode(bites, strict, true /* disallowUnknownFields */)
+	if strictErr == nil {
+		return nil, nil
+	}
+	const unknownPrefix = "json: unknown field "
+	if !strings.HasPrefix(strictErr.Error(), unknownPrefix) {
+		// The lenient decoding succeeded, so this is not a decoding error.
+		return nil, nil
+	}
+
+	var in, out interface{}
+	if err := json.Unmarshal(bites, &in); err != nil {
+		return nil, err
+	}
+	roundTripped, err := json.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(roundTripped, &out); err != nil {
+		return nil, err
+	}
+	// The metadata is opaque to us and validated by the API server.
+	if m, ok := in.(map[string]interface{}); ok {
+		delete(m, "metadata")
+	}
+
+	paths := droppedFields(in, out, "")
+	if len(paths) == 0 {
+		// The unknown fields were only set to empty values.
+		paths = []string{strings.Trim(strings.TrimPrefix(strictErr.Error(), unknownPrefix), `"`)}
+	}
+	return paths, nil
+}
+
+// droppedFields returns the paths of the fields of in which are not empty
+// and missing from out.
+func droppedFields(in, out interface{}, path string) []string {
+	var paths []string
+	switch in := in.(type) {
+	case map[string]interface{}:
+		out, _ := out.(map[string]interface{})
+		for k, v := range in {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if o, ok := out[k]; ok {
+				paths = append(paths, droppedFields(v, o, p)...)
+			} else if !isEmpty(v) {
+				paths = append(paths, p)
+			}
+		}
+		sort.Strings(paths)
+	case []interface{}:
+		out, _ := out.([]interface{})
+		if len(in) != len(out) {
+			return nil
+		}
+		for i := range in {
+			paths = append(paths, droppedFields(in[i], out[i], fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return paths
+}
+
+// isEmpty returns whether v is dropped by omitempty when known.
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
 func findMetadataOffsets(bites []byte) (start, end int64, err error) {
 	start, end = -1, -1
 	level := 0
@@ -192,6 +192,83 @@ func TestDecode_UnmarshalMetadataFailed(t *testing.T) {
 	}
 }
 
+func TestDecodeUnknownFields(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	type spec struct {
+		Replicas int    `json:"replicas,omitempty"`
+		Items    []item `json:"items,omitempty"`
+	}
+	type object struct {
+		metav1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              spec `json:"spec"`
+	}
+
+	cases := []struct {
+		name  string
+		input string
+
+		want        object
+		wantUnknown []string
+		wantErr     bool
+	}{{
+		name:  "known fields",
+		input: `{"metadata":{"name":"some-name"},"spec":{"replicas":2,"items":[{"name":"a"}]}}`,
+		want: object{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-name"},
+			Spec:       spec{Replicas: 2, Items: []item{{Name: "a"}}},
+		},
+	}, {
+		name:  "unknown metadata field",
+		input: `{"metadata":{"name":"some-name","bomba":"boom"},"spec":{}}`,
+		want: object{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-name"},
+		},
+	}, {
+		name:  "unknown fields",
+		input: `{"bomba":"boom","spec":{"replica":2,"items":[{"name":"a"},{"name":"b","nmae":"c"}]}}`,
+		want: object{
+			Spec: spec{Items: []item{{Name: "a"}, {Name: "b"}}},
+		},
+		wantUnknown: []string{"bomba", "spec.items[1].nmae", "spec.replica"},
+	}, {
+		name:  "unknown nested object",
+		input: `{"spec":{"template":{"replicas":1}}}`,
+		want:  object{},
+
+		wantUnknown: []string{"spec.template"},
+	}, {
+		name:        "unknown empty field",
+		input:       `{"spec":{"replicas":1,"zero":0}}`,
+		want:        object{Spec: spec{Replicas: 1}},
+		wantUnknown: []string{"zero"},
+	}, {
+		name:    "malformed",
+		input:   `{"spec":{"replicas":"one"}}`,
+		wantErr: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := object{}
+			unknown, err := DecodeUnknownFields([]byte(tc.input), &got)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("DecodeUnknownFields() = %v, wanted error: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error("DecodeUnknownFields() object (-want, +got):", diff)
+			}
+			if diff := cmp.Diff(tc.wantUnknown, unknown); diff != "" {
+				t.Error("DecodeUnknownFields() unknown fields (-want, +got):", diff)
+			}
+		})
+	}
+}
+
 type fixture struct {
 	// Our decoder doesn't support `inline` that's a sig.k8s.io/yaml feature
 	// So we skip parsing this property
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Listener serves some of the webhook's controllers on their own port and
+// serving certificate, apart from the ones served on Options.Port, e.g. to
+// keep the conversion traffic apart from the admission traffic.
+type Listener struct {
+	// Port where the listener serves.
+	Port int
+
+	// Paths are the paths of the controllers served on this listener
+	// instead of Options.Port.
+	Paths []string
+
+	// SecretName is the name of the k8s secret in the system namespace
+	// holding the listener's server key/cert, under the same data keys as
+	// the webhook's secret. The certificates controller does not generate
+	// it.
+	// If no SecretName is provided, then the listener serves without TLS.
+	SecretName string
+
+	// CASecretName is the name of the k8s secret holding the CA cert which
+	// signed the listener's server cert, under the CA cert data key.
+	// Default value is SecretName if no value is passed.
+	CASecretName string
+}
+
+// listener is the serving state of a Listener.
+type listener struct {
+	Listener
+
+	mux http.ServeMux
+
+	// The TLS configuration to use for serving (or nil for non-TLS)
+	tlsConfig *tls.Config
+
+	// testListener is only used in testing so we don't get port conflicts
+	testListener net.Listener
+}
+
+func (l *listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveJSON(&l.mux, w, r)
+}
+
+// ListenerFor returns the listener serving the controller with the given
+// path, or nil if it is served on Port.
+func (o *Options) ListenerFor(path string) *Listener {
+	for i := range o.Listeners {
+		for _, p := range o.Listeners[i].Paths {
+			if p == path {
+				return &o.Listeners[i]
+			}
+		}
+	}
+	return nil
+}
+
+// CABundleSecretNameFor returns the name of the secret holding the CA cert
+// which signed the serving certificate of the controller with the given
+// path, considering the Listeners.
+func (o *Options) CABundleSecretNameFor(path string) string {
+	if l := o.ListenerFor(path); l != nil {
+		if l.CASecretName != "" {
+			return l.CASecretName
+		}
+		return l.SecretName
+	}
+	return o.CABundleSecretName()
+}
+
+// validateListeners checks that the listeners use distinct ports and paths.
+func validateListeners(o *Options) error {
+	ports := map[int]struct{}{o.Port: {}}
+	paths := make(map[string]struct{})
+	for _, l := range o.Listeners {
+		if l.Port == 0 {
+			return fmt.Errorf("listener for paths %v has no port", l.Paths)
+		}
+		if _, ok := ports[l.Port]; ok {
+			return fmt.Errorf("port %d is served by several listeners", l.Port)
+		}
+		ports[l.Port] = struct{}{}
+		for _, p := range l.Paths {
+			if _, ok := paths[p]; ok {
+				return fmt.Errorf("path %q is served by several listeners", p)
+			}
+			paths[p] = struct{}{}
+		}
+	}
+	return nil
+}
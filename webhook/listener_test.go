@@ -0,0 +1,218 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/sync/errgroup"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/system"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+)
+
+func TestCABundleSecretNameFor(t *testing.T) {
+	opts := &Options{
+		SecretName: "webhook-certs",
+		Listeners: []Listener{{
+			Port:       8444,
+			Paths:      []string{"/conversion"},
+			SecretName: "conversion-certs",
+		}, {
+			Port:         8445,
+			Paths:        []string{"/other"},
+			SecretName:   "other-certs",
+			CASecretName: "other-ca",
+		}},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{{
+		path: "/defaulting",
+		want: "webhook-certs",
+	}, {
+		path: "/conversion",
+		want: "conversion-certs",
+	}, {
+		path: "/other",
+		want: "other-ca",
+	}}
+	for _, test := range tests {
+		if got := opts.CABundleSecretNameFor(test.path); got != test.want {
+			t.Errorf("CABundleSecretNameFor(%q) = %s, wanted %s", test.path, got, test.want)
+		}
+	}
+}
+
+func TestValidateListeners(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners []Listener
+		wantErr   bool
+	}{{
+		name: "valid",
+		listeners: []Listener{{
+			Port:  8444,
+			Paths: []string{"/conversion"},
+		}},
+	}, {
+		name: "missing port",
+		listeners: []Listener{{
+			Paths: []string{"/conversion"},
+		}},
+		wantErr: true,
+	}, {
+		name: "webhook port",
+		listeners: []Listener{{
+			Port:  8443,
+			Paths: []string{"/conversion"},
+		}},
+		wantErr: true,
+	}, {
+		name: "duplicate path",
+		listeners: []Listener{{
+			Port:  8444,
+			Paths: []string{"/conversion"},
+		}, {
+			Port:  8445,
+			Paths: []string{"/conversion"},
+		}},
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateListeners(&Options{Port: 8443, Listeners: test.listeners})
+			if got := err != nil; got != test.wantErr {
+				t.Errorf("validateListeners() = %v, wanted error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestListenerServesItsControllers(t *testing.T) {
+	cc := &fixedConversionController{
+		path:     "/conversion",
+		response: &apixv1.ConversionResponse{},
+	}
+
+	opts := newDefaultOptions()
+	opts.Listeners = []Listener{{
+		Port:       8444,
+		Paths:      []string{cc.Path()},
+		SecretName: "conversion-certs",
+	}}
+	ctx, wh, cancel := newNonRunningTestWebhook(t, opts, cc)
+
+	// ephemeral ports
+	webhookListener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal("unable to get ephemeral port: ", err)
+	}
+	wh.testListener = webhookListener
+	conversionListener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal("unable to get ephemeral port: ", err)
+	}
+	wh.listeners[0].testListener = conversionListener
+
+	kubeClient := kubeclient.Get(ctx)
+	for _, name := range []string{opts.SecretName, "conversion-certs"} {
+		secret, err := certresources.MakeSecret(ctx, name, system.Namespace(), opts.ServiceName)
+		if err != nil {
+			t.Fatal("MakeSecret() =", err)
+		}
+		if _, err := kubeClient.CoreV1().Secrets(secret.Namespace).Create(context.Background(), secret, metav1.CreateOptions{}); err != nil {
+			t.Fatal("Failed to create secret:", err)
+		}
+	}
+	resetMetrics()
+
+	eg, _ := errgroup.WithContext(ctx)
+	eg.Go(func() error { return wh.Run(ctx.Done()) })
+	defer func() {
+		cancel()
+		if err := eg.Wait(); err != nil {
+			t.Error("Unable to run controller:", err)
+		}
+	}()
+
+	webhookURL, conversionURL := webhookListener.Addr().String(), conversionListener.Addr().String()
+	for _, serverURL := range []string{webhookURL, conversionURL} {
+		if err := waitForServerAvailable(t, serverURL, testTimeout); err != nil {
+			t.Fatal("waitForServerAvailable() =", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		serverURL  string
+		secretName string
+		wantBody   string
+	}{{
+		name:       "conversion listener",
+		serverURL:  conversionURL,
+		secretName: "conversion-certs",
+		// The empty body reaches the conversion controller.
+		wantBody: "could not decode body",
+	}, {
+		name:       "webhook port",
+		serverURL:  webhookURL,
+		secretName: opts.SecretName,
+		wantBody:   "no controller registered",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tlsClient, err := createSecureTLSClient(t, kubeClient, &Options{SecretName: test.secretName})
+			if err != nil {
+				t.Fatal("createSecureTLSClient() =", err)
+			}
+
+			req, err := http.NewRequest("POST", fmt.Sprintf("https://%s%s", test.serverURL, cc.Path()), strings.NewReader(""))
+			if err != nil {
+				t.Fatal("http.NewRequest() =", err)
+			}
+			req.Header.Add("Content-Type", "application/json")
+
+			response, err := tlsClient.Do(req)
+			if err != nil {
+				t.Fatal("Failed to get response:", err)
+			}
+			defer response.Body.Close()
+
+			if got, want := response.StatusCode, http.StatusBadRequest; got != want {
+				t.Errorf("Response status code = %v, wanted %v", got, want)
+			}
+			body, err := io.ReadAll(response.Body)
+			if err != nil {
+				t.Fatal("Failed to read response body:", err)
+			}
+			if !strings.Contains(string(body), test.wantBody) {
+				t.Errorf("Response body = %q, wanted it to contain %q", body, test.wantBody)
+			}
+		})
+	}
+}
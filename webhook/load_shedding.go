@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"knative.dev/pkg/logging"
+)
+
+// LoadSheddingPolicy decides how admission requests are answered when the
+// webhook is saturated.
+type LoadSheddingPolicy int
+
+const (
+	// RejectWhenSaturated answers admission requests with 429 Too Many
+	// Requests, which the API server handles per the failurePolicy of the
+	// webhook.
+	RejectWhenSaturated LoadSheddingPolicy = iota
+
+	// AllowWhenSaturated admits the requests with a warning, without calling
+	// the admission controllers.
+	AllowWhenSaturated
+)
+
+// SaturatedWarning is the warning of the admission requests allowed by the
+// AllowWhenSaturated policy.
+const SaturatedWarning = "the webhook is saturated, the request was admitted without being checked"
+
+const (
+	admissionRequestType  = "admission"
+	conversionRequestType = "conversion"
+
+	shedActionReject = "reject"
+	shedActionAllow  = "allow"
+)
+
+// loadShedder limits the number of requests handled concurrently, and sheds
+// the requests which don't get to be handled within the queue timeout.
+type loadShedder struct {
+	slots   chan struct{}
+	timeout time.Duration
+	policy  LoadSheddingPolicy
+}
+
+// newLoadShedder returns a loadShedder per the options, or nil if requests
+// are not limited.
+func newLoadShedder(opts *Options) *loadShedder {
+	if opts.MaxInFlightRequests <= 0 {
+		return nil
+	}
+	return &loadShedder{
+		slots:   make(chan struct{}, opts.MaxInFlightRequests),
+		timeout: opts.QueueTimeout,
+		policy:  opts.LoadSheddingPolicy,
+	}
+}
+
+// acquire waits for an in-flight slot up to the queue timeout, and returns
+// whether it got one. The slot must then be released with release.
+func (l *loadShedder) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if l.timeout <= 0 {
+		return false
+	}
+
+	t := time.NewTimer(l.timeout)
+	defer t.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-t.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *loadShedder) release() {
+	<-l.slots
+}
+
+// admission limits the admission requests served by next, shedding them per
+// the policy.
+func (l *loadShedder) admission(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.acquire(r.Context()) {
+			defer l.release()
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		logger := logging.FromContext(r.Context())
+		if l.policy != AllowWhenSaturated {
+			logger.Warn("Webhook is saturated, rejecting admission request")
+			reportShedRequest(r.Context(), admissionRequestType, shedActionReject)
+			tooManyRequests(w)
+			return
+		}
+
+		var review admissionv1.AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprint("could not decode body:", err), http.StatusBadRequest)
+			return
+		}
+		logger.Warnw("Webhook is saturated, allowing admission request", AdmissionReviewUID, review.Request.UID)
+		reportShedRequest(r.Context(), admissionRequestType, shedActionAllow)
+
+		response := admissionv1.AdmissionReview{
+			TypeMeta: review.TypeMeta,
+			Response: &admissionv1.AdmissionResponse{
+				UID:      review.Request.UID,
+				Allowed:  true,
+				Warnings: []string{SaturatedWarning},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, fmt.Sprint("could not encode response:", err), http.StatusInternalServerError)
+		}
+	})
+}
+
+// conversion limits the conversion requests served by next. Conversions
+// can't be skipped, so they are always rejected when shed.
+func (l *loadShedder) conversion(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.acquire(r.Context()) {
+			defer l.release()
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context()).Warn("Webhook is saturated, rejecting conversion request")
+		reportShedRequest(r.Context(), conversionRequestType, shedActionReject)
+		tooManyRequests(w)
+	})
+}
+
+func tooManyRequests(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, "the webhook is saturated, try again later", http.StatusTooManyRequests)
+}
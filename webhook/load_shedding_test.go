@@ -0,0 +1,190 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+// blockingHandler serves requests once they are unblocked.
+type blockingHandler struct {
+	entered chan struct{}
+	unblock chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		entered: make(chan struct{}, 10),
+		unblock: make(chan struct{}),
+	}
+}
+
+func (b *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.entered <- struct{}{}
+	<-b.unblock
+	w.WriteHeader(http.StatusOK)
+}
+
+func newAdmissionRequest(t *testing.T) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{UID: "the-uid"},
+	})
+	if err != nil {
+		t.Fatal("Marshal() =", err)
+	}
+	return httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+}
+
+// saturate serves a request blocked in the handler, returning a channel
+// closed once it is served.
+func saturate(t *testing.T, h http.Handler, b *blockingHandler) <-chan struct{} {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(httptest.NewRecorder(), newAdmissionRequest(t))
+	}()
+	select {
+	case <-b.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Request was never handled")
+	}
+	return done
+}
+
+func TestNewLoadShedder(t *testing.T) {
+	if got := newLoadShedder(&Options{}); got != nil {
+		t.Errorf("newLoadShedder() = %v, wanted nil without MaxInFlightRequests", got)
+	}
+	handler := newBlockingHandler()
+	var l *loadShedder
+	if got := l.admission(handler); got != handler {
+		t.Error("admission() wrapped the handler without MaxInFlightRequests")
+	}
+	if got := l.conversion(handler); got != handler {
+		t.Error("conversion() wrapped the handler without MaxInFlightRequests")
+	}
+}
+
+func TestLoadSheddingReject(t *testing.T) {
+	setup()
+	b := newBlockingHandler()
+	l := newLoadShedder(&Options{MaxInFlightRequests: 1})
+
+	for _, tc := range []struct {
+		name        string
+		handler     http.Handler
+		requestType string
+	}{{
+		name:        "admission",
+		handler:     l.admission(b),
+		requestType: admissionRequestType,
+	}, {
+		name:        "conversion",
+		handler:     l.conversion(b),
+		requestType: conversionRequestType,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			setup()
+			done := saturate(t, tc.handler, b)
+
+			rec := httptest.NewRecorder()
+			tc.handler.ServeHTTP(rec, newAdmissionRequest(t))
+			if got, want := rec.Code, http.StatusTooManyRequests; got != want {
+				t.Errorf("Status = %d, wanted %d", got, want)
+			}
+			if got := rec.Header().Get("Retry-After"); got == "" {
+				t.Error("Retry-After header is missing")
+			}
+			metricstest.CheckCountData(t, shedRequestCountName, map[string]string{
+				requestTypeKey.Name(): tc.requestType,
+				shedActionKey.Name():  shedActionReject,
+			}, 1)
+
+			b.unblock <- struct{}{}
+			<-done
+		})
+	}
+}
+
+func TestLoadSheddingAllow(t *testing.T) {
+	setup()
+	b := newBlockingHandler()
+	h := newLoadShedder(&Options{
+		MaxInFlightRequests: 1,
+		LoadSheddingPolicy:  AllowWhenSaturated,
+	}).admission(b)
+	done := saturate(t, h, b)
+	defer func() {
+		b.unblock <- struct{}{}
+		<-done
+	}()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newAdmissionRequest(t))
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("Status = %d, wanted %d", got, want)
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(rec.Body).Decode(&review); err != nil {
+		t.Fatal("Decode() =", err)
+	}
+	want := &admissionv1.AdmissionResponse{
+		UID:      "the-uid",
+		Allowed:  true,
+		Warnings: []string{SaturatedWarning},
+	}
+	if diff := cmp.Diff(want, review.Response); diff != "" {
+		t.Error("Response (-want, +got) =", diff)
+	}
+	metricstest.CheckCountData(t, shedRequestCountName, map[string]string{
+		requestTypeKey.Name(): admissionRequestType,
+		shedActionKey.Name():  shedActionAllow,
+	}, 1)
+}
+
+func TestLoadSheddingQueue(t *testing.T) {
+	setup()
+	b := newBlockingHandler()
+	h := newLoadShedder(&Options{
+		MaxInFlightRequests: 1,
+		QueueTimeout:        time.Minute,
+	}).admission(b)
+	done := saturate(t, h, b)
+
+	// The queued request is handled once the in-flight one is done.
+	go func() {
+		b.unblock <- struct{}{}
+	}()
+	queuedDone := saturate(t, h, b)
+	<-done
+	b.unblock <- struct{}{}
+	<-queuedDone
+
+	metricstest.AssertNoMetric(t, shedRequestCountName)
+}
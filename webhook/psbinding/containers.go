@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package psbinding
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/kmeta"
+)
+
+// ContainersAnnotationPrefix prefixes the annotations recording on the
+// subjects of a ContainerSelectable the names of the containers it bound, so
+// that Undo applies to these containers even if its selector changed since.
+const ContainersAnnotationPrefix = "containers.psbinding.knative.dev/"
+
+// ContainerSelector selects by name the containers and init containers of
+// the subjects a Bindable applies to.
+type ContainerSelector struct {
+	// Include lists the names of the containers to bind.
+	// All the containers are bound if empty.
+	Include []string
+
+	// Exclude lists the names of the containers not to bind, even if they
+	// are included.
+	Exclude []string
+}
+
+// Matches returns whether the named container is selected.
+func (cs ContainerSelector) Matches(name string) bool {
+	for _, n := range cs.Exclude {
+		if n == name {
+			return false
+		}
+	}
+	if len(cs.Include) == 0 {
+		return true
+	}
+	for _, n := range cs.Include {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainerSelectable is implemented by Bindables which only bind some of
+// the containers of their subjects. Their Do and Undo methods are passed the
+// subjects with only the selected containers and init containers, and must
+// not add nor remove containers.
+type ContainerSelectable interface {
+	Bindable
+
+	// ContainerSelector returns the selector of the containers to bind.
+	ContainerSelector() ContainerSelector
+}
+
+// DoMutation returns the Mutation performing the Do method of the Bindable,
+// on the containers it selects if it is a ContainerSelectable.
+func DoMutation(fb Bindable) Mutation {
+	cs, ok := fb.(ContainerSelectable)
+	if !ok {
+		return fb.Do
+	}
+	return func(ctx context.Context, ps *duckv1.WithPod) {
+		key := containersAnnotation(fb)
+		selector := cs.ContainerSelector()
+
+		// Undo the binding of the containers which are no longer selected.
+		if bound, ok := ps.Annotations[key]; ok {
+			names := containerNames(bound)
+			onContainers(ctx, ps, func(name string) bool {
+				return names.Has(name) && !selector.Matches(name)
+			}, fb.Undo)
+		}
+
+		bound := onContainers(ctx, ps, selector.Matches, fb.Do)
+		if ps.Annotations == nil {
+			ps.Annotations = make(map[string]string, 1)
+		}
+		ps.Annotations[key] = strings.Join(bound, ",")
+	}
+}
+
+// UndoMutation returns the Mutation performing the Undo method of the
+// Bindable, on the containers it bound if it is a ContainerSelectable.
+func UndoMutation(fb Bindable) Mutation {
+	cs, ok := fb.(ContainerSelectable)
+	if !ok {
+		return fb.Undo
+	}
+	return func(ctx context.Context, ps *duckv1.WithPod) {
+		key := containersAnnotation(fb)
+		matches := cs.ContainerSelector().Matches
+		if bound, ok := ps.Annotations[key]; ok {
+			matches = containerNames(bound).Has
+		}
+
+		onContainers(ctx, ps, matches, fb.Undo)
+		delete(ps.Annotations, key)
+		if len(ps.Annotations) == 0 {
+			ps.Annotations = nil
+		}
+	}
+}
+
+// onContainers applies the mutation to the subject with only the matching
+// containers and init containers, and returns the sorted names of these.
+func onContainers(ctx context.Context, ps *duckv1.WithPod, matches func(string) bool, mutation Mutation) []string {
+	spec := &ps.Spec.Template.Spec
+	initContainers, containers := spec.InitContainers, spec.Containers
+
+	var names []string
+	spec.InitContainers, names = filterContainers(initContainers, matches, names)
+	spec.Containers, names = filterContainers(containers, matches, names)
+
+	mutation(ctx, ps)
+
+	spec.InitContainers = mergeContainers(initContainers, spec.InitContainers, matches)
+	spec.Containers = mergeContainers(containers, spec.Containers, matches)
+
+	sort.Strings(names)
+	return names
+}
+
+func filterContainers(containers []corev1.Container, matches func(string) bool, names []string) ([]corev1.Container, []string) {
+	var selected []corev1.Container
+	for _, c := range containers {
+		if matches(c.Name) {
+			selected = append(selected, c)
+			names = append(names, c.Name)
+		}
+	}
+	return selected, names
+}
+
+// mergeContainers puts the mutated containers back in place of the matching
+// ones among the original containers.
+func mergeContainers(orig, mutated []corev1.Container, matches func(string) bool) []corev1.Container {
+	if len(orig) == 0 && len(mutated) == 0 {
+		return orig
+	}
+	ret := make([]corev1.Container, 0, len(orig))
+	j := 0
+	for _, c := range orig {
+		if !matches(c.Name) {
+			ret = append(ret, c)
+		} else if j < len(mutated) {
+			ret = append(ret, mutated[j])
+			j++
+		}
+	}
+	return append(ret, mutated[j:]...)
+}
+
+func containersAnnotation(fb Bindable) string {
+	return ContainersAnnotationPrefix + kmeta.ChildName(strings.ToLower(fb.GetGroupVersionKind().Kind)+"."+fb.GetName(), "")
+}
+
+func containerNames(annotation string) sets.String {
+	if annotation == "" {
+		return sets.NewString()
+	}
+	return sets.NewString(strings.Split(annotation, ",")...)
+}
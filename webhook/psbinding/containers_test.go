@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package psbinding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	. "knative.dev/pkg/testing/duck"
+)
+
+type selectableBindable struct {
+	*TestBindable
+	selector ContainerSelector
+}
+
+var _ ContainerSelectable = (*selectableBindable)(nil)
+
+func (sb *selectableBindable) ContainerSelector() ContainerSelector {
+	return sb.selector
+}
+
+func TestContainerSelectorMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector ContainerSelector
+		want     map[string]bool
+	}{{
+		name: "empty selects all",
+		want: map[string]bool{"a": true, "b": true},
+	}, {
+		name:     "include",
+		selector: ContainerSelector{Include: []string{"a"}},
+		want:     map[string]bool{"a": true, "b": false},
+	}, {
+		name:     "exclude",
+		selector: ContainerSelector{Exclude: []string{"a"}},
+		want:     map[string]bool{"a": false, "b": true},
+	}, {
+		name: "exclude wins",
+		selector: ContainerSelector{
+			Include: []string{"a", "b"},
+			Exclude: []string{"a"},
+		},
+		want: map[string]bool{"a": false, "b": true, "c": false},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, want := range test.want {
+				if got := test.selector.Matches(name); got != want {
+					t.Errorf("Matches(%q) = %v, wanted %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestContainerSelectableMutations(t *testing.T) {
+	fb := &selectableBindable{
+		TestBindable: &TestBindable{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+			},
+			Spec: TestBindableSpec{
+				Foo: "baz",
+			},
+		},
+		selector: ContainerSelector{Include: []string{"init", "sidecar"}},
+	}
+	key := containersAnnotation(fb)
+	foo := []corev1.EnvVar{{Name: "FOO", Value: "baz"}}
+
+	withPod := func(annotations map[string]string, init, first, sidecar, last []corev1.EnvVar) *duckv1.WithPod {
+		return &duckv1.WithPod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec: duckv1.WithPodSpec{
+				Template: duckv1.PodSpecable{
+					Spec: corev1.PodSpec{
+						InitContainers: []corev1.Container{{Name: "init", Env: init}},
+						Containers: []corev1.Container{
+							{Name: "first", Env: first},
+							{Name: "sidecar", Env: sidecar},
+							{Name: "last", Env: last},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	ctx := context.Background()
+	ps := withPod(nil, nil, nil, nil, nil)
+
+	DoMutation(fb)(ctx, ps)
+	want := withPod(map[string]string{key: "init,sidecar"}, foo, nil, foo, nil)
+	if !cmp.Equal(ps, want, cmpopts.EquateEmpty()) {
+		t.Error("DoMutation (-want, +got):", cmp.Diff(want, ps, cmpopts.EquateEmpty()))
+	}
+
+	// Changing the selector rebinds the containers.
+	fb.selector = ContainerSelector{Exclude: []string{"init", "sidecar"}}
+	DoMutation(fb)(ctx, ps)
+	want = withPod(map[string]string{key: "first,last"}, nil, foo, nil, foo)
+	if !cmp.Equal(ps, want, cmpopts.EquateEmpty()) {
+		t.Error("DoMutation after selector change (-want, +got):", cmp.Diff(want, ps, cmpopts.EquateEmpty()))
+	}
+
+	// Undo applies to the recorded containers, whatever the selector.
+	fb.selector = ContainerSelector{Include: []string{"none"}}
+	UndoMutation(fb)(ctx, ps)
+	want = withPod(nil, nil, nil, nil, nil)
+	if !cmp.Equal(ps, want, cmpopts.EquateEmpty()) {
+		t.Error("UndoMutation (-want, +got):", cmp.Diff(want, ps, cmpopts.EquateEmpty()))
+	}
+}
+
+func TestUndoMutationWithoutRecord(t *testing.T) {
+	fb := &selectableBindable{
+		TestBindable: &TestBindable{
+			ObjectMeta: metav1.ObjectMeta{Name: "bar"},
+		},
+		selector: ContainerSelector{Include: []string{"a"}},
+	}
+	ps := &duckv1.WithPod{
+		Spec: duckv1.WithPodSpec{
+			Template: duckv1.PodSpecable{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "a",
+						Env:  []corev1.EnvVar{{Name: "FOO"}},
+					}, {
+						Name: "b",
+						Env:  []corev1.EnvVar{{Name: "FOO"}},
+					}},
+				},
+			},
+		},
+	}
+
+	UndoMutation(fb)(context.Background(), ps)
+	want := []corev1.Container{{
+		Name: "a",
+		Env:  []corev1.EnvVar{},
+	}, {
+		Name: "b",
+		Env:  []corev1.EnvVar{{Name: "FOO"}},
+	}}
+	if got := ps.Spec.Template.Spec.Containers; !cmp.Equal(got, want) {
+		t.Error("UndoMutation (-want, +got):", cmp.Diff(want, got))
+	}
+}
@@ -86,7 +86,8 @@ func NewAdmissionController(
 	options := webhook.GetOptions(ctx)
 
 	// Construct the reconciler for the mutating webhook configuration.
-	wh := NewReconciler(name, path, options.SecretName, client, mwhInformer.Lister(), secretInformer.Lister(), withContext, reconcilerOptions...)
+	reconcilerOptions = append([]ReconcilerOption{WithCACertKey(options.CABundleKey())}, reconcilerOptions...)
+	wh := NewReconciler(name, path, options.CABundleSecretNameFor(path), client, mwhInformer.Lister(), secretInformer.Lister(), withContext, reconcilerOptions...)
 	c := controller.NewContext(ctx, wh, controller.ControllerOptions{WorkQueueName: name, Logger: logging.FromContext(ctx).Named(name)})
 
 	// Enqueue a sentinel when we become leader.
@@ -56,6 +56,13 @@ func WithSelector(s metav1.LabelSelector) ReconcilerOption {
 	}
 }
 
+// WithCACertKey specifies the data key holding the CA cert in the secret.
+func WithCACertKey(key string) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.caCertKey = key
+	}
+}
+
 func NewReconciler(
 	name, path, secretName string,
 	client kubernetes.Interface,
@@ -77,6 +84,7 @@ func NewReconciler(
 		MWHLister:    mwhLister,
 		SecretLister: secretLister,
 		selector:     ExclusionSelector, // Use ExclusionSelector by default.
+		caCertKey:    certresources.CACert,
 	}
 
 	// Apply options.
@@ -113,7 +121,8 @@ type Reconciler struct {
 	// respective tasks.
 	WithContext BindableContext
 
-	selector metav1.LabelSelector
+	selector  metav1.LabelSelector
+	caCertKey string
 
 	index index
 }
@@ -153,9 +162,9 @@ func (ac *Reconciler) Reconcile(ctx context.Context, key string) error {
 		logging.FromContext(ctx).Errorw("Error fetching secret", zap.Error(err))
 		return err
 	}
-	caCert, ok := secret.Data[certresources.CACert]
+	caCert, ok := secret.Data[ac.caCertKey]
 	if !ok {
-		return fmt.Errorf("secret %q is missing %q key", ac.SecretName, certresources.CACert)
+		return fmt.Errorf("secret %q is missing %q key", ac.SecretName, ac.caCertKey)
 	}
 
 	// Reconcile the webhook configuration.
@@ -214,14 +223,16 @@ func (ac *Reconciler) Admit(ctx context.Context, request *admissionv1.AdmissionR
 
 		// Mutate the copy of the subject state according to the deletion state of the Bindable.
 		if fb.GetDeletionTimestamp() != nil {
-			fb.Undo(bindingContext, mutated)
+			UndoMutation(fb)(bindingContext, mutated)
 		} else {
-			fb.Do(bindingContext, mutated)
+			DoMutation(fb)(bindingContext, mutated)
 		}
 	}
 
 	// Synthesize a patch from the changes and return it in our AdmissionResponse
+	_, span := webhook.StartPatchSpan(ctx)
 	patchBytes, err := duck.CreateBytePatch(orig, mutated)
+	span.End()
 	if err != nil {
 		return webhook.MakeErrorStatus("unable to create patch with binding: %v", err)
 	}
@@ -179,7 +179,7 @@ func (r *BaseReconciler) reconcile(ctx context.Context, fb Bindable) error {
 	}
 
 	// Perform our Binding's Do() method on the subject(s) of the Binding.
-	if err := r.ReconcileSubject(ctx, fb, fb.Do); err != nil {
+	if err := r.ReconcileSubject(ctx, fb, DoMutation(fb)); err != nil {
 		return err
 	}
 	if r.SubResourcesReconciler != nil {
@@ -207,7 +207,7 @@ func (r *BaseReconciler) ReconcileDeletion(ctx context.Context, fb Bindable) err
 	// If it is our turn to finalize the Binding, then first undo the effect
 	// of our Binding on the resource.
 	logging.FromContext(ctx).Info("Removing the binding for ", fb.GetName())
-	if err := r.ReconcileSubject(ctx, fb, fb.Undo); apierrs.IsNotFound(err) || apierrs.IsForbidden(err) {
+	if err := r.ReconcileSubject(ctx, fb, UndoMutation(fb)); apierrs.IsNotFound(err) || apierrs.IsForbidden(err) {
 		// If the subject has been deleted, then there is nothing to undo.
 	} else if err != nil {
 		return err
@@ -19,9 +19,12 @@ package conversion
 import (
 	"context"
 
+	apixclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apixv1informers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/apis"
 	apixclient "knative.dev/pkg/client/injection/apiextensions/client"
@@ -100,9 +103,21 @@ func NewConversionController(
 }
 
 func newController(ctx context.Context, optsFunc ...OptionFunc) *controller.Impl {
-	secretInformer := secretinformer.Get(ctx)
-	crdInformer := crdinformer.Get(ctx)
-	client := apixclient.Get(ctx)
+	return NewConversionControllerWithInformers(ctx,
+		apixclient.Get(ctx), crdinformer.Get(ctx), secretinformer.Get(ctx), optsFunc...)
+}
+
+// NewConversionControllerWithInformers returns the controller of
+// NewConversionController without relying on injection, from the given client
+// and informers. The secret informer must cover the system namespace. The
+// caller is responsible for running the informers, see webhook.Standalone.
+func NewConversionControllerWithInformers(
+	ctx context.Context,
+	client apixclientset.Interface,
+	crdInformer apixv1informers.CustomResourceDefinitionInformer,
+	secretInformer corev1informers.SecretInformer,
+	optsFunc ...OptionFunc,
+) *controller.Impl {
 	woptions := webhook.GetOptions(ctx)
 
 	opts := &options{}
@@ -125,7 +140,8 @@ func newController(ctx context.Context, optsFunc ...OptionFunc) *controller.Impl
 
 		kinds:       opts.kinds,
 		path:        opts.path,
-		secretName:  woptions.SecretName,
+		secretName:  woptions.CABundleSecretNameFor(opts.path),
+		caCertKey:   woptions.CABundleKey(),
 		withContext: opts.wc,
 
 		client:       client,
@@ -154,7 +170,7 @@ func newController(ctx context.Context, optsFunc ...OptionFunc) *controller.Impl
 
 		// Reconcile when the cert bundle changes.
 		secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
-			FilterFunc: controller.FilterWithNameAndNamespace(system.Namespace(), woptions.SecretName),
+			FilterFunc: controller.FilterWithNameAndNamespace(system.Namespace(), woptions.CABundleSecretNameFor(opts.path)),
 			Handler:    controller.HandleAll(sentinel),
 		})
 	}
@@ -19,7 +19,9 @@ package conversion
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -54,11 +56,17 @@ func (r *reconciler) Convert(
 	result := make([]runtime.RawExtension, 0, len(req.Objects))
 
 	for _, obj := range req.Objects {
-		converted, err := r.convert(ctx, obj, req.DesiredAPIVersion)
+		start := time.Now()
+		converted, inGVK, err := r.convert(ctx, obj, req.DesiredAPIVersion)
+		reportConversion(ctx, inGVK, req.DesiredAPIVersion, err, time.Since(start))
 		if err != nil {
 			logging.FromContext(ctx).Errorw("Conversion failed", zap.Error(err))
 			res.Result.Status = metav1.StatusFailure
 			res.Result.Message = err.Error()
+			if details := failureDetails(obj, inGVK, err); details != nil {
+				res.Result.Reason = metav1.StatusReasonInvalid
+				res.Result.Details = details
+			}
 			break
 		}
 
@@ -73,37 +81,36 @@ func (r *reconciler) convert(
 	ctx context.Context,
 	inRaw runtime.RawExtension,
 	targetVersion string,
-) (runtime.RawExtension, error) {
+) (ret runtime.RawExtension, inGVK schema.GroupVersionKind, err error) {
 	logger := logging.FromContext(ctx)
-	var ret runtime.RawExtension
 
-	inGVK, err := parseGVK(inRaw)
+	inGVK, err = parseGVK(inRaw)
 	if err != nil {
-		return ret, err
+		return ret, schema.GroupVersionKind{}, err
 	}
 
 	inGK := inGVK.GroupKind()
 	conv, ok := r.kinds[inGK]
 	if !ok {
-		return ret, fmt.Errorf("no conversion support for type %s", formatGK(inGVK.GroupKind()))
+		return ret, inGVK, fmt.Errorf("no conversion support for type %s", formatGK(inGVK.GroupKind()))
 	}
 
 	outGVK, err := parseAPIVersion(targetVersion, inGK.Kind)
 	if err != nil {
-		return ret, err
+		return ret, inGVK, err
 	}
 
 	inZygote, ok := conv.Zygotes[inGVK.Version]
 	if !ok {
-		return ret, fmt.Errorf("conversion not supported for type %s", formatGVK(inGVK))
+		return ret, inGVK, fmt.Errorf("conversion not supported for type %s", formatGVK(inGVK))
 	}
 	outZygote, ok := conv.Zygotes[outGVK.Version]
 	if !ok {
-		return ret, fmt.Errorf("conversion not supported for type %s", formatGVK(outGVK))
+		return ret, inGVK, fmt.Errorf("conversion not supported for type %s", formatGVK(outGVK))
 	}
 	hubZygote, ok := conv.Zygotes[conv.HubVersion]
 	if !ok {
-		return ret, fmt.Errorf("conversion not supported for type %s", formatGK(inGVK.GroupKind()))
+		return ret, inGVK, fmt.Errorf("conversion not supported for type %s", formatGK(inGVK.GroupKind()))
 	}
 
 	in := inZygote.DeepCopyObject().(ConvertibleObject)
@@ -120,7 +127,7 @@ func (r *reconciler) convert(
 
 	// TODO(dprotaso) - potentially error on unknown fields
 	if err = json.Unmarshal(inRaw.Raw, &in); err != nil {
-		return ret, fmt.Errorf("unable to unmarshal input: %w", err)
+		return ret, inGVK, fmt.Errorf("unable to unmarshal input: %w", err)
 	}
 
 	if acc, err := kmeta.DeletionHandlingAccessor(in); err == nil {
@@ -135,13 +142,13 @@ func (r *reconciler) convert(
 	if inGVK.Version == conv.HubVersion {
 		hub = in
 	} else if err = hub.ConvertFrom(ctx, in); err != nil {
-		return ret, fmt.Errorf("conversion failed to version %s for type %s -  %w", outGVK.Version, formatGVK(inGVK), err)
+		return ret, inGVK, fmt.Errorf("conversion failed to version %s for type %s -  %w", outGVK.Version, formatGVK(inGVK), err)
 	}
 
 	if outGVK.Version == conv.HubVersion {
 		out = hub
 	} else if err = hub.ConvertTo(ctx, out); err != nil {
-		return ret, fmt.Errorf("conversion failed to version %s for type %s -  %w", outGVK.Version, formatGVK(inGVK), err)
+		return ret, inGVK, fmt.Errorf("conversion failed to version %s for type %s -  %w", outGVK.Version, formatGVK(inGVK), err)
 	}
 
 	out.GetObjectKind().SetGroupVersionKind(outGVK)
@@ -151,9 +158,47 @@ func (r *reconciler) convert(
 	}
 
 	if ret.Raw, err = json.Marshal(out); err != nil {
-		return ret, fmt.Errorf("unable to marshal output: %w", err)
+		return ret, inGVK, fmt.Errorf("unable to marshal output: %w", err)
 	}
-	return ret, nil
+	return ret, inGVK, nil
+}
+
+// failureDetails returns the details of a conversion which failed with field
+// errors, with a cause for each of the field paths which failed to convert,
+// or nil for other failures.
+func failureDetails(in runtime.RawExtension, gvk schema.GroupVersionKind, err error) *metav1.StatusDetails {
+	var fe *apis.FieldError
+	if !errors.As(err, &fe) {
+		return nil
+	}
+
+	details := &metav1.StatusDetails{
+		Group: gvk.Group,
+		Kind:  gvk.Kind,
+	}
+	var meta metav1.PartialObjectMetadata
+	if json.Unmarshal(in.Raw, &meta) == nil {
+		details.Name = meta.Name
+	}
+
+	for _, e := range fe.WrappedErrors() {
+		msg := e.Message
+		if e.Details != "" {
+			msg += ": " + e.Details
+		}
+		cause := metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: msg,
+		}
+		if len(e.Paths) == 0 {
+			details.Causes = append(details.Causes, cause)
+		}
+		for _, path := range e.Paths {
+			cause.Field = path
+			details.Causes = append(details.Causes, cause)
+		}
+	}
+	return details
 }
 
 func parseGVK(in runtime.RawExtension) (schema.GroupVersionKind, error) {
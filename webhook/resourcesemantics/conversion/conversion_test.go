@@ -630,3 +630,65 @@ func newConversionWithKinds(
 	controller := NewConversionController(ctx, webhookPath, kinds, nil)
 	return ctx, controller.Reconciler.(*reconciler)
 }
+
+func TestConversionFailureFieldErrors(t *testing.T) {
+	// v1 => error resource => v3
+	kinds := map[schema.GroupKind]GroupKindConversion{
+		testGK: {
+			DefinitionName: "resource.webhook.pkg.knative.dev",
+			HubVersion:     "error",
+			Zygotes:        zygotes,
+		},
+	}
+
+	ctx, conversion := newConversionWithKinds(t, kinds)
+	obj := internal.NewV1(internal.ErrorConvertFields)
+	obj.Name = "bad-resource"
+	req := &apixv1.ConversionRequest{
+		UID:               "some-uid",
+		DesiredAPIVersion: testAPIVersion("v3"),
+		Objects: []runtime.RawExtension{
+			toRaw(t, obj),
+		},
+	}
+
+	want := &apixv1.ConversionResponse{
+		UID: "some-uid",
+		Result: metav1.Status{
+			Status: metav1.StatusFailure,
+			Reason: metav1.StatusReasonInvalid,
+			Details: &metav1.StatusDetails{
+				Group: internal.Group,
+				Kind:  internal.Kind,
+				Name:  "bad-resource",
+				Causes: []metav1.StatusCause{{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid value: convertFields",
+					Field:   "spec.property",
+				}, {
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "missing field(s)",
+					Field:   "spec.another",
+				}, {
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "missing field(s)",
+					Field:   "spec.other",
+				}},
+			},
+		},
+	}
+
+	cmpOpts := []cmp.Option{
+		cmpopts.IgnoreFields(metav1.Status{}, "Message"),
+		rawOpt,
+	}
+
+	got := conversion.Convert(ctx, req)
+	if diff := cmp.Diff(want, got, cmpOpts...); diff != "" {
+		t.Error("unexpected response:", diff)
+	}
+
+	if !strings.Contains(got.Result.Message, "spec.property") {
+		t.Errorf("expected message to contain the failed field paths got %q", got.Result.Message)
+	}
+}
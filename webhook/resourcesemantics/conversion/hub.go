@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Hub wires the conversions of the versions of a kind through its hub
+// version, so that only the functions mapping the fields of each spoke
+// version to and from the hub version are hand-written, and the types need
+// not implement apis.Convertible. For example:
+//
+//	conversion.NewHub("v1", &v1.Foo{}).
+//		Spoke("v1alpha1", &v1alpha1.Foo{}, v1alpha1.ConvertFooToV1, v1alpha1.ConvertFooFromV1).
+//		Spoke("v1beta1", &v1beta1.Foo{}, v1beta1.ConvertFooToV1, v1beta1.ConvertFooFromV1).
+//		GroupKindConversion("foos.example.dev")
+type Hub struct {
+	version string
+	hubType reflect.Type
+	zygotes map[string]ConvertibleObject
+
+	// spokes holds the conversions of the spoke types, by type.
+	spokes map[reflect.Type]spoke
+}
+
+type spoke struct {
+	// to converts the spoke to the hub, and from the hub to the spoke.
+	to, from reflect.Value
+}
+
+// NewHub returns the Hub of the given version and empty object of the hub
+// type.
+func NewHub(version string, zygote runtime.Object) *Hub {
+	h := &Hub{
+		version: version,
+		hubType: reflect.TypeOf(zygote),
+		spokes:  make(map[reflect.Type]spoke),
+	}
+	h.zygotes = map[string]ConvertibleObject{
+		version: &hubObject{Object: zygote, hub: h},
+	}
+	return h
+}
+
+// Spoke registers the spoke version of the given empty object and its
+// conversions to and from the hub, with the signatures:
+//
+//	to func(ctx context.Context, from *Spoke, to *Hub) error
+//	from func(ctx context.Context, from *Hub, to *Spoke) error
+//
+// It panics when the functions don't have these signatures or the version
+// or type is already registered, as these are programming errors.
+func (h *Hub) Spoke(version string, zygote runtime.Object, to, from interface{}) *Hub {
+	spokeType := reflect.TypeOf(zygote)
+	if _, ok := h.zygotes[version]; ok {
+		panic(fmt.Sprintf("version %s is already registered", version))
+	}
+	if _, ok := h.spokes[spokeType]; ok || spokeType == h.hubType {
+		panic(fmt.Sprintf("type %v is already registered", spokeType))
+	}
+
+	s := spoke{
+		to:   checkConversion(to, spokeType, h.hubType),
+		from: checkConversion(from, h.hubType, spokeType),
+	}
+	h.spokes[spokeType] = s
+	h.zygotes[version] = &hubObject{Object: zygote, hub: h}
+	return h
+}
+
+// checkConversion returns the value of fn, after checking that it converts
+// from in to out.
+func checkConversion(fn interface{}, in, out reflect.Type) reflect.Value {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 3 || t.NumOut() != 1 ||
+		t.In(0) != contextType || t.In(1) != in || t.In(2) != out || t.Out(0) != errorType {
+		panic(fmt.Sprintf("conversion %v must be a func(context.Context, %v, %v) error", t, in, out))
+	}
+	return v
+}
+
+// GroupKindConversion returns the conversion of the kind through the hub,
+// to be given to the conversion controller.
+func (h *Hub) GroupKindConversion(definitionName string) GroupKindConversion {
+	return GroupKindConversion{
+		DefinitionName: definitionName,
+		HubVersion:     h.version,
+		Zygotes:        h.zygotes,
+	}
+}
+
+// hubObject adapts the objects of the versions of a Hub into a
+// ConvertibleObject. It is serialized as the object it holds.
+type hubObject struct {
+	runtime.Object
+	hub *Hub
+}
+
+var (
+	_ ConvertibleObject         = (*hubObject)(nil)
+	_ apis.Defaultable          = (*hubObject)(nil)
+	_ metav1.ObjectMetaAccessor = (*hubObject)(nil)
+	_ json.Marshaler            = (*hubObject)(nil)
+	_ json.Unmarshaler          = (*hubObject)(nil)
+)
+
+// ConvertTo implements apis.Convertible, converting the hub object to the
+// given spoke object.
+func (o *hubObject) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	sink, ok := to.(*hubObject)
+	if !ok {
+		return fmt.Errorf("unknown conversion target %T", to)
+	}
+	s, ok := o.hub.spokes[reflect.TypeOf(sink.Object)]
+	if !ok {
+		return fmt.Errorf("no conversion from %T to %T", o.Object, sink.Object)
+	}
+	return call(ctx, s.from, o.Object, sink.Object)
+}
+
+// ConvertFrom implements apis.Convertible, converting the given spoke
+// object to the hub object.
+func (o *hubObject) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	source, ok := from.(*hubObject)
+	if !ok {
+		return fmt.Errorf("unknown conversion source %T", from)
+	}
+	s, ok := o.hub.spokes[reflect.TypeOf(source.Object)]
+	if !ok {
+		return fmt.Errorf("no conversion from %T to %T", source.Object, o.Object)
+	}
+	return call(ctx, s.to, source.Object, o.Object)
+}
+
+func call(ctx context.Context, fn reflect.Value, from, to runtime.Object) error {
+	out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(from), reflect.ValueOf(to)})
+	err, _ := out[0].Interface().(error)
+	return err
+}
+
+// GetObjectMeta implements metav1.ObjectMetaAccessor, for the metadata of
+// the object it holds to be logged.
+func (o *hubObject) GetObjectMeta() metav1.Object {
+	if acc, ok := o.Object.(metav1.Object); ok {
+		return acc
+	}
+	return nil
+}
+
+// SetDefaults implements apis.Defaultable, defaulting the object it holds
+// if it is defaultable.
+func (o *hubObject) SetDefaults(ctx context.Context) {
+	if d, ok := o.Object.(apis.Defaultable); ok {
+		d.SetDefaults(ctx)
+	}
+}
+
+// DeepCopyObject implements runtime.Object.
+func (o *hubObject) DeepCopyObject() runtime.Object {
+	return &hubObject{Object: o.Object.DeepCopyObject(), hub: o.hub}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *hubObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Object)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *hubObject) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, o.Object)
+}
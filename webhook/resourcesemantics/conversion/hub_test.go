@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/webhook/resourcesemantics/conversion/internal"
+)
+
+func v2ToV1(_ context.Context, from *internal.V2Resource, to *internal.V1Resource) error {
+	to.ObjectMeta = from.ObjectMeta
+	to.Spec.Property = strings.TrimPrefix(from.Spec.Property, "prefix/")
+	return nil
+}
+
+func v1ToV2(_ context.Context, from *internal.V1Resource, to *internal.V2Resource) error {
+	to.ObjectMeta = from.ObjectMeta
+	to.Spec.Property = "prefix/" + from.Spec.Property
+	return nil
+}
+
+func v3ToV1(_ context.Context, from *internal.V3Resource, to *internal.V1Resource) error {
+	if from.Spec.Property == "fail/suffix" {
+		return errors.New("failed")
+	}
+	to.ObjectMeta = from.ObjectMeta
+	to.Spec.Property = strings.TrimSuffix(from.Spec.Property, "/suffix")
+	return nil
+}
+
+func v1ToV3(_ context.Context, from *internal.V1Resource, to *internal.V3Resource) error {
+	to.ObjectMeta = from.ObjectMeta
+	to.Spec.Property = from.Spec.Property + "/suffix"
+	return nil
+}
+
+func newHubConversion(t *testing.T) func(*apixv1.ConversionRequest) *apixv1.ConversionResponse {
+	t.Helper()
+	gkc := NewHub("v1", &internal.V1Resource{}).
+		Spoke("v2", &internal.V2Resource{}, v2ToV1, v1ToV2).
+		Spoke("v3", &internal.V3Resource{}, v3ToV1, v1ToV3).
+		GroupKindConversion("resource.webhook.pkg.knative.dev")
+
+	if got, want := gkc.HubVersion, "v1"; got != want {
+		t.Errorf("HubVersion = %q, wanted %q", got, want)
+	}
+	ctx, conversion := newConversionWithKinds(t, map[schema.GroupKind]GroupKindConversion{testGK: gkc})
+	return func(req *apixv1.ConversionRequest) *apixv1.ConversionResponse {
+		return conversion.Convert(ctx, req)
+	}
+}
+
+func TestHubConversion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		in      runtime.Object
+		want    runtime.Object
+	}{{
+		name:    "spoke to hub",
+		version: "v1",
+		in:      internal.NewV2("bing"),
+		want:    internal.NewV1("bing"),
+	}, {
+		name:    "hub to spoke",
+		version: "v2",
+		in:      internal.NewV1("bing"),
+		want:    internal.NewV2("bing"),
+	}, {
+		name:    "spoke to spoke, defaulted",
+		version: "v3",
+		in:      internal.NewV2("bang"),
+		want:    internal.NewV3("bang"),
+	}, {
+		name:    "spoke to spoke",
+		version: "v2",
+		in:      internal.NewV3("bang"),
+		want:    internal.NewV2("bang"),
+	}, {
+		name:    "hub to hub",
+		version: "v1",
+		in:      internal.NewV1("bong"),
+		want:    internal.NewV1("bong"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			convert := newHubConversion(t)
+			got := convert(&apixv1.ConversionRequest{
+				UID:               "some-uid",
+				DesiredAPIVersion: testAPIVersion(test.version),
+				Objects:           []runtime.RawExtension{toRaw(t, test.in)},
+			})
+			want := &apixv1.ConversionResponse{
+				UID:              "some-uid",
+				Result:           metav1.Status{Status: metav1.StatusSuccess},
+				ConvertedObjects: []runtime.RawExtension{toRaw(t, test.want)},
+			}
+			if diff := cmp.Diff(want, got, cmpOpts...); diff != "" {
+				t.Error("unexpected response:", diff)
+			}
+		})
+	}
+}
+
+func TestHubConversionFailure(t *testing.T) {
+	convert := newHubConversion(t)
+	got := convert(&apixv1.ConversionRequest{
+		UID:               "some-uid",
+		DesiredAPIVersion: testAPIVersion("v2"),
+		Objects:           []runtime.RawExtension{toRaw(t, internal.NewV3("fail"))},
+	})
+	if got.Result.Status != metav1.StatusFailure {
+		t.Errorf("Status = %q, wanted %q", got.Result.Status, metav1.StatusFailure)
+	}
+	if !strings.Contains(got.Result.Message, "failed") {
+		t.Errorf("Message = %q, wanted the error of the conversion", got.Result.Message)
+	}
+}
+
+func TestHubSpokePanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		zygote   runtime.Object
+		to, from interface{}
+	}{{
+		name:    "not a func",
+		version: "v2",
+		zygote:  &internal.V2Resource{},
+		to:      "v2ToV1",
+		from:    v1ToV2,
+	}, {
+		name:    "wrong spoke type",
+		version: "v2",
+		zygote:  &internal.V2Resource{},
+		to:      v3ToV1,
+		from:    v1ToV2,
+	}, {
+		name:    "swapped conversions",
+		version: "v2",
+		zygote:  &internal.V2Resource{},
+		to:      v1ToV2,
+		from:    v2ToV1,
+	}, {
+		name:    "no error",
+		version: "v2",
+		zygote:  &internal.V2Resource{},
+		to:      func(context.Context, *internal.V2Resource, *internal.V1Resource) {},
+		from:    v1ToV2,
+	}, {
+		name:    "hub version",
+		version: "v1",
+		zygote:  &internal.V2Resource{},
+		to:      v2ToV1,
+		from:    v1ToV2,
+	}, {
+		name:    "hub type",
+		version: "v2",
+		zygote:  &internal.V1Resource{},
+		to:      func(context.Context, *internal.V1Resource, *internal.V1Resource) error { return nil },
+		from:    func(context.Context, *internal.V1Resource, *internal.V1Resource) error { return nil },
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Spoke() did not panic")
+				}
+			}()
+			NewHub("v1", &internal.V1Resource{}).Spoke(test.version, test.zygote, test.to, test.from)
+		})
+	}
+}
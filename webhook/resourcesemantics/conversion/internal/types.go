@@ -49,6 +49,10 @@ const (
 	// ErrorConvertFrom when assigned to the Spec.Property of the ErrorResource
 	// will cause ConvertFrom to fail
 	ErrorConvertFrom = "convertFrom"
+
+	// ErrorConvertFields when assigned to the Spec.Property of the ErrorResource
+	// will cause ConvertTo to fail with field errors
+	ErrorConvertFields = "convertFields"
 )
 
 type (
@@ -246,6 +250,10 @@ func (e *ErrorResource) ConvertTo(ctx context.Context, to apis.Convertible) erro
 	if e.Spec.Property == ErrorConvertTo {
 		return errors.New("boooom - convert up")
 	}
+	if e.Spec.Property == ErrorConvertFields {
+		return apis.ErrInvalidValue(e.Spec.Property, "spec.property").Also(
+			apis.ErrMissingField("spec.other", "spec.another"))
+	}
 
 	return e.V1Resource.ConvertTo(ctx, to)
 }
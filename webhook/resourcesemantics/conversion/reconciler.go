@@ -35,7 +35,6 @@ import (
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/webhook"
-	certresources "knative.dev/pkg/webhook/certificates/resources"
 )
 
 type reconciler struct {
@@ -44,6 +43,7 @@ type reconciler struct {
 	kinds       map[schema.GroupKind]GroupKindConversion
 	path        string
 	secretName  string
+	caCertKey   string
 	withContext func(context.Context) context.Context
 
 	secretLister corelisters.SecretLister
@@ -75,9 +75,9 @@ func (r *reconciler) Reconcile(ctx context.Context, key string) error {
 		return err
 	}
 
-	cacert, ok := secret.Data[certresources.CACert]
+	cacert, ok := secret.Data[r.caCertKey]
 	if !ok {
-		return fmt.Errorf("secret %q is missing %q key", r.secretName, certresources.CACert)
+		return fmt.Errorf("secret %q is missing %q key", r.secretName, r.caCertKey)
 	}
 
 	return r.reconcileCRD(ctx, cacert, key)
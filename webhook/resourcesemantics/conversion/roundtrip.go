@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RoundTripAnnotation holds the fields of a newer version of a resource
+	// which an older version cannot represent, so that they survive a
+	// round-trip through the older version. See SaveRoundTrip.
+	RoundTripAnnotation = "conversion.knative.dev/round-trip"
+
+	// MaxRoundTripSize is the maximum size of the RoundTripAnnotation, well
+	// below the 256KiB limit of the annotations of a resource.
+	MaxRoundTripSize = 32 * 1024
+
+	// roundTripFormat is the version of the format of the annotation.
+	roundTripFormat = 1
+)
+
+// ErrRoundTripTooLarge is returned by SaveRoundTrip when the fields exceed
+// MaxRoundTripSize.
+var ErrRoundTripTooLarge = errors.New("round-trip fields exceed the maximum size of " + RoundTripAnnotation)
+
+// roundTrip is the content of the RoundTripAnnotation.
+type roundTrip struct {
+	// Format is the version of this format.
+	Format int `json:"format"`
+
+	// Version is the API version the fields were saved from.
+	Version string `json:"version"`
+
+	// Hash is the SHA-256 of Fields, which detects edits of the annotation.
+	Hash string `json:"hash"`
+
+	Fields json.RawMessage `json:"fields"`
+}
+
+// SaveRoundTrip saves the fields of the given API version which the object
+// it is down-converted to cannot represent, e.g. a struct of the new fields
+// or the whole spec, in the RoundTripAnnotation of that object, for
+// RestoreRoundTrip to restore them on up-conversion. The annotations of the
+// object are copied, since down-conversions often share the metadata of the
+// object they convert.
+func SaveRoundTrip(to metav1.Object, version string, fields interface{}) error {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the round-trip fields: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	value, err := json.Marshal(roundTrip{
+		Format:  roundTripFormat,
+		Version: version,
+		Hash:    "sha256:" + hex.EncodeToString(sum[:]),
+		Fields:  b,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", RoundTripAnnotation, err)
+	}
+	if len(value) > MaxRoundTripSize {
+		return ErrRoundTripTooLarge
+	}
+
+	annotations := make(map[string]string, len(to.GetAnnotations())+1)
+	for k, v := range to.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[RoundTripAnnotation] = string(value)
+	to.SetAnnotations(annotations)
+	return nil
+}
+
+// RestoreRoundTrip restores the fields saved by SaveRoundTrip from the
+// given API version into fields, and removes the RoundTripAnnotation from
+// the up-converted object. It returns false, leaving the annotation alone,
+// when there is none or it was saved from another version. The annotations
+// of the object are copied, like by SaveRoundTrip.
+func RestoreRoundTrip(from metav1.Object, version string, fields interface{}) (bool, error) {
+	value, ok := from.GetAnnotations()[RoundTripAnnotation]
+	if !ok {
+		return false, nil
+	}
+	var rt roundTrip
+	if err := json.Unmarshal([]byte(value), &rt); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s: %w", RoundTripAnnotation, err)
+	}
+	if rt.Format != roundTripFormat {
+		return false, fmt.Errorf("unsupported format %d of %s", rt.Format, RoundTripAnnotation)
+	}
+	if rt.Version != version {
+		return false, nil
+	}
+	sum := sha256.Sum256(rt.Fields)
+	if rt.Hash != "sha256:"+hex.EncodeToString(sum[:]) {
+		return false, fmt.Errorf("hash mismatch of %s, it was modified", RoundTripAnnotation)
+	}
+	if err := json.Unmarshal(rt.Fields, fields); err != nil {
+		return false, fmt.Errorf("failed to unmarshal the round-trip fields: %w", err)
+	}
+
+	annotations := make(map[string]string, len(from.GetAnnotations()))
+	for k, v := range from.GetAnnotations() {
+		if k != RoundTripAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	from.SetAnnotations(annotations)
+	return true, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type roundTripFields struct {
+	Replicas *int32            `json:"replicas,omitempty"`
+	Selector map[string]string `json:"selector,omitempty"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	replicas := int32(3)
+	saved := roundTripFields{Replicas: &replicas, Selector: map[string]string{"app": "foo"}}
+
+	source := metav1.ObjectMeta{Annotations: map[string]string{"foo": "bar"}}
+	down := source
+	if err := SaveRoundTrip(&down, "v2", saved); err != nil {
+		t.Fatal("SaveRoundTrip() =", err)
+	}
+	if _, ok := source.Annotations[RoundTripAnnotation]; ok {
+		t.Error("SaveRoundTrip() modified the annotations of the source object")
+	}
+
+	// Another version does not restore the fields.
+	var restored roundTripFields
+	if ok, err := RestoreRoundTrip(&down, "v3", &restored); ok || err != nil {
+		t.Errorf("RestoreRoundTrip(v3) = %v, %v, wanted false, nil", ok, err)
+	}
+
+	up := down
+	if ok, err := RestoreRoundTrip(&up, "v2", &restored); !ok || err != nil {
+		t.Fatalf("RestoreRoundTrip() = %v, %v, wanted true, nil", ok, err)
+	}
+	if diff := cmp.Diff(saved, restored); diff != "" {
+		t.Error("RestoreRoundTrip() (-want, +got):", diff)
+	}
+	if diff := cmp.Diff(source.Annotations, up.Annotations); diff != "" {
+		t.Error("Annotations after RestoreRoundTrip() (-want, +got):", diff)
+	}
+	if _, ok := down.Annotations[RoundTripAnnotation]; !ok {
+		t.Error("RestoreRoundTrip() modified the annotations of the source object")
+	}
+
+	// Without an annotation, there is nothing to restore.
+	if ok, err := RestoreRoundTrip(&up, "v2", &restored); ok || err != nil {
+		t.Errorf("RestoreRoundTrip() without annotation = %v, %v, wanted false, nil", ok, err)
+	}
+}
+
+func TestRoundTripErrors(t *testing.T) {
+	if err := SaveRoundTrip(&metav1.ObjectMeta{}, "v2", strings.Repeat("x", MaxRoundTripSize)); !errors.Is(err, ErrRoundTripTooLarge) {
+		t.Errorf("SaveRoundTrip() = %v, wanted %v", err, ErrRoundTripTooLarge)
+	}
+	if err := SaveRoundTrip(&metav1.ObjectMeta{}, "v2", func() {}); err == nil {
+		t.Error("SaveRoundTrip() of a func succeeded, wanted an error")
+	}
+
+	tests := []struct {
+		name       string
+		annotation string
+		want       string
+	}{{
+		name:       "not json",
+		annotation: "{",
+		want:       "failed to unmarshal " + RoundTripAnnotation,
+	}, {
+		name:       "unknown format",
+		annotation: `{"format":2,"version":"v2"}`,
+		want:       "unsupported format 2",
+	}, {
+		name:       "edited",
+		annotation: `{"format":1,"version":"v2","hash":"sha256:00","fields":{"replicas":5}}`,
+		want:       "hash mismatch",
+	}, {
+		name:       "wrong fields",
+		annotation: `{"format":1,"version":"v2","hash":"sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945","fields":[]}`,
+		want:       "failed to unmarshal the round-trip fields",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			meta := &metav1.ObjectMeta{Annotations: map[string]string{RoundTripAnnotation: tc.annotation}}
+			var restored roundTripFields
+			ok, err := RestoreRoundTrip(meta, "v2", &restored)
+			if ok || err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("RestoreRoundTrip() = %v, %v, wanted an error containing %q", ok, err, tc.want)
+			}
+		})
+	}
+}
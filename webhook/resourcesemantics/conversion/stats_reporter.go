@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/metrics"
+)
+
+const (
+	conversionCountName     = "conversion_count"
+	conversionLatenciesName = "conversion_latencies"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+var (
+	conversionCountM = stats.Int64(
+		conversionCountName,
+		"The number of objects converted by the conversion webhook",
+		stats.UnitDimensionless)
+	conversionLatencyInMsecM = stats.Float64(
+		conversionLatenciesName,
+		"The time taken to convert an object in milliseconds",
+		stats.UnitMilliseconds)
+
+	fromGroupVersionKey = tag.MustNewKey("from_group_version")
+	toGroupVersionKey   = tag.MustNewKey("to_group_version")
+	kindKey             = tag.MustNewKey("kind")
+	resultKey           = tag.MustNewKey("result")
+)
+
+func init() {
+	registerMetrics()
+}
+
+func registerMetrics() {
+	tagKeys := []tag.Key{fromGroupVersionKey, toGroupVersionKey, kindKey, resultKey}
+	if err := view.Register(
+		&view.View{
+			Description: conversionCountM.Description(),
+			Measure:     conversionCountM,
+			Aggregation: view.Count(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Description: conversionLatencyInMsecM.Description(),
+			Measure:     conversionLatencyInMsecM,
+			Aggregation: view.Distribution(metrics.Buckets125(0.1, 10000)...), // [0.1 0.2 0.5 1 2 5 ... 10000]ms
+			TagKeys:     tagKeys,
+		},
+	); err != nil {
+		panic(err)
+	}
+}
+
+// reportConversion records the conversion of an object of the given type to
+// the given API version. The type is empty when it could not be parsed.
+func reportConversion(ctx context.Context, from schema.GroupVersionKind, to string, err error, d time.Duration) {
+	result := resultSuccess
+	if err != nil {
+		result = resultFailure
+	}
+	ctx, tagErr := tag.New(ctx,
+		tag.Insert(fromGroupVersionKey, from.GroupVersion().String()),
+		tag.Insert(toGroupVersionKey, to),
+		tag.Insert(kindKey, from.Kind),
+		tag.Insert(resultKey, result),
+	)
+	if tagErr != nil {
+		return
+	}
+	metrics.RecordBatch(ctx, conversionCountM.M(1),
+		conversionLatencyInMsecM.M(float64(d.Microseconds())/1000))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"testing"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+	"knative.dev/pkg/webhook/resourcesemantics/conversion/internal"
+)
+
+// opencensus metrics carry global state that need to be reset between unit tests
+func resetMetrics() {
+	metricstest.Unregister(conversionCountName, conversionLatenciesName)
+	registerMetrics()
+}
+
+func TestConversionMetrics(t *testing.T) {
+	kinds := map[schema.GroupKind]GroupKindConversion{
+		testGK: {
+			DefinitionName: "resource.webhook.pkg.knative.dev",
+			HubVersion:     "v1",
+			Zygotes:        zygotes,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		want    int64
+		result  string
+	}{{
+		name:    "success",
+		version: "v1",
+		want:    2,
+		result:  resultSuccess,
+	}, {
+		name:    "failure",
+		version: "v4",
+		// Conversion stops at the first failure.
+		want:   1,
+		result: resultFailure,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMetrics()
+			ctx, conversion := newConversionWithKinds(t, kinds)
+
+			req := &apixv1.ConversionRequest{
+				UID:               "some-uid",
+				DesiredAPIVersion: testAPIVersion(test.version),
+				Objects: []runtime.RawExtension{
+					toRaw(t, internal.NewV2("bing")),
+					toRaw(t, internal.NewV2("bang")),
+				},
+			}
+			conversion.Convert(ctx, req)
+
+			tags := map[string]string{
+				fromGroupVersionKey.Name(): testAPIVersion("v2"),
+				toGroupVersionKey.Name():   testAPIVersion(test.version),
+				kindKey.Name():             internal.Kind,
+				resultKey.Name():           test.result,
+			}
+			metricstest.CheckCountData(t, conversionCountName, tags, test.want)
+			metricstest.AssertMetricExists(t, conversionLatenciesName)
+		})
+	}
+}
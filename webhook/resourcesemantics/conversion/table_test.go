@@ -282,6 +282,7 @@ func TestReconcile(t *testing.T) {
 			kinds:        kinds,
 			path:         path,
 			secretName:   secretName,
+			caCertKey:    certresources.CACert,
 			secretLister: listers.GetSecretLister(),
 			crdLister:    listers.GetCustomResourceDefinitionLister(),
 			client:       apixclient.Get(ctx),
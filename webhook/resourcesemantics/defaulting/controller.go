@@ -28,6 +28,9 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	admissionregistrationv1informers "k8s.io/client-go/informers/admissionregistration/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 
 	"knative.dev/pkg/controller"
@@ -73,11 +76,29 @@ func NewAdmissionController(
 	return newController(ctx, name, opts...)
 }
 
+// NewAdmissionControllerWithOptions constructs a reconciler configured by
+// the given options, e.g. WithPath, WithTypes and WithNamespaceSelector.
+func NewAdmissionControllerWithOptions(ctx context.Context, name string, optsFunc ...OptionFunc) *controller.Impl {
+	return newController(ctx, name, optsFunc...)
+}
+
 func newController(ctx context.Context, name string, optsFunc ...OptionFunc) *controller.Impl {
-	client := kubeclient.Get(ctx)
-	mwhInformer := mwhinformer.Get(ctx)
-	secretInformer := secretinformer.Get(ctx)
+	return NewAdmissionControllerWithInformers(ctx, name,
+		kubeclient.Get(ctx), mwhinformer.Get(ctx), secretinformer.Get(ctx), optsFunc...)
+}
 
+// NewAdmissionControllerWithInformers constructs a reconciler without relying
+// on injection, from the given client and informers. The secret informer must
+// cover the system namespace. The caller is responsible for running the
+// informers, see webhook.Standalone.
+func NewAdmissionControllerWithInformers(
+	ctx context.Context,
+	name string,
+	client kubernetes.Interface,
+	mwhInformer admissionregistrationv1informers.MutatingWebhookConfigurationInformer,
+	secretInformer corev1informers.SecretInformer,
+	optsFunc ...OptionFunc,
+) *controller.Impl {
 	opts := &options{}
 	wopts := webhook.GetOptions(ctx)
 
@@ -101,9 +122,17 @@ func newController(ctx context.Context, name string, optsFunc ...OptionFunc) *co
 		handlers:  opts.types,
 		callbacks: opts.callbacks,
 
+		namespaceSelector: opts.namespaceSelector,
+		objectSelector:    opts.objectSelector,
+		policies:          opts.policies,
+		schemas:           opts.schemas,
+		schemaWarnings:    opts.schemaWarnings,
+
 		withContext:           opts.wc,
 		disallowUnknownFields: opts.disallowUnknownFields,
-		secretName:            wopts.SecretName,
+		unknownFieldWarnings:  opts.unknownFieldWarnings,
+		secretName:            wopts.CABundleSecretNameFor(opts.path),
+		caCertKey:             wopts.CABundleKey(),
 
 		client:       client,
 		mwhlister:    mwhInformer.Lister(),
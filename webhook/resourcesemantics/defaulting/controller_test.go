@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaulting
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/system"
+	"knative.dev/pkg/webhook"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+)
+
+func TestStandalone(t *testing.T) {
+	const name, path, secretName = "defaulting.webhook.knative.dev", "/defaulting", "webhook-certs"
+
+	ctx, cancel := context.WithCancel(logtesting.TestContextWithLogger(t))
+	defer cancel()
+	ctx = webhook.WithOptions(ctx, webhook.Options{
+		ServiceName: "webhook",
+		SecretName:  secretName,
+		GracePeriod: 100 * time.Millisecond,
+	})
+
+	secret, err := certresources.MakeSecret(ctx, secretName, system.Namespace(), "webhook")
+	if err != nil {
+		t.Fatal("MakeSecret() =", err)
+	}
+	client := fakekube.NewSimpleClientset(
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: system.Namespace()},
+		},
+		secret,
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{
+				Name: name,
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{
+						Namespace: system.Namespace(),
+						Name:      "webhook",
+					},
+				},
+			}},
+		},
+	)
+
+	factory := informers.NewSharedInformerFactory(client, 0)
+	mwhInformer := factory.Admissionregistration().V1().MutatingWebhookConfigurations()
+	secretInformer := factory.Core().V1().Secrets()
+
+	c := NewAdmissionControllerWithInformers(ctx, name, client, mwhInformer, secretInformer,
+		WithPath(path), WithTypes(handlers))
+	wh, err := webhook.NewWithSecretInformer(ctx, []interface{}{c.Reconciler}, secretInformer)
+	if err != nil {
+		t.Fatal("NewWithSecretInformer() =", err)
+	}
+
+	s := &webhook.Standalone{
+		Webhook:     wh,
+		Informers:   []controller.Informer{mwhInformer.Informer(), secretInformer.Informer()},
+		Controllers: []*controller.Impl{c},
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Run(ctx)
+	}()
+
+	// The controller should set the CA bundle and path of the webhook.
+	if err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		mwh, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		cc := mwh.Webhooks[0].ClientConfig
+		return bytes.Equal(cc.CABundle, secret.Data[certresources.CACert]) &&
+			cc.Service.Path != nil && *cc.Service.Path == path, nil
+	}); err != nil {
+		t.Fatal("Failed to reconcile the webhook configuration:", err)
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Error("Run() =", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Error("Timed out waiting for Run() to return")
+	}
+}
@@ -46,7 +46,6 @@ import (
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/webhook"
-	certresources "knative.dev/pkg/webhook/certificates/resources"
 	"knative.dev/pkg/webhook/json"
 	"knative.dev/pkg/webhook/resourcesemantics"
 )
@@ -63,17 +62,37 @@ type reconciler struct {
 	handlers  map[schema.GroupVersionKind]resourcesemantics.GenericCRD
 	callbacks map[schema.GroupVersionKind]Callback
 
+	// namespaceSelector and objectSelector, when set, are reconciled into
+	// the webhook configuration.
+	namespaceSelector *metav1.LabelSelector
+	objectSelector    *metav1.LabelSelector
+
+	// policies are the failure policies, timeouts and side effects of the
+	// kinds whose webhooks differ from the configured one.
+	policies resourcesemantics.WebhookPolicies
+
+	// schemas are checked against the raw objects before decoding them,
+	// rejecting the violations unless schemaWarnings is set.
+	schemas        resourcesemantics.Schemas
+	schemaWarnings bool
+
 	withContext func(context.Context) context.Context
 
 	client       kubernetes.Interface
 	mwhlister    admissionlisters.MutatingWebhookConfigurationLister
 	secretlister corelisters.SecretLister
 
+	// disallowUnknownFields rejects the objects with unknown fields, while
+	// unknownFieldWarnings reports them as warnings.
 	disallowUnknownFields bool
+	unknownFieldWarnings  bool
 	secretName            string
+	caCertKey             string
 }
 
-// CallbackFunc is the function to be invoked.
+// CallbackFunc is the function to be invoked. The context it is invoked with
+// satisfies apis.IsDryRun for dry-run requests, in which case it must not
+// have side effects.
 type CallbackFunc func(ctx context.Context, unstructured *unstructured.Unstructured) error
 
 // Callback is a generic function to be called by a consumer of defaulting.
@@ -84,6 +103,10 @@ type Callback struct {
 	// supportedVerbs are the verbs supported for the callback.
 	// The function will only be called on these actions.
 	supportedVerbs map[webhook.Operation]struct{}
+
+	// sideEffects is whether the function has side effects, in which case
+	// it is not called on dry-run requests.
+	sideEffects bool
 }
 
 // NewCallback creates a new callback function to be invoked on supported verbs.
@@ -104,6 +127,15 @@ func NewCallback(function func(context.Context, *unstructured.Unstructured) erro
 	return Callback{function: function, supportedVerbs: m}
 }
 
+// WithSideEffects returns a copy of the callback which is not invoked on
+// dry-run requests, for functions that can't check apis.IsDryRun themselves.
+// Note the patches of such a callback are then missing from the dry-run
+// responses.
+func (c Callback) WithSideEffects() Callback {
+	c.sideEffects = true
+	return c
+}
+
 var _ controller.Reconciler = (*reconciler)(nil)
 var _ pkgreconciler.LeaderAware = (*reconciler)(nil)
 var _ webhook.AdmissionController = (*reconciler)(nil)
@@ -123,9 +155,9 @@ func (ac *reconciler) Reconcile(ctx context.Context, key string) error {
 		logger.Errorw("Error fetching secret", zap.Error(err))
 		return err
 	}
-	caCert, ok := secret.Data[certresources.CACert]
+	caCert, ok := secret.Data[ac.caCertKey]
 	if !ok {
-		return fmt.Errorf("secret %q is missing %q key", ac.secretName, certresources.CACert)
+		return fmt.Errorf("secret %q is missing %q key", ac.secretName, ac.caCertKey)
 	}
 
 	// Reconcile the webhook configuration.
@@ -142,6 +174,9 @@ func (ac *reconciler) Admit(ctx context.Context, request *admissionv1.AdmissionR
 	if ac.withContext != nil {
 		ctx = ac.withContext(ctx)
 	}
+	if request.DryRun != nil && *request.DryRun {
+		ctx = apis.WithDryRun(ctx)
+	}
 
 	logger := logging.FromContext(ctx)
 	switch request.Operation {
@@ -151,15 +186,33 @@ func (ac *reconciler) Admit(ctx context.Context, request *admissionv1.AdmissionR
 		return &admissionv1.AdmissionResponse{Allowed: true}
 	}
 
-	patchBytes, err := ac.mutate(ctx, request)
+	// Check the raw object before decoding it drops the unknown fields.
+	gvk := schema.GroupVersionKind{
+		Group:   request.Kind.Group,
+		Version: request.Kind.Version,
+		Kind:    request.Kind.Kind,
+	}
+	var warnings []string
+	if errs := ac.schemas.Validate(gvk, request.Object.Raw); errs != nil {
+		if !ac.schemaWarnings {
+			return webhook.MakeErrorStatus("schema validation failed: %v", errs)
+		}
+		for _, w := range errs.WrappedErrors() {
+			warnings = append(warnings, w.Error())
+		}
+	}
+
+	patchBytes, unknownWarnings, err := ac.mutate(ctx, request)
 	if err != nil {
 		return webhook.MakeErrorStatus("mutation failed: %v", err)
 	}
+	warnings = append(warnings, unknownWarnings...)
 	logger.Infof("Kind: %q PatchBytes: %v", request.Kind, string(patchBytes))
 
 	return &admissionv1.AdmissionResponse{
-		Patch:   patchBytes,
-		Allowed: true,
+		Patch:    patchBytes,
+		Allowed:  true,
+		Warnings: warnings,
 		PatchType: func() *admissionv1.PatchType {
 			pt := admissionv1.PatchTypeJSONPatch
 			return &pt
@@ -223,6 +276,9 @@ func (ac *reconciler) reconcileMutatingWebhook(ctx context.Context, caCert []byt
 	nsRef := *metav1.NewControllerRef(ns, corev1.SchemeGroupVersion.WithKind("Namespace"))
 	current.OwnerReferences = []metav1.OwnerReference{nsRef}
 
+	// The kinds with a policy are moved to webhooks of their own.
+	rules, policyRules := ac.policies.Split(current.Name, rules)
+
 	for i, wh := range current.Webhooks {
 		if wh.Name != current.Name {
 			continue
@@ -231,14 +287,25 @@ func (ac *reconciler) reconcileMutatingWebhook(ctx context.Context, caCert []byt
 		cur := &current.Webhooks[i]
 		cur.Rules = rules
 
-		cur.NamespaceSelector = webhook.EnsureLabelSelectorExpressions(
-			cur.NamespaceSelector,
-			&metav1.LabelSelector{
-				MatchExpressions: []metav1.LabelSelectorRequirement{{
-					Key:      "webhooks.knative.dev/exclude",
-					Operator: metav1.LabelSelectorOpDoesNotExist,
-				}},
-			})
+		exclude := metav1.LabelSelectorRequirement{
+			Key:      "webhooks.knative.dev/exclude",
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		}
+		if ac.namespaceSelector != nil {
+			// The configured selector owns the field, so drift is corrected.
+			selector := ac.namespaceSelector.DeepCopy()
+			selector.MatchExpressions = append(selector.MatchExpressions, exclude)
+			cur.NamespaceSelector = selector
+		} else {
+			cur.NamespaceSelector = webhook.EnsureLabelSelectorExpressions(
+				cur.NamespaceSelector,
+				&metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{exclude},
+				})
+		}
+		if ac.objectSelector != nil {
+			cur.ObjectSelector = ac.objectSelector.DeepCopy()
+		}
 
 		cur.ClientConfig.CABundle = caCert
 		if cur.ClientConfig.Service == nil {
@@ -248,6 +315,7 @@ func (ac *reconciler) reconcileMutatingWebhook(ctx context.Context, caCert []byt
 
 		cur.ReinvocationPolicy = ptrReinvocationPolicyType(admissionregistrationv1.IfNeededReinvocationPolicy)
 	}
+	current.Webhooks = withPolicyWebhooks(current.Name, current.Webhooks, policyRules)
 
 	if ok, err := kmp.SafeEqual(configuredWebhook, current); err != nil {
 		return fmt.Errorf("error diffing webhooks: %w", err)
@@ -263,7 +331,47 @@ func (ac *reconciler) reconcileMutatingWebhook(ctx context.Context, caCert []byt
 	return nil
 }
 
-func (ac *reconciler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest) ([]byte, error) {
+// withPolicyWebhooks returns the webhooks with those of the kinds with a
+// policy, copied from the webhook named base with the policy applied, in
+// place of the previous ones.
+func withPolicyWebhooks(base string, webhooks []admissionregistrationv1.MutatingWebhook, policyRules []resourcesemantics.PolicyRules) []admissionregistrationv1.MutatingWebhook {
+	var baseWebhook *admissionregistrationv1.MutatingWebhook
+	kept := make([]admissionregistrationv1.MutatingWebhook, 0, len(webhooks)+len(policyRules))
+	for _, wh := range webhooks {
+		if resourcesemantics.IsPolicyWebhook(base, wh.Name) {
+			continue
+		}
+		kept = append(kept, wh)
+		if wh.Name == base {
+			baseWebhook = &kept[len(kept)-1]
+		}
+	}
+	if baseWebhook == nil {
+		return kept
+	}
+
+	policyWebhooks := make([]admissionregistrationv1.MutatingWebhook, 0, len(policyRules))
+	for _, pr := range policyRules {
+		wh := *baseWebhook.DeepCopy()
+		wh.Name = pr.Name
+		wh.Rules = pr.Rules
+		if pr.Policy.FailurePolicy != nil {
+			wh.FailurePolicy = pr.Policy.FailurePolicy
+		}
+		if pr.Policy.TimeoutSeconds != nil {
+			wh.TimeoutSeconds = pr.Policy.TimeoutSeconds
+		}
+		if pr.Policy.SideEffects != nil {
+			wh.SideEffects = pr.Policy.SideEffects
+		}
+		policyWebhooks = append(policyWebhooks, wh)
+	}
+	return append(kept, policyWebhooks...)
+}
+
+// mutate returns the patch of the request's object, and the warnings about
+// its unknown fields when they are reported.
+func (ac *reconciler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest) ([]byte, []string, error) {
 	kind := req.Kind
 	newBytes := req.Object.Raw
 	oldBytes := req.OldObject.Raw
@@ -279,33 +387,44 @@ func (ac *reconciler) mutate(ctx context.Context, req *admissionv1.AdmissionRequ
 	if !ok {
 		if _, ok := ac.callbacks[gvk]; !ok {
 			logger.Error("Unhandled kind: ", gvk)
-			return nil, fmt.Errorf("unhandled kind: %v", gvk)
+			return nil, nil, fmt.Errorf("unhandled kind: %v", gvk)
 		}
 		patches, err := ac.callback(ctx, gvk, req, true /* shouldSetUserInfo */, duck.JSONPatch{})
 		if err != nil {
 			logger.Errorw("Failed the callback defaulter", zap.Error(err))
 			// Return the error message as-is to give the defaulter callback
 			// discretion over (our portion of) the message that the user sees.
-			return nil, err
+			return nil, nil, err
 		}
-		return json.Marshal(patches)
+		patch, err := minimizePatch(ctx, newBytes, patches)
+		return patch, nil, err
 	}
 
 	// nil values denote absence of `old` (create) or `new` (delete) objects.
 	var oldObj, newObj resourcesemantics.GenericCRD
 
+	var warnings []string
 	if len(newBytes) != 0 {
 		newObj = handler.DeepCopyObject().(resourcesemantics.GenericCRD)
-		err := json.Decode(newBytes, newObj, ac.disallowUnknownFields)
+		var err error
+		if ac.unknownFieldWarnings && !ac.disallowUnknownFields {
+			var unknown []string
+			unknown, err = json.DecodeUnknownFields(newBytes, newObj)
+			for _, f := range unknown {
+				warnings = append(warnings, fmt.Sprintf("unknown field %q", f))
+			}
+		} else {
+			err = json.Decode(newBytes, newObj, ac.disallowUnknownFields)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("cannot decode incoming new object: %w", err)
+			return nil, nil, fmt.Errorf("cannot decode incoming new object: %w", err)
 		}
 	}
 	if len(oldBytes) != 0 {
 		oldObj = handler.DeepCopyObject().(resourcesemantics.GenericCRD)
 		err := json.Decode(oldBytes, oldObj, ac.disallowUnknownFields)
 		if err != nil {
-			return nil, fmt.Errorf("cannot decode incoming old object: %w", err)
+			return nil, nil, fmt.Errorf("cannot decode incoming old object: %w", err)
 		}
 	}
 	var patches duck.JSONPatch
@@ -318,7 +437,7 @@ func (ac *reconciler) mutate(ctx context.Context, req *admissionv1.AdmissionRequ
 		// because it expects the round tripped through Golang fields to be present already.
 		rtp, err := roundTripPatch(newBytes, newObj)
 		if err != nil {
-			return nil, fmt.Errorf("cannot create patch for round tripped newBytes: %w", err)
+			return nil, nil, fmt.Errorf("cannot create patch for round tripped newBytes: %w", err)
 		}
 		patches = append(patches, rtp...)
 	}
@@ -350,26 +469,41 @@ func (ac *reconciler) mutate(ctx context.Context, req *admissionv1.AdmissionRequ
 		logger.Errorw("Failed the resource specific defaulter", zap.Error(err))
 		// Return the error message as-is to give the defaulter callback
 		// discretion over (our portion of) the message that the user sees.
-		return nil, err
+		return nil, nil, err
 	}
 
 	if patches, err = ac.setUserInfoAnnotations(ctx, patches, newObj, req.Resource.Group); err != nil {
 		logger.Errorw("Failed the resource user info annotator", zap.Error(err))
-		return nil, err
+		return nil, nil, err
 	}
 
 	if patches, err = ac.callback(ctx, gvk, req, false /* shouldSetUserInfo */, patches); err != nil {
 		logger.Errorw("Failed the callback defaulter", zap.Error(err))
 		// Return the error message as-is to give the defaulter callback
 		// discretion over (our portion of) the message that the user sees.
-		return nil, err
+		return nil, nil, err
 	}
 
 	// None of the validators will accept a nil value for newObj.
 	if newObj == nil {
-		return nil, errMissingNewObject
+		return nil, nil, errMissingNewObject
+	}
+	patch, err := minimizePatch(ctx, newBytes, patches)
+	return patch, warnings, err
+}
+
+// minimizePatch marshals the minimized patches of the given object, or the
+// patches as they are when they can't be minimized.
+func minimizePatch(ctx context.Context, bytes []byte, patches duck.JSONPatch) ([]byte, error) {
+	_, span := webhook.StartPatchSpan(ctx)
+	defer span.End()
+
+	minimized, err := duck.MinimizePatch(bytes, patches)
+	if err != nil {
+		logging.FromContext(ctx).Warnw("Failed to minimize the patch", zap.Error(err))
+		return json.Marshal(patches)
 	}
-	return json.Marshal(patches)
+	return json.Marshal(minimized)
 }
 
 func (ac *reconciler) setUserInfoAnnotations(ctx context.Context, patches duck.JSONPatch, new resourcesemantics.GenericCRD, groupName string) (duck.JSONPatch, error) {
@@ -403,6 +537,9 @@ func (ac *reconciler) callback(ctx context.Context, gvk schema.GroupVersionKind,
 	if _, isSupported := callback.supportedVerbs[req.Operation]; !isSupported {
 		return patches, nil
 	}
+	if callback.sideEffects && apis.IsDryRun(ctx) {
+		return patches, nil
+	}
 
 	oldBytes := req.OldObject.Raw
 	newBytes := req.Object.Raw
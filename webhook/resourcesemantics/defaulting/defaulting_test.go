@@ -38,6 +38,7 @@ import (
 	admissionv1 "k8s.io/api/admission/v1"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
@@ -220,6 +221,44 @@ func TestUnknownFieldFails(t *testing.T) {
 		`mutation failed: cannot decode incoming new object: json: unknown field "foo"`)
 }
 
+func TestUnknownFieldWarnings(t *testing.T) {
+	_, ac := newNonRunningTestResourceAdmissionController(t)
+	ac.(*reconciler).disallowUnknownFields = false
+	ac.(*reconciler).unknownFieldWarnings = true
+	req := &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Kind: metav1.GroupVersionKind{
+			Group:   "pkg.knative.dev",
+			Version: "v1alpha1",
+			Kind:    "Resource",
+		},
+	}
+
+	marshaled, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "pkg.knative.dev/v1alpha1",
+		"kind":       "Resource",
+		"spec": map[string]interface{}{
+			"foo":              "bar",
+			"fieldWithDefault": "baz",
+		},
+	})
+	if err != nil {
+		t.Fatal("Failed to marshal resource:", err)
+	}
+	req.Object.Raw = marshaled
+
+	resp := ac.Admit(TestContextWithLogger(t), req)
+	ExpectAllowed(t, resp)
+	if want := []string{`unknown field "spec.foo"`}; !reflect.DeepEqual(resp.Warnings, want) {
+		t.Errorf("Warnings = %v, wanted %v", resp.Warnings, want)
+	}
+
+	// The objects are still rejected in strict mode.
+	ac.(*reconciler).disallowUnknownFields = true
+	ExpectFailsWith(t, ac.Admit(TestContextWithLogger(t), req),
+		`mutation failed: cannot decode incoming new object: json: unknown field "foo"`)
+}
+
 func TestUnknownMetadataFieldSucceeds(t *testing.T) {
 	_, ac := newNonRunningTestResourceAdmissionController(t)
 	req := &admissionv1.AdmissionRequest{
@@ -249,6 +288,60 @@ func TestUnknownMetadataFieldSucceeds(t *testing.T) {
 	ExpectAllowed(t, ac.Admit(TestContextWithLogger(t), req))
 }
 
+func TestSchemaViolations(t *testing.T) {
+	gvk := schema.GroupVersionKind{
+		Group:   "pkg.knative.dev",
+		Version: "v1alpha1",
+		Kind:    "Resource",
+	}
+	schemas := resourcesemantics.Schemas{
+		gvk: {
+			Type: "object",
+			Properties: map[string]apixv1.JSONSchemaProps{
+				"spec": {
+					Type: "object",
+					Properties: map[string]apixv1.JSONSchemaProps{
+						"fieldWithValidation": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+	marshaled, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "pkg.knative.dev/v1alpha1",
+		"kind":       "Resource",
+		"spec": map[string]interface{}{
+			"fieldWithValidation": "magic value",
+			// Known to the type, but not to the schema.
+			"fieldWithDefault": "foo",
+		},
+	})
+	if err != nil {
+		t.Fatal("Failed to marshal resource:", err)
+	}
+	req := &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Kind: metav1.GroupVersionKind{
+			Group:   gvk.Group,
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+		},
+	}
+	req.Object.Raw = marshaled
+
+	_, ac := newNonRunningTestResourceAdmissionController(t)
+	ac.(*reconciler).schemas = schemas
+	ExpectFailsWith(t, ac.Admit(TestContextWithLogger(t), req),
+		"schema validation failed: must not set the field(s): spec.fieldWithDefault")
+
+	ac.(*reconciler).schemaWarnings = true
+	resp := ac.Admit(TestContextWithLogger(t), req)
+	ExpectAllowed(t, resp)
+	if want := []string{"must not set the field(s): spec.fieldWithDefault"}; !reflect.DeepEqual(resp.Warnings, want) {
+		t.Errorf("Warnings = %v, wanted %v", resp.Warnings, want)
+	}
+}
+
 func TestAdmitCreates(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -912,14 +1005,13 @@ func TestValidCreateResourceSucceedsWithRoundTripAndDefaultPatch(t *testing.T) {
 	_, ac := newNonRunningTestResourceAdmissionController(t)
 	resp := ac.Admit(TestContextWithLogger(t), req)
 	ExpectAllowed(t, resp)
+	// The round trip and default patches are collapsed into one.
 	ExpectPatches(t, resp.Patch, []jsonpatch.JsonPatchOperation{{
 		Operation: "add",
 		Path:      "/spec",
-		Value:     map[string]interface{}{},
-	}, {
-		Operation: "add",
-		Path:      "/spec/fieldWithDefault",
-		Value:     "I'm a default.",
+		Value: map[string]interface{}{
+			"fieldWithDefault": "I'm a default.",
+		},
 	}})
 }
 
@@ -1016,3 +1108,68 @@ func podCallback(ctx context.Context, u *unstructured.Unstructured) error {
 
 	return nil
 }
+
+func TestCallbackDryRun(t *testing.T) {
+	gvk := schema.GroupVersionKind{
+		Group:   "pkg.knative.dev",
+		Version: "v1beta1",
+		Kind:    "ResourceCallbackDryRun",
+	}
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		sideEffects bool
+		wantCalled  bool
+	}{{
+		name:       "no dry run",
+		wantCalled: true,
+	}, {
+		name:       "dry run",
+		dryRun:     true,
+		wantCalled: true,
+	}, {
+		name:        "no dry run with side effects",
+		sideEffects: true,
+		wantCalled:  true,
+	}, {
+		name:        "dry run with side effects",
+		dryRun:      true,
+		sideEffects: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var called, gotDryRun bool
+			cb := NewCallback(func(ctx context.Context, _ *unstructured.Unstructured) error {
+				called, gotDryRun = true, apis.IsDryRun(ctx)
+				return nil
+			}, webhook.Create)
+			if tc.sideEffects {
+				cb = cb.WithSideEffects()
+			}
+			ac := &reconciler{
+				callbacks: map[schema.GroupVersionKind]Callback{gvk: cb},
+			}
+
+			req := &admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Kind: metav1.GroupVersionKind{
+					Group:   gvk.Group,
+					Version: gvk.Version,
+					Kind:    gvk.Kind,
+				},
+				Object: runtime.RawExtension{Raw: []byte(`{"apiVersion":"pkg.knative.dev/v1beta1","kind":"Resource","metadata":{"name":"foo"}}`)},
+				DryRun: ptr.Bool(tc.dryRun),
+			}
+			ExpectAllowed(t, ac.Admit(TestContextWithLogger(t), req))
+
+			if called != tc.wantCalled {
+				t.Errorf("callback called = %v, wanted %v", called, tc.wantCalled)
+			}
+			if called && gotDryRun != tc.dryRun {
+				t.Errorf("IsDryRun() = %v, wanted %v", gotDryRun, tc.dryRun)
+			}
+		})
+	}
+}
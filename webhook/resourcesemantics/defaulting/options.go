@@ -19,6 +19,7 @@ package defaulting
 import (
 	"context"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/webhook/resourcesemantics"
 )
@@ -28,7 +29,13 @@ type options struct {
 	types                 map[schema.GroupVersionKind]resourcesemantics.GenericCRD
 	wc                    func(context.Context) context.Context
 	disallowUnknownFields bool
+	unknownFieldWarnings  bool
 	callbacks             map[schema.GroupVersionKind]Callback
+	namespaceSelector     *metav1.LabelSelector
+	objectSelector        *metav1.LabelSelector
+	policies              resourcesemantics.WebhookPolicies
+	schemas               resourcesemantics.Schemas
+	schemaWarnings        bool
 }
 
 type OptionFunc func(*options)
@@ -62,3 +69,56 @@ func WithDisallowUnknownFields() OptionFunc {
 		o.disallowUnknownFields = true
 	}
 }
+
+// WithUnknownFieldWarnings reports the unknown fields of the objects, which
+// are otherwise silently dropped, as warnings. The objects are still
+// rejected under WithDisallowUnknownFields.
+func WithUnknownFieldWarnings() OptionFunc {
+	return func(o *options) {
+		o.unknownFieldWarnings = true
+	}
+}
+
+// WithSchemas checks the raw objects of the kinds with a schema against it
+// before decoding them and invoking the callbacks, rejecting the requests
+// for objects with unknown or mistyped fields.
+func WithSchemas(schemas resourcesemantics.Schemas) OptionFunc {
+	return func(o *options) {
+		o.schemas = schemas
+	}
+}
+
+// WithSchemaWarnings reports the violations of the schemas given to
+// WithSchemas as warnings instead of rejecting the requests.
+func WithSchemaWarnings() OptionFunc {
+	return func(o *options) {
+		o.schemaWarnings = true
+	}
+}
+
+// WithNamespaceSelector restricts the webhook to the requests for resources
+// in the namespaces matching the given selector. The namespaces labeled
+// webhooks.knative.dev/exclude remain excluded.
+func WithNamespaceSelector(selector metav1.LabelSelector) OptionFunc {
+	return func(o *options) {
+		o.namespaceSelector = &selector
+	}
+}
+
+// WithObjectSelector restricts the webhook to the requests for resources
+// whose labels match the given selector.
+func WithObjectSelector(selector metav1.LabelSelector) OptionFunc {
+	return func(o *options) {
+		o.objectSelector = &selector
+	}
+}
+
+// WithWebhookPolicies registers the kinds with a policy with webhooks of
+// their own, e.g. failing open for low-risk kinds while the others fail
+// closed. The policies may be read from a ConfigMap with
+// resourcesemantics.NewWebhookPoliciesFromConfigMap.
+func WithWebhookPolicies(policies resourcesemantics.WebhookPolicies) OptionFunc {
+	return func(o *options) {
+		o.policies = policies
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+
 	kubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	_ "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret/fake"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -43,6 +45,7 @@ import (
 	"knative.dev/pkg/webhook/resourcesemantics"
 
 	. "knative.dev/pkg/reconciler/testing"
+	. "knative.dev/pkg/testing"
 	. "knative.dev/pkg/webhook/testing"
 )
 
@@ -456,6 +459,7 @@ func TestReconcile(t *testing.T) {
 			secretlister: listers.GetSecretLister(),
 
 			secretName: secretName,
+			caCertKey:  certresources.CACert,
 		}
 	}))
 }
@@ -493,3 +497,155 @@ func TestNew(t *testing.T) {
 		t.Error("Queue length was never 1")
 	}
 }
+
+func TestReconcileSelectors(t *testing.T) {
+	const name = "foo.bar.baz"
+	ctx, _ := SetupFakeContext(t)
+	wh := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{{
+			Name: name,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: system.Namespace(),
+					Name:      "webhook",
+				},
+			},
+			// A stale selector is replaced by the configured one.
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"stale": "true"},
+			},
+		}},
+	}
+	ctx, client := kubeclient.With(ctx, wh, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: system.Namespace()},
+	})
+	listers := NewListers([]runtime.Object{wh})
+
+	ac := &reconciler{
+		key:  types.NamespacedName{Name: name},
+		path: "/blah",
+
+		namespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"team": "serving"},
+		},
+		objectSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "app",
+				Operator: metav1.LabelSelectorOpExists,
+			}},
+		},
+
+		client:    client,
+		mwhlister: listers.GetMutatingWebhookConfigurationLister(),
+	}
+	if err := ac.reconcileMutatingWebhook(ctx, []byte("present")); err != nil {
+		t.Fatal("reconcileMutatingWebhook() =", err)
+	}
+
+	var got *admissionregistrationv1.MutatingWebhookConfiguration
+	for _, action := range client.Actions() {
+		if update, ok := action.(clientgotesting.UpdateAction); ok {
+			got = update.GetObject().(*admissionregistrationv1.MutatingWebhookConfiguration)
+		}
+	}
+	if got == nil {
+		t.Fatal("Expected the webhook configuration to be updated")
+	}
+
+	wantNamespaceSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"team": "serving"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "webhooks.knative.dev/exclude",
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		}},
+	}
+	if !cmp.Equal(got.Webhooks[0].NamespaceSelector, wantNamespaceSelector) {
+		t.Error("NamespaceSelector (-want, +got):", cmp.Diff(wantNamespaceSelector, got.Webhooks[0].NamespaceSelector))
+	}
+	if !cmp.Equal(got.Webhooks[0].ObjectSelector, ac.objectSelector) {
+		t.Error("ObjectSelector (-want, +got):", cmp.Diff(ac.objectSelector, got.Webhooks[0].ObjectSelector))
+	}
+}
+
+func TestReconcilePolicies(t *testing.T) {
+	const name = "foo.bar.baz"
+	ctx, _ := SetupFakeContext(t)
+	fail := admissionregistrationv1.Fail
+	ignore := admissionregistrationv1.Ignore
+	wh := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{{
+			Name: name,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: system.Namespace(),
+					Name:      "webhook",
+				},
+			},
+			FailurePolicy:  &fail,
+			TimeoutSeconds: ptr.Int32(10),
+		}, {
+			// A stale policy webhook is removed.
+			Name: "policy-ignore-inherit-inherit." + name,
+		}},
+	}
+	ctx, client := kubeclient.With(ctx, wh, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: system.Namespace()},
+	})
+	listers := NewListers([]runtime.Object{wh})
+
+	ac := &reconciler{
+		key:  types.NamespacedName{Name: name},
+		path: "/blah",
+		handlers: map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
+			{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "Resource"}:      &Resource{},
+			{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "OtherResource"}: &Resource{},
+		},
+		policies: resourcesemantics.WebhookPolicies{
+			{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "OtherResource"}: {
+				FailurePolicy:  &ignore,
+				TimeoutSeconds: ptr.Int32(2),
+			},
+		},
+
+		client:    client,
+		mwhlister: listers.GetMutatingWebhookConfigurationLister(),
+	}
+	if err := ac.reconcileMutatingWebhook(ctx, []byte("present")); err != nil {
+		t.Fatal("reconcileMutatingWebhook() =", err)
+	}
+
+	var got *admissionregistrationv1.MutatingWebhookConfiguration
+	for _, action := range client.Actions() {
+		if update, ok := action.(clientgotesting.UpdateAction); ok {
+			got = update.GetObject().(*admissionregistrationv1.MutatingWebhookConfiguration)
+		}
+	}
+	if got == nil {
+		t.Fatal("Expected the webhook configuration to be updated")
+	}
+
+	if len(got.Webhooks) != 2 {
+		t.Fatalf("Webhooks = %d, wanted 2", len(got.Webhooks))
+	}
+	base, policy := got.Webhooks[0], got.Webhooks[1]
+	if got, want := base.Rules[0].Resources[0], "resources"; len(base.Rules) != 1 || got != want {
+		t.Errorf("Base webhook rules = %v, wanted only %s", base.Rules, want)
+	}
+	if got, want := policy.Name, "policy-ignore-2s-inherit."+name; got != want {
+		t.Errorf("Policy webhook name = %q, wanted %q", got, want)
+	}
+	if got, want := policy.Rules[0].Resources[0], "otherresources"; len(policy.Rules) != 1 || got != want {
+		t.Errorf("Policy webhook rules = %v, wanted only %s", policy.Rules, want)
+	}
+	if *policy.FailurePolicy != ignore || *policy.TimeoutSeconds != 2 {
+		t.Errorf("Policy webhook = %v, %v, wanted Ignore, 2", *policy.FailurePolicy, *policy.TimeoutSeconds)
+	}
+	if *base.FailurePolicy != fail || *base.TimeoutSeconds != 10 {
+		t.Errorf("Base webhook = %v, %v, wanted Fail, 10", *base.FailurePolicy, *base.TimeoutSeconds)
+	}
+	if !cmp.Equal(policy.ClientConfig, base.ClientConfig) {
+		t.Error("Policy webhook ClientConfig (-want, +got):", cmp.Diff(base.ClientConfig, policy.ClientConfig))
+	}
+}
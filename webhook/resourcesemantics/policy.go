@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcesemantics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// policyWebhookPrefix prefixes the names of the webhooks generated for the
+// kinds with a WebhookPolicy.
+const policyWebhookPrefix = "policy-"
+
+// WebhookPolicy overrides the stance of the webhook for some kinds. The
+// unset fields are those of the webhook.
+type WebhookPolicy struct {
+	FailurePolicy  *admissionregistrationv1.FailurePolicyType `json:"failurePolicy,omitempty"`
+	TimeoutSeconds *int32                                     `json:"timeoutSeconds,omitempty"`
+	SideEffects    *admissionregistrationv1.SideEffectClass   `json:"sideEffects,omitempty"`
+}
+
+// name returns the name of the webhook of the kinds with the policy, among
+// the webhooks of the configuration named base.
+func (p WebhookPolicy) name(base string) string {
+	failure, timeout, sideEffects := "inherit", "inherit", "inherit"
+	if p.FailurePolicy != nil {
+		failure = strings.ToLower(string(*p.FailurePolicy))
+	}
+	if p.TimeoutSeconds != nil {
+		timeout = fmt.Sprint(*p.TimeoutSeconds, "s")
+	}
+	if p.SideEffects != nil {
+		sideEffects = strings.ToLower(string(*p.SideEffects))
+	}
+	return fmt.Sprintf("%s%s-%s-%s.%s", policyWebhookPrefix, failure, timeout, sideEffects, base)
+}
+
+// WebhookPolicies are the policies of the kinds whose stance differs from
+// the webhook's, e.g. to fail open for low-risk resources while the others
+// fail closed. The kinds with a policy are registered with a webhook of
+// their own in the webhook configuration.
+type WebhookPolicies map[schema.GroupVersionKind]WebhookPolicy
+
+// NewWebhookPoliciesFromConfigMap returns the WebhookPolicies of the
+// ConfigMap: its keys are the kinds, as Kind.version.group, e.g.
+// Deployment.v1.apps or Pod.v1 for the core group, and its values the YAML
+// of their policies, e.g.:
+//
+//	Deployment.v1.apps: |
+//	  failurePolicy: Ignore
+//	  timeoutSeconds: 5
+func NewWebhookPoliciesFromConfigMap(cm *corev1.ConfigMap) (WebhookPolicies, error) {
+	policies := make(WebhookPolicies, len(cm.Data))
+	for key, value := range cm.Data {
+		if strings.HasPrefix(key, "_") {
+			// Ignore the keys of the examples.
+			continue
+		}
+		parts := strings.SplitN(key, ".", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid kind %q, wanted Kind.version.group", key)
+		}
+		gvk := schema.GroupVersionKind{Kind: parts[0], Version: parts[1]}
+		if len(parts) == 3 {
+			gvk.Group = parts[2]
+		}
+		var policy WebhookPolicy
+		if err := yaml.UnmarshalStrict([]byte(value), &policy); err != nil {
+			return nil, fmt.Errorf("invalid policy of %q: %w", key, err)
+		}
+		policies[gvk] = policy
+	}
+	return policies, nil
+}
+
+// PolicyRules are the rules of the kinds sharing a WebhookPolicy, registered
+// with the webhook of the given name.
+type PolicyRules struct {
+	Name   string
+	Policy WebhookPolicy
+	Rules  []admissionregistrationv1.RuleWithOperations
+}
+
+// Split splits the rules of a webhook named base: it returns the rules of the
+// kinds without a policy, which remain with the webhook, and those of the
+// kinds with one, grouped by policy and sorted by webhook name.
+func (wp WebhookPolicies) Split(base string, rules []admissionregistrationv1.RuleWithOperations) ([]admissionregistrationv1.RuleWithOperations, []PolicyRules) {
+	if len(wp) == 0 {
+		return rules, nil
+	}
+	rest := make([]admissionregistrationv1.RuleWithOperations, 0, len(rules))
+	groups := make(map[string]*PolicyRules)
+	for _, rule := range rules {
+		policy, ok := wp.policyOf(rule)
+		if !ok {
+			rest = append(rest, rule)
+			continue
+		}
+		name := policy.name(base)
+		if groups[name] == nil {
+			groups[name] = &PolicyRules{Name: name, Policy: policy}
+		}
+		groups[name].Rules = append(groups[name].Rules, rule)
+	}
+
+	split := make([]PolicyRules, 0, len(groups))
+	for _, g := range groups {
+		split = append(split, *g)
+	}
+	sort.Slice(split, func(i, j int) bool { return split[i].Name < split[j].Name })
+	return rest, split
+}
+
+// policyOf returns the policy of the kind of the rule, as built by the
+// webhooks: for a single group and version, and the plural of the kind.
+func (wp WebhookPolicies) policyOf(rule admissionregistrationv1.RuleWithOperations) (WebhookPolicy, bool) {
+	if len(rule.APIGroups) != 1 || len(rule.APIVersions) != 1 {
+		return WebhookPolicy{}, false
+	}
+	for gvk, policy := range wp {
+		if gvk.Group != rule.APIGroups[0] || gvk.Version != rule.APIVersions[0] {
+			continue
+		}
+		plural := strings.ToLower(flect.Pluralize(gvk.Kind))
+		for _, resource := range rule.Resources {
+			if strings.SplitN(resource, "/", 2)[0] == plural {
+				return policy, true
+			}
+		}
+	}
+	return WebhookPolicy{}, false
+}
+
+// IsPolicyWebhook returns whether the webhook of the given name was generated
+// for the kinds with a policy of the webhook named base.
+func IsPolicyWebhook(base, name string) bool {
+	return strings.HasPrefix(name, policyWebhookPrefix) && strings.HasSuffix(name, "."+base)
+}
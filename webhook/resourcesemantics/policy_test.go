@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcesemantics
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/pkg/ptr"
+)
+
+func TestNewWebhookPoliciesFromConfigMap(t *testing.T) {
+	ignore := admissionregistrationv1.Ignore
+	none := admissionregistrationv1.SideEffectClassNone
+
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    WebhookPolicies
+		wantErr bool
+	}{{
+		name: "empty",
+		want: WebhookPolicies{},
+	}, {
+		name: "policies",
+		data: map[string]string{
+			"_example":           "ignored",
+			"Deployment.v1.apps": "failurePolicy: Ignore\ntimeoutSeconds: 5",
+			"Pod.v1":             "sideEffects: None",
+		},
+		want: WebhookPolicies{
+			{Group: "apps", Version: "v1", Kind: "Deployment"}: {FailurePolicy: &ignore, TimeoutSeconds: ptr.Int32(5)},
+			{Version: "v1", Kind: "Pod"}:                       {SideEffects: &none},
+		},
+	}, {
+		name:    "no version",
+		data:    map[string]string{"Pod": "failurePolicy: Ignore"},
+		wantErr: true,
+	}, {
+		name:    "unknown field",
+		data:    map[string]string{"Pod.v1": "failure: Ignore"},
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewWebhookPoliciesFromConfigMap(&corev1.ConfigMap{Data: tc.data})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("NewWebhookPoliciesFromConfigMap() = %v, wanted error: %v", err, tc.wantErr)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Error("NewWebhookPoliciesFromConfigMap() (-want, +got):", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestWebhookPoliciesSplit(t *testing.T) {
+	ignore := admissionregistrationv1.Ignore
+	rule := func(group, version, plural string) admissionregistrationv1.RuleWithOperations {
+		return admissionregistrationv1.RuleWithOperations{
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{group},
+				APIVersions: []string{version},
+				Resources:   []string{plural, plural + "/status"},
+			},
+		}
+	}
+	deployments, pods, services := rule("apps", "v1", "deployments"), rule("", "v1", "pods"), rule("", "v1", "services")
+
+	policies := WebhookPolicies{
+		{Group: "apps", Version: "v1", Kind: "Deployment"}: {FailurePolicy: &ignore},
+		{Version: "v1", Kind: "Service"}:                   {FailurePolicy: &ignore},
+		{Version: "v1", Kind: "Pod"}:                       {TimeoutSeconds: ptr.Int32(3)},
+	}
+	rest, split := policies.Split("base", []admissionregistrationv1.RuleWithOperations{deployments, pods, services})
+	if len(rest) != 0 {
+		t.Errorf("Split() rest = %v, wanted none", rest)
+	}
+	want := []PolicyRules{{
+		Name:   "policy-ignore-inherit-inherit.base",
+		Policy: WebhookPolicy{FailurePolicy: &ignore},
+		Rules:  []admissionregistrationv1.RuleWithOperations{deployments, services},
+	}, {
+		Name:   "policy-inherit-3s-inherit.base",
+		Policy: WebhookPolicy{TimeoutSeconds: ptr.Int32(3)},
+		Rules:  []admissionregistrationv1.RuleWithOperations{pods},
+	}}
+	if !cmp.Equal(split, want) {
+		t.Error("Split() (-want, +got):", cmp.Diff(want, split))
+	}
+	for _, pr := range split {
+		if !IsPolicyWebhook("base", pr.Name) {
+			t.Errorf("IsPolicyWebhook(%q) = false, wanted true", pr.Name)
+		}
+	}
+	if IsPolicyWebhook("base", "base") {
+		t.Error("IsPolicyWebhook(base) = true, wanted false")
+	}
+
+	rest, split = WebhookPolicies(nil).Split("base", []admissionregistrationv1.RuleWithOperations{pods})
+	if len(rest) != 1 || len(split) != 0 {
+		t.Errorf("Split() without policies = %v, %v, wanted the rules unchanged", rest, split)
+	}
+}
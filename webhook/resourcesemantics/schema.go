@@ -0,0 +1,183 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcesemantics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/apis"
+)
+
+// Schemas holds the structural OpenAPI v3 schemas of kinds, against which
+// the admission controllers check the raw objects before decoding them, so
+// that unknown or mistyped fields are not silently dropped.
+type Schemas map[schema.GroupVersionKind]*apixv1.JSONSchemaProps
+
+// SchemasFromCRD returns the schemas of the served versions of the given CRD.
+func SchemasFromCRD(crd *apixv1.CustomResourceDefinition) Schemas {
+	schemas := make(Schemas, len(crd.Spec.Versions))
+	for _, v := range crd.Spec.Versions {
+		if !v.Served || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		gvk := schema.GroupVersionKind{
+			Group:   crd.Spec.Group,
+			Version: v.Name,
+			Kind:    crd.Spec.Names.Kind,
+		}
+		schemas[gvk] = v.Schema.OpenAPIV3Schema
+	}
+	return schemas
+}
+
+// Validate checks the raw JSON object of the given kind against its schema,
+// returning the fields it does not know and the values of the wrong type.
+// Objects of kinds without a schema are not checked.
+func (s Schemas) Validate(gvk schema.GroupVersionKind, raw []byte) *apis.FieldError {
+	props, ok := s[gvk]
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	// Keep the numbers as they were sent to tell integers from floats.
+	dec.UseNumber()
+	var obj interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprint("cannot decode object: ", err),
+			Paths:   []string{apis.CurrentField},
+		}
+	}
+	return validateValue(obj, props, true /* embedded */)
+}
+
+// embeddedFields are the fields of resources the schemas need not declare.
+var embeddedFields = map[string]struct{}{
+	"apiVersion": {},
+	"kind":       {},
+	"metadata":   {},
+}
+
+func validateValue(v interface{}, props *apixv1.JSONSchemaProps, embedded bool) *apis.FieldError {
+	if v == nil {
+		// Nulls are dropped by the apiserver when not nullable, which is not
+		// a structural error.
+		return nil
+	}
+	if props.XIntOrString {
+		switch v.(type) {
+		case string, json.Number:
+			return nil
+		}
+		return errWrongType(v, "integer or string")
+	}
+
+	switch props.Type {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return errWrongType(v, props.Type)
+		}
+		return validateObject(m, props, embedded || props.XEmbeddedResource)
+
+	case "array":
+		a, ok := v.([]interface{})
+		if !ok {
+			return errWrongType(v, props.Type)
+		}
+		if props.Items == nil || props.Items.Schema == nil {
+			return nil
+		}
+		var errs *apis.FieldError
+		for i, item := range a {
+			errs = errs.Also(validateValue(item, props.Items.Schema, false).ViaIndex(i))
+		}
+		return errs
+
+	case "string":
+		if _, ok := v.(string); !ok {
+			return errWrongType(v, props.Type)
+		}
+
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return errWrongType(v, props.Type)
+		}
+		if _, err := n.Int64(); err != nil {
+			return errWrongType(v, props.Type)
+		}
+
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return errWrongType(v, props.Type)
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return errWrongType(v, props.Type)
+		}
+	}
+	return nil
+}
+
+func validateObject(m map[string]interface{}, props *apixv1.JSONSchemaProps, embedded bool) *apis.FieldError {
+	preserveUnknown := props.XPreserveUnknownFields != nil && *props.XPreserveUnknownFields
+
+	// Check the fields in order for the errors to be stable.
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs *apis.FieldError
+	for _, k := range keys {
+		if _, ok := embeddedFields[k]; ok && embedded {
+			// The apiserver validates the type and object metadata itself.
+			continue
+		}
+		if p, ok := props.Properties[k]; ok {
+			p := p
+			errs = errs.Also(validateValue(m[k], &p, false).ViaField(k))
+			continue
+		}
+		if props.AdditionalProperties != nil {
+			if ap := props.AdditionalProperties.Schema; ap != nil {
+				errs = errs.Also(validateValue(m[k], ap, false).ViaKey(k))
+				continue
+			}
+			if props.AdditionalProperties.Allows {
+				continue
+			}
+		}
+		if !preserveUnknown {
+			errs = errs.Also(apis.ErrDisallowedFields(k))
+		}
+	}
+	return errs
+}
+
+func errWrongType(v interface{}, want string) *apis.FieldError {
+	return apis.ErrInvalidValue(v, apis.CurrentField, "expected "+want)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcesemantics
+
+import (
+	"testing"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var testGVK = schema.GroupVersionKind{
+	Group:   "pkg.knative.dev",
+	Version: "v1alpha1",
+	Kind:    "Resource",
+}
+
+func testSchema() *apixv1.JSONSchemaProps {
+	preserve := true
+	return &apixv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apixv1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apixv1.JSONSchemaProps{
+					"name":     {Type: "string"},
+					"replicas": {Type: "integer"},
+					"ratio":    {Type: "number"},
+					"enabled":  {Type: "boolean"},
+					"port":     {XIntOrString: true},
+					"args": {
+						Type: "array",
+						Items: &apixv1.JSONSchemaPropsOrArray{
+							Schema: &apixv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"labels": {
+						Type: "object",
+						AdditionalProperties: &apixv1.JSONSchemaPropsOrBool{
+							Schema: &apixv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"config": {
+						Type:                   "object",
+						XPreserveUnknownFields: &preserve,
+					},
+					"template": {
+						Type:              "object",
+						XEmbeddedResource: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSchemasValidate(t *testing.T) {
+	schemas := Schemas{testGVK: testSchema()}
+
+	tests := []struct {
+		name string
+		gvk  schema.GroupVersionKind
+		raw  string
+		want string
+	}{{
+		name: "valid",
+		gvk:  testGVK,
+		raw: `{"apiVersion":"pkg.knative.dev/v1alpha1","kind":"Resource","metadata":{"name":"foo"},
+			"spec":{"name":"foo","replicas":2,"ratio":0.5,"enabled":true,"port":"http","args":["a"],
+			"labels":{"a":"b"},"config":{"anything":1},"template":{"apiVersion":"v1","kind":"Pod"}}}`,
+	}, {
+		name: "null",
+		gvk:  testGVK,
+		raw:  `{"spec":{"name":null}}`,
+	}, {
+		name: "unknown kind",
+		gvk:  testGVK.GroupKind().WithVersion("v1"),
+		raw:  `{"spec":{"foo":"bar"}}`,
+	}, {
+		name: "unknown field",
+		gvk:  testGVK,
+		raw:  `{"spec":{"foo":"bar"}}`,
+		want: "must not set the field(s): spec.foo",
+	}, {
+		name: "mistyped field",
+		gvk:  testGVK,
+		raw:  `{"spec":{"replicas":"2"}}`,
+		want: "invalid value: 2: spec.replicas\nexpected integer",
+	}, {
+		name: "float for an integer",
+		gvk:  testGVK,
+		raw:  `{"spec":{"replicas":1.5}}`,
+		want: "invalid value: 1.5: spec.replicas\nexpected integer",
+	}, {
+		name: "mistyped array item",
+		gvk:  testGVK,
+		raw:  `{"spec":{"args":["a",1]}}`,
+		want: "invalid value: 1: spec.args[1]\nexpected string",
+	}, {
+		name: "mistyped map value",
+		gvk:  testGVK,
+		raw:  `{"spec":{"labels":{"a":true}}}`,
+		want: "invalid value: true: spec.labels[a]\nexpected string",
+	}, {
+		name: "nested metadata",
+		gvk:  testGVK,
+		raw:  `{"spec":{"metadata":{}}}`,
+		want: "must not set the field(s): spec.metadata",
+	}, {
+		name: "not json",
+		gvk:  testGVK,
+		raw:  `{`,
+		want: "cannot decode object: unexpected EOF: ",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schemas.Validate(test.gvk, []byte(test.raw))
+			if got := err.Error(); got != test.want {
+				t.Errorf("Validate() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSchemasFromCRD(t *testing.T) {
+	crd := &apixv1.CustomResourceDefinition{
+		Spec: apixv1.CustomResourceDefinitionSpec{
+			Group: testGVK.Group,
+			Names: apixv1.CustomResourceDefinitionNames{Kind: testGVK.Kind},
+			Versions: []apixv1.CustomResourceDefinitionVersion{{
+				Name:   testGVK.Version,
+				Served: true,
+				Schema: &apixv1.CustomResourceValidation{OpenAPIV3Schema: testSchema()},
+			}, {
+				Name:   "v1alpha0",
+				Schema: &apixv1.CustomResourceValidation{OpenAPIV3Schema: testSchema()},
+			}},
+		},
+	}
+
+	got := SchemasFromCRD(crd)
+	if _, ok := got[testGVK]; !ok || len(got) != 1 {
+		t.Errorf("SchemasFromCRD() = %v, wanted only %v", got, testGVK)
+	}
+}
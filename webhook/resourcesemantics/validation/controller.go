@@ -28,6 +28,9 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	admissionregistrationv1informers "k8s.io/client-go/informers/admissionregistration/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/system"
@@ -59,10 +62,29 @@ func NewAdmissionControllerWithConfig(
 	return newController(ctx, name, opts...)
 }
 
+// NewAdmissionControllerWithOptions constructs a reconciler configured by
+// the given options, e.g. WithPath, WithTypes and WithUpdateDiffs.
+func NewAdmissionControllerWithOptions(ctx context.Context, name string, optsFunc ...OptionFunc) *controller.Impl {
+	return newController(ctx, name, optsFunc...)
+}
+
 func newController(ctx context.Context, name string, optsFunc ...OptionFunc) *controller.Impl {
-	client := kubeclient.Get(ctx)
-	vwhInformer := vwhinformer.Get(ctx)
-	secretInformer := secretinformer.Get(ctx)
+	return NewAdmissionControllerWithInformers(ctx, name,
+		kubeclient.Get(ctx), vwhinformer.Get(ctx), secretinformer.Get(ctx), optsFunc...)
+}
+
+// NewAdmissionControllerWithInformers constructs a reconciler without relying
+// on injection, from the given client and informers. The secret informer must
+// cover the system namespace. The caller is responsible for running the
+// informers, see webhook.Standalone.
+func NewAdmissionControllerWithInformers(
+	ctx context.Context,
+	name string,
+	client kubernetes.Interface,
+	vwhInformer admissionregistrationv1informers.ValidatingWebhookConfigurationInformer,
+	secretInformer corev1informers.SecretInformer,
+	optsFunc ...OptionFunc,
+) *controller.Impl {
 	woptions := webhook.GetOptions(ctx)
 
 	opts := &options{}
@@ -87,9 +109,17 @@ func newController(ctx context.Context, name string, optsFunc ...OptionFunc) *co
 		handlers:  opts.types,
 		callbacks: opts.callbacks,
 
+		updateDiffs:       opts.updateDiffs,
+		namespaceSelector: opts.namespaceSelector,
+		objectSelector:    opts.objectSelector,
+		policies:          opts.policies,
+		schemas:           opts.schemas,
+		schemaWarnings:    opts.schemaWarnings,
+
 		withContext:           opts.wc,
 		disallowUnknownFields: opts.DisallowUnknownFields(),
-		secretName:            woptions.SecretName,
+		secretName:            woptions.CABundleSecretNameFor(opts.path),
+		caCertKey:             woptions.CABundleKey(),
 
 		client:       client,
 		vwhlister:    vwhInformer.Lister(),
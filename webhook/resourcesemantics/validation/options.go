@@ -19,6 +19,7 @@ package validation
 import (
 	"context"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/webhook/resourcesemantics"
 )
@@ -29,6 +30,12 @@ type options struct {
 	wc                    func(context.Context) context.Context
 	disallowUnknownFields bool
 	callbacks             map[schema.GroupVersionKind]Callback
+	namespaceSelector     *metav1.LabelSelector
+	objectSelector        *metav1.LabelSelector
+	policies              resourcesemantics.WebhookPolicies
+	updateDiffs           map[schema.GroupVersionKind]struct{}
+	schemas               resourcesemantics.Schemas
+	schemaWarnings        bool
 }
 
 type OptionFunc func(*options)
@@ -63,6 +70,65 @@ func WithDisallowUnknownFields() OptionFunc {
 	}
 }
 
+// WithUpdateDiffs attaches to the rejections of updates of the given kinds
+// the diffs of the old and new values of the offending fields, as causes of
+// the response status. Kinds holding sensitive data should be left out, as
+// the diffs expose their values.
+func WithUpdateDiffs(kinds ...schema.GroupVersionKind) OptionFunc {
+	return func(o *options) {
+		if o.updateDiffs == nil {
+			o.updateDiffs = make(map[schema.GroupVersionKind]struct{}, len(kinds))
+		}
+		for _, kind := range kinds {
+			o.updateDiffs[kind] = struct{}{}
+		}
+	}
+}
+
+// WithSchemas checks the raw objects of the kinds with a schema against it
+// before decoding them and invoking the callbacks, rejecting the requests
+// for objects with unknown or mistyped fields.
+func WithSchemas(schemas resourcesemantics.Schemas) OptionFunc {
+	return func(o *options) {
+		o.schemas = schemas
+	}
+}
+
+// WithSchemaWarnings reports the violations of the schemas given to
+// WithSchemas as warnings instead of rejecting the requests.
+func WithSchemaWarnings() OptionFunc {
+	return func(o *options) {
+		o.schemaWarnings = true
+	}
+}
+
+// WithNamespaceSelector restricts the webhook to the requests for resources
+// in the namespaces matching the given selector. The namespaces labeled
+// webhooks.knative.dev/exclude remain excluded.
+func WithNamespaceSelector(selector metav1.LabelSelector) OptionFunc {
+	return func(o *options) {
+		o.namespaceSelector = &selector
+	}
+}
+
+// WithObjectSelector restricts the webhook to the requests for resources
+// whose labels match the given selector.
+func WithObjectSelector(selector metav1.LabelSelector) OptionFunc {
+	return func(o *options) {
+		o.objectSelector = &selector
+	}
+}
+
+// WithWebhookPolicies registers the kinds with a policy with webhooks of
+// their own, e.g. failing open for low-risk kinds while the others fail
+// closed. The policies may be read from a ConfigMap with
+// resourcesemantics.NewWebhookPoliciesFromConfigMap.
+func WithWebhookPolicies(policies resourcesemantics.WebhookPolicies) OptionFunc {
+	return func(o *options) {
+		o.policies = policies
+	}
+}
+
 func (o *options) DisallowUnknownFields() bool {
 	return o.disallowUnknownFields
 }
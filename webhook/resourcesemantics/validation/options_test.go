@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/webhook/resourcesemantics"
 )
@@ -27,18 +28,31 @@ import (
 func TestOptions(t *testing.T) {
 	callbacks := map[schema.GroupVersionKind]Callback{}
 	types := map[schema.GroupVersionKind]resourcesemantics.GenericCRD{}
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}}
+	schemas := resourcesemantics.Schemas{}
+	gvk := schema.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1", Kind: "Resource"}
 
 	got := &options{}
 	WithCallbacks(callbacks)(got)
 	WithDisallowUnknownFields()(got)
 	WithPath("path")(got)
 	WithTypes(types)(got)
+	WithNamespaceSelector(selector)(got)
+	WithObjectSelector(selector)(got)
+	WithSchemas(schemas)(got)
+	WithSchemaWarnings()(got)
+	WithUpdateDiffs(gvk)(got)
 
 	want := &options{
 		callbacks:             callbacks,
 		disallowUnknownFields: true,
 		path:                  "path",
 		types:                 types,
+		namespaceSelector:     &selector,
+		objectSelector:        &selector,
+		schemas:               schemas,
+		schemaWarnings:        true,
+		updateDiffs:           map[schema.GroupVersionKind]struct{}{gvk: {}},
 		// we can't compare wc as functions are not
 		// comparable in golang (thus it needs to be
 		// done indirectly)
@@ -40,7 +40,6 @@ import (
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/webhook"
-	certresources "knative.dev/pkg/webhook/certificates/resources"
 	"knative.dev/pkg/webhook/resourcesemantics"
 )
 
@@ -54,6 +53,24 @@ type reconciler struct {
 	handlers  map[schema.GroupVersionKind]resourcesemantics.GenericCRD
 	callbacks map[schema.GroupVersionKind]Callback
 
+	// updateDiffs are the kinds whose update rejections detail the diffs of
+	// the offending fields.
+	updateDiffs map[schema.GroupVersionKind]struct{}
+
+	// namespaceSelector and objectSelector, when set, are reconciled into
+	// the webhook configuration.
+	namespaceSelector *metav1.LabelSelector
+	objectSelector    *metav1.LabelSelector
+
+	// policies are the failure policies, timeouts and side effects of the
+	// kinds whose webhooks differ from the configured one.
+	policies resourcesemantics.WebhookPolicies
+
+	// schemas are checked against the raw objects before decoding them,
+	// rejecting the violations unless schemaWarnings is set.
+	schemas        resourcesemantics.Schemas
+	schemaWarnings bool
+
 	withContext func(context.Context) context.Context
 
 	client       kubernetes.Interface
@@ -62,6 +79,7 @@ type reconciler struct {
 
 	disallowUnknownFields bool
 	secretName            string
+	caCertKey             string
 }
 
 var _ controller.Reconciler = (*reconciler)(nil)
@@ -88,9 +106,9 @@ func (ac *reconciler) Reconcile(ctx context.Context, key string) error {
 		logger.Errorw("Error fetching secret", zap.Error(err))
 		return err
 	}
-	caCert, ok := secret.Data[certresources.CACert]
+	caCert, ok := secret.Data[ac.caCertKey]
 	if !ok {
-		return fmt.Errorf("secret %q is missing %q key", ac.secretName, certresources.CACert)
+		return fmt.Errorf("secret %q is missing %q key", ac.secretName, ac.caCertKey)
 	}
 
 	// Reconcile the webhook configuration.
@@ -199,6 +217,9 @@ func (ac *reconciler) reconcileValidatingWebhook(ctx context.Context, caCert []b
 	nsRef := *metav1.NewControllerRef(ns, corev1.SchemeGroupVersion.WithKind("Namespace"))
 	current.OwnerReferences = []metav1.OwnerReference{nsRef}
 
+	// The kinds with a policy are moved to webhooks of their own.
+	rules, policyRules := ac.policies.Split(current.Name, rules)
+
 	for i, wh := range current.Webhooks {
 		if wh.Name != current.Name {
 			continue
@@ -206,14 +227,25 @@ func (ac *reconciler) reconcileValidatingWebhook(ctx context.Context, caCert []b
 		cur := &current.Webhooks[i]
 		cur.Rules = rules
 
-		cur.NamespaceSelector = webhook.EnsureLabelSelectorExpressions(
-			cur.NamespaceSelector,
-			&metav1.LabelSelector{
-				MatchExpressions: []metav1.LabelSelectorRequirement{{
-					Key:      "webhooks.knative.dev/exclude",
-					Operator: metav1.LabelSelectorOpDoesNotExist,
-				}},
-			})
+		exclude := metav1.LabelSelectorRequirement{
+			Key:      "webhooks.knative.dev/exclude",
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		}
+		if ac.namespaceSelector != nil {
+			// The configured selector owns the field, so drift is corrected.
+			selector := ac.namespaceSelector.DeepCopy()
+			selector.MatchExpressions = append(selector.MatchExpressions, exclude)
+			cur.NamespaceSelector = selector
+		} else {
+			cur.NamespaceSelector = webhook.EnsureLabelSelectorExpressions(
+				cur.NamespaceSelector,
+				&metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{exclude},
+				})
+		}
+		if ac.objectSelector != nil {
+			cur.ObjectSelector = ac.objectSelector.DeepCopy()
+		}
 
 		cur.ClientConfig.CABundle = caCert
 		if cur.ClientConfig.Service == nil {
@@ -221,6 +253,7 @@ func (ac *reconciler) reconcileValidatingWebhook(ctx context.Context, caCert []b
 		}
 		cur.ClientConfig.Service.Path = ptr.String(ac.Path())
 	}
+	current.Webhooks = withPolicyWebhooks(current.Name, current.Webhooks, policyRules)
 
 	if ok, err := kmp.SafeEqual(configuredWebhook, current); err != nil {
 		return fmt.Errorf("error diffing webhooks: %w", err)
@@ -235,3 +268,41 @@ func (ac *reconciler) reconcileValidatingWebhook(ctx context.Context, caCert []b
 	}
 	return nil
 }
+
+// withPolicyWebhooks returns the webhooks with those of the kinds with a
+// policy, copied from the webhook named base with the policy applied, in
+// place of the previous ones.
+func withPolicyWebhooks(base string, webhooks []admissionregistrationv1.ValidatingWebhook, policyRules []resourcesemantics.PolicyRules) []admissionregistrationv1.ValidatingWebhook {
+	var baseWebhook *admissionregistrationv1.ValidatingWebhook
+	kept := make([]admissionregistrationv1.ValidatingWebhook, 0, len(webhooks)+len(policyRules))
+	for _, wh := range webhooks {
+		if resourcesemantics.IsPolicyWebhook(base, wh.Name) {
+			continue
+		}
+		kept = append(kept, wh)
+		if wh.Name == base {
+			baseWebhook = &kept[len(kept)-1]
+		}
+	}
+	if baseWebhook == nil {
+		return kept
+	}
+
+	policyWebhooks := make([]admissionregistrationv1.ValidatingWebhook, 0, len(policyRules))
+	for _, pr := range policyRules {
+		wh := *baseWebhook.DeepCopy()
+		wh.Name = pr.Name
+		wh.Rules = pr.Rules
+		if pr.Policy.FailurePolicy != nil {
+			wh.FailurePolicy = pr.Policy.FailurePolicy
+		}
+		if pr.Policy.TimeoutSeconds != nil {
+			wh.TimeoutSeconds = pr.Policy.TimeoutSeconds
+		}
+		if pr.Policy.SideEffects != nil {
+			wh.SideEffects = pr.Policy.SideEffects
+		}
+		policyWebhooks = append(policyWebhooks, wh)
+	}
+	return append(kept, policyWebhooks...)
+}
@@ -21,6 +21,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+
 	kubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	_ "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret/fake"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -569,6 +571,7 @@ func TestReconcile(t *testing.T) {
 			secretlister: listers.GetSecretLister(),
 
 			secretName: secretName,
+			caCertKey:  certresources.CACert,
 		}
 	}))
 }
@@ -608,3 +611,73 @@ func TestNew(t *testing.T) {
 	}
 
 }
+
+func TestReconcileSelectors(t *testing.T) {
+	const name = "foo.bar.baz"
+	ctx, _ := SetupFakeContext(t)
+	wh := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name: name,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: system.Namespace(),
+					Name:      "webhook",
+				},
+			},
+			// A stale selector is replaced by the configured one.
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"stale": "true"},
+			},
+		}},
+	}
+	ctx, client := kubeclient.With(ctx, wh, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: system.Namespace()},
+	})
+	listers := NewListers([]runtime.Object{wh})
+
+	ac := &reconciler{
+		key:  types.NamespacedName{Name: name},
+		path: "/blah",
+
+		namespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"team": "serving"},
+		},
+		objectSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "app",
+				Operator: metav1.LabelSelectorOpExists,
+			}},
+		},
+
+		client:    client,
+		vwhlister: listers.GetValidatingWebhookConfigurationLister(),
+	}
+	if err := ac.reconcileValidatingWebhook(ctx, []byte("present")); err != nil {
+		t.Fatal("reconcileValidatingWebhook() =", err)
+	}
+
+	var got *admissionregistrationv1.ValidatingWebhookConfiguration
+	for _, action := range client.Actions() {
+		if update, ok := action.(clientgotesting.UpdateAction); ok {
+			got = update.GetObject().(*admissionregistrationv1.ValidatingWebhookConfiguration)
+		}
+	}
+	if got == nil {
+		t.Fatal("Expected the webhook configuration to be updated")
+	}
+
+	wantNamespaceSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"team": "serving"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "webhooks.knative.dev/exclude",
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		}},
+	}
+	if !cmp.Equal(got.Webhooks[0].NamespaceSelector, wantNamespaceSelector) {
+		t.Error("NamespaceSelector (-want, +got):", cmp.Diff(wantNamespaceSelector, got.Webhooks[0].NamespaceSelector))
+	}
+	if !cmp.Equal(got.Webhooks[0].ObjectSelector, ac.objectSelector) {
+		t.Error("ObjectSelector (-want, +got):", cmp.Diff(ac.objectSelector, got.Webhooks[0].ObjectSelector))
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmp"
+	"knative.dev/pkg/webhook/json"
+)
+
+// updateDiffs returns the causes detailing how the fields of the validation
+// errors of the update request changed, as diffs of their old and new values.
+func updateDiffs(req *admissionv1.AdmissionRequest, err error) []metav1.StatusCause {
+	var fe *apis.FieldError
+	if !errors.As(err, &fe) {
+		return nil
+	}
+
+	var oldObj, newObj map[string]interface{}
+	if json.Unmarshal(req.OldObject.Raw, &oldObj) != nil || json.Unmarshal(req.Object.Raw, &newObj) != nil {
+		return nil
+	}
+
+	var causes []metav1.StatusCause
+	seen := make(map[string]struct{})
+	for _, e := range fe.WrappedErrors() {
+		for _, path := range e.Paths {
+			if _, ok := seen[path]; ok {
+				continue
+			}
+			seen[path] = struct{}{}
+
+			oldValue, hasOld := fieldValue(oldObj, path)
+			newValue, hasNew := fieldValue(newObj, path)
+			if !hasOld && !hasNew {
+				continue
+			}
+			diff, err := kmp.ShortDiff(oldValue, newValue)
+			if err != nil || diff == "" {
+				continue
+			}
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Field:   path,
+				Message: e.Message + " (-old +new):\n" + diff,
+			})
+		}
+	}
+	return causes
+}
+
+// fieldValue returns the value at the path of a FieldError, e.g.
+// "spec.containers[0].env[FOO]", within the JSON object.
+func fieldValue(obj interface{}, path string) (interface{}, bool) {
+	for _, part := range splitPath(path) {
+		switch o := obj.(type) {
+		case map[string]interface{}:
+			v, ok := o[part]
+			if !ok {
+				return nil, false
+			}
+			obj = v
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(o) {
+				return nil, false
+			}
+			obj = o[i]
+		default:
+			return nil, false
+		}
+	}
+	return obj, true
+}
+
+// splitPath splits the path of a FieldError into field names, keys and
+// indices, keeping keys with dots, e.g. label names, in one piece.
+func splitPath(path string) []string {
+	var parts []string
+	for path != "" {
+		switch {
+		case path[0] == '.':
+			path = path[1:]
+		case path[0] == '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return append(parts, path[1:])
+			}
+			parts = append(parts, path[1:end])
+			path = path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				return append(parts, path)
+			}
+			parts = append(parts, path[:end])
+			path = path[end:]
+		}
+	}
+	return parts
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+
+	. "knative.dev/pkg/logging/testing"
+	. "knative.dev/pkg/testing"
+	. "knative.dev/pkg/webhook/testing"
+)
+
+func TestAdmitUpdateDiffs(t *testing.T) {
+	resourceGVK := schema.GroupVersionKind{
+		Group:   "pkg.knative.dev",
+		Version: "v1alpha1",
+		Kind:    "Resource",
+	}
+
+	tests := []struct {
+		name        string
+		updateDiffs map[schema.GroupVersionKind]struct{}
+		mutate      func(context.Context, *Resource)
+		want        []metav1.StatusCause
+	}{{
+		name:        "immutable field changed",
+		updateDiffs: map[schema.GroupVersionKind]struct{}{resourceGVK: {}},
+		mutate: func(ctx context.Context, r *Resource) {
+			r.Spec.FieldThatsImmutableWithDefault = "something different"
+		},
+		want: []metav1.StatusCause{{
+			Type:  metav1.CauseTypeFieldValueInvalid,
+			Field: "spec.fieldThatsImmutableWithDefault",
+		}},
+	}, {
+		name:        "several fields",
+		updateDiffs: map[schema.GroupVersionKind]struct{}{resourceGVK: {}},
+		mutate: func(ctx context.Context, r *Resource) {
+			r.Spec.FieldThatsImmutable = "something different"
+			r.Spec.FieldWithValidation = "not what's expected"
+		},
+		want: []metav1.StatusCause{{
+			Type:  metav1.CauseTypeFieldValueInvalid,
+			Field: "spec.fieldThatsImmutable",
+		}, {
+			Type:  metav1.CauseTypeFieldValueInvalid,
+			Field: "spec.fieldWithValidation",
+		}},
+	}, {
+		name: "kind not opted in",
+		updateDiffs: map[schema.GroupVersionKind]struct{}{
+			resourceGVK.GroupKind().WithVersion("v1beta1"): {},
+		},
+		mutate: func(ctx context.Context, r *Resource) {
+			r.Spec.FieldThatsImmutableWithDefault = "something different"
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := TestContextWithLogger(t)
+			old := CreateResource("a name")
+			old.SetDefaults(ctx)
+			old.Spec.FieldWithValidation = "magic value"
+
+			new := old.DeepCopy()
+			ctx = apis.WithUserInfo(apis.WithinUpdate(ctx, old),
+				&authenticationv1.UserInfo{Username: user2})
+			tc.mutate(ctx, new)
+
+			_, ac := newNonRunningTestResourceAdmissionController(t)
+			ac.(*reconciler).updateDiffs = tc.updateDiffs
+			req := createUpdateResource(ctx, t, old, new, "")
+			req.Name = new.Name
+			resp := ac.Admit(ctx, req)
+			if resp.Allowed {
+				t.Fatal("Admit() allowed the update")
+			}
+
+			var got []metav1.StatusCause
+			if resp.Result.Details != nil {
+				if d := resp.Result.Details; d.Name != new.Name || d.Kind != "Resource" {
+					t.Errorf("Details = %s %s, wanted Resource %s", d.Kind, d.Name, new.Name)
+				}
+				got = resp.Result.Details.Causes
+			}
+			// Check the messages apart, as they hold the diffs.
+			for i, cause := range got {
+				if !strings.Contains(cause.Message, "(-old +new)") {
+					t.Errorf("Cause %q message = %q, wanted a diff", cause.Field, cause.Message)
+				}
+				got[i].Message = ""
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Error("Causes (-want, +got):", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/name": "foo",
+			},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"image": "busybox"},
+			},
+		},
+	}
+
+	tests := []struct {
+		path   string
+		want   interface{}
+		wantOK bool
+	}{{
+		path:   "metadata.labels[app.kubernetes.io/name]",
+		want:   "foo",
+		wantOK: true,
+	}, {
+		path:   "spec.containers[0].image",
+		want:   "busybox",
+		wantOK: true,
+	}, {
+		path: "spec.containers[1].image",
+	}, {
+		path: "spec.containers[x]",
+	}, {
+		path: "spec.missing",
+	}, {
+		path: "spec.containers[0].image.tag",
+	}}
+
+	for _, tc := range tests {
+		got, ok := fieldValue(obj, tc.path)
+		if ok != tc.wantOK || got != tc.want {
+			t.Errorf("fieldValue(%q) = %v, %v, wanted %v, %v", tc.path, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
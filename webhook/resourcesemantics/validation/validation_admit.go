@@ -23,6 +23,7 @@ import (
 
 	"go.uber.org/zap"
 	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/apis"
@@ -37,11 +38,17 @@ var errMissingNewObject = errors.New("the new object may not be nil")
 
 // Callback is a generic function to be called by a consumer of validation
 type Callback struct {
-	// function is the callback to be invoked
+	// function is the callback to be invoked. The context it is invoked with
+	// satisfies apis.IsDryRun for dry-run requests, in which case it must not
+	// have side effects.
 	function func(ctx context.Context, unstructured *unstructured.Unstructured) error
 
 	// supportedVerbs are the verbs supported for the callback.
 	supportedVerbs map[webhook.Operation]struct{}
+
+	// sideEffects is whether the function has side effects, in which case
+	// it is not called on dry-run requests.
+	sideEffects bool
 }
 
 // NewCallback creates a new callback function to be invoked on supported verbs.
@@ -56,6 +63,13 @@ func NewCallback(function func(context.Context, *unstructured.Unstructured) erro
 	return Callback{function: function, supportedVerbs: m}
 }
 
+// WithSideEffects returns a copy of the callback which is not invoked on
+// dry-run requests, for functions that can't check apis.IsDryRun themselves.
+func (c Callback) WithSideEffects() Callback {
+	c.sideEffects = true
+	return c
+}
+
 var _ webhook.AdmissionController = (*reconciler)(nil)
 
 // Admit implements AdmissionController
@@ -71,13 +85,25 @@ func (ac *reconciler) Admit(ctx context.Context, request *admissionv1.AdmissionR
 		Kind:    kind.Kind,
 	}
 
+	// Check the raw object before decoding it drops the unknown fields.
+	var warnings []error
+	if errs := ac.schemas.Validate(gvk, request.Object.Raw); errs != nil {
+		if !ac.schemaWarnings {
+			return webhook.MakeErrorStatus("schema validation failed: %v", errs)
+		}
+		for _, w := range errs.WrappedErrors() {
+			warnings = append(warnings, w)
+		}
+	}
+
 	ctx, resource, err := ac.decodeRequestAndPrepareContext(ctx, request, gvk)
 	if err != nil {
 		return webhook.MakeErrorStatus("decoding request failed: %v", err)
 	}
 
-	errors, warnings := validate(ctx, resource, request)
-	if warnings != nil {
+	errors, validationWarnings := validate(ctx, resource, request)
+	warnings = append(warnings, validationWarnings...)
+	if len(warnings) > 0 {
 		// If there were warnings, then keep processing things, but augment
 		// whatever AdmissionResponse we send with the warnings.  We cannot
 		// simply set `resp.Warnings` directly here because the return paths
@@ -91,7 +117,18 @@ func (ac *reconciler) Admit(ctx context.Context, request *admissionv1.AdmissionR
 		}()
 	}
 	if errors != nil {
-		return webhook.MakeErrorStatus("validation failed: %v", errors)
+		resp := webhook.MakeErrorStatus("validation failed: %v", errors)
+		if _, ok := ac.updateDiffs[gvk]; ok && request.Operation == admissionv1.Update {
+			if causes := updateDiffs(request, errors); len(causes) > 0 {
+				resp.Result.Details = &metav1.StatusDetails{
+					Name:   request.Name,
+					Group:  gvk.Group,
+					Kind:   gvk.Kind,
+					Causes: causes,
+				}
+			}
+		}
+		return resp
 	}
 
 	if err := ac.callback(ctx, request, gvk); err != nil {
@@ -217,6 +254,9 @@ func (ac *reconciler) callback(ctx context.Context, req *admissionv1.AdmissionRe
 	// Generically callback if any are provided for the resource.
 	if c, ok := ac.callbacks[gvk]; ok {
 		if _, supported := c.supportedVerbs[req.Operation]; supported {
+			if c.sideEffects && apis.IsDryRun(ctx) {
+				return nil
+			}
 			unstruct := &unstructured.Unstructured{}
 			if err := json.Unmarshal(toDecode, unstruct); err != nil {
 				return fmt.Errorf("cannot decode incoming new object: %w", err)
@@ -20,6 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -32,6 +34,7 @@ import (
 	admissionv1 "k8s.io/api/admission/v1"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1beta1"
 	authenticationv1 "k8s.io/api/authentication/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -248,6 +251,60 @@ func TestUnknownMetadataFieldSucceeds(t *testing.T) {
 	ExpectAllowed(t, ac.Admit(TestContextWithLogger(t), req))
 }
 
+func TestSchemaViolations(t *testing.T) {
+	gvk := schema.GroupVersionKind{
+		Group:   "pkg.knative.dev",
+		Version: "v1alpha1",
+		Kind:    "Resource",
+	}
+	schemas := resourcesemantics.Schemas{
+		gvk: {
+			Type: "object",
+			Properties: map[string]apixv1.JSONSchemaProps{
+				"spec": {
+					Type: "object",
+					Properties: map[string]apixv1.JSONSchemaProps{
+						"fieldWithValidation": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+	marshaled, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "pkg.knative.dev/v1alpha1",
+		"kind":       "Resource",
+		"spec": map[string]interface{}{
+			"fieldWithValidation": "magic value",
+			// Known to the type, but not to the schema.
+			"fieldWithDefault": "foo",
+		},
+	})
+	if err != nil {
+		t.Fatal("Failed to marshal resource:", err)
+	}
+	req := &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Kind: metav1.GroupVersionKind{
+			Group:   gvk.Group,
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+		},
+	}
+	req.Object.Raw = marshaled
+
+	_, ac := newNonRunningTestResourceAdmissionController(t)
+	ac.(*reconciler).schemas = schemas
+	ExpectFailsWith(t, ac.Admit(TestContextWithLogger(t), req),
+		"schema validation failed: must not set the field(s): spec.fieldWithDefault")
+
+	ac.(*reconciler).schemaWarnings = true
+	resp := ac.Admit(TestContextWithLogger(t), req)
+	ExpectAllowed(t, resp)
+	if want := []string{"must not set the field(s): spec.fieldWithDefault"}; !reflect.DeepEqual(resp.Warnings, want) {
+		t.Errorf("Warnings = %v, wanted %v", resp.Warnings, want)
+	}
+}
+
 func TestAdmitCreates(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -321,6 +378,11 @@ func resourceCallback(ctx context.Context, uns *unstructured.Unstructured) error
 		return errors.New("dryRun fail")
 	}
 
+	if resource.Spec.FieldForCallbackValidation == "magic warning" {
+		webhook.AddWarning(ctx, "callbacks warn about %q", resource.Spec.FieldForCallbackValidation)
+		return nil
+	}
+
 	if resource.Spec.FieldForCallbackValidation != "" &&
 		resource.Spec.FieldForCallbackValidation != "magic value" {
 		return errors.New(resource.Spec.FieldForCallbackValidation)
@@ -334,6 +396,7 @@ func TestValidationCreateCallback(t *testing.T) {
 		dryRun    bool
 		setup     func(context.Context, *Resource)
 		rejection string
+		warnings  []string
 	}{{
 		name:      "with dryRun reject",
 		dryRun:    true,
@@ -358,13 +421,20 @@ func TestValidationCreateCallback(t *testing.T) {
 			r.Spec.FieldForCallbackValidation = "callbacks hate this"
 		},
 		rejection: "validation callback failed: callbacks hate this",
+	}, {
+		name:   "with field warning value",
+		dryRun: false,
+		setup: func(ctx context.Context, r *Resource) {
+			r.Spec.FieldForCallbackValidation = "magic warning"
+		},
+		warnings: []string{`callbacks warn about "magic warning"`},
 	}}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			r := CreateResource("a name")
 			ctx := apis.WithinCreate(apis.WithUserInfo(
-				TestContextWithLogger(t),
+				webhook.WithWarnings(TestContextWithLogger(t)),
 				&authenticationv1.UserInfo{Username: user1}))
 
 			// Setup the resource.
@@ -384,6 +454,9 @@ func TestValidationCreateCallback(t *testing.T) {
 			} else {
 				ExpectFailsWith(t, resp, tc.rejection)
 			}
+			if got := webhook.GetWarnings(ctx); !reflect.DeepEqual(got, tc.warnings) {
+				t.Errorf("GetWarnings() = %v, wanted %v", got, tc.warnings)
+			}
 		})
 	}
 }
@@ -723,3 +796,40 @@ func newTestResourceAdmissionController(t *testing.T) webhook.AdmissionControlle
 
 	return c.Reconciler.(*reconciler)
 }
+
+func TestCallbackWithSideEffects(t *testing.T) {
+	gvk := schema.GroupVersionKind{
+		Group:   "pkg.knative.dev",
+		Version: "v1alpha1",
+		Kind:    "Resource",
+	}
+
+	for _, dryRun := range []bool{false, true} {
+		t.Run(fmt.Sprint("dryRun=", dryRun), func(t *testing.T) {
+			called := false
+			ac := &reconciler{
+				callbacks: map[schema.GroupVersionKind]Callback{
+					gvk: NewCallback(func(context.Context, *unstructured.Unstructured) error {
+						called = true
+						return nil
+					}, webhook.Create).WithSideEffects(),
+				},
+			}
+
+			ctx := TestContextWithLogger(t)
+			if dryRun {
+				ctx = apis.WithDryRun(ctx)
+			}
+			req := &admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: []byte(`{"apiVersion":"pkg.knative.dev/v1alpha1","kind":"Resource","metadata":{"name":"foo"}}`)},
+			}
+			if err := ac.callback(ctx, req, gvk); err != nil {
+				t.Fatal("callback() =", err)
+			}
+			if called == dryRun {
+				t.Errorf("callback called = %v, wanted %v", called, !dryRun)
+			}
+		})
+	}
+}
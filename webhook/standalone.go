@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+
+	"knative.dev/pkg/controller"
+)
+
+// Standalone runs a Webhook along with the informers and controllers it
+// depends on, for binaries which use neither injection nor sharedmain.
+//
+// The controllers are typically built with the constructors taking explicit
+// clients and informers, e.g. defaulting.NewAdmissionControllerWithInformers,
+// and the Webhook with NewWithSecretInformer.
+type Standalone struct {
+	// Webhook is the webhook server.
+	Webhook *Webhook
+
+	// Informers are the informers of the Webhook and its controllers, which
+	// are run by Run. Admission requests are held until they have synced.
+	Informers []controller.Informer
+
+	// Controllers are the controllers reconciling the webhook configurations
+	// and certificates, which are run by Run. These are typically also passed
+	// to the Webhook to serve the requests.
+	Controllers []*controller.Impl
+}
+
+// Run serves the webhook and runs its informers and controllers until the
+// context is cancelled, in the same order as sharedmain does.
+func (s *Standalone) Run(ctx context.Context) error {
+	eg, egCtx := errgroup.WithContext(ctx)
+
+	// Start serving right away, admission requests are held until the
+	// informers have synced.
+	eg.Go(func() error {
+		return s.Webhook.Run(ctx.Done())
+	})
+
+	waitInformers, err := controller.RunInformers(egCtx.Done(), s.Informers...)
+	defer waitInformers()
+	if err != nil {
+		// The informers only fail to sync when they are stopped, because
+		// either the context was cancelled or the webhook server failed.
+		return eg.Wait()
+	}
+	s.Webhook.InformersHaveSynced()
+
+	eg.Go(func() error {
+		return controller.StartAll(egCtx, s.Controllers...)
+	})
+
+	return eg.Wait()
+}
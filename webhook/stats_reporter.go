@@ -32,6 +32,11 @@ import (
 const (
 	requestCountName     = "request_count"
 	requestLatenciesName = "request_latencies"
+
+	admissionRequestCountName     = "admission_request_count"
+	admissionRequestLatenciesName = "admission_request_latencies"
+
+	shedRequestCountName = "shed_request_count"
 )
 
 var (
@@ -44,6 +49,23 @@ var (
 		"The response time in milliseconds",
 		stats.UnitMilliseconds)
 
+	// The admission measures are only tagged with the kind, the operation and
+	// the result of the requests, to keep their cardinality low enough to
+	// alert on e.g. spikes in denials for a given kind.
+	admissionRequestCountM = stats.Int64(
+		admissionRequestCountName,
+		"The number of admission requests by kind, operation and result",
+		stats.UnitDimensionless)
+	admissionResponseTimeInMsecM = stats.Float64(
+		admissionRequestLatenciesName,
+		"The response time of admission requests in milliseconds",
+		stats.UnitMilliseconds)
+
+	shedRequestCountM = stats.Int64(
+		shedRequestCountName,
+		"The number of requests shed because the webhook was saturated",
+		stats.UnitDimensionless)
+
 	// Create the tag keys that will be used to add tags to our measurements.
 	// Tag keys must conform to the restrictions described in
 	// go.opencensus.io/tag/validate.go. Currently those restrictions are:
@@ -63,6 +85,9 @@ var (
 	resultStatusKey      = tag.MustNewKey("result_status")
 	resultReasonKey      = tag.MustNewKey("result_reason")
 	resultCodeKey        = tag.MustNewKey("result_code")
+
+	requestTypeKey = tag.MustNewKey("request_type")
+	shedActionKey  = tag.MustNewKey("shed_action")
 )
 
 // StatsReporter reports webhook metrics
@@ -109,6 +134,21 @@ func (r *reporter) ReportAdmissionRequest(req *admissionv1.AdmissionRequest, res
 	metrics.RecordBatch(ctx, requestCountM.M(1),
 		// Convert time.Duration in nanoseconds to milliseconds
 		responseTimeInMsecM.M(float64(d.Milliseconds())))
+
+	ctx, err = tag.New(
+		r.ctx,
+		tag.Insert(requestOperationKey, string(req.Operation)),
+		tag.Insert(kindGroupKey, req.Kind.Group),
+		tag.Insert(kindVersionKey, req.Kind.Version),
+		tag.Insert(kindKindKey, req.Kind.Kind),
+		tag.Insert(admissionAllowedKey, strconv.FormatBool(resp.Allowed)),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordBatch(ctx, admissionRequestCountM.M(1),
+		admissionResponseTimeInMsecM.M(float64(d.Milliseconds())))
 	return nil
 }
 
@@ -131,6 +171,19 @@ func (r *reporter) ReportConversionRequest(req *apixv1.ConversionRequest, resp *
 	return nil
 }
 
+// reportShedRequest records a request of the given type (admission or
+// conversion) shed with the given action (reject or allow).
+func reportShedRequest(ctx context.Context, requestType, action string) {
+	ctx, err := tag.New(ctx,
+		tag.Insert(requestTypeKey, requestType),
+		tag.Insert(shedActionKey, action),
+	)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, shedRequestCountM.M(1))
+}
+
 func RegisterMetrics() {
 	tagKeys := []tag.Key{
 		requestOperationKey,
@@ -147,6 +200,13 @@ func RegisterMetrics() {
 		resultReasonKey,
 		resultCodeKey}
 
+	admissionTagKeys := []tag.Key{
+		requestOperationKey,
+		kindGroupKey,
+		kindVersionKey,
+		kindKindKey,
+		admissionAllowedKey}
+
 	if err := view.Register(
 		&view.View{
 			Description: requestCountM.Description(),
@@ -160,6 +220,24 @@ func RegisterMetrics() {
 			Aggregation: view.Distribution(metrics.Buckets125(1, 100000)...), // [1 2 5 10 20 50 100 200 500 1000 2000 5000 10000 20000 50000 100000]ms
 			TagKeys:     tagKeys,
 		},
+		&view.View{
+			Description: admissionRequestCountM.Description(),
+			Measure:     admissionRequestCountM,
+			Aggregation: view.Count(),
+			TagKeys:     admissionTagKeys,
+		},
+		&view.View{
+			Description: admissionResponseTimeInMsecM.Description(),
+			Measure:     admissionResponseTimeInMsecM,
+			Aggregation: view.Distribution(metrics.Buckets125(1, 100000)...),
+			TagKeys:     admissionTagKeys,
+		},
+		&view.View{
+			Description: shedRequestCountM.Description(),
+			Measure:     shedRequestCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{requestTypeKey, shedActionKey},
+		},
 	); err != nil {
 		panic(err)
 	}
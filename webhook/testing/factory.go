@@ -51,7 +51,13 @@ type Ctor func(context.Context, *Listers, configmap.Watcher) controller.Reconcil
 func MakeFactory(ctor Ctor) rtesting.Factory {
 	return func(t *testing.T, r *rtesting.TableRow) (
 		controller.Reconciler, rtesting.ActionRecorderList, rtesting.EventList) {
-		ls := NewListers(r.Objects)
+		ls := NewListers(r.CacheObjects())
+		// The clients hold the state of the API server, which the listers
+		// may lag behind.
+		clients := ls
+		if r.LagsInformers() {
+			clients = NewListers(r.Objects)
+		}
 
 		ctx := r.Ctx
 		if ctx == nil {
@@ -60,8 +66,8 @@ func MakeFactory(ctor Ctor) rtesting.Factory {
 		logger := logtesting.TestLogger(t)
 		ctx = logging.WithLogger(ctx, logger)
 
-		ctx, kubeClient := fakekubeclient.With(ctx, ls.GetKubeObjects()...)
-		ctx, apixClient := fakeapixclient.With(ctx, ls.GetAPIExtensionsObjects()...)
+		ctx, kubeClient := fakekubeclient.With(ctx, clients.GetKubeObjects()...)
+		ctx, apixClient := fakeapixclient.With(ctx, clients.GetAPIExtensionsObjects()...)
 		ctx, dynamicClient := fakedynamicclient.With(ctx, ls.NewScheme(), r.Objects...)
 
 		// The dynamic client's support for patching is BS.  Implement it
@@ -76,21 +82,24 @@ func MakeFactory(ctor Ctor) rtesting.Factory {
 		ctx = controller.WithEventRecorder(ctx, eventRecorder)
 
 		// This is needed for the tests that use generated names and
-		// the object cannot be created beforehand.
-		kubeClient.PrependReactor("create", "*",
-			func(action ktesting.Action) (bool, runtime.Object, error) {
-				ca := action.(ktesting.CreateAction)
-				ls.IndexerFor(ca.GetObject()).Add(ca.GetObject())
-				return false, nil, nil
-			},
-		)
-		apixClient.PrependReactor("create", "*",
-			func(action ktesting.Action) (bool, runtime.Object, error) {
-				ca := action.(ktesting.CreateAction)
-				ls.IndexerFor(ca.GetObject()).Add(ca.GetObject())
-				return false, nil, nil
-			},
-		)
+		// the object cannot be created beforehand. The lagging listers
+		// do not see the objects created right away.
+		if !r.LagsInformers() {
+			kubeClient.PrependReactor("create", "*",
+				func(action ktesting.Action) (bool, runtime.Object, error) {
+					ca := action.(ktesting.CreateAction)
+					ls.IndexerFor(ca.GetObject()).Add(ca.GetObject())
+					return false, nil, nil
+				},
+			)
+			apixClient.PrependReactor("create", "*",
+				func(action ktesting.Action) (bool, runtime.Object, error) {
+					ca := action.(ktesting.CreateAction)
+					ls.IndexerFor(ca.GetObject()).Add(ca.GetObject())
+					return false, nil, nil
+				},
+			)
+		}
 
 		// Set up our Controller from the fakes.
 		c := ctor(ctx, &ls, configmap.NewStaticWatcher())
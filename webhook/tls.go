@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// curves maps the names of the supported curves to their IDs.
+var curves = map[string]tls.CurveID{
+	"X25519":    tls.X25519,
+	"CurveP256": tls.CurveP256,
+	"CurveP384": tls.CurveP384,
+	"CurveP521": tls.CurveP521,
+}
+
+// ParseCipherSuites parses a comma separated list of cipher suite names, as
+// defined by the crypto/tls package, e.g.
+// "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256".
+// Cipher suites with known security issues are rejected.
+func ParseCipherSuites(s string) ([]uint16, error) {
+	known := make(map[string]uint16, len(tls.CipherSuites()))
+	for _, cs := range tls.CipherSuites() {
+		known[cs.Name] = cs.ID
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite: %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ParseCurvePreferences parses a comma separated list of curve names, which
+// are any of X25519, CurveP256, CurveP384 and CurveP521.
+func ParseCurvePreferences(s string) ([]tls.CurveID, error) {
+	var ids []tls.CurveID
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := curves[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown curve: %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// validateCipherSuites checks that the cipher suites are supported and
+// don't have known security issues.
+func validateCipherSuites(ids []uint16) error {
+	secure := make(map[uint16]struct{}, len(tls.CipherSuites()))
+	for _, cs := range tls.CipherSuites() {
+		secure[cs.ID] = struct{}{}
+	}
+	for _, id := range ids {
+		if _, ok := secure[id]; !ok {
+			return fmt.Errorf("unsupported cipher suite: %s", tls.CipherSuiteName(id))
+		}
+	}
+	return nil
+}
+
+// validateCurvePreferences checks that the curves are supported.
+func validateCurvePreferences(ids []tls.CurveID) error {
+	for _, id := range ids {
+		switch id {
+		case tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521:
+		default:
+			return fmt.Errorf("unsupported curve: %s", id)
+		}
+	}
+	return nil
+}
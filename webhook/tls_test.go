@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCipherSuites(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []uint16
+		wantErr bool
+	}{{
+		name: "empty",
+	}, {
+		name: "single",
+		in:   "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		want: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	}, {
+		name: "list with spaces",
+		in:   "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,",
+		want: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+	}, {
+		name:    "unknown",
+		in:      "TLS_FOO",
+		wantErr: true,
+	}, {
+		name:    "insecure",
+		in:      "TLS_RSA_WITH_RC4_128_SHA",
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseCipherSuites(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseCipherSuites() = %v, wantErr: %v", err, tc.wantErr)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("ParseCipherSuites() = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseCurvePreferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []tls.CurveID
+		wantErr bool
+	}{{
+		name: "empty",
+	}, {
+		name: "list",
+		in:   "CurveP384,X25519",
+		want: []tls.CurveID{tls.CurveP384, tls.X25519},
+	}, {
+		name:    "unknown",
+		in:      "CurveP256,P192",
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseCurvePreferences(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseCurvePreferences() = %v, wantErr: %v", err, tc.wantErr)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("ParseCurvePreferences() = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"go.opencensus.io/trace"
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"knative.dev/pkg/tracing/propagation/tracecontextb3"
+)
+
+const (
+	// admissionSpanName is the name of the spans wrapping the admissions,
+	// parent of the spans of their phases.
+	admissionSpanName = "webhook.Admission"
+
+	// The names of the spans of the phases of the admissions.
+	decodeSpanName   = "webhook.Decode"
+	callbackSpanName = "webhook.Admit"
+	patchSpanName    = "webhook.Patch"
+	writeSpanName    = "webhook.Write"
+)
+
+// startAdmissionSpan starts the span of the admission request, child of the
+// span of the API server when it propagated its trace context, and returns
+// the context carrying it. Whether the span is sampled and exported is
+// governed by the tracing configuration, see knative.dev/pkg/tracing.
+func startAdmissionSpan(r *http.Request, path string) (context.Context, *trace.Span) {
+	var (
+		ctx  context.Context
+		span *trace.Span
+	)
+	if sc, ok := tracecontextb3.TraceContextEgress.SpanContextFromRequest(r); ok && trace.FromContext(r.Context()) == nil {
+		ctx, span = trace.StartSpanWithRemoteParent(r.Context(), admissionSpanName, sc, trace.WithSpanKind(trace.SpanKindServer))
+	} else {
+		ctx, span = trace.StartSpan(r.Context(), admissionSpanName, trace.WithSpanKind(trace.SpanKindServer))
+	}
+	if span.IsRecordingEvents() {
+		span.AddAttributes(trace.StringAttribute("path", path))
+	}
+	return ctx, span
+}
+
+// addRequestAttributes records the resource of the admission request on its
+// span.
+func addRequestAttributes(span *trace.Span, req *admissionv1.AdmissionRequest) {
+	if !span.IsRecordingEvents() {
+		return
+	}
+	span.AddAttributes(
+		trace.StringAttribute("kind", req.Kind.String()),
+		trace.StringAttribute("operation", string(req.Operation)),
+		trace.StringAttribute("namespace", req.Namespace),
+		trace.StringAttribute("name", req.Name),
+	)
+}
+
+// StartPatchSpan starts the span of the generation of the patch of an
+// admission, for the AdmissionControllers mutating the resources, and
+// returns the context carrying it.
+func StartPatchSpan(ctx context.Context) (context.Context, *trace.Span) {
+	return trace.StartSpan(ctx, patchSpanName)
+}
+
+// endSpan records the error, if any, on the span and ends it.
+func endSpan(span *trace.Span, code int32, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{Code: code, Message: err.Error()})
+	}
+	span.End()
+}
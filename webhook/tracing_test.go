@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opencensus.io/trace"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+// byName returns the spans by their name.
+func (r *spanRecorder) byName() map[string]*trace.SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := make(map[string]*trace.SpanData, len(r.spans))
+	for _, s := range r.spans {
+		spans[s.Name] = s
+	}
+	return spans
+}
+
+// patchingAdmissionController generates its patch in a span.
+type patchingAdmissionController struct {
+	fixedAdmissionController
+}
+
+func (pac *patchingAdmissionController) Admit(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	_, span := StartPatchSpan(ctx)
+	defer span.End()
+	return pac.fixedAdmissionController.Admit(ctx, req)
+}
+
+func TestAdmissionSpans(t *testing.T) {
+	rec := &spanRecorder{}
+	trace.RegisterExporter(rec)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	t.Cleanup(func() {
+		trace.UnregisterExporter(rec)
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+	})
+
+	c := &patchingAdmissionController{fixedAdmissionController{
+		path:     "/admit",
+		response: &admissionv1.AdmissionResponse{Allowed: true},
+	}}
+	synced := make(chan struct{})
+	close(synced)
+
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "1234",
+			Kind:      metav1.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1", Kind: "Resource"},
+			Namespace: "ns",
+			Name:      "foo",
+			Operation: admissionv1.Create,
+		},
+	})
+	if err != nil {
+		t.Fatal("Marshal() =", err)
+	}
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodPost, "/admit", bytes.NewReader(body))
+	req = req.WithContext(TestContextWithLogger(t))
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	admissionHandler(nil, nil, c, synced).ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.byName()
+	admission, ok := spans[admissionSpanName]
+	if !ok {
+		t.Fatalf("No %s span in %v", admissionSpanName, spans)
+	}
+	if got := admission.TraceID.String(); got != traceID {
+		t.Errorf("TraceID = %s, wanted that of the API server %s", got, traceID)
+	}
+	if got := admission.ParentSpanID.String(); got != parentID {
+		t.Errorf("ParentSpanID = %s, wanted the span of the API server %s", got, parentID)
+	}
+	for k, v := range map[string]interface{}{"path": "/admit", "namespace": "ns", "name": "foo", "operation": "CREATE"} {
+		if got := admission.Attributes[k]; got != v {
+			t.Errorf("Attribute %q = %v, wanted %v", k, got, v)
+		}
+	}
+
+	// The phases are the children of the admission, the patch that of the
+	// callback.
+	for name, parent := range map[string]string{
+		decodeSpanName:   admissionSpanName,
+		callbackSpanName: admissionSpanName,
+		patchSpanName:    callbackSpanName,
+		writeSpanName:    admissionSpanName,
+	} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("No %s span", name)
+			continue
+		}
+		if span.ParentSpanID != spans[parent].SpanID {
+			t.Errorf("Parent of %s = %s, wanted %s", name, span.ParentSpanID, parent)
+		}
+	}
+	if got := spans[callbackSpanName].Attributes["allowed"]; got != true {
+		t.Errorf("Attribute allowed = %v, wanted true", got)
+	}
+}
+
+func TestAdmissionSpanDecodeError(t *testing.T) {
+	rec := &spanRecorder{}
+	trace.RegisterExporter(rec)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	t.Cleanup(func() {
+		trace.UnregisterExporter(rec)
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+	})
+
+	synced := make(chan struct{})
+	close(synced)
+	req := httptest.NewRequest(http.MethodPost, "/admit", strings.NewReader("{"))
+	req = req.WithContext(TestContextWithLogger(t))
+	admissionHandler(nil, nil, &fixedAdmissionController{path: "/admit"}, synced).ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.byName()
+	for _, name := range []string{admissionSpanName, decodeSpanName} {
+		if got := spans[name].Code; got != trace.StatusCodeInvalidArgument {
+			t.Errorf("Status of %s = %d, wanted %d", name, got, trace.StatusCodeInvalidArgument)
+		}
+	}
+	if _, ok := spans[callbackSpanName]; ok {
+		t.Errorf("Unexpected %s span", callbackSpanName)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// warningsKey is used as the key for associating the warnings
+// accumulated while admitting a request with a context.Context.
+type warningsKey struct{}
+
+type warnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// WithWarnings returns a context with which warnings can be attached to the
+// response of an admission request through AddWarning, and retrieved
+// through GetWarnings. The admission handler sets it up for every request, so
+// it only needs to be called when invoking an AdmissionController directly.
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsKey{}, &warnings{})
+}
+
+// AddWarning attaches a warning to the response of the admission request
+// handled with the given context, so it reaches the user, e.g. is printed
+// by kubectl. This lets defaulting and validation callbacks warn without
+// failing the request. It is a no-op if the context wasn't set up with
+// WithWarnings.
+func AddWarning(ctx context.Context, format string, args ...interface{}) {
+	w, ok := ctx.Value(warningsKey{}).(*warnings)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, fmt.Sprintf(format, args...))
+}
+
+// GetWarnings returns the warnings added with AddWarning to the given
+// context.
+func GetWarnings(ctx context.Context) []string {
+	w, ok := ctx.Value(warningsKey{}).(*warnings)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.messages...)
+}
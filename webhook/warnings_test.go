@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWarnings(t *testing.T) {
+	// AddWarning is a no-op on contexts without warnings.
+	ctx := context.Background()
+	AddWarning(ctx, "dropped")
+	if got := GetWarnings(ctx); got != nil {
+		t.Errorf("GetWarnings() = %v, wanted nil", got)
+	}
+
+	ctx = WithWarnings(ctx)
+	AddWarning(ctx, "first")
+	AddWarning(ctx, "second %d", 2)
+
+	want := []string{"first", "second 2"}
+	got := GetWarnings(ctx)
+	if !cmp.Equal(got, want) {
+		t.Errorf("GetWarnings() (-want, +got) = %s", cmp.Diff(want, got))
+	}
+
+	// The returned warnings are a copy.
+	got[0] = "changed"
+	if got := GetWarnings(ctx); !cmp.Equal(got, want) {
+		t.Errorf("GetWarnings() (-want, +got) = %s", cmp.Diff(want, got))
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	admissionv1 "k8s.io/api/admission/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/system"
 )
@@ -46,6 +47,17 @@ type Options struct {
 	// TLS 1.3 is the minimum version if not specified otherwise.
 	TLSMinVersion uint16
 
+	// CipherSuites is the list of cipher suites enabled for TLS 1.2, e.g. to
+	// restrict them to FIPS approved ones. The cipher suites of TLS 1.3 are
+	// not configurable.
+	// Go's default cipher suites are used if not specified otherwise.
+	CipherSuites []uint16
+
+	// CurvePreferences is the list of elliptic curves used in ECDHE key
+	// exchanges, in order of preference.
+	// Go's default curves are used if not specified otherwise.
+	CurvePreferences []tls.CurveID
+
 	// ServiceName is the service name of the webhook.
 	ServiceName string
 
@@ -65,24 +77,130 @@ type Options struct {
 	// Default value is `server-cert.pem` if no value is passed.
 	ServerCertificateName string
 
+	// ServerOCSPStapleName is the name for the webhook secret's data key holding
+	// a DER-encoded OCSP response for the serving certificate. When present, the
+	// response is stapled to TLS handshakes.
+	// Default value is `ocsp-staple.der` if no value is passed.
+	ServerOCSPStapleName string
+
+	// CASecretName is the name of the k8s secret that contains the CA cert
+	// provided to the k8s apiserver during admission controller registration,
+	// when it is kept apart from the serving key/cert in SecretName.
+	// Setting it to a different name marks the certificates as managed
+	// externally, so they are not generated by the certificates controller.
+	// Default value is SecretName if no value is passed.
+	CASecretName string
+
+	// CACertificateName is the name for the CA secret's data key holding
+	// the CA cert e.g. `ca.crt`.
+	// Default value is `ca-cert.pem` if no value is passed.
+	CACertificateName string
+
+	// CertificateDuration is the lifetime of the self-signed certificates
+	// generated by the certificates controller.
+	// Default value is one week if no value is passed.
+	CertificateDuration time.Duration
+
+	// CertificateRenewBefore is how long before they expire the self-signed
+	// certificates are renewed.
+	// Default value is one day if no value is passed.
+	CertificateRenewBefore time.Duration
+
+	// SigningCASecretName is the name of a kubernetes.io/tls secret in the
+	// system namespace holding an operator-provided CA, which signs the
+	// serving certificates generated by the certificates controller instead
+	// of a generated CA. The CA certificate is then propagated as the CA
+	// bundle, and the serving certificates are renewed when it changes.
+	// A CA is generated with the certificates if no value is passed.
+	SigningCASecretName string
+
+	// CertificateProvider provisions the webhook certificates, e.g. through
+	// cert-manager. It also provides the default names of the secret's data
+	// keys.
+	// Self-signed certificates are generated if no value is passed.
+	CertificateProvider CertificateProvider
+
 	// Port where the webhook is served. Per k8s admission
 	// registration requirements this should be 443 unless there is
 	// only a single port for the service.
 	Port int
 
+	// Listeners serve some of the controllers on other ports than Port,
+	// with their own serving certificates.
+	// All the controllers are served on Port if no value is passed.
+	Listeners []Listener
+
 	// StatsReporter reports metrics about the webhook.
 	// This will be automatically initialized by the constructor if left uninitialized.
 	StatsReporter StatsReporter
 
+	// AuditSink keeps a record of every admission request and of the
+	// decision taken on it. Sinks with a lifecycle, like HTTPAuditSink, are
+	// run by the caller.
+	// The admission requests are not audited if no value is passed.
+	AuditSink AuditSink
+
 	// GracePeriod is how long to wait after failing readiness probes
 	// before shutting down.
 	GracePeriod time.Duration
 
+	// MaxInFlightRequests is the maximum number of admission and conversion
+	// requests handled concurrently. Requests beyond it wait up to
+	// QueueTimeout to be handled, and are shed per the LoadSheddingPolicy
+	// after that.
+	// Requests are not limited if no value is passed.
+	MaxInFlightRequests int
+
+	// QueueTimeout is how long requests wait to be handled when
+	// MaxInFlightRequests are in flight.
+	// Requests are shed right away if no value is passed.
+	QueueTimeout time.Duration
+
+	// LoadSheddingPolicy decides how the shed admission requests are
+	// answered. Shed conversion requests are always rejected.
+	// Default value is RejectWhenSaturated if no value is passed.
+	LoadSheddingPolicy LoadSheddingPolicy
+
 	// ControllerOptions encapsulates options for creating a new controller,
 	// including throttling and stats behavior.
 	ControllerOptions *controller.ControllerOptions
 }
 
+// CABundleSecretName returns the name of the secret holding the CA cert
+// which signed the webhook's serving certificate.
+func (o *Options) CABundleSecretName() string {
+	if o.CASecretName != "" {
+		return o.CASecretName
+	}
+	return o.SecretName
+}
+
+// CABundleKey returns the name of the data key holding the CA cert in the
+// secret named by CABundleSecretName.
+func (o *Options) CABundleKey() string {
+	_, _, caCert := o.SecretDataKeys()
+	return caCert
+}
+
+// SecretDataKeys returns the names of the data keys of the webhook's
+// secrets, defaulted from the CertificateProvider if any.
+func (o *Options) SecretDataKeys() (serverKey, serverCert, caCert string) {
+	if o.CertificateProvider != nil {
+		serverKey, serverCert, caCert = o.CertificateProvider.SecretDataKeys()
+	}
+	if o.ServerPrivateKeyName != "" {
+		serverKey = o.ServerPrivateKeyName
+	}
+	if o.ServerCertificateName != "" {
+		serverCert = o.ServerCertificateName
+	}
+	if o.CACertificateName != "" {
+		caCert = o.CACertificateName
+	}
+	serverKey, serverCert = getSecretDataKeyNamesOrDefault(serverKey, serverCert)
+	return serverKey, serverCert, getCACertNameOrDefault(caCert)
+}
+
 // Operation is the verb being operated on
 // it is aliased in Validation from the k8s admission package
 type Operation = admissionv1.Operation
@@ -111,6 +229,9 @@ type Webhook struct {
 
 	// testListener is only used in testing so we don't get port conflicts
 	testListener net.Listener
+
+	// listeners serve the controllers of the Options' Listeners.
+	listeners []*listener
 }
 
 // New constructs a Webhook
@@ -118,6 +239,35 @@ func New(
 	ctx context.Context,
 	controllers []interface{},
 ) (webhook *Webhook, err error) {
+	return newWebhook(ctx, controllers, func() corev1informers.SecretInformer {
+		// Injection is too aggressive for this case because by simply linking this
+		// library we force consumers to have secret access.  If we require that one
+		// of the admission controllers' informers *also* require the secret
+		// informer, then we can fetch the shared informer factory here and produce
+		// a new secret informer from it.
+		return kubeinformerfactory.Get(ctx).Core().V1().Secrets()
+	})
+}
+
+// NewWithSecretInformer constructs a Webhook without relying on injection,
+// watching the serving certificate secret through the given informer, which
+// must cover the system namespace. The informer may be nil when the Options
+// specify no SecretName.
+func NewWithSecretInformer(
+	ctx context.Context,
+	controllers []interface{},
+	secretInformer corev1informers.SecretInformer,
+) (*Webhook, error) {
+	return newWebhook(ctx, controllers, func() corev1informers.SecretInformer {
+		return secretInformer
+	})
+}
+
+func newWebhook(
+	ctx context.Context,
+	controllers []interface{},
+	secretInformer func() corev1informers.SecretInformer,
+) (webhook *Webhook, err error) {
 
 	// ServeMux.Handle panics on duplicate paths
 	defer func() {
@@ -147,6 +297,23 @@ func New(
 		return nil, fmt.Errorf("unsupported TLS version: %d", opts.TLSMinVersion)
 	}
 
+	if opts.CipherSuites == nil {
+		opts.CipherSuites = TLSCipherSuitesFromEnv(nil)
+	}
+	if err := validateCipherSuites(opts.CipherSuites); err != nil {
+		return nil, err
+	}
+	if opts.CurvePreferences == nil {
+		opts.CurvePreferences = TLSCurvePreferencesFromEnv(nil)
+	}
+	if err := validateCurvePreferences(opts.CurvePreferences); err != nil {
+		return nil, err
+	}
+
+	if err := validateListeners(opts); err != nil {
+		return nil, err
+	}
+
 	syncCtx, cancel := context.WithCancel(context.Background())
 
 	webhook = &Webhook{
@@ -155,61 +322,65 @@ func New(
 		synced:  cancel,
 	}
 
-	if opts.SecretName != "" {
-		// Injection is too aggressive for this case because by simply linking this
-		// library we force consumers to have secret access.  If we require that one
-		// of the admission controllers' informers *also* require the secret
-		// informer, then we can fetch the shared informer factory here and produce
-		// a new secret informer from it.
-		secretInformer := kubeinformerfactory.Get(ctx).Core().V1().Secrets()
-
-		webhook.tlsConfig = &tls.Config{
-			MinVersion: opts.TLSMinVersion,
-
-			// If we return (nil, error) the client sees - 'tls: internal error"
-			// If we return (nil, nil) the client sees - 'tls: no certificates configured'
-			//
-			// We'll return (nil, nil) when we don't find a certificate
-			GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
-				secret, err := secretInformer.Lister().Secrets(system.Namespace()).Get(opts.SecretName)
-				if err != nil {
-					logger.Errorw("failed to fetch secret", zap.Error(err))
-					return nil, nil
-				}
-				webOpts := GetOptions(ctx)
-				sKey, sCert := getSecretDataKeyNamesOrDefault(webOpts.ServerPrivateKeyName, webOpts.ServerCertificateName)
-				serverKey, ok := secret.Data[sKey]
-				if !ok {
-					logger.Warn("server key missing")
-					return nil, nil
-				}
-				serverCert, ok := secret.Data[sCert]
-				if !ok {
-					logger.Warn("server cert missing")
-					return nil, nil
-				}
-				cert, err := tls.X509KeyPair(serverCert, serverKey)
-				if err != nil {
-					return nil, err
-				}
-				return &cert, nil
-			},
+	var informer corev1informers.SecretInformer
+	tlsConfig := func(secretName string) (*tls.Config, error) {
+		if secretName == "" {
+			return nil, nil
+		}
+		if informer == nil {
+			if informer = secretInformer(); informer == nil {
+				return nil, errors.New("a secret informer is required to serve TLS from SecretName")
+			}
 		}
+
+		certs := newCertificateCache(logger, opts)
+		informer.Informer().AddEventHandler(certs.handler(system.Namespace(), secretName))
+
+		return &tls.Config{
+			MinVersion:       opts.TLSMinVersion,
+			CipherSuites:     opts.CipherSuites,
+			CurvePreferences: opts.CurvePreferences,
+
+			// The serving certificate is swapped whenever the secret changes,
+			// so rotations take effect without restarting the webhook.
+			GetCertificate: certs.GetCertificate,
+		}, nil
 	}
 
-	webhook.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		http.Error(w, fmt.Sprint("no controller registered for: ", html.EscapeString(r.URL.Path)), http.StatusBadRequest)
-	})
+	if webhook.tlsConfig, err = tlsConfig(opts.SecretName); err != nil {
+		return nil, err
+	}
+	webhook.mux.HandleFunc("/", noController)
 
+	muxes := make(map[string]*http.ServeMux)
+	for _, l := range opts.Listeners {
+		wl := &listener{Listener: l}
+		if wl.tlsConfig, err = tlsConfig(l.SecretName); err != nil {
+			return nil, err
+		}
+		wl.mux.HandleFunc("/", noController)
+		for _, p := range l.Paths {
+			muxes[p] = &wl.mux
+		}
+		webhook.listeners = append(webhook.listeners, wl)
+	}
+	muxFor := func(path string) *http.ServeMux {
+		if mux, ok := muxes[path]; ok {
+			return mux
+		}
+		return &webhook.mux
+	}
+
+	shedder := newLoadShedder(opts)
 	for _, controller := range controllers {
 		switch c := controller.(type) {
 		case AdmissionController:
-			handler := admissionHandler(logger, opts.StatsReporter, c, syncCtx.Done())
-			webhook.mux.Handle(c.Path(), handler)
+			handler := admissionHandler(opts.StatsReporter, opts.AuditSink, c, syncCtx.Done())
+			muxFor(c.Path()).Handle(c.Path(), shedder.admission(handler))
 
 		case ConversionController:
-			handler := conversionHandler(logger, opts.StatsReporter, c)
-			webhook.mux.Handle(c.Path(), handler)
+			handler := conversionHandler(opts.StatsReporter, c)
+			muxFor(c.Path()).Handle(c.Path(), shedder.conversion(handler))
 
 		default:
 			return nil, fmt.Errorf("unknown webhook controller type:  %T", controller)
@@ -219,6 +390,17 @@ func New(
 	return
 }
 
+func noController(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, fmt.Sprint("no controller registered for: ", html.EscapeString(r.URL.Path)), http.StatusBadRequest)
+}
+
+// TLSConfig returns the TLS configuration the webhook serves its Port with,
+// or nil when it serves without TLS. Its certificate rotates with the secret,
+// so other servers of the process may share it.
+func (wh *Webhook) TLSConfig() *tls.Config {
+	return wh.tlsConfig
+}
+
 // InformersHaveSynced is called when the informers have all been synced, which allows any outstanding
 // admission webhooks through.
 func (wh *Webhook) InformersHaveSynced() {
@@ -240,58 +422,40 @@ func (wh *Webhook) Run(stop <-chan struct{}) error {
 	logger := wh.Logger
 	ctx := logging.WithLogger(context.Background(), logger)
 
-	drainer := &handlers.Drainer{
-		Inner:       wh,
-		QuietPeriod: wh.Options.GracePeriod,
-	}
-
-	server := &http.Server{
-		ErrorLog:          log.New(&zapWrapper{logger}, "", 0),
-		Handler:           drainer,
-		Addr:              fmt.Sprint(":", wh.Options.Port),
-		TLSConfig:         wh.tlsConfig,
-		ReadHeaderTimeout: time.Minute, //https://medium.com/a-journey-with-go/go-understand-and-mitigate-slowloris-attack-711c1b1403f6
-	}
-
-	var serve = server.ListenAndServe
-
-	if server.TLSConfig != nil && wh.testListener != nil {
-		serve = func() error {
-			return server.ServeTLS(wh.testListener, "", "")
-		}
-	} else if server.TLSConfig != nil {
-		serve = func() error {
-			return server.ListenAndServeTLS("", "")
-		}
-	} else if wh.testListener != nil {
-		serve = func() error {
-			return server.Serve(wh.testListener)
-		}
+	servers := []*server{wh.newServer(wh, wh.Options.Port, wh.tlsConfig, wh.testListener)}
+	for _, l := range wh.listeners {
+		servers = append(servers, wh.newServer(l, l.Port, l.tlsConfig, l.testListener))
 	}
 
 	eg, ctx := errgroup.WithContext(ctx)
-	eg.Go(func() error {
-		if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logger.Errorw("ListenAndServe for admission webhook returned error", zap.Error(err))
-			return err
-		}
-		return nil
-	})
+	for _, s := range servers {
+		s := s
+		eg.Go(func() error {
+			if err := s.serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Errorw("ListenAndServe for admission webhook returned error", zap.Error(err))
+				return err
+			}
+			return nil
+		})
+	}
 
 	select {
 	case <-stop:
-		eg.Go(func() error {
-			// As we start to shutdown, disable keep-alives to avoid clients hanging onto connections.
-			server.SetKeepAlivesEnabled(false)
-
-			// Start failing readiness probes immediately.
-			logger.Info("Starting to fail readiness probes...")
-			drainer.Drain()
-
-			return server.Shutdown(context.Background())
-		})
+		for _, s := range servers {
+			s := s
+			eg.Go(func() error {
+				// As we start to shutdown, disable keep-alives to avoid clients hanging onto connections.
+				s.SetKeepAlivesEnabled(false)
+
+				// Start failing readiness probes immediately.
+				logger.Info("Starting to fail readiness probes...")
+				s.drainer.Drain()
+
+				return s.Shutdown(context.Background())
+			})
+		}
 
-		// Wait for all outstanding go routined to terminate, including our new one.
+		// Wait for all outstanding go routined to terminate, including our new ones.
 		return eg.Wait()
 
 	case <-ctx.Done():
@@ -299,7 +463,54 @@ func (wh *Webhook) Run(stop <-chan struct{}) error {
 	}
 }
 
+// server is an http.Server serving one of the webhook's ports.
+type server struct {
+	*http.Server
+	drainer *handlers.Drainer
+	serve   func() error
+}
+
+func (wh *Webhook) newServer(handler http.Handler, port int, tlsConfig *tls.Config, testListener net.Listener) *server {
+	logger := wh.Logger
+
+	drainer := &handlers.Drainer{
+		Inner:       handler,
+		QuietPeriod: wh.Options.GracePeriod,
+	}
+
+	s := &server{
+		Server: &http.Server{
+			ErrorLog:          log.New(&zapWrapper{logger}, "", 0),
+			Handler:           logging.NewRequestLoggingHandler(logger, drainer),
+			Addr:              fmt.Sprint(":", port),
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: time.Minute, //https://medium.com/a-journey-with-go/go-understand-and-mitigate-slowloris-attack-711c1b1403f6
+		},
+		drainer: drainer,
+	}
+	s.serve = s.ListenAndServe
+
+	if tlsConfig != nil && testListener != nil {
+		s.serve = func() error {
+			return s.ServeTLS(testListener, "", "")
+		}
+	} else if tlsConfig != nil {
+		s.serve = func() error {
+			return s.ListenAndServeTLS("", "")
+		}
+	} else if testListener != nil {
+		s.serve = func() error {
+			return s.Serve(testListener)
+		}
+	}
+	return s
+}
+
 func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveJSON(&wh.mux, w, r)
+}
+
+func serveJSON(mux *http.ServeMux, w http.ResponseWriter, r *http.Request) {
 	// Verify the content type is accurate.
 	contentType := r.Header.Get("Content-Type")
 	if contentType != "application/json" {
@@ -307,5 +518,5 @@ func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	wh.mux.ServeHTTP(w, r)
+	mux.ServeHTTP(w, r)
 }
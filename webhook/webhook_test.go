@@ -136,3 +136,44 @@ func TestTLSMinVersionWebhookOption(t *testing.T) {
 		}
 	})
 }
+
+func TestTLSCipherSuitesAndCurvesWebhookOption(t *testing.T) {
+	t.Run("when the cipher suites and curves are supported", func(t *testing.T) {
+		opts := newDefaultOptions()
+		opts.CipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+		opts.CurvePreferences = []tls.CurveID{tls.CurveP256}
+		wh, err := newAdmissionControllerWebhook(t, opts)
+		if err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if got := wh.tlsConfig.CipherSuites; len(got) != 1 || got[0] != tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 {
+			t.Errorf("CipherSuites = %v", got)
+		}
+		if got := wh.tlsConfig.CurvePreferences; len(got) != 1 || got[0] != tls.CurveP256 {
+			t.Errorf("CurvePreferences = %v", got)
+		}
+	})
+	t.Run("when a cipher suite is insecure", func(t *testing.T) {
+		opts := newDefaultOptions()
+		opts.CipherSuites = []uint16{tls.TLS_RSA_WITH_RC4_128_SHA}
+		if _, err := newAdmissionControllerWebhook(t, opts); err == nil {
+			t.Fatal("Admission Controller Webhook creation expected to fail due to an insecure cipher suite")
+		}
+	})
+	t.Run("when a curve is not supported", func(t *testing.T) {
+		opts := newDefaultOptions()
+		opts.CurvePreferences = []tls.CurveID{tls.CurveID(1)}
+		if _, err := newAdmissionControllerWebhook(t, opts); err == nil {
+			t.Fatal("Admission Controller Webhook creation expected to fail due to an unsupported curve")
+		}
+	})
+}
+
+func TestNewWithSecretInformerMissing(t *testing.T) {
+	ctx, cancel, _ := SetupFakeContextWithCancel(t)
+	defer cancel()
+	ctx = WithOptions(ctx, newDefaultOptions())
+	if _, err := NewWithSecretInformer(ctx, nil, nil); err == nil {
+		t.Error("NewWithSecretInformer() = nil, wanted an error")
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes and decodes the typed messages exchanged over a connection.
+type Codec interface {
+	// Name identifies the codec. It is used as the websocket subprotocol
+	// when negotiating the codec on connect.
+	Name() string
+
+	// MessageType is the websocket message type of the encoded messages,
+	// i.e. websocket.TextMessage or websocket.BinaryMessage.
+	MessageType() int
+
+	// Marshal encodes the given message.
+	Marshal(msg interface{}) ([]byte, error)
+
+	// Unmarshal decodes the given data into the message pointed to by msg.
+	Unmarshal(data []byte, msg interface{}) error
+}
+
+var (
+	// JSONCodec encodes messages as JSON text messages.
+	JSONCodec Codec = jsonCodec{}
+
+	// GobCodec encodes messages with encoding/gob, like Send does.
+	GobCodec Codec = gobCodec{}
+
+	// ProtobufCodec encodes messages, which must be proto.Message, in the
+	// protobuf wire format.
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string     { return "json.v1.knative.dev" }
+func (jsonCodec) MessageType() int { return websocket.TextMessage }
+
+func (jsonCodec) Marshal(msg interface{}) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+func (jsonCodec) Unmarshal(data []byte, msg interface{}) error {
+	return json.Unmarshal(data, msg)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string     { return "gob.v1.knative.dev" }
+func (gobCodec) MessageType() int { return websocket.BinaryMessage }
+
+func (gobCodec) Marshal(msg interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(msg); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, msg interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(msg)
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string     { return "protobuf.v1.knative.dev" }
+func (protobufCodec) MessageType() int { return websocket.BinaryMessage }
+
+func (protobufCodec) Marshal(msg interface{}) ([]byte, error) {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("message of type %T is not a proto.Message", msg)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, msg interface{}) error {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return fmt.Errorf("message of type %T is not a proto.Message", msg)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// ErrNoCodec is returned when the peers have no codec in common.
+var ErrNoCodec = errors.New("no codec could be negotiated")
+
+// VersionSkewError is returned when decoding a message whose envelope
+// carries another version than the one expected.
+type VersionSkewError struct {
+	Got, Want string
+}
+
+// Error implements error.
+func (e *VersionSkewError) Error() string {
+	return fmt.Sprintf("message version %q does not match expected version %q", e.Got, e.Want)
+}
+
+// CodecNames returns the names of the given codecs, for use as the
+// subprotocols of a websocket.Dialer or websocket.Upgrader.
+func CodecNames(codecs ...Codec) []string {
+	names := make([]string, 0, len(codecs))
+	for _, c := range codecs {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// NewCodecUpgrader returns an upgrader negotiating one of the given codecs,
+// in order of preference, with the connecting clients. The negotiated codec
+// of an upgraded connection is returned by NegotiatedCodec.
+func NewCodecUpgrader(codecs ...Codec) *websocket.Upgrader {
+	return &websocket.Upgrader{
+		Subprotocols: CodecNames(codecs...),
+	}
+}
+
+// NegotiatedCodec returns the codec among the given ones that the peers
+// agreed on for the given subprotocol, or ErrNoCodec if there is none.
+func NegotiatedCodec(subprotocol string, codecs ...Codec) (Codec, error) {
+	for _, c := range codecs {
+		if c.Name() == subprotocol {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: subprotocol %q", ErrNoCodec, subprotocol)
+}
+
+// EncodeMessage encodes the given message with the codec and wraps it into
+// an envelope carrying the given version.
+func EncodeMessage(codec Codec, version string, msg interface{}) ([]byte, error) {
+	if strings.ContainsRune(version, '\n') {
+		return nil, fmt.Errorf("message version %q must not contain a newline", version)
+	}
+	payload, err := codec.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, len(version)+1+len(payload))
+	b = append(b, version...)
+	b = append(b, '\n')
+	return append(b, payload...), nil
+}
+
+// DecodeMessage unwraps the envelope of the given data and decodes its
+// payload into msg with the codec. A *VersionSkewError is returned if the
+// envelope does not carry the given version.
+func DecodeMessage(codec Codec, version string, data []byte, msg interface{}) error {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return errors.New("message is missing its envelope")
+	}
+	if got := string(data[:i]); got != version {
+		return &VersionSkewError{Got: got, Want: version}
+	}
+	return codec.Unmarshal(data[i+1:], msg)
+}
+
+// Codec returns the codec negotiated for the current connection, or
+// ErrConnectionNotEstablished if there is none.
+func (c *ManagedConnection) Codec() (Codec, error) {
+	c.connectionLock.RLock()
+	defer c.connectionLock.RUnlock()
+
+	if c.connection == nil || c.codec == nil {
+		return nil, ErrConnectionNotEstablished
+	}
+	return c.codec, nil
+}
+
+// SendMessage encodes the message with the negotiated codec and sends it
+// in an envelope carrying the version of the connection.
+func (c *ManagedConnection) SendMessage(msg interface{}) error {
+	codec, err := c.Codec()
+	if err != nil {
+		return err
+	}
+	b, err := EncodeMessage(codec, c.messageVersion, msg)
+	if err != nil {
+		return err
+	}
+	return c.write(codec.MessageType(), b)
+}
+
+// DecodeMessage decodes a message received on the connection's message
+// channel with the negotiated codec. A *VersionSkewError is returned if the
+// message's envelope does not carry the version of the connection.
+func (c *ManagedConnection) DecodeMessage(data []byte, msg interface{}) error {
+	codec, err := c.Codec()
+	if err != nil {
+		return err
+	}
+	return DecodeMessage(codec, c.messageVersion, data, msg)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2023 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	ktesting "knative.dev/pkg/logging/testing"
+)
+
+type testMessage struct {
+	Name  string
+	Count int
+}
+
+func TestCodecs(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec Codec
+		in    interface{}
+		out   func() interface{}
+	}{{
+		name:  "json",
+		codec: JSONCodec,
+		in:    &testMessage{Name: "foo", Count: 2},
+		out:   func() interface{} { return &testMessage{} },
+	}, {
+		name:  "gob",
+		codec: GobCodec,
+		in:    &testMessage{Name: "foo", Count: 2},
+		out:   func() interface{} { return &testMessage{} },
+	}, {
+		name:  "protobuf",
+		codec: ProtobufCodec,
+		in:    wrapperspb.String("foo"),
+		out:   func() interface{} { return &wrapperspb.StringValue{} },
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := EncodeMessage(test.codec, "v1", test.in)
+			if err != nil {
+				t.Fatal("EncodeMessage() =", err)
+			}
+			out := test.out()
+			if err := DecodeMessage(test.codec, "v1", b, out); err != nil {
+				t.Fatal("DecodeMessage() =", err)
+			}
+			if !cmp.Equal(test.in, out, cmp.Comparer(func(a, b *wrapperspb.StringValue) bool {
+				return a.GetValue() == b.GetValue()
+			})) {
+				t.Errorf("DecodeMessage() = %v, wanted %v", out, test.in)
+			}
+		})
+	}
+}
+
+func TestProtobufCodecRejectsOtherTypes(t *testing.T) {
+	if _, err := ProtobufCodec.Marshal(&testMessage{}); err == nil {
+		t.Error("Marshal() = nil, wanted an error")
+	}
+	if err := ProtobufCodec.Unmarshal(nil, &testMessage{}); err == nil {
+		t.Error("Unmarshal() = nil, wanted an error")
+	}
+}
+
+func TestDecodeMessageErrors(t *testing.T) {
+	b, err := EncodeMessage(JSONCodec, "v2", &testMessage{Name: "foo"})
+	if err != nil {
+		t.Fatal("EncodeMessage() =", err)
+	}
+
+	var skew *VersionSkewError
+	if err := DecodeMessage(JSONCodec, "v1", b, &testMessage{}); !errors.As(err, &skew) {
+		t.Errorf("DecodeMessage() = %v, wanted a VersionSkewError", err)
+	} else if skew.Got != "v2" || skew.Want != "v1" {
+		t.Errorf("VersionSkewError = %+v, wanted v2 and v1", skew)
+	}
+
+	if err := DecodeMessage(JSONCodec, "v1", []byte("{}"), &testMessage{}); err == nil {
+		t.Error("DecodeMessage() = nil, wanted an error for a missing envelope")
+	}
+
+	if _, err := EncodeMessage(JSONCodec, "v1\n", &testMessage{}); err == nil {
+		t.Error("EncodeMessage() = nil, wanted an error for a newline in the version")
+	}
+}
+
+func TestNegotiatedCodec(t *testing.T) {
+	got, err := NegotiatedCodec(GobCodec.Name(), JSONCodec, GobCodec)
+	if err != nil {
+		t.Fatal("NegotiatedCodec() =", err)
+	}
+	if got != GobCodec {
+		t.Errorf("NegotiatedCodec() = %s, wanted %s", got.Name(), GobCodec.Name())
+	}
+
+	if _, err := NegotiatedCodec("", JSONCodec, GobCodec); !errors.Is(err, ErrNoCodec) {
+		t.Errorf("NegotiatedCodec() = %v, wanted %v", err, ErrNoCodec)
+	}
+}
+
+func TestDurableCodecConnection(t *testing.T) {
+	// The server only speaks JSON, so that is what gets negotiated.
+	upgrader := NewCodecUpgrader(JSONCodec)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		codec, err := NegotiatedCodec(c.Subprotocol(), JSONCodec)
+		if err != nil {
+			return
+		}
+		for {
+			_, b, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg testMessage
+			if err := DecodeMessage(codec, "v1", b, &msg); err != nil {
+				return
+			}
+			msg.Count++
+			reply, err := EncodeMessage(codec, "v1", &msg)
+			if err != nil {
+				return
+			}
+			if err := c.WriteMessage(codec.MessageType(), reply); err != nil {
+				return
+			}
+		}
+	}))
+	defer s.Close()
+
+	logger := ktesting.TestLogger(t)
+	target := "ws" + strings.TrimPrefix(s.URL, "http")
+	messageChan := make(chan []byte, 1)
+	conn := NewDurableCodecConnection(target, "v1", messageChan, logger, ProtobufCodec, JSONCodec)
+	defer conn.Shutdown()
+
+	if err := wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return conn.SendMessage(&testMessage{Name: "foo", Count: 1}) == nil, nil
+	}); err != nil {
+		t.Fatal("Timed out trying to send a message:", err)
+	}
+
+	codec, err := conn.Codec()
+	if err != nil {
+		t.Fatal("Codec() =", err)
+	}
+	if codec != JSONCodec {
+		t.Errorf("Codec() = %s, wanted %s", codec.Name(), JSONCodec.Name())
+	}
+
+	var got testMessage
+	if err := conn.DecodeMessage(<-messageChan, &got); err != nil {
+		t.Fatal("DecodeMessage() =", err)
+	}
+	if want := (testMessage{Name: "foo", Count: 2}); got != want {
+		t.Errorf("DecodeMessage() = %+v, wanted %+v", got, want)
+	}
+}
+
+func TestCodecConnectionWithoutCommonCodec(t *testing.T) {
+	spy := &inspectableConnection{
+		closeCalls: make(chan struct{}, 1),
+	}
+	conn := newConnection(staticConnFactory(spy), nil)
+	conn.codecs = []Codec{JSONCodec}
+	conn.connectionBackoff = wait.Backoff{Duration: time.Millisecond, Steps: 1}
+
+	if err := conn.connect(); err == nil {
+		t.Error("connect() = nil, wanted an error")
+	}
+	if len(spy.closeCalls) != 1 {
+		t.Errorf("Expected 'Close' to be called once, but was called %v times", len(spy.closeCalls))
+	}
+	if _, err := conn.Codec(); !errors.Is(err, ErrConnectionNotEstablished) {
+		t.Errorf("Codec() = %v, wanted %v", err, ErrConnectionNotEstablished)
+	}
+	if err := conn.SendMessage(&testMessage{}); !errors.Is(err, ErrConnectionNotEstablished) {
+		t.Errorf("SendMessage() = %v, wanted %v", err, ErrConnectionNotEstablished)
+	}
+}
@@ -17,8 +17,7 @@ limitations under the License.
 package websocket
 
 import (
-	"bytes"
-	"encoding/gob"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -38,6 +37,10 @@ var (
 	// but no connection is already created.
 	ErrConnectionNotEstablished = errors.New("connection has not yet been established")
 
+	// ErrDraining is returned when sending messages over a connection which
+	// is being drained.
+	ErrDraining = errors.New("connection is draining")
+
 	// errShuttingDown is returned internally once the shutdown signal has been sent.
 	errShuttingDown = errors.New("shutdown in progress")
 
@@ -57,6 +60,12 @@ type rawConnection interface {
 	SetPongHandler(func(string) error)
 }
 
+// subprotocolConnection is implemented by connections that negotiated
+// a subprotocol, like *websocket.Conn.
+type subprotocolConnection interface {
+	Subprotocol() string
+}
+
 // ManagedConnection represents a websocket connection.
 type ManagedConnection struct {
 	connection        rawConnection
@@ -65,6 +74,11 @@ type ManagedConnection struct {
 	closeChan chan struct{}
 	closeOnce sync.Once
 
+	// drainChan is closed once the connection is draining, to stop
+	// reconnecting and sending messages.
+	drainChan chan struct{}
+	drainOnce sync.Once
+
 	establishChan chan struct{}
 	establishOnce sync.Once
 
@@ -86,6 +100,12 @@ type ManagedConnection struct {
 
 	// Used for the exponential backoff when connecting
 	connectionBackoff wait.Backoff
+
+	// The codecs offered when connecting, the one negotiated for the
+	// current connection and the version of the messages' envelopes.
+	codecs         []Codec
+	codec          Codec
+	messageVersion string
 }
 
 // NewDurableSendingConnection creates a new websocket connection
@@ -128,11 +148,26 @@ func NewDurableSendingConnectionGuaranteed(target string, duration time.Duration
 // go func() {conn.Shutdown(); close(messageChan)}
 // go func() {for range messageChan {}}
 func NewDurableConnection(target string, messageChan chan []byte, logger *zap.SugaredLogger) *ManagedConnection {
+	return newDurableConnection(target, messageChan, logger, "", nil)
+}
+
+// NewDurableCodecConnection creates a new durable websocket connection like
+// NewDurableConnection, which negotiates one of the given codecs, in order of
+// preference, with the endpoint on each connect. Typed messages are sent with
+// SendMessage and the ones received are decoded with DecodeMessage, both
+// wrapping them into envelopes carrying the given version so that version
+// skews between the peers are detected.
+func NewDurableCodecConnection(target, version string, messageChan chan []byte, logger *zap.SugaredLogger, codecs ...Codec) *ManagedConnection {
+	return newDurableConnection(target, messageChan, logger, version, codecs)
+}
+
+func newDurableConnection(target string, messageChan chan []byte, logger *zap.SugaredLogger, version string, codecs []Codec) *ManagedConnection {
 	websocketConnectionFactory := func() (rawConnection, error) {
 		dialer := &websocket.Dialer{
 			// This needs to be relatively short to avoid the connection getting blackholed for a long time
 			// by restarting the serving side of the connection behind a Kubernetes Service.
 			HandshakeTimeout: 3 * time.Second,
+			Subprotocols:     CodecNames(codecs...),
 		}
 		conn, resp, err := dialer.Dial(target, nil)
 		if err != nil {
@@ -148,6 +183,8 @@ func NewDurableConnection(target string, messageChan chan []byte, logger *zap.Su
 	}
 
 	c := newConnection(websocketConnectionFactory, messageChan)
+	c.codecs = codecs
+	c.messageVersion = version
 
 	// Keep the connection alive asynchronously and reconnect on
 	// connection failure.
@@ -170,6 +207,9 @@ func NewDurableConnection(target string, messageChan chan []byte, logger *zap.Su
 				if err := c.closeConnection(); err != nil {
 					logger.Errorw("Failed to close the connection after crashing", zap.Error(err))
 				}
+			case <-c.drainChan:
+				logger.Infof("Connection to %s is draining, not reconnecting", target)
+				return
 			case <-c.closeChan:
 				logger.Infof("Connection to %s is being shutdown", target)
 				return
@@ -204,6 +244,7 @@ func newConnection(connFactory func() (rawConnection, error), messageChan chan [
 	conn := &ManagedConnection{
 		connectionFactory: connFactory,
 		closeChan:         make(chan struct{}),
+		drainChan:         make(chan struct{}),
 		establishChan:     make(chan struct{}),
 		messageChan:       messageChan,
 		connectionBackoff: wait.Backoff{
@@ -227,6 +268,18 @@ func (c *ManagedConnection) connect() error {
 				return false, nil
 			}
 
+			var codec Codec
+			if len(c.codecs) > 0 {
+				var subprotocol string
+				if sc, ok := conn.(subprotocolConnection); ok {
+					subprotocol = sc.Subprotocol()
+				}
+				if codec, err = NegotiatedCodec(subprotocol, c.codecs...); err != nil {
+					conn.Close()
+					return false, nil
+				}
+			}
+
 			// Setting the read deadline will cause NextReader in read
 			// to fail if it is exceeded. This deadline is reset each
 			// time we receive a pong message so we know the connection
@@ -241,10 +294,13 @@ func (c *ManagedConnection) connect() error {
 			defer c.connectionLock.Unlock()
 
 			c.connection = conn
+			c.codec = codec
 			c.establishOnce.Do(func() {
 				close(c.establishChan)
 			})
 			return true, nil
+		case <-c.drainChan:
+			return false, ErrDraining
 		case <-c.closeChan:
 			return false, errShuttingDown
 		}
@@ -273,6 +329,7 @@ func (c *ManagedConnection) closeConnection() error {
 	if c.connection != nil {
 		err := c.connection.Close()
 		c.connection = nil
+		c.codec = nil
 		return err
 	}
 	return nil
@@ -310,6 +367,14 @@ func (c *ManagedConnection) read() error {
 }
 
 func (c *ManagedConnection) write(messageType int, body []byte) error {
+	if messageType == websocket.TextMessage || messageType == websocket.BinaryMessage {
+		select {
+		case <-c.drainChan:
+			return ErrDraining
+		default:
+		}
+	}
+
 	c.connectionLock.RLock()
 	defer c.connectionLock.RUnlock()
 
@@ -336,13 +401,12 @@ func (c *ManagedConnection) Status() error {
 
 // Send sends an encodable message over the websocket connection.
 func (c *ManagedConnection) Send(msg interface{}) error {
-	var b bytes.Buffer
-	enc := gob.NewEncoder(&b)
-	if err := enc.Encode(msg); err != nil {
+	b, err := GobCodec.Marshal(msg)
+	if err != nil {
 		return err
 	}
 
-	return c.write(websocket.BinaryMessage, b.Bytes())
+	return c.write(websocket.BinaryMessage, b)
 }
 
 // SendRaw sends a message over the websocket connection without performing any encoding.
@@ -360,3 +424,36 @@ func (c *ManagedConnection) Shutdown() error {
 	c.processingWg.Wait()
 	return err
 }
+
+// Drain gracefully shuts the connection down, e.g. when the process is
+// draining: it stops reconnecting and sending messages, waits for the
+// messages being sent to be flushed, sends a close frame with the going away
+// code to the peer and shuts the connection down. The connection is shut
+// down without waiting any longer once ctx is done. Drain can be registered
+// as a shutdown hook with signals.OnShutdown.
+func (c *ManagedConnection) Drain(ctx context.Context) error {
+	c.drainOnce.Do(func() {
+		close(c.drainChan)
+	})
+
+	flushed := make(chan error, 1)
+	go func() {
+		// The close frame is written once the writes in flight are.
+		err := c.write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "draining"))
+		if errors.Is(err, ErrConnectionNotEstablished) {
+			err = nil
+		}
+		flushed <- err
+	}()
+
+	var err error
+	select {
+	case err = <-flushed:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if serr := c.Shutdown(); err == nil {
+		err = serr
+	}
+	return err
+}
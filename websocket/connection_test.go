@@ -17,6 +17,7 @@ limitations under the License.
 package websocket
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -459,3 +460,63 @@ func TestNewDurableSendingConnectionGuaranteed(t *testing.T) {
 	reconnectChan <- struct{}{}
 
 }
+
+func TestDrainClosesWithGoingAway(t *testing.T) {
+	closeCodes := make(chan int, 1)
+	upgrader := websocket.Upgrader{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			if _, _, err := c.NextReader(); err != nil {
+				var ce *websocket.CloseError
+				if errors.As(err, &ce) {
+					closeCodes <- ce.Code
+				}
+				return
+			}
+		}
+	}))
+	defer s.Close()
+
+	logger := ktesting.TestLogger(t)
+	target := "ws" + strings.TrimPrefix(s.URL, "http")
+	conn, err := NewDurableSendingConnectionGuaranteed(target, propagationTimeout, logger)
+	if err != nil {
+		t.Fatal("Failed to connect:", err)
+	}
+	if err := conn.Send("test"); err != nil {
+		t.Fatal("Send() =", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), propagationTimeout)
+	defer cancel()
+	if err := conn.Drain(ctx); err != nil {
+		t.Fatal("Drain() =", err)
+	}
+
+	select {
+	case code := <-closeCodes:
+		if code != websocket.CloseGoingAway {
+			t.Errorf("Close code = %d, wanted %d", code, websocket.CloseGoingAway)
+		}
+	case <-time.After(propagationTimeout):
+		t.Error("Timed out waiting for the close frame")
+	}
+	if err := conn.Send("test"); !errors.Is(err, ErrDraining) {
+		t.Errorf("Send() after Drain = %v, wanted %v", err, ErrDraining)
+	}
+}
+
+func TestDrainWithoutConnection(t *testing.T) {
+	conn := newConnection(errConnFactory(errors.New("connection error")), nil)
+	if err := conn.Drain(context.Background()); err != nil {
+		t.Error("Drain() =", err)
+	}
+	if err := conn.SendRaw(websocket.TextMessage, []byte("test")); !errors.Is(err, ErrDraining) {
+		t.Errorf("SendRaw() after Drain = %v, wanted %v", err, ErrDraining)
+	}
+}